@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultAuditLogQueryLimit is the default number of entries returned by the read-audit-log command.
+const DefaultAuditLogQueryLimit = 100
+
+// AuditEntry is the audit log record of a single admin command request.
+// Async commands are recorded twice: when the request starts the job, and when the job has finished,
+// with the job ID, and the final result code and duration of the job.
+type AuditEntry struct {
+	Time       time.Time   `json:"time"`
+	Identity   string      `json:"identity"`
+	Command    string      `json:"command"`
+	Data       interface{} `json:"data,omitempty"`
+	JobID      string      `json:"job_id,omitempty"`
+	Code       string      `json:"code"`
+	DurationMS int64       `json:"duration_ms"`
+}
+
+// AuditLogFilter selects the entries returned when reading the audit log. Empty fields match all entries.
+type AuditLogFilter struct {
+	Identity string
+	Command  string
+}
+
+func (f AuditLogFilter) matches(entry *AuditEntry) bool {
+	return (f.Identity == "" || f.Identity == entry.Identity) &&
+		(f.Command == "" || f.Command == entry.Command)
+}
+
+// AuditLog is an append-only file of admin command requests, with one JSON encoded entry per line.
+type AuditLog struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// OpenAuditLog opens the audit log at the given path, creating it if it doesn't exist.
+// New entries are appended to existing ones.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log: %w", err)
+	}
+
+	return &AuditLog{
+		path: path,
+		file: file,
+	}, nil
+}
+
+// Append writes the given entry to the end of the audit log.
+func (l *AuditLog) Append(entry *AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not encode audit log entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.file.Write(line); err != nil {
+		return fmt.Errorf("could not write audit log entry: %w", err)
+	}
+	return nil
+}
+
+// Read returns the latest entries of the audit log matching the given filter, oldest first.
+// At most limit entries are returned.
+func (l *AuditLog) Read(filter AuditLogFilter, limit int) ([]*AuditEntry, error) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log: %w", err)
+	}
+	defer file.Close()
+
+	// entries are kept in a ring buffer, so that only the latest ones are returned
+	latest := make([]*AuditEntry, 0, limit)
+	next := 0
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// a partially written entry at the end of the log is skipped
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read audit log: %w", err)
+		}
+
+		var entry AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("could not decode audit log entry: %w", err)
+		}
+		if !filter.matches(&entry) || limit == 0 {
+			continue
+		}
+
+		if len(latest) < limit {
+			latest = append(latest, &entry)
+		} else {
+			latest[next] = &entry
+			next = (next + 1) % limit
+		}
+	}
+
+	return append(latest[next:], latest[:next]...), nil
+}
+
+// Close closes the audit log file.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Close()
+}
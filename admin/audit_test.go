@@ -0,0 +1,208 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestAuditLog(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "audit.log")
+
+		policy, err := NewAuthorizationPolicy(
+			map[string][]string{
+				"operator": {AllCommands},
+				"viewer":   {"ping"},
+			},
+			map[string][]string{
+				"ops.example.com":  {"operator"},
+				"view.example.com": {"viewer"},
+			},
+		)
+		require.NoError(t, err)
+
+		auditLog, err := OpenAuditLog(path)
+		require.NoError(t, err)
+
+		bootstrapper := NewCommandRunnerBootstrapper()
+		bootstrapper.RegisterHandler("echo", func(ctx context.Context, req *CommandRequest) (interface{}, error) {
+			return req.Data, nil
+		})
+		runner := newTestCommandRunner(t, bootstrapper, WithAuthorizationPolicy(policy), WithAuditLog(auditLog))
+
+		run := func(identity string, command string, data interface{}) (interface{}, error) {
+			md := metadata.Pairs(identityMetadataKey, identity, tokenMetadataKey, runner.gatewayToken)
+			return runner.runCommand(metadata.NewIncomingContext(context.Background(), md), command, data)
+		}
+
+		result, err := run("ops.example.com", "echo", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "hello", result)
+
+		_, err = run("view.example.com", "echo", "denied")
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		_, err = run("view.example.com", "ping", nil)
+		require.NoError(t, err)
+
+		_, err = run("unknown.example.com", "ping", nil)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		// the local identity has no roles
+		_, err = runner.runCommand(context.Background(), "ping", nil)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		_, err = run("ops.example.com", "unknown", nil)
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+		t.Run("read all entries", func(t *testing.T) {
+			result, err := run("ops.example.com", "read-audit-log", nil)
+			require.NoError(t, err)
+
+			entries := result.([]interface{})
+			require.Len(t, entries, 6)
+
+			first := entries[0].(map[string]interface{})
+			assert.Equal(t, "ops.example.com", first["identity"])
+			assert.Equal(t, "echo", first["command"])
+			assert.Equal(t, "hello", first["data"])
+			assert.Equal(t, codes.OK.String(), first["code"])
+			assert.Contains(t, first, "duration_ms")
+
+			denied := entries[1].(map[string]interface{})
+			assert.Equal(t, codes.PermissionDenied.String(), denied["code"])
+
+			local := entries[4].(map[string]interface{})
+			assert.Equal(t, IdentityLocal, local["identity"])
+		})
+
+		t.Run("filter and limit", func(t *testing.T) {
+			result, err := run("ops.example.com", "read-audit-log", map[string]interface{}{
+				"identity": "view.example.com",
+				"limit":    float64(1),
+			})
+			require.NoError(t, err)
+
+			entries := result.([]interface{})
+			require.Len(t, entries, 1)
+			entry := entries[0].(map[string]interface{})
+			assert.Equal(t, "ping", entry["command"])
+			assert.Equal(t, codes.OK.String(), entry["code"])
+
+			result, err = run("ops.example.com", "read-audit-log", map[string]interface{}{"command": "read-audit-log"})
+			require.NoError(t, err)
+			// the previous queries were recorded as well
+			require.Len(t, result, 2)
+		})
+
+		t.Run("invalid query", func(t *testing.T) {
+			_, err := run("ops.example.com", "read-audit-log", map[string]interface{}{"limit": float64(-1)})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			_, err = run("ops.example.com", "read-audit-log", map[string]interface{}{"unknown": "value"})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+
+		t.Run("entries are appended across restarts", func(t *testing.T) {
+			require.NoError(t, auditLog.Close())
+
+			reopened, err := OpenAuditLog(path)
+			require.NoError(t, err)
+			defer reopened.Close()
+
+			entries, err := reopened.Read(AuditLogFilter{}, DefaultAuditLogQueryLimit)
+			require.NoError(t, err)
+			count := len(entries)
+
+			require.NoError(t, reopened.Append(&AuditEntry{Identity: IdentityLocal, Command: "ping", Code: codes.OK.String()}))
+			entries, err = reopened.Read(AuditLogFilter{}, DefaultAuditLogQueryLimit)
+			require.NoError(t, err)
+			require.Len(t, entries, count+1)
+			assert.Equal(t, "ping", entries[count].Command)
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		})
+	})
+}
+
+func TestAuditLogDisabled(t *testing.T) {
+	runner := newTestCommandRunner(t, NewCommandRunnerBootstrapper())
+
+	_, err := runner.runCommand(context.Background(), "read-audit-log", nil)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+// TestAuditLogAsyncCommand tests that async commands are recorded when the job is started, and
+// again with the final result code and duration of the job once it has finished.
+func TestAuditLogAsyncCommand(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		auditLog, err := OpenAuditLog(filepath.Join(dir, "audit.log"))
+		require.NoError(t, err)
+		defer auditLog.Close()
+
+		proceed := make(chan error)
+		bootstrapper := NewCommandRunnerBootstrapper()
+		bootstrapper.RegisterAsyncHandler("slow", func(ctx context.Context, req *CommandRequest) (interface{}, error) {
+			return nil, <-proceed
+		})
+		runner := newTestCommandRunner(t, bootstrapper, WithAuditLog(auditLog))
+
+		jobEntry := func(id string) *AuditEntry {
+			var found *AuditEntry
+			require.Eventually(t, func() bool {
+				entries, err := auditLog.Read(AuditLogFilter{Command: "slow"}, DefaultAuditLogQueryLimit)
+				require.NoError(t, err)
+				for _, entry := range entries {
+					if entry.JobID == id {
+						found = entry
+						return true
+					}
+				}
+				return false
+			}, time.Second, 10*time.Millisecond)
+			return found
+		}
+
+		for _, c := range []struct {
+			err  error
+			code codes.Code
+		}{
+			{nil, codes.OK},
+			{status.Error(codes.NotFound, "not found"), codes.NotFound},
+			{errors.New("failed"), codes.Unknown},
+		} {
+			result, err := runner.runCommand(context.Background(), "slow", "data")
+			require.NoError(t, err)
+			id := result.(map[string]interface{})["job_id"].(string)
+
+			time.Sleep(20 * time.Millisecond)
+			proceed <- c.err
+
+			entry := jobEntry(id)
+			assert.Equal(t, IdentityLocal, entry.Identity)
+			assert.Equal(t, "data", entry.Data)
+			assert.Equal(t, c.code.String(), entry.Code)
+			assert.GreaterOrEqual(t, entry.DurationMS, int64(20))
+		}
+
+		// the requests starting the jobs are recorded as well
+		entries, err := auditLog.Read(AuditLogFilter{Command: "slow"}, DefaultAuditLogQueryLimit)
+		require.NoError(t, err)
+		require.Len(t, entries, 6)
+		assert.Empty(t, entries[0].JobID)
+		assert.Equal(t, codes.OK.String(), entries[0].Code)
+	})
+}
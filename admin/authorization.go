@@ -0,0 +1,187 @@
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// IdentityLocal is the identity of callers connecting directly to the gRPC server on the unix socket.
+	IdentityLocal = "local"
+	// IdentityAnonymous is the identity of HTTP callers which did not present a client certificate.
+	IdentityAnonymous = "anonymous"
+
+	// AllCommands may be used in a role to allow all commands, and in the identities of a policy
+	// to assign roles to all callers.
+	AllCommands = "*"
+
+	// metadataPrefix is the prefix of all gRPC metadata keys set by the HTTP gateway. Headers with this
+	// prefix are never forwarded from HTTP requests, so callers can't choose their own identity.
+	metadataPrefix = "flow-admin-"
+
+	identityMetadataKey = metadataPrefix + "identity"
+	tokenMetadataKey    = metadataPrefix + "gateway-token"
+)
+
+// AuthorizationPolicy maps caller identities to the admin commands they are allowed to run.
+//
+// The identity of an HTTP caller is the subject common name of its TLS client certificate, or the first
+// DNS name of the certificate if the common name is empty. Callers without a client certificate have the
+// identity "anonymous", and callers using the gRPC unix socket directly have the identity "local".
+type AuthorizationPolicy struct {
+	// Roles maps role names to the commands allowed for the role. "*" allows all commands.
+	Roles map[string][]string `json:"roles"`
+	// Identities maps caller identities to their roles. The roles of "*" are assigned to all callers.
+	Identities map[string][]string `json:"identities"`
+
+	allowed map[string]map[string]struct{} // identity -> allowed commands
+}
+
+// LoadAuthorizationPolicy reads the JSON authorization policy from the given file, e.g.:
+//
+//	{
+//	  "roles": {
+//	    "operator": ["*"],
+//	    "viewer": ["ping", "list-commands", "read-blocks"]
+//	  },
+//	  "identities": {
+//	    "ops.example.com": ["operator"],
+//	    "local": ["operator"],
+//	    "*": ["viewer"]
+//	  }
+//	}
+func LoadAuthorizationPolicy(path string) (*AuthorizationPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read authorization policy: %w", err)
+	}
+
+	var policy AuthorizationPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("could not decode authorization policy: %w", err)
+	}
+
+	if err := policy.init(); err != nil {
+		return nil, fmt.Errorf("invalid authorization policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// NewAuthorizationPolicy returns a policy with the given roles and identities.
+func NewAuthorizationPolicy(roles map[string][]string, identities map[string][]string) (*AuthorizationPolicy, error) {
+	policy := &AuthorizationPolicy{
+		Roles:      roles,
+		Identities: identities,
+	}
+	if err := policy.init(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// init resolves the commands allowed for each identity.
+func (p *AuthorizationPolicy) init() error {
+	p.allowed = make(map[string]map[string]struct{}, len(p.Identities))
+	for identity, roles := range p.Identities {
+		commands := make(map[string]struct{})
+		for _, role := range roles {
+			roleCommands, ok := p.Roles[role]
+			if !ok {
+				return fmt.Errorf("identity %s has unknown role %s", identity, role)
+			}
+			for _, command := range roleCommands {
+				commands[command] = struct{}{}
+			}
+		}
+		p.allowed[identity] = commands
+	}
+	return nil
+}
+
+// IsAllowed returns true if the caller with the given identity is allowed to run the given command.
+func (p *AuthorizationPolicy) IsAllowed(identity string, command string) bool {
+	for _, id := range []string{identity, AllCommands} {
+		commands := p.allowed[id]
+		if _, ok := commands[command]; ok {
+			return true
+		}
+		if _, ok := commands[AllCommands]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// newGatewayToken returns a random token, which is added to the gRPC metadata by the HTTP gateway
+// to show that the caller identity was set by the gateway.
+func newGatewayToken() string {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		panic(fmt.Errorf("could not generate admin gateway token: %w", err))
+	}
+	return hex.EncodeToString(token)
+}
+
+// certificateIdentity returns the identity of the caller of the given HTTP request.
+func certificateIdentity(req *http.Request) string {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return IdentityAnonymous
+	}
+
+	cert := req.TLS.PeerCertificates[0]
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return IdentityAnonymous
+}
+
+// gatewayMetadata is used by the HTTP gateway to forward the identity of the caller to the gRPC server.
+func (r *CommandRunner) gatewayMetadata(_ context.Context, req *http.Request) metadata.MD {
+	return metadata.Pairs(
+		identityMetadataKey, certificateIdentity(req),
+		tokenMetadataKey, r.gatewayToken,
+	)
+}
+
+// gatewayHeaderMatcher forwards HTTP headers to the gRPC server like the default matcher, except for headers
+// which could be used to set the caller identity.
+func gatewayHeaderMatcher(key string) (string, bool) {
+	key, ok := runtime.DefaultHeaderMatcher(key)
+	if !ok || strings.HasPrefix(strings.ToLower(key), metadataPrefix) {
+		return "", false
+	}
+	return key, true
+}
+
+// callerIdentity returns the identity of the caller of a gRPC request. Requests which were not forwarded
+// by the HTTP gateway are made through the unix socket and have the local identity.
+func (r *CommandRunner) callerIdentity(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return IdentityLocal
+	}
+
+	tokens := md.Get(tokenMetadataKey)
+	identities := md.Get(identityMetadataKey)
+	if len(tokens) != 1 || len(identities) != 1 {
+		return IdentityLocal
+	}
+	if subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(r.gatewayToken)) != 1 {
+		return IdentityLocal
+	}
+	return identities[0]
+}
@@ -0,0 +1,107 @@
+package admin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestLoadAuthorizationPolicy(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "policy.json")
+
+		t.Run("valid policy", func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path, []byte(`{
+				"roles": {"operator": ["*"], "viewer": ["ping", "read-blocks"]},
+				"identities": {"ops.example.com": ["operator"], "*": ["viewer"]}
+			}`), 0644))
+
+			policy, err := LoadAuthorizationPolicy(path)
+			require.NoError(t, err)
+
+			assert.True(t, policy.IsAllowed("ops.example.com", "set-config"))
+			assert.True(t, policy.IsAllowed("ops.example.com", "ping"))
+			assert.True(t, policy.IsAllowed("other.example.com", "read-blocks"))
+			assert.False(t, policy.IsAllowed("other.example.com", "set-config"))
+			assert.False(t, policy.IsAllowed(IdentityLocal, "set-config"))
+		})
+
+		t.Run("unknown role", func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path, []byte(`{
+				"roles": {"viewer": ["ping"]},
+				"identities": {"ops.example.com": ["operator"]}
+			}`), 0644))
+
+			_, err := LoadAuthorizationPolicy(path)
+			require.Error(t, err)
+		})
+
+		t.Run("invalid json", func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path, []byte(`{"roles": [`), 0644))
+
+			_, err := LoadAuthorizationPolicy(path)
+			require.Error(t, err)
+		})
+	})
+}
+
+func TestCallerIdentity(t *testing.T) {
+	runner := newTestCommandRunner(t, NewCommandRunnerBootstrapper())
+
+	requestWithCert := func(cert *x509.Certificate) *http.Request {
+		req := &http.Request{}
+		if cert != nil {
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		}
+		return req
+	}
+	identityOf := func(req *http.Request) string {
+		md := runner.gatewayMetadata(context.Background(), req)
+		return runner.callerIdentity(metadata.NewIncomingContext(context.Background(), md))
+	}
+
+	t.Run("common name", func(t *testing.T) {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: "ops.example.com"}, DNSNames: []string{"dns.example.com"}}
+		assert.Equal(t, "ops.example.com", identityOf(requestWithCert(cert)))
+	})
+
+	t.Run("dns name", func(t *testing.T) {
+		cert := &x509.Certificate{DNSNames: []string{"dns.example.com"}}
+		assert.Equal(t, "dns.example.com", identityOf(requestWithCert(cert)))
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		assert.Equal(t, IdentityAnonymous, identityOf(requestWithCert(nil)))
+	})
+
+	t.Run("unix socket", func(t *testing.T) {
+		assert.Equal(t, IdentityLocal, runner.callerIdentity(context.Background()))
+	})
+
+	t.Run("identity without gateway token", func(t *testing.T) {
+		md := metadata.Pairs(identityMetadataKey, "ops.example.com", tokenMetadataKey, "forged")
+		assert.Equal(t, IdentityLocal, runner.callerIdentity(metadata.NewIncomingContext(context.Background(), md)))
+	})
+
+	t.Run("identity headers are not forwarded", func(t *testing.T) {
+		_, ok := gatewayHeaderMatcher("Grpc-Metadata-Flow-Admin-Identity")
+		assert.False(t, ok)
+		_, ok = gatewayHeaderMatcher("Grpc-Metadata-Flow-Admin-Gateway-Token")
+		assert.False(t, ok)
+
+		key, ok := gatewayHeaderMatcher("Grpc-Metadata-Custom")
+		assert.True(t, ok)
+		assert.Equal(t, "Custom", key)
+	})
+}
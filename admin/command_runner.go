@@ -41,6 +41,17 @@ type CommandRequest struct {
 	// ValidatorData may be optionally set by the Validator function, and will
 	// then be available for use in the Handler function.
 	ValidatorData interface{}
+
+	// job is the job running the request, nil if the command is not async.
+	job *job
+}
+
+// ReportProgress records the progress of an async command, which is reported by the
+// get-job and list-jobs commands. It is a no-op for commands which are not async.
+func (r *CommandRequest) ReportProgress(progress string) {
+	if r.job != nil {
+		r.job.reportProgress(progress)
+	}
 }
 
 func WithTLS(config *tls.Config) CommandRunnerOption {
@@ -61,21 +72,48 @@ func WithMaxMsgSize(size int) CommandRunnerOption {
 	}
 }
 
+// WithAuthorizationPolicy restricts the commands each caller is allowed to run.
+// If no policy is set, all callers are allowed to run all commands.
+func WithAuthorizationPolicy(policy *AuthorizationPolicy) CommandRunnerOption {
+	return func(r *CommandRunner) {
+		r.policy = policy
+	}
+}
+
+// WithAuditLog records all command requests in the given audit log. The audit log is closed when
+// the command runner shuts down.
+func WithAuditLog(log *AuditLog) CommandRunnerOption {
+	return func(r *CommandRunner) {
+		r.auditLog = log
+	}
+}
+
+// WithMaxFinishedJobs sets the number of finished async command jobs kept in the job history.
+func WithMaxFinishedJobs(n int) CommandRunnerOption {
+	return func(r *CommandRunner) {
+		r.jobs.maxFinished = n
+	}
+}
+
 type CommandRunnerBootstrapper struct {
 	handlers   map[string]CommandHandler
 	validators map[string]CommandValidator
+	async      map[string]struct{}
 }
 
 func NewCommandRunnerBootstrapper() *CommandRunnerBootstrapper {
 	return &CommandRunnerBootstrapper{
 		handlers:   make(map[string]CommandHandler),
 		validators: make(map[string]CommandValidator),
+		async:      make(map[string]struct{}),
 	}
 }
 
 func (r *CommandRunnerBootstrapper) Bootstrap(logger zerolog.Logger, bindAddress string, opts ...CommandRunnerOption) *CommandRunner {
 	handlers := make(map[string]CommandHandler)
 	commands := make([]interface{}, 0, len(r.handlers))
+	jobs := newJobs(DefaultMaxFinishedJobs)
+	var commandRunner *CommandRunner
 
 	r.RegisterHandler("ping", func(ctx context.Context, req *CommandRequest) (interface{}, error) {
 		return "pong", nil
@@ -85,6 +123,68 @@ func (r *CommandRunnerBootstrapper) Bootstrap(logger zerolog.Logger, bindAddress
 		return commands, nil
 	})
 
+	r.RegisterHandler("list-jobs", func(ctx context.Context, req *CommandRequest) (interface{}, error) {
+		all := jobs.list()
+		summaries := make([]interface{}, 0, len(all))
+		for _, j := range all {
+			summaries = append(summaries, j.summary(false))
+		}
+		return summaries, nil
+	})
+
+	r.RegisterHandler("get-job", func(ctx context.Context, req *CommandRequest) (interface{}, error) {
+		j, err := jobs.get(req.Data.(string))
+		if errors.Is(err, ErrJobNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if err != nil {
+			return nil, err
+		}
+		return j.summary(true), nil
+	})
+	r.RegisterValidator("get-job", validateJobID)
+
+	r.RegisterHandler("cancel-job", func(ctx context.Context, req *CommandRequest) (interface{}, error) {
+		err := jobs.cancelJob(req.Data.(string))
+		if errors.Is(err, ErrJobNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, ErrJobFinished) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		if err != nil {
+			return nil, err
+		}
+		return "ok", nil
+	})
+	r.RegisterValidator("cancel-job", validateJobID)
+
+	r.RegisterHandler("read-audit-log", func(ctx context.Context, req *CommandRequest) (interface{}, error) {
+		if commandRunner.auditLog == nil {
+			return nil, status.Error(codes.FailedPrecondition, "audit log is not enabled")
+		}
+
+		query := req.ValidatorData.(*auditLogQuery)
+		entries, err := commandRunner.auditLog.Read(query.filter, query.limit)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]interface{}, 0, len(entries))
+		for _, entry := range entries {
+			result = append(result, map[string]interface{}{
+				"time":        entry.Time.UTC().Format(time.RFC3339Nano),
+				"identity":    entry.Identity,
+				"command":     entry.Command,
+				"data":        entry.Data,
+				"code":        entry.Code,
+				"duration_ms": float64(entry.DurationMS),
+			})
+		}
+		return result, nil
+	})
+	r.RegisterValidator("read-audit-log", validateAuditLogQuery)
+
 	for command, handler := range r.handlers {
 		handlers[command] = handler
 		commands = append(commands, command)
@@ -95,9 +195,17 @@ func (r *CommandRunnerBootstrapper) Bootstrap(logger zerolog.Logger, bindAddress
 		validators[command] = validator
 	}
 
-	commandRunner := &CommandRunner{
+	async := make(map[string]struct{})
+	for command := range r.async {
+		async[command] = struct{}{}
+	}
+
+	commandRunner = &CommandRunner{
 		handlers:         handlers,
 		validators:       validators,
+		async:            async,
+		jobs:             jobs,
+		gatewayToken:     newGatewayToken(),
 		grpcAddress:      fmt.Sprintf("%s/flow-node-admin.sock", os.TempDir()),
 		httpAddress:      bindAddress,
 		logger:           logger.With().Str("admin", "command_runner").Logger(),
@@ -119,6 +227,17 @@ func (r *CommandRunnerBootstrapper) RegisterHandler(command string, handler Comm
 	return true
 }
 
+// RegisterAsyncHandler registers the handler of a long-running command. Instead of running the handler
+// within the request, a job is started and its ID is returned to the caller. The job can be inspected
+// with the get-job and list-jobs commands, and canceled with the cancel-job command.
+func (r *CommandRunnerBootstrapper) RegisterAsyncHandler(command string, handler CommandHandler) bool {
+	if !r.RegisterHandler(command, handler) {
+		return false
+	}
+	r.async[command] = struct{}{}
+	return true
+}
+
 func (r *CommandRunnerBootstrapper) RegisterValidator(command string, validator CommandValidator) bool {
 	if _, ok := r.validators[command]; ok {
 		return false
@@ -130,18 +249,32 @@ func (r *CommandRunnerBootstrapper) RegisterValidator(command string, validator
 type CommandRunner struct {
 	handlers    map[string]CommandHandler
 	validators  map[string]CommandValidator
+	async       map[string]struct{}
+	jobs        *jobs
+	jobsCtx     context.Context // parent context of all jobs, canceled when the runner shuts down
 	grpcAddress string
 	httpAddress string
 	maxMsgSize  int
 	tlsConfig   *tls.Config
 	logger      zerolog.Logger
 
+	policy       *AuthorizationPolicy
+	auditLog     *AuditLog
+	gatewayToken string // proves that the caller identity in the gRPC metadata was set by the HTTP gateway
+
 	// wait for worker routines to be ready
 	workersStarted sync.WaitGroup
 
 	// wait for worker routines to exit
 	workersFinished sync.WaitGroup
 
+	// jobsMu guards jobsStopped against jobs being started while the runner waits for
+	// jobsFinished, since WaitGroup.Add must not be called concurrently with Wait once the
+	// counter may be zero.
+	jobsMu       sync.Mutex
+	jobsStopped  bool
+	jobsFinished sync.WaitGroup
+
 	// signals startup completion
 	startupCompleted chan struct{}
 }
@@ -155,6 +288,8 @@ func (r *CommandRunner) getValidator(command string) CommandValidator {
 }
 
 func (r *CommandRunner) Start(ctx irrecoverable.SignalerContext) {
+	r.jobsCtx = ctx
+
 	if err := r.runAdminServer(ctx); err != nil {
 		ctx.Throw(fmt.Errorf("failed to start admin server: %w", err))
 	}
@@ -180,6 +315,7 @@ func (r *CommandRunner) Done() <-chan struct{} {
 	go func() {
 		<-r.startupCompleted
 		r.workersFinished.Wait()
+		r.stopJobs()
 		close(done)
 	}()
 
@@ -221,7 +357,10 @@ func (r *CommandRunner) runAdminServer(ctx irrecoverable.SignalerContext) error
 	}()
 
 	// Initialize gRPC and HTTP muxers
-	gwmux := runtime.NewServeMux()
+	gwmux := runtime.NewServeMux(
+		runtime.WithMetadata(r.gatewayMetadata),
+		runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+	)
 	dialOpts := []grpc.DialOption{
 		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(r.maxMsgSize)),
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
@@ -287,14 +426,57 @@ func (r *CommandRunner) runAdminServer(ctx irrecoverable.SignalerContext) error
 				ctx.Throw(err)
 			}
 		}
+
+		if r.auditLog != nil {
+			if err := r.auditLog.Close(); err != nil {
+				r.logger.Err(err).Msg("failed to close audit log")
+			}
+		}
 	}()
 
 	return nil
 }
 
+// runCommand authorizes and runs the given command, and records the request in the audit log.
 func (r *CommandRunner) runCommand(ctx context.Context, command string, data interface{}) (interface{}, error) {
-	r.logger.Info().Str("command", command).Msg("received new command")
+	start := time.Now()
+	identity := r.callerIdentity(ctx)
+
+	r.logger.Info().Str("command", command).Str("identity", identity).Msg("received new command")
+
+	var result interface{}
+	var err error
+	if r.policy != nil && !r.policy.IsAllowed(identity, command) {
+		r.logger.Warn().Str("command", command).Str("identity", identity).Msg("admin command not allowed for caller")
+		err = status.Errorf(codes.PermissionDenied, "%s is not allowed to run %s", identity, command)
+	} else {
+		result, err = r.executeCommand(ctx, command, data)
+	}
+
+	r.audit(&AuditEntry{
+		Time:       start,
+		Identity:   identity,
+		Command:    command,
+		Data:       data,
+		Code:       status.Code(err).String(),
+		DurationMS: time.Since(start).Milliseconds(),
+	})
+
+	return result, err
+}
 
+// audit records the given entry in the audit log, if there is one.
+func (r *CommandRunner) audit(entry *AuditEntry) {
+	if r.auditLog == nil {
+		return
+	}
+
+	if err := r.auditLog.Append(entry); err != nil {
+		r.logger.Err(err).Str("command", entry.Command).Msg("failed to record admin command in audit log")
+	}
+}
+
+func (r *CommandRunner) executeCommand(ctx context.Context, command string, data interface{}) (interface{}, error) {
 	req := &CommandRequest{Data: data}
 
 	if validator := r.getValidator(command); validator != nil {
@@ -309,6 +491,10 @@ func (r *CommandRunner) runCommand(ctx context.Context, command string, data int
 		}
 	}
 
+	if _, ok := r.async[command]; ok {
+		return r.startJob(ctx, command, req)
+	}
+
 	var handleResult interface{}
 	var handleErr error
 
@@ -331,6 +517,109 @@ func (r *CommandRunner) runCommand(ctx context.Context, command string, data int
 	return handleResult, nil
 }
 
+// startJob runs the handler of the given async command in a new job, and returns the job ID.
+// The request is recorded in the audit log once it is accepted, and the job once it has finished,
+// with its final result code and duration.
+func (r *CommandRunner) startJob(ctx context.Context, command string, req *CommandRequest) (interface{}, error) {
+	identity := r.callerIdentity(ctx)
+
+	r.jobsMu.Lock()
+	if r.jobsStopped || r.jobsCtx.Err() != nil {
+		r.jobsMu.Unlock()
+		return nil, status.Error(codes.Unavailable, "admin server is shutting down")
+	}
+	r.jobsFinished.Add(1)
+	r.jobsMu.Unlock()
+
+	id := r.jobs.start(r.jobsCtx, command, req, r.getHandler(command), func(j *job) {
+		defer r.jobsFinished.Done()
+
+		j.mu.RLock()
+		defer j.mu.RUnlock()
+
+		r.audit(&AuditEntry{
+			Time:       j.startedAt,
+			Identity:   identity,
+			Command:    command,
+			Data:       req.Data,
+			JobID:      j.id,
+			Code:       j.code().String(),
+			DurationMS: j.finishedAt.Sub(j.startedAt).Milliseconds(),
+		})
+
+		if j.status == JobStatusFailed {
+			r.logger.Err(j.err).Str("command", command).Str("job_id", j.id).Msg("async admin command failed")
+		} else {
+			r.logger.Info().Str("command", command).Str("job_id", j.id).Str("status", string(j.status)).Msg("async admin command finished")
+		}
+	})
+
+	r.logger.Info().Str("command", command).Str("job_id", id).Msg("started async admin command")
+	return map[string]interface{}{"job_id": id}, nil
+}
+
+// stopJobs refuses to start new jobs, and waits for the running jobs to finish. Running jobs are
+// canceled by the runner's context.
+func (r *CommandRunner) stopJobs() {
+	r.jobsMu.Lock()
+	r.jobsStopped = true
+	r.jobsMu.Unlock()
+
+	r.jobsFinished.Wait()
+}
+
+// validateJobID validates that the request data is a job ID.
+func validateJobID(req *CommandRequest) error {
+	if _, ok := req.Data.(string); !ok {
+		return NewInvalidAdminReqFormatError("expected job id string, got %T", req.Data)
+	}
+	return nil
+}
+
+type auditLogQuery struct {
+	filter AuditLogFilter
+	limit  int
+}
+
+// validateAuditLogQuery validates the optional filter and limit of the read-audit-log command, e.g.:
+// {"identity": "ops.example.com", "command": "set-config", "limit": 10}
+func validateAuditLogQuery(req *CommandRequest) error {
+	query := &auditLogQuery{limit: DefaultAuditLogQueryLimit}
+	req.ValidatorData = query
+
+	if req.Data == nil {
+		return nil
+	}
+	input, ok := req.Data.(map[string]interface{})
+	if !ok {
+		return NewInvalidAdminReqFormatError("expected map[string]any")
+	}
+
+	for key, value := range input {
+		switch key {
+		case "identity", "command":
+			str, ok := value.(string)
+			if !ok {
+				return NewInvalidAdminReqParameterError(key, "must be a string", value)
+			}
+			if key == "identity" {
+				query.filter.Identity = str
+			} else {
+				query.filter.Command = str
+			}
+		case "limit":
+			limit, ok := value.(float64)
+			if !ok || limit <= 0 || limit != float64(int(limit)) {
+				return NewInvalidAdminReqParameterError(key, "must be a positive integer", value)
+			}
+			query.limit = int(limit)
+		default:
+			return NewInvalidAdminReqParameterError(key, "unknown parameter", value)
+		}
+	}
+	return nil
+}
+
 func (r *CommandRunner) GrpcAddress() string {
 	return r.grpcAddress
 }
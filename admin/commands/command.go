@@ -28,3 +28,14 @@ type AdminCommand interface {
 	// Unexpected errors will be returned with Internal error code, but will not be otherwise propagated.
 	Handler(ctx context.Context, request *admin.CommandRequest) (any, error)
 }
+
+// AsyncAdminCommand is implemented by admin commands whose Handler may run for a long time.
+// If IsAsync returns true, the Handler is run in a background job after the request has been
+// validated, and the request returns the ID of the job instead of the result of the Handler.
+// The job reports its status and result through the get-job and list-jobs commands, and its
+// context is canceled by the cancel-job command.
+type AsyncAdminCommand interface {
+	AdminCommand
+
+	IsAsync() bool
+}
@@ -0,0 +1,161 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/admin/commands"
+	"github.com/onflow/flow-go/module/jobqueue"
+)
+
+var _ commands.AdminCommand = (*ListDeadLettersCommand)(nil)
+var _ commands.AdminCommand = (*RetryDeadLetterCommand)(nil)
+var _ commands.AdminCommand = (*SkipDeadLetterCommand)(nil)
+
+// ListDeadLettersCommand is an admin command which lists the jobs dead-lettered by the job consumers
+// of the node. The data field is either empty to list the jobs of all consumers, or the name of a consumer.
+type ListDeadLettersCommand struct {
+	queues *jobqueue.DeadLetterQueues
+}
+
+func NewListDeadLettersCommand(queues *jobqueue.DeadLetterQueues) *ListDeadLettersCommand {
+	return &ListDeadLettersCommand{
+		queues: queues,
+	}
+}
+
+func (l *ListDeadLettersCommand) Handler(_ context.Context, req *admin.CommandRequest) (interface{}, error) {
+	names := req.ValidatorData.([]string)
+
+	result := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		queue, _ := l.queues.ByName(name)
+		jobs, err := queue.DeadLetters()
+		if err != nil {
+			return nil, fmt.Errorf("could not list dead-lettered jobs of %s: %w", name, err)
+		}
+		list, err := commands.ConvertToInterfaceList(jobs)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert dead-lettered jobs of %s: %w", name, err)
+		}
+		result[name] = list
+	}
+	return result, nil
+}
+
+// Validator validates the request.
+// Returns admin.InvalidAdminReqError for invalid/malformed requests.
+func (l *ListDeadLettersCommand) Validator(req *admin.CommandRequest) error {
+	switch data := req.Data.(type) {
+	case nil:
+		req.ValidatorData = l.queues.Names()
+	case string:
+		if _, ok := l.queues.ByName(data); !ok {
+			return admin.NewInvalidAdminReqErrorf("unknown job consumer: %s", data)
+		}
+		req.ValidatorData = []string{data}
+	default:
+		return admin.NewInvalidAdminReqFormatError("the data field must be empty or the name of a job consumer")
+	}
+	return nil
+}
+
+// RetryDeadLetterCommand is an admin command which processes a dead-lettered job once more, e.g.:
+// {"consumer": "ConsumeProgressVerificationChunkIndex", "index": 42}
+type RetryDeadLetterCommand struct {
+	queues *jobqueue.DeadLetterQueues
+}
+
+func NewRetryDeadLetterCommand(queues *jobqueue.DeadLetterQueues) *RetryDeadLetterCommand {
+	return &RetryDeadLetterCommand{
+		queues: queues,
+	}
+}
+
+func (r *RetryDeadLetterCommand) Handler(_ context.Context, req *admin.CommandRequest) (interface{}, error) {
+	data := req.ValidatorData.(validatedDeadLetterData)
+	err := data.queue.RetryDeadLetter(data.index)
+	if err != nil {
+		return nil, fmt.Errorf("could not retry dead-lettered job: %w", err)
+	}
+	return "OK", nil
+}
+
+// Validator validates the request.
+// Returns admin.InvalidAdminReqError for invalid/malformed requests.
+func (r *RetryDeadLetterCommand) Validator(req *admin.CommandRequest) error {
+	return validateDeadLetterRequest(r.queues, req)
+}
+
+// SkipDeadLetterCommand is an admin command which removes a dead-lettered job without processing it, e.g.:
+// {"consumer": "ConsumeProgressVerificationChunkIndex", "index": 42}
+type SkipDeadLetterCommand struct {
+	queues *jobqueue.DeadLetterQueues
+}
+
+func NewSkipDeadLetterCommand(queues *jobqueue.DeadLetterQueues) *SkipDeadLetterCommand {
+	return &SkipDeadLetterCommand{
+		queues: queues,
+	}
+}
+
+func (s *SkipDeadLetterCommand) Handler(_ context.Context, req *admin.CommandRequest) (interface{}, error) {
+	data := req.ValidatorData.(validatedDeadLetterData)
+	err := data.queue.SkipDeadLetter(data.index)
+	if err != nil {
+		return nil, fmt.Errorf("could not skip dead-lettered job: %w", err)
+	}
+	return "OK", nil
+}
+
+// Validator validates the request.
+// Returns admin.InvalidAdminReqError for invalid/malformed requests.
+func (s *SkipDeadLetterCommand) Validator(req *admin.CommandRequest) error {
+	return validateDeadLetterRequest(s.queues, req)
+}
+
+// validatedDeadLetterData represents a validated retry or skip request for a dead-lettered job.
+type validatedDeadLetterData struct {
+	queue jobqueue.DeadLetterQueue
+	index uint64
+}
+
+// validateDeadLetterRequest checks that the request references an existing dead-lettered job of a known consumer.
+// Returns admin.InvalidAdminReqError for invalid/malformed requests.
+func validateDeadLetterRequest(queues *jobqueue.DeadLetterQueues, req *admin.CommandRequest) error {
+	input, ok := req.Data.(map[string]interface{})
+	if !ok {
+		return admin.NewInvalidAdminReqFormatError("expected map[string]any")
+	}
+
+	name, ok := input["consumer"].(string)
+	if !ok {
+		return admin.NewInvalidAdminReqParameterError("consumer", "must be a string", input["consumer"])
+	}
+	queue, ok := queues.ByName(name)
+	if !ok {
+		return admin.NewInvalidAdminReqParameterError("consumer", "unknown job consumer", name)
+	}
+
+	index, ok := input["index"].(float64)
+	if !ok || index < 0 || index != math.Trunc(index) {
+		return admin.NewInvalidAdminReqParameterError("index", "must be a non-negative integer", input["index"])
+	}
+
+	jobs, err := queue.DeadLetters()
+	if err != nil {
+		return fmt.Errorf("could not list dead-lettered jobs of %s: %w", name, err)
+	}
+	for _, job := range jobs {
+		if job.Index == uint64(index) {
+			req.ValidatorData = validatedDeadLetterData{
+				queue: queue,
+				index: job.Index,
+			}
+			return nil
+		}
+	}
+	return admin.NewInvalidAdminReqParameterError("index", "no dead-lettered job at index", uint64(index))
+}
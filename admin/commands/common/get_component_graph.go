@@ -0,0 +1,69 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/admin/commands"
+	"github.com/onflow/flow-go/module/component"
+)
+
+var _ commands.AdminCommand = (*GetComponentGraphCommand)(nil)
+
+const (
+	componentGraphFormatJSON = "json"
+	componentGraphFormatDOT  = "dot"
+)
+
+// GetComponentGraphCommand is an admin command which returns the modules and components of the node,
+// their dependencies, and how long they took to initialize, to become ready and to shut down.
+// The graph is returned as JSON by default, or in the Graphviz DOT format with {"format": "dot"}.
+type GetComponentGraphCommand struct {
+	graph *component.StartupGraph
+}
+
+func NewGetComponentGraphCommand(graph *component.StartupGraph) *GetComponentGraphCommand {
+	return &GetComponentGraphCommand{
+		graph: graph,
+	}
+}
+
+func (g *GetComponentGraphCommand) Handler(_ context.Context, req *admin.CommandRequest) (interface{}, error) {
+	if req.ValidatorData.(string) == componentGraphFormatDOT {
+		return g.graph.DOT(), nil
+	}
+
+	nodes, err := commands.ConvertToInterfaceList(g.graph.Snapshot())
+	if err != nil {
+		return nil, fmt.Errorf("could not convert component graph: %w", err)
+	}
+	return nodes, nil
+}
+
+// Validator validates the request.
+// Returns admin.InvalidAdminReqError for invalid/malformed requests.
+func (g *GetComponentGraphCommand) Validator(req *admin.CommandRequest) error {
+	if req.Data == nil {
+		req.ValidatorData = componentGraphFormatJSON
+		return nil
+	}
+
+	input, ok := req.Data.(map[string]interface{})
+	if !ok {
+		return admin.NewInvalidAdminReqFormatError("expected map[string]any")
+	}
+
+	format, ok := input["format"]
+	if !ok {
+		req.ValidatorData = componentGraphFormatJSON
+		return nil
+	}
+	switch format {
+	case componentGraphFormatJSON, componentGraphFormatDOT:
+		req.ValidatorData = format
+	default:
+		return admin.NewInvalidAdminReqParameterError("format", "must be \"json\" or \"dot\"", format)
+	}
+	return nil
+}
@@ -25,21 +25,48 @@ func NewGetConfigCommand(configs *updatable_configs.Manager) *GetConfigCommand {
 // validatedGetConfigData represents a validated get-config request,
 // and includes the requested config field.
 type validatedGetConfigData struct {
-	field updatable_configs.Field
+	field      updatable_configs.Field
+	withSource bool
 }
 
 func (s *GetConfigCommand) Handler(_ context.Context, req *admin.CommandRequest) (interface{}, error) {
 	validatedReq := req.ValidatorData.(validatedGetConfigData)
 	curValue := validatedReq.field.Get()
-	return curValue, nil
+	if !validatedReq.withSource {
+		return curValue, nil
+	}
+
+	return map[string]any{
+		"value":  curValue,
+		"source": string(s.configs.Source(validatedReq.field.Name)),
+	}, nil
 }
 
-// Validator validates the request.
+// Validator validates the request. The data field is either the config name, or a map
+// with the config name and whether to include the source of the value, e.g.:
+// {"name": "profiler-enabled", "source": true}
 // Returns admin.InvalidAdminReqError for invalid/malformed requests.
 func (s *GetConfigCommand) Validator(req *admin.CommandRequest) error {
-	configName, ok := req.Data.(string)
-	if !ok {
-		return admin.NewInvalidAdminReqFormatError("the data field must be a string")
+	var configName string
+	var withSource bool
+	switch data := req.Data.(type) {
+	case string:
+		configName = data
+	case map[string]any:
+		name, ok := data["name"].(string)
+		if !ok {
+			return admin.NewInvalidAdminReqParameterError("name", "must be a string", data["name"])
+		}
+		configName = name
+
+		if source, ok := data["source"]; ok {
+			withSource, ok = source.(bool)
+			if !ok {
+				return admin.NewInvalidAdminReqParameterError("source", "must be a bool", source)
+			}
+		}
+	default:
+		return admin.NewInvalidAdminReqFormatError("the data field must be a string or a map")
 	}
 
 	field, ok := s.configs.GetField(configName)
@@ -50,7 +77,8 @@ func (s *GetConfigCommand) Validator(req *admin.CommandRequest) error {
 	// we have found a corresponding updatable config field, set it in the ValidatorData
 	// field - we will read it in the Handler
 	req.ValidatorData = validatedGetConfigData{
-		field: field,
+		field:      field,
+		withSource: withSource,
 	}
 
 	return nil
@@ -35,7 +35,8 @@ func (s *SetConfigCommand) Handler(_ context.Context, req *admin.CommandRequest)
 
 	oldValue := validatedReq.field.Get()
 
-	err := validatedReq.field.Set(validatedReq.value)
+	// the value is set through the config manager, so it is persisted if persistence is enabled
+	err := s.configs.Set(validatedReq.field.Name, validatedReq.value)
 	if err != nil {
 		if updatable_configs.IsValidationError(err) {
 			return nil, fmt.Errorf("config update failed due to invalid input: %w", err)
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/admin/commands"
+	"github.com/onflow/flow-go/module/updatable_configs"
+)
+
+var _ commands.AdminCommand = (*UnsetConfigCommand)(nil)
+
+// UnsetConfigCommand is an admin command which removes the persisted value of a dynamically
+// updatable config, and restores the value set by its default or command line flag.
+type UnsetConfigCommand struct {
+	configs *updatable_configs.Manager
+}
+
+func NewUnsetConfigCommand(configs *updatable_configs.Manager) *UnsetConfigCommand {
+	return &UnsetConfigCommand{
+		configs: configs,
+	}
+}
+
+func (s *UnsetConfigCommand) Handler(_ context.Context, req *admin.CommandRequest) (interface{}, error) {
+	field := req.ValidatorData.(updatable_configs.Field)
+
+	oldValue := field.Get()
+
+	err := s.configs.Unset(field.Name)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error unsetting config field %s: %w", field.Name, err)
+	}
+
+	res := map[string]any{
+		"oldValue": oldValue,
+		"newValue": field.Get(),
+		"source":   string(s.configs.Source(field.Name)),
+	}
+
+	return res, nil
+}
+
+// Validator validates the request. The data field is the config name.
+// Returns admin.InvalidAdminReqError for invalid/malformed requests.
+func (s *UnsetConfigCommand) Validator(req *admin.CommandRequest) error {
+	configName, ok := req.Data.(string)
+	if !ok {
+		return admin.NewInvalidAdminReqFormatError("the data field must be a string")
+	}
+
+	field, ok := s.configs.GetField(configName)
+	if !ok {
+		return admin.NewInvalidAdminReqErrorf("unknown config field: %s", configName)
+	}
+
+	req.ValidatorData = field
+	return nil
+}
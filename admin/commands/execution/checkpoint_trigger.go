@@ -2,6 +2,8 @@ package execution
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"go.uber.org/atomic"
@@ -10,28 +12,73 @@ import (
 	"github.com/onflow/flow-go/admin/commands"
 )
 
-var _ commands.AdminCommand = (*TriggerCheckpointCommand)(nil)
+var _ commands.AsyncAdminCommand = (*TriggerCheckpointCommand)(nil)
+
+// checkpointPollInterval is the interval at which the command checks whether the checkpoint was created.
+const checkpointPollInterval = 5 * time.Second
 
 // TriggerCheckpointCommand will send a signal to compactor to trigger checkpoint
-// once finishing writing the current WAL segment file
+// once finishing writing the current WAL segment file.
+// The command runs as a job which completes once the checkpoint has been created.
 type TriggerCheckpointCommand struct {
 	trigger *atomic.Bool
+	// latestCheckpoint returns the number of the latest checkpoint, or -1 if there are no checkpoints
+	latestCheckpoint func() (int, error)
+	pollInterval     time.Duration
 }
 
-func NewTriggerCheckpointCommand(trigger *atomic.Bool) *TriggerCheckpointCommand {
+func NewTriggerCheckpointCommand(trigger *atomic.Bool, latestCheckpoint func() (int, error)) *TriggerCheckpointCommand {
 	return &TriggerCheckpointCommand{
-		trigger: trigger,
+		trigger:          trigger,
+		latestCheckpoint: latestCheckpoint,
+		pollInterval:     checkpointPollInterval,
 	}
 }
 
-func (s *TriggerCheckpointCommand) Handler(_ context.Context, _ *admin.CommandRequest) (interface{}, error) {
-	if s.trigger.CompareAndSwap(false, true) {
+func (s *TriggerCheckpointCommand) Handler(ctx context.Context, req *admin.CommandRequest) (interface{}, error) {
+	previous, err := s.latestCheckpoint()
+	if err != nil {
+		return nil, fmt.Errorf("could not get latest checkpoint: %w", err)
+	}
+
+	triggered := s.trigger.CompareAndSwap(false, true)
+	if triggered {
 		log.Info().Msgf("admintool: trigger checkpoint as soon as finishing writing the current segment file. you can find log about 'compactor' to check the checkpointing progress")
 	} else {
 		log.Info().Msgf("admintool: checkpoint is already set to be triggered")
 	}
+	req.ReportProgress(fmt.Sprintf("waiting for the current segment file to be finished, latest checkpoint is %d", previous))
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// withdraw the signal if the compactor has not picked it up yet
+			if triggered && s.trigger.CompareAndSwap(true, false) {
+				log.Info().Msgf("admintool: checkpoint trigger canceled")
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		latest, err := s.latestCheckpoint()
+		if err != nil {
+			return nil, fmt.Errorf("could not get latest checkpoint: %w", err)
+		}
+		if latest > previous {
+			return map[string]interface{}{"checkpoint": latest}, nil
+		}
+		if !s.trigger.Load() {
+			req.ReportProgress(fmt.Sprintf("checkpoint triggered, waiting for checkpoint %d to be created", previous+1))
+		}
+	}
+}
 
-	return "ok", nil
+// IsAsync returns true, as creating the checkpoint can take a long time.
+func (s *TriggerCheckpointCommand) IsAsync() bool {
+	return true
 }
 
 func (s *TriggerCheckpointCommand) Validator(_ *admin.CommandRequest) error {
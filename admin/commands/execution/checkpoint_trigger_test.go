@@ -0,0 +1,53 @@
+package execution
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/onflow/flow-go/admin"
+)
+
+func TestTriggerCheckpoint(t *testing.T) {
+	t.Run("completes once the checkpoint is created", func(t *testing.T) {
+		trigger := atomic.NewBool(false)
+		latest := atomic.NewInt64(3)
+		cmd := NewTriggerCheckpointCommand(trigger, func() (int, error) {
+			return int(latest.Load()), nil
+		})
+		cmd.pollInterval = time.Millisecond
+
+		// the compactor picks up the signal and creates the next checkpoint
+		go func() {
+			assert.Eventually(t, trigger.Load, time.Second, time.Millisecond)
+			trigger.Store(false)
+			latest.Store(4)
+		}()
+
+		result, err := cmd.Handler(context.Background(), &admin.CommandRequest{})
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"checkpoint": 4}, result)
+	})
+
+	t.Run("cancel withdraws the trigger", func(t *testing.T) {
+		trigger := atomic.NewBool(false)
+		cmd := NewTriggerCheckpointCommand(trigger, func() (int, error) {
+			return -1, nil
+		})
+		cmd.pollInterval = time.Millisecond
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			assert.Eventually(t, trigger.Load, time.Second, time.Millisecond)
+			cancel()
+		}()
+
+		_, err := cmd.Handler(ctx, &admin.CommandRequest{})
+		require.ErrorIs(t, err, context.Canceled)
+		require.False(t, trigger.Load())
+	})
+}
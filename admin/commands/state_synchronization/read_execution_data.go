@@ -11,7 +11,7 @@ import (
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 )
 
-var _ commands.AdminCommand = (*ReadExecutionDataCommand)(nil)
+var _ commands.AsyncAdminCommand = (*ReadExecutionDataCommand)(nil)
 
 type requestData struct {
 	rootID flow.Identifier
@@ -24,6 +24,7 @@ type ReadExecutionDataCommand struct {
 func (r *ReadExecutionDataCommand) Handler(ctx context.Context, req *admin.CommandRequest) (interface{}, error) {
 	data := req.ValidatorData.(*requestData)
 
+	req.ReportProgress("fetching execution data")
 	ed, err := r.executionDataStore.GetExecutionData(ctx, data.rootID)
 
 	if err != nil {
@@ -33,6 +34,11 @@ func (r *ReadExecutionDataCommand) Handler(ctx context.Context, req *admin.Comma
 	return commands.ConvertToMap(ed)
 }
 
+// IsAsync returns true, as the execution data might have to be read from many blobs.
+func (r *ReadExecutionDataCommand) IsAsync() bool {
+	return true
+}
+
 // Validator validates the request.
 // Returns admin.InvalidAdminReqError for invalid/malformed requests.
 func (r *ReadExecutionDataCommand) Validator(req *admin.CommandRequest) error {
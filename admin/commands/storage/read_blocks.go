@@ -11,7 +11,7 @@ import (
 	"github.com/onflow/flow-go/storage"
 )
 
-var _ commands.AdminCommand = (*ReadBlocksCommand)(nil)
+var _ commands.AsyncAdminCommand = (*ReadBlocksCommand)(nil)
 
 type readBlocksRequest struct {
 	blocksRequest    *blocksRequest
@@ -35,6 +35,13 @@ func (r *ReadBlocksCommand) Handler(ctx context.Context, req *admin.CommandReque
 	}
 
 	for i := uint64(0); i < data.numBlocksToQuery; i++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		req.ReportProgress(fmt.Sprintf("read %d of %d blocks", i, data.numBlocksToQuery))
+
 		block, err := r.blocks.ByID(blockID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get block by ID: %w", err)
@@ -49,6 +56,11 @@ func (r *ReadBlocksCommand) Handler(ctx context.Context, req *admin.CommandReque
 	return commands.ConvertToInterfaceList(result)
 }
 
+// IsAsync returns true, as reading many blocks can take a long time.
+func (r *ReadBlocksCommand) IsAsync() bool {
+	return true
+}
+
 // Validator validates the request.
 // Returns admin.InvalidAdminReqError for invalid/malformed requests.
 func (r *ReadBlocksCommand) Validator(req *admin.CommandRequest) error {
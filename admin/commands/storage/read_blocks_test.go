@@ -251,3 +251,18 @@ func (suite *ReadBlocksSuite) TestHandleNExceedsRootBlock() {
 	require.Len(suite.T(), responseBlocks, len(suite.allBlocks))
 	require.ElementsMatch(suite.T(), responseBlocks, suite.allBlocks)
 }
+
+func (suite *ReadBlocksSuite) TestHandleCanceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := &admin.CommandRequest{
+		Data: map[string]interface{}{
+			"block": "final",
+			"n":     float64(len(suite.allBlocks)),
+		},
+	}
+	require.NoError(suite.T(), suite.command.Validator(req))
+	_, err := suite.command.Handler(ctx, req)
+	require.ErrorIs(suite.T(), err, context.Canceled)
+}
@@ -0,0 +1,224 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMaxFinishedJobs is the default number of finished jobs kept in the job history.
+const DefaultMaxFinishedJobs = 100
+
+// JobStatus is the status of an async admin command job.
+type JobStatus string
+
+const (
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+	JobStatusCanceled  JobStatus = "canceled"
+)
+
+// ErrJobNotFound is returned when a job is neither running nor in the job history.
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrJobFinished is returned when canceling a job which has already finished.
+var ErrJobFinished = errors.New("job already finished")
+
+// job is a single execution of an async admin command.
+type job struct {
+	id         string
+	command    string
+	cancel     context.CancelFunc
+	startedAt  time.Time
+	mu         sync.RWMutex
+	status     JobStatus
+	progress   string
+	result     interface{}
+	err        error
+	finishedAt time.Time
+}
+
+func (j *job) reportProgress(progress string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.progress = progress
+}
+
+// code returns the gRPC status code of the finished job, as it would be returned for a synchronous
+// command. The caller must hold the job's lock.
+func (j *job) code() codes.Code {
+	switch j.status {
+	case JobStatusSucceeded:
+		return codes.OK
+	case JobStatusCanceled:
+		return codes.Canceled
+	}
+
+	if errors.Is(j.err, context.DeadlineExceeded) {
+		return codes.DeadlineExceeded
+	}
+	return status.Code(j.err)
+}
+
+// summary returns the status of the job as a value which can be returned by an admin command.
+// The result of the job is only included if withResult is true.
+func (j *job) summary(withResult bool) map[string]interface{} {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	summary := map[string]interface{}{
+		"id":         j.id,
+		"command":    j.command,
+		"status":     string(j.status),
+		"progress":   j.progress,
+		"started_at": j.startedAt.UTC().Format(time.RFC3339),
+	}
+	if j.status != JobStatusRunning {
+		summary["finished_at"] = j.finishedAt.UTC().Format(time.RFC3339)
+	}
+	if j.err != nil {
+		summary["error"] = j.err.Error()
+	}
+	if withResult && j.result != nil {
+		summary["result"] = j.result
+	}
+	return summary
+}
+
+// jobs tracks the running async admin command jobs, and keeps a bounded history of finished jobs.
+type jobs struct {
+	mu          sync.Mutex
+	running     map[string]*job
+	finished    []*job // oldest first
+	maxFinished int
+}
+
+func newJobs(maxFinished int) *jobs {
+	return &jobs{
+		running:     make(map[string]*job),
+		maxFinished: maxFinished,
+	}
+}
+
+// start runs the given handler in a new job, and returns the ID of the job.
+// The job is canceled when the given context is canceled, or when the job is canceled through cancel.
+// onDone is called once the handler has returned.
+func (js *jobs) start(
+	ctx context.Context,
+	command string,
+	req *CommandRequest,
+	handler CommandHandler,
+	onDone func(j *job),
+) string {
+	jobCtx, cancel := context.WithCancel(ctx)
+	j := &job{
+		id:        uuid.New().String(),
+		command:   command,
+		cancel:    cancel,
+		startedAt: time.Now(),
+		status:    JobStatusRunning,
+	}
+	req.job = j
+
+	js.mu.Lock()
+	js.running[j.id] = j
+	js.mu.Unlock()
+
+	go func() {
+		defer cancel()
+
+		result, err := handler(jobCtx, req)
+		js.finish(j, result, err, jobCtx.Err() != nil)
+		onDone(j)
+	}()
+
+	return j.id
+}
+
+func (js *jobs) finish(j *job, result interface{}, err error, canceled bool) {
+	j.mu.Lock()
+	j.finishedAt = time.Now()
+	switch {
+	case err == nil:
+		j.status = JobStatusSucceeded
+		j.result = result
+	case canceled && errors.Is(err, context.Canceled):
+		j.status = JobStatusCanceled
+		j.err = err
+	default:
+		j.status = JobStatusFailed
+		j.err = err
+	}
+	j.mu.Unlock()
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	delete(js.running, j.id)
+	js.finished = append(js.finished, j)
+	if len(js.finished) > js.maxFinished {
+		js.finished = js.finished[len(js.finished)-js.maxFinished:]
+	}
+}
+
+// get returns the job with the given ID.
+// Expected errors during normal operation:
+//   - ErrJobNotFound if the job is neither running nor in the job history
+func (js *jobs) get(id string) (*job, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if j, ok := js.running[id]; ok {
+		return j, nil
+	}
+	for _, j := range js.finished {
+		if j.id == id {
+			return j, nil
+		}
+	}
+	return nil, ErrJobNotFound
+}
+
+// list returns all running jobs and the job history, ordered by start time.
+func (js *jobs) list() []*job {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	all := make([]*job, 0, len(js.running)+len(js.finished))
+	for _, j := range js.running {
+		all = append(all, j)
+	}
+	all = append(all, js.finished...)
+
+	sort.SliceStable(all, func(i, k int) bool {
+		return all[i].startedAt.Before(all[k].startedAt)
+	})
+	return all
+}
+
+// cancelJob cancels the running job with the given ID. The job is finished once its handler returns.
+// Expected errors during normal operation:
+//   - ErrJobNotFound if the job is neither running nor in the job history
+//   - ErrJobFinished if the job has already finished
+func (js *jobs) cancelJob(id string) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if j, ok := js.running[id]; ok {
+		j.cancel()
+		return nil
+	}
+	for _, j := range js.finished {
+		if j.id == id {
+			return ErrJobFinished
+		}
+	}
+	return ErrJobNotFound
+}
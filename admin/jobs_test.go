@@ -0,0 +1,188 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// newTestCommandRunner returns a command runner which runs commands without starting the admin server.
+func newTestCommandRunner(t *testing.T, bootstrapper *CommandRunnerBootstrapper, opts ...CommandRunnerOption) *CommandRunner {
+	runner := bootstrapper.Bootstrap(unittest.Logger(), "", opts...)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	runner.jobsCtx = ctx
+	return runner
+}
+
+func runJobCommand(t *testing.T, runner *CommandRunner, command string, data interface{}) (interface{}, error) {
+	return runner.runCommand(context.Background(), command, data)
+}
+
+func getJob(t *testing.T, runner *CommandRunner, id string) map[string]interface{} {
+	result, err := runJobCommand(t, runner, "get-job", id)
+	require.NoError(t, err)
+	return result.(map[string]interface{})
+}
+
+func waitForJobStatus(t *testing.T, runner *CommandRunner, id string, expected JobStatus) map[string]interface{} {
+	var summary map[string]interface{}
+	require.Eventually(t, func() bool {
+		summary = getJob(t, runner, id)
+		return summary["status"] == string(expected)
+	}, time.Second, 10*time.Millisecond)
+	return summary
+}
+
+func TestAsyncCommand(t *testing.T) {
+	bootstrapper := NewCommandRunnerBootstrapper()
+
+	proceed := make(chan struct{})
+	bootstrapper.RegisterAsyncHandler("slow", func(ctx context.Context, req *CommandRequest) (interface{}, error) {
+		req.ReportProgress("halfway")
+		select {
+		case <-proceed:
+			return req.Data, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+	bootstrapper.RegisterValidator("slow", func(req *CommandRequest) error {
+		if _, ok := req.Data.(string); !ok {
+			return NewInvalidAdminReqFormatError("expected string")
+		}
+		return nil
+	})
+	runner := newTestCommandRunner(t, bootstrapper)
+
+	t.Run("validation errors are returned by the request", func(t *testing.T) {
+		_, err := runJobCommand(t, runner, "slow", 1)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("job result", func(t *testing.T) {
+		result, err := runJobCommand(t, runner, "slow", "done")
+		require.NoError(t, err)
+		id := result.(map[string]interface{})["job_id"].(string)
+
+		require.Eventually(t, func() bool {
+			return getJob(t, runner, id)["progress"] == "halfway"
+		}, time.Second, 10*time.Millisecond)
+		summary := getJob(t, runner, id)
+		assert.Equal(t, string(JobStatusRunning), summary["status"])
+		assert.Equal(t, "slow", summary["command"])
+
+		proceed <- struct{}{}
+		summary = waitForJobStatus(t, runner, id, JobStatusSucceeded)
+		assert.Equal(t, "done", summary["result"])
+		assert.Contains(t, summary, "finished_at")
+
+		// the result is only returned for a single job
+		list, err := runJobCommand(t, runner, "list-jobs", nil)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.NotContains(t, list.([]interface{})[0], "result")
+
+		_, err = runJobCommand(t, runner, "cancel-job", id)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("cancel job", func(t *testing.T) {
+		result, err := runJobCommand(t, runner, "slow", "canceled")
+		require.NoError(t, err)
+		id := result.(map[string]interface{})["job_id"].(string)
+
+		_, err = runJobCommand(t, runner, "cancel-job", id)
+		require.NoError(t, err)
+
+		summary := waitForJobStatus(t, runner, id, JobStatusCanceled)
+		assert.Equal(t, context.Canceled.Error(), summary["error"])
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		_, err := runJobCommand(t, runner, "get-job", "unknown")
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		_, err = runJobCommand(t, runner, "cancel-job", "unknown")
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		_, err = runJobCommand(t, runner, "get-job", 1)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestJobHistory(t *testing.T) {
+	bootstrapper := NewCommandRunnerBootstrapper()
+	bootstrapper.RegisterAsyncHandler("fail", func(ctx context.Context, req *CommandRequest) (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+	runner := newTestCommandRunner(t, bootstrapper, WithMaxFinishedJobs(2))
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		result, err := runJobCommand(t, runner, "fail", nil)
+		require.NoError(t, err)
+		id := result.(map[string]interface{})["job_id"].(string)
+		summary := waitForJobStatus(t, runner, id, JobStatusFailed)
+		assert.Equal(t, "failed", summary["error"])
+		ids = append(ids, id)
+	}
+
+	// only the latest jobs are kept
+	_, err := runJobCommand(t, runner, "get-job", ids[0])
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	list, err := runJobCommand(t, runner, "list-jobs", nil)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, ids[1], list.([]interface{})[0].(map[string]interface{})["id"])
+	assert.Equal(t, ids[2], list.([]interface{})[1].(map[string]interface{})["id"])
+}
+
+// TestStopJobs tests that jobs started concurrently with the shutdown of the runner are either
+// refused, or waited for before the runner is done.
+func TestStopJobs(t *testing.T) {
+	bootstrapper := NewCommandRunnerBootstrapper()
+	bootstrapper.RegisterAsyncHandler("wait", func(ctx context.Context, req *CommandRequest) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	runner := bootstrapper.Bootstrap(unittest.Logger(), "")
+	ctx, cancel := context.WithCancel(context.Background())
+	runner.jobsCtx = ctx
+
+	var wg sync.WaitGroup
+	ids := make(chan string, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := runJobCommand(t, runner, "wait", nil)
+			if err != nil {
+				assert.Equal(t, codes.Unavailable, status.Code(err))
+				return
+			}
+			ids <- result.(map[string]interface{})["job_id"].(string)
+		}()
+	}
+
+	cancel()
+	runner.stopJobs()
+
+	// all jobs which were started have finished
+	wg.Wait()
+	close(ids)
+	for id := range ids {
+		assert.Equal(t, string(JobStatusCanceled), getJob(t, runner, id)["status"])
+	}
+
+	_, err := runJobCommand(t, runner, "wait", nil)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
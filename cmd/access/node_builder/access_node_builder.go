@@ -35,6 +35,7 @@ import (
 	"github.com/onflow/flow-go/consensus/hotstuff/verification"
 	recovery "github.com/onflow/flow-go/consensus/recovery/protocol"
 	"github.com/onflow/flow-go/crypto"
+	"github.com/onflow/flow-go/engine/access/apikey"
 	"github.com/onflow/flow-go/engine/access/ingestion"
 	pingeng "github.com/onflow/flow-go/engine/access/ping"
 	"github.com/onflow/flow-go/engine/access/rpc"
@@ -49,13 +50,16 @@ import (
 	"github.com/onflow/flow-go/module/blobs"
 	"github.com/onflow/flow-go/module/chainsync"
 	modulecompliance "github.com/onflow/flow-go/module/compliance"
+	"github.com/onflow/flow-go/module/executiondatasync/archive"
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 	finalizer "github.com/onflow/flow-go/module/finalizer/consensus"
 	"github.com/onflow/flow-go/module/id"
+	"github.com/onflow/flow-go/module/jobqueue"
 	"github.com/onflow/flow-go/module/mempool/stdmap"
 	"github.com/onflow/flow-go/module/metrics"
 	"github.com/onflow/flow-go/module/metrics/unstaked"
 	"github.com/onflow/flow-go/module/state_synchronization"
+	"github.com/onflow/flow-go/module/state_synchronization/indexer"
 	edrequester "github.com/onflow/flow-go/module/state_synchronization/requester"
 	"github.com/onflow/flow-go/network"
 	netcache "github.com/onflow/flow-go/network/cache"
@@ -112,6 +116,8 @@ type AccessNodeConfig struct {
 	nodeInfoFile                 string
 	apiRatelimits                map[string]int
 	apiBurstlimits               map[string]int
+	apiKeyConfigFile             string
+	apiKeyReloadInterval         time.Duration
 	rpcConf                      rpc.Config
 	stateStreamConf              state_stream.Config
 	stateStreamFilterConf        map[string]int
@@ -124,8 +130,15 @@ type AccessNodeConfig struct {
 	rpcMetricsEnabled            bool
 	executionDataSyncEnabled     bool
 	executionDataDir             string
+	executionDataArchiveDir      string
+	executionDataArchiveFile     string
 	executionDataStartHeight     uint64
 	executionDataConfig          edrequester.ExecutionDataConfig
+	executionDataIndexingEnabled bool
+	pruningRetention             map[string]int
+	pruningInterval              time.Duration
+	pruningBatchSize             uint64
+	headerFirstSyncConfig        chainsync.HeaderFirstConfig
 	PublicNetworkConfig          PublicNetworkConfig
 }
 
@@ -178,6 +191,8 @@ func DefaultAccessNodeConfig() *AccessNodeConfig {
 		nodeInfoFile:                 "",
 		apiRatelimits:                nil,
 		apiBurstlimits:               nil,
+		apiKeyConfigFile:             "",
+		apiKeyReloadInterval:         apikey.DefaultReloadInterval,
 		PublicNetworkConfig: PublicNetworkConfig{
 			BindAddress: cmd.NotSet,
 			Metrics:     metrics.NewNoopCollector(),
@@ -192,7 +207,14 @@ func DefaultAccessNodeConfig() *AccessNodeConfig {
 			MaxFetchTimeout:    edrequester.DefaultMaxFetchTimeout,
 			RetryDelay:         edrequester.DefaultRetryDelay,
 			MaxRetryDelay:      edrequester.DefaultMaxRetryDelay,
+			BlockJobTimeout:    jobqueue.DefaultJobTimeoutConfig(),
+			CatchUp:            edrequester.DefaultCatchUpConfig(),
 		},
+		executionDataIndexingEnabled: false,
+		pruningRetention:             nil,
+		pruningInterval:              time.Minute,
+		pruningBatchSize:             100,
+		headerFirstSyncConfig:        chainsync.DefaultHeaderFirstConfig(),
 	}
 }
 
@@ -224,6 +246,7 @@ type FlowAccessNodeBuilder struct {
 	ExecutionDataDownloader    execution_data.Downloader
 	ExecutionDataRequester     state_synchronization.ExecutionDataRequester
 	ExecutionDataStore         execution_data.ExecutionDataStore
+	ExecutionDataIndexer       *indexer.Indexer
 
 	// The sync engine participants provider is the libp2p peer store for the access node
 	// which is not available until after the network has started.
@@ -377,6 +400,18 @@ func (builder *FlowAccessNodeBuilder) buildFollowerEngine() *FlowAccessNodeBuild
 
 func (builder *FlowAccessNodeBuilder) buildSyncEngine() *FlowAccessNodeBuilder {
 	builder.Component("sync engine", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+		var opts []synceng.OptionFunc
+		if builder.headerFirstSyncConfig.Threshold > 0 {
+			headerFirst := chainsync.NewHeaderFirst(
+				node.Logger,
+				builder.headerFirstSyncConfig,
+				builder.Validator,
+				metrics.NewHeaderFirstSyncCollector(),
+				builder.Finalized,
+			)
+			opts = append(opts, synceng.WithHeaderFirst(headerFirst))
+		}
+
 		sync, err := synceng.New(
 			node.Logger,
 			node.Metrics.Engine,
@@ -387,6 +422,7 @@ func (builder *FlowAccessNodeBuilder) buildSyncEngine() *FlowAccessNodeBuilder {
 			builder.FollowerEng,
 			builder.SyncCore,
 			builder.SyncEngineParticipantsProviderFactory(),
+			opts...,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("could not create synchronization engine: %w", err)
@@ -465,6 +501,104 @@ func (builder *FlowAccessNodeBuilder) BuildExecutionDataRequester() *FlowAccessN
 			builder.ExecutionDataStore = execution_data.NewExecutionDataStore(blobstore, execution_data.DefaultSerializer)
 			return nil
 		}).
+		Module("execution data archive", func(node *cmd.NodeConfig) error {
+			if builder.executionDataArchiveFile != "" {
+				file, err := os.Open(builder.executionDataArchiveFile)
+				if err != nil {
+					return fmt.Errorf("could not open execution data archive: %w", err)
+				}
+
+				builder.ShutdownFunc(func() error {
+					if err := file.Close(); err != nil {
+						return fmt.Errorf("could not close execution data archive: %w", err)
+					}
+					return nil
+				})
+
+				info, err := file.Stat()
+				if err != nil {
+					return fmt.Errorf("could not stat execution data archive: %w", err)
+				}
+
+				reader, err := archive.NewReader(file, info.Size())
+				if err != nil {
+					return fmt.Errorf("could not read execution data archive: %w", err)
+				}
+
+				builder.executionDataConfig.CatchUp.Archive = edrequester.NewFileArchive(reader, blobs.NewBlobstore(ds))
+
+				return nil
+			}
+
+			if builder.executionDataArchiveDir == "" {
+				return nil
+			}
+
+			opts := badger.DefaultOptions
+			opts.ReadOnly = true
+			opts.GcInterval = 0 // garbage collection is not supported by read-only databases
+
+			archiveDs, err := badger.NewDatastore(builder.executionDataArchiveDir, &opts)
+			if err != nil {
+				return fmt.Errorf("could not open execution data archive: %w", err)
+			}
+
+			builder.ShutdownFunc(func() error {
+				if err := archiveDs.Close(); err != nil {
+					return fmt.Errorf("could not close execution data archive: %w", err)
+				}
+				return nil
+			})
+
+			archiveStore := execution_data.NewExecutionDataStore(blobs.NewBlobstore(archiveDs), execution_data.DefaultSerializer)
+			builder.executionDataConfig.CatchUp.Archive = edrequester.NewStoreArchive(archiveStore, builder.ExecutionDataStore)
+
+			return nil
+		}).
+		Module("execution data indexer", func(node *cmd.NodeConfig) error {
+			if !builder.executionDataIndexingEnabled {
+				return nil
+			}
+
+			node.Storage.Events = bstorage.NewEvents(node.Metrics.Cache, node.DB)
+			node.Storage.TransactionResults = bstorage.NewTransactionResults(node.Metrics.Cache, node.DB, bstorage.DefaultCacheSize)
+
+			// index the same heights the requester downloads
+			initHeight := builder.RootBlock.Header.Height
+			if builder.executionDataStartHeight > 0 {
+				initHeight = builder.executionDataStartHeight - 1
+			}
+
+			// execution data has been downloaded up to the height the requester last notified about
+			highestAvailableHeight, err := processedNotifications.ProcessedIndex()
+			if errors.Is(err, storage.ErrNotFound) {
+				highestAvailableHeight = initHeight
+			} else if err != nil {
+				return fmt.Errorf("could not get highest notified execution data height: %w", err)
+			}
+
+			builder.ExecutionDataIndexer = indexer.NewIndexer(
+				node.Logger,
+				initHeight,
+				highestAvailableHeight,
+				indexer.NewIndexerCore(
+					node.Logger,
+					node.DB,
+					node.Storage.Headers,
+					node.Storage.Events,
+					node.Storage.TransactionResults,
+					node.Storage.Collections,
+					node.Storage.Transactions,
+					nil,
+				),
+				builder.ExecutionDataStore,
+				node.Storage.Headers,
+				node.Storage.Results,
+				node.Storage.Seals,
+				bstorage.NewConsumerProgress(node.DB, module.ConsumeProgressExecutionDataIndexerBlockHeight),
+			)
+			return nil
+		}).
 		Component("execution data service", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
 
 			opts := []network.BlobServiceOption{
@@ -542,8 +676,17 @@ func (builder *FlowAccessNodeBuilder) BuildExecutionDataRequester() *FlowAccessN
 
 			builder.FollowerDistributor.AddOnBlockFinalizedConsumer(builder.ExecutionDataRequester.OnBlockFinalized)
 			builder.ExecutionDataRequester.AddOnExecutionDataReceivedConsumer(execDataDistributor.OnExecutionDataReceived)
+			if builder.ExecutionDataIndexer != nil {
+				builder.ExecutionDataRequester.AddOnExecutionDataReceivedConsumer(builder.ExecutionDataIndexer.OnExecutionData)
+			}
 
 			return builder.ExecutionDataRequester, nil
+		}).
+		Component("execution data indexer", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+			if builder.ExecutionDataIndexer == nil {
+				return &module.NoopComponent{}, nil
+			}
+			return builder.ExecutionDataIndexer, nil
 		})
 
 	if builder.stateStreamConf.ListenAddr != "" {
@@ -559,6 +702,7 @@ func (builder *FlowAccessNodeBuilder) BuildExecutionDataRequester() *FlowAccessN
 				}
 			}
 			builder.stateStreamConf.RpcMetricsEnabled = builder.rpcMetricsEnabled
+			builder.stateStreamConf.APIKeys = builder.rpcConf.APIKeys
 
 			var heroCacheCollector module.HeroCacheMetrics = metrics.NewNoopCollector()
 			if builder.HeroCacheMetricsEnable {
@@ -642,6 +786,8 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 		flags.StringVarP(&builder.nodeInfoFile, "node-info-file", "", defaultConfig.nodeInfoFile, "full path to a json file which provides more details about nodes when reporting its reachability metrics")
 		flags.StringToIntVar(&builder.apiRatelimits, "api-rate-limits", defaultConfig.apiRatelimits, "per second rate limits for Access API methods e.g. Ping=300,GetTransaction=500 etc.")
 		flags.StringToIntVar(&builder.apiBurstlimits, "api-burst-limits", defaultConfig.apiBurstlimits, "burst limits for Access API methods e.g. Ping=100,GetTransaction=100 etc.")
+		flags.StringVar(&builder.apiKeyConfigFile, "api-key-config", defaultConfig.apiKeyConfigFile, "path to a json file defining the API keys and their per method class quotas. when set, all gRPC and REST requests must carry a valid key in the X-API-Key header")
+		flags.DurationVar(&builder.apiKeyReloadInterval, "api-key-reload-interval", defaultConfig.apiKeyReloadInterval, "interval at which the API key config file is checked for changes and reloaded")
 		flags.BoolVar(&builder.supportsObserver, "supports-observer", defaultConfig.supportsObserver, "true if this staked access node supports observer or follower connections")
 		flags.StringVar(&builder.PublicNetworkConfig.BindAddress, "public-network-address", defaultConfig.PublicNetworkConfig.BindAddress, "staked access node's public network bind address")
 
@@ -654,6 +800,29 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 		flags.DurationVar(&builder.executionDataConfig.MaxFetchTimeout, "execution-data-max-fetch-timeout", defaultConfig.executionDataConfig.MaxFetchTimeout, "maximum timeout to use when fetching execution data from the network e.g. 300s")
 		flags.DurationVar(&builder.executionDataConfig.RetryDelay, "execution-data-retry-delay", defaultConfig.executionDataConfig.RetryDelay, "initial delay for exponential backoff when fetching execution data fails e.g. 10s")
 		flags.DurationVar(&builder.executionDataConfig.MaxRetryDelay, "execution-data-max-retry-delay", defaultConfig.executionDataConfig.MaxRetryDelay, "maximum delay for exponential backoff when fetching execution data fails e.g. 5m")
+		flags.DurationVar(&builder.executionDataConfig.BlockJobTimeout.Timeout, "execution-data-job-timeout", defaultConfig.executionDataConfig.BlockJobTimeout.Timeout, "time downloading the execution data of a block may take before it is retried, 0 to disable e.g. 30m")
+		flags.Uint64Var(&builder.executionDataConfig.CatchUp.Threshold, "execution-data-catch-up-threshold", defaultConfig.executionDataConfig.CatchUp.Threshold, "number of heights behind the latest sealed block at which execution data is downloaded in parallel ranges, 0 to disable")
+		flags.Uint64Var(&builder.executionDataConfig.CatchUp.RangeSize, "execution-data-catch-up-range-size", defaultConfig.executionDataConfig.CatchUp.RangeSize, "number of consecutive heights downloaded at a time by a catch-up worker")
+		flags.IntVar(&builder.executionDataConfig.CatchUp.MinWorkers, "execution-data-catch-up-min-workers", defaultConfig.executionDataConfig.CatchUp.MinWorkers, "minimum number of height ranges downloaded in parallel when catching up")
+		flags.IntVar(&builder.executionDataConfig.CatchUp.MaxWorkers, "execution-data-catch-up-max-workers", defaultConfig.executionDataConfig.CatchUp.MaxWorkers, "maximum number of height ranges downloaded in parallel when catching up")
+		flags.StringVar(&builder.executionDataArchiveDir, "execution-data-archive-dir", defaultConfig.executionDataArchiveDir, "execution data blobstore database of another node (e.g. a copy of its execution-data-dir/blobstore) to import execution data from when catching up, instead of downloading it")
+		flags.StringVar(&builder.executionDataArchiveFile, "execution-data-archive-file", defaultConfig.executionDataArchiveFile, "execution data archive file (e.g. written by the export-execution-data util command) to import execution data from when catching up, instead of downloading it")
+		flags.BoolVar(&builder.executionDataIndexingEnabled, "execution-data-indexing-enabled", defaultConfig.executionDataIndexingEnabled, "whether to index events, transaction results and collections from the downloaded execution data, and serve them without querying execution nodes")
+
+		// Protocol data pruning
+		flags.StringToIntVar(&builder.pruningRetention, "pruning-retention", defaultConfig.pruningRetention, "number of heights below the latest sealed height to retain per category of protocol data, "+
+			"e.g. collections=100000,transaction_results=100000. categories which are not listed are not pruned. by default, nothing is pruned")
+		flags.DurationVar(&builder.pruningInterval, "pruning-interval", defaultConfig.pruningInterval, "interval at which protocol data is pruned")
+		flags.Uint64Var(&builder.pruningBatchSize, "pruning-batch-size", defaultConfig.pruningBatchSize, "number of heights of protocol data pruned in a single database write batch")
+
+		// Header-first chain sync
+		flags.Uint64Var(&builder.headerFirstSyncConfig.Threshold, "sync-header-first-threshold", defaultConfig.headerFirstSyncConfig.Threshold, "number of heights behind the finalized height reported by peers at which the chain is synced header-first, with block payloads downloaded in parallel, 0 to disable")
+		flags.UintVar(&builder.headerFirstSyncConfig.HeaderBatchSize, "sync-header-first-header-batch-size", defaultConfig.headerFirstSyncConfig.HeaderBatchSize, "maximum number of headers requested in the same header range request")
+		flags.UintVar(&builder.headerFirstSyncConfig.MaxHeadersAhead, "sync-header-first-max-headers-ahead", defaultConfig.headerFirstSyncConfig.MaxHeadersAhead, "maximum number of validated headers above the local finalized height")
+		flags.UintVar(&builder.headerFirstSyncConfig.BodyBatchSize, "sync-header-first-body-batch-size", defaultConfig.headerFirstSyncConfig.BodyBatchSize, "maximum number of block payloads requested in the same request")
+		flags.UintVar(&builder.headerFirstSyncConfig.MaxBodyRequests, "sync-header-first-max-body-requests", defaultConfig.headerFirstSyncConfig.MaxBodyRequests, "maximum number of block payload requests in flight, each sent to a different peer")
+		flags.DurationVar(&builder.headerFirstSyncConfig.RetryInterval, "sync-header-first-retry-interval", defaultConfig.headerFirstSyncConfig.RetryInterval, "interval before a header range or block payload request is retried e.g. 4s")
+		flags.DurationVar(&builder.headerFirstSyncConfig.StallTimeout, "sync-header-first-stall-timeout", defaultConfig.headerFirstSyncConfig.StallTimeout, "time without finalization progress after which header-first sync falls back to range requests for the same duration e.g. 1m")
 
 		// Execution State Streaming API
 		flags.Uint32Var(&builder.stateStreamConf.ExecutionDataCacheSize, "execution-data-cache-size", defaultConfig.stateStreamConf.ExecutionDataCacheSize, "block execution data cache size")
@@ -682,6 +851,49 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 			if builder.executionDataConfig.MaxSearchAhead == 0 {
 				return errors.New("execution-data-max-search-ahead must be greater than 0")
 			}
+			if builder.executionDataConfig.CatchUp.Threshold > 0 {
+				if builder.executionDataConfig.CatchUp.RangeSize == 0 {
+					return errors.New("execution-data-catch-up-range-size must be greater than 0")
+				}
+				if builder.executionDataConfig.CatchUp.MinWorkers <= 0 {
+					return errors.New("execution-data-catch-up-min-workers must be greater than 0")
+				}
+				if builder.executionDataConfig.CatchUp.MaxWorkers < builder.executionDataConfig.CatchUp.MinWorkers {
+					return errors.New("execution-data-catch-up-max-workers must be greater than or equal to execution-data-catch-up-min-workers")
+				}
+				if builder.executionDataArchiveDir != "" && builder.executionDataArchiveFile != "" {
+					return errors.New("execution-data-archive-dir and execution-data-archive-file cannot both be set")
+				}
+			} else if builder.executionDataArchiveDir != "" {
+				return errors.New("execution-data-archive-dir requires execution-data-catch-up-threshold")
+			} else if builder.executionDataArchiveFile != "" {
+				return errors.New("execution-data-archive-file requires execution-data-catch-up-threshold")
+			}
+		} else if builder.executionDataIndexingEnabled {
+			return errors.New("execution-data-indexing-enabled requires execution-data-sync-enabled")
+		}
+		if builder.headerFirstSyncConfig.Threshold > 0 {
+			if builder.headerFirstSyncConfig.HeaderBatchSize < 2 {
+				return errors.New("sync-header-first-header-batch-size must be at least 2")
+			}
+			if builder.headerFirstSyncConfig.HeaderBatchSize > chainsync.DefaultHeaderFirstConfig().HeaderBatchSize {
+				return fmt.Errorf("sync-header-first-header-batch-size must be at most %d", chainsync.DefaultHeaderFirstConfig().HeaderBatchSize)
+			}
+			if builder.headerFirstSyncConfig.MaxHeadersAhead == 0 {
+				return errors.New("sync-header-first-max-headers-ahead must be greater than 0")
+			}
+			if builder.headerFirstSyncConfig.BodyBatchSize == 0 {
+				return errors.New("sync-header-first-body-batch-size must be greater than 0")
+			}
+			if builder.headerFirstSyncConfig.MaxBodyRequests == 0 {
+				return errors.New("sync-header-first-max-body-requests must be greater than 0")
+			}
+			if builder.headerFirstSyncConfig.RetryInterval <= 0 {
+				return errors.New("sync-header-first-retry-interval must be greater than 0")
+			}
+			if builder.headerFirstSyncConfig.StallTimeout <= 0 {
+				return errors.New("sync-header-first-stall-timeout must be greater than 0")
+			}
 		}
 		if builder.stateStreamConf.ListenAddr != "" {
 			if builder.stateStreamConf.ExecutionDataCacheSize == 0 {
@@ -932,6 +1144,24 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 			builder.rpcConf.TransportCredentials = credentials.NewTLS(tlsConfig)
 			return nil
 		}).
+		Component("api key authenticator", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+			if builder.apiKeyConfigFile == "" {
+				return &module.NoopReadyDoneAware{}, nil
+			}
+
+			authenticator, err := apikey.NewAuthenticator(
+				node.Logger,
+				metrics.NewAccessAPIKeyCollector(),
+				builder.apiKeyConfigFile,
+				builder.apiKeyReloadInterval,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("could not create api key authenticator: %w", err)
+			}
+			builder.rpcConf.APIKeys = authenticator
+
+			return authenticator, nil
+		}).
 		Component("RPC engine", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
 			engineBuilder, err := rpc.NewBuilder(
 				node.Logger,
@@ -960,6 +1190,14 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 				return nil, err
 			}
 
+			if builder.ExecutionDataIndexer != nil {
+				engineBuilder.WithIndexedData(backend.NewIndexedData(
+					builder.ExecutionDataIndexer,
+					node.Storage.Events,
+					node.Storage.TransactionResults,
+				))
+			}
+
 			builder.RpcEng, err = engineBuilder.
 				WithLegacy().
 				WithBlockSignerDecoder(signature.NewBlockSignerDecoder(builder.Committee)).
@@ -1044,6 +1282,19 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 		builder.BuildExecutionDataRequester()
 	}
 
+	builder.Component("protocol data pruner", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+		retention, err := bstorage.ParsePruningRetention(builder.pruningRetention)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pruning retention: %w", err)
+		}
+
+		return bstorage.NewPruner(node.Logger, node.DB, bstorage.PrunerConfig{
+			Interval:  builder.pruningInterval,
+			BatchSize: builder.pruningBatchSize,
+			Retention: retention,
+		})
+	})
+
 	builder.Component("ping engine", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
 		ping, err := pingeng.New(
 			node.Logger,
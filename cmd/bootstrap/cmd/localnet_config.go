@@ -0,0 +1,557 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/onflow/flow-go/cmd"
+	"github.com/onflow/flow-go/cmd/bootstrap/utils"
+	"github.com/onflow/flow-go/crypto"
+	"github.com/onflow/flow-go/crypto/hash"
+	"github.com/onflow/flow-go/fvm"
+	model "github.com/onflow/flow-go/model/bootstrap"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/model/flow/order"
+	"github.com/onflow/flow-go/utils/io"
+)
+
+// ports used by every node of a local network. Each node listens on its own loopback
+// address, so all nodes can use the same ports, just like nodes in separate containers.
+const (
+	localnetNetworkPort     = "3569"
+	localnetGRPCPort        = "9000"
+	localnetGRPCSecurePort  = "9001"
+	localnetAdminPort       = "9002"
+	localnetStateStreamPort = "9003"
+	localnetGRPCWebPort     = "8000"
+	localnetRESTPort        = "8070"
+)
+
+const (
+	// PathLocalnetManifest is the path of the launch manifest, relative to the output directory.
+	PathLocalnetManifest = "localnet-manifest.json"
+	// PathLocalnetProcfile is the path of the Procfile, relative to the output directory.
+	PathLocalnetProcfile = "Procfile"
+	// PathLocalnetNodeConfig is the path of the generated node configurations, relative to the output directory.
+	PathLocalnetNodeConfig = "node-config.json"
+)
+
+var (
+	flagLocalnetConfig string
+
+	// PathLocalnetServiceAccount is the path of the service account private key, relative to the output directory.
+	PathLocalnetServiceAccount = filepath.Join(model.DirPrivateRoot, "service-account.priv.json")
+)
+
+// LocalnetConfig is the YAML description of a local network consumed by the localnet-config command.
+type LocalnetConfig struct {
+	// Chain is the chain of the network (either 'local' or 'bench')
+	Chain string `yaml:"chain"`
+	// Subnet is the IPv4 loopback subnet from which node addresses are assigned
+	Subnet string `yaml:"subnet"`
+	// BinaryDir is the directory containing one binary per role, named after the role
+	BinaryDir string `yaml:"binary_dir"`
+	// DataDir is the directory under which every node stores its databases
+	DataDir string `yaml:"data_dir"`
+	// MetricsPort is the metrics port of the first node, subsequent nodes use the following ports
+	MetricsPort uint   `yaml:"metrics_port"`
+	LogLevel    string `yaml:"log_level"`
+
+	CollectionClusters         uint   `yaml:"collection_clusters"`
+	EpochLength                uint64 `yaml:"epoch_length"`
+	EpochStakingPhaseLength    uint64 `yaml:"epoch_staking_phase_length"`
+	EpochDKGPhaseLength        uint64 `yaml:"epoch_dkg_phase_length"`
+	EpochCommitSafetyThreshold uint64 `yaml:"epoch_commit_safety_threshold"`
+	GenesisTokenSupply         string `yaml:"genesis_token_supply"`
+
+	Nodes []LocalnetNodeGroup `yaml:"nodes"`
+}
+
+// LocalnetNodeGroup describes a group of nodes of the same role.
+type LocalnetNodeGroup struct {
+	Role  flow.Role `yaml:"role"`
+	Count uint      `yaml:"count"`
+	// Weight is the weight of every node in the group, defaults to flow.DefaultInitialWeight
+	Weight uint64 `yaml:"weight"`
+	// Flags are additional command line flags passed to every node in the group
+	Flags []string `yaml:"flags"`
+}
+
+// LocalnetManifest describes how to launch every node of a local network as a local process.
+type LocalnetManifest struct {
+	ChainID      flow.ChainID
+	BootstrapDir string
+	Processes    []LocalnetProcess
+}
+
+// LocalnetProcess describes a single node process of a local network.
+type LocalnetProcess struct {
+	Name        string
+	Role        flow.Role
+	NodeID      flow.Identifier
+	Address     string
+	MetricsPort uint
+	Binary      string
+	Args        []string
+}
+
+// localnetConfigCmd represents the localnet-config command
+var localnetConfigCmd = &cobra.Command{
+	Use:   "localnet-config",
+	Short: "Generate all bootstrap data and a launch manifest for a network of local processes",
+	Long: `Generate the private information of every node, the root protocol snapshot and the root execution state
+	for the network described by the YAML file provided by the flag '--config'. Additionally, write a manifest
+	(and a Procfile) describing how to launch every node as a local process, without Docker.`,
+	Run: localnetConfig,
+}
+
+func init() {
+	rootCmd.AddCommand(localnetConfigCmd)
+
+	localnetConfigCmd.Flags().StringVar(&flagLocalnetConfig, "config", "localnet.yml", "path to a YAML file describing the roles, counts and weights of the nodes")
+	cmd.MarkFlagRequired(localnetConfigCmd, "config")
+}
+
+func localnetConfig(cmd *cobra.Command, args []string) {
+	conf, err := readLocalnetConfig(flagLocalnetConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid localnet config")
+	}
+
+	exists, err := pathExists(flagOutdir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not check if output directory exists")
+	}
+	if exists {
+		empty, err := isEmptyDir(flagOutdir)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not check if output directory is empty")
+		}
+		if !empty {
+			log.Fatal().Msg("output directory already exists and has content. delete and try again.")
+		}
+	}
+
+	nodeConfigs, err := conf.NodeConfigs()
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not assign node addresses")
+	}
+	writeJSON(PathLocalnetNodeConfig, nodeConfigs)
+	log.Info().Msg("")
+
+	// the steps below are the ones of the keygen, rootblock and finalize commands,
+	// run with a configuration without partner nodes
+	flagConfig = filepath.Join(flagOutdir, PathLocalnetNodeConfig)
+	flagRootChain = conf.Chain
+	flagRootParent = hex.EncodeToString(flow.ZeroID[:])
+	flagRootHeight = 0
+	flagRootTimestamp = time.Now().UTC().Format(time.RFC3339)
+	flagBootstrapRandomSeed = GenerateRandomSeed(flow.EpochSetupRandomSourceLength)
+	chainID := parseChainID(flagRootChain)
+
+	log.Info().Msg("generating internal private networking and staking keys")
+	nodes := model.Sort(genNetworkAndStakingKeys(), order.Canonical)
+	log.Info().Msg("")
+
+	writeJSONFile := func(relativePath string, val interface{}) error {
+		writeJSON(relativePath, val)
+		return nil
+	}
+	writeFile := func(relativePath string, data []byte) error {
+		writeText(relativePath, data)
+		return nil
+	}
+
+	log.Info().Msg("writing internal private key files")
+	err = utils.WriteStakingNetworkingKeyFiles(nodes, writeJSONFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to write internal private key files")
+	}
+	err = utils.WriteSecretsDBEncryptionKeyFiles(nodes, writeFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to write internal db encryption key files")
+	}
+	// machine accounts are created in the canonical order of the nodes
+	err = utils.WriteMachineAccountFiles(chainID, nodes, writeJSONFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to write machine account key files")
+	}
+	genNodePubInfo(nodes)
+	log.Info().Msg("")
+
+	// a local network has no partner nodes
+	partnerDir, err := os.MkdirTemp("", "localnet-partners")
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create partner directory")
+	}
+	defer os.RemoveAll(partnerDir)
+	flagPartnerNodeInfoDir = partnerDir
+	flagPartnerWeights = filepath.Join(partnerDir, "partner-weights.json")
+	err = os.WriteFile(flagPartnerWeights, []byte("{}"), 0644)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not write partner weights")
+	}
+	flagInternalNodePrivInfoDir = flagOutdir
+
+	log.Info().Msg("generating service account key")
+	serviceAccountKey := generateServiceAccountKey()
+	serviceAccountPublicKey, err := serviceAccountKey.PublicKey(fvm.AccountKeyWeightThreshold).MarshalJSON()
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not encode service account public key")
+	}
+	writeJSON(PathLocalnetServiceAccount, localnetServiceAccount{
+		Address:    chainID.Chain().ServiceAddress().Hex(),
+		PrivateKey: hex.EncodeToString(serviceAccountKey.PrivateKey.Encode()),
+		SignAlgo:   serviceAccountKey.SignAlgo,
+		HashAlgo:   serviceAccountKey.HashAlgo,
+	})
+	log.Info().Msg("")
+
+	rootBlock(nil, nil)
+
+	flagRootBlock = filepath.Join(flagOutdir, model.PathRootBlockData)
+	flagRootBlockVotesDir = filepath.Join(flagOutdir, model.DirnameRootBlockVotes)
+	flagDKGDataPath = filepath.Join(flagOutdir, model.PathRootDKGData)
+	// an all-zero root commit makes finalize generate the genesis execution state, including the
+	// service account and the core contracts, and use its commitment as the root commit
+	flagRootCommit = hex.EncodeToString(flow.DummyStateCommitment[:])
+	flagServiceAccountPublicKeyJSON = string(serviceAccountPublicKey)
+	flagGenesisTokenSupply = conf.GenesisTokenSupply
+	flagCollectionClusters = conf.CollectionClusters
+	flagEpochCounter = 0
+	flagNumViewsInEpoch = conf.EpochLength
+	flagNumViewsInStakingAuction = conf.EpochStakingPhaseLength
+	flagNumViewsInDKGPhase = conf.EpochDKGPhaseLength
+	flagEpochCommitSafetyThreshold = conf.EpochCommitSafetyThreshold
+	flagProtocolVersion = flow.DefaultProtocolVersion
+
+	finalize(nil, nil)
+
+	log.Info().Msg("writing launch manifest")
+	bootstrapDir, err := filepath.Abs(flagOutdir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not resolve output directory")
+	}
+	manifest, err := conf.Manifest(chainID, bootstrapDir, nodes)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not build launch manifest")
+	}
+	writeJSON(PathLocalnetManifest, manifest)
+	writeText(PathLocalnetProcfile, []byte(manifest.Procfile()))
+	log.Info().Msg("")
+
+	err = createLocalnetDirs(manifest)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create node data directories")
+	}
+
+	log.Info().Msgf("start all %d nodes with a Procfile runner (e.g. 'goreman -f %s start')",
+		len(manifest.Processes), filepath.Join(flagOutdir, PathLocalnetProcfile))
+}
+
+// localnetServiceAccount is the format of the service account private key file.
+type localnetServiceAccount struct {
+	Address    string
+	PrivateKey string
+	SignAlgo   crypto.SigningAlgorithm
+	HashAlgo   hash.HashingAlgorithm
+}
+
+func generateServiceAccountKey() flow.AccountPrivateKey {
+	sk, err := crypto.GeneratePrivateKey(crypto.ECDSAP256, GenerateRandomSeed(crypto.KeyGenSeedMinLen))
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not generate service account key")
+	}
+	return flow.AccountPrivateKey{
+		PrivateKey: sk,
+		SignAlgo:   crypto.ECDSAP256,
+		HashAlgo:   hash.SHA3_256,
+	}
+}
+
+// readLocalnetConfig reads the YAML file at the given path, fills in defaults for
+// missing values and validates the result.
+func readLocalnetConfig(path string) (LocalnetConfig, error) {
+	data, err := io.ReadFile(path)
+	if err != nil {
+		return LocalnetConfig{}, fmt.Errorf("could not read config file: %w", err)
+	}
+
+	conf := DefaultLocalnetConfig()
+	err = yaml.Unmarshal(data, &conf)
+	if err != nil {
+		return LocalnetConfig{}, fmt.Errorf("could not decode config file %s: %w", path, err)
+	}
+
+	for i := range conf.Nodes {
+		if conf.Nodes[i].Weight == 0 {
+			conf.Nodes[i].Weight = flow.DefaultInitialWeight
+		}
+	}
+
+	err = conf.Validate()
+	if err != nil {
+		return LocalnetConfig{}, err
+	}
+	return conf, nil
+}
+
+// DefaultLocalnetConfig returns the configuration used for every value missing in the YAML file.
+func DefaultLocalnetConfig() LocalnetConfig {
+	return LocalnetConfig{
+		Chain:                      "local",
+		Subnet:                     "127.0.1.0/24",
+		BinaryDir:                  "bin",
+		DataDir:                    "data",
+		MetricsPort:                8080,
+		LogLevel:                   "info",
+		CollectionClusters:         1,
+		EpochLength:                10_000,
+		EpochStakingPhaseLength:    2_000,
+		EpochDKGPhaseLength:        2_000,
+		EpochCommitSafetyThreshold: 1_000,
+		GenesisTokenSupply:         "1000000000.0",
+	}
+}
+
+// Validate checks that the configuration describes a network which can be bootstrapped.
+func (conf LocalnetConfig) Validate() error {
+	if conf.Chain != "local" && conf.Chain != "bench" {
+		return fmt.Errorf("unsupported chain %q, local networks must use 'local' or 'bench'", conf.Chain)
+	}
+
+	counts := make(map[flow.Role]uint)
+	weights := make(map[flow.Role]uint64)
+	for _, group := range conf.Nodes {
+		if !group.Role.Valid() {
+			return fmt.Errorf("invalid role in node group")
+		}
+		if group.Count == 0 {
+			return fmt.Errorf("node group with role %s has no nodes", group.Role)
+		}
+		// the bootstrapping requires all nodes of the same role to have the same weight
+		if weight, ok := weights[group.Role]; ok && weight != group.Weight {
+			return fmt.Errorf("all %s nodes must have the same weight (%d != %d)", group.Role, weight, group.Weight)
+		}
+		weights[group.Role] = group.Weight
+		counts[group.Role] += group.Count
+	}
+
+	for _, role := range flow.Roles() {
+		if counts[role] == 0 {
+			return fmt.Errorf("network requires at least one %s node", role)
+		}
+	}
+	if conf.CollectionClusters == 0 || conf.CollectionClusters > counts[flow.RoleCollection] {
+		return fmt.Errorf("invalid number of collection clusters %d for %d collection nodes",
+			conf.CollectionClusters, counts[flow.RoleCollection])
+	}
+
+	return nil
+}
+
+// NodeConfigs returns the configurations of all nodes, assigning every node its own
+// address from the configured subnet.
+func (conf LocalnetConfig) NodeConfigs() ([]model.NodeConfig, error) {
+	ip, subnet, err := net.ParseCIDR(conf.Subnet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet: %w", err)
+	}
+	ip = ip.Mask(subnet.Mask).To4()
+	if ip == nil {
+		return nil, fmt.Errorf("subnet %s is not an IPv4 subnet", conf.Subnet)
+	}
+
+	var configs []model.NodeConfig
+	for _, group := range conf.Nodes {
+		for i := uint(0); i < group.Count; i++ {
+			ip = nextIP(ip)
+			if !subnet.Contains(ip) {
+				return nil, fmt.Errorf("subnet %s is too small for all nodes", conf.Subnet)
+			}
+			configs = append(configs, model.NodeConfig{
+				Role:    group.Role,
+				Address: net.JoinHostPort(ip.String(), localnetNetworkPort),
+				Weight:  group.Weight,
+			})
+		}
+	}
+	return configs, nil
+}
+
+// nextIP returns the IPv4 address following the given one.
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// Manifest returns the launch manifest for the given nodes, which were bootstrapped into bootstrapDir.
+func (conf LocalnetConfig) Manifest(chainID flow.ChainID, bootstrapDir string, nodes []model.NodeInfo) (*LocalnetManifest, error) {
+	binaryDir, err := filepath.Abs(conf.BinaryDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve binary directory: %w", err)
+	}
+	dataDir, err := filepath.Abs(conf.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve data directory: %w", err)
+	}
+
+	nodesByAddress := make(map[string]model.NodeInfo, len(nodes))
+	for _, node := range nodes {
+		nodesByAddress[node.Address] = node
+	}
+
+	configs, err := conf.NodeConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &LocalnetManifest{
+		ChainID:      chainID,
+		BootstrapDir: bootstrapDir,
+	}
+
+	roleIndex := make(map[flow.Role]int)
+	for i, config := range configs {
+		node, ok := nodesByAddress[config.Address]
+		if !ok {
+			return nil, fmt.Errorf("no node with address %s", config.Address)
+		}
+		host, _, err := net.SplitHostPort(node.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node address %s: %w", node.Address, err)
+		}
+
+		roleIndex[node.Role]++
+		name := fmt.Sprintf("%s_%d", node.Role, roleIndex[node.Role])
+		nodeDir := filepath.Join(dataDir, name)
+		metricsPort := conf.MetricsPort + uint(i)
+
+		args := []string{
+			fmt.Sprintf("--nodeid=%s", node.NodeID),
+			fmt.Sprintf("--bootstrapdir=%s", bootstrapDir),
+			fmt.Sprintf("--datadir=%s", filepath.Join(nodeDir, "protocol")),
+			fmt.Sprintf("--secretsdir=%s", filepath.Join(nodeDir, "secret")),
+			fmt.Sprintf("--bind=%s", node.Address),
+			fmt.Sprintf("--loglevel=%s", conf.LogLevel),
+			fmt.Sprintf("--metricport=%d", metricsPort),
+			fmt.Sprintf("--admin-addr=%s", net.JoinHostPort(host, localnetAdminPort)),
+		}
+		args = append(args, localnetRoleArgs(node.Role, host, nodeDir)...)
+		args = append(args, conf.groupFlags(i)...)
+
+		manifest.Processes = append(manifest.Processes, LocalnetProcess{
+			Name:        name,
+			Role:        node.Role,
+			NodeID:      node.NodeID,
+			Address:     node.Address,
+			MetricsPort: metricsPort,
+			Binary:      filepath.Join(binaryDir, node.Role.String()),
+			Args:        args,
+		})
+	}
+
+	return manifest, nil
+}
+
+// groupFlags returns the additional flags of the group containing the i-th node.
+func (conf LocalnetConfig) groupFlags(i int) []string {
+	for _, group := range conf.Nodes {
+		if i < int(group.Count) {
+			return group.Flags
+		}
+		i -= int(group.Count)
+	}
+	return nil
+}
+
+// localnetRoleArgs returns the role specific flags of a node listening on the given host.
+func localnetRoleArgs(role flow.Role, host string, nodeDir string) []string {
+	switch role {
+	case flow.RoleCollection:
+		return []string{
+			fmt.Sprintf("--ingress-addr=%s", net.JoinHostPort(host, localnetGRPCPort)),
+			"--insecure-access-api=false",
+			"--access-node-ids=*",
+		}
+	case flow.RoleConsensus:
+		return []string{
+			"--chunk-alpha=1",
+			"--emergency-sealing-active=false",
+			"--insecure-access-api=false",
+			"--access-node-ids=*",
+		}
+	case flow.RoleExecution:
+		return []string{
+			fmt.Sprintf("--rpc-addr=%s", net.JoinHostPort(host, localnetGRPCPort)),
+			fmt.Sprintf("--triedir=%s", filepath.Join(nodeDir, "trie")),
+			fmt.Sprintf("--execution-data-dir=%s", filepath.Join(nodeDir, "execution-data")),
+			"--execution-data-transaction-results-height=0",
+		}
+	case flow.RoleVerification:
+		return []string{
+			"--chunk-alpha=1",
+		}
+	case flow.RoleAccess:
+		return []string{
+			fmt.Sprintf("--rpc-addr=%s", net.JoinHostPort(host, localnetGRPCPort)),
+			fmt.Sprintf("--secure-rpc-addr=%s", net.JoinHostPort(host, localnetGRPCSecurePort)),
+			fmt.Sprintf("--http-addr=%s", net.JoinHostPort(host, localnetGRPCWebPort)),
+			fmt.Sprintf("--rest-addr=%s", net.JoinHostPort(host, localnetRESTPort)),
+			fmt.Sprintf("--state-stream-addr=%s", net.JoinHostPort(host, localnetStateStreamPort)),
+			fmt.Sprintf("--collection-ingress-port=%s", localnetGRPCPort),
+			"--execution-data-sync-enabled=true",
+			fmt.Sprintf("--execution-data-dir=%s", filepath.Join(nodeDir, "execution-data")),
+		}
+	}
+	return nil
+}
+
+// Procfile returns the manifest in the Procfile format understood by common process managers.
+func (m *LocalnetManifest) Procfile() string {
+	var b strings.Builder
+	for _, process := range m.Processes {
+		b.WriteString(process.Name)
+		b.WriteString(": ")
+		b.WriteString(process.Binary)
+		for _, arg := range process.Args {
+			b.WriteString(" '")
+			b.WriteString(strings.ReplaceAll(arg, "'", `'\''`))
+			b.WriteString("'")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// createLocalnetDirs creates the data directories of all processes.
+func createLocalnetDirs(manifest *LocalnetManifest) error {
+	for _, process := range manifest.Processes {
+		for _, arg := range process.Args {
+			name, value, ok := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+			if !ok || !strings.HasSuffix(name, "dir") || name == "bootstrapdir" {
+				continue
+			}
+			err := os.MkdirAll(value, 0700)
+			if err != nil {
+				return fmt.Errorf("could not create directory %s of %s: %w", value, process.Name, err)
+			}
+		}
+	}
+	return nil
+}
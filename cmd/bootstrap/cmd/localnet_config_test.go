@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	model "github.com/onflow/flow-go/model/bootstrap"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const localnetTestConfig = `
+collection_clusters: 1
+epoch_length: 5000
+epoch_staking_phase_length: 500
+epoch_dkg_phase_length: 500
+nodes:
+  - role: collection
+    count: 2
+  - role: consensus
+    count: 2
+    flags: ["--block-rate-delay=800ms"]
+  - role: execution
+    count: 1
+    weight: 500
+  - role: verification
+    count: 1
+  - role: access
+    count: 1
+`
+
+func writeLocalnetConfig(t *testing.T, dir string, config string) string {
+	path := filepath.Join(dir, "localnet.yml")
+	require.NoError(t, os.WriteFile(path, []byte(config), 0644))
+	return path
+}
+
+func TestReadLocalnetConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	conf, err := readLocalnetConfig(writeLocalnetConfig(t, dir, localnetTestConfig))
+	require.NoError(t, err)
+
+	// missing values are taken from the defaults
+	defaults := DefaultLocalnetConfig()
+	assert.Equal(t, defaults.Chain, conf.Chain)
+	assert.Equal(t, defaults.Subnet, conf.Subnet)
+	assert.Equal(t, uint64(5000), conf.EpochLength)
+
+	require.Len(t, conf.Nodes, 5)
+	assert.Equal(t, flow.RoleConsensus, conf.Nodes[1].Role)
+	assert.Equal(t, flow.DefaultInitialWeight, conf.Nodes[1].Weight)
+	assert.Equal(t, uint64(500), conf.Nodes[2].Weight)
+
+	t.Run("missing role", func(t *testing.T) {
+		_, err := readLocalnetConfig(writeLocalnetConfig(t, dir, `
+nodes:
+  - role: collection
+    count: 1
+`))
+		require.Error(t, err)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		_, err := readLocalnetConfig(writeLocalnetConfig(t, dir, `
+nodes:
+  - role: archive
+    count: 1
+`))
+		require.Error(t, err)
+	})
+
+	t.Run("non-uniform weights", func(t *testing.T) {
+		_, err := readLocalnetConfig(writeLocalnetConfig(t, dir, localnetTestConfig+`
+  - role: access
+    count: 1
+    weight: 10
+`))
+		require.Error(t, err)
+	})
+
+	t.Run("too many clusters", func(t *testing.T) {
+		conf := conf
+		conf.CollectionClusters = 3
+		require.Error(t, conf.Validate())
+	})
+}
+
+func TestLocalnetNodeConfigs(t *testing.T) {
+	conf, err := readLocalnetConfig(writeLocalnetConfig(t, t.TempDir(), localnetTestConfig))
+	require.NoError(t, err)
+
+	configs, err := conf.NodeConfigs()
+	require.NoError(t, err)
+	require.Len(t, configs, 7)
+	assert.Equal(t, "127.0.1.1:3569", configs[0].Address)
+	assert.Equal(t, "127.0.1.7:3569", configs[6].Address)
+	assert.Equal(t, flow.RoleAccess, configs[6].Role)
+
+	conf.Subnet = "127.0.1.0/30"
+	_, err = conf.NodeConfigs()
+	require.Error(t, err)
+}
+
+func TestLocalnetConfig_HappyPath(t *testing.T) {
+	dir := t.TempDir()
+	dataDir := filepath.Join(dir, "data")
+
+	flagOutdir = filepath.Join(dir, "bootstrap")
+	flagLocalnetConfig = writeLocalnetConfig(t, dir, localnetTestConfig+fmt.Sprintf("data_dir: %s\n", dataDir))
+
+	localnetConfig(nil, nil)
+
+	assert.FileExists(t, filepath.Join(flagOutdir, model.PathRootProtocolStateSnapshot))
+	assert.FileExists(t, filepath.Join(flagOutdir, model.PathRootCheckpoint))
+	assert.FileExists(t, filepath.Join(flagOutdir, PathLocalnetServiceAccount))
+	assert.FileExists(t, filepath.Join(flagOutdir, PathLocalnetProcfile))
+
+	data, err := os.ReadFile(filepath.Join(flagOutdir, PathLocalnetManifest))
+	require.NoError(t, err)
+	var manifest LocalnetManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+
+	require.Len(t, manifest.Processes, 7)
+	assert.Equal(t, flow.Localnet, manifest.ChainID)
+
+	metricsPorts := make(map[uint]struct{})
+	for _, process := range manifest.Processes {
+		// every node has its private information in the bootstrap directory
+		assert.FileExists(t, filepath.Join(flagOutdir, fmt.Sprintf(model.PathNodeInfoPriv, process.NodeID)))
+		assert.DirExists(t, filepath.Join(dataDir, process.Name, "protocol"))
+		assert.Contains(t, process.Args, fmt.Sprintf("--nodeid=%s", process.NodeID))
+		assert.Contains(t, process.Args, fmt.Sprintf("--bind=%s", process.Address))
+
+		metricsPorts[process.MetricsPort] = struct{}{}
+	}
+	assert.Len(t, metricsPorts, 7)
+
+	// group flags are only passed to the nodes of the group
+	consensus := manifest.Processes[2]
+	require.Equal(t, "consensus_1", consensus.Name)
+	assert.Contains(t, consensus.Args, "--block-rate-delay=800ms")
+	assert.NotContains(t, manifest.Processes[4].Args, "--block-rate-delay=800ms")
+}
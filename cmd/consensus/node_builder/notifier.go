@@ -1,4 +1,4 @@
-package main
+package node_builder
 
 import (
 	"github.com/rs/zerolog"
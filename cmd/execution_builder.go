@@ -13,6 +13,7 @@ import (
 
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	badgerv2 "github.com/dgraph-io/badger/v2"
 	"github.com/ipfs/go-cid"
 	badger "github.com/ipfs/go-ds-badger2"
 	"github.com/onflow/flow-core-contracts/lib/go/templates"
@@ -50,11 +51,15 @@ import (
 	"github.com/onflow/flow-go/engine/execution/rpc"
 	"github.com/onflow/flow-go/engine/execution/state"
 	"github.com/onflow/flow-go/engine/execution/state/bootstrap"
+	"github.com/onflow/flow-go/engine/execution/state/history"
 	"github.com/onflow/flow-go/fvm"
 	"github.com/onflow/flow-go/fvm/storage/snapshot"
 	"github.com/onflow/flow-go/fvm/systemcontracts"
+	ledgerpkg "github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	ledger "github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadfile"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	bootstrapFilenames "github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/flow"
@@ -79,6 +84,8 @@ import (
 	storageerr "github.com/onflow/flow-go/storage"
 	storage "github.com/onflow/flow-go/storage/badger"
 	"github.com/onflow/flow-go/storage/badger/procedure"
+	"github.com/onflow/flow-go/storage/operation/badgerimpl"
+	sutil "github.com/onflow/flow-go/storage/util"
 )
 
 const (
@@ -141,6 +148,8 @@ type ExecutionNode struct {
 	executionDataTracker   tracker.Storage
 	blobService            network.BlobService
 	blobserviceDependable  *module.ProxiedReadyDoneAware
+	historicalRegisters    *history.Registers // nil if the historical registers store is disabled
+	historicalIndexer      *history.Indexer
 }
 
 func (builder *ExecutionNodeBuilder) LoadComponentsAndModules() {
@@ -156,7 +165,7 @@ func (builder *ExecutionNodeBuilder) LoadComponentsAndModules() {
 			return stateSyncCommands.NewReadExecutionDataCommand(exeNode.executionDataStore)
 		}).
 		AdminCommand("trigger-checkpoint", func(config *NodeConfig) commands.AdminCommand {
-			return executionCommands.NewTriggerCheckpointCommand(exeNode.toTriggerCheckpoint)
+			return executionCommands.NewTriggerCheckpointCommand(exeNode.toTriggerCheckpoint, exeNode.latestCheckpoint)
 		}).
 		AdminCommand("stop-at-height", func(config *NodeConfig) commands.AdminCommand {
 			return executionCommands.NewStopAtHeightCommand(exeNode.stopControl)
@@ -188,8 +197,10 @@ func (builder *ExecutionNodeBuilder) LoadComponentsAndModules() {
 		// so it will be easier to follow and refactor later
 		Component("execution state", exeNode.LoadExecutionState).
 		Component("stop control", exeNode.LoadStopControl).
+		Component("historical registers indexer", exeNode.LoadHistoricalRegistersIndexer).
 		Component("execution state ledger WAL compactor", exeNode.LoadExecutionStateLedgerWALCompactor).
 		Component("execution data pruner", exeNode.LoadExecutionDataPruner).
+		Component("protocol data pruner", exeNode.LoadProtocolDataPruner).
 		Component("blob service", exeNode.LoadBlobService).
 		Component("block data upload manager", exeNode.LoadBlockUploaderManager).
 		Component("GCP block data uploader", exeNode.LoadGCPBlockDataUploader).
@@ -679,8 +690,19 @@ func (exeNode *ExecutionNode) LoadExecutionStateLedger(
 		return nil, fmt.Errorf("failed to initialize wal: %w", err)
 	}
 
+	var forestOpts []mtrie.ForestOption
+	if exeNode.exeConf.mTriePayloadFile != "" {
+		payloads, err := payloadfile.Open(exeNode.exeConf.mTriePayloadFile, payloadfile.DefaultSegmentSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open mtrie payload file: %w", err)
+		}
+		// the payload file must only be closed once the ledger isn't used anymore
+		exeNode.builder.ShutdownFunc(payloads.Close)
+		forestOpts = append(forestOpts, mtrie.WithPayloadStore(payloads))
+	}
+
 	exeNode.ledgerStorage, err = ledger.NewLedger(exeNode.diskWAL, int(exeNode.exeConf.mTrieCacheSize), exeNode.collector, node.Logger.With().Str("subcomponent",
-		"ledger").Logger(), ledger.DefaultPathFinderVersion)
+		"ledger").Logger(), ledger.DefaultPathFinderVersion, forestOpts...)
 	return exeNode.ledgerStorage, err
 }
 
@@ -690,7 +712,7 @@ func (exeNode *ExecutionNode) LoadExecutionStateLedgerWALCompactor(
 	module.ReadyDoneAware,
 	error,
 ) {
-	return ledger.NewCompactor(
+	compactor, err := ledger.NewCompactor(
 		exeNode.ledgerStorage,
 		exeNode.diskWAL,
 		node.Logger.With().Str("subcomponent", "checkpointer").Logger(),
@@ -699,6 +721,125 @@ func (exeNode *ExecutionNode) LoadExecutionStateLedgerWALCompactor(
 		exeNode.exeConf.checkpointsToKeep,
 		exeNode.toTriggerCheckpoint, // compactor will listen to the signal from admin tool for force triggering checkpointing
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if exeNode.historicalIndexer != nil {
+		compactor.AddTrieUpdateConsumer(exeNode.historicalIndexer)
+	}
+
+	return compactor, nil
+}
+
+// LoadHistoricalRegistersIndexer opens the historical registers store, if enabled.
+// The store is bootstrapped from the ledger on first use, which requires the ledger to be loaded
+// and no trie updates to be applied before the indexer is registered with the compactor.
+func (exeNode *ExecutionNode) LoadHistoricalRegistersIndexer(
+	node *NodeConfig,
+) (
+	module.ReadyDoneAware,
+	error,
+) {
+	if exeNode.exeConf.historicalRegistersDir == "" {
+		return &module.NoopReadyDoneAware{}, nil
+	}
+
+	err := os.MkdirAll(exeNode.exeConf.historicalRegistersDir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("could not create historical registers dir: %w", err)
+	}
+	db, err := badgerv2.Open(badgerv2.DefaultOptions(exeNode.exeConf.historicalRegistersDir).
+		WithLogger(sutil.NewLogger(node.Logger)))
+	if err != nil {
+		return nil, fmt.Errorf("could not open historical registers db: %w", err)
+	}
+	exeNode.builder.ShutdownFunc(db.Close)
+
+	exeNode.historicalRegisters, err = history.NewRegisters(badgerimpl.ToDB(db))
+	if err != nil {
+		return nil, fmt.Errorf("could not load historical registers: %w", err)
+	}
+
+	if !exeNode.historicalRegisters.IsBootstrapped() {
+		err = exeNode.bootstrapHistoricalRegisters(node)
+		if err != nil {
+			return nil, fmt.Errorf("could not bootstrap historical registers: %w", err)
+		}
+	}
+
+	exeNode.historicalIndexer, err = history.NewIndexer(
+		node.Logger,
+		exeNode.historicalRegisters,
+		node.State,
+		node.Storage.Headers,
+		node.Storage.Commits,
+		exeNode.ledgerStorage.Tries,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create historical registers indexer: %w", err)
+	}
+
+	node.ProtocolEvents.AddConsumer(exeNode.historicalIndexer)
+
+	return exeNode.historicalIndexer, nil
+}
+
+// latestCheckpoint returns the number of the latest checkpoint of the ledger, or -1 if there are no checkpoints.
+func (exeNode *ExecutionNode) latestCheckpoint() (int, error) {
+	if exeNode.diskWAL == nil {
+		return 0, fmt.Errorf("ledger WAL is not initialized yet")
+	}
+	checkpointer, err := exeNode.diskWAL.NewCheckpointer()
+	if err != nil {
+		return 0, fmt.Errorf("could not create checkpointer: %w", err)
+	}
+	return checkpointer.LatestCheckpoint()
+}
+
+// bootstrapHistoricalRegisters seeds the historical registers store with the state of the highest
+// executed finalized block. Blocks executed before the store was bootstrapped which are not ancestors
+// of this block are not indexed, as their trie updates have already been applied to the ledger.
+func (exeNode *ExecutionNode) bootstrapHistoricalRegisters(node *NodeConfig) error {
+	final, err := node.State.Final().Head()
+	if err != nil {
+		return fmt.Errorf("could not get finalized block: %w", err)
+	}
+
+	rootHeight := node.RootBlock.Header.Height
+	for height := final.Height; height >= rootHeight; height-- {
+		header, err := node.Storage.Headers.ByHeight(height)
+		if err != nil {
+			return fmt.Errorf("could not get header at height %d: %w", height, err)
+		}
+		commit, err := node.Storage.Commits.ByBlockID(header.ID())
+		if errors.Is(err, storageerr.ErrNotFound) && height > rootHeight {
+			// not executed yet
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not get state commitment of block %v: %w", header.ID(), err)
+		}
+
+		if !exeNode.ledgerStorage.HasState(ledgerpkg.State(commit)) && height == rootHeight {
+			// the root state is only held by the ledger until the first checkpoint after it
+			return history.BootstrapFromCheckpoint(
+				node.Logger,
+				exeNode.historicalRegisters,
+				height,
+				commit,
+				filepath.Join(node.BootstrapDir, bootstrapFilenames.PathRootCheckpoint),
+			)
+		}
+
+		tries, err := exeNode.ledgerStorage.Tries()
+		if err != nil {
+			return fmt.Errorf("could not get ledger tries: %w", err)
+		}
+		return history.BootstrapFromTries(exeNode.historicalRegisters, height, commit, tries)
+	}
+
+	return fmt.Errorf("no executed block found at or below height %d", final.Height)
 }
 
 func (exeNode *ExecutionNode) LoadExecutionDataPruner(
@@ -749,6 +890,24 @@ func (exeNode *ExecutionNode) LoadExecutionDataPruner(
 	return exeNode.executionDataPruner, err
 }
 
+func (exeNode *ExecutionNode) LoadProtocolDataPruner(
+	node *NodeConfig,
+) (
+	module.ReadyDoneAware,
+	error,
+) {
+	retention, err := storage.ParsePruningRetention(exeNode.exeConf.pruningRetention)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pruning retention: %w", err)
+	}
+
+	return storage.NewPruner(node.Logger, node.DB, storage.PrunerConfig{
+		Interval:  exeNode.exeConf.pruningInterval,
+		BatchSize: exeNode.exeConf.pruningBatchSize,
+		Retention: retention,
+	})
+}
+
 func (exeNode *ExecutionNode) LoadCheckerEngine(
 	node *NodeConfig,
 ) (
@@ -808,6 +967,13 @@ func (exeNode *ExecutionNode) LoadIngestionEngine(
 		exeNode.blockDataUploader,
 		exeNode.stopControl,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create ingestion engine: %w", err)
+	}
+
+	if exeNode.historicalRegisters != nil {
+		exeNode.ingestionEng.WithHistoricalRegisters(exeNode.historicalRegisters)
+	}
 
 	// TODO: we should solve these mutual dependencies better
 	// => https://github.com/dapperlabs/flow-go/issues/4360
@@ -1009,6 +1175,8 @@ func (exeNode *ExecutionNode) LoadGrpcServer(
 		exeNode.results,
 		exeNode.txResults,
 		node.Storage.Commits,
+		node.Storage.Seals,
+		exeNode.ledgerStorage,
 		node.RootChainID,
 		signature.NewBlockSignerDecoder(exeNode.committee),
 		exeNode.exeConf.apiRatelimits,
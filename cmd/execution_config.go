@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -27,7 +28,9 @@ type ExecutionConfig struct {
 	rpcConf                              rpc.Config
 	triedir                              string
 	executionDataDir                     string
+	historicalRegistersDir               string
 	mTrieCacheSize                       uint32
+	mTriePayloadFile                     string
 	transactionResultsCacheSize          uint
 	checkpointDistance                   uint
 	checkpointsToKeep                    uint
@@ -49,6 +52,9 @@ type ExecutionConfig struct {
 	blobstoreRateLimit                   int
 	blobstoreBurstLimit                  int
 	chunkDataPackRequestWorkers          uint
+	pruningRetention                     map[string]int
+	pruningInterval                      time.Duration
+	pruningBatchSize                     uint64
 
 	computationConfig        computation.ComputationConfig
 	receiptRequestWorkers    uint   // common provider engine workers
@@ -64,13 +70,20 @@ func (exeConf *ExecutionConfig) SetupFlags(flags *pflag.FlagSet) {
 	flags.BoolVar(&exeConf.rpcConf.RpcMetricsEnabled, "rpc-metrics-enabled", false, "whether to enable the rpc metrics")
 	flags.StringVar(&exeConf.triedir, "triedir", datadir, "directory to store the execution State")
 	flags.StringVar(&exeConf.executionDataDir, "execution-data-dir", filepath.Join(homedir, ".flow", "execution_data"), "directory to use for storing Execution Data")
+	flags.StringVar(&exeConf.historicalRegistersDir, "historical-registers-dir", "", "directory to store the registers of all sealed heights, used to serve scripts and register reads at old blocks. the store is disabled if empty")
 	flags.Uint32Var(&exeConf.mTrieCacheSize, "mtrie-cache-size", 500, "cache size for MTrie")
+	flags.StringVar(&exeConf.mTriePayloadFile, "mtrie-payload-file", "", "[experimental] path of a memory-mapped file to keep the MTrie leaf payloads in, instead of the heap. "+
+		"the file is never compacted: it is recreated on startup and grows with every trie update until the node is restarted. "+
+		"payloads are kept in the heap if empty (default)")
 	flags.UintVar(&exeConf.checkpointDistance, "checkpoint-distance", 20, "number of WAL segments between checkpoints")
 	flags.UintVar(&exeConf.checkpointsToKeep, "checkpoints-to-keep", 5, "number of recent checkpoints to keep (0 to keep all)")
 	flags.UintVar(&exeConf.computationConfig.DerivedDataCacheSize, "cadence-execution-cache", derived.DefaultDerivedDataCacheSize,
 		"cache size for Cadence execution")
 	flags.BoolVar(&exeConf.computationConfig.ExtensiveTracing, "extensive-tracing", false, "adds high-overhead tracing to execution")
 	flags.BoolVar(&exeConf.computationConfig.CadenceTracing, "cadence-tracing", false, "enables cadence runtime level tracing")
+	flags.Uint64Var(&exeConf.computationConfig.ExecutionDataTransactionResultsHeight, "execution-data-transaction-results-height", math.MaxUint64,
+		"first block height at which transaction results are included in the chunk execution data. it changes the execution data IDs, "+
+			"so it must be the same for all execution nodes of the network. disabled by default")
 	flags.UintVar(&exeConf.chunkDataPackCacheSize, "chdp-cache", storage.DefaultCacheSize, "cache size for chunk data packs")
 	flags.Uint32Var(&exeConf.chunkDataPackRequestsCacheSize, "chdp-request-queue", mempool.DefaultChunkDataPackRequestQueueSize, "queue size for chunk data pack requests")
 	flags.DurationVar(&exeConf.requestInterval, "request-interval", 60*time.Second, "the interval between requests for the requester engine")
@@ -80,6 +93,12 @@ func (exeConf *ExecutionConfig) SetupFlags(flags *pflag.FlagSet) {
 		"threshold for logging script execution")
 	flags.DurationVar(&exeConf.computationConfig.QueryConfig.ExecutionTimeLimit, "script-execution-time-limit", query.DefaultExecutionTimeLimit,
 		"script execution time limit")
+	flags.StringVar(&exeConf.computationConfig.QueryConfig.ComputationProfileDir, "script-computation-profile-dir", "",
+		"directory to write pprof computation profiles of executed scripts to, profiling is disabled if empty")
+	flags.Uint64Var(&exeConf.computationConfig.QueryConfig.ComputationProfileThreshold, "script-computation-profile-threshold", 0,
+		"minimum computation used by a script for its computation profile to be written")
+	flags.UintVar(&exeConf.computationConfig.QueryConfig.ComputationProfileMaxFiles, "script-computation-profile-max-files", query.DefaultComputationProfileMaxFiles,
+		"maximum number of script computation profiles kept, the oldest are removed first. 0 means no limit")
 	flags.UintVar(&exeConf.transactionResultsCacheSize, "transaction-results-cache-size", 10000, "number of transaction results to be cached")
 	flags.BoolVar(&exeConf.extensiveLog, "extensive-logging", false, "extensive logging logs tx contents and block headers")
 	flags.DurationVar(&exeConf.chunkDataPackQueryTimeout, "chunk-data-pack-query-timeout", exeprovider.DefaultChunkDataPackQueryTimeout, "timeout duration to determine a chunk data pack query being slow")
@@ -95,6 +114,10 @@ func (exeConf *ExecutionConfig) SetupFlags(flags *pflag.FlagSet) {
 	flags.Uint64Var(&exeConf.executionDataPrunerThreshold, "execution-data-height-range-threshold", 100_000, "height threshold used to trigger Execution Data pruning")
 	flags.StringToIntVar(&exeConf.apiRatelimits, "api-rate-limits", map[string]int{}, "per second rate limits for GRPC API methods e.g. Ping=300,ExecuteScriptAtBlockID=500 etc. note limits apply globally to all clients.")
 	flags.StringToIntVar(&exeConf.apiBurstlimits, "api-burst-limits", map[string]int{}, "burst limits for gRPC API methods e.g. Ping=100,ExecuteScriptAtBlockID=100 etc. note limits apply globally to all clients.")
+	flags.StringToIntVar(&exeConf.pruningRetention, "pruning-retention", map[string]int{}, "number of heights below the latest sealed height to retain per category of protocol data, "+
+		"e.g. chunk_data_packs=10000,events=100000. categories which are not listed are not pruned. by default, nothing is pruned")
+	flags.DurationVar(&exeConf.pruningInterval, "pruning-interval", time.Minute, "interval at which protocol data is pruned")
+	flags.Uint64Var(&exeConf.pruningBatchSize, "pruning-batch-size", 100, "number of heights of protocol data pruned in a single database write batch")
 	flags.IntVar(&exeConf.blobstoreRateLimit, "blobstore-rate-limit", 0, "per second outgoing rate limit for Execution Data blobstore")
 	flags.IntVar(&exeConf.blobstoreBurstLimit, "blobstore-burst-limit", 0, "outgoing burst limit for Execution Data blobstore")
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/onflow/flow-go/module/chainsync"
 	"github.com/onflow/flow-go/module/compliance"
 	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/jobqueue"
 	"github.com/onflow/flow-go/module/profiler"
 	"github.com/onflow/flow-go/module/updatable_configs"
 	"github.com/onflow/flow-go/network"
@@ -147,6 +148,10 @@ type BaseConfig struct {
 	AdminKey                    string
 	AdminClientCAs              string
 	AdminMaxMsgSize             uint
+	AdminPolicyFile             string
+	AdminAuditLog               string
+	ConfigOverridesStore        string
+	ComponentReadyTimeout       time.Duration
 	BindAddr                    string
 	NodeRole                    string
 	DynamicStartupANAddress     string
@@ -169,6 +174,7 @@ type BaseConfig struct {
 	guaranteesCacheSize         uint
 	receiptsCacheSize           uint
 	db                          *badger.DB
+	args                        []string // flags are parsed from args instead of the command line if set
 	HeroCacheMetricsEnable      bool
 	SyncCoreConfig              chainsync.Config
 	CodecFactory                func() network.Codec
@@ -228,6 +234,8 @@ type NodeConfig struct {
 	Me                module.Local
 	Tracer            module.Tracer
 	ConfigManager     *updatable_configs.Manager
+	DeadLetterQueues  *jobqueue.DeadLetterQueues // job consumers which dead-letter jobs, for the admin commands
+	StartupGraph      *component.StartupGraph    // startup and shutdown timings of the modules and components
 	MetricsRegisterer prometheus.Registerer
 	Metrics           Metrics
 	DB                *badger.DB
@@ -316,6 +324,8 @@ func DefaultBaseConfig() *BaseConfig {
 		AdminKey:         NotSet,
 		AdminClientCAs:   NotSet,
 		AdminMaxMsgSize:  grpcutils.DefaultMaxMsgSize,
+		AdminPolicyFile:  NotSet,
+		AdminAuditLog:    NotSet,
 		BindAddr:         NotSet,
 		BootstrapDir:     "bootstrap",
 		datadir:          datadir,
@@ -340,6 +350,8 @@ func DefaultBaseConfig() *BaseConfig {
 			Duration: 10 * time.Second,
 		},
 
+		ComponentReadyTimeout: 5 * time.Minute,
+
 		HeroCacheMetricsEnable: false,
 		SyncCoreConfig:         chainsync.DefaultConfig(),
 		CodecFactory:           codecFactory,
@@ -37,18 +37,23 @@ import (
 	"github.com/onflow/flow-go/engine/common/follower"
 	synceng "github.com/onflow/flow-go/engine/common/synchronization"
 	"github.com/onflow/flow-go/engine/protocol"
+	"github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/encodable"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/blobs"
 	"github.com/onflow/flow-go/module/chainsync"
 	"github.com/onflow/flow-go/module/compliance"
+	"github.com/onflow/flow-go/module/executiondatasync/archive"
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 	finalizer "github.com/onflow/flow-go/module/finalizer/consensus"
 	"github.com/onflow/flow-go/module/id"
+	"github.com/onflow/flow-go/module/jobqueue"
 	"github.com/onflow/flow-go/module/local"
 	"github.com/onflow/flow-go/module/metrics"
 	"github.com/onflow/flow-go/module/state_synchronization"
+	"github.com/onflow/flow-go/module/state_synchronization/indexer"
 	edrequester "github.com/onflow/flow-go/module/state_synchronization/requester"
 	consensus_follower "github.com/onflow/flow-go/module/upstream"
 	"github.com/onflow/flow-go/network"
@@ -78,6 +83,8 @@ import (
 	"github.com/onflow/flow-go/state/protocol/events/gadgets"
 	"github.com/onflow/flow-go/storage"
 	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/operation/badgerimpl"
+	"github.com/onflow/flow-go/storage/store"
 	"github.com/onflow/flow-go/utils/grpcutils"
 	"github.com/onflow/flow-go/utils/io"
 )
@@ -102,22 +109,27 @@ import (
 // For a node running as a standalone process, the config fields will be populated from the command line params,
 // while for a node running as a library, the config fields are expected to be initialized by the caller.
 type ObserverServiceConfig struct {
-	bootstrapNodeAddresses    []string
-	bootstrapNodePublicKeys   []string
-	observerNetworkingKeyPath string
-	bootstrapIdentities       flow.IdentityList // the identity list of bootstrap peers the node uses to discover other nodes
-	apiRatelimits             map[string]int
-	apiBurstlimits            map[string]int
-	rpcConf                   rpc.Config
-	rpcMetricsEnabled         bool
-	executionDataSyncEnabled  bool
-	executionDataDir          string
-	executionDataStartHeight  uint64
-	executionDataConfig       edrequester.ExecutionDataConfig
-	apiTimeout                time.Duration
-	upstreamNodeAddresses     []string
-	upstreamNodePublicKeys    []string
-	upstreamIdentities        flow.IdentityList // the identity list of upstream peers the node uses to forward API requests to
+	bootstrapNodeAddresses       []string
+	bootstrapNodePublicKeys      []string
+	observerNetworkingKeyPath    string
+	bootstrapIdentities          flow.IdentityList // the identity list of bootstrap peers the node uses to discover other nodes
+	apiRatelimits                map[string]int
+	apiBurstlimits               map[string]int
+	rpcConf                      rpc.Config
+	rpcMetricsEnabled            bool
+	executionDataSyncEnabled     bool
+	executionDataDir             string
+	executionDataArchiveDir      string
+	executionDataArchiveFile     string
+	executionDataStartHeight     uint64
+	executionDataConfig          edrequester.ExecutionDataConfig
+	executionDataIndexingEnabled bool
+	scriptExecutionEnabled       bool
+	executionStateCheckpoint     string
+	apiTimeout                   time.Duration
+	upstreamNodeAddresses        []string
+	upstreamNodePublicKeys       []string
+	upstreamIdentities           flow.IdentityList // the identity list of upstream peers the node uses to forward API requests to
 }
 
 // DefaultObserverServiceConfig defines all the default values for the ObserverServiceConfig
@@ -154,10 +166,15 @@ func DefaultObserverServiceConfig() *ObserverServiceConfig {
 			FetchTimeout:       edrequester.DefaultFetchTimeout,
 			RetryDelay:         edrequester.DefaultRetryDelay,
 			MaxRetryDelay:      edrequester.DefaultMaxRetryDelay,
+			BlockJobTimeout:    jobqueue.DefaultJobTimeoutConfig(),
+			CatchUp:            edrequester.DefaultCatchUpConfig(),
 		},
-		apiTimeout:             3 * time.Second,
-		upstreamNodeAddresses:  []string{},
-		upstreamNodePublicKeys: []string{},
+		executionDataIndexingEnabled: false,
+		scriptExecutionEnabled:       false,
+		executionStateCheckpoint:     "",
+		apiTimeout:                   3 * time.Second,
+		upstreamNodeAddresses:        []string{},
+		upstreamNodePublicKeys:       []string{},
 	}
 }
 
@@ -178,6 +195,9 @@ type ObserverServiceBuilder struct {
 	Pending                 []*flow.Header
 	FollowerCore            module.HotStuffFollower
 	ExecutionDataDownloader execution_data.Downloader
+	ExecutionDataStore      execution_data.ExecutionDataStore
+	ExecutionDataIndexer    *indexer.Indexer
+	Registers               *store.Registers
 	ExecutionDataRequester  state_synchronization.ExecutionDataRequester // for the observer, the sync engine participants provider is the libp2p peer store which is not
 	// available until after the network has started. Hence, a factory function that needs to be called just before
 	// creating the sync engine
@@ -470,6 +490,120 @@ func (builder *ObserverServiceBuilder) BuildExecutionDataRequester() *ObserverSe
 			processedNotifications = bstorage.NewConsumerProgress(ds.DB, module.ConsumeProgressExecutionDataRequesterNotification)
 			return nil
 		}).
+		Module("execution datastore", func(node *cmd.NodeConfig) error {
+			blobstore := blobs.NewBlobstore(ds)
+			builder.ExecutionDataStore = execution_data.NewExecutionDataStore(blobstore, execution_data.DefaultSerializer)
+			return nil
+		}).
+		Module("execution data archive", func(node *cmd.NodeConfig) error {
+			if builder.executionDataArchiveFile != "" {
+				file, err := os.Open(builder.executionDataArchiveFile)
+				if err != nil {
+					return fmt.Errorf("could not open execution data archive: %w", err)
+				}
+
+				builder.ShutdownFunc(func() error {
+					if err := file.Close(); err != nil {
+						return fmt.Errorf("could not close execution data archive: %w", err)
+					}
+					return nil
+				})
+
+				info, err := file.Stat()
+				if err != nil {
+					return fmt.Errorf("could not stat execution data archive: %w", err)
+				}
+
+				reader, err := archive.NewReader(file, info.Size())
+				if err != nil {
+					return fmt.Errorf("could not read execution data archive: %w", err)
+				}
+
+				builder.executionDataConfig.CatchUp.Archive = edrequester.NewFileArchive(reader, blobs.NewBlobstore(ds))
+
+				return nil
+			}
+
+			if builder.executionDataArchiveDir == "" {
+				return nil
+			}
+
+			opts := badger.DefaultOptions
+			opts.ReadOnly = true
+			opts.GcInterval = 0 // garbage collection is not supported by read-only databases
+
+			archiveDs, err := badger.NewDatastore(builder.executionDataArchiveDir, &opts)
+			if err != nil {
+				return fmt.Errorf("could not open execution data archive: %w", err)
+			}
+
+			builder.ShutdownFunc(func() error {
+				if err := archiveDs.Close(); err != nil {
+					return fmt.Errorf("could not close execution data archive: %w", err)
+				}
+				return nil
+			})
+
+			archiveStore := execution_data.NewExecutionDataStore(blobs.NewBlobstore(archiveDs), execution_data.DefaultSerializer)
+			builder.executionDataConfig.CatchUp.Archive = edrequester.NewStoreArchive(archiveStore, builder.ExecutionDataStore)
+
+			return nil
+		}).
+		Module("execution data indexer", func(node *cmd.NodeConfig) error {
+			if !builder.executionDataIndexingEnabled {
+				return nil
+			}
+
+			node.Storage.Events = bstorage.NewEvents(node.Metrics.Cache, node.DB)
+			node.Storage.TransactionResults = bstorage.NewTransactionResults(node.Metrics.Cache, node.DB, bstorage.DefaultCacheSize)
+
+			// index the same heights the requester downloads
+			initHeight := builder.RootBlock.Header.Height
+			if builder.executionDataStartHeight > 0 {
+				initHeight = builder.executionDataStartHeight - 1
+			}
+
+			// execution data has been downloaded up to the height the requester last notified about
+			highestAvailableHeight, err := processedNotifications.ProcessedIndex()
+			if errors.Is(err, storage.ErrNotFound) {
+				highestAvailableHeight = initHeight
+			} else if err != nil {
+				return fmt.Errorf("could not get highest notified execution data height: %w", err)
+			}
+
+			indexerProgress := bstorage.NewConsumerProgress(node.DB, module.ConsumeProgressExecutionDataIndexerBlockHeight)
+
+			var registers storage.RegisterIndex // nil if registers are not indexed
+			if builder.scriptExecutionEnabled {
+				builder.Registers, err = builder.initRegisters(node, indexerProgress)
+				if err != nil {
+					return err
+				}
+				registers = builder.Registers
+			}
+
+			builder.ExecutionDataIndexer = indexer.NewIndexer(
+				node.Logger,
+				initHeight,
+				highestAvailableHeight,
+				indexer.NewIndexerCore(
+					node.Logger,
+					node.DB,
+					node.Storage.Headers,
+					node.Storage.Events,
+					node.Storage.TransactionResults,
+					node.Storage.Collections,
+					node.Storage.Transactions,
+					registers,
+				),
+				builder.ExecutionDataStore,
+				node.Storage.Headers,
+				node.Storage.Results,
+				node.Storage.Seals,
+				indexerProgress,
+			)
+			return nil
+		}).
 		Component("execution data service", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
 			var err error
 			bs, err = node.Network.RegisterBlobService(channels.ExecutionDataService, ds,
@@ -532,8 +666,17 @@ func (builder *ObserverServiceBuilder) BuildExecutionDataRequester() *ObserverSe
 			)
 
 			builder.FollowerDistributor.AddOnBlockFinalizedConsumer(builder.ExecutionDataRequester.OnBlockFinalized)
+			if builder.ExecutionDataIndexer != nil {
+				builder.ExecutionDataRequester.AddOnExecutionDataReceivedConsumer(builder.ExecutionDataIndexer.OnExecutionData)
+			}
 
 			return builder.ExecutionDataRequester, nil
+		}).
+		Component("execution data indexer", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+			if builder.ExecutionDataIndexer == nil {
+				return &module.NoopComponent{}, nil
+			}
+			return builder.ExecutionDataIndexer, nil
 		})
 
 	return builder
@@ -595,6 +738,16 @@ func (builder *ObserverServiceBuilder) extraFlags() {
 		flags.DurationVar(&builder.executionDataConfig.FetchTimeout, "execution-data-fetch-timeout", defaultConfig.executionDataConfig.FetchTimeout, "timeout to use when fetching execution data from the network e.g. 300s")
 		flags.DurationVar(&builder.executionDataConfig.RetryDelay, "execution-data-retry-delay", defaultConfig.executionDataConfig.RetryDelay, "initial delay for exponential backoff when fetching execution data fails e.g. 10s")
 		flags.DurationVar(&builder.executionDataConfig.MaxRetryDelay, "execution-data-max-retry-delay", defaultConfig.executionDataConfig.MaxRetryDelay, "maximum delay for exponential backoff when fetching execution data fails e.g. 5m")
+		flags.DurationVar(&builder.executionDataConfig.BlockJobTimeout.Timeout, "execution-data-job-timeout", defaultConfig.executionDataConfig.BlockJobTimeout.Timeout, "time downloading the execution data of a block may take before it is retried, 0 to disable e.g. 30m")
+		flags.Uint64Var(&builder.executionDataConfig.CatchUp.Threshold, "execution-data-catch-up-threshold", defaultConfig.executionDataConfig.CatchUp.Threshold, "number of heights behind the latest sealed block at which execution data is downloaded in parallel ranges, 0 to disable")
+		flags.Uint64Var(&builder.executionDataConfig.CatchUp.RangeSize, "execution-data-catch-up-range-size", defaultConfig.executionDataConfig.CatchUp.RangeSize, "number of consecutive heights downloaded at a time by a catch-up worker")
+		flags.IntVar(&builder.executionDataConfig.CatchUp.MinWorkers, "execution-data-catch-up-min-workers", defaultConfig.executionDataConfig.CatchUp.MinWorkers, "minimum number of height ranges downloaded in parallel when catching up")
+		flags.IntVar(&builder.executionDataConfig.CatchUp.MaxWorkers, "execution-data-catch-up-max-workers", defaultConfig.executionDataConfig.CatchUp.MaxWorkers, "maximum number of height ranges downloaded in parallel when catching up")
+		flags.StringVar(&builder.executionDataArchiveDir, "execution-data-archive-dir", defaultConfig.executionDataArchiveDir, "execution data blobstore database of another node (e.g. a copy of its execution-data-dir/blobstore) to import execution data from when catching up, instead of downloading it")
+		flags.StringVar(&builder.executionDataArchiveFile, "execution-data-archive-file", defaultConfig.executionDataArchiveFile, "execution data archive file (e.g. written by the export-execution-data util command) to import execution data from when catching up, instead of downloading it")
+		flags.BoolVar(&builder.executionDataIndexingEnabled, "execution-data-indexing-enabled", defaultConfig.executionDataIndexingEnabled, "whether to index events and collections from the downloaded execution data, and serve them without forwarding requests upstream")
+		flags.BoolVar(&builder.scriptExecutionEnabled, "script-execution-enabled", defaultConfig.scriptExecutionEnabled, "whether to index the registers updated by the downloaded execution data, and execute scripts locally without forwarding them upstream")
+		flags.StringVar(&builder.executionStateCheckpoint, "execution-state-checkpoint", defaultConfig.executionStateCheckpoint, "checkpoint of the execution state at the root block the registers are bootstrapped from, defaults to execution-state/root.checkpoint in the bootstrap directory")
 	}).ValidateFlags(func() error {
 		if builder.executionDataSyncEnabled {
 			if builder.executionDataConfig.FetchTimeout <= 0 {
@@ -609,11 +762,81 @@ func (builder *ObserverServiceBuilder) extraFlags() {
 			if builder.executionDataConfig.MaxSearchAhead == 0 {
 				return errors.New("execution-data-max-search-ahead must be greater than 0")
 			}
+			if builder.executionDataConfig.CatchUp.Threshold > 0 {
+				if builder.executionDataConfig.CatchUp.RangeSize == 0 {
+					return errors.New("execution-data-catch-up-range-size must be greater than 0")
+				}
+				if builder.executionDataConfig.CatchUp.MinWorkers <= 0 {
+					return errors.New("execution-data-catch-up-min-workers must be greater than 0")
+				}
+				if builder.executionDataConfig.CatchUp.MaxWorkers < builder.executionDataConfig.CatchUp.MinWorkers {
+					return errors.New("execution-data-catch-up-max-workers must be greater than or equal to execution-data-catch-up-min-workers")
+				}
+				if builder.executionDataArchiveDir != "" && builder.executionDataArchiveFile != "" {
+					return errors.New("execution-data-archive-dir and execution-data-archive-file cannot both be set")
+				}
+			} else if builder.executionDataArchiveDir != "" {
+				return errors.New("execution-data-archive-dir requires execution-data-catch-up-threshold")
+			} else if builder.executionDataArchiveFile != "" {
+				return errors.New("execution-data-archive-file requires execution-data-catch-up-threshold")
+			}
+		} else if builder.executionDataIndexingEnabled {
+			return errors.New("execution-data-indexing-enabled requires execution-data-sync-enabled")
+		}
+		if builder.scriptExecutionEnabled {
+			if !builder.executionDataIndexingEnabled {
+				return errors.New("script-execution-enabled requires execution-data-indexing-enabled")
+			}
+			// the registers are bootstrapped from the execution state at the root block, hence the
+			// execution data of all following blocks is needed
+			if builder.executionDataStartHeight > 0 {
+				return errors.New("script-execution-enabled can't be used with execution-data-start-height")
+			}
+		} else if builder.executionStateCheckpoint != "" {
+			return errors.New("execution-state-checkpoint requires script-execution-enabled")
 		}
 		return nil
 	})
 }
 
+// initRegisters opens the registers indexed from execution data, and bootstraps them from the checkpoint
+// of the execution state at the root block on the first start. The registers must have been indexed up
+// to the height the indexer continues from, since register updates can only be stored consecutively.
+func (builder *ObserverServiceBuilder) initRegisters(node *cmd.NodeConfig, indexerProgress storage.ConsumerProgress) (*store.Registers, error) {
+	db := badgerimpl.ToDB(node.DB)
+
+	registers, err := store.NewRegisters(db)
+	if errors.Is(err, storage.ErrNotFound) {
+		checkpoint := builder.executionStateCheckpoint
+		if checkpoint == "" {
+			checkpoint = filepath.Join(node.BootstrapDir, bootstrap.PathRootCheckpoint)
+		}
+
+		err = indexer.BootstrapRegisters(node.Logger, db, checkpoint, builder.RootBlock.Header.Height)
+		if err != nil {
+			return nil, fmt.Errorf("could not bootstrap registers: %w", err)
+		}
+		registers, err = store.NewRegisters(db)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open registers: %w", err)
+	}
+
+	indexedHeight, err := indexerProgress.ProcessedIndex()
+	if errors.Is(err, storage.ErrNotFound) {
+		indexedHeight = builder.RootBlock.Header.Height
+	} else if err != nil {
+		return nil, fmt.Errorf("could not get highest indexed execution data height: %w", err)
+	}
+	if indexedHeight > registers.LatestHeight() {
+		return nil, fmt.Errorf(
+			"execution data has been indexed up to height %d without registers, which are indexed up to height %d",
+			indexedHeight, registers.LatestHeight())
+	}
+
+	return registers, nil
+}
+
 // initNetwork creates the network.Network implementation with the given metrics, middleware, initial list of network
 // participants and topology used to choose peers from the list of participants. The list of participants can later be
 // updated by calling network.SetIDs.
@@ -1040,6 +1263,29 @@ func (builder *ObserverServiceBuilder) enqueueRPCServer() {
 			)),
 		}
 
+		// serve collections and events indexed from execution data locally
+		if builder.ExecutionDataIndexer != nil {
+			engineBuilder.WithIndexedData(backend.NewIndexedData(
+				builder.ExecutionDataIndexer,
+				node.Storage.Events,
+				node.Storage.TransactionResults,
+			))
+			proxy.Local = engineBuilder.DefaultHandler()
+			proxy.Indexed = builder.ExecutionDataIndexer
+			proxy.Headers = node.Storage.Headers
+		}
+
+		// execute scripts against the registers indexed from execution data locally
+		if builder.Registers != nil {
+			scriptExecutor, err := backend.NewScriptExecutor(node.Logger, metrics.NewNoopCollector(), node.FvmOptions, builder.Registers)
+			if err != nil {
+				return nil, fmt.Errorf("could not create script executor: %w", err)
+			}
+			engineBuilder.WithScriptExecutor(scriptExecutor)
+			proxy.Registers = builder.Registers
+			proxy.State = node.State
+		}
+
 		// build the rpc engine
 		builder.RpcEng, err = engineBuilder.
 			WithNewHandler(proxy).
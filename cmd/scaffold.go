@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -8,6 +9,7 @@ import (
 	"math/rand"
 	"os"
 	"runtime"
+	"runtime/pprof"
 	"strings"
 	"time"
 
@@ -36,6 +38,7 @@ import (
 	"github.com/onflow/flow-go/module/component"
 	"github.com/onflow/flow-go/module/id"
 	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/module/jobqueue"
 	"github.com/onflow/flow-go/module/local"
 	"github.com/onflow/flow-go/module/mempool/herocache"
 	"github.com/onflow/flow-go/module/metrics"
@@ -165,6 +168,13 @@ func (fnb *FlowNodeBuilder) BaseFlags() {
 	fnb.flags.StringVar(&fnb.BaseConfig.AdminKey, "admin-key", defaultConfig.AdminKey, "admin key file (for TLS)")
 	fnb.flags.StringVar(&fnb.BaseConfig.AdminClientCAs, "admin-client-certs", defaultConfig.AdminClientCAs, "admin client certs (for mutual TLS)")
 	fnb.flags.UintVar(&fnb.BaseConfig.AdminMaxMsgSize, "admin-max-response-size", defaultConfig.AdminMaxMsgSize, "admin server max response size in bytes")
+	fnb.flags.StringVar(&fnb.BaseConfig.AdminPolicyFile, "admin-policy-file", defaultConfig.AdminPolicyFile, "admin authorization policy file, mapping client cert identities to allowed commands (all commands are allowed if not set)")
+	fnb.flags.StringVar(&fnb.BaseConfig.AdminAuditLog, "admin-audit-log", defaultConfig.AdminAuditLog, "file to append the audit log of all admin commands to (disabled if not set)")
+	fnb.flags.StringVar(&fnb.BaseConfig.ConfigOverridesStore, "config-overrides-store", defaultConfig.ConfigOverridesStore,
+		"where to persist config values changed with the set-config admin command, so they are re-applied on restart: "+
+			"\"db\" for the node's database, or the path of a JSON file (not persisted if empty)")
+	fnb.flags.DurationVar(&fnb.BaseConfig.ComponentReadyTimeout, "component-ready-timeout", defaultConfig.ComponentReadyTimeout,
+		"time after which components which are not ready are reported with a dump of their goroutines (disabled if 0)")
 
 	fnb.flags.Float64Var(&fnb.BaseConfig.LibP2PResourceManagerConfig.FileDescriptorsRatio, "libp2p-fd-ratio", defaultConfig.LibP2PResourceManagerConfig.FileDescriptorsRatio, "ratio of available file descriptors to be used by libp2p (in (0,1])")
 	fnb.flags.Float64Var(&fnb.BaseConfig.LibP2PResourceManagerConfig.MemoryLimitRatio, "libp2p-memory-limit", defaultConfig.LibP2PResourceManagerConfig.MemoryLimitRatio, "ratio of available memory to be used by libp2p (in (0,1])")
@@ -529,7 +539,11 @@ func (fnb *FlowNodeBuilder) EnqueueAdminServerInit() error {
 		// set up all admin commands
 		for commandName, commandFunc := range fnb.adminCommands {
 			command := commandFunc(fnb.NodeConfig)
-			fnb.adminCommandBootstrapper.RegisterHandler(commandName, command.Handler)
+			if async, ok := command.(commands.AsyncAdminCommand); ok && async.IsAsync() {
+				fnb.adminCommandBootstrapper.RegisterAsyncHandler(commandName, command.Handler)
+			} else {
+				fnb.adminCommandBootstrapper.RegisterHandler(commandName, command.Handler)
+			}
 			fnb.adminCommandBootstrapper.RegisterValidator(commandName, command.Validator)
 		}
 
@@ -558,6 +572,22 @@ func (fnb *FlowNodeBuilder) EnqueueAdminServerInit() error {
 			opts = append(opts, admin.WithTLS(config))
 		}
 
+		if node.AdminPolicyFile != NotSet {
+			policy, err := admin.LoadAuthorizationPolicy(node.AdminPolicyFile)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, admin.WithAuthorizationPolicy(policy))
+		}
+
+		if node.AdminAuditLog != NotSet {
+			auditLog, err := admin.OpenAuditLog(node.AdminAuditLog)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, admin.WithAuditLog(auditLog))
+		}
+
 		runner := fnb.adminCommandBootstrapper.Bootstrap(fnb.Logger, fnb.AdminAddr, opts...)
 
 		return runner, nil
@@ -566,6 +596,27 @@ func (fnb *FlowNodeBuilder) EnqueueAdminServerInit() error {
 	return nil
 }
 
+// initConfigOverrides determines which updatable configs were set by flags, and re-applies the
+// config values persisted by the set-config admin command if persistence is enabled.
+func (fnb *FlowNodeBuilder) initConfigOverrides() error {
+	fnb.ConfigManager.ResolveFlagSources(fnb.flags.Changed)
+
+	switch fnb.BaseConfig.ConfigOverridesStore {
+	case "":
+		return nil
+	case "db":
+		fnb.ConfigManager.SetStore(bstorage.NewConfigOverrides(fnb.DB))
+	default:
+		fnb.ConfigManager.SetStore(updatable_configs.NewFileStore(fnb.BaseConfig.ConfigOverridesStore))
+	}
+
+	err := fnb.ConfigManager.ApplyPersistedOverrides(fnb.Logger)
+	if err != nil {
+		return fmt.Errorf("could not apply persisted config overrides: %w", err)
+	}
+	return nil
+}
+
 func (fnb *FlowNodeBuilder) RegisterBadgerMetrics() error {
 	return metrics.RegisterBadgerMetrics()
 }
@@ -578,12 +629,19 @@ func (fnb *FlowNodeBuilder) EnqueueTracer() {
 
 func (fnb *FlowNodeBuilder) ParseAndPrintFlags() error {
 	// parse configuration parameters
-	pflag.Parse()
+	if fnb.flags == pflag.CommandLine {
+		pflag.Parse()
+	} else {
+		err := fnb.flags.Parse(fnb.BaseConfig.args)
+		if err != nil {
+			return fmt.Errorf("could not parse flags: %w", err)
+		}
+	}
 
 	// print all flags
 	log := fnb.Logger.Info()
 
-	pflag.VisitAll(func(flag *pflag.Flag) {
+	fnb.flags.VisitAll(func(flag *pflag.Flag) {
 		log = log.Str(flag.Name, flag.Value.String())
 	})
 
@@ -799,6 +857,8 @@ func (fnb *FlowNodeBuilder) initProfiler() error {
 	if err != nil {
 		return fmt.Errorf("could not register profiler-trigger config: %w", err)
 	}
+	// triggering a profiler run is an action, it must not be repeated on restart
+	fnb.ConfigManager.SetTransient("profiler-trigger")
 
 	err = fnb.ConfigManager.RegisterUintConfig(
 		"profiler-set-mem-profile-rate",
@@ -1254,32 +1314,23 @@ func (fnb *FlowNodeBuilder) initLocal() error {
 
 func (fnb *FlowNodeBuilder) initFvmOptions() {
 	blockFinder := environment.NewBlockFinder(fnb.Storage.Headers)
-	vmOpts := []fvm.Option{
-		fvm.WithChain(fnb.RootChainID.Chain()),
+	vmOpts := append(fvm.ChainOptions(fnb.RootChainID),
 		fvm.WithBlocks(blockFinder),
-		fvm.WithAccountStorageLimit(true),
-	}
-	if fnb.RootChainID == flow.Testnet || fnb.RootChainID == flow.Sandboxnet || fnb.RootChainID == flow.Mainnet {
-		vmOpts = append(vmOpts,
-			fvm.WithTransactionFeesEnabled(true),
-		)
-	}
-	if fnb.RootChainID == flow.Testnet || fnb.RootChainID == flow.Sandboxnet || fnb.RootChainID == flow.Localnet || fnb.RootChainID == flow.Benchnet {
-		vmOpts = append(vmOpts,
-			fvm.WithContractDeploymentRestricted(false),
-		)
-	}
+	)
 	fnb.FvmOptions = vmOpts
 }
 
 // handleModules initializes the given module.
 func (fnb *FlowNodeBuilder) handleModule(v namedModuleFunc) error {
+	start := time.Now()
 	err := v.fn(fnb.NodeConfig)
 	if err != nil {
 		return fmt.Errorf("module %s initialization failed: %w", v.name, err)
 	}
+	duration := time.Since(start)
+	fnb.StartupGraph.ModuleInitialized(v.name, duration)
 
-	fnb.Logger.Info().Str("module", v.name).Msg("module initialization complete")
+	fnb.Logger.Info().Str("module", v.name).Dur("duration", duration).Msg("module initialization complete")
 	return nil
 }
 
@@ -1311,6 +1362,9 @@ func (fnb *FlowNodeBuilder) handleComponents() error {
 	var err error
 	asyncComponents := []namedComponentFunc{}
 
+	// name of the previous serial component, which the next serial component depends on
+	var parentName []string
+
 	// Run all components
 	for _, f := range fnb.components {
 		// Components with explicit dependencies are not started serially
@@ -1322,8 +1376,10 @@ func (fnb *FlowNodeBuilder) handleComponents() error {
 		started := make(chan struct{})
 
 		if f.errorHandler != nil {
+			fnb.StartupGraph.AddComponent(f.name, component.StartupKindRestartable, parentName, nil)
 			err = fnb.handleRestartableComponent(f, parent, func() { close(started) })
 		} else {
+			fnb.StartupGraph.AddComponent(f.name, component.StartupKindComponent, parentName, nil)
 			err = fnb.handleComponent(f, parent, func() { close(started) })
 		}
 
@@ -1332,12 +1388,14 @@ func (fnb *FlowNodeBuilder) handleComponents() error {
 		}
 
 		parent = started
+		parentName = []string{f.name}
 	}
 
 	// Components with explicit dependencies are run asynchronously, which means dependencies in
 	// the dependency list must be initialized outside of the component factory.
 	for _, f := range asyncComponents {
 		fnb.Logger.Debug().Str("component", f.name).Int("dependencies", len(f.dependencies.components)).Msg("handling component asynchronously")
+		fnb.StartupGraph.AddComponent(f.name, component.StartupKindDependable, nil, f.dependencies.components)
 		err = fnb.handleComponent(f, util.AllReady(f.dependencies.components...), func() {})
 		if err != nil {
 			return fmt.Errorf("could not handle dependable component %s: %w", f.name, err)
@@ -1365,19 +1423,22 @@ func (fnb *FlowNodeBuilder) handleComponent(v namedComponentFunc, dependencies <
 	// gracefully.
 	// Startup for all components will happen in parallel, and components can use their dependencies'
 	// ReadyDoneAware interface to wait until they are ready.
-	fnb.componentBuilder.AddWorker(func(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+	fnb.componentBuilder.AddWorker(labeledWorker(v.name, func(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
 		// wait for the dependencies to be ready before starting
 		if err := util.WaitClosed(ctx, dependencies); err != nil {
 			return
 		}
 
 		logger := fnb.Logger.With().Str("component", v.name).Logger()
+		fnb.StartupGraph.Initializing(v.name)
+		stopWatch := fnb.watchStartup(v.name, logger)
 
 		// First, build the component using the factory method.
 		readyAware, err := v.fn(fnb.NodeConfig)
 		if err != nil {
 			ctx.Throw(fmt.Errorf("component %s initialization failed: %w", v.name, err))
 		}
+		fnb.StartupGraph.Initialized(v.name, readyAware)
 		logger.Info().Msg("component initialization complete")
 
 		// if this is a Component, use the Startable interface to start the component, otherwise
@@ -1388,9 +1449,12 @@ func (fnb *FlowNodeBuilder) handleComponent(v namedComponentFunc, dependencies <
 		}
 
 		// Wait until the component is ready
-		if err := util.WaitClosed(ctx, readyAware.Ready()); err != nil {
+		err = util.WaitClosed(ctx, readyAware.Ready())
+		stopWatch()
+		if err != nil {
 			// The context was cancelled. Continue to shutdown logic.
 			logger.Warn().Msg("component startup aborted")
+			fnb.StartupGraph.Aborted(v.name)
 
 			// Non-idempotent ReadyDoneAware components trigger shutdown by calling Done(). Don't
 			// do that here since it may not be safe if the component is not Ready().
@@ -1399,6 +1463,7 @@ func (fnb *FlowNodeBuilder) handleComponent(v namedComponentFunc, dependencies <
 			}
 		} else {
 			logger.Info().Msg("component startup complete")
+			fnb.StartupGraph.Ready(v.name)
 			ready()
 
 			// Signal to the next component that we're ready.
@@ -1408,11 +1473,13 @@ func (fnb *FlowNodeBuilder) handleComponent(v namedComponentFunc, dependencies <
 		// Component shutdown is signaled by cancelling its context.
 		<-ctx.Done()
 		logger.Info().Msg("component shutdown started")
+		fnb.StartupGraph.Stopping(v.name)
 
 		// Finally, wait until component has finished shutting down.
 		<-readyAware.Done()
 		logger.Info().Msg("component shutdown complete")
-	})
+		fnb.StartupGraph.Done(v.name)
+	}))
 
 	return nil
 }
@@ -1425,7 +1492,7 @@ func (fnb *FlowNodeBuilder) handleComponent(v namedComponentFunc, dependencies <
 //
 // Any irrecoverable errors thrown by the component will be passed to the provided error handler.
 func (fnb *FlowNodeBuilder) handleRestartableComponent(v namedComponentFunc, parentReady <-chan struct{}, started func()) error {
-	fnb.componentBuilder.AddWorker(func(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+	fnb.componentBuilder.AddWorker(labeledWorker(v.name, func(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
 		// wait for the previous component to be ready before starting
 		if err := util.WaitClosed(ctx, parentReady); err != nil {
 			return
@@ -1444,21 +1511,31 @@ func (fnb *FlowNodeBuilder) handleRestartableComponent(v namedComponentFunc, par
 
 		// This may be called multiple times if the component is restarted
 		componentFactory := func() (component.Component, error) {
+			fnb.StartupGraph.Initializing(v.name)
+			stopWatch := fnb.watchStartup(v.name, log)
+
 			c, err := v.fn(fnb.NodeConfig)
 			if err != nil {
+				stopWatch()
 				return nil, err
 			}
+			fnb.StartupGraph.Initialized(v.name, c)
 			log.Info().Msg("component initialization complete")
 
 			go func() {
-				if err := util.WaitClosed(ctx, c.Ready()); err != nil {
+				err := util.WaitClosed(ctx, c.Ready())
+				stopWatch()
+				if err != nil {
 					log.Info().Msg("component startup aborted")
+					fnb.StartupGraph.Aborted(v.name)
 				} else {
 					log.Info().Msg("component startup complete")
+					fnb.StartupGraph.Ready(v.name)
 				}
 
 				<-ctx.Done()
 				log.Info().Msg("component shutdown started")
+				fnb.StartupGraph.Stopping(v.name)
 			}()
 			return c.(component.Component), nil
 		}
@@ -1469,11 +1546,45 @@ func (fnb *FlowNodeBuilder) handleRestartableComponent(v namedComponentFunc, par
 		}
 
 		log.Info().Msg("component shutdown complete")
-	})
+		fnb.StartupGraph.Done(v.name)
+	}))
 
 	return nil
 }
 
+// labeledWorker runs the worker of a component with the component's name set as pprof label, which
+// is inherited by all goroutines started by the component. This allows to attribute goroutine dumps
+// to components.
+func labeledWorker(name string, worker component.ComponentWorker) component.ComponentWorker {
+	return func(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+		pprof.Do(ctx, pprof.Labels(component.ComponentLabel, name), func(context.Context) {
+			worker(ctx, ready)
+		})
+	}
+}
+
+// watchStartup flags the component as stuck in the startup graph if it is not ready within the
+// configured timeout, and logs the goroutine dump of its workers. The returned function must be
+// called once the component is ready, or its startup was aborted.
+func (fnb *FlowNodeBuilder) watchStartup(name string, logger zerolog.Logger) func() {
+	if fnb.BaseConfig.ComponentReadyTimeout <= 0 {
+		return func() {}
+	}
+
+	timer := time.AfterFunc(fnb.BaseConfig.ComponentReadyTimeout, func() {
+		goroutines, err := component.LabeledGoroutines(component.ComponentLabel, name)
+		if err != nil {
+			logger.Error().Err(err).Msg("could not dump goroutines of component")
+		}
+		fnb.StartupGraph.Stuck(name, goroutines)
+		logger.Warn().
+			Dur("timeout", fnb.BaseConfig.ComponentReadyTimeout).
+			Str("goroutines", goroutines).
+			Msg("component is not ready after timeout")
+	})
+	return func() { timer.Stop() }
+}
+
 // ExtraFlags enables binding additional flags beyond those defined in BaseConfig.
 func (fnb *FlowNodeBuilder) ExtraFlags(f func(*pflag.FlagSet)) NodeBuilder {
 	f(fnb.flags)
@@ -1666,6 +1777,14 @@ func WithDB(db *badger.DB) Option {
 	}
 }
 
+// WithArgs makes the node parse its flags from the given arguments instead of the command line.
+// This allows running several nodes in the same process, each with its own configuration.
+func WithArgs(args []string) Option {
+	return func(config *BaseConfig) {
+		config.args = args
+	}
+}
+
 // FlowNode creates a new Flow node builder with the given name.
 func FlowNode(role string, opts ...Option) *FlowNodeBuilder {
 	config := DefaultBaseConfig()
@@ -1674,14 +1793,21 @@ func FlowNode(role string, opts ...Option) *FlowNodeBuilder {
 		opt(config)
 	}
 
+	flags := pflag.CommandLine
+	if config.args != nil {
+		flags = pflag.NewFlagSet(role, pflag.ContinueOnError)
+	}
+
 	builder := &FlowNodeBuilder{
 		NodeConfig: &NodeConfig{
 			BaseConfig:              *config,
 			Logger:                  zerolog.New(os.Stderr),
 			PeerManagerDependencies: NewDependencyList(),
 			ConfigManager:           updatable_configs.NewManager(),
+			DeadLetterQueues:        jobqueue.NewDeadLetterQueues(),
+			StartupGraph:            component.NewStartupGraph(),
 		},
-		flags:                    pflag.CommandLine,
+		flags:                    flags,
 		adminCommandBootstrapper: admin.NewCommandRunnerBootstrapper(),
 		adminCommands:            make(map[string]func(*NodeConfig) commands.AdminCommand),
 		componentBuilder:         component.NewComponentManagerBuilder(),
@@ -1728,6 +1854,8 @@ func (fnb *FlowNodeBuilder) RegisterDefaultAdminCommands() {
 		return common.NewGetConfigCommand(config.ConfigManager)
 	}).AdminCommand("set-config", func(config *NodeConfig) commands.AdminCommand {
 		return common.NewSetConfigCommand(config.ConfigManager)
+	}).AdminCommand("unset-config", func(config *NodeConfig) commands.AdminCommand {
+		return common.NewUnsetConfigCommand(config.ConfigManager)
 	}).AdminCommand("list-configs", func(config *NodeConfig) commands.AdminCommand {
 		return common.NewListConfigCommand(config.ConfigManager)
 	}).AdminCommand("read-blocks", func(config *NodeConfig) commands.AdminCommand {
@@ -1738,6 +1866,14 @@ func (fnb *FlowNodeBuilder) RegisterDefaultAdminCommands() {
 		return storageCommands.NewReadSealsCommand(config.State, config.Storage.Seals, config.Storage.Index)
 	}).AdminCommand("get-latest-identity", func(config *NodeConfig) commands.AdminCommand {
 		return common.NewGetIdentityCommand(config.IdentityProvider)
+	}).AdminCommand("list-dead-letters", func(config *NodeConfig) commands.AdminCommand {
+		return common.NewListDeadLettersCommand(config.DeadLetterQueues)
+	}).AdminCommand("retry-dead-letter", func(config *NodeConfig) commands.AdminCommand {
+		return common.NewRetryDeadLetterCommand(config.DeadLetterQueues)
+	}).AdminCommand("skip-dead-letter", func(config *NodeConfig) commands.AdminCommand {
+		return common.NewSkipDeadLetterCommand(config.DeadLetterQueues)
+	}).AdminCommand("get-component-graph", func(config *NodeConfig) commands.AdminCommand {
+		return common.NewGetComponentGraphCommand(config.StartupGraph)
 	})
 }
 
@@ -1820,6 +1956,12 @@ func (fnb *FlowNodeBuilder) onStart() error {
 		return fmt.Errorf("could not handle modules: %w", err)
 	}
 
+	// all updatable configs are registered by the modules, re-apply the persisted
+	// config values before the components start
+	if err := fnb.initConfigOverrides(); err != nil {
+		return err
+	}
+
 	// run all components
 	return fnb.handleComponents()
 }
@@ -0,0 +1,59 @@
+package checkpoint_verify
+
+import (
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/ledger/complete/wal"
+)
+
+var (
+	flagCheckpoint string
+	flagNWorker    int
+	flagMemoryMB   uint64
+)
+
+var Cmd = &cobra.Command{
+	Use:   "checkpoint-verify",
+	Short: "Verifies the integrity of all the part files of a V6 checkpoint, and lists the corrupt part files",
+	Long: `Verifies the integrity of all the part files of a V6 checkpoint without loading the tries into memory.
+Every part file is checked for existence, truncation and checksum mismatches, the hash of every trie node
+is recomputed, and the root hashes of all tries are compared with the root hashes recorded in the checkpoint.
+Corrupt part files are printed, so only those need to be fetched again.`,
+	Run: run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagCheckpoint, "checkpoint", "",
+		"checkpoint file to verify, e.g. /var/flow/data/execution/checkpoint.00000123")
+	_ = Cmd.MarkFlagRequired("checkpoint")
+
+	Cmd.Flags().IntVar(&flagNWorker, "n-worker", 4,
+		"number of part files to verify concurrently")
+
+	Cmd.Flags().Uint64Var(&flagMemoryMB, "memory-limit-mb", 4096,
+		"maximum memory in MB for the node hashes of the part files being verified concurrently, "+
+			"parts which do not fit are verified after the other parts have been verified")
+}
+
+func run(*cobra.Command, []string) {
+	dir, fileName := filepath.Split(flagCheckpoint)
+
+	log.Info().Msgf("verifying checkpoint %v", flagCheckpoint)
+	corrupt, err := wal.VerifyCheckpointV6(dir, fileName, flagNWorker, flagMemoryMB*1024*1024, &log.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("error while verifying checkpoint")
+	}
+
+	if len(corrupt) == 0 {
+		log.Info().Msgf("checkpoint %v is valid", flagCheckpoint)
+		return
+	}
+
+	for _, part := range corrupt {
+		log.Error().Str("file", filepath.Join(dir, part.FileName)).Err(part.Err).Msg("corrupt part file")
+	}
+	log.Fatal().Int("corrupt_parts", len(corrupt)).Msgf("checkpoint %v is corrupt", flagCheckpoint)
+}
@@ -0,0 +1,77 @@
+package db_migration
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/storage/migration"
+	"github.com/onflow/flow-go/storage/operation/badgerimpl"
+	"github.com/onflow/flow-go/storage/operation/pebbleimpl"
+)
+
+var (
+	flagBadgerDir string
+	flagPebbleDir string
+	flagBatchSize int
+	flagVerify    bool
+)
+
+var Cmd = &cobra.Command{
+	Use:   "db-migration",
+	Short: "Copies the protocol state database from Badger into Pebble",
+	Long: `Copies every key-value pair of the protocol state database in Badger into an empty Pebble database,
+and verifies the copy unless --verify=false is set.
+Nodes still store their protocol state in Badger. Only the stores in storage/store (job consumer progress
+and registers) run on Pebble so far, so a node can't be started from the Pebble copy yet.`,
+	Run: run,
+}
+
+func init() {
+
+	Cmd.Flags().StringVar(&flagBadgerDir, "datadir", "",
+		"directory that stores the protocol state in Badger")
+	_ = Cmd.MarkFlagRequired("datadir")
+
+	Cmd.Flags().StringVar(&flagPebbleDir, "pebbledir", "",
+		"directory to store the protocol state in Pebble, should be empty")
+	_ = Cmd.MarkFlagRequired("pebbledir")
+
+	Cmd.Flags().IntVar(&flagBatchSize, "batch-size", migration.DefaultBatchSize,
+		"number of key-value pairs written per batch")
+
+	Cmd.Flags().BoolVar(&flagVerify, "verify", true,
+		"verify that the Pebble database matches the Badger database after copying")
+}
+
+func run(*cobra.Command, []string) {
+	log.Info().
+		Str("datadir", flagBadgerDir).
+		Str("pebbledir", flagPebbleDir).
+		Int("batch_size", flagBatchSize).
+		Msg("flags")
+
+	badgerDB := common.InitStorage(flagBadgerDir)
+	defer badgerDB.Close()
+
+	pebbleDB, err := pebbleimpl.OpenDefaultPebbleDB(flagPebbleDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not open pebble db")
+	}
+	defer pebbleDB.Close()
+
+	err = migration.CopyFromBadgerToPebble(log.Logger, badgerDB, pebbleDB, flagBatchSize)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not copy badger db into pebble db")
+	}
+
+	if flagVerify {
+		log.Info().Msg("verifying pebble db")
+		err = migration.Verify(badgerimpl.ToReader(badgerDB), pebbleimpl.ToReader(pebbleDB))
+		if err != nil {
+			log.Fatal().Err(err).Msg("pebble db does not match badger db")
+		}
+	}
+
+	log.Info().Msg("migration finished")
+}
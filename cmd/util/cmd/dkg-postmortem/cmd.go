@@ -0,0 +1,85 @@
+package dkg_postmortem
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/model/dkg"
+	dkgmodule "github.com/onflow/flow-go/module/dkg"
+	"github.com/onflow/flow-go/storage/badger"
+)
+
+var (
+	flagDatadir       string
+	flagEpochCounter  uint64
+	flagDKGInstanceID string
+)
+
+// This command reconstructs the outcome of a DKG from the transcript recorded by a consensus node,
+// reporting for each participant whether it was offline, misbehaved, or sent all expected messages.
+// The transcript only reflects the messages observed by the node it was recorded by, so transcripts
+// of several nodes should be compared before concluding that a participant was offline.
+var Cmd = &cobra.Command{
+	Use:   "dkg-postmortem",
+	Short: "Reconstructs which DKG participants misbehaved or were offline from a consensus node's DKG transcript",
+	Run:   run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagDatadir, "datadir", "",
+		"directory that stores the protocol state")
+	_ = Cmd.MarkFlagRequired("datadir")
+
+	Cmd.Flags().Uint64Var(&flagEpochCounter, "epoch", 0,
+		"counter of the epoch the DKG was run for")
+	Cmd.Flags().StringVar(&flagDKGInstanceID, "dkg-instance-id", "",
+		"DKG instance ID of the transcript, which is derived from the chain ID and --epoch by default")
+}
+
+func run(cmd *cobra.Command, _ []string) {
+	if flagDKGInstanceID == "" && !cmd.Flags().Changed("epoch") {
+		log.Fatal().Msg("either --epoch or --dkg-instance-id must be specified")
+	}
+
+	db := common.InitStorage(flagDatadir)
+	defer db.Close()
+
+	dkgInstanceID := flagDKGInstanceID
+	if dkgInstanceID == "" {
+		storages := common.InitStorages(db)
+		state, err := common.InitProtocolState(db, storages)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not init protocol state")
+		}
+		chainID, err := state.Params().ChainID()
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not get chain ID")
+		}
+		dkgInstanceID = dkgmodule.CanonicalInstanceID(chainID, flagEpochCounter)
+	}
+
+	log := log.With().Str("dkg_instance_id", dkgInstanceID).Logger()
+
+	transcript, err := badger.NewDKGTranscripts(db).ByInstanceID(dkgInstanceID)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not read dkg transcript")
+	}
+	log.Info().Msgf("read dkg transcript with %d events", len(transcript.Events))
+
+	postMortem := dkg.AnalyzeTranscript(transcript)
+	for _, p := range postMortem.Participants {
+		if p.Status == dkg.ParticipantOK {
+			continue
+		}
+		log.Warn().
+			Int("index", p.Index).
+			Str("node_id", p.NodeID.String()).
+			Ints("unanswered_complaints", p.UnansweredComplaints).
+			Strs("disqualifications", p.Disqualifications).
+			Strs("misbehavior_flags", p.MisbehaviorFlags).
+			Msgf("participant is %s", p.Status)
+	}
+
+	common.PrettyPrint(postMortem)
+}
@@ -0,0 +1,154 @@
+package export_execution_data
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	badger "github.com/ipfs/go-ds-badger2"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/executiondatasync/archive"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+)
+
+var (
+	flagDatadir      string
+	flagBlobstoreDir string
+	flagOutput       string
+	flagStartHeight  uint64
+	flagEndHeight    uint64
+	flagSkipMissing  bool
+)
+
+// example:
+// ./util export-execution-data --datadir /var/flow/data/protocol --blobstore-dir /var/flow/data/execution_data/blobstore --output ./execution_data.archive --start-height 100 --end-height 200
+var Cmd = &cobra.Command{
+	Use:   "export-execution-data",
+	Short: "exports the execution data of a range of sealed blocks into an archive file",
+	Run:   run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagDatadir, "datadir", "/var/flow/data/protocol",
+		"directory of the protocol state")
+
+	Cmd.Flags().StringVar(&flagBlobstoreDir, "blobstore-dir", "",
+		"directory of the execution data blobstore")
+	_ = Cmd.MarkFlagRequired("blobstore-dir")
+
+	Cmd.Flags().StringVar(&flagOutput, "output", "",
+		"path of the archive file to write")
+	_ = Cmd.MarkFlagRequired("output")
+
+	Cmd.Flags().Uint64Var(&flagStartHeight, "start-height", 0,
+		"first height to export")
+	_ = Cmd.MarkFlagRequired("start-height")
+
+	Cmd.Flags().Uint64Var(&flagEndHeight, "end-height", 0,
+		"last height to export")
+	_ = Cmd.MarkFlagRequired("end-height")
+
+	Cmd.Flags().BoolVar(&flagSkipMissing, "skip-missing", false,
+		"skip heights whose execution data is not in the blobstore, e.g. because it was pruned, instead of failing")
+}
+
+func run(*cobra.Command, []string) {
+	if flagStartHeight > flagEndHeight {
+		log.Fatal().Msgf("start height %d must not be above end height %d", flagStartHeight, flagEndHeight)
+	}
+
+	log.Info().
+		Uint64("start_height", flagStartHeight).
+		Uint64("end_height", flagEndHeight).
+		Msg("exporting execution data")
+
+	exported, err := ExportExecutionData(context.Background(), flagDatadir, flagBlobstoreDir, flagOutput, flagStartHeight, flagEndHeight, flagSkipMissing)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not export execution data")
+	}
+
+	log.Info().
+		Int("heights", exported).
+		Str("output", flagOutput).
+		Msg("execution data exported")
+}
+
+// ExportExecutionData writes the execution data of the sealed blocks from startHeight to endHeight
+// to an archive file at outputPath, and returns the number of heights exported.
+// If skipMissing is set, heights whose execution data is not in the blobstore are skipped.
+func ExportExecutionData(ctx context.Context, dataDir string, blobstoreDir string, outputPath string, startHeight uint64, endHeight uint64, skipMissing bool) (int, error) {
+	db := common.InitStorage(dataDir)
+	defer db.Close()
+	storages := common.InitStorages(db)
+
+	opts := badger.DefaultOptions
+	opts.ReadOnly = true
+	opts.GcInterval = 0 // garbage collection is not supported by read-only databases
+
+	ds, err := badger.NewDatastore(blobstoreDir, &opts)
+	if err != nil {
+		return 0, fmt.Errorf("could not open blobstore: %w", err)
+	}
+	defer ds.Close()
+	blobstore := blobs.NewBlobstore(ds)
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return 0, fmt.Errorf("could not create archive file: %w", err)
+	}
+	defer file.Close()
+
+	writer, err := archive.NewWriter(file)
+	if err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	for height := startHeight; height <= endHeight; height++ {
+		header, err := storages.Headers.ByHeight(height)
+		if err != nil {
+			return exported, fmt.Errorf("could not get header for height %d: %w", height, err)
+		}
+		blockID := header.ID()
+
+		seal, err := storages.Seals.FinalizedSealForBlock(blockID)
+		if err != nil {
+			return exported, fmt.Errorf("could not get seal for height %d: %w", height, err)
+		}
+
+		result, err := storages.Results.ByID(seal.ResultID)
+		if err != nil {
+			return exported, fmt.Errorf("could not get execution result for height %d: %w", height, err)
+		}
+
+		record, err := archive.NewRecord(ctx, blobstore, height, blockID, result.ExecutionDataID)
+		if skipMissing && execution_data.IsBlobNotFoundError(err) {
+			log.Warn().Err(err).Uint64("height", height).Msg("skipping height with missing execution data")
+			continue
+		}
+		if err != nil {
+			return exported, fmt.Errorf("could not read execution data for height %d: %w", height, err)
+		}
+
+		err = writer.Write(record)
+		if err != nil {
+			return exported, fmt.Errorf("could not write execution data for height %d: %w", height, err)
+		}
+		exported++
+
+		if exported%1000 == 0 {
+			log.Info().Uint64("height", height).Int("exported", exported).Msg("exporting execution data")
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return exported, fmt.Errorf("could not finish archive: %w", err)
+	}
+
+	return exported, file.Sync()
+}
@@ -0,0 +1,151 @@
+package export_execution_data
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	badgerds "github.com/ipfs/go-ds-badger2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	import_execution_data "github.com/onflow/flow-go/cmd/util/cmd/import-execution-data"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestExportImportExecutionData tests that the execution data of sealed blocks exported to an
+// archive is imported unchanged, and that missing execution data is only skipped on request.
+func TestExportImportExecutionData(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		ctx := context.Background()
+		dataDir := filepath.Join(dir, "protocol")
+		blobstoreDir := filepath.Join(dir, "blobstore")
+
+		eds, ids := storeSealedExecutionData(t, dataDir, blobstoreDir, 10, 14)
+
+		t.Run("round trip", func(t *testing.T) {
+			output := filepath.Join(dir, "round-trip.archive")
+			exported, err := ExportExecutionData(ctx, dataDir, blobstoreDir, output, 10, 14, false)
+			require.NoError(t, err)
+			assert.Equal(t, 5, exported)
+
+			targetDir := filepath.Join(dir, "round-trip-target")
+			imported, err := import_execution_data.ImportExecutionData(ctx, dataDir, targetDir, output, 0, 100)
+			require.NoError(t, err)
+			assert.Equal(t, 5, imported)
+
+			withExecutionDataStore(t, targetDir, func(store execution_data.ExecutionDataStore) {
+				for i, id := range ids {
+					ed, err := store.GetExecutionData(ctx, id)
+					require.NoError(t, err)
+					assert.Equal(t, eds[i], ed)
+				}
+			})
+		})
+
+		// remove the root blob of the execution data at height 12, e.g. because it was pruned
+		withBlobstore(t, blobstoreDir, func(blobstore blobs.Blobstore) {
+			require.NoError(t, blobstore.DeleteBlob(ctx, flow.IdToCid(ids[2])))
+		})
+
+		t.Run("missing execution data", func(t *testing.T) {
+			output := filepath.Join(dir, "missing.archive")
+			_, err := ExportExecutionData(ctx, dataDir, blobstoreDir, output, 10, 14, false)
+			assert.True(t, execution_data.IsBlobNotFoundError(err))
+		})
+
+		t.Run("skip missing execution data", func(t *testing.T) {
+			output := filepath.Join(dir, "skip-missing.archive")
+			exported, err := ExportExecutionData(ctx, dataDir, blobstoreDir, output, 10, 14, true)
+			require.NoError(t, err)
+			assert.Equal(t, 4, exported)
+
+			targetDir := filepath.Join(dir, "skip-missing-target")
+			imported, err := import_execution_data.ImportExecutionData(ctx, dataDir, targetDir, output, 0, 100)
+			require.NoError(t, err)
+			assert.Equal(t, 4, imported)
+
+			withExecutionDataStore(t, targetDir, func(store execution_data.ExecutionDataStore) {
+				for i, id := range ids {
+					ed, err := store.GetExecutionData(ctx, id)
+					if i == 2 {
+						assert.True(t, execution_data.IsBlobNotFoundError(err))
+						continue
+					}
+					require.NoError(t, err)
+					assert.Equal(t, eds[i], ed)
+				}
+			})
+		})
+	})
+}
+
+// storeSealedExecutionData stores finalized and sealed blocks from startHeight to endHeight in the
+// protocol database in dataDir, and their execution data in the blobstore in blobstoreDir.
+func storeSealedExecutionData(t *testing.T, dataDir string, blobstoreDir string, startHeight uint64, endHeight uint64) ([]*execution_data.BlockExecutionData, []flow.Identifier) {
+	var eds []*execution_data.BlockExecutionData
+	var ids []flow.Identifier
+
+	db := common.InitStorage(dataDir)
+	defer db.Close()
+
+	withExecutionDataStore(t, blobstoreDir, func(store execution_data.ExecutionDataStore) {
+		for height := startHeight; height <= endHeight; height++ {
+			header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(height))
+			blockID := header.ID()
+
+			ed := unittest.BlockExecutionDataFixture(unittest.WithBlockExecutionDataBlockID(blockID))
+			id, err := store.AddExecutionData(context.Background(), ed)
+			require.NoError(t, err)
+
+			result := unittest.ExecutionResultFixture(
+				unittest.WithExecutionResultBlockID(blockID),
+				unittest.WithExecutionDataID(id),
+			)
+			seal := unittest.Seal.Fixture(unittest.Seal.WithResult(result))
+
+			err = operation.RetryOnConflict(db.Update, func(tx *badger.Txn) error {
+				for _, op := range []func(*badger.Txn) error{
+					operation.InsertHeader(blockID, header),
+					operation.IndexBlockHeight(height, blockID),
+					operation.InsertExecutionResult(result),
+					operation.InsertSeal(seal.ID(), seal),
+					operation.IndexFinalizedSealByBlockID(blockID, seal.ID()),
+				} {
+					if err := op(tx); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+
+			eds = append(eds, ed)
+			ids = append(ids, id)
+		}
+	})
+
+	return eds, ids
+}
+
+func withBlobstore(t *testing.T, dir string, f func(blobs.Blobstore)) {
+	ds, err := badgerds.NewDatastore(dir, &badgerds.DefaultOptions)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, ds.Close())
+	}()
+
+	f(blobs.NewBlobstore(ds))
+}
+
+func withExecutionDataStore(t *testing.T, dir string, f func(execution_data.ExecutionDataStore)) {
+	withBlobstore(t, dir, func(blobstore blobs.Blobstore) {
+		f(execution_data.NewExecutionDataStore(blobstore, execution_data.DefaultSerializer))
+	})
+}
@@ -4,6 +4,7 @@ package transactions
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/state/protocol"
@@ -13,6 +14,7 @@ import (
 type BlockData struct {
 	BlockID     flow.Identifier   `json:"block_id"`
 	Height      uint64            `json:"height"`
+	Timestamp   time.Time         `json:"timestamp"`
 	Collections []*CollectionData `json:"collections"`
 }
 
@@ -22,10 +24,14 @@ type CollectionData struct {
 }
 
 type Transaction struct {
-	TxID   string `json:"tx_id"`
-	Index  int    `json:"tx_index"`
-	Script string `json:"script"`
-	Payer  string `json:"payer_address"`
+	TxID        string   `json:"tx_id"`
+	Index       int      `json:"tx_index"`
+	Script      string   `json:"script"`
+	Arguments   []string `json:"arguments"` // JSON-CDC encoded
+	GasLimit    uint64   `json:"gas_limit"`
+	Proposer    string   `json:"proposer_address"`
+	Payer       string   `json:"payer_address"`
+	Authorizers []string `json:"authorizer_addresses"`
 }
 
 type Finder struct {
@@ -60,6 +66,7 @@ func (f *Finder) RunByHeightRange(startHeight uint64, endHeight uint64, run func
 		err = run(&BlockData{
 			BlockID:     blockID,
 			Height:      height,
+			Timestamp:   header.Timestamp,
 			Collections: cols,
 		})
 
@@ -102,11 +109,24 @@ func FindBlockTransactions(
 		}
 		txs := make([]*Transaction, 0, len(col.Transactions))
 		for txIndex, tx := range col.Transactions {
+			arguments := make([]string, 0, len(tx.Arguments))
+			for _, argument := range tx.Arguments {
+				arguments = append(arguments, string(argument))
+			}
+			authorizers := make([]string, 0, len(tx.Authorizers))
+			for _, authorizer := range tx.Authorizers {
+				authorizers = append(authorizers, authorizer.String())
+			}
+
 			txs = append(txs, &Transaction{
-				TxID:   tx.ID().String(),
-				Index:  txIndex,
-				Script: string(tx.Script),
-				Payer:  tx.Payer.String(),
+				TxID:        tx.ID().String(),
+				Index:       txIndex,
+				Script:      string(tx.Script),
+				Arguments:   arguments,
+				GasLimit:    tx.GasLimit,
+				Proposer:    tx.ProposalKey.Address.String(),
+				Payer:       tx.Payer.String(),
+				Authorizers: authorizers,
 			})
 		}
 		cols = append(cols, &CollectionData{
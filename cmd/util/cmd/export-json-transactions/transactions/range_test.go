@@ -90,6 +90,19 @@ func TestFindBlockTransactions(t *testing.T) {
 			fetched[0].Collections[0].Transactions[0].TxID,
 			col1.Collection.Transactions[0].ID().String(),
 		)
+		require.Equal(t, b1.Header.Timestamp, fetched[0].Timestamp)
+
+		// the transaction shape needed to replay it is exported
+		tx := col1.Collection.Transactions[0]
+		exported := fetched[0].Collections[0].Transactions[0]
+		require.Equal(t, string(tx.Script), exported.Script)
+		require.Len(t, exported.Arguments, len(tx.Arguments))
+		require.Equal(t, tx.GasLimit, exported.GasLimit)
+		require.Equal(t, tx.ProposalKey.Address.String(), exported.Proposer)
+		require.Len(t, exported.Authorizers, len(tx.Authorizers))
+		for i, authorizer := range tx.Authorizers {
+			require.Equal(t, authorizer.String(), exported.Authorizers[i])
+		}
 
 		// unhappy path: endHeight is lower than startHeight
 		_, err = f.GetByHeightRange(5, 4)
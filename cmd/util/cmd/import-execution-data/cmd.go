@@ -0,0 +1,166 @@
+package import_execution_data
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+
+	badger "github.com/ipfs/go-ds-badger2"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/executiondatasync/archive"
+	"github.com/onflow/flow-go/storage"
+)
+
+var (
+	flagDatadir      string
+	flagBlobstoreDir string
+	flagInput        string
+	flagStartHeight  uint64
+	flagEndHeight    uint64
+)
+
+// example:
+// ./util import-execution-data --blobstore-dir /var/flow/data/execution_data/blobstore --input ./execution_data.archive --datadir /var/flow/data/protocol
+var Cmd = &cobra.Command{
+	Use:   "import-execution-data",
+	Short: "imports the execution data from an archive file into an execution data blobstore",
+	Run:   run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagBlobstoreDir, "blobstore-dir", "",
+		"directory of the execution data blobstore to import into")
+	_ = Cmd.MarkFlagRequired("blobstore-dir")
+
+	Cmd.Flags().StringVar(&flagInput, "input", "",
+		"path of the archive file to import")
+	_ = Cmd.MarkFlagRequired("input")
+
+	Cmd.Flags().StringVar(&flagDatadir, "datadir", "",
+		"directory of the protocol state. if set, only execution data committed to by the sealed results in the protocol state is imported")
+
+	Cmd.Flags().Uint64Var(&flagStartHeight, "start-height", 0,
+		"first height to import, defaults to the first height in the archive")
+
+	Cmd.Flags().Uint64Var(&flagEndHeight, "end-height", math.MaxUint64,
+		"last height to import, defaults to the last height in the archive")
+}
+
+func run(*cobra.Command, []string) {
+	if flagStartHeight > flagEndHeight {
+		log.Fatal().Msgf("start height %d must not be above end height %d", flagStartHeight, flagEndHeight)
+	}
+
+	log.Info().Str("input", flagInput).Msg("importing execution data")
+
+	imported, err := ImportExecutionData(context.Background(), flagDatadir, flagBlobstoreDir, flagInput, flagStartHeight, flagEndHeight)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not import execution data")
+	}
+
+	log.Info().Int("heights", imported).Msg("execution data imported")
+}
+
+// ImportExecutionData imports the execution data of the heights from startHeight to endHeight in
+// the archive file at inputPath into the blobstore, and returns the number of heights imported.
+// The CIDs of all blobs are verified while they are read from the archive. If dataDir is set, the
+// execution data of each height must also match the sealed execution result in the protocol state.
+func ImportExecutionData(ctx context.Context, dataDir string, blobstoreDir string, inputPath string, startHeight uint64, endHeight uint64) (int, error) {
+	var sealedExecutionDataID func(height uint64) (flow.Identifier, error)
+	if dataDir != "" {
+		db := common.InitStorage(dataDir)
+		defer db.Close()
+		sealedExecutionDataID = sealedExecutionDataIDs(common.InitStorages(db))
+	}
+
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return 0, fmt.Errorf("could not open archive file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("could not stat archive file: %w", err)
+	}
+
+	reader, err := archive.NewReader(file, info.Size())
+	if err != nil {
+		return 0, fmt.Errorf("could not read archive: %w", err)
+	}
+
+	err = os.MkdirAll(blobstoreDir, 0700)
+	if err != nil {
+		return 0, fmt.Errorf("could not create blobstore directory: %w", err)
+	}
+
+	ds, err := badger.NewDatastore(blobstoreDir, &badger.DefaultOptions)
+	if err != nil {
+		return 0, fmt.Errorf("could not open blobstore: %w", err)
+	}
+	defer ds.Close()
+	blobstore := blobs.NewBlobstore(ds)
+
+	imported := 0
+	for _, entry := range reader.Index() {
+		if entry.Height < startHeight || entry.Height > endHeight {
+			continue
+		}
+
+		if sealedExecutionDataID != nil {
+			expected, err := sealedExecutionDataID(entry.Height)
+			if err != nil {
+				return imported, fmt.Errorf("could not get sealed execution data ID for height %d: %w", entry.Height, err)
+			}
+			if entry.ExecutionDataID != expected {
+				return imported, fmt.Errorf("execution data ID %v for height %d does not match sealed execution data ID %v", entry.ExecutionDataID, entry.Height, expected)
+			}
+		}
+
+		record, err := reader.Read(entry)
+		if err != nil {
+			return imported, err
+		}
+
+		err = archive.Import(ctx, blobstore, record)
+		if err != nil {
+			return imported, err
+		}
+		imported++
+
+		if imported%1000 == 0 {
+			log.Info().Uint64("height", entry.Height).Int("imported", imported).Msg("importing execution data")
+		}
+	}
+
+	return imported, nil
+}
+
+// sealedExecutionDataIDs returns a function which looks up the execution data ID of the sealed
+// execution result for a height.
+func sealedExecutionDataIDs(storages *storage.All) func(height uint64) (flow.Identifier, error) {
+	return func(height uint64) (flow.Identifier, error) {
+		header, err := storages.Headers.ByHeight(height)
+		if err != nil {
+			return flow.ZeroID, fmt.Errorf("could not get header: %w", err)
+		}
+
+		seal, err := storages.Seals.FinalizedSealForBlock(header.ID())
+		if err != nil {
+			return flow.ZeroID, fmt.Errorf("could not get seal: %w", err)
+		}
+
+		result, err := storages.Results.ByID(seal.ResultID)
+		if err != nil {
+			return flow.ZeroID, fmt.Errorf("could not get execution result: %w", err)
+		}
+
+		return result.ExecutionDataID, nil
+	}
+}
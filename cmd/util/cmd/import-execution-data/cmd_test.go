@@ -0,0 +1,140 @@
+package import_execution_data
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	badgerds "github.com/ipfs/go-ds-badger2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/executiondatasync/archive"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestImportExecutionData_SealedIDMismatch tests that execution data which does not match the
+// sealed execution result in the protocol state is rejected, and only checked if a datadir is set.
+func TestImportExecutionData_SealedIDMismatch(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		ctx := context.Background()
+		input := filepath.Join(dir, "execution_data.archive")
+		dataDir := filepath.Join(dir, "protocol")
+
+		eds, ids := writeArchive(t, input, 10, 3)
+
+		// the execution data ID sealed for height 12 differs from the archived one
+		sealedIDs := []flow.Identifier{ids[0], ids[1], unittest.IdentifierFixture()}
+		storeSealedResults(t, dataDir, 10, sealedIDs)
+
+		targetDir := filepath.Join(dir, "checked")
+		imported, err := ImportExecutionData(ctx, dataDir, targetDir, input, 0, 100)
+		require.ErrorContains(t, err, "does not match sealed execution data ID")
+		assert.Equal(t, 2, imported)
+
+		withExecutionDataStore(t, targetDir, func(store execution_data.ExecutionDataStore) {
+			for i := 0; i < 2; i++ {
+				ed, err := store.GetExecutionData(ctx, ids[i])
+				require.NoError(t, err)
+				assert.Equal(t, eds[i], ed)
+			}
+
+			_, err := store.GetExecutionData(ctx, ids[2])
+			assert.True(t, execution_data.IsBlobNotFoundError(err))
+		})
+
+		// heights up to the mismatch can be imported
+		imported, err = ImportExecutionData(ctx, dataDir, filepath.Join(dir, "range"), input, 10, 11)
+		require.NoError(t, err)
+		assert.Equal(t, 2, imported)
+
+		// without a protocol state, only the CIDs of the blobs are verified
+		imported, err = ImportExecutionData(ctx, "", filepath.Join(dir, "unchecked"), input, 0, 100)
+		require.NoError(t, err)
+		assert.Equal(t, 3, imported)
+	})
+}
+
+// writeArchive writes the execution data of count blocks to an archive file at path, at heights
+// starting at startHeight.
+func writeArchive(t *testing.T, path string, startHeight uint64, count int) ([]*execution_data.BlockExecutionData, []flow.Identifier) {
+	ctx := context.Background()
+	blobstore := blobs.NewBlobstore(dssync.MutexWrap(datastore.NewMapDatastore()))
+	store := execution_data.NewExecutionDataStore(blobstore, execution_data.DefaultSerializer)
+
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	writer, err := archive.NewWriter(file)
+	require.NoError(t, err)
+
+	eds := make([]*execution_data.BlockExecutionData, count)
+	ids := make([]flow.Identifier, count)
+	for i := range eds {
+		eds[i] = unittest.BlockExecutionDataFixture()
+		ids[i], err = store.AddExecutionData(ctx, eds[i])
+		require.NoError(t, err)
+
+		record, err := archive.NewRecord(ctx, blobstore, startHeight+uint64(i), eds[i].BlockID, ids[i])
+		require.NoError(t, err)
+		require.NoError(t, writer.Write(record))
+	}
+	require.NoError(t, writer.Close())
+
+	return eds, ids
+}
+
+// storeSealedResults stores finalized blocks starting at startHeight in the protocol database in
+// dataDir, sealed with results committing to the given execution data IDs.
+func storeSealedResults(t *testing.T, dataDir string, startHeight uint64, executionDataIDs []flow.Identifier) {
+	db := common.InitStorage(dataDir)
+	defer db.Close()
+
+	for i, executionDataID := range executionDataIDs {
+		height := startHeight + uint64(i)
+		header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(height))
+		blockID := header.ID()
+
+		result := unittest.ExecutionResultFixture(
+			unittest.WithExecutionResultBlockID(blockID),
+			unittest.WithExecutionDataID(executionDataID),
+		)
+		seal := unittest.Seal.Fixture(unittest.Seal.WithResult(result))
+
+		err := operation.RetryOnConflict(db.Update, func(tx *badger.Txn) error {
+			for _, op := range []func(*badger.Txn) error{
+				operation.InsertHeader(blockID, header),
+				operation.IndexBlockHeight(height, blockID),
+				operation.InsertExecutionResult(result),
+				operation.InsertSeal(seal.ID(), seal),
+				operation.IndexFinalizedSealByBlockID(blockID, seal.ID()),
+			} {
+				if err := op(tx); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+}
+
+func withExecutionDataStore(t *testing.T, dir string, f func(execution_data.ExecutionDataStore)) {
+	ds, err := badgerds.NewDatastore(dir, &badgerds.DefaultOptions)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, ds.Close())
+	}()
+
+	f(execution_data.NewExecutionDataStore(blobs.NewBlobstore(ds), execution_data.DefaultSerializer))
+}
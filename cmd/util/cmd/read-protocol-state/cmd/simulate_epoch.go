@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/model/flow"
+	protocolbadger "github.com/onflow/flow-go/state/protocol/badger"
+)
+
+var (
+	flagEpochSetup  string
+	flagEpochCommit string
+)
+
+var SimulateEpochCmd = &cobra.Command{
+	Use:   "simulate-epoch",
+	Short: "Check proposed EpochSetup and EpochCommit events against the current protocol state, without modifying it",
+	Long: `Check proposed EpochSetup and EpochCommit service events (JSON encoded) as if they were sealed
+in a child of the latest finalized block. The events are validated with the same checks as the
+protocol state applies when the events are sealed, and every failure is reported.`,
+	Run: runSimulateEpoch,
+}
+
+func init() {
+	rootCmd.AddCommand(SimulateEpochCmd)
+
+	SimulateEpochCmd.Flags().StringVar(&flagEpochSetup, "setup", "",
+		"file containing the JSON encoded EpochSetup event")
+
+	SimulateEpochCmd.Flags().StringVar(&flagEpochCommit, "commit", "",
+		"file containing the JSON encoded EpochCommit event")
+}
+
+func readServiceEvent(path string, event interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read file: %w", err)
+	}
+	err = json.Unmarshal(data, event)
+	if err != nil {
+		return fmt.Errorf("could not decode service event: %w", err)
+	}
+	return nil
+}
+
+func runSimulateEpoch(*cobra.Command, []string) {
+	if flagEpochSetup == "" && flagEpochCommit == "" {
+		log.Fatal().Msg("missing flag, try --setup or --commit")
+	}
+
+	var setup *flow.EpochSetup
+	if flagEpochSetup != "" {
+		setup = new(flow.EpochSetup)
+		err := readServiceEvent(flagEpochSetup, setup)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", flagEpochSetup).Msg("could not read EpochSetup event")
+		}
+	}
+
+	var commit *flow.EpochCommit
+	if flagEpochCommit != "" {
+		commit = new(flow.EpochCommit)
+		err := readServiceEvent(flagEpochCommit, commit)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", flagEpochCommit).Msg("could not read EpochCommit event")
+		}
+	}
+
+	db := common.InitStorage(flagDatadir)
+	defer db.Close()
+
+	storages := common.InitStorages(db)
+	state, err := common.InitProtocolState(db, storages)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not init protocol state")
+	}
+
+	badgerState, ok := state.(*protocolbadger.State)
+	if !ok {
+		log.Fatal().Msgf("unexpected protocol state type %T", state)
+	}
+
+	violations, err := badgerState.SimulateEpochTransition(setup, commit)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not simulate epoch transition")
+	}
+
+	if len(violations) == 0 {
+		log.Info().Msg("the service events are valid, the epoch transition would succeed")
+		return
+	}
+
+	for _, violation := range violations {
+		log.Error().Msg(violation.Error())
+	}
+	log.Fatal().Int("failures", len(violations)).Msg("the service events are invalid, they would trigger epoch fallback mode")
+}
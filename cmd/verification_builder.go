@@ -31,12 +31,14 @@ import (
 	"github.com/onflow/flow-go/module/chunks"
 	modulecompliance "github.com/onflow/flow-go/module/compliance"
 	finalizer "github.com/onflow/flow-go/module/finalizer/consensus"
+	"github.com/onflow/flow-go/module/jobqueue"
 	"github.com/onflow/flow-go/module/mempool"
 	"github.com/onflow/flow-go/module/mempool/stdmap"
 	"github.com/onflow/flow-go/module/metrics"
 	"github.com/onflow/flow-go/state/protocol"
 	badgerState "github.com/onflow/flow-go/state/protocol/badger"
 	"github.com/onflow/flow-go/state/protocol/blocktimer"
+	"github.com/onflow/flow-go/storage"
 	"github.com/onflow/flow-go/storage/badger"
 )
 
@@ -53,6 +55,8 @@ type VerificationConfig struct {
 	blockWorkers uint64 // number of blocks processed in parallel.
 	chunkWorkers uint64 // number of chunks processed in parallel.
 
+	chunkJobTimeout jobqueue.JobTimeoutConfig // timeout and retries of chunk jobs before they are dead-lettered.
+
 	stopAtHeight uint64 // height to stop the node on
 }
 
@@ -64,7 +68,9 @@ type VerificationNodeBuilder struct {
 func NewVerificationNodeBuilder(nodeBuilder *FlowNodeBuilder) *VerificationNodeBuilder {
 	return &VerificationNodeBuilder{
 		FlowNodeBuilder: nodeBuilder,
-		verConf:         VerificationConfig{},
+		verConf: VerificationConfig{
+			chunkJobTimeout: jobqueue.DefaultJobTimeoutConfig(),
+		},
 	}
 }
 
@@ -81,6 +87,8 @@ func (v *VerificationNodeBuilder) LoadFlags() {
 			flags.Uint64Var(&v.verConf.blockWorkers, "block-workers", blockconsumer.DefaultBlockWorkers, "maximum number of blocks being processed in parallel")
 			flags.Uint64Var(&v.verConf.chunkWorkers, "chunk-workers", chunkconsumer.DefaultChunkWorkers, "maximum number of execution nodes a chunk data pack request is dispatched to")
 			flags.Uint64Var(&v.verConf.stopAtHeight, "stop-at-height", 0, "height to stop the node at (0 to disable)")
+			flags.DurationVar(&v.verConf.chunkJobTimeout.Timeout, "chunk-job-timeout", v.verConf.chunkJobTimeout.Timeout, "time a chunk job may take before it is retried, 0 to disable (e.g. 10m)")
+			flags.UintVar(&v.verConf.chunkJobTimeout.MaxAttempts, "chunk-job-max-attempts", v.verConf.chunkJobTimeout.MaxAttempts, "number of attempts of a timed out chunk job before it is dead-lettered")
 		})
 }
 
@@ -90,8 +98,8 @@ func (v *VerificationNodeBuilder) LoadComponentsAndModules() {
 
 		chunkStatuses        *stdmap.ChunkStatuses    // used in fetcher engine
 		chunkRequests        *stdmap.ChunkRequests    // used in requester engine
-		processedChunkIndex  *badger.ConsumerProgress // used in chunk consumer
-		processedBlockHeight *badger.ConsumerProgress // used in block consumer
+		processedChunkIndex  storage.ConsumerProgress // used in chunk consumer
+		processedBlockHeight storage.ConsumerProgress // used in block consumer
 		chunkQueue           *badger.ChunksQueue      // used in chunk consumer
 
 		syncCore            *chainsync.Core   // used in follower engine
@@ -196,7 +204,7 @@ func (v *VerificationNodeBuilder) LoadComponentsAndModules() {
 				node.FvmOptions...,
 			)
 			vmCtx := fvm.NewContext(fvmOptions...)
-			chunkVerifier := chunks.NewChunkVerifier(vm, vmCtx, node.Logger)
+			chunkVerifier := chunks.NewChunkVerifier(vm, vmCtx, node.Logger, node.State)
 			approvalStorage := badger.NewResultApprovals(node.Metrics.Cache, node.DB)
 			verifierEng, err = verifier.New(
 				node.Logger,
@@ -252,7 +260,13 @@ func (v *VerificationNodeBuilder) LoadComponentsAndModules() {
 				processedChunkIndex,
 				chunkQueue,
 				fetcherEngine,
-				v.verConf.chunkWorkers)
+				v.verConf.chunkWorkers,
+				jobqueue.WithJobTimeout(v.verConf.chunkJobTimeout),
+				jobqueue.WithDeadLetters(
+					module.ConsumeProgressVerificationChunkIndex,
+					badger.NewJobDeadLetters(node.DB, module.ConsumeProgressVerificationChunkIndex),
+					node.DeadLetterQueues,
+				))
 
 			err = node.Metrics.Mempool.Register(metrics.ResourceChunkConsumer, chunkConsumer.Size)
 			if err != nil {
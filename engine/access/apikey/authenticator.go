@@ -0,0 +1,267 @@
+package apikey
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/time/rate"
+
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/irrecoverable"
+)
+
+// Header is the HTTP header (and lower-cased gRPC metadata key) carrying the API key.
+const Header = "X-API-Key"
+
+// DefaultReloadInterval is the default interval at which the config file is checked for changes.
+const DefaultReloadInterval = 10 * time.Second
+
+// rejection reasons reported in metrics
+const (
+	reasonMissingKey    = "missing_key"
+	reasonUnknownKey    = "unknown_key"
+	reasonQuotaExceeded = "quota_exceeded"
+)
+
+// unidentifiedKeyName is the key name reported in metrics for requests without a valid key.
+const unidentifiedKeyName = "unidentified"
+
+var (
+	// ErrMissingKey is returned when a request does not carry an API key.
+	ErrMissingKey = errors.New("missing api key")
+	// ErrUnknownKey is returned when a request carries an API key which is not configured.
+	ErrUnknownKey = errors.New("unknown api key")
+	// ErrQuotaExceeded is returned when the key's quota for the method class is exhausted.
+	ErrQuotaExceeded = errors.New("api key quota exceeded")
+)
+
+// keyState holds the token buckets of a single API key.
+type keyState struct {
+	name     string
+	limiters map[string]*rate.Limiter // method class -> limiter, nil entries are not limited
+}
+
+// limiter returns the limiter for the given method class, or nil if the class is not limited.
+func (k *keyState) limiter(class string) *rate.Limiter {
+	if limiter, ok := k.limiters[class]; ok {
+		return limiter
+	}
+	return k.limiters[DefaultMethodClass]
+}
+
+// Authenticator authenticates Access API requests by API key and enforces the per-key
+// token-bucket quotas defined in the API key config file.
+//
+// The config file is checked periodically and reloaded when it changes. Token buckets of
+// keys and classes present before and after a reload keep their state, so editing the file
+// does not reset quotas. If the new config is invalid, it is rejected and the previous config
+// remains in effect.
+type Authenticator struct {
+	component.Component
+
+	log            zerolog.Logger
+	metrics        module.AccessAPIKeyMetrics
+	path           string
+	reloadInterval time.Duration
+
+	mu      sync.RWMutex
+	keys    map[[sha256.Size]byte]*keyState // sha256(key) -> key state
+	classes map[string]string               // method -> method class
+	modTime time.Time
+	size    int64
+}
+
+// NewAuthenticator creates a new Authenticator from the config file at the given path.
+// The config file is reloaded on change at the given interval once the component is started.
+//
+// No errors are expected during normal operation; an error indicates that the config file
+// could not be read or is invalid.
+func NewAuthenticator(
+	log zerolog.Logger,
+	metrics module.AccessAPIKeyMetrics,
+	path string,
+	reloadInterval time.Duration,
+) (*Authenticator, error) {
+	a := &Authenticator{
+		log:            log.With().Str("component", "api_key_authenticator").Str("config", path).Logger(),
+		metrics:        metrics,
+		path:           path,
+		reloadInterval: reloadInterval,
+		keys:           make(map[[sha256.Size]byte]*keyState),
+		classes:        make(map[string]string),
+	}
+
+	err := a.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	a.Component = component.NewComponentManagerBuilder().
+		AddWorker(a.reloadWorker).
+		Build()
+
+	return a, nil
+}
+
+// Authorize checks that the given API key is valid and that its quota for the class of the given
+// method is not exhausted. It returns the name of the key on success.
+//
+// Expected errors during normal operation:
+//   - ErrMissingKey if the key is empty
+//   - ErrUnknownKey if the key is not configured
+//   - ErrQuotaExceeded if the key's quota for the method class is exhausted
+func (a *Authenticator) Authorize(key string, method string) (string, error) {
+	a.mu.RLock()
+	class, ok := a.classes[method]
+	if !ok {
+		class = DefaultMethodClass
+	}
+	state, known := a.keys[sha256.Sum256([]byte(key))]
+	a.mu.RUnlock()
+
+	if key == "" {
+		a.metrics.APIKeyRequestRejected(unidentifiedKeyName, class, reasonMissingKey)
+		return "", ErrMissingKey
+	}
+	if !known {
+		a.metrics.APIKeyRequestRejected(unidentifiedKeyName, class, reasonUnknownKey)
+		return "", ErrUnknownKey
+	}
+
+	limiter := state.limiter(class)
+	if limiter != nil && !limiter.Allow() {
+		a.metrics.APIKeyRequestRejected(state.name, class, reasonQuotaExceeded)
+		return state.name, fmt.Errorf("%w for method class %s", ErrQuotaExceeded, class)
+	}
+
+	a.metrics.APIKeyRequestAccepted(state.name, class)
+	return state.name, nil
+}
+
+// Reload reads the config file and replaces the active config with it.
+//
+// No errors are expected during normal operation; an error indicates that the config file
+// could not be read or is invalid, in which case the active config is left unchanged.
+func (a *Authenticator) Reload() error {
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return fmt.Errorf("could not stat api key config: %w", err)
+	}
+
+	config, err := ReadConfig(a.path)
+	if err != nil {
+		return err
+	}
+
+	classes := make(map[string]string)
+	for class, methods := range config.MethodClasses {
+		for _, method := range methods {
+			classes[method] = class
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// index the current key states by name, so that buckets survive reloads and key rotation
+	previous := make(map[string]*keyState, len(a.keys))
+	for _, state := range a.keys {
+		previous[state.name] = state
+	}
+
+	keys := make(map[[sha256.Size]byte]*keyState, len(config.Keys))
+	for _, keyConfig := range config.Keys {
+		state := &keyState{
+			name:     keyConfig.Name,
+			limiters: make(map[string]*rate.Limiter, len(keyConfig.Quotas)),
+		}
+		old := previous[keyConfig.Name]
+		for class, quota := range keyConfig.Quotas {
+			var limiter *rate.Limiter
+			if old != nil {
+				limiter = old.limiters[class]
+			}
+			if limiter == nil {
+				limiter = rate.NewLimiter(rate.Limit(quota.Rate), quota.burst())
+			} else {
+				limiter.SetLimit(rate.Limit(quota.Rate))
+				limiter.SetBurst(quota.burst())
+			}
+			state.limiters[class] = limiter
+		}
+		keys[sha256.Sum256([]byte(keyConfig.Key))] = state
+	}
+
+	a.keys = keys
+	a.classes = classes
+	a.modTime = info.ModTime()
+	a.size = info.Size()
+
+	a.log.Info().
+		Int("keys", len(keys)).
+		Int("method_classes", len(config.MethodClasses)).
+		Msg("loaded api key config")
+
+	return nil
+}
+
+// reloadWorker periodically checks the config file for changes and reloads it.
+func (a *Authenticator) reloadWorker(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+	ready()
+
+	ticker := time.NewTicker(a.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !a.changed() {
+			continue
+		}
+
+		err := a.Reload()
+		if err != nil {
+			// keep serving with the previous config, the operator is expected to fix the file
+			a.log.Error().Err(err).Msg("failed to reload api key config, keeping previous config")
+			// do not retry until the file changes again
+			a.markSeen()
+		}
+	}
+}
+
+// changed returns true if the config file was modified since it was last loaded.
+func (a *Authenticator) changed() bool {
+	info, err := os.Stat(a.path)
+	if err != nil {
+		a.log.Warn().Err(err).Msg("could not stat api key config")
+		return false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return !info.ModTime().Equal(a.modTime) || info.Size() != a.size
+}
+
+// markSeen records the current modification time and size of the config file, so that
+// a broken file is reported only once.
+func (a *Authenticator) markSeen() {
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.modTime = info.ModTime()
+	a.size = info.Size()
+}
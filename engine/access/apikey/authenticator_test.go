@@ -0,0 +1,273 @@
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func testConfig() Config {
+	return Config{
+		MethodClasses: map[string][]string{
+			"scripts": {"ExecuteScriptAtLatestBlock", "executeScript"},
+		},
+		Keys: []KeyConfig{
+			{
+				Name: "team-a",
+				Key:  "key-a",
+				Quotas: map[string]Quota{
+					"scripts":          {Rate: 0.001, Burst: 2},
+					DefaultMethodClass: {Rate: 0.001, Burst: 3},
+				},
+			},
+			{
+				Name: "team-b",
+				Key:  "key-b",
+				Quotas: map[string]Quota{
+					"scripts": {Rate: 0, Burst: 0},
+				},
+			},
+		},
+	}
+}
+
+func writeConfig(t *testing.T, path string, config Config) {
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func newTestAuthenticator(t *testing.T, config Config) (*Authenticator, string) {
+	path := filepath.Join(t.TempDir(), "api_keys.json")
+	writeConfig(t, path, config)
+
+	authenticator, err := NewAuthenticator(zerolog.Nop(), metrics.NewNoopCollector(), path, 10*time.Millisecond)
+	require.NoError(t, err)
+	return authenticator, path
+}
+
+func TestAuthorize(t *testing.T) {
+	authenticator, _ := newTestAuthenticator(t, testConfig())
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := authenticator.Authorize("", "Ping")
+		require.ErrorIs(t, err, ErrMissingKey)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := authenticator.Authorize("key-c", "Ping")
+		require.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("per class quota", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			name, err := authenticator.Authorize("key-a", "ExecuteScriptAtLatestBlock")
+			require.NoError(t, err)
+			assert.Equal(t, "team-a", name)
+		}
+		// gRPC and REST methods of the same class share the bucket
+		_, err := authenticator.Authorize("key-a", "executeScript")
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+
+		// other classes are unaffected
+		for i := 0; i < 3; i++ {
+			_, err := authenticator.Authorize("key-a", "GetAccount")
+			require.NoError(t, err)
+		}
+		_, err = authenticator.Authorize("key-a", "Ping")
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("unlimited and disabled classes", func(t *testing.T) {
+		// team-b has no default quota
+		for i := 0; i < 100; i++ {
+			_, err := authenticator.Authorize("key-b", "Ping")
+			require.NoError(t, err)
+		}
+		// and is not allowed to execute scripts at all
+		_, err := authenticator.Authorize("key-b", "ExecuteScriptAtLatestBlock")
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+}
+
+func TestReload(t *testing.T) {
+	config := testConfig()
+	authenticator, path := newTestAuthenticator(t, config)
+
+	// exhaust the scripts quota of team-a
+	for i := 0; i < 2; i++ {
+		_, err := authenticator.Authorize("key-a", "executeScript")
+		require.NoError(t, err)
+	}
+
+	// rotate the key of team-a and add a new key
+	config.Keys[0].Key = "key-a2"
+	config.Keys = append(config.Keys, KeyConfig{Name: "team-c", Key: "key-c"})
+	writeConfig(t, path, config)
+	require.NoError(t, authenticator.Reload())
+
+	_, err := authenticator.Authorize("key-a", "executeScript")
+	require.ErrorIs(t, err, ErrUnknownKey)
+	// the bucket of team-a survives the reload
+	_, err = authenticator.Authorize("key-a2", "executeScript")
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	_, err = authenticator.Authorize("key-c", "executeScript")
+	require.NoError(t, err)
+
+	// an invalid config is rejected and the previous config stays in effect
+	config.Keys = append(config.Keys, KeyConfig{Name: "team-c", Key: "key-d"})
+	writeConfig(t, path, config)
+	require.Error(t, authenticator.Reload())
+	_, err = authenticator.Authorize("key-c", "Ping")
+	require.NoError(t, err)
+}
+
+func TestReloadWorker(t *testing.T) {
+	authenticator, path := newTestAuthenticator(t, testConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signalerCtx, errChan := irrecoverable.WithSignaler(ctx)
+	go unittest.FailOnIrrecoverableError(t, ctx.Done(), errChan)
+	authenticator.Start(signalerCtx)
+	unittest.RequireCloseBefore(t, authenticator.Ready(), time.Second, "authenticator not ready")
+	defer func() {
+		cancel()
+		unittest.RequireCloseBefore(t, authenticator.Done(), time.Second, "authenticator not done")
+	}()
+
+	_, err := authenticator.Authorize("key-c", "Ping")
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	config := testConfig()
+	config.Keys = append(config.Keys, KeyConfig{Name: "team-c", Key: "key-c"})
+	writeConfig(t, path, config)
+
+	require.Eventually(t, func() bool {
+		_, err := authenticator.Authorize("key-c", "Ping")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestConfigValidation(t *testing.T) {
+	t.Run("method in multiple classes", func(t *testing.T) {
+		config := testConfig()
+		config.MethodClasses["other"] = []string{"executeScript"}
+		require.Error(t, config.Validate())
+	})
+
+	t.Run("reserved class", func(t *testing.T) {
+		config := testConfig()
+		config.MethodClasses[DefaultMethodClass] = []string{"Ping"}
+		require.Error(t, config.Validate())
+	})
+
+	t.Run("duplicate key", func(t *testing.T) {
+		config := testConfig()
+		config.Keys[1].Key = config.Keys[0].Key
+		require.Error(t, config.Validate())
+	})
+
+	t.Run("unknown class", func(t *testing.T) {
+		config := testConfig()
+		config.Keys[1].Quotas["unknown"] = Quota{Rate: 1}
+		require.Error(t, config.Validate())
+	})
+
+	t.Run("default burst", func(t *testing.T) {
+		assert.Equal(t, 3, Quota{Rate: 2.5}.burst())
+		assert.Equal(t, 1, Quota{Rate: 0.1}.burst())
+		assert.Equal(t, 5, Quota{Rate: 2.5, Burst: 5}.burst())
+		assert.Equal(t, 0, Quota{}.burst())
+	})
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	authenticator, _ := newTestAuthenticator(t, testConfig())
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/flow.access.AccessAPI/ExecuteScriptAtLatestBlock"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(metadataKey, key))
+	}
+
+	_, err := authenticator.UnaryServerInterceptor(context.Background(), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = authenticator.UnaryServerInterceptor(withKey("key-c"), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	for i := 0; i < 2; i++ {
+		resp, err := authenticator.UnaryServerInterceptor(withKey("key-a"), nil, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	}
+
+	_, err = authenticator.UnaryServerInterceptor(withKey("key-a"), nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+// testServerStream is a grpc.ServerStream with the given context.
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	authenticator, _ := newTestAuthenticator(t, testConfig())
+
+	info := &grpc.StreamServerInfo{
+		FullMethod:     "/flow.executiondata.ExecutionDataAPI/SubscribeEvents",
+		IsServerStream: true,
+	}
+	handled := 0
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		handled++
+		return nil
+	}
+	withKey := func(key string) grpc.ServerStream {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(metadataKey, key))
+		return &testServerStream{ctx: ctx}
+	}
+
+	err := authenticator.StreamServerInterceptor(nil, &testServerStream{ctx: context.Background()}, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	err = authenticator.StreamServerInterceptor(nil, withKey("key-c"), info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// the stream method falls into the default class
+	for i := 0; i < 3; i++ {
+		err := authenticator.StreamServerInterceptor(nil, withKey("key-a"), info, handler)
+		require.NoError(t, err)
+	}
+
+	err = authenticator.StreamServerInterceptor(nil, withKey("key-a"), info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// key-b has no quota for the default class, so its streams are not limited
+	err = authenticator.StreamServerInterceptor(nil, withKey("key-b"), info, handler)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, handled)
+}
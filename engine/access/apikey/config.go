@@ -0,0 +1,144 @@
+package apikey
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// DefaultMethodClass is the method class of all API methods which are not explicitly assigned to a
+// class in the config file. A key's quota for the default class also applies to every class for
+// which the key has no explicit quota.
+const DefaultMethodClass = "default"
+
+// Config is the content of the API key config file.
+//
+// Example:
+//
+//	{
+//	  "method_classes": {
+//	    "scripts": ["ExecuteScriptAtLatestBlock", "ExecuteScriptAtBlockID", "executeScript"],
+//	    "transactions": ["SendTransaction", "createTransaction"]
+//	  },
+//	  "keys": [
+//	    {
+//	      "name": "team-a",
+//	      "key": "0b7a1c...",
+//	      "quotas": {
+//	        "scripts": {"rate": 10, "burst": 20},
+//	        "default": {"rate": 100}
+//	      }
+//	    }
+//	  ]
+//	}
+//
+// Method names are gRPC method names (e.g. "GetAccountAtLatestBlock") or REST route names
+// (e.g. "getAccount"), so a single class can cover both servers.
+type Config struct {
+	// MethodClasses maps a class name to the API methods belonging to it.
+	MethodClasses map[string][]string `json:"method_classes"`
+	// Keys is the list of API keys allowed to use the API.
+	Keys []KeyConfig `json:"keys"`
+}
+
+// KeyConfig defines a single API key.
+type KeyConfig struct {
+	// Name identifies the key holder in logs and metrics. The key itself is never logged.
+	Name string `json:"name"`
+	// Key is the secret value clients send in the API key header.
+	Key string `json:"key"`
+	// Quotas maps a method class to the token-bucket quota of this key for that class.
+	// Classes without a quota fall back to the quota of the default class; if neither is
+	// defined, requests of that class are not limited for this key.
+	Quotas map[string]Quota `json:"quotas"`
+}
+
+// Quota defines a token bucket.
+type Quota struct {
+	// Rate is the number of requests per second refilled into the bucket. A rate of 0 with
+	// a burst of 0 denies all requests of the class.
+	Rate float64 `json:"rate"`
+	// Burst is the size of the bucket. If zero and the rate is positive, the burst defaults
+	// to the rate (rounded up, at least 1).
+	Burst int `json:"burst"`
+}
+
+// burst returns the effective burst of the quota.
+func (q Quota) burst() int {
+	if q.Burst > 0 || q.Rate <= 0 {
+		return q.Burst
+	}
+	burst := int(q.Rate)
+	if float64(burst) < q.Rate {
+		burst++
+	}
+	return burst
+}
+
+// ReadConfig reads and validates the API key config from the given file.
+func ReadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read api key config: %w", err)
+	}
+
+	var config Config
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode api key config: %w", err)
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid api key config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// Validate checks that the config is self-consistent: key names and key values are unique and
+// non-empty, every method belongs to at most one class, and every quota references a known class.
+func (c *Config) Validate() error {
+	methods := make(map[string]string)
+	for class, classMethods := range c.MethodClasses {
+		if class == DefaultMethodClass {
+			return fmt.Errorf("method class %q is reserved", DefaultMethodClass)
+		}
+		for _, method := range classMethods {
+			if other, ok := methods[method]; ok {
+				return fmt.Errorf("method %s is assigned to both classes %s and %s", method, other, class)
+			}
+			methods[method] = class
+		}
+	}
+
+	names := make(map[string]struct{}, len(c.Keys))
+	secrets := make(map[string]struct{}, len(c.Keys))
+	for i, key := range c.Keys {
+		if key.Name == "" {
+			return fmt.Errorf("key %d has no name", i)
+		}
+		if key.Key == "" {
+			return fmt.Errorf("key %s has an empty value", key.Name)
+		}
+		if _, ok := names[key.Name]; ok {
+			return fmt.Errorf("duplicate key name %s", key.Name)
+		}
+		if _, ok := secrets[key.Key]; ok {
+			return fmt.Errorf("key %s has the same value as another key", key.Name)
+		}
+		names[key.Name] = struct{}{}
+		secrets[key.Key] = struct{}{}
+
+		for class, quota := range key.Quotas {
+			if _, ok := c.MethodClasses[class]; !ok && class != DefaultMethodClass {
+				return fmt.Errorf("key %s has a quota for unknown method class %s", key.Name, class)
+			}
+			if quota.Rate < 0 || quota.Burst < 0 {
+				return fmt.Errorf("key %s has a negative quota for method class %s", key.Name, class)
+			}
+		}
+	}
+
+	return nil
+}
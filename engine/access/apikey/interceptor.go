@@ -0,0 +1,77 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadataKey is the gRPC metadata key carrying the API key. gRPC metadata keys are lower case.
+var metadataKey = strings.ToLower(Header)
+
+// UnaryServerInterceptor rejects requests which do not carry a valid API key in the gRPC metadata,
+// or whose key has exhausted its quota for the class of the requested method.
+func (a *Authenticator) UnaryServerInterceptor(ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+	err = a.authorizeCall(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// StreamServerInterceptor rejects streams which do not carry a valid API key in the gRPC metadata,
+// or whose key has exhausted its quota for the class of the requested method. Opening a stream
+// counts as a single request against the quota.
+func (a *Authenticator) StreamServerInterceptor(srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+
+	err := a.authorizeCall(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, stream)
+}
+
+// authorizeCall authorizes a call of the given gRPC method using the API key from the metadata
+// of the incoming context. It returns a gRPC status error if the call is rejected.
+func (a *Authenticator) authorizeCall(ctx context.Context, fullMethod string) error {
+	// remove the package name (e.g. "/flow.access.AccessAPI/Ping" to "Ping")
+	methodName := filepath.Base(fullMethod)
+
+	var key string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(metadataKey); len(values) > 0 {
+			key = values[0]
+		}
+	}
+
+	name, err := a.Authorize(key, methodName)
+	if err != nil {
+		a.log.Trace().
+			Str("method", methodName).
+			Str("key_name", name).
+			Err(err).
+			Msg("request rejected")
+
+		if errors.Is(err, ErrQuotaExceeded) {
+			return status.Errorf(codes.ResourceExhausted, "%s: %v, please retry later.", fullMethod, err)
+		}
+		return status.Errorf(codes.Unauthenticated, "%v", err)
+	}
+
+	return nil
+}
@@ -18,27 +18,66 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/engine/access/rpc/backend"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/engine/protocol"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/module/state_synchronization"
+	stateprotocol "github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/storage"
 	"github.com/onflow/flow-go/utils/grpcutils"
 )
 
+const (
+	// routeLocal is recorded for requests served from the observer's own data
+	routeLocal = "local"
+	// routeUpstream is recorded for requests forwarded upstream because they can't be served locally
+	routeUpstream = "upstream"
+	// routeFallback is recorded for requests forwarded upstream after the local data turned out to be missing
+	routeFallback = "fallback"
+)
+
 // FlowAccessAPIRouter is a structure that represents the routing proxy algorithm.
 // It splits requests between a local and a remote API service.
+//
+// Header, block, network and protocol snapshot reads are served from the follower state by Observer.
+// If Local is set, collection reads are served by it when the collection is stored locally, event
+// reads are served by it when the execution data of all requested blocks has been indexed, and scripts
+// are executed by it when the registers at the requested block have been indexed. Everything else is
+// forwarded upstream.
 type FlowAccessAPIRouter struct {
 	Logger   zerolog.Logger
 	Metrics  *metrics.ObserverCollector
 	Upstream *FlowAccessAPIForwarder
 	Observer *protocol.Handler
-}
 
-func (h *FlowAccessAPIRouter) log(handler, rpc string, err error) {
+	// Local serves reads from the data stored by the node itself. Optional.
+	Local access.AccessAPIServer
+	// Indexed reports the heights whose execution data has been indexed. Optional, when not set no
+	// event reads are served locally.
+	Indexed state_synchronization.IndexReporter
+	// Registers is the index of the registers updated by the indexed execution data. Optional, when
+	// not set scripts are forwarded upstream.
+	Registers storage.RegisterIndex
+	// Headers is used to look up the heights of requested blocks. Required if Indexed or Registers is set.
+	Headers storage.Headers
+	// State is used to look up the latest sealed block. Required if Registers is set.
+	State stateprotocol.State
+}
+
+func (h *FlowAccessAPIRouter) log(route, rpc string, err error) {
+	handler := "upstream"
+	if route == routeLocal {
+		handler = "observer"
+	}
+
 	code := status.Code(err)
 	h.Metrics.RecordRPC(handler, rpc, code)
+	h.Metrics.RecordRouteDecision(rpc, route)
 
 	logger := h.Logger.With().
 		Str("handler", handler).
+		Str("route", route).
 		Str("grpc_method", rpc).
 		Str("grpc_code", code.String()).
 		Logger()
@@ -51,6 +90,53 @@ func (h *FlowAccessAPIRouter) log(handler, rpc string, err error) {
 	logger.Info().Msg("request succeeded")
 }
 
+// isIndexed returns whether the execution data of all blocks in the given height range has been
+// indexed locally.
+func (h *FlowAccessAPIRouter) isIndexed(startHeight, endHeight uint64) bool {
+	if h.Local == nil || h.Indexed == nil || startHeight > endHeight {
+		return false
+	}
+
+	lowest, err := h.Indexed.LowestIndexedHeight()
+	if err != nil {
+		return false
+	}
+	highest, err := h.Indexed.HighestIndexedHeight()
+	if err != nil {
+		return false
+	}
+
+	return startHeight >= lowest && endHeight <= highest
+}
+
+// areIndexed returns whether the execution data of all given blocks has been indexed locally.
+func (h *FlowAccessAPIRouter) areIndexed(blockIDs [][]byte) bool {
+	if h.Local == nil || h.Indexed == nil || len(blockIDs) == 0 {
+		return false
+	}
+
+	for _, blockID := range blockIDs {
+		header, err := h.Headers.ByBlockID(convert.MessageToIdentifier(blockID))
+		if err != nil {
+			return false
+		}
+		if !h.isIndexed(header.Height, header.Height) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// registersIndexed returns whether the registers at the given height have been indexed locally.
+func (h *FlowAccessAPIRouter) registersIndexed(height uint64) bool {
+	if h.Local == nil || h.Registers == nil {
+		return false
+	}
+
+	return height >= h.Registers.FirstHeight() && height <= h.Registers.LatestHeight()
+}
+
 // reconnectingClient returns an active client, or
 // creates one, if the last one is not ready anymore.
 func (h *FlowAccessAPIForwarder) reconnectingClient(i int) error {
@@ -136,157 +222,211 @@ func (h *FlowAccessAPIForwarder) faultTolerantClient() (access.AccessAPIClient,
 // Ping pings the service. It is special in the sense that it responds successful,
 // only if all underlying services are ready.
 func (h *FlowAccessAPIRouter) Ping(context context.Context, req *access.PingRequest) (*access.PingResponse, error) {
-	h.log("observer", "Ping", nil)
+	h.log(routeLocal, "Ping", nil)
 	return &access.PingResponse{}, nil
 }
 
 func (h *FlowAccessAPIRouter) GetNodeVersionInfo(ctx context.Context, request *access.GetNodeVersionInfoRequest) (*access.GetNodeVersionInfoResponse, error) {
 	res, err := h.Observer.GetNodeVersionInfo(ctx, request)
-	h.log("observer", "GetNodeVersionInfo", err)
+	h.log(routeLocal, "GetNodeVersionInfo", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetLatestBlockHeader(context context.Context, req *access.GetLatestBlockHeaderRequest) (*access.BlockHeaderResponse, error) {
 	res, err := h.Observer.GetLatestBlockHeader(context, req)
-	h.log("observer", "GetLatestBlockHeader", err)
+	h.log(routeLocal, "GetLatestBlockHeader", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetBlockHeaderByID(context context.Context, req *access.GetBlockHeaderByIDRequest) (*access.BlockHeaderResponse, error) {
 	res, err := h.Observer.GetBlockHeaderByID(context, req)
-	h.log("observer", "GetBlockHeaderByID", err)
+	h.log(routeLocal, "GetBlockHeaderByID", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetBlockHeaderByHeight(context context.Context, req *access.GetBlockHeaderByHeightRequest) (*access.BlockHeaderResponse, error) {
 	res, err := h.Observer.GetBlockHeaderByHeight(context, req)
-	h.log("observer", "GetBlockHeaderByHeight", err)
+	h.log(routeLocal, "GetBlockHeaderByHeight", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetLatestBlock(context context.Context, req *access.GetLatestBlockRequest) (*access.BlockResponse, error) {
 	res, err := h.Observer.GetLatestBlock(context, req)
-	h.log("observer", "GetLatestBlock", err)
+	h.log(routeLocal, "GetLatestBlock", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetBlockByID(context context.Context, req *access.GetBlockByIDRequest) (*access.BlockResponse, error) {
 	res, err := h.Observer.GetBlockByID(context, req)
-	h.log("observer", "GetBlockByID", err)
+	h.log(routeLocal, "GetBlockByID", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetBlockByHeight(context context.Context, req *access.GetBlockByHeightRequest) (*access.BlockResponse, error) {
 	res, err := h.Observer.GetBlockByHeight(context, req)
-	h.log("observer", "GetBlockByHeight", err)
+	h.log(routeLocal, "GetBlockByHeight", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetCollectionByID(context context.Context, req *access.GetCollectionByIDRequest) (*access.CollectionResponse, error) {
+	route := routeUpstream
+	if h.Local != nil {
+		res, err := h.Local.GetCollectionByID(context, req)
+		if status.Code(err) != codes.NotFound {
+			h.log(routeLocal, "GetCollectionByID", err)
+			return res, err
+		}
+		// the collection hasn't been indexed (yet)
+		route = routeFallback
+	}
+
 	res, err := h.Upstream.GetCollectionByID(context, req)
-	h.log("upstream", "GetCollectionByID", err)
+	h.log(route, "GetCollectionByID", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) SendTransaction(context context.Context, req *access.SendTransactionRequest) (*access.SendTransactionResponse, error) {
 	res, err := h.Upstream.SendTransaction(context, req)
-	h.log("upstream", "SendTransaction", err)
+	h.log(routeUpstream, "SendTransaction", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetTransaction(context context.Context, req *access.GetTransactionRequest) (*access.TransactionResponse, error) {
 	res, err := h.Upstream.GetTransaction(context, req)
-	h.log("upstream", "GetTransaction", err)
+	h.log(routeUpstream, "GetTransaction", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetTransactionResult(context context.Context, req *access.GetTransactionRequest) (*access.TransactionResultResponse, error) {
 	res, err := h.Upstream.GetTransactionResult(context, req)
-	h.log("upstream", "GetTransactionResult", err)
+	h.log(routeUpstream, "GetTransactionResult", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetTransactionResultsByBlockID(context context.Context, req *access.GetTransactionsByBlockIDRequest) (*access.TransactionResultsResponse, error) {
 	res, err := h.Upstream.GetTransactionResultsByBlockID(context, req)
-	h.log("upstream", "GetTransactionResultsByBlockID", err)
+	h.log(routeUpstream, "GetTransactionResultsByBlockID", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetTransactionsByBlockID(context context.Context, req *access.GetTransactionsByBlockIDRequest) (*access.TransactionsResponse, error) {
 	res, err := h.Upstream.GetTransactionsByBlockID(context, req)
-	h.log("upstream", "GetTransactionsByBlockID", err)
+	h.log(routeUpstream, "GetTransactionsByBlockID", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetTransactionResultByIndex(context context.Context, req *access.GetTransactionByIndexRequest) (*access.TransactionResultResponse, error) {
 	res, err := h.Upstream.GetTransactionResultByIndex(context, req)
-	h.log("upstream", "GetTransactionResultByIndex", err)
+	h.log(routeUpstream, "GetTransactionResultByIndex", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetAccount(context context.Context, req *access.GetAccountRequest) (*access.GetAccountResponse, error) {
 	res, err := h.Upstream.GetAccount(context, req)
-	h.log("upstream", "GetAccount", err)
+	h.log(routeUpstream, "GetAccount", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetAccountAtLatestBlock(context context.Context, req *access.GetAccountAtLatestBlockRequest) (*access.AccountResponse, error) {
 	res, err := h.Upstream.GetAccountAtLatestBlock(context, req)
-	h.log("upstream", "GetAccountAtLatestBlock", err)
+	h.log(routeUpstream, "GetAccountAtLatestBlock", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetAccountAtBlockHeight(context context.Context, req *access.GetAccountAtBlockHeightRequest) (*access.AccountResponse, error) {
 	res, err := h.Upstream.GetAccountAtBlockHeight(context, req)
-	h.log("upstream", "GetAccountAtBlockHeight", err)
+	h.log(routeUpstream, "GetAccountAtBlockHeight", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) ExecuteScriptAtLatestBlock(context context.Context, req *access.ExecuteScriptAtLatestBlockRequest) (*access.ExecuteScriptResponse, error) {
+	if h.Registers != nil {
+		sealed, err := h.State.Sealed().Head()
+		if err == nil && h.registersIndexed(sealed.Height) {
+			// execute the script at the sealed block checked here, the block the local handler would
+			// look up might have been sealed in the meantime and not be indexed yet
+			blockID := sealed.ID()
+			res, err := h.Local.ExecuteScriptAtBlockID(context, &access.ExecuteScriptAtBlockIDRequest{
+				BlockId:   blockID[:],
+				Script:    req.GetScript(),
+				Arguments: req.GetArguments(),
+			})
+			h.log(routeLocal, "ExecuteScriptAtLatestBlock", err)
+			return res, err
+		}
+	}
+
 	res, err := h.Upstream.ExecuteScriptAtLatestBlock(context, req)
-	h.log("upstream", "ExecuteScriptAtLatestBlock", err)
+	h.log(routeUpstream, "ExecuteScriptAtLatestBlock", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) ExecuteScriptAtBlockID(context context.Context, req *access.ExecuteScriptAtBlockIDRequest) (*access.ExecuteScriptResponse, error) {
+	if h.Registers != nil {
+		header, err := h.Headers.ByBlockID(convert.MessageToIdentifier(req.GetBlockId()))
+		if err == nil && h.registersIndexed(header.Height) {
+			res, err := h.Local.ExecuteScriptAtBlockID(context, req)
+			h.log(routeLocal, "ExecuteScriptAtBlockID", err)
+			return res, err
+		}
+	}
+
 	res, err := h.Upstream.ExecuteScriptAtBlockID(context, req)
-	h.log("upstream", "ExecuteScriptAtBlockID", err)
+	h.log(routeUpstream, "ExecuteScriptAtBlockID", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) ExecuteScriptAtBlockHeight(context context.Context, req *access.ExecuteScriptAtBlockHeightRequest) (*access.ExecuteScriptResponse, error) {
+	if h.registersIndexed(req.GetBlockHeight()) {
+		res, err := h.Local.ExecuteScriptAtBlockHeight(context, req)
+		h.log(routeLocal, "ExecuteScriptAtBlockHeight", err)
+		return res, err
+	}
+
 	res, err := h.Upstream.ExecuteScriptAtBlockHeight(context, req)
-	h.log("upstream", "ExecuteScriptAtBlockHeight", err)
+	h.log(routeUpstream, "ExecuteScriptAtBlockHeight", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetEventsForHeightRange(context context.Context, req *access.GetEventsForHeightRangeRequest) (*access.EventsResponse, error) {
+	if h.isIndexed(req.GetStartHeight(), req.GetEndHeight()) {
+		res, err := h.Local.GetEventsForHeightRange(context, req)
+		h.log(routeLocal, "GetEventsForHeightRange", err)
+		return res, err
+	}
+
 	res, err := h.Upstream.GetEventsForHeightRange(context, req)
-	h.log("upstream", "GetEventsForHeightRange", err)
+	h.log(routeUpstream, "GetEventsForHeightRange", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetEventsForBlockIDs(context context.Context, req *access.GetEventsForBlockIDsRequest) (*access.EventsResponse, error) {
+	if h.areIndexed(req.GetBlockIds()) {
+		res, err := h.Local.GetEventsForBlockIDs(context, req)
+		h.log(routeLocal, "GetEventsForBlockIDs", err)
+		return res, err
+	}
+
 	res, err := h.Upstream.GetEventsForBlockIDs(context, req)
-	h.log("upstream", "GetEventsForBlockIDs", err)
+	h.log(routeUpstream, "GetEventsForBlockIDs", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetNetworkParameters(context context.Context, req *access.GetNetworkParametersRequest) (*access.GetNetworkParametersResponse, error) {
 	res, err := h.Observer.GetNetworkParameters(context, req)
-	h.log("observer", "GetNetworkParameters", err)
+	h.log(routeLocal, "GetNetworkParameters", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetLatestProtocolStateSnapshot(context context.Context, req *access.GetLatestProtocolStateSnapshotRequest) (*access.ProtocolStateSnapshotResponse, error) {
 	res, err := h.Observer.GetLatestProtocolStateSnapshot(context, req)
-	h.log("observer", "GetLatestProtocolStateSnapshot", err)
+	h.log(routeLocal, "GetLatestProtocolStateSnapshot", err)
 	return res, err
 }
 
 func (h *FlowAccessAPIRouter) GetExecutionResultForBlockID(context context.Context, req *access.GetExecutionResultForBlockIDRequest) (*access.ExecutionResultForBlockIDResponse, error) {
 	res, err := h.Upstream.GetExecutionResultForBlockID(context, req)
-	h.log("upstream", "GetExecutionResultForBlockID", err)
+	h.log(routeUpstream, "GetExecutionResultForBlockID", err)
 	return res, err
 }
 
@@ -8,10 +8,22 @@ import (
 	"time"
 
 	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	grpcinsecure "google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
+	accessmock "github.com/onflow/flow-go/engine/access/mock"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	syncmock "github.com/onflow/flow-go/module/state_synchronization/mock"
+	protocolmock "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/storage"
+	storagemock "github.com/onflow/flow-go/storage/mock"
 	"github.com/onflow/flow-go/utils/grpcutils"
 	"github.com/onflow/flow-go/utils/unittest"
 )
@@ -24,6 +36,10 @@ import (
 // * We embrace the simplest adequate solution to reduce engineering cost.
 // * Any use cases requiring multiple conditionals exercised in a row are considered ignorable due to cost constraints.
 
+// observerMetrics is shared by all routers created in the tests, since the observer collector registers
+// its metrics globally
+var observerMetrics = metrics.NewObserverCollector()
+
 // TestNetE2E tests the basic unix network first
 func TestNetE2E(t *testing.T) {
 	done := make(chan int)
@@ -189,6 +205,194 @@ func TestNewFlowCachedAccessAPIProxy(t *testing.T) {
 	<-done
 }
 
+// TestFlowAccessAPIRouter_LocalReads tests that collection and event reads are served locally when the
+// data is available, and forwarded upstream otherwise
+func TestFlowAccessAPIRouter_LocalReads(t *testing.T) {
+	ctx := context.Background()
+	// a forwarder without upstream nodes fails all requests with codes.Unimplemented
+	upstream := &FlowAccessAPIForwarder{}
+
+	header := unittest.BlockHeaderFixture()
+	blockID := header.ID()
+	headers := storagemock.NewHeaders(t)
+	headers.On("ByBlockID", blockID).Return(header, nil).Maybe()
+
+	reporter := syncmock.NewIndexReporter(t)
+	reporter.On("LowestIndexedHeight").Return(header.Height, nil).Maybe()
+	reporter.On("HighestIndexedHeight").Return(header.Height, nil).Maybe()
+
+	router := &FlowAccessAPIRouter{
+		Logger:   zerolog.Nop(),
+		Metrics:  observerMetrics,
+		Upstream: upstream,
+		Indexed:  reporter,
+		Headers:  headers,
+	}
+
+	t.Run("without local handler everything is forwarded", func(t *testing.T) {
+		_, err := router.GetCollectionByID(ctx, &access.GetCollectionByIDRequest{Id: convert.IdentifierToMessage(unittest.IdentifierFixture())})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+
+		_, err = router.GetEventsForHeightRange(ctx, &access.GetEventsForHeightRangeRequest{StartHeight: header.Height, EndHeight: header.Height})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	local := accessmock.NewAccessAPIServer(t)
+	router.Local = local
+
+	t.Run("stored collection is served locally", func(t *testing.T) {
+		req := &access.GetCollectionByIDRequest{Id: convert.IdentifierToMessage(unittest.IdentifierFixture())}
+		expected := &access.CollectionResponse{}
+		local.On("GetCollectionByID", ctx, req).Return(expected, nil).Once()
+
+		res, err := router.GetCollectionByID(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("missing collection is forwarded", func(t *testing.T) {
+		req := &access.GetCollectionByIDRequest{Id: convert.IdentifierToMessage(unittest.IdentifierFixture())}
+		local.On("GetCollectionByID", ctx, req).Return(nil, status.Error(codes.NotFound, "not found")).Once()
+
+		_, err := router.GetCollectionByID(ctx, req)
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("local failure is not forwarded", func(t *testing.T) {
+		req := &access.GetCollectionByIDRequest{Id: convert.IdentifierToMessage(unittest.IdentifierFixture())}
+		local.On("GetCollectionByID", ctx, req).Return(nil, status.Error(codes.Internal, "failure")).Once()
+
+		_, err := router.GetCollectionByID(ctx, req)
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("events of indexed heights are served locally", func(t *testing.T) {
+		req := &access.GetEventsForHeightRangeRequest{StartHeight: header.Height, EndHeight: header.Height}
+		expected := &access.EventsResponse{}
+		local.On("GetEventsForHeightRange", ctx, req).Return(expected, nil).Once()
+
+		res, err := router.GetEventsForHeightRange(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("events of heights not indexed yet are forwarded", func(t *testing.T) {
+		req := &access.GetEventsForHeightRangeRequest{StartHeight: header.Height, EndHeight: header.Height + 1}
+
+		_, err := router.GetEventsForHeightRange(ctx, req)
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("events of indexed blocks are served locally", func(t *testing.T) {
+		req := &access.GetEventsForBlockIDsRequest{BlockIds: [][]byte{blockID[:]}}
+		expected := &access.EventsResponse{}
+		local.On("GetEventsForBlockIDs", ctx, req).Return(expected, nil).Once()
+
+		res, err := router.GetEventsForBlockIDs(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("events of unknown blocks are forwarded", func(t *testing.T) {
+		unknownID := unittest.IdentifierFixture()
+		headers.On("ByBlockID", unknownID).Return(nil, storage.ErrNotFound).Once()
+		req := &access.GetEventsForBlockIDsRequest{BlockIds: [][]byte{blockID[:], unknownID[:]}}
+
+		_, err := router.GetEventsForBlockIDs(ctx, req)
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("scripts are forwarded without registers", func(t *testing.T) {
+		_, err := router.ExecuteScriptAtBlockHeight(ctx, &access.ExecuteScriptAtBlockHeightRequest{BlockHeight: header.Height})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
+
+// TestFlowAccessAPIRouter_LocalScripts tests that scripts are executed locally when the registers at the
+// requested block have been indexed, and forwarded upstream otherwise
+func TestFlowAccessAPIRouter_LocalScripts(t *testing.T) {
+	ctx := context.Background()
+	// a forwarder without upstream nodes fails all requests with codes.Unimplemented
+	upstream := &FlowAccessAPIForwarder{}
+
+	sealed := unittest.BlockHeaderFixture()
+	sealedID := sealed.ID()
+	unindexed := unittest.BlockHeaderWithParentFixture(sealed)
+	unindexedID := unindexed.ID()
+
+	headers := storagemock.NewHeaders(t)
+	headers.On("ByBlockID", sealedID).Return(sealed, nil).Maybe()
+	headers.On("ByBlockID", unindexedID).Return(unindexed, nil).Maybe()
+
+	registers := storagemock.NewRegisterIndex(t)
+	registers.On("FirstHeight").Return(sealed.Height - 10).Maybe()
+	registers.On("LatestHeight").Return(sealed.Height).Maybe()
+
+	snapshot := protocolmock.NewSnapshot(t)
+	snapshot.On("Head").Return(sealed, nil).Maybe()
+	state := protocolmock.NewState(t)
+	state.On("Sealed").Return(snapshot).Maybe()
+
+	local := accessmock.NewAccessAPIServer(t)
+	router := &FlowAccessAPIRouter{
+		Logger:    zerolog.Nop(),
+		Metrics:   observerMetrics,
+		Upstream:  upstream,
+		Local:     local,
+		Registers: registers,
+		Headers:   headers,
+		State:     state,
+	}
+
+	script := []byte("script")
+	expected := &access.ExecuteScriptResponse{Value: []byte("value")}
+
+	t.Run("scripts at indexed heights are executed locally", func(t *testing.T) {
+		req := &access.ExecuteScriptAtBlockHeightRequest{BlockHeight: sealed.Height - 10, Script: script}
+		local.On("ExecuteScriptAtBlockHeight", ctx, req).Return(expected, nil).Once()
+
+		res, err := router.ExecuteScriptAtBlockHeight(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("scripts at heights not indexed are forwarded", func(t *testing.T) {
+		_, err := router.ExecuteScriptAtBlockHeight(ctx, &access.ExecuteScriptAtBlockHeightRequest{BlockHeight: sealed.Height - 11, Script: script})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+
+		_, err = router.ExecuteScriptAtBlockHeight(ctx, &access.ExecuteScriptAtBlockHeightRequest{BlockHeight: unindexed.Height, Script: script})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("scripts at indexed blocks are executed locally", func(t *testing.T) {
+		req := &access.ExecuteScriptAtBlockIDRequest{BlockId: sealedID[:], Script: script}
+		local.On("ExecuteScriptAtBlockID", ctx, req).Return(expected, nil).Once()
+
+		res, err := router.ExecuteScriptAtBlockID(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("scripts at blocks not indexed are forwarded", func(t *testing.T) {
+		_, err := router.ExecuteScriptAtBlockID(ctx, &access.ExecuteScriptAtBlockIDRequest{BlockId: unindexedID[:], Script: script})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+
+		unknownID := unittest.IdentifierFixture()
+		headers.On("ByBlockID", unknownID).Return(nil, storage.ErrNotFound).Once()
+		_, err = router.ExecuteScriptAtBlockID(ctx, &access.ExecuteScriptAtBlockIDRequest{BlockId: unknownID[:], Script: script})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("scripts at the latest block are executed locally at the sealed block", func(t *testing.T) {
+		req := &access.ExecuteScriptAtBlockIDRequest{BlockId: sealedID[:], Script: script}
+		local.On("ExecuteScriptAtBlockID", ctx, req).Return(expected, nil).Once()
+
+		res, err := router.ExecuteScriptAtLatestBlock(ctx, &access.ExecuteScriptAtLatestBlockRequest{Script: script})
+		require.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+}
+
 func makeFlowLite(address string, done chan int) (net.Listener, error) {
 	l, err := net.Listen("unix", address)
 	if err != nil {
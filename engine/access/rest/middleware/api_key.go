@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/onflow/flow-go/engine/access/apikey"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+)
+
+// APIKeyMiddleware creates a middleware which rejects requests that do not carry a valid API key in
+// the X-API-Key header, or whose key has exhausted its quota for the class of the requested route.
+// Routes are identified by their name, e.g. "getAccount".
+func APIKeyMiddleware(authenticator *apikey.Authenticator) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var routeName string
+			if route := mux.CurrentRoute(req); route != nil {
+				routeName = route.GetName()
+			}
+
+			_, err := authenticator.Authorize(req.Header.Get(apikey.Header), routeName)
+			if err != nil {
+				code := http.StatusUnauthorized
+				if errors.Is(err, apikey.ErrQuotaExceeded) {
+					code = http.StatusTooManyRequests
+				}
+				apiKeyErrorResponse(w, code, err.Error())
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// apiKeyErrorResponse writes an error response using the same error model as the API handlers.
+func apiKeyErrorResponse(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(models.ModelError{
+		Code:    int32(code),
+		Message: message,
+	})
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/access/apikey"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+// TestAPIKeyMiddleware tests that requests are authenticated by API key and limited per route class
+func TestAPIKeyMiddleware(t *testing.T) {
+	config := apikey.Config{
+		MethodClasses: map[string][]string{
+			"scripts": {"executeScript"},
+		},
+		Keys: []apikey.KeyConfig{{
+			Name: "team-a",
+			Key:  "key-a",
+			Quotas: map[string]apikey.Quota{
+				"scripts": {Rate: 0.001, Burst: 1},
+			},
+		}},
+	}
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "api_keys.json")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	authenticator, err := apikey.NewAuthenticator(zerolog.Nop(), metrics.NewNoopCollector(), path, time.Minute)
+	require.NoError(t, err)
+
+	r := mux.NewRouter()
+	r.Use(APIKeyMiddleware(authenticator))
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Handle("/scripts", ok).Name("executeScript")
+	r.Handle("/blocks", ok).Name("getBlocksByHeight")
+
+	send := func(path string, key string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if key != "" {
+			req.Header.Set(apikey.Header, key)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("/blocks", ""))
+	assert.Equal(t, http.StatusUnauthorized, send("/blocks", "key-b"))
+
+	assert.Equal(t, http.StatusOK, send("/scripts", "key-a"))
+	assert.Equal(t, http.StatusTooManyRequests, send("/scripts", "key-a"))
+
+	// the default class is not limited for this key
+	assert.Equal(t, http.StatusOK, send("/blocks", "key-a"))
+	assert.Equal(t, http.StatusOK, send("/blocks", "key-a"))
+}
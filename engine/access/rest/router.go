@@ -7,12 +7,13 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/apikey"
 	"github.com/onflow/flow-go/engine/access/rest/middleware"
 	"github.com/onflow/flow-go/engine/access/rest/models"
 	"github.com/onflow/flow-go/model/flow"
 )
 
-func newRouter(backend access.API, logger zerolog.Logger, chain flow.Chain) (*mux.Router, error) {
+func newRouter(backend access.API, logger zerolog.Logger, chain flow.Chain, apiKeys *apikey.Authenticator) (*mux.Router, error) {
 	router := mux.NewRouter().StrictSlash(true)
 	v1SubRouter := router.PathPrefix("/v1").Subrouter()
 
@@ -21,6 +22,9 @@ func newRouter(backend access.API, logger zerolog.Logger, chain flow.Chain) (*mu
 	v1SubRouter.Use(middleware.QueryExpandable())
 	v1SubRouter.Use(middleware.QuerySelect())
 	v1SubRouter.Use(middleware.MetricsMiddleware())
+	if apiKeys != nil {
+		v1SubRouter.Use(middleware.APIKeyMiddleware(apiKeys))
+	}
 
 	linkGenerator := models.NewLinkGeneratorImpl(v1SubRouter)
 
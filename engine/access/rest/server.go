@@ -8,13 +8,21 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/apikey"
 	"github.com/onflow/flow-go/model/flow"
 )
 
-// NewServer returns an HTTP server initialized with the REST API handler
-func NewServer(backend access.API, listenAddress string, logger zerolog.Logger, chain flow.Chain) (*http.Server, error) {
+// NewServer returns an HTTP server initialized with the REST API handler.
+// If apiKeys is not nil, requests are authenticated by API key.
+func NewServer(
+	backend access.API,
+	listenAddress string,
+	logger zerolog.Logger,
+	chain flow.Chain,
+	apiKeys *apikey.Authenticator,
+) (*http.Server, error) {
 
-	router, err := newRouter(backend, logger, chain)
+	router, err := newRouter(backend, logger, chain, apiKeys)
 	if err != nil {
 		return nil, err
 	}
@@ -189,6 +189,21 @@ func New(
 	return b
 }
 
+// SetIndexedData configures the backend to serve events and transaction results of blocks whose
+// execution data has been indexed from the index, instead of querying execution nodes.
+// It must be called before the backend serves any request.
+func (b *Backend) SetIndexedData(indexedData *IndexedData) {
+	b.backendEvents.indexedData = indexedData
+	b.backendTransactions.indexedData = indexedData
+}
+
+// SetScriptExecutor configures the backend to execute scripts at heights whose registers have been
+// indexed locally, instead of forwarding them to execution nodes.
+// It must be called before the backend serves any request.
+func (b *Backend) SetScriptExecutor(scriptExecutor *ScriptExecutor) {
+	b.backendScripts.scriptExecutor = scriptExecutor
+}
+
 func identifierList(ids []string) (flow.IdentifierList, error) {
 	idList := make(flow.IdentifierList, len(ids))
 	for i, idStr := range ids {
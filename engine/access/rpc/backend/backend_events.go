@@ -27,6 +27,7 @@ type backendEvents struct {
 	connFactory       ConnectionFactory
 	log               zerolog.Logger
 	maxHeightRange    uint
+	indexedData       *IndexedData // nil if execution data isn't indexed
 }
 
 // GetEventsForHeightRange retrieves events for all sealed blocks between the start block height and
@@ -76,7 +77,7 @@ func (b *backendEvents) GetEventsForHeightRange(
 		blockHeaders = append(blockHeaders, header)
 	}
 
-	return b.getBlockEventsFromExecutionNode(ctx, blockHeaders, eventType)
+	return b.getBlockEvents(ctx, blockHeaders, eventType)
 }
 
 // GetEventsForBlockIDs retrieves events for all the specified block IDs that have the given type
@@ -101,8 +102,52 @@ func (b *backendEvents) GetEventsForBlockIDs(
 		blockHeaders = append(blockHeaders, header)
 	}
 
-	// forward the request to the execution node
-	return b.getBlockEventsFromExecutionNode(ctx, blockHeaders, eventType)
+	return b.getBlockEvents(ctx, blockHeaders, eventType)
+}
+
+// getBlockEvents returns the events of the given type for the given blocks, in the same order. The events
+// of blocks whose execution data has been indexed are read from the index, the events of all other blocks
+// are requested from execution nodes.
+func (b *backendEvents) getBlockEvents(
+	ctx context.Context,
+	blockHeaders []*flow.Header,
+	eventType string,
+) ([]flow.BlockEvents, error) {
+
+	indexed := make(map[flow.Identifier]flow.BlockEvents)
+	missing := make([]*flow.Header, 0)
+	for _, header := range blockHeaders {
+		if !b.indexedData.isIndexed(header.Height) {
+			missing = append(missing, header)
+			continue
+		}
+
+		blockEvents, err := b.indexedData.blockEvents(header, eventType)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get indexed events: %v", err)
+		}
+		indexed[blockEvents.BlockID] = blockEvents
+	}
+
+	if len(indexed) == 0 {
+		// forward the request to the execution node
+		return b.getBlockEventsFromExecutionNode(ctx, blockHeaders, eventType)
+	}
+
+	executionNodeEvents, err := b.getBlockEventsFromExecutionNode(ctx, missing, eventType)
+	if err != nil {
+		return nil, err
+	}
+	for _, blockEvents := range executionNodeEvents {
+		indexed[blockEvents.BlockID] = blockEvents
+	}
+
+	results := make([]flow.BlockEvents, len(blockHeaders))
+	for i, header := range blockHeaders {
+		results[i] = indexed[header.ID()]
+	}
+
+	return results, nil
 }
 
 func (b *backendEvents) getBlockEventsFromExecutionNode(
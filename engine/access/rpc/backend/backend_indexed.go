@@ -0,0 +1,164 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
+
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/state_synchronization"
+	"github.com/onflow/flow-go/storage"
+)
+
+// IndexedData gives access to the events and transaction results indexed from execution data, so
+// that they can be served without querying execution nodes. Only heights within the range reported
+// by the index reporter are served from the index, all other heights are forwarded to execution nodes.
+type IndexedData struct {
+	reporter state_synchronization.IndexReporter
+	events   storage.Events
+	results  storage.TransactionResults
+}
+
+// NewIndexedData creates a new IndexedData.
+func NewIndexedData(
+	reporter state_synchronization.IndexReporter,
+	events storage.Events,
+	results storage.TransactionResults,
+) *IndexedData {
+	return &IndexedData{
+		reporter: reporter,
+		events:   events,
+		results:  results,
+	}
+}
+
+// isIndexed returns whether the data of the block at the given height has been indexed.
+// It is safe to call on a nil IndexedData, in which case nothing is indexed.
+func (d *IndexedData) isIndexed(height uint64) bool {
+	if d == nil {
+		return false
+	}
+
+	lowest, err := d.reporter.LowestIndexedHeight()
+	if err != nil {
+		return false
+	}
+	highest, err := d.reporter.HighestIndexedHeight()
+	if err != nil {
+		return false
+	}
+
+	return height >= lowest && height <= highest
+}
+
+// blockEvents returns the events of the given type emitted in the given indexed block.
+// No errors are expected during normal operation.
+func (d *IndexedData) blockEvents(header *flow.Header, eventType string) (flow.BlockEvents, error) {
+	events, err := d.events.ByBlockIDEventType(header.ID(), flow.EventType(eventType))
+	if err != nil {
+		return flow.BlockEvents{}, fmt.Errorf("could not get indexed events of block %v: %w", header.ID(), err)
+	}
+
+	return flow.BlockEvents{
+		BlockID:        header.ID(),
+		BlockHeight:    header.Height,
+		BlockTimestamp: header.Timestamp,
+		Events:         events,
+	}, nil
+}
+
+// transactionResult returns the result of the given transaction in the given indexed block, in the
+// format returned by execution nodes.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the result is not indexed, e.g. because the execution data of the
+//     block doesn't include transaction results
+func (d *IndexedData) transactionResult(blockID flow.Identifier, txID flow.Identifier) (*execproto.GetTransactionResultResponse, error) {
+	result, err := d.results.ByBlockIDTransactionID(blockID, txID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get indexed transaction result: %w", err)
+	}
+
+	events, err := d.events.ByBlockIDTransactionID(blockID, txID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("could not get indexed events of transaction: %w", err)
+	}
+
+	return transactionResultToMessage(result, events), nil
+}
+
+// transactionResultByIndex returns the result of the transaction at the given index in the given
+// indexed block, in the format returned by execution nodes.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the result is not indexed
+func (d *IndexedData) transactionResultByIndex(blockID flow.Identifier, index uint32) (*execproto.GetTransactionResultResponse, error) {
+	result, err := d.results.ByBlockIDTransactionIndex(blockID, index)
+	if err != nil {
+		return nil, fmt.Errorf("could not get indexed transaction result: %w", err)
+	}
+
+	events, err := d.events.ByBlockIDTransactionIndex(blockID, index)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("could not get indexed events of transaction: %w", err)
+	}
+
+	return transactionResultToMessage(result, events), nil
+}
+
+// transactionResults returns the results of all transactions in the given indexed block, ordered by
+// transaction index, in the format returned by execution nodes.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the results are not indexed
+func (d *IndexedData) transactionResults(blockID flow.Identifier) (*execproto.GetTransactionResultsResponse, error) {
+	results, err := d.results.ByBlockID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get indexed transaction results: %w", err)
+	}
+	if len(results) == 0 {
+		// every executed block contains at least the system transaction
+		return nil, fmt.Errorf("no transaction results indexed for block %v: %w", blockID, storage.ErrNotFound)
+	}
+
+	events, err := d.events.ByBlockID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get indexed events of block: %w", err)
+	}
+
+	// events are stored ordered by transaction ID, and within a transaction by event index
+	eventsByTxIndex := make(map[uint32][]flow.Event, len(results))
+	for _, event := range events {
+		eventsByTxIndex[event.TransactionIndex] = append(eventsByTxIndex[event.TransactionIndex], event)
+	}
+
+	messages := make([]*execproto.GetTransactionResultResponse, len(results))
+	for i := range results {
+		messages[i] = transactionResultToMessage(&results[i], eventsByTxIndex[uint32(i)])
+	}
+
+	return &execproto.GetTransactionResultsResponse{
+		TransactionResults: messages,
+	}, nil
+}
+
+// transactionResultToMessage converts a transaction result the same way execution nodes do.
+func transactionResultToMessage(result *flow.TransactionResult, events []flow.Event) *execproto.GetTransactionResultResponse {
+	var statusCode uint32 = 0
+	errMsg := ""
+	if result.ErrorMessage != "" {
+		statusCode = 1 // for now a statusCode of 1 indicates an error and 0 indicates no error
+		// convert non UTF-8 string to a UTF-8 string for safe GRPC marshaling
+		errMsg = result.ErrorMessage
+		if !utf8.ValidString(errMsg) {
+			errMsg = strings.ToValidUTF8(errMsg, "?")
+		}
+	}
+
+	return &execproto.GetTransactionResultResponse{
+		StatusCode:   statusCode,
+		ErrorMessage: errMsg,
+		Events:       convert.EventsToMessages(events),
+	}
+}
@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution/computation/query"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/storage/derived"
+	"github.com/onflow/flow-go/fvm/storage/snapshot"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/storage"
+)
+
+// ScriptExecutor executes scripts locally against the registers indexed from execution data, so
+// that they can be served without querying execution nodes. Only scripts at heights within the
+// range of the register index are executed locally, all other scripts are forwarded to execution nodes.
+type ScriptExecutor struct {
+	registers storage.RegisterIndex
+	executor  *query.QueryExecutor
+}
+
+// NewScriptExecutor creates a new ScriptExecutor which executes scripts with the given fvm options,
+// i.e. the options execution nodes use on the chain, against the given registers.
+// No errors are expected during normal operation.
+func NewScriptExecutor(
+	log zerolog.Logger,
+	metrics module.ExecutionMetrics,
+	vmOptions []fvm.Option,
+	registers storage.RegisterIndex,
+) (*ScriptExecutor, error) {
+	derivedChainData, err := derived.NewDerivedChainData(derived.DefaultDerivedDataCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("could not create derived data cache: %w", err)
+	}
+
+	executor := query.NewQueryExecutor(
+		query.NewDefaultConfig(),
+		log.With().Str("component", "script_executor").Logger(),
+		metrics,
+		fvm.NewVirtualMachine(),
+		fvm.NewContext(vmOptions...),
+		derivedChainData,
+	)
+
+	return &ScriptExecutor{
+		registers: registers,
+		executor:  executor,
+	}, nil
+}
+
+// isIndexed returns whether the registers at the given height have been indexed.
+// It is safe to call on a nil ScriptExecutor, in which case nothing is indexed.
+func (e *ScriptExecutor) isIndexed(height uint64) bool {
+	if e == nil {
+		return false
+	}
+
+	return height >= e.registers.FirstHeight() && height <= e.registers.LatestHeight()
+}
+
+// executeScript executes the given script against the registers at the height of the given block.
+// The returned error is the error of the script execution, including errors of the script itself.
+func (e *ScriptExecutor) executeScript(
+	ctx context.Context,
+	header *flow.Header,
+	script []byte,
+	arguments [][]byte,
+) ([]byte, error) {
+	storageSnapshot := snapshot.NewReadFuncStorageSnapshot(func(id flow.RegisterID) (flow.RegisterValue, error) {
+		value, err := e.registers.Get(id, header.Height)
+		if errors.Is(err, storage.ErrNotFound) {
+			// registers which were never set are empty
+			return nil, nil
+		}
+		return value, err
+	})
+
+	return e.executor.ExecuteScript(ctx, script, arguments, header, storageSnapshot)
+}
@@ -32,6 +32,7 @@ type backendScripts struct {
 	metrics            module.BackendScriptsMetrics
 	loggedScripts      *lru.Cache
 	archiveAddressList []string
+	scriptExecutor     *ScriptExecutor // nil if scripts aren't executed locally
 }
 
 func (b *backendScripts) ExecuteScriptAtLatestBlock(
@@ -46,11 +47,7 @@ func (b *backendScripts) ExecuteScriptAtLatestBlock(
 		return nil, status.Errorf(codes.Internal, "failed to get latest sealed header: %v", err)
 	}
 
-	// get the block id of the latest sealed header
-	latestBlockID := latestHeader.ID()
-
-	// execute script on the execution node at that block id
-	return b.executeScriptOnExecutionNode(ctx, latestBlockID, script, arguments)
+	return b.executeScript(ctx, latestHeader, script, arguments)
 }
 
 func (b *backendScripts) ExecuteScriptAtBlockID(
@@ -59,8 +56,18 @@ func (b *backendScripts) ExecuteScriptAtBlockID(
 	script []byte,
 	arguments [][]byte,
 ) ([]byte, error) {
-	// execute script on the execution node at that block id
-	return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
+	if b.scriptExecutor == nil {
+		// execute script on the execution node at that block id
+		return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
+	}
+
+	header, err := b.headers.ByBlockID(blockID)
+	if err != nil {
+		err = rpc.ConvertStorageError(err)
+		return nil, err
+	}
+
+	return b.executeScript(ctx, header, script, arguments)
 }
 
 func (b *backendScripts) ExecuteScriptAtBlockHeight(
@@ -76,10 +83,28 @@ func (b *backendScripts) ExecuteScriptAtBlockHeight(
 		return nil, err
 	}
 
-	blockID := header.ID()
+	return b.executeScript(ctx, header, script, arguments)
+}
 
-	// execute script on the execution node at that block id
-	return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
+// executeScript executes the script locally if the registers at the height of the given block have
+// been indexed, and forwards it to the execution nodes otherwise.
+func (b *backendScripts) executeScript(
+	ctx context.Context,
+	header *flow.Header,
+	script []byte,
+	arguments [][]byte,
+) ([]byte, error) {
+	if !b.scriptExecutor.isIndexed(header.Height) {
+		// execute script on the execution node at that block id
+		return b.executeScriptOnExecutionNode(ctx, header.ID(), script, arguments)
+	}
+
+	result, err := b.scriptExecutor.executeScript(ctx, header, script, arguments)
+	if err != nil {
+		// the same status execution nodes return for failed scripts
+		return nil, status.Errorf(codes.InvalidArgument, "failed to execute script: %v", err)
+	}
+	return result, nil
 }
 
 func (b *backendScripts) findScriptExecutors(
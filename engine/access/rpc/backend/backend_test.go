@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/dgraph-io/badger/v2"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
 	accessproto "github.com/onflow/flow/protobuf/go/flow/access"
 	entitiesproto "github.com/onflow/flow/protobuf/go/flow/entities"
 	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
@@ -22,13 +24,18 @@ import (
 	access "github.com/onflow/flow-go/engine/access/mock"
 	backendmock "github.com/onflow/flow-go/engine/access/rpc/backend/mock"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/environment"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module/metrics"
+	syncmock "github.com/onflow/flow-go/module/state_synchronization/mock"
 	bprotocol "github.com/onflow/flow-go/state/protocol/badger"
 	protocol "github.com/onflow/flow-go/state/protocol/mock"
 	"github.com/onflow/flow-go/state/protocol/util"
 	"github.com/onflow/flow-go/storage"
 	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/storage/operation/badgerimpl"
+	"github.com/onflow/flow-go/storage/store"
 	"github.com/onflow/flow-go/utils/unittest"
 )
 
@@ -743,6 +750,79 @@ func (suite *Suite) TestGetTransactionResultsByBlockID() {
 	suite.assertAllExpectations()
 }
 
+// TestGetTransactionResultsByBlockID_Indexed tests that transaction results of indexed blocks are
+// served from the local index without querying execution nodes
+func (suite *Suite) TestGetTransactionResultsByBlockID_Indexed() {
+	head := unittest.BlockHeaderFixture()
+	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
+	suite.snapshot.On("Head").Return(head, nil).Maybe()
+
+	ctx := context.Background()
+	block := unittest.BlockFixture()
+	blockId := block.ID()
+
+	// block storage returns the corresponding block
+	suite.blocks.
+		On("ByID", blockId).
+		Return(&block, nil)
+
+	// the block only contains the system transaction
+	systemTxResult := flow.TransactionResult{
+		TransactionID: unittest.IdentifierFixture(),
+		ErrorMessage:  "system transaction failed",
+	}
+	systemTxEvent := unittest.EventFixture(flow.EventAccountCreated, 0, 0, systemTxResult.TransactionID, 0)
+
+	reporter := new(syncmock.IndexReporter)
+	reporter.On("LowestIndexedHeight").Return(block.Header.Height, nil)
+	reporter.On("HighestIndexedHeight").Return(block.Header.Height, nil)
+
+	txResults := new(storagemock.TransactionResults)
+	txResults.
+		On("ByBlockID", blockId).
+		Return([]flow.TransactionResult{systemTxResult}, nil)
+
+	events := new(storagemock.Events)
+	events.
+		On("ByBlockID", blockId).
+		Return([]flow.Event{systemTxEvent}, nil)
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		suite.blocks,
+		suite.headers,
+		suite.collections,
+		suite.transactions,
+		suite.receipts,
+		suite.results,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		suite.connectionFactory, // the connection factory must not be used
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+	backend.SetIndexedData(NewIndexedData(reporter, events, txResults))
+
+	result, err := backend.GetTransactionResultsByBlockID(ctx, blockId)
+	suite.checkResponse(result, err)
+
+	suite.Require().Len(result, 1)
+	suite.Assert().Equal(uint(1), result[0].StatusCode)
+	suite.Assert().Equal(systemTxResult.ErrorMessage, result[0].ErrorMessage)
+	suite.Assert().Equal([]flow.Event{systemTxEvent}, result[0].Events)
+	suite.Assert().Equal(block.Header.Height, result[0].BlockHeight)
+
+	suite.connectionFactory.AssertNotCalled(suite.T(), "GetExecutionAPIClient", mock.Anything)
+	suite.assertAllExpectations()
+}
+
 // TestTransactionStatusTransition tests that the status of transaction changes from Finalized to Sealed
 // when the protocol state is updated
 func (suite *Suite) TestTransactionStatusTransition() {
@@ -1394,6 +1474,124 @@ func (suite *Suite) TestGetEventsForBlockIDs() {
 	suite.assertAllExpectations()
 }
 
+// TestGetEventsForBlockIDs_Indexed tests that events of indexed blocks are served from the local
+// index, while events of blocks that haven't been indexed yet are requested from execution nodes
+func (suite *Suite) TestGetEventsForBlockIDs_Indexed() {
+	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
+	suite.state.On("Final").Return(suite.snapshot, nil).Maybe()
+
+	ctx := context.Background()
+	eventType := string(flow.EventAccountCreated)
+
+	blockHeaders := make([]*flow.Header, 4)
+	blockIDs := make([]flow.Identifier, len(blockHeaders))
+	parent := unittest.BlockHeaderFixture()
+	for i := range blockHeaders {
+		blockHeaders[i] = unittest.BlockHeaderWithParentFixture(parent)
+		blockIDs[i] = blockHeaders[i].ID()
+		parent = blockHeaders[i]
+
+		suite.headers.
+			On("ByBlockID", blockIDs[i]).
+			Return(blockHeaders[i], nil).Once()
+	}
+
+	// the first two blocks are indexed
+	reporter := new(syncmock.IndexReporter)
+	reporter.On("LowestIndexedHeight").Return(blockHeaders[0].Height, nil)
+	reporter.On("HighestIndexedHeight").Return(blockHeaders[1].Height, nil)
+
+	events := new(storagemock.Events)
+	indexedEvents := getEvents(2)
+	for i := 0; i < 2; i++ {
+		events.
+			On("ByBlockIDEventType", blockIDs[i], flow.EventType(eventType)).
+			Return(indexedEvents, nil).Once()
+	}
+
+	// the remaining blocks are requested from the execution nodes
+	exeIDs := unittest.IdentityListFixture(2, unittest.WithRole(flow.RoleExecution))
+	for i := 2; i < len(blockHeaders); i++ {
+		block := unittest.BlockWithParentFixture(blockHeaders[i-1])
+		block.Header = blockHeaders[i]
+
+		receipt1 := unittest.ReceiptForBlockFixture(block)
+		receipt1.ExecutorID = exeIDs[0].NodeID
+		receipt2 := unittest.ReceiptForBlockFixture(block)
+		receipt2.ExecutorID = exeIDs[1].NodeID
+		receipt1.ExecutionResult = receipt2.ExecutionResult
+		suite.receipts.
+			On("ByBlockID", blockIDs[i]).
+			Return(flow.ExecutionReceiptList{receipt1, receipt2}, nil).Once()
+	}
+	suite.snapshot.On("Identities", mock.Anything).Return(exeIDs, nil)
+
+	connFactory := new(backendmock.ConnectionFactory)
+	connFactory.On("GetExecutionAPIClient", mock.Anything).Return(suite.execClient, &mockCloser{}, nil)
+
+	exeEvents := getEvents(3)
+	exeResults := make([]*execproto.GetEventsForBlockIDsResponse_Result, 0, 2)
+	for i := 2; i < len(blockHeaders); i++ {
+		exeResults = append(exeResults, &execproto.GetEventsForBlockIDsResponse_Result{
+			BlockId:     convert.IdentifierToMessage(blockIDs[i]),
+			BlockHeight: blockHeaders[i].Height,
+			Events:      convert.EventsToMessages(exeEvents),
+		})
+	}
+	exeReq := &execproto.GetEventsForBlockIDsRequest{
+		BlockIds: convert.IdentifiersToMessages(blockIDs[2:]),
+		Type:     eventType,
+	}
+	suite.execClient.
+		On("GetEventsForBlockIDs", ctx, exeReq).
+		Return(&execproto.GetEventsForBlockIDsResponse{Results: exeResults}, nil).
+		Once()
+
+	expected := make([]flow.BlockEvents, len(blockHeaders))
+	for i, header := range blockHeaders {
+		blockEvents := indexedEvents
+		if i >= 2 {
+			blockEvents = exeEvents
+		}
+		expected[i] = flow.BlockEvents{
+			BlockID:        header.ID(),
+			BlockHeight:    header.Height,
+			BlockTimestamp: header.Timestamp,
+			Events:         blockEvents,
+		}
+	}
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		nil,
+		suite.headers,
+		nil,
+		nil,
+		suite.receipts,
+		suite.results,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		connFactory,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		flow.IdentifierList(exeIDs.NodeIDs()).Strings(),
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+	backend.SetIndexedData(NewIndexedData(reporter, events, nil))
+
+	actual, err := backend.GetEventsForBlockIDs(ctx, eventType, blockIDs)
+	suite.checkResponse(actual, err)
+	suite.Require().Equal(expected, actual)
+
+	events.AssertExpectations(suite.T())
+	suite.assertAllExpectations()
+}
+
 func (suite *Suite) TestGetExecutionResultByID() {
 	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
 
@@ -2287,6 +2485,75 @@ func (suite *Suite) TestExecuteScriptOnExecutionNode() {
 	})
 }
 
+// TestExecuteScriptFromIndex tests that scripts at heights whose registers have been indexed are
+// executed locally, without querying execution nodes.
+func (suite *Suite) TestExecuteScriptFromIndex() {
+	unittest.RunWithBadgerDB(suite.T(), func(db *badger.DB) {
+		ctx := context.Background()
+		header := unittest.BlockHeaderFixture()
+
+		registersDB := badgerimpl.ToDB(db)
+		err := store.BootstrapRegisters(registersDB, header.Height, func() (flow.RegisterEntries, error) {
+			return nil, nil
+		})
+		suite.Require().NoError(err)
+		registers, err := store.NewRegisters(registersDB)
+		suite.Require().NoError(err)
+
+		scriptExecutor, err := NewScriptExecutor(
+			suite.log,
+			metrics.NewNoopCollector(),
+			append(fvm.ChainOptions(suite.chainID), fvm.WithBlocks(environment.NewBlockFinder(suite.headers))),
+			registers,
+		)
+		suite.Require().NoError(err)
+
+		backend := New(
+			suite.state,
+			nil,
+			nil,
+			suite.blocks,
+			suite.headers,
+			suite.collections,
+			suite.transactions,
+			suite.receipts,
+			suite.results,
+			suite.chainID,
+			metrics.NewNoopCollector(),
+			suite.connectionFactory, // the connection factory must not be used
+			false,
+			DefaultMaxHeightRange,
+			nil,
+			nil,
+			suite.log,
+			DefaultSnapshotHistoryLimit,
+			nil,
+		)
+		backend.SetScriptExecutor(scriptExecutor)
+
+		suite.headers.On("ByHeight", header.Height).Return(header, nil)
+
+		suite.Run("script is executed at the height of the block", func() {
+			script := []byte("pub fun main(): UInt64 { return getCurrentBlock().height }")
+			value, err := backend.ExecuteScriptAtBlockHeight(ctx, header.Height, script, nil)
+			suite.Require().NoError(err)
+
+			expected, err := jsoncdc.Encode(cadence.NewUInt64(header.Height))
+			suite.Require().NoError(err)
+			suite.Assert().Equal(expected, value)
+		})
+
+		suite.Run("failed script returns status code InvalidArgument", func() {
+			script := []byte("pub fun main(): Int { panic(\"failure\") }")
+			_, err := backend.ExecuteScriptAtBlockHeight(ctx, header.Height, script, nil)
+			suite.Require().Error(err)
+			suite.Assert().Equal(codes.InvalidArgument, status.Code(err))
+		})
+
+		suite.connectionFactory.AssertNotCalled(suite.T(), "GetExecutionAPIClient", mock.Anything)
+	})
+}
+
 func (suite *Suite) assertAllExpectations() {
 	suite.snapshot.AssertExpectations(suite.T())
 	suite.state.AssertExpectations(suite.T())
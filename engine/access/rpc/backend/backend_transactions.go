@@ -38,6 +38,7 @@ type backendTransactions struct {
 	transactionValidator *access.TransactionValidator
 	retry                *Retry
 	connFactory          ConnectionFactory
+	indexedData          *IndexedData // nil if execution data isn't indexed
 
 	previousAccessNodes []accessproto.AccessAPIClient
 	log                 zerolog.Logger
@@ -274,8 +275,8 @@ func (b *backendTransactions) GetTransactionResult(
 	// access node may not have the block if it hasn't yet been finalized, hence block can be nil at this point
 	if block != nil {
 		blockID = block.ID()
-		transactionWasExecuted, events, statusCode, txError, err = b.lookupTransactionResult(ctx, txID, blockID)
 		blockHeight = block.Header.Height
+		transactionWasExecuted, events, statusCode, txError, err = b.lookupTransactionResult(ctx, txID, blockID, blockHeight)
 		if err != nil {
 			return nil, rpc.ConvertError(err, "failed to retrieve result from any execution node", codes.Internal)
 		}
@@ -368,20 +369,9 @@ func (b *backendTransactions) GetTransactionResultsByBlockID(
 		return nil, rpc.ConvertStorageError(err)
 	}
 
-	req := &execproto.GetTransactionsByBlockIDRequest{
-		BlockId: blockID[:],
-	}
-	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
-	if err != nil {
-		if IsInsufficientExecutionReceipts(err) {
-			return nil, status.Errorf(codes.NotFound, err.Error())
-		}
-		return nil, rpc.ConvertError(err, "failed to retrieve result from any execution node", codes.Internal)
-	}
-
-	resp, err := b.getTransactionResultsByBlockIDFromAnyExeNode(ctx, execNodes, req)
+	resp, err := b.getTransactionResultsByBlockID(ctx, block)
 	if err != nil {
-		return nil, rpc.ConvertError(err, "failed to retrieve result from execution node", codes.Internal)
+		return nil, err
 	}
 
 	results := make([]*access.TransactionResult, 0, len(resp.TransactionResults))
@@ -486,22 +476,9 @@ func (b *backendTransactions) GetTransactionResultByIndex(
 		return nil, rpc.ConvertStorageError(err)
 	}
 
-	// create request and forward to EN
-	req := &execproto.GetTransactionByIndexRequest{
-		BlockId: blockID[:],
-		Index:   index,
-	}
-	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	resp, err := b.getTransactionResultByIndex(ctx, block, index)
 	if err != nil {
-		if IsInsufficientExecutionReceipts(err) {
-			return nil, status.Errorf(codes.NotFound, err.Error())
-		}
-		return nil, rpc.ConvertError(err, "failed to retrieve result from any execution node", codes.Internal)
-	}
-
-	resp, err := b.getTransactionResultByIndexFromAnyExeNode(ctx, execNodes, req)
-	if err != nil {
-		return nil, rpc.ConvertError(err, "failed to retrieve result from execution node", codes.Internal)
+		return nil, err
 	}
 
 	// tx body is irrelevant to status if it's in an executed block
@@ -622,8 +599,20 @@ func (b *backendTransactions) lookupTransactionResult(
 	ctx context.Context,
 	txID flow.Identifier,
 	blockID flow.Identifier,
+	blockHeight uint64,
 ) (bool, []flow.Event, uint32, string, error) {
 
+	if b.indexedData.isIndexed(blockHeight) {
+		resp, err := b.indexedData.transactionResult(blockID, txID)
+		if err == nil {
+			return true, convert.MessagesToEvents(resp.GetEvents()), resp.GetStatusCode(), resp.GetErrorMessage(), nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return false, nil, 0, "", err
+		}
+		// the result isn't indexed, fall back to the execution nodes
+	}
+
 	events, txStatus, message, err := b.getTransactionResultFromExecutionNode(ctx, blockID, txID[:])
 	if err != nil {
 		// if either the execution node reported no results or the execution node could not be chosen
@@ -734,6 +723,85 @@ func (b *backendTransactions) getTransactionResultFromExecutionNode(
 	return events, resp.GetStatusCode(), resp.GetErrorMessage(), nil
 }
 
+// getTransactionResultsByBlockID returns the results of all transactions of the given block, from the
+// indexed execution data if available, or from the execution nodes otherwise.
+// All errors are converted into gRPC status errors.
+func (b *backendTransactions) getTransactionResultsByBlockID(
+	ctx context.Context,
+	block *flow.Block,
+) (*execproto.GetTransactionResultsResponse, error) {
+	blockID := block.ID()
+
+	if b.indexedData.isIndexed(block.Header.Height) {
+		resp, err := b.indexedData.transactionResults(blockID)
+		if err == nil {
+			return resp, nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return nil, status.Errorf(codes.Internal, "failed to retrieve indexed transaction results: %v", err)
+		}
+		// the results aren't indexed, fall back to the execution nodes
+	}
+
+	req := &execproto.GetTransactionsByBlockIDRequest{
+		BlockId: blockID[:],
+	}
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	if err != nil {
+		if IsInsufficientExecutionReceipts(err) {
+			return nil, status.Errorf(codes.NotFound, err.Error())
+		}
+		return nil, rpc.ConvertError(err, "failed to retrieve result from any execution node", codes.Internal)
+	}
+
+	resp, err := b.getTransactionResultsByBlockIDFromAnyExeNode(ctx, execNodes, req)
+	if err != nil {
+		return nil, rpc.ConvertError(err, "failed to retrieve result from execution node", codes.Internal)
+	}
+	return resp, nil
+}
+
+// getTransactionResultByIndex returns the result of the transaction at the given index of the given block,
+// from the indexed execution data if available, or from the execution nodes otherwise.
+// All errors are converted into gRPC status errors.
+func (b *backendTransactions) getTransactionResultByIndex(
+	ctx context.Context,
+	block *flow.Block,
+	index uint32,
+) (*execproto.GetTransactionResultResponse, error) {
+	blockID := block.ID()
+
+	if b.indexedData.isIndexed(block.Header.Height) {
+		resp, err := b.indexedData.transactionResultByIndex(blockID, index)
+		if err == nil {
+			return resp, nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return nil, status.Errorf(codes.Internal, "failed to retrieve indexed transaction result: %v", err)
+		}
+		// the result isn't indexed, fall back to the execution nodes
+	}
+
+	// create request and forward to EN
+	req := &execproto.GetTransactionByIndexRequest{
+		BlockId: blockID[:],
+		Index:   index,
+	}
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	if err != nil {
+		if IsInsufficientExecutionReceipts(err) {
+			return nil, status.Errorf(codes.NotFound, err.Error())
+		}
+		return nil, rpc.ConvertError(err, "failed to retrieve result from any execution node", codes.Internal)
+	}
+
+	resp, err := b.getTransactionResultByIndexFromAnyExeNode(ctx, execNodes, req)
+	if err != nil {
+		return nil, rpc.ConvertError(err, "failed to retrieve result from execution node", codes.Internal)
+	}
+	return resp, nil
+}
+
 func (b *backendTransactions) NotifyFinalizedBlockHeight(height uint64) {
 	b.retry.Retry(height)
 }
@@ -17,6 +17,7 @@ import (
 	"google.golang.org/grpc/credentials"
 
 	"github.com/onflow/flow-go/consensus/hotstuff/model"
+	"github.com/onflow/flow-go/engine/access/apikey"
 	"github.com/onflow/flow-go/engine/access/rest"
 	"github.com/onflow/flow-go/engine/access/rpc/backend"
 	"github.com/onflow/flow-go/engine/common/rpc"
@@ -48,6 +49,7 @@ type Config struct {
 	PreferredExecutionNodeIDs []string                         // preferred list of upstream execution node IDs
 	FixedExecutionNodeIDs     []string                         // fixed list of execution node IDs to choose from if no node node ID can be chosen from the PreferredExecutionNodeIDs
 	ArchiveAddressList        []string                         // the archive node address list to send script executions. when configured, script executions will be all sent to the archive node
+	APIKeys                   *apikey.Authenticator            // the optional API key authenticator for the gRPC and REST servers. when nil, requests are not authenticated
 }
 
 // Engine exposes the server with a simplified version of the Access API.
@@ -113,6 +115,12 @@ func NewBuilder(log zerolog.Logger,
 		interceptors = append(interceptors, grpc_prometheus.UnaryServerInterceptor)
 	}
 
+	if config.APIKeys != nil {
+		// authenticate requests before applying the global rate limits, so that unauthenticated
+		// requests do not consume the budget of authenticated clients
+		interceptors = append(interceptors, config.APIKeys.UnaryServerInterceptor)
+	}
+
 	if len(apiRatelimits) > 0 {
 		// create a rate limit interceptor
 		rateLimitInterceptor := rpc.NewRateLimiterInterceptor(log, apiRatelimits, apiBurstLimits).UnaryServerInterceptor
@@ -127,6 +135,11 @@ func NewBuilder(log zerolog.Logger,
 	chainedInterceptors := grpc.ChainUnaryInterceptor(interceptors...)
 	grpcOpts = append(grpcOpts, chainedInterceptors)
 
+	if config.APIKeys != nil {
+		// streaming requests are subject to the same API key checks as unary requests
+		grpcOpts = append(grpcOpts, grpc.ChainStreamInterceptor(config.APIKeys.StreamServerInterceptor))
+	}
+
 	// create an unsecured grpc server
 	unsecureGrpcServer := grpc.NewServer(grpcOpts...)
 
@@ -383,7 +396,7 @@ func (e *Engine) serveREST(ctx irrecoverable.SignalerContext, ready component.Re
 
 	e.log.Info().Str("rest_api_address", e.config.RESTListenAddr).Msg("starting REST server on address")
 
-	r, err := rest.NewServer(e.backend, e.config.RESTListenAddr, e.log, e.chain)
+	r, err := rest.NewServer(e.backend, e.config.RESTListenAddr, e.log, e.chain, e.config.APIKeys)
 	if err != nil {
 		e.log.Err(err).Msg("failed to initialize the REST server")
 		ctx.Throw(err)
@@ -11,6 +11,7 @@ import (
 	"github.com/onflow/flow-go/access"
 	legacyaccess "github.com/onflow/flow-go/access/legacy"
 	"github.com/onflow/flow-go/consensus/hotstuff"
+	"github.com/onflow/flow-go/engine/access/rpc/backend"
 	"github.com/onflow/flow-go/module"
 )
 
@@ -78,6 +79,32 @@ func (builder *RPCEngineBuilder) WithLegacy() *RPCEngineBuilder {
 	return builder
 }
 
+// WithIndexedData specifies that events and transaction results of blocks whose execution data has
+// been indexed should be served from the index.
+// Returns self-reference for chaining.
+func (builder *RPCEngineBuilder) WithIndexedData(indexedData *backend.IndexedData) *RPCEngineBuilder {
+	builder.backend.SetIndexedData(indexedData)
+	return builder
+}
+
+// WithScriptExecutor specifies that scripts at heights whose registers have been indexed should be
+// executed locally.
+// Returns self-reference for chaining.
+func (builder *RPCEngineBuilder) WithScriptExecutor(scriptExecutor *backend.ScriptExecutor) *RPCEngineBuilder {
+	builder.backend.SetScriptExecutor(scriptExecutor)
+	return builder
+}
+
+// DefaultHandler returns a handler serving API queries from the engine's backend. This is the handler
+// used when no other handler is injected via method `WithNewHandler`. An injected handler, e.g. a proxy,
+// may use it to serve part of the queries from local data.
+func (builder *RPCEngineBuilder) DefaultHandler() *access.Handler {
+	if builder.signerIndicesDecoder == nil {
+		return access.NewHandler(builder.Engine.backend, builder.Engine.chain, builder.finalizedHeaderCache, builder.me)
+	}
+	return access.NewHandler(builder.Engine.backend, builder.Engine.chain, builder.finalizedHeaderCache, builder.me, access.WithBlockSignerDecoder(builder.signerIndicesDecoder))
+}
+
 // WithMetrics specifies the metrics should be collected.
 // Returns self-reference for chaining.
 func (builder *RPCEngineBuilder) WithMetrics() *RPCEngineBuilder {
@@ -94,11 +121,7 @@ func (builder *RPCEngineBuilder) Build() (*Engine, error) {
 	}
 	handler := builder.handler
 	if handler == nil {
-		if builder.signerIndicesDecoder == nil {
-			handler = access.NewHandler(builder.Engine.backend, builder.Engine.chain, builder.finalizedHeaderCache, builder.me)
-		} else {
-			handler = access.NewHandler(builder.Engine.backend, builder.Engine.chain, builder.finalizedHeaderCache, builder.me, access.WithBlockSignerDecoder(builder.signerIndicesDecoder))
-		}
+		handler = builder.DefaultHandler()
 	}
 	accessproto.RegisterAccessAPIServer(builder.unsecureGrpcServer, handler)
 	accessproto.RegisterAccessAPIServer(builder.secureGrpcServer, handler)
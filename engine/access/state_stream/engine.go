@@ -11,6 +11,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/engine/access/apikey"
 	"github.com/onflow/flow-go/engine/common/rpc"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
@@ -48,6 +49,10 @@ type Config struct {
 
 	// ClientSendBufferSize is the size of the response buffer for sending messages to the client.
 	ClientSendBufferSize uint
+
+	// APIKeys is the optional API key authenticator for the GRPC server. When nil, requests are
+	// not authenticated.
+	APIKeys *apikey.Authenticator
 }
 
 // Engine exposes the server with the state stream API.
@@ -96,6 +101,12 @@ func NewEng(
 		interceptors = append(interceptors, grpc_prometheus.UnaryServerInterceptor)
 	}
 
+	if config.APIKeys != nil {
+		// authenticate requests before applying the global rate limits, so that unauthenticated
+		// requests do not consume the budget of authenticated clients
+		interceptors = append(interceptors, config.APIKeys.UnaryServerInterceptor)
+	}
+
 	if len(apiRatelimits) > 0 {
 		// create a rate limit interceptor
 		rateLimitInterceptor := rpc.NewRateLimiterInterceptor(log, apiRatelimits, apiBurstLimits).UnaryServerInterceptor
@@ -110,6 +121,11 @@ func NewEng(
 	chainedInterceptors := grpc.ChainUnaryInterceptor(interceptors...)
 	grpcOpts = append(grpcOpts, chainedInterceptors)
 
+	if config.APIKeys != nil {
+		// the subscription endpoints are streaming, authenticate them like unary requests
+		grpcOpts = append(grpcOpts, grpc.ChainStreamInterceptor(config.APIKeys.StreamServerInterceptor))
+	}
+
 	server := grpc.NewServer(grpcOpts...)
 
 	execDataCache := herocache.NewBlockExecutionData(config.ExecutionDataCacheSize, logger, heroCacheMetrics)
@@ -881,6 +881,10 @@ func BlockExecutionDataToMessage(data *execution_data.BlockExecutionData) (
 	}, nil
 }
 
+// ChunkExecutionDataToMessage converts the chunk execution data to a protobuf message.
+// The protobuf schema has no field for the transaction results of the chunk yet, hence they are
+// not part of the message, and MessageToChunkExecutionData returns chunk execution data without
+// transaction results.
 func ChunkExecutionDataToMessage(data *execution_data.ChunkExecutionData) (
 	*entities.ChunkExecutionData,
 	error,
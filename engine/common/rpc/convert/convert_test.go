@@ -151,4 +151,24 @@ func TestConvertBlockExecutionData(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, bed, bedReConverted)
 	})
+
+	t.Run("transaction results are not part of the message", func(t *testing.T) {
+		chunk := *bed.ChunkExecutionDatas[0]
+		for _, tx := range chunk.Collection.Transactions {
+			chunk.TransactionResults = append(chunk.TransactionResults, flow.TransactionResult{
+				TransactionID:   tx.ID(),
+				ComputationUsed: 10,
+			})
+		}
+
+		chunkMsg, err := convert.ChunkExecutionDataToMessage(&chunk)
+		assert.Nil(t, err)
+
+		chunkReConverted, err := convert.MessageToChunkExecutionData(chunkMsg, flow.Testnet.Chain())
+		assert.Nil(t, err)
+		assert.Empty(t, chunkReConverted.TransactionResults)
+
+		chunkReConverted.TransactionResults = chunk.TransactionResults
+		assert.Equal(t, &chunk, chunkReConverted)
+	})
 }
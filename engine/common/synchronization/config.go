@@ -9,6 +9,7 @@ import (
 type Config struct {
 	PollInterval time.Duration
 	ScanInterval time.Duration
+	HeaderFirst  *core.HeaderFirst // optional, enables header-first synchronization
 }
 
 func DefaultConfig() *Config {
@@ -36,3 +37,12 @@ func WithScanInterval(interval time.Duration) OptionFunc {
 		cfg.ScanInterval = interval
 	}
 }
+
+// WithHeaderFirst enables header-first synchronization: when the node falls far behind, the
+// finalized chain is fetched as validated headers first, and the block payloads are then
+// downloaded in parallel from multiple peers.
+func WithHeaderFirst(headerFirst *core.HeaderFirst) OptionFunc {
+	return func(cfg *Config) {
+		cfg.HeaderFirst = headerFirst
+	}
+}
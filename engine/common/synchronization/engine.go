@@ -32,6 +32,9 @@ const defaultSyncResponseQueueCapacity = 500
 // defaultBlockResponseQueueCapacity maximum capacity of block responses queue
 const defaultBlockResponseQueueCapacity = 500
 
+// defaultHeaderResponseQueueCapacity maximum capacity of header responses queue
+const defaultHeaderResponseQueueCapacity = 500
+
 // Engine is the synchronization engine, responsible for synchronizing chain state.
 type Engine struct {
 	// TODO replace engine.Unit and lifecycle.LifecycleManager with component.ComponentManager
@@ -47,6 +50,7 @@ type Engine struct {
 	pollInterval         time.Duration
 	scanInterval         time.Duration
 	core                 module.SyncCore
+	headerFirst          *synccore.HeaderFirst // nil if header-first synchronization is disabled
 	state                protocol.State
 	participantsProvider module.IdentifierProvider
 
@@ -54,6 +58,7 @@ type Engine struct {
 
 	pendingSyncResponses   engine.MessageStore    // message store for *message.SyncResponse
 	pendingBlockResponses  engine.MessageStore    // message store for *message.BlockResponse
+	pendingHeaderResponses engine.MessageStore    // message store for *message.HeaderResponse
 	responseMessageHandler *engine.MessageHandler // message handler responsible for response processing
 }
 
@@ -91,6 +96,7 @@ func New(
 		blocks:               blocks,
 		comp:                 comp,
 		core:                 core,
+		headerFirst:          opt.HeaderFirst,
 		pollInterval:         opt.PollInterval,
 		scanInterval:         opt.ScanInterval,
 		participantsProvider: participantsProvider,
@@ -133,6 +139,15 @@ func (e *Engine) setupResponseMessageHandler() error {
 		FifoQueue: blockResponseQueue,
 	}
 
+	headerResponseQueue, err := fifoqueue.NewFifoQueue(defaultHeaderResponseQueueCapacity)
+	if err != nil {
+		return fmt.Errorf("failed to create queue for header responses: %w", err)
+	}
+
+	e.pendingHeaderResponses = &engine.FifoMessageStore{
+		FifoQueue: headerResponseQueue,
+	}
+
 	// define message queueing behaviour
 	e.responseMessageHandler = engine.NewMessageHandler(
 		e.log,
@@ -157,6 +172,16 @@ func (e *Engine) setupResponseMessageHandler() error {
 			},
 			Store: e.pendingBlockResponses,
 		},
+		engine.Pattern{
+			Match: func(msg *engine.Message) bool {
+				_, ok := msg.Payload.(*messages.HeaderResponse)
+				if ok {
+					e.metrics.MessageReceived(metrics.EngineSynchronization, metrics.MessageHeaderResponse)
+				}
+				return ok
+			},
+			Store: e.pendingHeaderResponses,
+		},
 	)
 
 	return nil
@@ -230,9 +255,9 @@ func (e *Engine) Process(channel channels.Channel, originID flow.Identifier, eve
 //   - All other errors are potential symptoms of internal state corruption or bugs (fatal).
 func (e *Engine) process(originID flow.Identifier, event interface{}) error {
 	switch event.(type) {
-	case *messages.RangeRequest, *messages.BatchRequest, *messages.SyncRequest:
+	case *messages.RangeRequest, *messages.BatchRequest, *messages.SyncRequest, *messages.HeaderRangeRequest:
 		return e.requestHandler.process(originID, event)
-	case *messages.SyncResponse, *messages.BlockResponse:
+	case *messages.SyncResponse, *messages.BlockResponse, *messages.HeaderResponse:
 		return e.responseMessageHandler.Process(originID, event)
 	default:
 		return fmt.Errorf("received input with type %T from %x: %w", event, originID[:], engine.IncompatibleInputTypeError)
@@ -275,6 +300,13 @@ func (e *Engine) processAvailableResponses() {
 			continue
 		}
 
+		msg, ok = e.pendingHeaderResponses.Get()
+		if ok {
+			e.onHeaderResponse(msg.OriginID, msg.Payload.(*messages.HeaderResponse))
+			e.metrics.MessageHandled(metrics.EngineSynchronization, metrics.MessageHeaderResponse)
+			continue
+		}
+
 		// when there is no more messages in the queue, back to the loop to wait
 		// for the next incoming message to arrive.
 		return
@@ -289,6 +321,9 @@ func (e *Engine) onSyncResponse(originID flow.Identifier, res *messages.SyncResp
 		e.log.Fatal().Err(err).Msg("unexpected fatal error retrieving latest finalized block")
 	}
 	e.core.HandleHeight(final, res.Height)
+	if e.headerFirst != nil {
+		e.headerFirst.HandleHeight(final, res.Height)
+	}
 }
 
 // onBlockResponse processes a response containing a specifically requested block.
@@ -306,6 +341,10 @@ func (e *Engine) onBlockResponse(originID flow.Identifier, res *messages.BlockRe
 	filteredBlocks := make([]*messages.BlockProposal, 0, len(res.Blocks))
 	for _, block := range res.Blocks {
 		header := block.Header
+		// blocks with a header validated by header-first sync are forwarded in height order below
+		if e.headerFirst != nil && e.headerFirst.HandleBlock(originID, block.ToInternal()) {
+			continue
+		}
 		if !e.core.HandleBlock(&header) {
 			e.log.Debug().Uint64("height", header.Height).Msg("block handler rejected")
 			continue
@@ -314,10 +353,67 @@ func (e *Engine) onBlockResponse(originID flow.Identifier, res *messages.BlockRe
 	}
 
 	// forward the block to the compliance engine for validation and processing
-	e.comp.OnSyncedBlocks(flow.Slashable[[]*messages.BlockProposal]{
-		OriginID: originID,
-		Message:  filteredBlocks,
-	})
+	if len(filteredBlocks) > 0 {
+		e.comp.OnSyncedBlocks(flow.Slashable[[]*messages.BlockProposal]{
+			OriginID: originID,
+			Message:  filteredBlocks,
+		})
+	}
+
+	if e.headerFirst != nil {
+		e.forwardHeaderFirstBlocks()
+	}
+}
+
+// forwardHeaderFirstBlocks forwards the blocks downloaded by header-first sync, which are ready
+// to be processed, to the compliance engine in height order. Consecutive blocks from the same
+// origin are forwarded together.
+func (e *Engine) forwardHeaderFirstBlocks() {
+	ready := e.headerFirst.ReadyBlocks()
+	for len(ready) > 0 {
+		originID := ready[0].OriginID
+		proposals := make([]*messages.BlockProposal, 0, len(ready))
+		for len(ready) > 0 && ready[0].OriginID == originID {
+			proposals = append(proposals, messages.NewBlockProposal(ready[0].Message))
+			ready = ready[1:]
+		}
+
+		e.log.Debug().
+			Uint64("first", proposals[0].Block.Header.Height).
+			Uint64("last", proposals[len(proposals)-1].Block.Header.Height).
+			Msg("forwarding blocks synced header-first")
+		e.comp.OnSyncedBlocks(flow.Slashable[[]*messages.BlockProposal]{
+			OriginID: originID,
+			Message:  proposals,
+		})
+	}
+}
+
+// onHeaderResponse processes a response containing a chain of headers requested by header-first sync.
+func (e *Engine) onHeaderResponse(originID flow.Identifier, res *messages.HeaderResponse) {
+	if e.headerFirst == nil {
+		e.log.Debug().Str("origin_id", originID.String()).Msg("discarding header response, header-first sync is disabled")
+		return
+	}
+	if len(res.Headers) == 0 {
+		e.log.Debug().Msg("received empty header response")
+		return
+	}
+
+	headers := make([]*flow.Header, 0, len(res.Headers))
+	for i := range res.Headers {
+		headers = append(headers, &res.Headers[i])
+	}
+
+	e.log.Debug().
+		Uint64("first", headers[0].Height).
+		Uint64("last", headers[len(headers)-1].Height).
+		Msg("received header response")
+
+	err := e.headerFirst.HandleHeaders(originID, headers)
+	if err != nil {
+		e.log.Fatal().Err(err).Msg("unexpected fatal error handling headers")
+	}
 }
 
 // checkLoop will regularly scan for items that need requesting.
@@ -351,6 +447,15 @@ CheckLoop:
 			}
 			participants := e.participantsProvider.Identifiers()
 			ranges, batches := e.core.ScanPending(final)
+			if e.headerFirst != nil {
+				headerRanges, bodyBatches := e.headerFirst.ScanPending(final)
+				if e.headerFirst.Active() {
+					// the finalized blocks are downloaded header-first, so only the blocks
+					// requested by ID are requested in full
+					ranges = nil
+				}
+				e.sendHeaderFirstRequests(participants, headerRanges, bodyBatches)
+			}
 			e.sendRequests(participants, ranges, batches)
 		}
 	}
@@ -430,3 +535,52 @@ func (e *Engine) sendRequests(participants flow.IdentifierList, ranges []chainsy
 		e.log.Warn().Err(err).Msg("sending range and batch requests failed")
 	}
 }
+
+// sendHeaderFirstRequests sends a request for each header range and payload batch of header-first
+// sync. Each payload batch is sent to a single random peer, so the payloads are downloaded from
+// multiple peers in parallel.
+func (e *Engine) sendHeaderFirstRequests(participants flow.IdentifierList, ranges []chainsync.Range, batches []chainsync.Batch) {
+	var errs *multierror.Error
+
+	for _, ran := range ranges {
+		req := &messages.HeaderRangeRequest{
+			Nonce:      rand.Uint64(),
+			FromHeight: ran.From,
+			ToHeight:   ran.To,
+		}
+		err := e.con.Multicast(req, 1, participants...)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("could not submit header range request: %w", err))
+			continue
+		}
+		e.log.Info().
+			Uint64("range_from", req.FromHeight).
+			Uint64("range_to", req.ToHeight).
+			Uint64("range_nonce", req.Nonce).
+			Msg("header range requested")
+		e.headerFirst.HeadersRequested(ran)
+		e.metrics.MessageSent(metrics.EngineSynchronization, metrics.MessageHeaderRangeRequest)
+	}
+
+	for _, batch := range batches {
+		req := &messages.BatchRequest{
+			Nonce:    rand.Uint64(),
+			BlockIDs: batch.BlockIDs,
+		}
+		err := e.con.Multicast(req, 1, participants...)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("could not submit payload batch request: %w", err))
+			continue
+		}
+		e.log.Debug().
+			Int("blocks", len(batch.BlockIDs)).
+			Uint64("range_nonce", req.Nonce).
+			Msg("payload batch requested")
+		e.headerFirst.BodiesRequested(batch)
+		e.metrics.MessageSent(metrics.EngineSynchronization, metrics.MessageBatchRequest)
+	}
+
+	if err := errs.ErrorOrNil(); err != nil {
+		e.log.Warn().Err(err).Msg("sending header-first requests failed")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/onflow/flow-go/consensus/hotstuff/mocks"
 	"github.com/onflow/flow-go/engine"
 	mockconsensus "github.com/onflow/flow-go/engine/consensus/mock"
 	"github.com/onflow/flow-go/model/flow"
@@ -466,6 +467,111 @@ func (ss *SyncSuite) TestOnBlockResponse() {
 	ss.core.AssertExpectations(ss.T())
 }
 
+func (ss *SyncSuite) TestOnHeaderRangeRequest() {
+
+	// generate originID and header range request
+	originID := unittest.IdentifierFixture()
+	req := &messages.HeaderRangeRequest{
+		Nonce: rand.Uint64(),
+	}
+
+	// finalized headers at heights -2 to 0 from head
+	ref := ss.head.Height
+	headers := map[uint64]*flow.Header{ref: ss.head}
+	for height := ref - 2; height < ref; height++ {
+		headers[height] = unittest.BlockHeaderFixture(func(header *flow.Header) {
+			header.Height = height
+		})
+	}
+	ss.state.On("AtHeight", mock.Anything).Return(
+		func(height uint64) protocolint.Snapshot {
+			header, ok := headers[height]
+			if !ok {
+				return unittest.StateSnapshotForUnknownBlock()
+			}
+			snapshot := &protocol.Snapshot{}
+			snapshot.On("Head").Return(header, nil)
+			return snapshot
+		},
+	)
+
+	// range above the finalized height should be a no-op
+	ss.T().Run("range above finalized", func(t *testing.T) {
+		req.FromHeight = ref + 1
+		req.ToHeight = ref + 3
+		err := ss.e.requestHandler.onHeaderRangeRequest(originID, req)
+		require.NoError(ss.T(), err, "unknown header range request should pass")
+		ss.con.AssertNumberOfCalls(ss.T(), "Unicast", 0)
+	})
+
+	// a request for a range that we partially have should send partial response
+	ss.T().Run("have partial range", func(t *testing.T) {
+		req.FromHeight = ref - 1
+		req.ToHeight = ref + 2
+		ss.con.On("Unicast", mock.Anything, mock.Anything).Return(nil).Once().Run(
+			func(args mock.Arguments) {
+				res := args.Get(0).(*messages.HeaderResponse)
+				expected := []flow.Header{*headers[ref-1], *headers[ref]}
+				assert.Equal(ss.T(), expected, res.Headers, "response should contain the known headers")
+			},
+		)
+		err := ss.e.requestHandler.onHeaderRangeRequest(originID, req)
+		require.NoError(ss.T(), err, "header range request with unknown heights should pass")
+	})
+
+	// a request for a range we entirely have should send all headers in order
+	ss.T().Run("have entire range", func(t *testing.T) {
+		req.FromHeight = ref - 2
+		req.ToHeight = ref
+		ss.con.On("Unicast", mock.Anything, mock.Anything).Return(nil).Once().Run(
+			func(args mock.Arguments) {
+				res := args.Get(0).(*messages.HeaderResponse)
+				expected := []flow.Header{*headers[ref-2], *headers[ref-1], *headers[ref]}
+				assert.Equal(ss.T(), expected, res.Headers, "response should contain right headers")
+				assert.Equal(ss.T(), req.Nonce, res.Nonce, "response should contain request nonce")
+				recipientID := args.Get(1).(flow.Identifier)
+				assert.Equal(ss.T(), originID, recipientID, "should send response to original requester")
+			},
+		)
+		err := ss.e.requestHandler.onHeaderRangeRequest(originID, req)
+		require.NoError(ss.T(), err, "valid header range request should pass")
+	})
+}
+
+func (ss *SyncSuite) TestOnBlockResponse_HeaderFirst() {
+
+	// validate the headers of a chain of blocks above the finalized head
+	blocks := unittest.ChainFixtureFrom(4, ss.head)
+	validator := &mocks.Validator{}
+	validator.On("ValidateQC", mock.Anything).Return(nil)
+	config := synccore.DefaultHeaderFirstConfig()
+	config.Threshold = 1
+	ss.e.headerFirst = synccore.NewHeaderFirst(ss.e.log, config, validator, metrics.NewNoopCollector(), ss.head)
+
+	ss.e.headerFirst.HandleHeight(ss.head, ss.head.Height+10)
+	ss.e.onHeaderResponse(unittest.IdentifierFixture(), &messages.HeaderResponse{
+		Headers: []flow.Header{*blocks[0].Header, *blocks[1].Header, *blocks[2].Header, *blocks[3].Header},
+	})
+
+	// the second block is received first, and is held back until the first block is received
+	originID := unittest.IdentifierFixture()
+	ss.e.onBlockResponse(originID, &messages.BlockResponse{
+		Blocks: []messages.UntrustedBlock{messages.UntrustedBlockFromInternal(blocks[1])},
+	})
+	ss.comp.AssertNotCalled(ss.T(), "OnSyncedBlocks", mock.Anything)
+
+	ss.comp.On("OnSyncedBlocks", mock.Anything).Run(func(args mock.Arguments) {
+		res := args.Get(0).(flow.Slashable[[]*messages.BlockProposal])
+		ss.Require().Len(res.Message, 2)
+		ss.Assert().Equal(blocks[0].ID(), res.Message[0].Block.ToInternal().ID())
+		ss.Assert().Equal(blocks[1].ID(), res.Message[1].Block.ToInternal().ID())
+		ss.Assert().Equal(originID, res.OriginID)
+	}).Once()
+	ss.e.onBlockResponse(originID, &messages.BlockResponse{
+		Blocks: []messages.UntrustedBlock{messages.UntrustedBlockFromInternal(blocks[0])},
+	})
+}
+
 func (ss *SyncSuite) TestPollHeight() {
 
 	// check that we send to three nodes from our total list
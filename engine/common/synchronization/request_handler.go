@@ -14,6 +14,7 @@ import (
 	"github.com/onflow/flow-go/module/lifecycle"
 	"github.com/onflow/flow-go/module/metrics"
 	"github.com/onflow/flow-go/network/channels"
+	"github.com/onflow/flow-go/state"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
 	"github.com/onflow/flow-go/utils/logging"
@@ -28,6 +29,9 @@ const defaultRangeRequestQueueCapacity = 500
 // defaultSyncRequestQueueCapacity maximum capacity of batch requests queue
 const defaultBatchRequestQueueCapacity = 500
 
+// defaultHeaderRangeRequestQueueCapacity maximum capacity of header range requests queue
+const defaultHeaderRangeRequestQueueCapacity = 500
+
 // defaultEngineRequestsWorkers number of workers to dispatch events for requests
 const defaultEngineRequestsWorkers = 8
 
@@ -47,6 +51,7 @@ type RequestHandler struct {
 	pendingSyncRequests   engine.MessageStore    // message store for *message.SyncRequest
 	pendingBatchRequests  engine.MessageStore    // message store for *message.BatchRequest
 	pendingRangeRequests  engine.MessageStore    // message store for *message.RangeRequest
+	pendingHeaderRequests engine.MessageStore    // message store for *message.HeaderRangeRequest
 	requestMessageHandler *engine.MessageHandler // message handler responsible for request processing
 
 	queueMissingHeights bool // true if missing heights should be added to download queue
@@ -108,6 +113,7 @@ func (r *RequestHandler) setupRequestMessageHandler() {
 	r.pendingSyncRequests = NewRequestHeap(defaultSyncRequestQueueCapacity)
 	r.pendingRangeRequests = NewRequestHeap(defaultRangeRequestQueueCapacity)
 	r.pendingBatchRequests = NewRequestHeap(defaultBatchRequestQueueCapacity)
+	r.pendingHeaderRequests = NewRequestHeap(defaultHeaderRangeRequestQueueCapacity)
 
 	// define message queueing behaviour
 	r.requestMessageHandler = engine.NewMessageHandler(
@@ -143,6 +149,16 @@ func (r *RequestHandler) setupRequestMessageHandler() {
 			},
 			Store: r.pendingBatchRequests,
 		},
+		engine.Pattern{
+			Match: func(msg *engine.Message) bool {
+				_, ok := msg.Payload.(*messages.HeaderRangeRequest)
+				if ok {
+					r.metrics.MessageReceived(metrics.EngineSynchronization, metrics.MessageHeaderRangeRequest)
+				}
+				return ok
+			},
+			Store: r.pendingHeaderRequests,
+		},
 	)
 }
 
@@ -261,6 +277,73 @@ func (r *RequestHandler) onRangeRequest(originID flow.Identifier, req *messages.
 	return nil
 }
 
+// onHeaderRangeRequest processes a request for a range of headers by height.
+// No errors are expected during normal operation.
+func (r *RequestHandler) onHeaderRangeRequest(originID flow.Identifier, req *messages.HeaderRangeRequest) error {
+	logger := r.log.With().Str("origin_id", originID.String()).Logger()
+	logger.Debug().Msg("received new header range request")
+
+	// get the latest final state to know if we can fulfill the request
+	head, err := r.state.Final().Head()
+	if err != nil {
+		return fmt.Errorf("could not get finalized header: %w", err)
+	}
+
+	// if we don't have anything to send, we can bail right away
+	if head.Height < req.FromHeight || req.FromHeight > req.ToHeight {
+		return nil
+	}
+
+	// enforce client-side max request size
+	maxSize := chainsync.DefaultHeaderFirstConfig().HeaderBatchSize
+	maxHeight := req.FromHeight + uint64(maxSize)
+	if maxHeight < req.ToHeight {
+		logger.Warn().
+			Uint64("from", req.FromHeight).
+			Uint64("to", req.ToHeight).
+			Uint64("size", (req.ToHeight-req.FromHeight)+1).
+			Uint("max_size", maxSize).
+			Bool(logging.KeySuspicious, true).
+			Msg("header range request is too large")
+
+		req.ToHeight = maxHeight
+	}
+
+	// get all the headers, one by one
+	headers := make([]flow.Header, 0, req.ToHeight-req.FromHeight+1)
+	for height := req.FromHeight; height <= req.ToHeight; height++ {
+		header, err := r.state.AtHeight(height).Head()
+		if errors.Is(err, state.ErrUnknownSnapshotReference) {
+			logger.Debug().Uint64("height", height).Msg("skipping unknown heights")
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("could not get header for height (%d): %w", height, err)
+		}
+		headers = append(headers, *header)
+	}
+
+	// if there are no headers to send, skip network message
+	if len(headers) == 0 {
+		logger.Debug().Msg("skipping empty header range response")
+		return nil
+	}
+
+	// send the response
+	res := &messages.HeaderResponse{
+		Nonce:   req.Nonce,
+		Headers: headers,
+	}
+	err = r.responseSender.SendResponse(res, originID)
+	if err != nil {
+		logger.Warn().Err(err).Msg("sending header range response failed")
+		return nil
+	}
+	r.metrics.MessageSent(metrics.EngineSynchronization, metrics.MessageHeaderResponse)
+
+	return nil
+}
+
 // onBatchRequest processes a request for a specific block by block ID.
 func (r *RequestHandler) onBatchRequest(originID flow.Identifier, req *messages.BatchRequest) error {
 	logger := r.log.With().Str("origin_id", originID.String()).Logger()
@@ -369,6 +452,15 @@ func (r *RequestHandler) processAvailableRequests() error {
 			continue
 		}
 
+		msg, ok = r.pendingHeaderRequests.Get()
+		if ok {
+			err := r.onHeaderRangeRequest(msg.OriginID, msg.Payload.(*messages.HeaderRangeRequest))
+			if err != nil {
+				return fmt.Errorf("processing header range request failed: %w", err)
+			}
+			continue
+		}
+
 		// when there is no more messages in the queue, back to the loop to wait
 		// for the next incoming message to arrive.
 		return nil
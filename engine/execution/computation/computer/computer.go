@@ -22,6 +22,7 @@ import (
 	"github.com/onflow/flow-go/module/executiondatasync/provider"
 	"github.com/onflow/flow-go/module/mempool/entity"
 	"github.com/onflow/flow-go/module/trace"
+	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/utils/logging"
 )
 
@@ -111,6 +112,11 @@ type blockComputer struct {
 	spockHasher           hash.Hasher
 	receiptHasher         hash.Hasher
 	colResCons            []result.ExecutedCollectionConsumer
+	protocolState         protocol.State
+
+	// transactionResultsHeight is the first block height at which the
+	// transaction results are included in the chunk execution data
+	transactionResultsHeight uint64
 }
 
 func SystemChunkContext(vmCtx fvm.Context, logger zerolog.Logger) fvm.Context {
@@ -127,7 +133,10 @@ func SystemChunkContext(vmCtx fvm.Context, logger zerolog.Logger) fvm.Context {
 	)
 }
 
-// NewBlockComputer creates a new block executor.
+// NewBlockComputer creates a new block executor.  The transaction results are
+// included in the chunk execution data of blocks at or above
+// transactionResultsHeight.  Changing it changes the execution data IDs of
+// these blocks, hence it must be the same for all execution nodes of a chain.
 func NewBlockComputer(
 	vm fvm.VM,
 	vmCtx fvm.Context,
@@ -138,6 +147,8 @@ func NewBlockComputer(
 	signer module.Local,
 	executionDataProvider *provider.Provider,
 	colResCons []result.ExecutedCollectionConsumer,
+	protocolState protocol.State,
+	transactionResultsHeight uint64,
 ) (BlockComputer, error) {
 	systemChunkCtx := SystemChunkContext(vmCtx, logger)
 	vmCtx = fvm.NewContextFromParent(
@@ -157,6 +168,9 @@ func NewBlockComputer(
 		spockHasher:           utils.NewSPOCKHasher(),
 		receiptHasher:         utils.NewExecutionReceiptHasher(),
 		colResCons:            colResCons,
+		protocolState:         protocolState,
+
+		transactionResultsHeight: transactionResultsHeight,
 	}, nil
 }
 
@@ -195,10 +209,14 @@ func (e *blockComputer) queueTransactionRequests(
 ) {
 	txnIndex := uint32(0)
 
+	// the source of randomness of the block is only read if a transaction uses it
+	entropyProvider := e.protocolState.AtBlockID(blockId)
+
 	// TODO(patrick): remove derivedBlockData from context
 	collectionCtx := fvm.NewContextFromParent(
 		e.vmCtx,
 		fvm.WithBlockHeader(blockHeader),
+		fvm.WithEntropyProvider(entropyProvider),
 		fvm.WithDerivedBlockData(derivedBlockData))
 
 	for idx, collection := range rawCollections {
@@ -234,6 +252,7 @@ func (e *blockComputer) queueTransactionRequests(
 	systemCtx := fvm.NewContextFromParent(
 		e.systemChunkCtx,
 		fvm.WithBlockHeader(blockHeader),
+		fvm.WithEntropyProvider(entropyProvider),
 		fvm.WithDerivedBlockData(derivedBlockData))
 	systemCollectionLogger := systemCtx.Logger.With().
 		Str("block_id", blockIdStr).
@@ -318,7 +337,8 @@ func (e *blockComputer) executeBlock(
 		parentBlockExecutionResultID,
 		block,
 		numTxns,
-		e.colResCons)
+		e.colResCons,
+		block.Height() >= e.transactionResultsHeight)
 	defer collector.Stop()
 
 	requestQueue := make(chan transactionRequest, numTxns)
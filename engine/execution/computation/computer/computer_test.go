@@ -1,8 +1,10 @@
 package computer_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 
@@ -47,6 +49,7 @@ import (
 	modulemock "github.com/onflow/flow-go/module/mock"
 	requesterunit "github.com/onflow/flow-go/module/state_synchronization/requester/unittest"
 	"github.com/onflow/flow-go/module/trace"
+	protocolmock "github.com/onflow/flow-go/state/protocol/mock"
 	"github.com/onflow/flow-go/utils/unittest"
 )
 
@@ -165,7 +168,9 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 			committer,
 			me,
 			prov,
-			nil)
+			nil,
+			unittest.ProtocolStateWithSourceFixture(nil),
+			math.MaxUint64)
 		require.NoError(t, err)
 
 		// create a block with 1 collection with 2 transactions
@@ -298,7 +303,9 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 			committer,
 			me,
 			prov,
-			nil)
+			nil,
+			unittest.ProtocolStateWithSourceFixture(nil),
+			math.MaxUint64)
 		require.NoError(t, err)
 
 		// create an empty block
@@ -332,6 +339,135 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 		vm.AssertExpectations(t)
 	})
 
+	t.Run("transaction results are included in execution data from activation height", func(t *testing.T) {
+		// generated blocks are at height 42
+		for activationHeight, included := range map[uint64]bool{41: true, 42: true, 43: false} {
+			execCtx := fvm.NewContext()
+
+			vm := new(fvmmock.VM)
+			committer := new(computermock.ViewCommitter)
+
+			bservice := requesterunit.MockBlobService(blockstore.NewBlockstore(dssync.MutexWrap(datastore.NewMapDatastore())))
+			trackerStorage := mocktracker.NewMockStorage()
+
+			prov := provider.NewProvider(
+				zerolog.Nop(),
+				metrics.NewNoopCollector(),
+				execution_data.DefaultSerializer,
+				bservice,
+				trackerStorage,
+			)
+
+			exe, err := computer.NewBlockComputer(
+				vm,
+				execCtx,
+				metrics.NewNoopCollector(),
+				trace.NewNoopTracer(),
+				zerolog.Nop(),
+				committer,
+				me,
+				prov,
+				nil,
+				unittest.ProtocolStateWithSourceFixture(nil),
+				activationHeight)
+			require.NoError(t, err)
+
+			block := generateBlock(0, 0, rag)
+
+			vm.On("Run", mock.Anything, mock.Anything, mock.Anything).
+				Return(
+					&snapshot.ExecutionSnapshot{},
+					fvm.ProcedureOutput{},
+					nil).
+				Once() // just system chunk
+
+			committer.On("CommitView", mock.Anything, mock.Anything).
+				Return(nil, nil, nil, nil).
+				Once() // just system chunk
+
+			result, err := exe.ExecuteBlock(
+				context.Background(),
+				unittest.IdentifierFixture(),
+				block,
+				nil,
+				derived.NewEmptyDerivedBlockData(0))
+			require.NoError(t, err)
+			require.Len(t, result.ChunkExecutionDatas, 1)
+
+			if included {
+				assert.Equal(t, result.AllTransactionResults(), result.ChunkExecutionDatas[0].TransactionResults)
+			} else {
+				assert.Empty(t, result.ChunkExecutionDatas[0].TransactionResults)
+			}
+		}
+	})
+
+	t.Run("transactions are executed with the source of randomness of the block", func(t *testing.T) {
+		execCtx := fvm.NewContext()
+
+		vm := new(fvmmock.VM)
+		committer := new(computermock.ViewCommitter)
+
+		source := unittest.SignatureFixture()
+		block := generateBlock(0, 0, rag)
+
+		blockSnapshot := new(protocolmock.Snapshot)
+		blockSnapshot.On("RandomSource").Return([]byte(source), nil)
+		protocolState := new(protocolmock.State)
+		protocolState.On("AtBlockID", block.ID()).Return(blockSnapshot).Once()
+
+		bservice := requesterunit.MockBlobService(blockstore.NewBlockstore(dssync.MutexWrap(datastore.NewMapDatastore())))
+		trackerStorage := mocktracker.NewMockStorage()
+
+		prov := provider.NewProvider(
+			zerolog.Nop(),
+			metrics.NewNoopCollector(),
+			execution_data.DefaultSerializer,
+			bservice,
+			trackerStorage,
+		)
+
+		exe, err := computer.NewBlockComputer(
+			vm,
+			execCtx,
+			metrics.NewNoopCollector(),
+			trace.NewNoopTracer(),
+			zerolog.Nop(),
+			committer,
+			me,
+			prov,
+			nil,
+			protocolState,
+			math.MaxUint64)
+		require.NoError(t, err)
+
+		hasBlockSource := mock.MatchedBy(func(ctx fvm.Context) bool {
+			actual, err := ctx.EntropyProvider.RandomSource()
+			return err == nil && bytes.Equal(source, actual)
+		})
+		vm.On("Run", hasBlockSource, mock.Anything, mock.Anything).
+			Return(
+				&snapshot.ExecutionSnapshot{},
+				fvm.ProcedureOutput{},
+				nil).
+			Once() // just system chunk
+
+		committer.On("CommitView", mock.Anything, mock.Anything).
+			Return(nil, nil, nil, nil).
+			Once() // just system chunk
+
+		_, err = exe.ExecuteBlock(
+			context.Background(),
+			unittest.IdentifierFixture(),
+			block,
+			nil,
+			derived.NewEmptyDerivedBlockData(0))
+		require.NoError(t, err)
+
+		vm.AssertExpectations(t)
+		protocolState.AssertExpectations(t)
+	})
+
 	t.Run("system chunk transaction should not fail", func(t *testing.T) {
 
 		// include all fees. System chunk should ignore them
@@ -397,7 +533,9 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 			comm,
 			me,
 			prov,
-			nil)
+			nil,
+			unittest.ProtocolStateWithSourceFixture(nil),
+			math.MaxUint64)
 		require.NoError(t, err)
 
 		// create an empty block
@@ -455,7 +593,9 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 			committer,
 			me,
 			prov,
-			nil)
+			nil,
+			unittest.ProtocolStateWithSourceFixture(nil),
+			math.MaxUint64)
 		require.NoError(t, err)
 
 		collectionCount := 2
@@ -651,6 +791,8 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 				me,
 				prov,
 				nil,
+				unittest.ProtocolStateWithSourceFixture(nil),
+				math.MaxUint64,
 			)
 			require.NoError(t, err)
 
@@ -760,7 +902,9 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 			committer.NewNoopViewCommitter(),
 			me,
 			prov,
-			nil)
+			nil,
+			unittest.ProtocolStateWithSourceFixture(nil),
+			math.MaxUint64)
 		require.NoError(t, err)
 
 		const collectionCount = 2
@@ -864,7 +1008,9 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 			committer.NewNoopViewCommitter(),
 			me,
 			prov,
-			nil)
+			nil,
+			unittest.ProtocolStateWithSourceFixture(nil),
+			math.MaxUint64)
 		require.NoError(t, err)
 
 		block := generateBlock(collectionCount, transactionCount, rag)
@@ -1179,7 +1325,9 @@ func Test_ExecutingSystemCollection(t *testing.T) {
 		committer,
 		me,
 		prov,
-		nil)
+		nil,
+		unittest.ProtocolStateWithSourceFixture(nil),
+		math.MaxUint64)
 	require.NoError(t, err)
 
 	// create empty block, it will have system collection attached while executing
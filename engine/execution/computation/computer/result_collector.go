@@ -68,6 +68,10 @@ type resultCollector struct {
 
 	parentBlockExecutionResultID flow.Identifier
 
+	// includeTransactionResults indicates whether the transaction results are
+	// included in the chunk execution data of the block
+	includeTransactionResults bool
+
 	result    *execution.ComputationResult
 	consumers []result.ExecutedCollectionConsumer
 
@@ -95,6 +99,7 @@ func newResultCollector(
 	block *entity.ExecutableBlock,
 	numTransactions int,
 	consumers []result.ExecutedCollectionConsumer,
+	includeTransactionResults bool,
 ) *resultCollector {
 	numCollections := len(block.Collections()) + 1
 	now := time.Now()
@@ -110,6 +115,7 @@ func newResultCollector(
 		receiptHasher:                receiptHasher,
 		executionDataProvider:        executionDataProvider,
 		parentBlockExecutionResultID: parentBlockExecutionResultID,
+		includeTransactionResults:    includeTransactionResults,
 		result:                       execution.NewEmptyComputationResult(block),
 		consumers:                    consumers,
 		spockSignatures:              make([]crypto.Signature, 0, numCollections),
@@ -159,6 +165,9 @@ func (collector *resultCollector) commitCollection(
 		Events:     events,
 		TrieUpdate: trieUpdate,
 	}
+	if collector.includeTransactionResults {
+		chunkExecData.TransactionResults = execColRes.TransactionResults()
+	}
 
 	collector.result.AppendCollectionAttestationResult(
 		startState,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/ipfs/go-datastore"
@@ -774,7 +775,9 @@ func executeBlockAndVerifyWithParameters(t *testing.T,
 		ledgerCommiter,
 		me,
 		prov,
-		nil)
+		nil,
+		unittest.ProtocolStateWithSourceFixture(nil),
+		math.MaxUint64)
 	require.NoError(t, err)
 
 	executableBlock := unittest.ExecutableBlockFromTransactions(chain.ChainID(), txs)
@@ -820,7 +823,7 @@ func executeBlockAndVerifyWithParameters(t *testing.T,
 
 	er := &computationResult.ExecutionResult
 
-	verifier := chunks.NewChunkVerifier(vm, fvmContext, logger)
+	verifier := chunks.NewChunkVerifier(vm, fvmContext, logger, unittest.ProtocolStateWithSourceFixture(nil))
 
 	vcds := make([]*verification.VerifiableChunkData, er.Chunks.Len())
 
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/onflow/cadence/runtime"
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/engine/execution"
@@ -65,6 +64,11 @@ type ComputationConfig struct {
 	ExtensiveTracing     bool
 	DerivedDataCacheSize uint
 
+	// ExecutionDataTransactionResultsHeight is the first block height at which
+	// the transaction results are included in the chunk execution data.  It
+	// must be the same for all execution nodes of a chain.
+	ExecutionDataTransactionResultsHeight uint64
+
 	// When NewCustomVirtualMachine is nil, the manager will create a standard
 	// fvm virtual machine via fvm.NewVirtualMachine.  Otherwise, the manager
 	// will create a virtual machine using this function.
@@ -110,12 +114,7 @@ func New(
 		fvm.WithReusableCadenceRuntimePool(
 			reusableRuntime.NewReusableCadenceRuntimePool(
 				ReusableCadenceRuntimePoolSize,
-				runtime.Config{
-					TracingEnabled:        params.CadenceTracing,
-					AccountLinkingEnabled: true,
-					// Attachments are enabled everywhere except for Mainnet
-					AttachmentsEnabled: chainID != flow.Mainnet,
-				},
+				fvm.RuntimeConfig(chainID, params.CadenceTracing),
 			),
 		),
 	}
@@ -135,6 +134,8 @@ func New(
 		me,
 		executionDataProvider,
 		nil, // TODO(ramtin): update me with proper consumers
+		protoState,
+		params.ExecutionDataTransactionResultsHeight,
 	)
 
 	if err != nil {
@@ -3,6 +3,7 @@ package computation
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 	"time"
@@ -159,7 +160,9 @@ func BenchmarkComputeBlock(b *testing.B) {
 		committer.NewNoopViewCommitter(),
 		me,
 		prov,
-		nil)
+		nil,
+		unittest.ProtocolStateWithSourceFixture(nil),
+		math.MaxUint64)
 	require.NoError(b, err)
 
 	derivedChainData, err := derived.NewDerivedChainData(
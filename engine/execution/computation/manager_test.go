@@ -141,7 +141,9 @@ func TestComputeBlockWithStorage(t *testing.T) {
 		committer.NewNoopViewCommitter(),
 		me,
 		prov,
-		nil)
+		nil,
+		unittest.ProtocolStateWithSourceFixture(nil),
+		math.MaxUint64)
 	require.NoError(t, err)
 
 	derivedChainData, err := derived.NewDerivedChainData(10)
@@ -771,6 +773,8 @@ func Test_EventEncodingFailsOnlyTxAndCarriesOn(t *testing.T) {
 		me,
 		prov,
 		nil,
+		unittest.ProtocolStateWithSourceFixture(nil),
+		math.MaxUint64,
 	)
 	require.NoError(t, err)
 
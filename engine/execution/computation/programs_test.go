@@ -3,6 +3,7 @@ package computation
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -133,7 +134,9 @@ func TestPrograms_TestContractUpdates(t *testing.T) {
 		committer.NewNoopViewCommitter(),
 		me,
 		prov,
-		nil)
+		nil,
+		unittest.ProtocolStateWithSourceFixture(nil),
+		math.MaxUint64)
 	require.NoError(t, err)
 
 	derivedChainData, err := derived.NewDerivedChainData(10)
@@ -243,7 +246,9 @@ func TestPrograms_TestBlockForks(t *testing.T) {
 		committer.NewNoopViewCommitter(),
 		me,
 		prov,
-		nil)
+		nil,
+		unittest.ProtocolStateWithSourceFixture(nil),
+		math.MaxUint64)
 	require.NoError(t, err)
 
 	derivedChainData, err := derived.NewDerivedChainData(10)
@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -13,6 +15,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/environment"
 	"github.com/onflow/flow-go/fvm/storage/derived"
 	"github.com/onflow/flow-go/fvm/storage/snapshot"
 	"github.com/onflow/flow-go/model/flow"
@@ -24,6 +27,8 @@ const (
 	DefaultLogTimeThreshold    = 1 * time.Second
 	DefaultExecutionTimeLimit  = 10 * time.Second
 	DefaultMaxErrorMessageSize = 1000 // 1000 chars
+
+	DefaultComputationProfileMaxFiles = 100
 )
 
 type Executor interface {
@@ -53,6 +58,16 @@ type QueryConfig struct {
 	LogTimeThreshold    time.Duration
 	ExecutionTimeLimit  time.Duration
 	MaxErrorMessageSize int
+
+	// ComputationProfileDir, when not empty, enables computation profiling
+	// of scripts.  A pprof profile is written to this directory for every
+	// executed script using at least ComputationProfileThreshold computation.
+	ComputationProfileDir       string
+	ComputationProfileThreshold uint64
+	// ComputationProfileMaxFiles is the maximum number of profiles kept in
+	// the profile directory.  Once reached, the oldest profile written by
+	// this executor is removed for every new one.  Zero means no limit.
+	ComputationProfileMaxFiles uint
 }
 
 func NewDefaultConfig() QueryConfig {
@@ -60,6 +75,8 @@ func NewDefaultConfig() QueryConfig {
 		LogTimeThreshold:    DefaultLogTimeThreshold,
 		ExecutionTimeLimit:  DefaultExecutionTimeLimit,
 		MaxErrorMessageSize: DefaultMaxErrorMessageSize,
+
+		ComputationProfileMaxFiles: DefaultComputationProfileMaxFiles,
 	}
 }
 
@@ -72,6 +89,9 @@ type QueryExecutor struct {
 	derivedChainData *derived.DerivedChainData
 	rngLock          *sync.Mutex
 	rng              *rand.Rand
+
+	profilesLock sync.Mutex
+	profiles     []string // paths of the written profiles, oldest first
 }
 
 var _ Executor = &QueryExecutor{}
@@ -156,19 +176,31 @@ func (e *QueryExecutor) ExecuteScript(
 		}
 	}()
 
+	var profiler *environment.ComputationProfiler
+	if e.config.ComputationProfileDir != "" {
+		profiler = environment.NewComputationProfiler()
+	}
+
+	proc := fvm.NewScriptWithContextAndArgs(script, requestCtx, arguments...)
+
 	var output fvm.ProcedureOutput
 	_, output, err = e.vm.Run(
 		fvm.NewContextFromParent(
 			e.vmCtx,
 			fvm.WithBlockHeader(blockHeader),
 			fvm.WithDerivedBlockData(
-				e.derivedChainData.NewDerivedBlockDataForScript(blockHeader.ID()))),
-		fvm.NewScriptWithContextAndArgs(script, requestCtx, arguments...),
+				e.derivedChainData.NewDerivedBlockDataForScript(blockHeader.ID())),
+			fvm.WithComputationProfiler(profiler)),
+		proc,
 		snapshot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute script (internal error): %w", err)
 	}
 
+	if profiler != nil && output.ComputationUsed >= e.config.ComputationProfileThreshold {
+		e.writeComputationProfile(profiler, proc.ID)
+	}
+
 	if output.Err != nil {
 		return nil, fmt.Errorf("failed to execute script at block (%s): %s",
 			blockHeader.ID(),
@@ -191,6 +223,65 @@ func (e *QueryExecutor) ExecuteScript(
 	return encodedValue, nil
 }
 
+// writeComputationProfile writes the script's computation profile to the
+// configured profile directory.  Failures are only logged, since profiling
+// must not affect the script's result.
+func (e *QueryExecutor) writeComputationProfile(
+	profiler *environment.ComputationProfiler,
+	scriptID flow.Identifier,
+) {
+	path := filepath.Join(
+		e.config.ComputationProfileDir,
+		fmt.Sprintf("script-%s-%d.pb.gz", scriptID, time.Now().UnixNano()))
+
+	log := e.logger.With().
+		Hex("script_id", scriptID[:]).
+		Str("profile_path", path).
+		Logger()
+
+	file, err := os.Create(path)
+	if err != nil {
+		log.Warn().Err(err).Msg("could not create computation profile file")
+		return
+	}
+	defer file.Close()
+
+	err = profiler.WriteProfile(file)
+	if err != nil {
+		log.Warn().Err(err).Msg("could not write computation profile")
+		return
+	}
+
+	log.Debug().Msg("computation profile written")
+
+	e.rotateComputationProfiles(path)
+}
+
+// rotateComputationProfiles records the newly written profile and removes the
+// oldest profiles exceeding the configured maximum number of files.
+func (e *QueryExecutor) rotateComputationProfiles(path string) {
+	e.profilesLock.Lock()
+	defer e.profilesLock.Unlock()
+
+	e.profiles = append(e.profiles, path)
+	if e.config.ComputationProfileMaxFiles == 0 {
+		return
+	}
+
+	for uint(len(e.profiles)) > e.config.ComputationProfileMaxFiles {
+		oldest := e.profiles[0]
+		e.profiles = e.profiles[1:]
+
+		err := os.Remove(oldest)
+		if err != nil && !os.IsNotExist(err) {
+			e.logger.Warn().
+				Err(err).
+				Str("profile_path", oldest).
+				Msg("could not remove computation profile")
+		}
+	}
+}
+
 func summarizeLog(log string, limit int) string {
 	if limit > 0 && len(log) > limit {
 		split := int(limit/2) - 1
@@ -0,0 +1,43 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestRotateComputationProfiles tests that only the configured number of most
+// recent computation profiles are kept.
+func TestRotateComputationProfiles(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		config := NewDefaultConfig()
+		config.ComputationProfileDir = dir
+		config.ComputationProfileMaxFiles = 2
+
+		e := &QueryExecutor{
+			config: config,
+			logger: zerolog.Nop(),
+		}
+
+		paths := make([]string, 4)
+		for i := range paths {
+			paths[i] = filepath.Join(dir, fmt.Sprintf("script-%d.pb.gz", i))
+			require.NoError(t, os.WriteFile(paths[i], []byte{}, 0644))
+			e.rotateComputationProfiles(paths[i])
+		}
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, paths[2:], e.profiles)
+		for _, path := range paths[2:] {
+			require.FileExists(t, path)
+		}
+	})
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/onflow/flow-go/engine/execution/ingestion/uploader"
 	"github.com/onflow/flow-go/engine/execution/provider"
 	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/fvm/storage/snapshot"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/module"
@@ -60,6 +61,15 @@ type Engine struct {
 	executionDataPruner    *pruner.Pruner
 	uploader               *uploader.Manager
 	stopControl            *StopControl
+	historicalRegisters    HistoricalRegisters // optional, used for reads of states no longer held by the ledger
+}
+
+// HistoricalRegisters provides the registers at sealed heights.
+type HistoricalRegisters interface {
+	// StorageSnapshot returns a snapshot of the registers at the given height, which must have the given state commitment.
+	// Expected errors during normal operation:
+	//   - storage.ErrHeightNotIndexed if the registers of the height with the given state commitment are not available
+	StorageSnapshot(height uint64, commit flow.StateCommitment) (snapshot.StorageSnapshot, error)
 }
 
 func New(
@@ -116,6 +126,12 @@ func New(
 	return &eng, nil
 }
 
+// WithHistoricalRegisters sets the historical registers used to serve scripts and register reads
+// at blocks whose state is no longer held by the ledger.
+func (e *Engine) WithHistoricalRegisters(registers HistoricalRegisters) {
+	e.historicalRegisters = registers
+}
+
 // Ready returns a channel that will close when the engine has
 // successfully started.
 func (e *Engine) Ready() <-chan struct{} {
@@ -1050,18 +1066,17 @@ func (e *Engine) ExecuteScriptAtBlockID(
 		return nil, fmt.Errorf("failed to get state commitment for block (%s): %w", blockID, err)
 	}
 
-	// return early if state with the given state commitment is not in memory
-	// and already purged. This reduces allocations for scripts targeting old blocks.
-	if !e.execState.HasState(stateCommit) {
-		return nil, fmt.Errorf("failed to execute script at block (%s): state commitment not found (%s). this error usually happens if the reference block for this script is not set to a recent block", blockID.String(), hex.EncodeToString(stateCommit[:]))
-	}
-
 	block, err := e.state.AtBlockID(blockID).Head()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get block (%s): %w", blockID, err)
 	}
 
-	blockSnapshot := e.execState.NewStorageSnapshot(stateCommit)
+	// return early if state with the given state commitment is not available anymore.
+	// This reduces allocations for scripts targeting old blocks.
+	blockSnapshot, err := e.storageSnapshot(block, stateCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute script at block (%s): %w. this error usually happens if the reference block for this script is not set to a recent block", blockID.String(), err)
+	}
 
 	if e.extensiveLogging {
 		args := make([]string, 0)
@@ -1095,7 +1110,15 @@ func (e *Engine) GetRegisterAtBlockID(
 		return nil, fmt.Errorf("failed to get state commitment for block (%s): %w", blockID, err)
 	}
 
-	blockSnapshot := e.execState.NewStorageSnapshot(stateCommit)
+	block, err := e.state.AtBlockID(blockID).Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block (%s): %w", blockID, err)
+	}
+
+	blockSnapshot, err := e.storageSnapshot(block, stateCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the register at block (%s): %w", blockID, err)
+	}
 
 	id := flow.NewRegisterID(string(owner), string(key))
 	data, err := blockSnapshot.Get(id)
@@ -1106,6 +1129,26 @@ func (e *Engine) GetRegisterAtBlockID(
 	return data, nil
 }
 
+// storageSnapshot returns a snapshot of the given state commitment of the given block. States which are
+// no longer held by the ledger are read from the historical registers, if the block is sealed and indexed.
+func (e *Engine) storageSnapshot(block *flow.Header, stateCommit flow.StateCommitment) (snapshot.StorageSnapshot, error) {
+	if e.execState.HasState(stateCommit) {
+		return e.execState.NewStorageSnapshot(stateCommit), nil
+	}
+
+	if e.historicalRegisters != nil {
+		blockSnapshot, err := e.historicalRegisters.StorageSnapshot(block.Height, stateCommit)
+		if err == nil {
+			return blockSnapshot, nil
+		}
+		if !errors.Is(err, storage.ErrHeightNotIndexed) {
+			return nil, fmt.Errorf("failed to read historical registers: %w", err)
+		}
+	}
+
+	return nil, fmt.Errorf("state commitment not found (%s)", hex.EncodeToString(stateCommit[:]))
+}
+
 func (e *Engine) GetAccount(
 	ctx context.Context,
 	addr flow.Address,
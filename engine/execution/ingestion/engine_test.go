@@ -28,6 +28,7 @@ import (
 	stateMock "github.com/onflow/flow-go/engine/execution/state/mock"
 	executionUnittest "github.com/onflow/flow-go/engine/execution/state/unittest"
 	"github.com/onflow/flow-go/engine/testutil/mocklocal"
+	fvmsnapshot "github.com/onflow/flow-go/fvm/storage/snapshot"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/model/flow/order"
@@ -1348,6 +1349,10 @@ func TestExecuteScriptAtBlockID(t *testing.T) {
 			// make sure blockID to state commitment mapping exist
 			ctx.executionState.On("StateCommitmentByBlockID", mock.Anything, blockA.ID()).Return(*blockA.StartState, nil)
 
+			snapshot := new(protocol.Snapshot)
+			snapshot.On("Head").Return(blockA.Block.Header, nil)
+			ctx.state.On("AtBlockID", blockA.Block.ID()).Return(snapshot)
+
 			// but the state commitment does not exist (e.g. purged)
 			ctx.executionState.On("HasState", *blockA.StartState).Return(false)
 
@@ -1362,6 +1367,60 @@ func TestExecuteScriptAtBlockID(t *testing.T) {
 		})
 	})
 
+	t.Run("purged state is read from historical registers", func(t *testing.T) {
+		runWithEngine(t, func(ctx testingContext) {
+			// Meaningless script
+			script := []byte{1, 1, 2, 3, 5, 8, 11}
+			scriptResult := []byte{1}
+
+			blockA := unittest.ExecutableBlockFixture(nil, unittest.StateCommitmentPointerFixture())
+
+			snapshot := new(protocol.Snapshot)
+			snapshot.On("Head").Return(blockA.Block.Header, nil)
+			ctx.state.On("AtBlockID", blockA.Block.ID()).Return(snapshot)
+
+			ctx.executionState.On("StateCommitmentByBlockID", mock.Anything, blockA.ID()).Return(*blockA.StartState, nil)
+			ctx.executionState.On("HasState", *blockA.StartState).Return(false)
+
+			historical := &historicalRegistersStub{
+				height:   blockA.Block.Header.Height,
+				commit:   *blockA.StartState,
+				snapshot: fvmsnapshot.MapStorageSnapshot{},
+			}
+			ctx.engine.WithHistoricalRegisters(historical)
+
+			ctx.computationManager.
+				On("ExecuteScript", mock.Anything, script, [][]byte(nil), blockA.Block.Header, historical.snapshot).
+				Return(scriptResult, nil)
+
+			res, err := ctx.engine.ExecuteScriptAtBlockID(context.Background(), script, nil, blockA.Block.ID())
+			require.NoError(t, err)
+			assert.Equal(t, scriptResult, res)
+
+			// heights which are not indexed are reported as missing state
+			historical.height++
+			_, err = ctx.engine.ExecuteScriptAtBlockID(context.Background(), script, nil, blockA.Block.ID())
+			assert.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), "state commitment not found"))
+
+			ctx.computationManager.AssertExpectations(t)
+			ctx.executionState.AssertExpectations(t)
+		})
+	})
+}
+
+// historicalRegistersStub holds the registers of a single height.
+type historicalRegistersStub struct {
+	height   uint64
+	commit   flow.StateCommitment
+	snapshot fvmsnapshot.StorageSnapshot
+}
+
+func (h *historicalRegistersStub) StorageSnapshot(height uint64, commit flow.StateCommitment) (fvmsnapshot.StorageSnapshot, error) {
+	if height != h.height || commit != h.commit {
+		return nil, storageerr.ErrHeightNotIndexed
+	}
+	return h.snapshot, nil
 }
 
 func TestUnauthorizedNodeDoesNotBroadcastReceipts(t *testing.T) {
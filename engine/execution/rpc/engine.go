@@ -21,7 +21,9 @@ import (
 	"github.com/onflow/flow-go/engine/common/rpc"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/engine/execution/ingestion"
+	"github.com/onflow/flow-go/engine/execution/rpc/proofs"
 	fvmerrors "github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
@@ -54,6 +56,8 @@ func New(
 	exeResults storage.ExecutionResults,
 	txResults storage.TransactionResults,
 	commits storage.Commits,
+	seals storage.Seals,
+	ledger ledger.Ledger,
 	chainID flow.ChainID,
 	signerIndicesDecoder hotstuff.BlockSignerDecoder,
 	apiRatelimits map[string]int, // the api rate limit (max calls per second) for each of the gRPC API e.g. Ping->100, ExecuteScriptAtBlockID->300
@@ -109,6 +113,11 @@ func New(
 	}
 
 	execution.RegisterExecutionAPIServer(eng.server, eng.handler)
+	proofs.RegisterRegisterProofAPIServer(eng.server, &registerProofHandler{
+		ledger:  ledger,
+		seals:   seals,
+		commits: commits,
+	})
 
 	return eng
 }
@@ -0,0 +1,346 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v3.17.1
+// source: proofs.proto
+
+package proofs
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// RegisterID identifies a register by its owner and key.
+type RegisterID struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Owner []byte `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Key   []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (x *RegisterID) Reset() {
+	*x = RegisterID{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proofs_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterID) ProtoMessage() {}
+
+func (x *RegisterID) ProtoReflect() protoreflect.Message {
+	mi := &file_proofs_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterID.ProtoReflect.Descriptor instead.
+func (*RegisterID) Descriptor() ([]byte, []int) {
+	return file_proofs_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RegisterID) GetOwner() []byte {
+	if x != nil {
+		return x.Owner
+	}
+	return nil
+}
+
+func (x *RegisterID) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+// GetRegistersWithProofsRequest requests the values and proofs of a batch of registers.
+type GetRegistersWithProofsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID of a sealed block
+	BlockId []byte `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	// registers to read
+	RegisterIds []*RegisterID `protobuf:"bytes,2,rep,name=register_ids,json=registerIds,proto3" json:"register_ids,omitempty"`
+}
+
+func (x *GetRegistersWithProofsRequest) Reset() {
+	*x = GetRegistersWithProofsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proofs_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRegistersWithProofsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistersWithProofsRequest) ProtoMessage() {}
+
+func (x *GetRegistersWithProofsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proofs_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistersWithProofsRequest.ProtoReflect.Descriptor instead.
+func (*GetRegistersWithProofsRequest) Descriptor() ([]byte, []int) {
+	return file_proofs_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetRegistersWithProofsRequest) GetBlockId() []byte {
+	if x != nil {
+		return x.BlockId
+	}
+	return nil
+}
+
+func (x *GetRegistersWithProofsRequest) GetRegisterIds() []*RegisterID {
+	if x != nil {
+		return x.RegisterIds
+	}
+	return nil
+}
+
+// GetRegistersWithProofsResponse contains the values and the batch proof of the requested registers.
+type GetRegistersWithProofsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID of the requested block
+	BlockId []byte `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	// the sealed state commitment of the block the proof is made against
+	StateCommitment []byte `protobuf:"bytes,2,opt,name=state_commitment,json=stateCommitment,proto3" json:"state_commitment,omitempty"`
+	// register values in the order of the request, empty for registers which do not exist
+	Values [][]byte `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"`
+	// ledger.TrieBatchProof for all requested registers, encoded with ledger.EncodeTrieBatchProof
+	Proof []byte `protobuf:"bytes,4,opt,name=proof,proto3" json:"proof,omitempty"`
+}
+
+func (x *GetRegistersWithProofsResponse) Reset() {
+	*x = GetRegistersWithProofsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proofs_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRegistersWithProofsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistersWithProofsResponse) ProtoMessage() {}
+
+func (x *GetRegistersWithProofsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proofs_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistersWithProofsResponse.ProtoReflect.Descriptor instead.
+func (*GetRegistersWithProofsResponse) Descriptor() ([]byte, []int) {
+	return file_proofs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetRegistersWithProofsResponse) GetBlockId() []byte {
+	if x != nil {
+		return x.BlockId
+	}
+	return nil
+}
+
+func (x *GetRegistersWithProofsResponse) GetStateCommitment() []byte {
+	if x != nil {
+		return x.StateCommitment
+	}
+	return nil
+}
+
+func (x *GetRegistersWithProofsResponse) GetValues() [][]byte {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *GetRegistersWithProofsResponse) GetProof() []byte {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+var File_proofs_proto protoreflect.FileDescriptor
+
+var file_proofs_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15,
+	0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70,
+	0x72, 0x6f, 0x6f, 0x66, 0x73, 0x22, 0x34, 0x0a, 0x0a, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65,
+	0x72, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x80, 0x01, 0x0a, 0x1d,
+	0x47, 0x65, 0x74, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x73, 0x57, 0x69, 0x74, 0x68,
+	0x50, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a,
+	0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x44, 0x0a, 0x0c, 0x72, 0x65, 0x67, 0x69,
+	0x73, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21,
+	0x2e, 0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x70, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x49,
+	0x44, 0x52, 0x0b, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x49, 0x64, 0x73, 0x22, 0x94,
+	0x01, 0x0a, 0x1e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x73, 0x57,
+	0x69, 0x74, 0x68, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x10,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6d,
+	0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x70, 0x72, 0x6f, 0x6f, 0x66, 0x32, 0x9a, 0x01, 0x0a, 0x10, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74,
+	0x65, 0x72, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x41, 0x50, 0x49, 0x12, 0x85, 0x01, 0x0a, 0x16, 0x47,
+	0x65, 0x74, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x73, 0x57, 0x69, 0x74, 0x68, 0x50,
+	0x72, 0x6f, 0x6f, 0x66, 0x73, 0x12, 0x34, 0x2e, 0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x65, 0x78, 0x65,
+	0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x2e, 0x47, 0x65,
+	0x74, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x73, 0x57, 0x69, 0x74, 0x68, 0x50, 0x72,
+	0x6f, 0x6f, 0x66, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x35, 0x2e, 0x66, 0x6c,
+	0x6f, 0x77, 0x2e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f,
+	0x6f, 0x66, 0x73, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x73,
+	0x57, 0x69, 0x74, 0x68, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x42, 0x37, 0x5a, 0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x6f, 0x6e, 0x66, 0x6c, 0x6f, 0x77, 0x2f, 0x66, 0x6c, 0x6f, 0x77, 0x2d, 0x67, 0x6f, 0x2f,
+	0x65, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x2f, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x2f, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proofs_proto_rawDescOnce sync.Once
+	file_proofs_proto_rawDescData = file_proofs_proto_rawDesc
+)
+
+func file_proofs_proto_rawDescGZIP() []byte {
+	file_proofs_proto_rawDescOnce.Do(func() {
+		file_proofs_proto_rawDescData = protoimpl.X.CompressGZIP(file_proofs_proto_rawDescData)
+	})
+	return file_proofs_proto_rawDescData
+}
+
+var file_proofs_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proofs_proto_goTypes = []interface{}{
+	(*RegisterID)(nil),                     // 0: flow.execution.proofs.RegisterID
+	(*GetRegistersWithProofsRequest)(nil),  // 1: flow.execution.proofs.GetRegistersWithProofsRequest
+	(*GetRegistersWithProofsResponse)(nil), // 2: flow.execution.proofs.GetRegistersWithProofsResponse
+}
+var file_proofs_proto_depIdxs = []int32{
+	0, // 0: flow.execution.proofs.GetRegistersWithProofsRequest.register_ids:type_name -> flow.execution.proofs.RegisterID
+	1, // 1: flow.execution.proofs.RegisterProofAPI.GetRegistersWithProofs:input_type -> flow.execution.proofs.GetRegistersWithProofsRequest
+	2, // 2: flow.execution.proofs.RegisterProofAPI.GetRegistersWithProofs:output_type -> flow.execution.proofs.GetRegistersWithProofsResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_proofs_proto_init() }
+func file_proofs_proto_init() {
+	if File_proofs_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_proofs_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegisterID); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proofs_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRegistersWithProofsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proofs_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRegistersWithProofsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proofs_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proofs_proto_goTypes,
+		DependencyIndexes: file_proofs_proto_depIdxs,
+		MessageInfos:      file_proofs_proto_msgTypes,
+	}.Build()
+	File_proofs_proto = out.File
+	file_proofs_proto_rawDesc = nil
+	file_proofs_proto_goTypes = nil
+	file_proofs_proto_depIdxs = nil
+}
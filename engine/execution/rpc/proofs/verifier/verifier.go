@@ -0,0 +1,114 @@
+// Package verifier verifies register values returned by the execution node RegisterProofAPI
+// against a trusted state commitment.
+//
+// A client obtains the state commitment of a sealed block from a trusted source (e.g. the final
+// state of the sealed execution result, as reported by its own access node), requests the
+// registers from any execution node, and verifies the response with Verify before using the values.
+package verifier
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/onflow/flow-go/engine/execution/rpc/proofs"
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/common/proof"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// ErrInvalidProof is returned when a response is not consistent with the trusted state commitment.
+var ErrInvalidProof = errors.New("invalid register proof")
+
+// Verify checks that the given response contains the values of the given registers at the given
+// trusted state commitment and returns the verified values in the order of the registers.
+// Registers which do not exist have an empty value.
+//
+// Expected errors during normal operation:
+//   - ErrInvalidProof if the response does not prove the values of all registers against the commitment
+func Verify(
+	commit flow.StateCommitment,
+	registerIDs []flow.RegisterID,
+	response *proofs.GetRegistersWithProofsResponse,
+) ([]flow.RegisterValue, error) {
+
+	if !bytes.Equal(response.GetStateCommitment(), commit[:]) {
+		return nil, fmt.Errorf("%w: response is for state commitment %x, expected %x",
+			ErrInvalidProof, response.GetStateCommitment(), commit)
+	}
+
+	values := response.GetValues()
+	if len(values) != len(registerIDs) {
+		return nil, fmt.Errorf("%w: got %d values for %d registers", ErrInvalidProof, len(values), len(registerIDs))
+	}
+
+	batchProof, err := ledger.DecodeTrieBatchProof(response.GetProof())
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not decode proof: %v", ErrInvalidProof, err)
+	}
+
+	if !proof.VerifyTrieBatchProof(batchProof, ledger.State(commit)) {
+		return nil, fmt.Errorf("%w: proof does not match state commitment %x", ErrInvalidProof, commit)
+	}
+
+	// proofs are not necessarily in the order of the request, so index them by path
+	provenPayloads := make(map[ledger.Path]*ledger.Payload, len(batchProof.Proofs))
+	for _, p := range batchProof.Proofs {
+		if !p.Inclusion {
+			return nil, fmt.Errorf("%w: unexpected non-inclusion proof for path %x", ErrInvalidProof, p.Path)
+		}
+		provenPayloads[p.Path] = p.Payload
+	}
+
+	verified := make([]flow.RegisterValue, len(registerIDs))
+	for i, id := range registerIDs {
+		key := state.RegisterIDToKey(id)
+		path, err := pathfinder.KeyToPath(key, complete.DefaultPathFinderVersion)
+		if err != nil {
+			return nil, fmt.Errorf("could not compute path of register %s: %w", id, err)
+		}
+
+		payload, ok := provenPayloads[path]
+		if !ok {
+			return nil, fmt.Errorf("%w: no proof for register %s", ErrInvalidProof, id)
+		}
+
+		if !bytes.Equal(payload.Value(), values[i]) {
+			return nil, fmt.Errorf("%w: value of register %s does not match the proof", ErrInvalidProof, id)
+		}
+
+		// an empty payload proves that the register does not exist and carries no key
+		if !payload.IsEmpty() {
+			payloadKey, err := payload.Key()
+			if err != nil {
+				return nil, fmt.Errorf("%w: could not decode key of register %s: %v", ErrInvalidProof, id, err)
+			}
+			if !payloadKey.Equals(&key) {
+				return nil, fmt.Errorf("%w: proof for register %s has a different key", ErrInvalidProof, id)
+			}
+		}
+
+		verified[i] = values[i]
+	}
+
+	return verified, nil
+}
+
+// NewRequest creates a request for the given registers at the given block.
+func NewRequest(blockID flow.Identifier, registerIDs []flow.RegisterID) *proofs.GetRegistersWithProofsRequest {
+	ids := make([]*proofs.RegisterID, len(registerIDs))
+	for i, id := range registerIDs {
+		ids[i] = &proofs.RegisterID{
+			Owner: []byte(id.Owner),
+			Key:   []byte(id.Key),
+		}
+	}
+
+	return &proofs.GetRegistersWithProofsRequest{
+		BlockId:     blockID[:],
+		RegisterIds: ids,
+	}
+}
@@ -0,0 +1,133 @@
+package verifier_test
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/rpc/proofs"
+	"github.com/onflow/flow-go/engine/execution/rpc/proofs/verifier"
+	executionState "github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+// proveRegisters creates a ledger containing the given registers and returns its state
+// together with a response for the requested registers.
+func proveRegisters(
+	t *testing.T,
+	entries flow.RegisterEntries,
+	requested []flow.RegisterID,
+) (flow.StateCommitment, *proofs.GetRegistersWithProofsResponse) {
+	l, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+	require.NoError(t, err)
+
+	compactor := fixtures.NewNoopCompactor(l)
+	<-compactor.Ready()
+	defer func() {
+		<-l.Done()
+		<-compactor.Done()
+	}()
+
+	keys, values := executionState.RegisterEntriesToKeysValues(entries)
+	update, err := ledger.NewUpdate(l.InitialState(), keys, values)
+	require.NoError(t, err)
+	state, _, err := l.Set(update)
+	require.NoError(t, err)
+
+	requestedKeys := make([]ledger.Key, len(requested))
+	for i, id := range requested {
+		requestedKeys[i] = executionState.RegisterIDToKey(id)
+	}
+	query, err := ledger.NewQuery(state, requestedKeys)
+	require.NoError(t, err)
+
+	proof, err := l.Prove(query)
+	require.NoError(t, err)
+	readValues, err := l.Get(query)
+	require.NoError(t, err)
+
+	response := &proofs.GetRegistersWithProofsResponse{
+		StateCommitment: state[:],
+		Proof:           proof,
+	}
+	for _, value := range readValues {
+		response.Values = append(response.Values, value)
+	}
+
+	return flow.StateCommitment(state), response
+}
+
+func TestVerify(t *testing.T) {
+	a := flow.NewRegisterID("a", "key")
+	b := flow.NewRegisterID("b", "key")
+	missing := flow.NewRegisterID("c", "key")
+
+	entries := flow.RegisterEntries{
+		{Key: a, Value: []byte("value a")},
+		{Key: b, Value: []byte("value b")},
+	}
+	requested := []flow.RegisterID{b, missing, a}
+
+	t.Run("valid response", func(t *testing.T) {
+		commit, response := proveRegisters(t, entries, requested)
+
+		values, err := verifier.Verify(commit, requested, response)
+		require.NoError(t, err)
+		require.Len(t, values, 3)
+		require.Equal(t, flow.RegisterValue("value b"), values[0])
+		require.Empty(t, values[1])
+		require.Equal(t, flow.RegisterValue("value a"), values[2])
+	})
+
+	t.Run("tampered value", func(t *testing.T) {
+		commit, response := proveRegisters(t, entries, requested)
+		response.Values[0] = []byte("value c")
+
+		_, err := verifier.Verify(commit, requested, response)
+		require.ErrorIs(t, err, verifier.ErrInvalidProof)
+	})
+
+	t.Run("value for missing register", func(t *testing.T) {
+		commit, response := proveRegisters(t, entries, requested)
+		response.Values[1] = []byte("value c")
+
+		_, err := verifier.Verify(commit, requested, response)
+		require.ErrorIs(t, err, verifier.ErrInvalidProof)
+	})
+
+	t.Run("different state commitment", func(t *testing.T) {
+		_, response := proveRegisters(t, entries, requested)
+		otherCommit, _ := proveRegisters(t, entries[:1], requested)
+
+		_, err := verifier.Verify(otherCommit, requested, response)
+		require.ErrorIs(t, err, verifier.ErrInvalidProof)
+
+		// also when the response claims the trusted commitment
+		response.StateCommitment = otherCommit[:]
+		_, err = verifier.Verify(otherCommit, requested, response)
+		require.ErrorIs(t, err, verifier.ErrInvalidProof)
+	})
+
+	t.Run("proof for different registers", func(t *testing.T) {
+		commit, response := proveRegisters(t, entries, []flow.RegisterID{a, missing, b})
+
+		_, err := verifier.Verify(commit, requested, response)
+		require.ErrorIs(t, err, verifier.ErrInvalidProof)
+
+		_, err = verifier.Verify(commit, requested[:2], response)
+		require.ErrorIs(t, err, verifier.ErrInvalidProof)
+	})
+
+	t.Run("corrupted proof", func(t *testing.T) {
+		commit, response := proveRegisters(t, entries, requested)
+		response.Proof = response.Proof[:len(response.Proof)/2]
+
+		_, err := verifier.Verify(commit, requested, response)
+		require.ErrorIs(t, err, verifier.ErrInvalidProof)
+	})
+}
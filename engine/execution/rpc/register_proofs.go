@@ -0,0 +1,124 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/engine/execution/rpc/proofs"
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// MaxRegistersPerProofRequest is the maximum number of registers which can be requested in a
+// single GetRegistersWithProofs call.
+const MaxRegistersPerProofRequest = 1024
+
+// registerProofHandler implements the RegisterProofAPI.
+type registerProofHandler struct {
+	proofs.UnimplementedRegisterProofAPIServer
+
+	ledger  ledger.Ledger
+	seals   storage.Seals
+	commits storage.Commits
+}
+
+var _ proofs.RegisterProofAPIServer = (*registerProofHandler)(nil)
+
+// GetRegistersWithProofs returns the values of the requested registers at the sealed state commitment
+// of the requested block, together with a batch proof for all values against that commitment.
+func (h *registerProofHandler) GetRegistersWithProofs(
+	_ context.Context,
+	req *proofs.GetRegistersWithProofsRequest,
+) (*proofs.GetRegistersWithProofsResponse, error) {
+
+	blockID, err := convert.BlockID(req.GetBlockId())
+	if err != nil {
+		return nil, err
+	}
+
+	registerIDs := req.GetRegisterIds()
+	if len(registerIDs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no registers requested")
+	}
+	if len(registerIDs) > MaxRegistersPerProofRequest {
+		return nil, status.Errorf(codes.InvalidArgument, "too many registers requested: %d > %d",
+			len(registerIDs), MaxRegistersPerProofRequest)
+	}
+
+	commit, err := h.sealedStateCommitment(blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]ledger.Key, len(registerIDs))
+	for i, id := range registerIDs {
+		keys[i] = state.RegisterIDToKey(flow.NewRegisterID(string(id.GetOwner()), string(id.GetKey())))
+	}
+
+	query, err := ledger.NewQuery(ledger.State(commit), keys)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create ledger query: %v", err)
+	}
+
+	values, err := h.ledger.Get(query)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read registers: %v", err)
+	}
+
+	proof, err := h.ledger.Prove(query)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to prove registers: %v", err)
+	}
+
+	encodedValues := make([][]byte, len(values))
+	for i, value := range values {
+		encodedValues[i] = value
+	}
+
+	return &proofs.GetRegistersWithProofsResponse{
+		BlockId:         blockID[:],
+		StateCommitment: commit[:],
+		Values:          encodedValues,
+		Proof:           proof,
+	}, nil
+}
+
+// sealedStateCommitment returns the state commitment of the given block as sealed by consensus.
+// An error is returned if the block is not sealed, if this node has not executed the block, if the
+// state is no longer held in memory, or if this node's state commitment differs from the sealed one.
+func (h *registerProofHandler) sealedStateCommitment(blockID flow.Identifier) (flow.StateCommitment, error) {
+	seal, err := h.seals.FinalizedSealForBlock(blockID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return flow.DummyStateCommitment, status.Errorf(codes.FailedPrecondition, "block %s is not sealed", blockID)
+		}
+		return flow.DummyStateCommitment, status.Errorf(codes.Internal, "failed to get seal for block %s: %v", blockID, err)
+	}
+
+	commit, err := h.commits.ByBlockID(blockID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return flow.DummyStateCommitment, status.Errorf(codes.NotFound, "block %s has not been executed by this node", blockID)
+		}
+		return flow.DummyStateCommitment, status.Errorf(codes.Internal, "failed to get state commitment for block %s: %v", blockID, err)
+	}
+
+	if commit != seal.FinalState {
+		return flow.DummyStateCommitment, status.Errorf(codes.Internal,
+			"state commitment %x of block %s differs from sealed state commitment %x",
+			commit, blockID, seal.FinalState)
+	}
+
+	if !h.ledger.HasState(ledger.State(commit)) {
+		return flow.DummyStateCommitment, status.Errorf(codes.NotFound,
+			"state of block %s is not available anymore, please request a more recent block", blockID)
+	}
+
+	return commit, nil
+}
@@ -0,0 +1,94 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/engine/execution/rpc/proofs/verifier"
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	realstorage "github.com/onflow/flow-go/storage"
+	storage "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestGetRegistersWithProofs(t *testing.T) {
+	l, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+	require.NoError(t, err)
+	compactor := fixtures.NewNoopCompactor(l)
+	<-compactor.Ready()
+	defer func() {
+		<-l.Done()
+		<-compactor.Done()
+	}()
+
+	owner := unittest.RandomAddressFixture()
+	registerA := flow.NewRegisterID(string(owner.Bytes()), "a")
+	registerB := flow.NewRegisterID(string(owner.Bytes()), "b")
+	keys, values := state.RegisterEntriesToKeysValues(flow.RegisterEntries{
+		{Key: registerA, Value: []byte("value a")},
+	})
+	update, err := ledger.NewUpdate(l.InitialState(), keys, values)
+	require.NoError(t, err)
+	newState, _, err := l.Set(update)
+	require.NoError(t, err)
+	commit := flow.StateCommitment(newState)
+
+	sealedBlockID := unittest.IdentifierFixture()
+	unsealedBlockID := unittest.IdentifierFixture()
+	forkedBlockID := unittest.IdentifierFixture()
+
+	seals := new(storage.Seals)
+	seals.On("FinalizedSealForBlock", sealedBlockID).Return(&flow.Seal{BlockID: sealedBlockID, FinalState: commit}, nil)
+	seals.On("FinalizedSealForBlock", forkedBlockID).Return(&flow.Seal{BlockID: forkedBlockID, FinalState: commit}, nil)
+	seals.On("FinalizedSealForBlock", unsealedBlockID).Return(nil, realstorage.ErrNotFound)
+
+	commits := new(storage.Commits)
+	commits.On("ByBlockID", sealedBlockID).Return(commit, nil)
+	commits.On("ByBlockID", forkedBlockID).Return(unittest.StateCommitmentFixture(), nil)
+
+	handler := &registerProofHandler{
+		ledger:  l,
+		seals:   seals,
+		commits: commits,
+	}
+
+	t.Run("returns verifiable values", func(t *testing.T) {
+		registerIDs := []flow.RegisterID{registerA, registerB}
+		response, err := handler.GetRegistersWithProofs(context.Background(), verifier.NewRequest(sealedBlockID, registerIDs))
+		require.NoError(t, err)
+
+		verified, err := verifier.Verify(commit, registerIDs, response)
+		require.NoError(t, err)
+		require.Equal(t, flow.RegisterValue("value a"), verified[0])
+		require.Empty(t, verified[1])
+	})
+
+	t.Run("unsealed block", func(t *testing.T) {
+		_, err := handler.GetRegistersWithProofs(context.Background(), verifier.NewRequest(unsealedBlockID, []flow.RegisterID{registerA}))
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("local state differs from sealed state", func(t *testing.T) {
+		_, err := handler.GetRegistersWithProofs(context.Background(), verifier.NewRequest(forkedBlockID, []flow.RegisterID{registerA}))
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := handler.GetRegistersWithProofs(context.Background(), verifier.NewRequest(sealedBlockID, nil))
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		tooMany := make([]flow.RegisterID, MaxRegistersPerProofRequest+1)
+		_, err = handler.GetRegistersWithProofs(context.Background(), verifier.NewRequest(sealedBlockID, tooMany))
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,171 @@
+package history
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// BootstrapFromTries seeds the store with the state at the given height, using the trie with
+// the given state commitment from the given tries (e.g. the tries held by the ledger).
+// No error returns are expected during normal operation.
+func BootstrapFromTries(registers *Registers, height uint64, commit flow.StateCommitment, tries []*trie.MTrie) error {
+	t, ok := findTrie(tries, commit)
+	if !ok {
+		return fmt.Errorf("no trie with state commitment %x", commit)
+	}
+	return registers.Bootstrap(height, commit, TrieRegisters(t))
+}
+
+// findTrie returns the trie with the given state commitment from the given tries.
+func findTrie(tries []*trie.MTrie, commit flow.StateCommitment) (*trie.MTrie, bool) {
+	for _, t := range tries {
+		if t.RootHash() == ledger.RootHash(commit) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// TrieRegisters returns the registers of the given trie in batches, visiting the leaves one by
+// one instead of collecting all registers first.
+func TrieRegisters(t *trie.MTrie) RegisterBatches {
+	it := flattener.NewNodeIterator(t.RootNode())
+	return func() (flow.RegisterEntries, error) {
+		var entries flow.RegisterEntries
+		for len(entries) < bootstrapBatchSize && it.Next() {
+			n := it.Value()
+			if !n.IsLeaf() {
+				continue
+			}
+			payload, err := n.Payload()
+			if err != nil {
+				return nil, fmt.Errorf("could not read payload of trie leaf: %w", err)
+			}
+			if payload == nil || payload.IsEmpty() {
+				continue
+			}
+			id, err := payloadRegisterID(payload)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, flow.RegisterEntry{Key: id, Value: payload.Value()})
+		}
+		return entries, nil
+	}
+}
+
+// trieDiff returns the registers whose values differ between the given tries, with their values in
+// the trie to. Registers which are not in the trie to have an empty value.
+// Only the subtries whose hashes differ are visited, so the cost grows with the number of changes.
+// No error returns are expected during normal operation.
+func trieDiff(from *trie.MTrie, to *trie.MTrie) (flow.RegisterEntries, error) {
+	var entries flow.RegisterEntries
+	var diff func(a *node.Node, b *node.Node) error
+	diff = func(a *node.Node, b *node.Node) error {
+		if a == nil && b == nil || a != nil && b != nil && a.Hash() == b.Hash() {
+			return nil
+		}
+		if a != nil && b != nil && !a.IsLeaf() && !b.IsLeaf() {
+			err := diff(a.LeftChild(), b.LeftChild())
+			if err != nil {
+				return err
+			}
+			return diff(a.RightChild(), b.RightChild())
+		}
+
+		// one of the subtries is a single leaf or empty, so the subtries are small enough to compare all their registers
+		before, err := subtrieRegisters(a)
+		if err != nil {
+			return err
+		}
+		after, err := subtrieRegisters(b)
+		if err != nil {
+			return err
+		}
+		for id, value := range after {
+			if old, ok := before[id]; !ok || !bytes.Equal(old, value) {
+				entries = append(entries, flow.RegisterEntry{Key: id, Value: value})
+			}
+		}
+		for id := range before {
+			if _, ok := after[id]; !ok {
+				entries = append(entries, flow.RegisterEntry{Key: id, Value: nil})
+			}
+		}
+		return nil
+	}
+
+	err := diff(from.RootNode(), to.RootNode())
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// subtrieRegisters returns the registers of the subtrie with the given root node, which can be nil.
+func subtrieRegisters(n *node.Node) (map[flow.RegisterID]flow.RegisterValue, error) {
+	registers := make(map[flow.RegisterID]flow.RegisterValue)
+	if n == nil {
+		return registers, nil
+	}
+	payloads, err := n.AllPayloads()
+	if err != nil {
+		return nil, fmt.Errorf("could not read payloads of subtrie: %w", err)
+	}
+	for i := range payloads {
+		if payloads[i].IsEmpty() {
+			continue
+		}
+		id, err := payloadRegisterID(&payloads[i])
+		if err != nil {
+			return nil, err
+		}
+		registers[id] = payloads[i].Value()
+	}
+	return registers, nil
+}
+
+// BootstrapFromCheckpoint seeds the store with the state at the given height, using the trie with
+// the given state commitment from the checkpoint file at the given path.
+// No error returns are expected during normal operation.
+func BootstrapFromCheckpoint(
+	log zerolog.Logger,
+	registers *Registers,
+	height uint64,
+	commit flow.StateCommitment,
+	checkpointPath string,
+) error {
+	log.Info().Str("checkpoint", checkpointPath).Uint64("height", height).Msg("loading checkpoint to bootstrap historical registers")
+
+	tries, err := wal.LoadCheckpoint(checkpointPath, &log)
+	if err != nil {
+		return fmt.Errorf("could not load checkpoint %s: %w", checkpointPath, err)
+	}
+
+	err = BootstrapFromTries(registers, height, commit, tries)
+	if err != nil {
+		return fmt.Errorf("could not bootstrap from checkpoint %s: %w", checkpointPath, err)
+	}
+
+	log.Info().Uint64("height", height).Hex("commit", commit[:]).Msg("bootstrapped historical registers")
+	return nil
+}
+
+// payloadRegisterID returns the ID of the register stored in the given payload.
+func payloadRegisterID(payload *ledger.Payload) (flow.RegisterID, error) {
+	key, err := payload.Key()
+	if err != nil {
+		return flow.RegisterID{}, fmt.Errorf("could not decode payload key: %w", err)
+	}
+	return state.KeyToRegisterID(key)
+}
@@ -0,0 +1,417 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/atomic"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/state/protocol/events"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation"
+)
+
+// maxUpdatesPerBlock bounds the number of trie updates of a single block, to detect
+// inconsistent pending updates instead of following them forever.
+const maxUpdatesPerBlock = 10_000
+
+// errMissingUpdates is returned when the trie updates leading to the state of a block are not available.
+var errMissingUpdates = errors.New("missing trie updates")
+
+// Indexer stores the register updates of every sealed height in the Registers store.
+//
+// It receives all trie updates applied to the ledger from the Compactor and keeps them on disk
+// until the block which created them is sealed. Trie updates of blocks which are never sealed
+// are removed once a later height is indexed.
+// Since trie updates are only received while the node is running, the store must be bootstrapped
+// with the state of the latest indexed height before the ledger applies any further updates.
+// If the trie updates of a height are missing nevertheless (e.g. the node stopped after updating
+// the ledger, but before storing the trie update), the register updates of the height are derived
+// from the tries of the height and of its parent held by the ledger. If the ledger doesn't hold
+// them anymore, the store is bootstrapped again from the state of a later sealed height held by
+// the ledger, and the heights in between are not indexed.
+type Indexer struct {
+	component.Component
+	events.Noop
+
+	log       zerolog.Logger
+	registers *Registers
+	state     protocol.State
+	headers   storage.Headers
+	commits   storage.Commits
+	notifier  engine.Notifier
+	tries     func() ([]*trie.MTrie, error)
+
+	// nextSeq is the sequence number of the next pending trie update,
+	// only incremented from the Compactor goroutine
+	nextSeq *atomic.Uint64
+}
+
+var _ complete.TrieUpdateConsumer = (*Indexer)(nil)
+var _ protocol.Consumer = (*Indexer)(nil)
+
+// NewIndexer creates a new indexer storing the sealed heights into the given registers store.
+// The tries function must return the tries held by the ledger, they are used to recover heights
+// whose trie updates are missing.
+// No error returns are expected during normal operation.
+func NewIndexer(
+	log zerolog.Logger,
+	registers *Registers,
+	state protocol.State,
+	headers storage.Headers,
+	commits storage.Commits,
+	tries func() ([]*trie.MTrie, error),
+) (*Indexer, error) {
+	nextSeq, err := registers.nextPendingSeq()
+	if err != nil {
+		return nil, err
+	}
+
+	i := &Indexer{
+		log:       log.With().Str("component", "historical_registers_indexer").Logger(),
+		registers: registers,
+		state:     state,
+		headers:   headers,
+		commits:   commits,
+		notifier:  engine.NewNotifier(),
+		tries:     tries,
+		nextSeq:   atomic.NewUint64(nextSeq),
+	}
+
+	i.Component = component.NewComponentManagerBuilder().
+		AddWorker(i.indexLoop).
+		Build()
+
+	return i, nil
+}
+
+// OnTrieUpdate stores the given trie update until the block which created it is sealed.
+func (i *Indexer) OnTrieUpdate(update *ledger.TrieUpdate, newState ledger.State) {
+	err := i.registers.storePending(i.nextSeq.Load(), update, newState)
+	if err != nil {
+		// the missing update is detected when indexing the block containing it
+		i.log.Error().Err(err).Hex("state", newState[:]).Msg("could not store trie update")
+		return
+	}
+	i.nextSeq.Inc()
+}
+
+// BlockFinalized notifies the indexer that the sealed height might have changed.
+func (i *Indexer) BlockFinalized(*flow.Header) {
+	i.notifier.Notify()
+}
+
+func (i *Indexer) indexLoop(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+	ready()
+
+	// index heights sealed while the node was offline
+	i.notifier.Notify()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-i.notifier.Channel():
+			err := i.indexSealedHeights(ctx)
+			if err != nil {
+				ctx.Throw(err)
+			}
+		}
+	}
+}
+
+// indexSealedHeights indexes all sealed heights which have been executed by this node.
+// No error returns are expected during normal operation.
+func (i *Indexer) indexSealedHeights(ctx irrecoverable.SignalerContext) error {
+	if !i.registers.IsBootstrapped() {
+		return nil
+	}
+
+	sealed, err := i.state.Sealed().Head()
+	if err != nil {
+		return fmt.Errorf("could not get sealed block: %w", err)
+	}
+
+	for height := i.registers.LatestHeight() + 1; height <= sealed.Height; height++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		header, err := i.headers.ByHeight(height)
+		if err != nil {
+			return fmt.Errorf("could not get header at height %d: %w", height, err)
+		}
+		commit, err := i.commits.ByBlockID(header.ID())
+		if errors.Is(err, storage.ErrNotFound) {
+			// not executed yet, the remaining heights are indexed on a later notification
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not get state commitment of block %v: %w", header.ID(), err)
+		}
+
+		err = i.indexHeight(height, commit)
+		if errors.Is(err, errMissingUpdates) {
+			// this happens if the node was stopped between updating the ledger and storing the trie update
+			recovered, err := i.recoverHeight(height, commit, sealed.Height, err)
+			if err != nil {
+				return err
+			}
+			if !recovered {
+				return nil
+			}
+			// the store is indexed up to this height or a later sealed height
+			height = i.registers.LatestHeight()
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not index height %d: %w", height, err)
+		}
+	}
+
+	return nil
+}
+
+// recoverHeight indexes the given height, whose trie updates are missing because of the given error,
+// using the tries held by the ledger. If the ledger holds the tries of the height and of its parent,
+// the register updates of the height are derived from them. Otherwise, the store is bootstrapped again
+// from the state of the highest sealed height up to sealedHeight held by the ledger, and the heights in
+// between are not indexed. The heights indexed before stay readable in both cases.
+// It returns false if the ledger holds none of these states, the height is recovered once the ledger
+// holds the state of a later sealed height.
+// No error returns are expected during normal operation.
+func (i *Indexer) recoverHeight(height uint64, commit flow.StateCommitment, sealedHeight uint64, cause error) (bool, error) {
+	tries, err := i.tries()
+	if err != nil {
+		return false, fmt.Errorf("could not get ledger tries: %w", err)
+	}
+
+	parentCommit, err := i.registers.Commit(height - 1)
+	if err != nil {
+		return false, fmt.Errorf("could not get state commitment of previous height: %w", err)
+	}
+	parentTrie, parentOk := findTrie(tries, parentCommit)
+	heightTrie, ok := findTrie(tries, commit)
+	if parentOk && ok {
+		entries, err := trieDiff(parentTrie, heightTrie)
+		if err != nil {
+			return false, fmt.Errorf("could not derive register updates of height %d from ledger: %w", height, err)
+		}
+		err = i.registers.Store(height, commit, entries)
+		if err != nil {
+			return false, err
+		}
+		i.log.Info().Err(cause).
+			Uint64("height", height).
+			Int("registers", len(entries)).
+			Msg("trie updates of height are missing, indexed height from ledger tries")
+		return true, nil
+	}
+
+	for h := sealedHeight; h >= height; h-- {
+		header, err := i.headers.ByHeight(h)
+		if err != nil {
+			return false, fmt.Errorf("could not get header at height %d: %w", h, err)
+		}
+		c, err := i.commits.ByBlockID(header.ID())
+		if errors.Is(err, storage.ErrNotFound) {
+			// not executed yet
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("could not get state commitment of block %v: %w", header.ID(), err)
+		}
+		t, ok := findTrie(tries, c)
+		if !ok {
+			continue
+		}
+
+		i.log.Warn().Err(cause).
+			Uint64("height", height).
+			Uint64("bootstrap_height", h).
+			Msg("trie updates of height are missing and the ledger does not hold its state anymore, bootstrapping historical registers again")
+
+		err = i.registers.Rebootstrap(h, c, TrieRegisters(t))
+		if err != nil {
+			return false, fmt.Errorf("could not bootstrap historical registers again at height %d: %w", h, err)
+		}
+
+		i.log.Info().
+			Uint64("first_not_indexed_height", height).
+			Uint64("height", h).
+			Msg("historical registers bootstrapped again, heights in between are not indexed")
+		return true, nil
+	}
+
+	i.log.Error().Err(cause).
+		Uint64("height", height).
+		Msg("trie updates of height are missing and the ledger does not hold the state of any sealed height above it, waiting for a later sealed height")
+	return false, nil
+}
+
+// indexHeight stores the register updates of the block at the given height.
+// Expected errors during normal operation:
+//   - errMissingUpdates if the trie updates leading to the state of the block are not stored
+func (i *Indexer) indexHeight(height uint64, commit flow.StateCommitment) error {
+	parentCommit, err := i.registers.Commit(height - 1)
+	if err != nil {
+		return fmt.Errorf("could not get state commitment of previous height: %w", err)
+	}
+
+	// follow the trie updates back from the state of the block to the state of its parent
+	var updates []*pendingUpdate
+	for state := ledger.State(commit); state != ledger.State(parentCommit); {
+		if len(updates) >= maxUpdatesPerBlock {
+			return fmt.Errorf("%w: no path from state %x to state %x", errMissingUpdates, commit, parentCommit)
+		}
+		update, err := i.registers.pending(state)
+		if errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("%w: no trie update resulting in state %x", errMissingUpdates, state)
+		}
+		if err != nil {
+			return fmt.Errorf("could not read trie update resulting in state %x: %w", state, err)
+		}
+		updates = append(updates, update)
+		state = ledger.State(update.update.RootHash)
+	}
+
+	// apply the updates in the order they were applied to the ledger
+	registers := make(map[flow.RegisterID]flow.RegisterValue)
+	for u := len(updates) - 1; u >= 0; u-- {
+		for _, payload := range updates[u].update.Payloads {
+			id, err := payloadRegisterID(payload)
+			if err != nil {
+				return err
+			}
+			registers[id] = payload.Value()
+		}
+	}
+	entries := make(flow.RegisterEntries, 0, len(registers))
+	for id, value := range registers {
+		entries = append(entries, flow.RegisterEntry{Key: id, Value: value})
+	}
+
+	err = i.registers.Store(height, commit, entries)
+	if err != nil {
+		return err
+	}
+
+	// the trie updates of the indexed blocks and of their competing forks were received before the
+	// first update of this block, as the block could only be executed after its parent was executed.
+	// Forks of this block are removed when a later height is indexed.
+	if len(updates) > 0 {
+		minSeq := updates[0].seq
+		for _, update := range updates {
+			if update.seq < minSeq {
+				minSeq = update.seq
+			}
+		}
+		err = i.registers.prunePending(minSeq, updates)
+		if err != nil {
+			return fmt.Errorf("could not prune trie updates: %w", err)
+		}
+	}
+
+	i.log.Debug().Uint64("height", height).Int("registers", len(entries)).Msg("indexed height")
+	return nil
+}
+
+// pendingUpdate is a trie update which has not been indexed yet.
+type pendingUpdate struct {
+	seq      uint64
+	newState ledger.State
+	update   *ledger.TrieUpdate
+}
+
+// storePending stores the trie update resulting in the given state.
+// No error returns are expected during normal operation.
+func (r *Registers) storePending(seq uint64, update *ledger.TrieUpdate, newState ledger.State) error {
+	return r.db.WithReaderBatchWriter(storage.OnlyWriter(
+		operation.InsertPendingTrieUpdate(seq, flow.StateCommitment(newState), ledger.EncodeTrieUpdate(update))))
+}
+
+// pending returns the trie update resulting in the given state.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if there is no such trie update
+func (r *Registers) pending(state ledger.State) (*pendingUpdate, error) {
+	pending := pendingUpdate{newState: state}
+	var encoded []byte
+	err := operation.RetrievePendingTrieUpdate(flow.StateCommitment(state), &pending.seq, &encoded)(r.db.Reader())
+	if err != nil {
+		return nil, err
+	}
+	pending.update, err = ledger.DecodeTrieUpdate(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode trie update: %w", err)
+	}
+	return &pending, nil
+}
+
+// prunePending removes the given trie updates and all trie updates with a sequence number
+// lower than the given one.
+// No error returns are expected during normal operation.
+func (r *Registers) prunePending(belowSeq uint64, updates []*pendingUpdate) error {
+	type seqKey struct {
+		seq   uint64
+		state ledger.State
+	}
+
+	var pruned []seqKey
+	for _, update := range updates {
+		pruned = append(pruned, seqKey{seq: update.seq, state: update.newState})
+	}
+	err := operation.TraversePendingTrieSeqs(func(seq uint64, state flow.StateCommitment) bool {
+		if seq >= belowSeq {
+			return false
+		}
+		pruned = append(pruned, seqKey{seq: seq, state: ledger.State(state)})
+		return true
+	})(r.db.Reader())
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithReaderBatchWriter(func(rw storage.ReaderBatchWriter) error {
+		for _, p := range pruned {
+			// the same state can be reached again, e.g. when a block is executed again after a restart,
+			// in which case the stored update belongs to the later sequence number and must be kept
+			update, err := r.pending(p.state)
+			if err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return err
+			}
+			if err == nil && update.seq == p.seq {
+				err = operation.RemovePendingTrieUpdate(flow.StateCommitment(p.state))(rw.Writer())
+				if err != nil {
+					return err
+				}
+			}
+			err = operation.RemovePendingTrieSeq(p.seq, flow.StateCommitment(p.state))(rw.Writer())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// nextPendingSeq returns the sequence number following the highest stored one.
+// No error returns are expected during normal operation.
+func (r *Registers) nextPendingSeq() (uint64, error) {
+	var next uint64
+	err := operation.TraversePendingTrieSeqs(func(seq uint64, _ flow.StateCommitment) bool {
+		next = seq + 1
+		return true
+	})(r.db.Reader())
+	return next, err
+}
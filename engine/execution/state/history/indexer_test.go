@@ -0,0 +1,349 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	protocol "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/storage"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/storage/operation"
+	"github.com/onflow/flow-go/storage/operation/dbtest"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// indexerSuite provides a chain of headers, with the state commitments of the executed blocks.
+type indexerSuite struct {
+	registers *Registers
+	indexer   *Indexer
+	sealed    *flow.Header
+	headers   map[uint64]*flow.Header
+	commits   map[flow.Identifier]flow.StateCommitment
+	tries     func() ([]*trie.MTrie, error) // the tries held by the ledger
+}
+
+func newIndexerSuite(t *testing.T, db storage.DB) *indexerSuite {
+	s := &indexerSuite{
+		headers: make(map[uint64]*flow.Header),
+		commits: make(map[flow.Identifier]flow.StateCommitment),
+	}
+
+	var err error
+	s.registers, err = NewRegisters(db)
+	require.NoError(t, err)
+
+	headers := storagemock.NewHeaders(t)
+	headers.On("ByHeight", mock.Anything).Return(
+		func(height uint64) *flow.Header { return s.headers[height] },
+		func(height uint64) error { return nil },
+	).Maybe()
+
+	commits := storagemock.NewCommits(t)
+	commits.On("ByBlockID", mock.Anything).Return(
+		func(blockID flow.Identifier) flow.StateCommitment { return s.commits[blockID] },
+		func(blockID flow.Identifier) error {
+			if _, ok := s.commits[blockID]; !ok {
+				return storage.ErrNotFound
+			}
+			return nil
+		},
+	).Maybe()
+
+	snapshot := protocol.NewSnapshot(t)
+	snapshot.On("Head").Return(
+		func() *flow.Header { return s.sealed },
+		func() error { return nil },
+	).Maybe()
+	state := protocol.NewState(t)
+	state.On("Sealed").Return(snapshot).Maybe()
+
+	s.tries = func() ([]*trie.MTrie, error) {
+		return nil, nil
+	}
+	tries := func() ([]*trie.MTrie, error) {
+		return s.tries()
+	}
+	s.indexer, err = NewIndexer(unittest.Logger(), s.registers, state, headers, commits, tries)
+	require.NoError(t, err)
+
+	return s
+}
+
+// addBlock adds a block at the given height with the given state commitment,
+// and marks it as sealed.
+func (s *indexerSuite) addBlock(height uint64, commit flow.StateCommitment) {
+	header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(height))
+	s.headers[height] = header
+	s.commits[header.ID()] = commit
+	s.sealed = header
+}
+
+// update sends the trie update from the given state to the given state to the indexer.
+func (s *indexerSuite) update(from flow.StateCommitment, to flow.StateCommitment, entries ...flow.RegisterEntry) {
+	update := &ledger.TrieUpdate{RootHash: ledger.RootHash(from)}
+	for i, entry := range entries {
+		payload := registerPayload(entry.Key, entry.Value)
+		update.Paths = append(update.Paths, testutils.PathByUint8(uint8(i)))
+		update.Payloads = append(update.Payloads, &payload)
+	}
+	s.indexer.OnTrieUpdate(update, ledger.State(to))
+}
+
+func (s *indexerSuite) pendingCount(t *testing.T) int {
+	count := 0
+	err := operation.TraversePendingTrieSeqs(func(uint64, flow.StateCommitment) bool {
+		count++
+		return true
+	})(s.registers.db.Reader())
+	require.NoError(t, err)
+	return count
+}
+
+func TestIndexer_IndexSealedHeights(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		s := newIndexerSuite(t, db)
+		ctx := irrecoverable.NewMockSignalerContext(t, context.Background())
+
+		a := flow.NewRegisterID("owner", "a")
+		b := flow.NewRegisterID("owner", "b")
+
+		root := unittest.StateCommitmentFixture()
+		s.addBlock(10, root)
+		require.NoError(t, s.registers.Bootstrap(10, root, registerSlice(flow.RegisterEntry{Key: a, Value: []byte{1}})))
+
+		// block 11 is executed in two chunks, and a competing fork of it is executed as well
+		chunk := unittest.StateCommitmentFixture()
+		commit11 := unittest.StateCommitmentFixture()
+		fork := unittest.StateCommitmentFixture()
+		s.update(root, chunk, flow.RegisterEntry{Key: a, Value: []byte{2}})
+		s.update(root, fork, flow.RegisterEntry{Key: b, Value: []byte{7}})
+		s.update(chunk, commit11,
+			flow.RegisterEntry{Key: a, Value: []byte{3}},
+			flow.RegisterEntry{Key: b, Value: []byte{4}},
+		)
+
+		// block 12 does not update any registers
+		s.addBlock(11, commit11)
+		s.addBlock(12, commit11)
+
+		// block 13 is not executed yet
+		s.addBlock(13, unittest.StateCommitmentFixture())
+		delete(s.commits, s.headers[13].ID())
+
+		require.NoError(t, s.indexer.indexSealedHeights(ctx))
+		assert.Equal(t, uint64(12), s.registers.LatestHeight())
+
+		value, err := s.registers.Get(a, 11)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{3}, value)
+		value, err = s.registers.Get(b, 12)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{4}, value)
+		value, err = s.registers.Get(a, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1}, value)
+
+		// the updates of block 11 are removed, its competing fork is removed once a later height is indexed
+		assert.Equal(t, 1, s.pendingCount(t))
+
+		// pending updates are kept across restarts
+		commit13 := unittest.StateCommitmentFixture()
+		s.update(commit11, commit13, flow.RegisterEntry{Key: b, Value: []byte{5}})
+		s.commits[s.headers[13].ID()] = commit13
+
+		reopened := newIndexerSuite(t, db)
+		reopened.headers, reopened.commits, reopened.sealed = s.headers, s.commits, s.sealed
+
+		require.NoError(t, reopened.indexer.indexSealedHeights(ctx))
+		assert.Equal(t, uint64(13), reopened.registers.LatestHeight())
+		value, err = reopened.registers.Get(b, 13)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{5}, value)
+		assert.Equal(t, 0, reopened.pendingCount(t))
+	})
+}
+
+// newTrie returns a trie with the given registers updated in the given parent trie.
+// Registers with an empty value are removed.
+func newTrie(t *testing.T, parent *trie.MTrie, entries ...flow.RegisterEntry) *trie.MTrie {
+	paths := make([]ledger.Path, 0, len(entries))
+	payloads := make([]ledger.Payload, 0, len(entries))
+	for _, entry := range entries {
+		key := state.RegisterIDToKey(entry.Key)
+		path, err := pathfinder.KeyToPath(key, complete.DefaultPathFinderVersion)
+		require.NoError(t, err)
+		paths = append(paths, path)
+		payloads = append(payloads, *ledger.NewPayload(key, entry.Value))
+	}
+	updated, _, err := trie.NewTrieWithUpdatedRegisters(parent, paths, payloads, true)
+	require.NoError(t, err)
+	return updated
+}
+
+func trieCommit(t *trie.MTrie) flow.StateCommitment {
+	return flow.StateCommitment(t.RootHash())
+}
+
+// TestIndexer_MissingUpdatesFromLedger tests that the register updates of a sealed height whose trie
+// updates are missing are derived from the tries held by the ledger.
+func TestIndexer_MissingUpdatesFromLedger(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		s := newIndexerSuite(t, db)
+		ctx := irrecoverable.NewMockSignalerContext(t, context.Background())
+
+		a := flow.NewRegisterID("owner", "a")
+		b := flow.NewRegisterID("owner", "b")
+		c := flow.NewRegisterID("owner", "c")
+
+		trie10 := newTrie(t, trie.NewEmptyMTrie(),
+			flow.RegisterEntry{Key: a, Value: []byte{1}},
+			flow.RegisterEntry{Key: b, Value: []byte{2}},
+		)
+		s.addBlock(10, trieCommit(trie10))
+		require.NoError(t, s.registers.Bootstrap(10, trieCommit(trie10), TrieRegisters(trie10)))
+
+		// the update leading to the state of block 11 was never received, but the ledger holds the state
+		trie11 := newTrie(t, trie10,
+			flow.RegisterEntry{Key: a, Value: []byte{3}},
+			flow.RegisterEntry{Key: b, Value: nil},
+			flow.RegisterEntry{Key: c, Value: []byte{4}},
+		)
+		s.addBlock(11, trieCommit(trie11))
+		s.tries = func() ([]*trie.MTrie, error) {
+			return []*trie.MTrie{trie10, trie11}, nil
+		}
+
+		require.NoError(t, s.indexer.indexSealedHeights(ctx))
+		assert.Equal(t, uint64(10), s.registers.FirstHeight())
+		assert.Equal(t, uint64(11), s.registers.LatestHeight())
+
+		expected := []struct {
+			id     flow.RegisterID
+			height uint64
+			value  flow.RegisterValue
+		}{
+			{a, 10, []byte{1}},
+			{b, 10, []byte{2}},
+			{c, 10, nil},
+			{a, 11, []byte{3}},
+			{b, 11, nil},
+			{c, 11, []byte{4}},
+		}
+		for _, e := range expected {
+			value, err := s.registers.Get(e.id, e.height)
+			require.NoError(t, err)
+			assert.Equal(t, e.value, value, "register %s at height %d", e.id, e.height)
+		}
+	})
+}
+
+// TestIndexer_MissingUpdatesRebootstrap tests that the store is bootstrapped again from a later sealed
+// height if the trie updates of a sealed height are missing, and the ledger doesn't hold its state.
+// The heights indexed before stay readable.
+func TestIndexer_MissingUpdatesRebootstrap(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		s := newIndexerSuite(t, db)
+		ctx := irrecoverable.NewMockSignalerContext(t, context.Background())
+
+		a := flow.NewRegisterID("owner", "a")
+		b := flow.NewRegisterID("owner", "b")
+
+		trie10 := newTrie(t, trie.NewEmptyMTrie(),
+			flow.RegisterEntry{Key: a, Value: []byte{1}},
+			flow.RegisterEntry{Key: b, Value: []byte{2}},
+		)
+		s.addBlock(10, trieCommit(trie10))
+		require.NoError(t, s.registers.Bootstrap(10, trieCommit(trie10), TrieRegisters(trie10)))
+
+		// the update leading to the state of block 11 was never received, and the ledger only holds
+		// the state of block 12, in which register b was removed
+		s.addBlock(11, unittest.StateCommitmentFixture())
+		trie12 := newTrie(t, trie10,
+			flow.RegisterEntry{Key: a, Value: []byte{3}},
+			flow.RegisterEntry{Key: b, Value: nil},
+		)
+		s.addBlock(12, trieCommit(trie12))
+		// block 13 is not executed yet
+		s.addBlock(13, unittest.StateCommitmentFixture())
+		delete(s.commits, s.headers[13].ID())
+
+		// the ledger holds no sealed state above the indexed height yet
+		require.NoError(t, s.indexer.indexSealedHeights(ctx))
+		assert.Equal(t, uint64(10), s.registers.LatestHeight())
+
+		s.tries = func() ([]*trie.MTrie, error) {
+			return []*trie.MTrie{trie12}, nil
+		}
+		require.NoError(t, s.indexer.indexSealedHeights(ctx))
+		assert.Equal(t, uint64(10), s.registers.FirstHeight())
+		assert.Equal(t, uint64(12), s.registers.LatestHeight())
+
+		value, err := s.registers.Get(a, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1}, value)
+		value, err = s.registers.Get(b, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{2}, value)
+		_, err = s.registers.Get(a, 11)
+		require.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+		_, err = s.registers.Commit(11)
+		require.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+		value, err = s.registers.Get(a, 12)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{3}, value)
+		value, err = s.registers.Get(b, 12)
+		require.NoError(t, err)
+		assert.Nil(t, value)
+
+		// heights above the new bootstrap height are indexed as usual, also after a restart
+		commit13 := unittest.StateCommitmentFixture()
+		s.update(trieCommit(trie12), commit13, flow.RegisterEntry{Key: a, Value: []byte{4}})
+		s.commits[s.headers[13].ID()] = commit13
+
+		reopened := newIndexerSuite(t, db)
+		reopened.headers, reopened.commits, reopened.sealed = s.headers, s.commits, s.sealed
+
+		require.NoError(t, reopened.indexer.indexSealedHeights(ctx))
+		value, err = reopened.registers.Get(a, 13)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{4}, value)
+		_, err = reopened.registers.Get(a, 11)
+		require.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+		value, err = reopened.registers.Get(a, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1}, value)
+	})
+}
+
+// TestIndexer_LedgerFailure tests that failing to get the tries of the ledger is an exception.
+func TestIndexer_LedgerFailure(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		s := newIndexerSuite(t, db)
+		ctx := irrecoverable.NewMockSignalerContext(t, context.Background())
+
+		root := unittest.StateCommitmentFixture()
+		s.addBlock(10, root)
+		require.NoError(t, s.registers.Bootstrap(10, root, registerSlice()))
+		s.addBlock(11, unittest.StateCommitmentFixture())
+
+		exception := fmt.Errorf("exception")
+		s.tries = func() ([]*trie.MTrie, error) { return nil, exception }
+
+		err := s.indexer.indexSealedHeights(ctx)
+		require.ErrorIs(t, err, exception)
+		assert.Equal(t, uint64(10), s.registers.LatestHeight())
+	})
+}
@@ -0,0 +1,231 @@
+// Package history implements an on-disk store of the register values at every sealed height.
+//
+// The ledger only holds the tries of recent states in memory, so register values at older
+// state commitments can not be read from it anymore. The Registers store keeps every value a
+// register had since the store was bootstrapped, indexed by register ID and height, which makes
+// reading the value of a register at any indexed height a single seek.
+//
+// The register values are stored in the same register index (storage/store.Registers) which access
+// and observer nodes fill from execution data, execution nodes additionally store the state
+// commitment of every indexed height and the trie updates of the ledger until they are indexed.
+package history
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/onflow/flow-go/fvm/storage/snapshot"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation"
+	"github.com/onflow/flow-go/storage/store"
+)
+
+// Registers stores the values of all registers at every indexed height.
+// A value is only stored at the heights at which the register was updated, reading a register
+// returns the value stored at the highest height at or below the requested height.
+//
+// Registers is safe for concurrent use, but only one goroutine may store new heights.
+type Registers struct {
+	db storage.DB
+
+	mu        sync.RWMutex
+	registers *store.Registers // nil if the store is not bootstrapped
+}
+
+// NewRegisters returns the register store backed by the given database.
+// No error returns are expected during normal operation.
+func NewRegisters(db storage.DB) (*Registers, error) {
+	registers, err := store.NewRegisters(db)
+	if errors.Is(err, storage.ErrNotFound) {
+		return &Registers{db: db}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Registers{db: db, registers: registers}, nil
+}
+
+// index returns the register index, or nil if the store is not bootstrapped.
+func (r *Registers) index() *store.Registers {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.registers
+}
+
+// IsBootstrapped returns true if the store has been bootstrapped.
+func (r *Registers) IsBootstrapped() bool {
+	return r.index() != nil
+}
+
+// FirstHeight returns the height the store was bootstrapped at.
+func (r *Registers) FirstHeight() uint64 {
+	index := r.index()
+	if index == nil {
+		return 0
+	}
+	return index.FirstHeight()
+}
+
+// LatestHeight returns the latest indexed height.
+func (r *Registers) LatestHeight() uint64 {
+	index := r.index()
+	if index == nil {
+		return 0
+	}
+	return index.LatestHeight()
+}
+
+// bootstrapBatchSize is the maximum number of registers written in a single batch when bootstrapping.
+const bootstrapBatchSize = 10_000
+
+// RegisterBatches returns the next batch of registers of a state on every call, and no registers
+// once all registers have been returned.
+type RegisterBatches func() (flow.RegisterEntries, error)
+
+// Bootstrap seeds the store with the registers returned by next, which must be all registers of
+// the state at the given height. Registers are written in batches, the store is only marked as
+// bootstrapped once all of them are written. Registers left by an interrupted bootstrap are
+// removed first.
+// No error returns are expected during normal operation.
+func (r *Registers) Bootstrap(height uint64, commit flow.StateCommitment, next RegisterBatches) error {
+	if r.IsBootstrapped() {
+		return fmt.Errorf("register store is already bootstrapped")
+	}
+
+	// the commitment is only used once the store is marked as bootstrapped
+	err := r.db.WithReaderBatchWriter(storage.OnlyWriter(operation.UpsertRegistersCommit(height, commit)))
+	if err != nil {
+		return fmt.Errorf("could not store state commitment: %w", err)
+	}
+	err = store.BootstrapRegisters(r.db, height, next)
+	if err != nil {
+		return err
+	}
+	registers, err := store.NewRegisters(r.db)
+	if err != nil {
+		return fmt.Errorf("could not load bootstrapped registers: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.registers = registers
+	return nil
+}
+
+// Rebootstrap stores the registers returned by next, which must be all registers of the state at the
+// given height, when the register updates of the heights following the latest indexed height are not
+// available anymore. The heights between the latest indexed height and the given height are not indexed
+// afterwards, while the heights indexed before stay readable.
+// No error returns are expected during normal operation.
+func (r *Registers) Rebootstrap(height uint64, commit flow.StateCommitment, next RegisterBatches) error {
+	index := r.index()
+	if index == nil {
+		return fmt.Errorf("register store is not bootstrapped")
+	}
+
+	// the commitment is only used once the height is stored
+	err := r.db.WithReaderBatchWriter(storage.OnlyWriter(operation.UpsertRegistersCommit(height, commit)))
+	if err != nil {
+		return fmt.Errorf("could not store state commitment: %w", err)
+	}
+	return index.Rebootstrap(height, next)
+}
+
+// Store stores the registers updated by the block at the given height, which must be the height
+// following the latest indexed height.
+// No error returns are expected during normal operation.
+func (r *Registers) Store(height uint64, commit flow.StateCommitment, entries flow.RegisterEntries) error {
+	index := r.index()
+	if index == nil || height != index.LatestHeight()+1 {
+		return fmt.Errorf("cannot store height %d, latest indexed height is %d", height, r.LatestHeight())
+	}
+
+	// the commitment is only used once the height is stored
+	err := r.db.WithReaderBatchWriter(storage.OnlyWriter(operation.UpsertRegistersCommit(height, commit)))
+	if err != nil {
+		return fmt.Errorf("could not store state commitment: %w", err)
+	}
+	return index.Store(entries, height)
+}
+
+// Commit returns the state commitment of the given height.
+// Expected errors during normal operation:
+//   - storage.ErrHeightNotIndexed if the height is not in the store
+func (r *Registers) Commit(height uint64) (flow.StateCommitment, error) {
+	_, err := r.checkHeight(height)
+	if err != nil {
+		return flow.DummyStateCommitment, err
+	}
+
+	var commit flow.StateCommitment
+	err = operation.RetrieveRegistersCommit(height, &commit)(r.db.Reader())
+	if err != nil {
+		return flow.DummyStateCommitment, fmt.Errorf("could not read state commitment of height %d: %w", height, err)
+	}
+	return commit, nil
+}
+
+// Get returns the value of the register at the given height. Registers which do not exist
+// at the given height have an empty value.
+// Expected errors during normal operation:
+//   - storage.ErrHeightNotIndexed if the height is not in the store
+func (r *Registers) Get(id flow.RegisterID, height uint64) (flow.RegisterValue, error) {
+	index, err := r.checkHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := index.Get(id, height)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// StorageSnapshot returns a snapshot of the registers at the given height, which must have the given state commitment.
+// Expected errors during normal operation:
+//   - storage.ErrHeightNotIndexed if the height is not in the store, or if the indexed state of the height
+//     has a different state commitment (e.g. the commitment is of a block which is not sealed)
+func (r *Registers) StorageSnapshot(height uint64, commit flow.StateCommitment) (snapshot.StorageSnapshot, error) {
+	indexed, err := r.Commit(height)
+	if err != nil {
+		return nil, err
+	}
+	if indexed != commit {
+		return nil, fmt.Errorf("%w: state commitment %x differs from indexed state commitment %x of height %d",
+			storage.ErrHeightNotIndexed, commit, indexed, height)
+	}
+	return &registersSnapshot{registers: r, height: height}, nil
+}
+
+// checkHeight returns the register index if the given height is indexed.
+// Expected errors during normal operation:
+//   - storage.ErrHeightNotIndexed if the height is not in the store
+func (r *Registers) checkHeight(height uint64) (*store.Registers, error) {
+	index := r.index()
+	if index == nil {
+		return nil, fmt.Errorf("%w: height %d is not indexed, the store is not bootstrapped", storage.ErrHeightNotIndexed, height)
+	}
+	if !index.IsIndexed(height) {
+		return nil, fmt.Errorf("%w: height %d is not in the indexed range [%d, %d] or has been skipped",
+			storage.ErrHeightNotIndexed, height, index.FirstHeight(), index.LatestHeight())
+	}
+	return index, nil
+}
+
+// registersSnapshot is a storage snapshot of the registers at a fixed height.
+type registersSnapshot struct {
+	registers *Registers
+	height    uint64
+}
+
+var _ snapshot.StorageSnapshot = (*registersSnapshot)(nil)
+
+func (s *registersSnapshot) Get(id flow.RegisterID) (flow.RegisterValue, error) {
+	return s.registers.Get(id, s.height)
+}
@@ -0,0 +1,168 @@
+package history
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation/dbtest"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func registerPayload(id flow.RegisterID, value flow.RegisterValue) ledger.Payload {
+	return *ledger.NewPayload(state.RegisterIDToKey(id), value)
+}
+
+// registerBatches returns the given registers in batches of the given size.
+func registerBatches(size int, entries ...flow.RegisterEntry) RegisterBatches {
+	return func() (flow.RegisterEntries, error) {
+		if len(entries) < size {
+			size = len(entries)
+		}
+		batch := entries[:size]
+		entries = entries[size:]
+		return batch, nil
+	}
+}
+
+// registerSlice returns the given registers in a single batch.
+func registerSlice(entries ...flow.RegisterEntry) RegisterBatches {
+	return registerBatches(len(entries)+1, entries...)
+}
+
+func TestRegisters(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		registers, err := NewRegisters(db)
+		require.NoError(t, err)
+		require.False(t, registers.IsBootstrapped())
+
+		a := flow.NewRegisterID("owner", "a")
+		b := flow.NewRegisterID("owner", "b")
+		// a register whose owner and key concatenate to the ones of register a
+		aPrefixed := flow.NewRegisterID("own", "era")
+
+		commits := []flow.StateCommitment{
+			unittest.StateCommitmentFixture(),
+			unittest.StateCommitmentFixture(),
+			unittest.StateCommitmentFixture(),
+		}
+
+		_, err = registers.Get(a, 10)
+		require.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+
+		err = registers.Bootstrap(10, commits[0], registerSlice(
+			flow.RegisterEntry{Key: a, Value: []byte{1}},
+			flow.RegisterEntry{Key: aPrefixed, Value: []byte{9}},
+		))
+		require.NoError(t, err)
+		require.Error(t, registers.Bootstrap(10, commits[0], registerSlice()))
+
+		// heights must be stored in order
+		require.Error(t, registers.Store(12, commits[2], nil))
+
+		require.NoError(t, registers.Store(11, commits[1], flow.RegisterEntries{
+			{Key: b, Value: []byte{2}},
+		}))
+		require.NoError(t, registers.Store(12, commits[2], flow.RegisterEntries{
+			{Key: a, Value: []byte{3}},
+			{Key: b, Value: nil},
+		}))
+
+		assert.Equal(t, uint64(10), registers.FirstHeight())
+		assert.Equal(t, uint64(12), registers.LatestHeight())
+
+		expected := []struct {
+			id     flow.RegisterID
+			height uint64
+			value  flow.RegisterValue
+		}{
+			{a, 10, []byte{1}},
+			{a, 11, []byte{1}},
+			{a, 12, []byte{3}},
+			{b, 10, nil},
+			{b, 11, []byte{2}},
+			{b, 12, nil},
+			{aPrefixed, 12, []byte{9}},
+		}
+		for _, e := range expected {
+			value, err := registers.Get(e.id, e.height)
+			require.NoError(t, err)
+			assert.Equal(t, e.value, value, "register %s at height %d", e.id, e.height)
+		}
+
+		_, err = registers.Get(a, 9)
+		require.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+		_, err = registers.Get(a, 13)
+		require.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+
+		t.Run("storage snapshot", func(t *testing.T) {
+			snapshot, err := registers.StorageSnapshot(11, commits[1])
+			require.NoError(t, err)
+			value, err := snapshot.Get(b)
+			require.NoError(t, err)
+			assert.Equal(t, []byte{2}, value)
+
+			// the commitment of another block at the same height is not indexed
+			_, err = registers.StorageSnapshot(11, unittest.StateCommitmentFixture())
+			require.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+		})
+
+		t.Run("reopen", func(t *testing.T) {
+			reopened, err := NewRegisters(db)
+			require.NoError(t, err)
+			require.True(t, reopened.IsBootstrapped())
+			assert.Equal(t, uint64(10), reopened.FirstHeight())
+			assert.Equal(t, uint64(12), reopened.LatestHeight())
+
+			commit, err := reopened.Commit(12)
+			require.NoError(t, err)
+			assert.Equal(t, commits[2], commit)
+		})
+	})
+}
+
+// TestRegisters_InterruptedBootstrap tests that the registers written by an interrupted bootstrap,
+// which spans several batches, are removed when the store is bootstrapped again.
+func TestRegisters_InterruptedBootstrap(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		registers, err := NewRegisters(db)
+		require.NoError(t, err)
+
+		leftover := flow.NewRegisterID("owner", "0")
+		entries := make([]flow.RegisterEntry, 2*bootstrapBatchSize+1)
+		for i := range entries {
+			entries[i] = flow.RegisterEntry{Key: flow.NewRegisterID("owner", fmt.Sprintf("%d", i)), Value: []byte{1}}
+		}
+		interrupted := fmt.Errorf("interrupted")
+		batches := registerBatches(bootstrapBatchSize, entries...)
+		err = registers.Bootstrap(10, unittest.StateCommitmentFixture(), func() (flow.RegisterEntries, error) {
+			batch, err := batches()
+			if len(batch) == 0 {
+				return nil, interrupted
+			}
+			return batch, err
+		})
+		require.ErrorIs(t, err, interrupted)
+		require.False(t, registers.IsBootstrapped())
+
+		reopened, err := NewRegisters(db)
+		require.NoError(t, err)
+		require.False(t, reopened.IsBootstrapped())
+
+		a := flow.NewRegisterID("owner", "a")
+		require.NoError(t, reopened.Bootstrap(12, unittest.StateCommitmentFixture(), registerSlice(flow.RegisterEntry{Key: a, Value: []byte{2}})))
+
+		value, err := reopened.Get(a, 12)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{2}, value)
+		value, err = reopened.Get(leftover, 12)
+		require.NoError(t, err)
+		assert.Nil(t, value)
+	})
+}
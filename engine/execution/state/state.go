@@ -86,6 +86,21 @@ func RegisterIDToKey(reg flow.RegisterID) ledger.Key {
 	})
 }
 
+// KeyToRegisterID converts a ledger key back to the register ID it was created from by RegisterIDToKey.
+// No errors are expected for keys created by RegisterIDToKey.
+func KeyToRegisterID(key ledger.Key) (flow.RegisterID, error) {
+	if len(key.KeyParts) != 2 ||
+		key.KeyParts[0].Type != KeyPartOwner ||
+		key.KeyParts[1].Type != KeyPartKey {
+		return flow.RegisterID{}, fmt.Errorf("key not in expected format: %s", key.String())
+	}
+
+	return flow.RegisterID{
+		Owner: string(key.KeyParts[0].Value),
+		Key:   string(key.KeyParts[1].Value),
+	}, nil
+}
+
 // NewExecutionState returns a new execution state access layer for the given ledger storage.
 func NewExecutionState(
 	ls ledger.Ledger,
@@ -241,3 +241,17 @@ func TestExecutionStateWithTrieStorage(t *testing.T) {
 	}))
 
 }
+
+func TestKeyToRegisterID(t *testing.T) {
+	for _, id := range []flow.RegisterID{
+		flow.UUIDRegisterID,
+		flow.AccountStatusRegisterID(unittest.RandomAddressFixture()),
+	} {
+		converted, err := state.KeyToRegisterID(state.RegisterIDToKey(id))
+		require.NoError(t, err)
+		require.Equal(t, id, converted)
+	}
+
+	_, err := state.KeyToRegisterID(ledger2.NewKey([]ledger2.KeyPart{ledger2.NewKeyPart(state.KeyPartOwner, []byte("owner"))}))
+	require.Error(t, err)
+}
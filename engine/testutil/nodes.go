@@ -958,7 +958,7 @@ func VerificationNode(t testing.TB,
 			fvm.WithBlocks(blockFinder),
 		)
 
-		chunkVerifier := chunks.NewChunkVerifier(vm, vmCtx, node.Log)
+		chunkVerifier := chunks.NewChunkVerifier(vm, vmCtx, node.Log, node.State)
 
 		approvalStorage := storage.NewResultApprovals(node.Metrics, node.PublicDB)
 
@@ -33,6 +33,7 @@ func NewChunkConsumer(
 	chunksQueue storage.ChunksQueue, // to read jobs (chunks) from
 	chunkProcessor fetcher.AssignedChunkProcessor, // to process jobs (chunks)
 	maxProcessing uint64, // max number of jobs to be processed in parallel
+	opts ...jobqueue.ConsumerOption, // optional consumer config, e.g. job timeouts and dead-lettering
 ) *ChunkConsumer {
 	worker := NewWorker(chunkProcessor)
 	chunkProcessor.WithChunkConsumerNotifier(worker)
@@ -40,7 +41,7 @@ func NewChunkConsumer(
 	jobs := &ChunkJobs{locators: chunksQueue}
 
 	lg := log.With().Str("module", "chunk_consumer").Logger()
-	consumer := jobqueue.NewConsumer(lg, jobs, processedIndex, worker, maxProcessing, 0)
+	consumer := jobqueue.NewConsumer(lg, jobs, processedIndex, worker, maxProcessing, 0, opts...)
 
 	chunkConsumer := &ChunkConsumer{
 		consumer:       consumer,
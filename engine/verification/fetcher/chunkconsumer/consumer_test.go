@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/dgraph-io/badger/v2"
 	"github.com/stretchr/testify/require"
@@ -13,6 +14,7 @@ import (
 	"github.com/onflow/flow-go/engine/verification/fetcher/chunkconsumer"
 	"github.com/onflow/flow-go/model/chunks"
 	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/jobqueue"
 	"github.com/onflow/flow-go/module/metrics"
 	storage "github.com/onflow/flow-go/storage/badger"
 	"github.com/onflow/flow-go/utils/unittest"
@@ -66,11 +68,11 @@ func TestProduceConsume(t *testing.T) {
 		var called chunks.LocatorList
 		lock := &sync.Mutex{}
 		var finishAll sync.WaitGroup
+		finishAll.Add(10)
 		alwaysFinish := func(notifier module.ProcessingNotifier, locator *chunks.Locator) {
 			lock.Lock()
 			defer lock.Unlock()
 			called = append(called, locator)
-			finishAll.Add(1)
 			go func() {
 				notifier.Notify(locator.ID())
 				finishAll.Done()
@@ -88,8 +90,10 @@ func TestProduceConsume(t *testing.T) {
 				consumer.Check() // notify the consumer
 			}
 
+			// the consumer stops picking up new jobs once it is done, hence wait until all jobs
+			// finished before stopping it
+			finishAll.Wait()
 			<-consumer.Done()
-			finishAll.Wait() // wait until all finished
 			// expect the mock engine receives all 10 calls
 			require.Equal(t, locators, called)
 		})
@@ -136,6 +140,62 @@ func TestProduceConsume(t *testing.T) {
 	})
 }
 
+// TestCancelTimedOutChunk evaluates that a chunk which is not done within the job timeout is canceled at the
+// engine before it is retried, and that the retried chunk can finish the job.
+func TestCancelTimedOutChunk(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		processedIndex := storage.NewConsumerProgress(db, module.ConsumeProgressVerificationChunkIndex)
+		chunksQueue := storage.NewChunkQueue(db)
+		ok, err := chunksQueue.Init(chunkconsumer.DefaultJobIndex)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		processed := atomic.NewUint32(0)
+		canceled := atomic.NewUint32(0)
+		engine := &mockChunkProcessor{
+			// never finishes the first attempt of the chunk, and finishes the retry.
+			process: func(notifier module.ProcessingNotifier, locator *chunks.Locator) {
+				if processed.Inc() > 1 {
+					notifier.Notify(locator.ID())
+				}
+			},
+			cancel: func(*chunks.Locator) {
+				canceled.Inc()
+			},
+		}
+
+		consumer := chunkconsumer.NewChunkConsumer(
+			unittest.Logger(),
+			&metrics.NoopCollector{},
+			processedIndex,
+			chunksQueue,
+			engine,
+			1,
+			jobqueue.WithJobTimeout(jobqueue.JobTimeoutConfig{
+				Timeout:       10 * time.Millisecond,
+				MaxAttempts:   3,
+				RetryDelay:    time.Millisecond,
+				MaxRetryDelay: time.Millisecond,
+			}),
+		)
+		<-consumer.Ready()
+
+		ok, err = chunksQueue.StoreChunkLocator(unittest.ChunkLocatorFixture(unittest.IdentifierFixture(), 0))
+		require.NoError(t, err)
+		require.True(t, ok)
+		consumer.Check()
+
+		require.Eventually(t, func() bool {
+			index, err := processedIndex.ProcessedIndex()
+			return err == nil && index == 1
+		}, time.Second, 10*time.Millisecond)
+		<-consumer.Done()
+
+		require.Equal(t, uint32(2), processed.Load())
+		require.Equal(t, uint32(1), canceled.Load())
+	})
+}
+
 func WithConsumer(
 	t *testing.T,
 	process func(module.ProcessingNotifier, *chunks.Locator),
@@ -172,6 +232,7 @@ func WithConsumer(
 type mockChunkProcessor struct {
 	notifier module.ProcessingNotifier
 	process  func(notifier module.ProcessingNotifier, locator *chunks.Locator)
+	cancel   func(locator *chunks.Locator)
 }
 
 func (e *mockChunkProcessor) Ready() <-chan struct{} {
@@ -190,6 +251,12 @@ func (e *mockChunkProcessor) ProcessAssignedChunk(locator *chunks.Locator) {
 	e.process(e.notifier, locator)
 }
 
+func (e *mockChunkProcessor) CancelAssignedChunk(locator *chunks.Locator) {
+	if e.cancel != nil {
+		e.cancel(locator)
+	}
+}
+
 func (e *mockChunkProcessor) WithChunkConsumerNotifier(notifier module.ProcessingNotifier) {
 	e.notifier = notifier
 }
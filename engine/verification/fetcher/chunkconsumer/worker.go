@@ -1,9 +1,13 @@
 package chunkconsumer
 
 import (
+	"sync"
+
 	"github.com/onflow/flow-go/engine/verification/fetcher"
+	"github.com/onflow/flow-go/model/chunks"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/jobqueue"
 )
 
 // Worker receives job from job consumer and converts it back to Chunk
@@ -11,11 +15,17 @@ import (
 type Worker struct {
 	engine   fetcher.AssignedChunkProcessor
 	consumer *ChunkConsumer
+
+	mu       sync.Mutex
+	inFlight map[module.JobID]*chunks.Locator // locators of jobs which are being processed by the engine
 }
 
+var _ jobqueue.CancelableWorker = (*Worker)(nil)
+
 func NewWorker(engine fetcher.AssignedChunkProcessor) *Worker {
 	return &Worker{
-		engine: engine,
+		engine:   engine,
+		inFlight: make(map[module.JobID]*chunks.Locator),
 	}
 }
 
@@ -26,12 +36,35 @@ func (w *Worker) Run(job module.Job) error {
 	if err != nil {
 		return err
 	}
+
+	w.mu.Lock()
+	w.inFlight[job.ID()] = chunk
+	w.mu.Unlock()
+
 	w.engine.ProcessAssignedChunk(chunk)
 
 	return nil
 }
 
+// Cancel stops the engine from processing the chunk of the given job, so that the
+// job can be retried.
+func (w *Worker) Cancel(jobID module.JobID) {
+	w.mu.Lock()
+	chunk, ok := w.inFlight[jobID]
+	delete(w.inFlight, jobID)
+	w.mu.Unlock()
+
+	if ok {
+		w.engine.CancelAssignedChunk(chunk)
+	}
+}
+
 func (w *Worker) Notify(chunkLocatorID flow.Identifier) {
 	jobID := locatorIDToJobID(chunkLocatorID)
+
+	w.mu.Lock()
+	delete(w.inFlight, jobID)
+	w.mu.Unlock()
+
 	w.consumer.NotifyJobIsDone(jobID)
 }
@@ -468,6 +468,19 @@ func (e Engine) validateAuthorizedExecutionNodeAtBlockID(senderID flow.Identifie
 	return valid
 }
 
+// CancelAssignedChunk drops the pending status of the assigned chunk, so that a later ProcessAssignedChunk for
+// the same locator requests its chunk data pack again instead of being dropped as a duplicate.
+// A chunk data pack of a canceled chunk which arrives before the chunk is processed again is dropped.
+func (e *Engine) CancelAssignedChunk(locator *chunks.Locator) {
+	removed := e.pendingChunks.Remove(locator.Index, locator.ResultID)
+	e.log.Warn().
+		Hex("locator_id", logging.ID(locator.ID())).
+		Hex("result_id", logging.ID(locator.ResultID)).
+		Uint64("chunk_index", locator.Index).
+		Bool("removed", removed).
+		Msg("canceled processing of assigned chunk")
+}
+
 // NotifyChunkDataPackSealed is called by the ChunkDataPackRequester to notify the ChunkDataPackHandler that the specified chunk
 // has been sealed and hence the requester will no longer request it.
 //
@@ -15,6 +15,11 @@ type AssignedChunkProcessor struct {
 	mock.Mock
 }
 
+// CancelAssignedChunk provides a mock function with given fields: locator
+func (_m *AssignedChunkProcessor) CancelAssignedChunk(locator *chunks.Locator) {
+	_m.Called(locator)
+}
+
 // Done provides a mock function with given fields:
 func (_m *AssignedChunkProcessor) Done() <-chan struct{} {
 	ret := _m.Called()
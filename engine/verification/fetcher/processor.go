@@ -21,6 +21,11 @@ type AssignedChunkProcessor interface {
 	// Note: it should be implemented in a non-blocking way.
 	ProcessAssignedChunk(locator *chunks.Locator)
 
+	// CancelAssignedChunk stops processing an assigned chunk locator, so that a later ProcessAssignedChunk
+	// for the same locator processes the chunk again. The notifier is not called for a canceled chunk.
+	// It is called by the consumer when processing a chunk takes too long, before the chunk is retried.
+	CancelAssignedChunk(locator *chunks.Locator)
+
 	// WithChunkConsumerNotifier sets the notifier of this chunk processor.
 	// The notifier is called by the internal logic of the processor to let the consumer know that
 	// the processor is done by processing a chunk so that the next chunk may be passed to the processor
@@ -2,6 +2,7 @@ package verificationtest
 
 import (
 	"context"
+	"math"
 	"math/rand"
 	"testing"
 
@@ -288,7 +289,9 @@ func ExecutionResultFixture(t *testing.T, chunkCount int, chain flow.Chain, refB
 			committer,
 			me,
 			prov,
-			nil)
+			nil,
+			unittest.ProtocolStateWithSourceFixture(nil),
+			math.MaxUint64)
 		require.NoError(t, err)
 
 		completeColls := make(map[flow.Identifier]*entity.CompleteCollection)
@@ -0,0 +1,45 @@
+package fvm
+
+import (
+	"github.com/onflow/cadence/runtime"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// ChainOptions returns the options execution nodes use to execute
+// transactions on the given chain.
+func ChainOptions(chainID flow.ChainID) []Option {
+	opts := []Option{
+		WithChain(chainID.Chain()),
+		WithAccountStorageLimit(true),
+	}
+	if chainID == flow.Testnet || chainID == flow.Sandboxnet || chainID == flow.Mainnet {
+		opts = append(opts,
+			WithTransactionFeesEnabled(true),
+		)
+	}
+	if chainID == flow.Testnet || chainID == flow.Sandboxnet || chainID == flow.Localnet || chainID == flow.Benchnet {
+		opts = append(opts,
+			WithContractDeploymentRestricted(false),
+		)
+	}
+	if chainID == flow.Localnet || chainID == flow.Benchnet {
+		// verifiable randomness is not enabled on live networks yet, it is available
+		// from genesis on networks which are bootstrapped from scratch
+		opts = append(opts,
+			WithVerifiableRandomnessHeight(0),
+		)
+	}
+	return opts
+}
+
+// RuntimeConfig returns the cadence runtime configuration execution nodes use
+// on the given chain.
+func RuntimeConfig(chainID flow.ChainID, tracingEnabled bool) runtime.Config {
+	return runtime.Config{
+		TracingEnabled:        tracingEnabled,
+		AccountLinkingEnabled: true,
+		// Attachments are enabled everywhere except for Mainnet
+		AttachmentsEnabled: chainID != flow.Mainnet,
+	}
+}
@@ -171,6 +171,39 @@ func WithBlockHeader(header *flow.Header) Option {
 	}
 }
 
+// WithEntropyProvider sets the provider of the executed block's source of
+// randomness for a virtual machine context.
+//
+// The VM derives the randoms returned by the Cadence verifiableRandom function
+// from the source of randomness, customized by the transaction index.
+//
+// The source of randomness is the one of the executed block itself, i.e. the
+// random beacon signature in the QC certifying the block, and not the source
+// of the latest sealed block.  The latter is known before the block is
+// proposed, so the proposer could select transactions knowing their randoms.
+// The certifying QC is only known once the block is certified, and is the
+// same for all execution and verification nodes.
+func WithEntropyProvider(provider environment.EntropyProvider) Option {
+	return func(ctx Context) Context {
+		ctx.EntropyProvider = provider
+		return ctx
+	}
+}
+
+// WithVerifiableRandomnessHeight sets the first block height at which the
+// Cadence verifiableRandom function is available.  Below that height, and if
+// no entropy provider is set, verifiableRandom is not declared, so programs
+// which call it fail type checking, and programs which declare the same
+// identifier themselves keep working.
+//
+// Verifiable randomness is disabled by default.
+func WithVerifiableRandomnessHeight(height uint64) Option {
+	return func(ctx Context) Context {
+		ctx.VerifiableRandomnessHeight = height
+		return ctx
+	}
+}
+
 // WithServiceEventCollectionEnabled enables service event collection
 func WithServiceEventCollectionEnabled() Option {
 	return func(ctx Context) Context {
@@ -226,6 +259,18 @@ func WithExtensiveTracing() Option {
 	}
 }
 
+// WithComputationProfiler sets the profiler which attributes the computation
+// and memory usage of executed procedures to Cadence call stacks. Profiling
+// is disabled when the profiler is nil.
+func WithComputationProfiler(
+	profiler *environment.ComputationProfiler,
+) Option {
+	return func(ctx Context) Context {
+		ctx.ComputationProfiler = profiler
+		return ctx
+	}
+}
+
 // WithServiceAccount enables or disables calls to the Flow service account.
 func WithServiceAccount(enabled bool) Option {
 	return func(ctx Context) Context {
@@ -0,0 +1,522 @@
+package environment
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/google/pprof/profile"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+
+	"github.com/onflow/flow-go/fvm/meter"
+	reusableRuntime "github.com/onflow/flow-go/fvm/runtime"
+)
+
+const (
+	// ComputationProfileComputationUnit is the unit of the computation samples
+	// of a computation profile.  Computation is reported with the meter's
+	// internal precision, i.e. 1<<16 units are one unit of computation.
+	ComputationProfileComputationUnit = "computation/65536"
+
+	// ComputationProfileMemoryUnit is the unit of the memory samples of a
+	// computation profile (i.e., the metered memory estimate).
+	ComputationProfileMemoryUnit = "bytes"
+
+	unknownProfileFunction = "<unknown>"
+)
+
+// profileFrame identifies a position in a Cadence program.
+type profileFrame struct {
+	location string
+	function string
+	line     int
+}
+
+// profileNode is a node of the profiled call tree.  The path from the root
+// to the node is the (contract, function) call stack the node's computation
+// and memory usage is attributed to.
+type profileNode struct {
+	frame    profileFrame
+	parent   *profileNode
+	children map[profileFrame]*profileNode
+
+	computation uint64
+	memory      uint64
+}
+
+func (node *profileNode) child(frame profileFrame) *profileNode {
+	child, ok := node.children[frame]
+	if !ok {
+		child = &profileNode{
+			frame:    frame,
+			parent:   node,
+			children: map[profileFrame]*profileNode{},
+		}
+		node.children[frame] = child
+	}
+	return child
+}
+
+// functionRange is the source range of a declared Cadence function.
+type functionRange struct {
+	name  string
+	start int
+	end   int
+}
+
+// functionIndex maps source offsets of a program to the name of the
+// innermost function declared around them.
+type functionIndex struct {
+	ranges  []functionRange
+	offsets map[int]string
+}
+
+func newFunctionIndex(program *ast.Program) *functionIndex {
+	index := &functionIndex{
+		offsets: map[int]string{},
+	}
+	if program != nil {
+		index.addDeclarations("", program.Declarations())
+	}
+
+	return index
+}
+
+func (index *functionIndex) add(name string, element ast.HasPosition) {
+	index.ranges = append(index.ranges, functionRange{
+		name:  name,
+		start: element.StartPosition().Offset,
+		end:   element.EndPosition(nil).Offset,
+	})
+}
+
+func (index *functionIndex) addMembers(prefix string, members *ast.Members) {
+	if members == nil {
+		return
+	}
+
+	for _, function := range members.Functions() {
+		index.add(prefix+function.Identifier.Identifier, function)
+	}
+
+	for _, function := range members.SpecialFunctions() {
+		if function.FunctionDeclaration == nil {
+			continue
+		}
+		index.add(prefix+function.Kind.Keywords(), function.FunctionDeclaration)
+	}
+
+	for _, composite := range members.Composites() {
+		index.addMembers(
+			prefix+composite.Identifier.Identifier+".",
+			composite.Members)
+	}
+
+	for _, attachment := range members.Attachments() {
+		index.addMembers(
+			prefix+attachment.Identifier.Identifier+".",
+			attachment.Members)
+	}
+
+	for _, inter := range members.Interfaces() {
+		index.addMembers(
+			prefix+inter.Identifier.Identifier+".",
+			inter.Members)
+	}
+}
+
+func (index *functionIndex) addDeclarations(
+	prefix string,
+	declarations []ast.Declaration,
+) {
+	for _, declaration := range declarations {
+		switch declaration := declaration.(type) {
+		case *ast.FunctionDeclaration:
+			index.add(prefix+declaration.Identifier.Identifier, declaration)
+		case *ast.CompositeDeclaration:
+			index.addMembers(
+				prefix+declaration.Identifier.Identifier+".",
+				declaration.Members)
+		case *ast.AttachmentDeclaration:
+			index.addMembers(
+				prefix+declaration.Identifier.Identifier+".",
+				declaration.Members)
+		case *ast.InterfaceDeclaration:
+			index.addMembers(
+				prefix+declaration.Identifier.Identifier+".",
+				declaration.Members)
+		case *ast.TransactionDeclaration:
+			// pre and post conditions are attributed to the transaction
+			// itself.
+			index.add("transaction", declaration)
+			if declaration.Prepare != nil {
+				index.add(
+					"transaction.prepare",
+					declaration.Prepare.FunctionDeclaration)
+			}
+			if declaration.Execute != nil {
+				index.add(
+					"transaction.execute",
+					declaration.Execute.FunctionDeclaration)
+			}
+		}
+	}
+}
+
+// functionAt returns the name of the innermost function declared around the
+// given offset.
+func (index *functionIndex) functionAt(offset int) string {
+	name, ok := index.offsets[offset]
+	if ok {
+		return name
+	}
+
+	name = unknownProfileFunction
+	start := -1
+	for _, r := range index.ranges {
+		if r.start <= offset && offset <= r.end && r.start > start {
+			name = r.name
+			start = r.start
+		}
+	}
+
+	index.offsets[offset] = name
+	return name
+}
+
+// ComputationProfiler attributes the computation and memory usage metered
+// by the fvm to the Cadence (contract, function) call stacks which caused
+// it, and exports the result as a pprof profile.
+//
+// Profiling is opt-in (see fvm.WithComputationProfiler), since observing every
+// executed statement slows down execution.  The profiler aggregates all
+// procedures executed with it; attributions are only meaningful when these
+// procedures are executed one at a time.
+type ComputationProfiler struct {
+	mutex sync.Mutex
+
+	computationWeights meter.ExecutionEffortWeights
+	memoryWeights      meter.ExecutionMemoryWeights
+
+	root    *profileNode
+	current *profileNode
+
+	// pendingStatements is the statement computation metered right before
+	// the statement is observed.  It is attributed to the statement's stack.
+	pendingStatements uint
+
+	functions map[*ast.Program]*functionIndex
+}
+
+var _ reusableRuntime.StatementObserver = &ComputationProfiler{}
+
+func NewComputationProfiler() *ComputationProfiler {
+	root := &profileNode{
+		children: map[profileFrame]*profileNode{},
+	}
+
+	return &ComputationProfiler{
+		computationWeights: meter.DefaultComputationWeights,
+		memoryWeights:      meter.DefaultMemoryWeights,
+		root:               root,
+		current:            root,
+		functions:          map[*ast.Program]*functionIndex{},
+	}
+}
+
+// startProcedure attributes subsequently metered usage to no call stack,
+// until the first statement of the procedure which is about to be executed
+// is observed.  Otherwise, usage metered before that statement would be
+// attributed to the last statement of the previous procedure.
+func (profiler *ComputationProfiler) startProcedure() {
+	profiler.mutex.Lock()
+	defer profiler.mutex.Unlock()
+
+	profiler.attributeComputation(
+		common.ComputationKindStatement,
+		profiler.pendingStatements)
+	profiler.pendingStatements = 0
+	profiler.current = profiler.root
+}
+
+// SetMeterParameters sets the computation and memory weights used to
+// attribute subsequently metered usage.
+func (profiler *ComputationProfiler) SetMeterParameters(
+	params meter.MeterParameters,
+) {
+	profiler.mutex.Lock()
+	defer profiler.mutex.Unlock()
+
+	profiler.computationWeights = params.ComputationWeights()
+	profiler.memoryWeights = params.MemoryWeights()
+}
+
+func (profiler *ComputationProfiler) frameAt(
+	inter *interpreter.Interpreter,
+	position ast.HasPosition,
+) profileFrame {
+	frame := profileFrame{
+		location: "<unknown>",
+		function: unknownProfileFunction,
+	}
+
+	if inter.Location != nil {
+		frame.location = inter.Location.ID()
+	}
+
+	if position == nil {
+		return frame
+	}
+
+	start := position.StartPosition()
+	frame.line = start.Line
+
+	if inter.Program == nil {
+		return frame
+	}
+
+	index, ok := profiler.functions[inter.Program.Program]
+	if !ok {
+		index = newFunctionIndex(inter.Program.Program)
+		profiler.functions[inter.Program.Program] = index
+	}
+
+	frame.function = index.functionAt(start.Offset)
+	return frame
+}
+
+// OnStatement sets the call stack subsequently metered usage is attributed
+// to.  The stack is made of the call sites of the interpreter's invocations,
+// followed by the statement which is about to be executed.
+func (profiler *ComputationProfiler) OnStatement(
+	inter *interpreter.Interpreter,
+	statement ast.Statement,
+) {
+	profiler.mutex.Lock()
+	defer profiler.mutex.Unlock()
+
+	node := profiler.root
+	for _, invocation := range inter.CallStack() {
+		if invocation.Interpreter == nil ||
+			invocation.LocationRange.HasPosition == nil {
+			continue
+		}
+
+		node = node.child(profiler.frameAt(
+			invocation.Interpreter,
+			invocation.LocationRange.HasPosition))
+	}
+
+	node = node.child(profiler.frameAt(inter, statement))
+
+	profiler.current = node
+	profiler.attributeComputation(
+		common.ComputationKindStatement,
+		profiler.pendingStatements)
+	profiler.pendingStatements = 0
+}
+
+func (profiler *ComputationProfiler) attributeComputation(
+	kind common.ComputationKind,
+	intensity uint,
+) {
+	weight, ok := profiler.computationWeights[kind]
+	if !ok {
+		return
+	}
+
+	profiler.current.computation += weight * uint64(intensity)
+}
+
+func (profiler *ComputationProfiler) meterComputation(
+	kind common.ComputationKind,
+	intensity uint,
+) {
+	profiler.mutex.Lock()
+	defer profiler.mutex.Unlock()
+
+	// Cadence meters a statement right before notifying the observer about
+	// it.  Defer the attribution so that the statement's computation is
+	// attributed to the statement's call stack.
+	if kind == common.ComputationKindStatement {
+		profiler.pendingStatements += intensity
+		return
+	}
+
+	profiler.attributeComputation(kind, intensity)
+}
+
+func (profiler *ComputationProfiler) meterMemory(usage common.MemoryUsage) {
+	profiler.mutex.Lock()
+	defer profiler.mutex.Unlock()
+
+	weight, ok := profiler.memoryWeights[usage.Kind]
+	if !ok {
+		return
+	}
+
+	profiler.current.memory += weight * usage.Amount
+}
+
+// Profile returns the usage attributed so far as a pprof profile with two
+// sample types: computation and memory.
+func (profiler *ComputationProfiler) Profile() *profile.Profile {
+	profiler.mutex.Lock()
+	defer profiler.mutex.Unlock()
+
+	profiler.attributeComputation(
+		common.ComputationKindStatement,
+		profiler.pendingStatements)
+	profiler.pendingStatements = 0
+
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "computation", Unit: ComputationProfileComputationUnit},
+			{Type: "memory", Unit: ComputationProfileMemoryUnit},
+		},
+		DefaultSampleType: "computation",
+	}
+
+	functions := map[profileFrame]*profile.Function{}
+	locations := map[profileFrame]*profile.Location{}
+
+	locationFor := func(frame profileFrame) *profile.Location {
+		location, ok := locations[frame]
+		if ok {
+			return location
+		}
+
+		functionFrame := profileFrame{
+			location: frame.location,
+			function: frame.function,
+		}
+		function, ok := functions[functionFrame]
+		if !ok {
+			function = &profile.Function{
+				ID:         uint64(len(p.Function) + 1),
+				Name:       fmt.Sprintf("%s.%s", frame.location, frame.function),
+				SystemName: frame.function,
+				Filename:   frame.location,
+			}
+			functions[functionFrame] = function
+			p.Function = append(p.Function, function)
+		}
+
+		location = &profile.Location{
+			ID: uint64(len(p.Location) + 1),
+			Line: []profile.Line{
+				{
+					Function: function,
+					Line:     int64(frame.line),
+				},
+			},
+		}
+		locations[frame] = location
+		p.Location = append(p.Location, location)
+
+		return location
+	}
+
+	var visit func(node *profileNode)
+	visit = func(node *profileNode) {
+		if node != profiler.root &&
+			(node.computation > 0 || node.memory > 0) {
+
+			// pprof stacks are ordered from the leaf to the root.
+			var stack []*profile.Location
+			for n := node; n != profiler.root; n = n.parent {
+				stack = append(stack, locationFor(n.frame))
+			}
+
+			p.Sample = append(p.Sample, &profile.Sample{
+				Location: stack,
+				Value: []int64{
+					int64(node.computation),
+					int64(node.memory),
+				},
+			})
+		}
+
+		// visit the children in a deterministic order.
+		children := make([]*profileNode, 0, len(node.children))
+		for _, child := range node.children {
+			children = append(children, child)
+		}
+		sort.Slice(children, func(i, j int) bool {
+			a := children[i].frame
+			b := children[j].frame
+			if a.location != b.location {
+				return a.location < b.location
+			}
+			if a.function != b.function {
+				return a.function < b.function
+			}
+			return a.line < b.line
+		})
+
+		for _, child := range children {
+			visit(child)
+		}
+	}
+	visit(profiler.root)
+
+	// usage metered outside of any Cadence statement (e.g., by the fvm
+	// before the transaction body is executed) has no call stack.
+	if profiler.root.computation > 0 || profiler.root.memory > 0 {
+		p.Sample = append(p.Sample, &profile.Sample{
+			Location: []*profile.Location{
+				locationFor(profileFrame{
+					location: "<fvm>",
+					function: unknownProfileFunction,
+				}),
+			},
+			Value: []int64{
+				int64(profiler.root.computation),
+				int64(profiler.root.memory),
+			},
+		})
+	}
+
+	return p
+}
+
+// WriteProfile writes the usage attributed so far to w, as a gzip compressed
+// pprof protobuf.
+func (profiler *ComputationProfiler) WriteProfile(w io.Writer) error {
+	err := profiler.Profile().Write(w)
+	if err != nil {
+		return fmt.Errorf("failed to write computation profile: %w", err)
+	}
+	return nil
+}
+
+// profilingMeter reports all metered usage to the profiler before metering
+// it.
+type profilingMeter struct {
+	Meter
+
+	profiler *ComputationProfiler
+}
+
+func newProfilingMeter(meter Meter, profiler *ComputationProfiler) Meter {
+	return &profilingMeter{
+		Meter:    meter,
+		profiler: profiler,
+	}
+}
+
+func (meter *profilingMeter) MeterComputation(
+	kind common.ComputationKind,
+	intensity uint,
+) error {
+	meter.profiler.meterComputation(kind, intensity)
+	return meter.Meter.MeterComputation(kind, intensity)
+}
+
+func (meter *profilingMeter) MeterMemory(usage common.MemoryUsage) error {
+	meter.profiler.meterMemory(usage)
+	return meter.Meter.MeterMemory(usage)
+}
@@ -0,0 +1,37 @@
+package environment
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputationProfiler_StartProcedure(t *testing.T) {
+	profiler := NewComputationProfiler()
+
+	// the last statement observed while executing the previous procedure
+	last := profiler.root.child(profileFrame{
+		location: "s.0000000000000000000000000000000000000000000000000000000000000000",
+		function: "main",
+		line:     3,
+	})
+	profiler.current = last
+	profiler.meterComputation(common.ComputationKindStatement, 1)
+
+	profiler.startProcedure()
+
+	// the pending statement is attributed to the previous procedure
+	require.Greater(t, last.computation, uint64(0))
+	statement := last.computation
+
+	// usage metered before the first statement of the next procedure is
+	// attributed to no call stack
+	profiler.meterComputation(common.ComputationKindFunctionInvocation, 1)
+	profiler.meterMemory(common.MemoryUsage{Kind: common.MemoryKindStringValue, Amount: 1})
+
+	require.Equal(t, statement, last.computation)
+	require.Equal(t, uint64(0), last.memory)
+	require.Greater(t, profiler.root.computation, uint64(0))
+	require.Greater(t, profiler.root.memory, uint64(0))
+}
@@ -31,6 +31,8 @@ type Environment interface {
 
 	TransactionInfo
 
+	VerifiableRandomGenerator
+
 	// ProgramLogger
 	Logger() *zerolog.Logger
 	Logs() []string
@@ -97,8 +99,13 @@ type EnvironmentParams struct {
 
 	BlockInfoParams
 	TransactionInfoParams
+	RandomnessParams
 
 	ContractUpdaterParams
+
+	// ComputationProfiler, when set, profiles the computation and memory
+	// usage of the executed procedures.
+	ComputationProfiler *ComputationProfiler
 }
 
 func DefaultEnvironmentParams() EnvironmentParams {
@@ -111,6 +118,7 @@ func DefaultEnvironmentParams() EnvironmentParams {
 		EventEmitterParams:    DefaultEventEmitterParams(),
 		BlockInfoParams:       DefaultBlockInfoParams(),
 		TransactionInfoParams: DefaultTransactionInfoParams(),
+		RandomnessParams:      DefaultRandomnessParams(),
 		ContractUpdaterParams: DefaultContractUpdaterParams(),
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/interpreter"
 
+	"github.com/onflow/flow-go/fvm/runtime"
 	"github.com/onflow/flow-go/fvm/storage"
 	"github.com/onflow/flow-go/fvm/storage/snapshot"
 	"github.com/onflow/flow-go/fvm/storage/state"
@@ -13,6 +14,7 @@ import (
 )
 
 var _ Environment = &facadeEnvironment{}
+var _ runtime.ObservedEnvironment = &facadeEnvironment{}
 
 // facadeEnvironment exposes various fvm business logic as a single interface.
 type facadeEnvironment struct {
@@ -25,6 +27,7 @@ type facadeEnvironment struct {
 	EventEmitter
 
 	UnsafeRandomGenerator
+	VerifiableRandomGenerator
 	CryptoLibrary
 
 	BlockInfo
@@ -48,6 +51,8 @@ type facadeEnvironment struct {
 
 	accounts Accounts
 	txnState storage.TransactionPreparer
+
+	computationProfiler *ComputationProfiler
 }
 
 func newFacadeEnvironment(
@@ -56,6 +61,11 @@ func newFacadeEnvironment(
 	txnState storage.TransactionPreparer,
 	meter Meter,
 ) *facadeEnvironment {
+	if params.ComputationProfiler != nil {
+		params.ComputationProfiler.startProcedure()
+		meter = newProfilingMeter(meter, params.ComputationProfiler)
+	}
+
 	accounts := NewAccounts(txnState)
 	logger := NewProgramLogger(tracer, params.ProgramLoggerParams)
 	runtime := NewRuntime(params.RuntimeParams)
@@ -79,6 +89,12 @@ func newFacadeEnvironment(
 			params.BlockHeader,
 			params.TxIndex,
 		),
+		VerifiableRandomGenerator: NewVerifiableRandomGenerator(
+			tracer,
+			params.RandomnessParams,
+			params.BlockHeader,
+			params.TxIndex,
+		),
 		CryptoLibrary: NewCryptoLibrary(tracer, meter),
 
 		BlockInfo: NewBlockInfo(
@@ -133,6 +149,8 @@ func newFacadeEnvironment(
 
 		accounts: accounts,
 		txnState: txnState,
+
+		computationProfiler: params.ComputationProfiler,
 	}
 
 	env.Runtime.SetEnvironment(env)
@@ -266,6 +284,9 @@ func (env *facadeEnvironment) addParseRestrictedChecks() {
 	env.UnsafeRandomGenerator = NewParseRestrictedUnsafeRandomGenerator(
 		env.txnState,
 		env.UnsafeRandomGenerator)
+	env.VerifiableRandomGenerator = NewParseRestrictedVerifiableRandomGenerator(
+		env.txnState,
+		env.VerifiableRandomGenerator)
 	env.UUIDGenerator = NewParseRestrictedUUIDGenerator(
 		env.txnState,
 		env.UUIDGenerator)
@@ -303,3 +324,11 @@ func (env *facadeEnvironment) SetInterpreterSharedState(state *interpreter.Share
 func (env *facadeEnvironment) GetInterpreterSharedState() *interpreter.SharedState {
 	return nil
 }
+
+// StatementObserver returns the computation profiler, if profiling is enabled.
+func (env *facadeEnvironment) StatementObserver() runtime.StatementObserver {
+	if env.computationProfiler == nil {
+		return nil
+	}
+	return env.computationProfiler
+}
@@ -0,0 +1,173 @@
+package environment
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/onflow/flow-go/crypto/random"
+	"github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/fvm/storage/state"
+	"github.com/onflow/flow-go/fvm/tracing"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/trace"
+	"github.com/onflow/flow-go/state/protocol/seed"
+)
+
+// EntropyProvider provides the source of randomness of the executed block.
+//
+// protocol.Snapshot implements EntropyProvider: the source of randomness of a
+// block is the random beacon signature in the QC certifying it, which the
+// block proposer cannot bias.
+type EntropyProvider interface {
+	// RandomSource returns the source of randomness of the executed block.
+	RandomSource() ([]byte, error)
+}
+
+type VerifiableRandomGenerator interface {
+	// VerifiableRandom returns a random uint64 derived from the block's source
+	// of randomness.
+	VerifiableRandom() (uint64, error)
+
+	// VerifiableRandomEnabled returns whether verifiable randomness is
+	// available.  The Cadence verifiableRandom function is only declared when
+	// it is.
+	VerifiableRandomEnabled() bool
+}
+
+type RandomnessParams struct {
+	// EntropyProvider provides the source of randomness of the executed block.
+	EntropyProvider EntropyProvider
+
+	// VerifiableRandomnessHeight is the first block height at which
+	// verifiable randomness is available to Cadence.
+	VerifiableRandomnessHeight uint64
+}
+
+func DefaultRandomnessParams() RandomnessParams {
+	return RandomnessParams{
+		EntropyProvider:            nil,
+		VerifiableRandomnessHeight: math.MaxUint64,
+	}
+}
+
+type verifiableRandomGenerator struct {
+	tracer tracing.TracerSpan
+
+	entropyProvider EntropyProvider
+	txnIndex        uint32
+	enabled         bool
+
+	prg        random.Rand
+	createOnce sync.Once
+	createErr  error
+}
+
+type ParseRestrictedVerifiableRandomGenerator struct {
+	txnState state.NestedTransactionPreparer
+	impl     VerifiableRandomGenerator
+}
+
+func NewParseRestrictedVerifiableRandomGenerator(
+	txnState state.NestedTransactionPreparer,
+	impl VerifiableRandomGenerator,
+) VerifiableRandomGenerator {
+	return ParseRestrictedVerifiableRandomGenerator{
+		txnState: txnState,
+		impl:     impl,
+	}
+}
+
+func (gen ParseRestrictedVerifiableRandomGenerator) VerifiableRandom() (
+	uint64,
+	error,
+) {
+	return parseRestrict1Ret(
+		gen.txnState,
+		trace.FVMEnvVerifiableRandom,
+		gen.impl.VerifiableRandom)
+}
+
+func (gen ParseRestrictedVerifiableRandomGenerator) VerifiableRandomEnabled() bool {
+	return gen.impl.VerifiableRandomEnabled()
+}
+
+// NewVerifiableRandomGenerator returns a generator which derives the
+// randomness of the transaction at txnIndex from the block's source of
+// randomness.  The generator is only enabled for blocks at or above the
+// verifiable randomness height.
+func NewVerifiableRandomGenerator(
+	tracer tracing.TracerSpan,
+	params RandomnessParams,
+	blockHeader *flow.Header,
+	txnIndex uint32,
+) VerifiableRandomGenerator {
+	enabled := params.EntropyProvider != nil &&
+		blockHeader != nil &&
+		blockHeader.Height >= params.VerifiableRandomnessHeight
+
+	return &verifiableRandomGenerator{
+		tracer:          tracer,
+		entropyProvider: params.EntropyProvider,
+		txnIndex:        txnIndex,
+		enabled:         enabled,
+	}
+}
+
+func (gen *verifiableRandomGenerator) createRandomGenerator() (
+	random.Rand,
+	error,
+) {
+	source, err := gen.entropyProvider.RandomSource()
+	if err != nil {
+		return nil, fmt.Errorf("could not get source of randomness: %w", err)
+	}
+
+	// Each transaction gets its own PRG, customized by the transaction index,
+	// so transactions of the same block observe independent randoms.
+	prg, err := seed.PRGFromRandomSource(
+		source,
+		seed.ExecutionTransaction(gen.txnIndex))
+	if err != nil {
+		return nil, fmt.Errorf("creating random generator failed: %w", err)
+	}
+
+	return prg, nil
+}
+
+// maybeCreateRandomGenerator lazily seeds the PRG, since few transactions use
+// randomness, and reading the source of randomness is not free.
+func (gen *verifiableRandomGenerator) maybeCreateRandomGenerator() error {
+	gen.createOnce.Do(func() {
+		gen.prg, gen.createErr = gen.createRandomGenerator()
+	})
+
+	return gen.createErr
+}
+
+func (gen *verifiableRandomGenerator) VerifiableRandomEnabled() bool {
+	return gen.enabled
+}
+
+// VerifiableRandom returns a random uint64 using the transaction's PRG.  This
+// is not thread safe, which is Ok because a single transaction has a single
+// VerifiableRandomGenerator and is run in a single thread.
+func (gen *verifiableRandomGenerator) VerifiableRandom() (uint64, error) {
+	defer gen.tracer.StartExtensiveTracingChildSpan(
+		trace.FVMEnvVerifiableRandom).End()
+
+	if !gen.enabled {
+		return 0, errors.NewOperationNotSupportedError("VerifiableRandom")
+	}
+
+	// The internal seeding is only done once.
+	err := gen.maybeCreateRandomGenerator()
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 8)
+	gen.prg.Read(buf) // Note: prg.Read does not return error
+	return binary.LittleEndian.Uint64(buf), nil
+}
@@ -0,0 +1,129 @@
+package environment_test
+
+import (
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/fvm/environment"
+	"github.com/onflow/flow-go/fvm/environment/mock"
+	"github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/fvm/tracing"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol/seed"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestVerifiableRandomGenerator(t *testing.T) {
+	bh := unittest.BlockHeaderFixtureOnChain(flow.Mainnet.Chain().ChainID())
+	source := unittest.SeedFixture(seed.RandomSourceLength)
+
+	entropyProvider := &mock.EntropyProvider{}
+	entropyProvider.On("RandomSource").Return(source, nil)
+
+	params := environment.RandomnessParams{
+		EntropyProvider:            entropyProvider,
+		VerifiableRandomnessHeight: bh.Height,
+	}
+
+	getRandoms := func(txnIndex uint32, N int) []uint64 {
+		// seed the RG with the same source of randomness
+		vrg := environment.NewVerifiableRandomGenerator(
+			tracing.NewTracerSpan(),
+			params,
+			bh,
+			txnIndex)
+		numbers := make([]uint64, N)
+		for i := 0; i < N; i++ {
+			u, err := vrg.VerifiableRandom()
+			require.NoError(t, err)
+			numbers[i] = u
+		}
+		return numbers
+	}
+
+	// basic randomness test to check outputs are "uniformly" spread over the
+	// output space
+	t.Run("randomness test", func(t *testing.T) {
+		for txnIndex := uint32(0); txnIndex < 10; txnIndex++ {
+			vrg := environment.NewVerifiableRandomGenerator(
+				tracing.NewTracerSpan(),
+				params,
+				bh,
+				txnIndex)
+
+			// make sure n is a power of 2 so that there is no bias in the last class
+			// n is a random power of 2 (from 2 to 2^10)
+			n := 1 << (1 + mrand.Intn(10))
+			classWidth := (math.MaxUint64 / uint64(n)) + 1
+			BasicDistributionTest(t, uint64(n), uint64(classWidth), vrg.VerifiableRandom)
+		}
+	})
+
+	// tests that verifiableRandom is PRG based and hence has deterministic outputs.
+	t.Run("PRG-based VerifiableRandom", func(t *testing.T) {
+		for txnIndex := uint32(0); txnIndex < 10; txnIndex++ {
+			N := 100
+			r1 := getRandoms(txnIndex, N)
+			r2 := getRandoms(txnIndex, N)
+			require.Equal(t, r1, r2)
+		}
+	})
+
+	t.Run("transaction specific randomness", func(t *testing.T) {
+		txns := [][]uint64{}
+		for txnIndex := uint32(0); txnIndex < 10; txnIndex++ {
+			N := 100
+			txns = append(txns, getRandoms(txnIndex, N))
+		}
+
+		for i, txn := range txns {
+			for _, otherTxn := range txns[i+1:] {
+				require.NotEqual(t, txn, otherTxn)
+			}
+		}
+	})
+
+	t.Run("disabled below activation height", func(t *testing.T) {
+		params := environment.RandomnessParams{
+			EntropyProvider:            entropyProvider,
+			VerifiableRandomnessHeight: bh.Height + 1,
+		}
+		vrg := environment.NewVerifiableRandomGenerator(
+			tracing.NewTracerSpan(),
+			params,
+			bh,
+			0)
+
+		_, err := vrg.VerifiableRandom()
+		require.True(t, errors.IsOperationNotSupportedError(err))
+	})
+
+	t.Run("disabled without entropy provider", func(t *testing.T) {
+		vrg := environment.NewVerifiableRandomGenerator(
+			tracing.NewTracerSpan(),
+			environment.DefaultRandomnessParams(),
+			bh,
+			0)
+
+		_, err := vrg.VerifiableRandom()
+		require.True(t, errors.IsOperationNotSupportedError(err))
+	})
+
+	t.Run("entropy provider failure", func(t *testing.T) {
+		failing := &mock.EntropyProvider{}
+		failing.On("RandomSource").Return(nil, fmt.Errorf("no source"))
+
+		vrg := environment.NewVerifiableRandomGenerator(
+			tracing.NewTracerSpan(),
+			environment.RandomnessParams{EntropyProvider: failing},
+			bh,
+			0)
+
+		_, err := vrg.VerifiableRandom()
+		require.Error(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package fvm_test
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math"
@@ -24,6 +25,7 @@ import (
 	errors "github.com/onflow/flow-go/fvm/errors"
 	"github.com/onflow/flow-go/fvm/storage/snapshot"
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol/seed"
 	"github.com/onflow/flow-go/utils/unittest"
 )
 
@@ -1706,6 +1708,114 @@ func TestBlockContext_UnsafeRandom(t *testing.T) {
 	})
 }
 
+func TestBlockContext_VerifiableRandom(t *testing.T) {
+
+	t.Parallel()
+
+	chain, vm := createChainAndVm(flow.Mainnet)
+
+	header := &flow.Header{Height: 42}
+
+	source := unittest.SeedFixture(seed.RandomSourceLength)
+	entropyProvider := &envMock.EntropyProvider{}
+	entropyProvider.On("RandomSource").Return(source, nil)
+
+	txBody := flow.NewTransactionBody().
+		SetScript([]byte(`
+            transaction {
+                execute {
+                    let rand = verifiableRandom()
+                    log(rand)
+                }
+            }
+        `))
+
+	err := testutil.SignTransactionAsServiceAccount(txBody, 0, chain)
+	require.NoError(t, err)
+
+	t.Run("works as transaction", func(t *testing.T) {
+		ctx := fvm.NewContext(
+			fvm.WithChain(chain),
+			fvm.WithBlockHeader(header),
+			fvm.WithCadenceLogging(true),
+			fvm.WithEntropyProvider(entropyProvider),
+			fvm.WithVerifiableRandomnessHeight(header.Height),
+		)
+
+		_, output, err := vm.Run(
+			ctx,
+			fvm.Transaction(txBody, 0),
+			testutil.RootBootstrappedLedger(vm, ctx))
+		require.NoError(t, err)
+		require.NoError(t, output.Err)
+
+		require.Len(t, output.Logs, 1)
+
+		num, err := strconv.ParseUint(output.Logs[0], 10, 64)
+		require.NoError(t, err)
+
+		// the transaction observes the first output of the PRG seeded by the
+		// source of randomness and customized by the transaction index
+		prg, err := seed.PRGFromRandomSource(source, seed.ExecutionTransaction(0))
+		require.NoError(t, err)
+		buf := make([]byte, 8)
+		prg.Read(buf)
+		require.Equal(t, binary.LittleEndian.Uint64(buf), num)
+	})
+
+	t.Run("fails before activation height", func(t *testing.T) {
+		ctx := fvm.NewContext(
+			fvm.WithChain(chain),
+			fvm.WithBlockHeader(header),
+			fvm.WithCadenceLogging(true),
+			fvm.WithEntropyProvider(entropyProvider),
+			fvm.WithVerifiableRandomnessHeight(header.Height+1),
+		)
+
+		_, output, err := vm.Run(
+			ctx,
+			fvm.Transaction(txBody, 0),
+			testutil.RootBootstrappedLedger(vm, ctx))
+		require.NoError(t, err)
+		require.Error(t, output.Err)
+		require.Contains(t, output.Err.Error(), "cannot find variable in this scope: `verifiableRandom`")
+	})
+
+	t.Run("identifier can be declared before activation height", func(t *testing.T) {
+		ctx := fvm.NewContext(
+			fvm.WithChain(chain),
+			fvm.WithBlockHeader(header),
+			fvm.WithCadenceLogging(true),
+			fvm.WithEntropyProvider(entropyProvider),
+			fvm.WithVerifiableRandomnessHeight(header.Height+1),
+		)
+
+		txBody := flow.NewTransactionBody().
+			SetScript([]byte(`
+                pub fun verifiableRandom(): UInt64 {
+                    return 7
+                }
+
+                transaction {
+                    execute {
+                        log(verifiableRandom())
+                    }
+                }
+            `))
+
+		err := testutil.SignTransactionAsServiceAccount(txBody, 0, chain)
+		require.NoError(t, err)
+
+		_, output, err := vm.Run(
+			ctx,
+			fvm.Transaction(txBody, 0),
+			testutil.RootBootstrappedLedger(vm, ctx))
+		require.NoError(t, err)
+		require.NoError(t, output.Err)
+		require.Equal(t, []string{"7"}, output.Logs)
+	})
+}
+
 func TestBlockContext_ExecuteTransaction_CreateAccount_WithMonotonicAddresses(t *testing.T) {
 
 	t.Parallel()
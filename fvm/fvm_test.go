@@ -2448,3 +2448,74 @@ func TestAttachments(t *testing.T) {
 		test(t, false)
 	})
 }
+
+func TestComputationProfiler(t *testing.T) {
+	profiler := environment.NewComputationProfiler()
+
+	newVMTest().
+		withContextOptions(
+			fvm.WithComputationProfiler(profiler),
+		).
+		run(
+			func(
+				t *testing.T,
+				vm fvm.VM,
+				chain flow.Chain,
+				ctx fvm.Context,
+				snapshotTree snapshot.SnapshotTree,
+			) {
+				script := fvm.Script([]byte(`
+					pub fun fib(_ n: Int): Int {
+						if n < 2 {
+							return n
+						}
+						return fib(n - 1) + fib(n - 2)
+					}
+
+					pub fun main(): Int {
+						var i = 0
+						while i < 3 {
+							i = i + 1
+						}
+						return fib(10)
+					}
+				`))
+
+				_, output, err := vm.Run(ctx, script, snapshotTree)
+				require.NoError(t, err)
+				require.NoError(t, output.Err)
+
+				p := profiler.Profile()
+				require.NoError(t, p.CheckValid())
+				require.Equal(t, "computation", p.SampleType[0].Type)
+				require.Equal(t, "memory", p.SampleType[1].Type)
+
+				scriptLocation := common.ScriptLocation(script.ID).ID()
+
+				computation := map[string]int64{}
+				for _, sample := range p.Sample {
+					var stack []string
+					for _, location := range sample.Location {
+						stack = append(stack, location.Line[0].Function.Name)
+					}
+					computation[strings.Join(stack, ";")] += sample.Value[0]
+				}
+
+				main := scriptLocation + ".main"
+				fib := scriptLocation + ".fib"
+
+				require.Greater(t, computation[main], int64(0))
+				require.Greater(t, computation[fib+";"+main], int64(0))
+				require.Greater(t, computation[fib+";"+fib+";"+main], int64(0))
+
+				// the recursive calls use more computation than the main
+				// function's loop.
+				fibComputation := int64(0)
+				for stack, value := range computation {
+					if strings.HasPrefix(stack, fib+";") {
+						fibComputation += value
+					}
+				}
+				require.Greater(t, fibComputation, computation[main])
+			})(t)
+}
@@ -3,34 +3,179 @@ package runtime
 import (
 	"github.com/onflow/cadence"
 	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/ast"
 	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
 	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
 )
 
 // Note: this is a subset of environment.Environment, redeclared to handle
 // circular dependency.
 type Environment interface {
 	runtime.Interface
+
+	// VerifiableRandom returns a random uint64 derived from the block's
+	// source of randomness.
+	VerifiableRandom() (uint64, error)
+
+	// VerifiableRandomEnabled returns whether verifiable randomness is
+	// available to the executed procedure.
+	VerifiableRandomEnabled() bool
+}
+
+const verifiableRandomFunctionDocString = `
+Returns a pseudo-random number derived from the random beacon's source of randomness of the block.
+
+Unlike unsafeRandom, the block proposer cannot bias the returned numbers.
+`
+
+var verifiableRandomFunctionType = &sema.FunctionType{
+	ReturnTypeAnnotation: sema.NewTypeAnnotation(
+		sema.UInt64Type,
+	),
+}
+
+// StatementObserver is notified before every Cadence statement is executed.
+type StatementObserver interface {
+	OnStatement(inter *interpreter.Interpreter, statement ast.Statement)
+}
+
+// ObservedEnvironment is implemented by fvm environments which observe the
+// execution of Cadence statements (e.g., to profile computation).
+type ObservedEnvironment interface {
+	// StatementObserver returns the observer to notify, or nil if the
+	// environment does not observe statements.
+	StatementObserver() StatementObserver
+}
+
+// environmentKind identifies the Cadence environments of a reusable runtime.
+type environmentKind struct {
+	script bool
+
+	// verifiableRandom is whether the verifiableRandom function is declared.
+	// It is only declared when it is enabled, so that programs which declare
+	// the same identifier keep type checking until it is activated.
+	verifiableRandom bool
+}
+
+// interpreterEnvironment is a Cadence environment together with its
+// interpreter config, so that statement observers can be set.
+type interpreterEnvironment struct {
+	runtime.Environment
+	interpreterConfig *interpreter.Config
 }
 
 type ReusableCadenceRuntime struct {
 	runtime.Runtime
+
+	// Environment is the transaction environment of the fvm environment the
+	// runtime is currently borrowed by.
 	runtime.Environment
 
+	config runtime.Config
+
+	// environments are lazily initialized when they are first used.
+	environments map[environmentKind]*interpreterEnvironment
+
+	verifiableRandomEnabled bool
+	observer                StatementObserver
+
 	fvmEnv Environment
 }
 
 func NewReusableCadenceRuntime(rt runtime.Runtime, config runtime.Config) *ReusableCadenceRuntime {
 	reusable := &ReusableCadenceRuntime{
-		Runtime:     rt,
-		Environment: runtime.NewBaseInterpreterEnvironment(config),
+		Runtime:      rt,
+		config:       config,
+		environments: map[environmentKind]*interpreterEnvironment{},
 	}
 
+	reusable.Environment = reusable.environment(false).Environment
+
 	return reusable
 }
 
+// verifiableRandomFunction returns the declaration of the Cadence
+// verifiableRandom function, which is backed by the fvm environment the
+// runtime is currently borrowed by.
+func (reusable *ReusableCadenceRuntime) verifiableRandomFunction() stdlib.StandardLibraryValue {
+	return stdlib.NewStandardLibraryFunction(
+		"verifiableRandom",
+		verifiableRandomFunctionType,
+		verifiableRandomFunctionDocString,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			return interpreter.NewUInt64Value(
+				invocation.Interpreter,
+				func() uint64 {
+					var rand uint64
+					var err error
+					errors.WrapPanic(func() {
+						rand, err = reusable.fvmEnv.VerifiableRandom()
+					})
+					if err != nil {
+						panic(err)
+					}
+					return rand
+				},
+			)
+		},
+	)
+}
+
 func (reusable *ReusableCadenceRuntime) SetFvmEnvironment(fvmEnv Environment) {
 	reusable.fvmEnv = fvmEnv
+
+	reusable.observer = nil
+	if observed, ok := fvmEnv.(ObservedEnvironment); ok {
+		reusable.observer = observed.StatementObserver()
+	}
+
+	reusable.verifiableRandomEnabled = fvmEnv != nil &&
+		fvmEnv.VerifiableRandomEnabled()
+
+	reusable.Environment = reusable.environment(false).Environment
+}
+
+// environment returns the transaction or script environment matching the
+// fvm environment the runtime is currently borrowed by.
+func (reusable *ReusableCadenceRuntime) environment(
+	script bool,
+) *interpreterEnvironment {
+	kind := environmentKind{
+		script:           script,
+		verifiableRandom: reusable.verifiableRandomEnabled,
+	}
+
+	env, ok := reusable.environments[kind]
+	if !ok {
+		// Without declarations, these are equivalent to
+		// runtime.NewBaseInterpreterEnvironment and
+		// runtime.NewScriptInterpreterEnvironment, but expose the
+		// interpreter config.
+		base := runtime.NewBaseInterpreterEnvironment(reusable.config)
+		if kind.script {
+			base.Declare(stdlib.NewGetAuthAccountFunction(base))
+		}
+		if kind.verifiableRandom {
+			base.Declare(reusable.verifiableRandomFunction())
+		}
+
+		env = &interpreterEnvironment{
+			Environment:       base,
+			interpreterConfig: base.InterpreterConfig,
+		}
+		reusable.environments[kind] = env
+	}
+
+	var onStatement interpreter.OnStatementFunc
+	if reusable.observer != nil {
+		onStatement = reusable.observer.OnStatement
+	}
+	env.interpreterConfig.OnStatement = onStatement
+
+	return env
 }
 
 func (reusable *ReusableCadenceRuntime) ReadStored(
@@ -95,8 +240,9 @@ func (reusable *ReusableCadenceRuntime) ExecuteScript(
 	return reusable.Runtime.ExecuteScript(
 		script,
 		runtime.Context{
-			Interface: reusable.fvmEnv,
-			Location:  location,
+			Interface:   reusable.fvmEnv,
+			Location:    location,
+			Environment: reusable.environment(true).Environment,
 		},
 	)
 }
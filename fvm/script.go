@@ -174,6 +174,10 @@ func (executor *scriptExecutor) execute() error {
 		return fmt.Errorf("error getting meter parameters: %w", err)
 	}
 
+	if executor.ctx.ComputationProfiler != nil {
+		executor.ctx.ComputationProfiler.SetMeterParameters(meterParams)
+	}
+
 	txnId, err := executor.txnState.BeginNestedTransactionWithMeterParams(
 		meterParams)
 	if err != nil {
@@ -188,6 +188,10 @@ func (executor *transactionExecutor) preprocessTransactionBody() error {
 		return fmt.Errorf("error gettng meter parameters: %w", err)
 	}
 
+	if executor.ctx.ComputationProfiler != nil {
+		executor.ctx.ComputationProfiler.SetMeterParameters(meterParams)
+	}
+
 	txnId, err := executor.txnState.BeginNestedTransactionWithMeterParams(
 		meterParams)
 	if err != nil {
@@ -171,6 +171,7 @@ func main() {
 		},
 		// We do support only one load type for now.
 		benchmark.ConstExecParams{},
+		benchmark.ReplayParams{},
 	)
 	if err != nil {
 		log.Fatal().Err(err).Msg("unable to create new cont load generator")
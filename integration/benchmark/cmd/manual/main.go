@@ -35,7 +35,7 @@ const (
 
 func main() {
 	sleep := flag.Duration("sleep", 0, "duration to sleep before benchmarking starts")
-	loadTypeFlag := flag.String("load-type", "token-transfer", "type of loads (\"token-transfer\", \"add-keys\", \"computation-heavy\", \"event-heavy\", \"ledger-heavy\", \"const-exec\")")
+	loadTypeFlag := flag.String("load-type", "token-transfer", "type of loads (\"token-transfer\", \"add-keys\", \"computation-heavy\", \"event-heavy\", \"ledger-heavy\", \"const-exec\", \"replay\")")
 	tpsFlag := flag.String("tps", "1", "transactions per second (TPS) to send, accepts a comma separated list of values if used in conjunction with `tps-durations`")
 	tpsDurationsFlag := flag.String("tps-durations", "0", "duration that each load test will run, accepts a comma separted list that will be applied to multiple values of the `tps` flag (defaults to infinite if not provided, meaning only the first tps case will be tested; additional values will be ignored)")
 	chainIDStr := flag.String("chain", string(flowsdk.Emulator), "chain ID")
@@ -51,6 +51,9 @@ func main() {
 	authAccNumInConstExecTx := flag.Uint("const-exec-num-authorizer", 1, "num of authorizer for each constant exec transaction to generate")
 	argSizeInByteInConstExecTx := flag.Uint("const-exec-arg-size", 100, "byte size of tx argument for each constant exec transaction to generate")
 	payerKeyCountInConstExecTx := flag.Uint("const-exec-payer-key-count", 2, "num of payer keys for each constant exec transaction to generate")
+	replayTransactionsFile := flag.String("replay-transactions-file", "", "transactions JSON file exported by `util export-json-transactions` to replay with the replay load type")
+	replayTimeScale := flag.Float64("replay-time-scale", 1, "factor to scale the recorded time between replayed transactions by, e.g. 0.5 replays twice as fast")
+	replayRecordedChainIDStr := flag.String("replay-recorded-chain", string(flow.Mainnet), "chain ID of the chain the replayed transactions were recorded on, imports of core contracts are rewritten to the chain given by the chain flag")
 	flag.Parse()
 
 	chainID := flowsdk.ChainID([]byte(*chainIDStr))
@@ -143,6 +146,12 @@ func main() {
 			ArgSizeInByte:   *argSizeInByteInConstExecTx,
 			PayerKeyCount:   *payerKeyCountInConstExecTx,
 		},
+		benchmark.ReplayParams{
+			TransactionsFile: *replayTransactionsFile,
+			TimeScale:        *replayTimeScale,
+			RecordedChainID:  flow.ChainID(*replayRecordedChainIDStr),
+			ChainID:          flow.ChainID(chainID),
+		},
 	)
 	if err != nil {
 		log.Fatal().Err(err).Msg("unable to create new cont load generator")
@@ -30,6 +30,7 @@ const (
 	EventHeavyLoadType    LoadType = "event-heavy"
 	LedgerHeavyLoadType   LoadType = "ledger-heavy"
 	ConstExecCostLoadType LoadType = "const-exec" // for an empty transactions with various tx arguments
+	ReplayLoadType        LoadType = "replay"     // for a recorded transaction mix
 )
 
 const lostTransactionThreshold = 90 * time.Second
@@ -60,6 +61,7 @@ type ContLoadGenerator struct {
 	loadParams         LoadParams
 	networkParams      NetworkParams
 	constExecParams    ConstExecParams
+	replayer           *replayer
 	flowClient         access.Client
 	serviceAccount     *account.FlowAccount
 	favContractAddress *flowsdk.Address
@@ -101,6 +103,7 @@ func New(
 	networkParams NetworkParams,
 	loadParams LoadParams,
 	constExecParams ConstExecParams,
+	replayParams ReplayParams,
 ) (*ContLoadGenerator, error) {
 	if len(flowClients) == 0 {
 		return nil, errors.New("no flow clients available")
@@ -153,6 +156,37 @@ func New(
 		}
 	}
 
+	var replay *replayer
+	if loadParams.LoadType == ReplayLoadType {
+		imports, err := newReplayImports(replayParams.RecordedChainID, replayParams.ChainID)
+		if err != nil {
+			return nil, fmt.Errorf("could not map imports of transactions to replay: %w", err)
+		}
+		schedule, err := readReplayTransactions(replayParams.TransactionsFile, replayParams.TimeScale, imports)
+		if err != nil {
+			return nil, fmt.Errorf("could not read transactions to replay: %w", err)
+		}
+		replay = newReplayer(log.With().Str("component", "replayer").Logger(), schedule)
+
+		if replay.maxAuthorizers() > loadParams.NumberOfAccounts {
+			errMsg := fmt.Sprintf("Number of authorizers(%d) of a recorded transaction is larger than the number of accounts(%d).",
+				replay.maxAuthorizers(),
+				loadParams.NumberOfAccounts)
+			log.Error().Msg(errMsg)
+			return nil, errors.New(errMsg)
+		}
+		unmapped := 0
+		for _, tx := range schedule {
+			if tx.unmappedImports {
+				unmapped++
+			}
+		}
+		log.Info().
+			Int("transactions", len(schedule)).
+			Int("unmapped_imports", unmapped).
+			Msg("transactions to replay loaded, transactions importing contracts which aren't core contracts are skipped")
+	}
+
 	lg := &ContLoadGenerator{
 		ctx:                ctx,
 		log:                log,
@@ -160,6 +194,7 @@ func New(
 		loadParams:         loadParams,
 		networkParams:      networkParams,
 		constExecParams:    constExecParams,
+		replayer:           replay,
 		flowClient:         flowClient,
 		serviceAccount:     servAcc,
 		accounts:           make([]*account.FlowAccount, 0),
@@ -181,6 +216,8 @@ func New(
 		lg.workFunc = lg.sendConstExecCostTx
 	case CompHeavyLoadType, EventHeavyLoadType, LedgerHeavyLoadType:
 		lg.workFunc = lg.sendFavContractTx
+	case ReplayLoadType:
+		lg.workFunc = lg.sendReplayTx
 	default:
 		return nil, fmt.Errorf("unknown load type: %s", loadParams.LoadType)
 	}
@@ -318,13 +355,10 @@ func (lg *ContLoadGenerator) Init() error {
 	}
 
 	// TODO(rbtz): create an interface for different load types: Setup()
-	if lg.loadParams.LoadType != ConstExecCostLoadType {
-		err := lg.setupFavContract()
-		if err != nil {
-			lg.log.Error().Err(err).Msg("failed to setup fav contract")
-			return err
-		}
-	} else {
+	switch lg.loadParams.LoadType {
+	case ReplayLoadType:
+		// recorded transactions only need the created accounts
+	case ConstExecCostLoadType:
 		lg.log.Info().Int("numberOfAccountsCreated", len(lg.accounts)).
 			Msg("new accounts created. Grabbing the first as the proposer/payer " +
 				"and adding multiple keys to that account")
@@ -334,6 +368,12 @@ func (lg *ContLoadGenerator) Init() error {
 			lg.log.Error().Msg("failed to create add-key transaction for const-exec")
 			return err
 		}
+	default:
+		err := lg.setupFavContract()
+		if err != nil {
+			lg.log.Error().Err(err).Msg("failed to setup fav contract")
+			return err
+		}
 	}
 
 	return nil
@@ -464,6 +504,19 @@ func (lg *ContLoadGenerator) Stop() {
 	lg.log.Debug().Msg("stopping workers")
 	_ = lg.unsafeSetTPS(0)
 	close(lg.stoppedChannel)
+
+	if lg.replayer != nil {
+		lg.replayer.logStats()
+	}
+}
+
+// ReplayStats returns the latency and failure statistics of the replayed transactions, by template.
+// Transactions share a template if they have the same script. Returns nil unless the load type is replay.
+func (lg *ContLoadGenerator) ReplayStats() map[string]ReplayTemplateStats {
+	if lg.replayer == nil {
+		return nil
+	}
+	return lg.replayer.getStats()
 }
 
 func (lg *ContLoadGenerator) Done() <-chan struct{} {
@@ -947,6 +1000,136 @@ func (lg *ContLoadGenerator) sendFavContractTx(workerID int) {
 	<-ch
 }
 
+// sendReplayTx sends the next recorded transaction, if it is due. The transaction is re-signed with
+// generated accounts: the proposer and payer is also the first authorizer, the remaining authorizers
+// are taken from the available accounts.
+func (lg *ContLoadGenerator) sendReplayTx(workerID int) {
+	log := lg.log.With().Int("workerID", workerID).Logger()
+
+	rtx, lag, ok := lg.replayer.nextDue(time.Now())
+	if !ok {
+		return
+	}
+	log = log.With().Str("template", rtx.template).Logger()
+	if lag > replayLagWarningThreshold {
+		log.Warn().Dur("lag", lag).Msg("replay is behind the recorded schedule, increase the tps to keep up")
+	}
+
+	if rtx.unmappedImports {
+		log.Debug().Msg("transaction imports contracts which aren't core contracts; skipping send")
+		lg.replayer.skipped(rtx.template)
+		return
+	}
+
+	log.Trace().Msg("getting next available accounts")
+
+	acc := <-lg.availableAccounts
+	defer func() { lg.availableAccounts <- acc }()
+
+	authorizers := make([]*account.FlowAccount, 0, rtx.authorizers)
+	defer func() {
+		for _, a := range authorizers {
+			lg.availableAccounts <- a
+		}
+	}()
+	for len(authorizers)+1 < rtx.authorizers {
+		select {
+		case a := <-lg.availableAccounts:
+			authorizers = append(authorizers, a)
+		default:
+			log.Error().Int("authorizers", rtx.authorizers).Msg("not enough available accounts to authorize transaction; skipping send")
+			lg.replayer.skipped(rtx.template)
+			return
+		}
+	}
+
+	log.Trace().Msg("creating transaction")
+	tx := flowsdk.NewTransaction().
+		SetReferenceBlockID(lg.follower.BlockID()).
+		SetScript(rtx.script).
+		SetGasLimit(rtx.gasLimit)
+	for _, argument := range rtx.arguments {
+		tx.AddRawArgument(argument)
+	}
+
+	key, err := acc.GetKey()
+	if err != nil {
+		log.Error().Err(err).Msg("error getting key")
+		return
+	}
+	defer key.Done()
+
+	if rtx.authorizers > 0 {
+		tx.AddAuthorizer(*acc.Address)
+	}
+	for _, a := range authorizers {
+		tx.AddAuthorizer(*a.Address)
+	}
+	tx.SetProposalKey(*key.Address, key.Index, key.SequenceNumber).
+		SetPayer(*key.Address)
+
+	log.Trace().Msg("signing transaction")
+	for _, a := range authorizers {
+		authorizerKey, err := a.GetKey()
+		if err != nil {
+			log.Error().Err(err).Msg("error getting key")
+			return
+		}
+
+		err = authorizerKey.SignPayload(tx)
+		authorizerKey.Done() // authorizers don't need to increment their sequence number
+
+		if err != nil {
+			log.Error().Err(err).Msg("error signing payload")
+			return
+		}
+	}
+
+	err = tx.SignEnvelope(*key.Address, key.Index, key.Signer)
+	if err != nil {
+		log.Error().Err(err).Msg("error signing transaction")
+		return
+	}
+
+	startTime := time.Now()
+	ch, err := lg.sendTx(workerID, tx)
+	if err != nil {
+		return
+	}
+	defer key.IncrementSequenceNumber()
+	lg.replayer.sent(rtx.template)
+
+	log = log.With().Hex("tx_id", tx.ID().Bytes()).Logger()
+	log.Trace().Msg("transaction sent")
+
+	t := time.NewTimer(lostTransactionThreshold)
+	defer t.Stop()
+
+	select {
+	case result := <-ch:
+		latency := time.Since(startTime)
+		if result.Error != nil {
+			lg.workerStatsTracker.IncTxFailed()
+		}
+		lg.replayer.executed(rtx.template, latency, result.Error != nil)
+		log.Trace().
+			Dur("duration", latency).
+			Err(result.Error).
+			Str("status", result.Status.String()).
+			Msg("transaction confirmed")
+	case <-t.C:
+		lg.loaderMetrics.TransactionLost()
+		lg.replayer.timedOut(rtx.template)
+		log.Warn().
+			Dur("duration", time.Since(startTime)).
+			Msg("transaction lost")
+		lg.workerStatsTracker.IncTxTimedout()
+	case <-lg.Done():
+		return
+	}
+	lg.workerStatsTracker.IncTxExecuted()
+}
+
 func (lg *ContLoadGenerator) sendTx(workerID int, tx *flowsdk.Transaction) (<-chan flowsdk.TransactionResult, error) {
 	log := lg.log.With().Int("workerID", workerID).Str("tx_id", tx.ID().String()).Logger()
 	log.Trace().Msg("sending transaction")
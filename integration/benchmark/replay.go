@@ -0,0 +1,406 @@
+package benchmark
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/environment"
+	"github.com/onflow/flow-go/fvm/systemcontracts"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// replayDefaultGasLimit is used for recorded transactions without a gas limit
+const replayDefaultGasLimit = 9999
+
+// replayLagWarningThreshold is how far behind the recorded schedule the replay may fall before
+// warning that more workers (a higher TPS) are needed to keep up
+const replayLagWarningThreshold = 10 * time.Second
+
+// ReplayParams hosts all parameters for the replay load type
+type ReplayParams struct {
+	// TransactionsFile is the JSON file written by `util export-json-transactions`
+	TransactionsFile string
+	// TimeScale scales the recorded time between transactions, e.g. 0.5 replays the transactions
+	// twice as fast as they were recorded. 0 is treated as 1.
+	TimeScale float64
+	// RecordedChainID is the chain the transactions were recorded on
+	RecordedChainID flow.ChainID
+	// ChainID is the chain the transactions are replayed on. Imports of core contracts are rewritten
+	// to their addresses on this chain if it differs from RecordedChainID.
+	ChainID flow.ChainID
+}
+
+// ReplayTemplateStats hosts the statistics of all replayed transactions sharing the same script
+type ReplayTemplateStats struct {
+	Sent         int
+	Executed     int
+	Failed       int
+	TimedOut     int
+	Skipped      int // not sent because not enough accounts were available to authorize them, or because they import contracts which aren't core contracts
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+// AverageLatency returns the average time from sending to execution of the executed transactions.
+func (s ReplayTemplateStats) AverageLatency() time.Duration {
+	if s.Executed == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Executed)
+}
+
+// recordedBlock is the format of a block written by `util export-json-transactions`
+type recordedBlock struct {
+	Height      uint64    `json:"height"`
+	Timestamp   time.Time `json:"timestamp"`
+	Collections []struct {
+		Transactions []recordedTransaction `json:"transactions"`
+	} `json:"collections"`
+}
+
+// recordedTransaction is the format of a transaction written by `util export-json-transactions`
+type recordedTransaction struct {
+	Script      string   `json:"script"`
+	Arguments   []string `json:"arguments"`
+	GasLimit    uint64   `json:"gas_limit"`
+	Authorizers []string `json:"authorizer_addresses"`
+}
+
+// replayTransaction is a recorded transaction scheduled for replay
+type replayTransaction struct {
+	template    string        // identifies the script of the transaction
+	offset      time.Duration // time after the start of the replay the transaction is due
+	script      []byte
+	arguments   [][]byte
+	gasLimit    uint64
+	authorizers int // number of distinct authorizers
+	// unmappedImports is set if the script imports contracts of the recorded chain which can't be
+	// mapped to the replay chain, the transaction is skipped then
+	unmappedImports bool
+}
+
+// readReplayTransactions reads the transactions from the given file written by `util export-json-transactions`.
+func readReplayTransactions(path string, timeScale float64, imports *replayImports) ([]replayTransaction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open transactions file: %w", err)
+	}
+	defer file.Close()
+
+	return decodeReplayTransactions(file, timeScale, imports)
+}
+
+// decodeReplayTransactions decodes recorded blocks and schedules their transactions relative to the
+// first block, keeping their order and the recorded time between blocks, scaled by timeScale.
+// The imports of the scripts are rewritten by the given imports, which may be nil if the transactions
+// are replayed on the chain they were recorded on.
+func decodeReplayTransactions(reader io.Reader, timeScale float64, imports *replayImports) ([]replayTransaction, error) {
+	if timeScale < 0 {
+		return nil, fmt.Errorf("time scale must not be negative, got %v", timeScale)
+	}
+	if timeScale == 0 {
+		timeScale = 1
+	}
+
+	var blocks []recordedBlock
+	err := json.NewDecoder(reader).Decode(&blocks)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode recorded transactions: %w", err)
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no recorded blocks")
+	}
+
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return blocks[i].Height < blocks[j].Height
+	})
+
+	first := blocks[0].Timestamp
+	transactions := make([]replayTransaction, 0)
+	for _, block := range blocks {
+		offset := time.Duration(float64(block.Timestamp.Sub(first)) * timeScale)
+		if offset < 0 {
+			// timestamps of consecutive blocks are not guaranteed to be increasing
+			offset = 0
+		}
+		if len(transactions) > 0 && offset < transactions[len(transactions)-1].offset {
+			offset = transactions[len(transactions)-1].offset
+		}
+
+		for _, collection := range block.Collections {
+			for _, tx := range collection.Transactions {
+				transactions = append(transactions, newReplayTransaction(tx, offset, imports))
+			}
+		}
+	}
+
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("no recorded transactions in %d blocks", len(blocks))
+	}
+
+	return transactions, nil
+}
+
+func newReplayTransaction(tx recordedTransaction, offset time.Duration, imports *replayImports) replayTransaction {
+	arguments := make([][]byte, 0, len(tx.Arguments))
+	for _, argument := range tx.Arguments {
+		arguments = append(arguments, []byte(argument))
+	}
+
+	authorizers := make(map[string]struct{}, len(tx.Authorizers))
+	for _, authorizer := range tx.Authorizers {
+		authorizers[authorizer] = struct{}{}
+	}
+
+	gasLimit := tx.GasLimit
+	if gasLimit == 0 {
+		gasLimit = replayDefaultGasLimit
+	}
+
+	// the template identifies the recorded script, so that the statistics can be related to the recording
+	hash := sha256.Sum256([]byte(tx.Script))
+
+	script := tx.Script
+	mapped := true
+	if imports != nil {
+		script, mapped = imports.rewrite(script)
+	}
+
+	return replayTransaction{
+		template:        hex.EncodeToString(hash[:8]),
+		offset:          offset,
+		script:          []byte(script),
+		arguments:       arguments,
+		gasLimit:        gasLimit,
+		authorizers:     len(authorizers),
+		unmappedImports: !mapped,
+	}
+}
+
+// replayImportPattern matches an import of one or more contracts from an address, e.g.
+// `import FungibleToken, FlowToken from 0x1654653399040a61`
+var replayImportPattern = regexp.MustCompile(`import\s+([A-Za-z_][A-Za-z0-9_]*(?:\s*,\s*[A-Za-z_][A-Za-z0-9_]*)*)\s+from\s+0x([0-9a-fA-F]+)`)
+
+// replayImports rewrites the addresses of core contracts imported by recorded scripts from their
+// addresses on the recorded chain to their addresses on the replay chain.
+type replayImports struct {
+	recorded map[string]flow.Address // addresses of the core contracts on the recorded chain, by name
+	replay   map[string]flow.Address // addresses of the core contracts on the replay chain, by name
+}
+
+// newReplayImports creates the import rewriting from the recorded chain to the replay chain. Returns
+// nil if both are the same chain, in which case the scripts can be replayed as they are.
+func newReplayImports(recordedChainID flow.ChainID, chainID flow.ChainID) (*replayImports, error) {
+	if recordedChainID == chainID {
+		return nil, nil
+	}
+
+	recorded, err := coreContractAddresses(recordedChainID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get core contracts of recorded chain: %w", err)
+	}
+	replay, err := coreContractAddresses(chainID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get core contracts of replay chain: %w", err)
+	}
+
+	return &replayImports{
+		recorded: recorded,
+		replay:   replay,
+	}, nil
+}
+
+// coreContractAddresses returns the addresses of the core contracts on the given chain, by name.
+func coreContractAddresses(chainID flow.ChainID) (map[string]flow.Address, error) {
+	// validates the chain ID, which can't be converted to a chain if it is unknown
+	contracts, err := systemcontracts.SystemContractsForChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	chain := chainID.Chain()
+
+	return map[string]flow.Address{
+		contracts.Epoch.Name:                       contracts.Epoch.Address,
+		contracts.ClusterQC.Name:                   contracts.ClusterQC.Address,
+		contracts.DKG.Name:                         contracts.DKG.Address,
+		contracts.NodeVersionBeacon.Name:           contracts.NodeVersionBeacon.Address,
+		systemcontracts.ContractNameServiceAccount: chain.ServiceAddress(),
+		systemcontracts.ContractNameStorageFees:    chain.ServiceAddress(),
+		systemcontracts.ContractNameFlowFees:       environment.FlowFeesAddress(chain),
+		"FungibleToken":                            fvm.FungibleTokenAddress(chain),
+		"FlowToken":                                fvm.FlowTokenAddress(chain),
+	}, nil
+}
+
+// rewrite returns the given script with the imports of core contracts rewritten to the replay chain.
+// Returns false if the script imports any contract which isn't a core contract of the recorded chain,
+// since it might not be deployed on the replay chain.
+func (r *replayImports) rewrite(script string) (string, bool) {
+	mapped := true
+	rewritten := replayImportPattern.ReplaceAllStringFunc(script, func(declaration string) string {
+		match := replayImportPattern.FindStringSubmatch(declaration)
+		address := flow.HexToAddress(match[2])
+
+		// contracts imported together from one address may be deployed to different addresses on the replay chain
+		names := strings.Split(match[1], ",")
+		imports := make([]string, 0, len(names))
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			recorded, ok := r.recorded[name]
+			if !ok || recorded != address {
+				mapped = false
+				return declaration
+			}
+			imports = append(imports, fmt.Sprintf("import %s from 0x%s", name, r.replay[name].Hex()))
+		}
+		return strings.Join(imports, "\n")
+	})
+
+	return rewritten, mapped
+}
+
+// replayer hands out recorded transactions once they are due, and collects per template statistics.
+type replayer struct {
+	log      zerolog.Logger
+	schedule []replayTransaction
+
+	mu       sync.Mutex
+	started  time.Time
+	next     int
+	finished bool
+	stats    map[string]*ReplayTemplateStats
+}
+
+func newReplayer(log zerolog.Logger, schedule []replayTransaction) *replayer {
+	return &replayer{
+		log:      log,
+		schedule: schedule,
+		stats:    make(map[string]*ReplayTemplateStats),
+	}
+}
+
+// maxAuthorizers returns the highest number of authorizers of any scheduled transaction.
+func (r *replayer) maxAuthorizers() int {
+	max := 0
+	for _, tx := range r.schedule {
+		if tx.authorizers > max {
+			max = tx.authorizers
+		}
+	}
+	return max
+}
+
+// nextDue returns the next transaction due at the given time, and how far behind schedule it is.
+// The schedule starts with the first call. Returns false if no transaction is due.
+func (r *replayer) nextDue(now time.Time) (replayTransaction, time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started.IsZero() {
+		r.started = now
+	}
+
+	if r.next >= len(r.schedule) {
+		if !r.finished {
+			r.finished = true
+			r.log.Info().Int("transactions", len(r.schedule)).Msg("replay finished")
+		}
+		return replayTransaction{}, 0, false
+	}
+
+	tx := r.schedule[r.next]
+	due := r.started.Add(tx.offset)
+	if now.Before(due) {
+		return replayTransaction{}, 0, false
+	}
+	r.next++
+
+	return tx, now.Sub(due), true
+}
+
+// update applies the given update to the statistics of the given template.
+func (r *replayer) update(template string, update func(*ReplayTemplateStats)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.stats[template]
+	if !ok {
+		stats = &ReplayTemplateStats{}
+		r.stats[template] = stats
+	}
+	update(stats)
+}
+
+func (r *replayer) skipped(template string) {
+	r.update(template, func(s *ReplayTemplateStats) { s.Skipped++ })
+}
+
+func (r *replayer) sent(template string) {
+	r.update(template, func(s *ReplayTemplateStats) { s.Sent++ })
+}
+
+func (r *replayer) timedOut(template string) {
+	r.update(template, func(s *ReplayTemplateStats) { s.TimedOut++ })
+}
+
+func (r *replayer) executed(template string, latency time.Duration, failed bool) {
+	r.update(template, func(s *ReplayTemplateStats) {
+		s.Executed++
+		if failed {
+			s.Failed++
+		}
+		s.TotalLatency += latency
+		if latency > s.MaxLatency {
+			s.MaxLatency = latency
+		}
+	})
+}
+
+// getStats returns a copy of the statistics, by template.
+func (r *replayer) getStats() map[string]ReplayTemplateStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make(map[string]ReplayTemplateStats, len(r.stats))
+	for template, s := range r.stats {
+		stats[template] = *s
+	}
+	return stats
+}
+
+// logStats logs the statistics of every template.
+func (r *replayer) logStats() {
+	stats := r.getStats()
+
+	templates := make([]string, 0, len(stats))
+	for template := range stats {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+
+	for _, template := range templates {
+		s := stats[template]
+		r.log.Info().
+			Str("template", template).
+			Int("sent", s.Sent).
+			Int("executed", s.Executed).
+			Int("failed", s.Failed).
+			Int("timed_out", s.TimedOut).
+			Int("skipped", s.Skipped).
+			Dur("avg_latency", s.AverageLatency()).
+			Dur("max_latency", s.MaxLatency).
+			Msg("replay template stats")
+	}
+}
@@ -0,0 +1,198 @@
+package benchmark
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const recordedTransactionsJSON = `[
+	{
+		"block_id": "b2",
+		"height": 11,
+		"timestamp": "2023-05-01T10:00:04Z",
+		"collections": [
+			{"index": 0, "transactions": [
+				{"tx_id": "t3", "tx_index": 0, "script": "transaction { prepare(a: AuthAccount, b: AuthAccount) {} }", "arguments": [], "gas_limit": 100, "proposer_address": "01", "payer_address": "01", "authorizer_addresses": ["01", "02"]}
+			]}
+		]
+	},
+	{
+		"block_id": "b1",
+		"height": 10,
+		"timestamp": "2023-05-01T10:00:00Z",
+		"collections": [
+			{"index": 0, "transactions": [
+				{"tx_id": "t1", "tx_index": 0, "script": "transaction(x: Int) {}", "arguments": ["{\"type\":\"Int\",\"value\":\"1\"}"], "gas_limit": 0, "proposer_address": "01", "payer_address": "01", "authorizer_addresses": []},
+				{"tx_id": "t2", "tx_index": 1, "script": "transaction(x: Int) {}", "arguments": ["{\"type\":\"Int\",\"value\":\"2\"}"], "gas_limit": 50, "proposer_address": "01", "payer_address": "01", "authorizer_addresses": ["03", "03"]}
+			]}
+		]
+	}
+]`
+
+// TestDecodeReplayTransactions tests that recorded transactions are scheduled in height order,
+// keeping the scaled recorded time between blocks.
+func TestDecodeReplayTransactions(t *testing.T) {
+	schedule, err := decodeReplayTransactions(strings.NewReader(recordedTransactionsJSON), 0.5, nil)
+	require.NoError(t, err)
+	require.Len(t, schedule, 3)
+
+	// transactions of the first block are due right away
+	assert.Equal(t, time.Duration(0), schedule[0].offset)
+	assert.Equal(t, time.Duration(0), schedule[1].offset)
+	// the 4s between the blocks are scaled by 0.5
+	assert.Equal(t, 2*time.Second, schedule[2].offset)
+
+	// transactions with the same script share a template
+	assert.Equal(t, schedule[0].template, schedule[1].template)
+	assert.NotEqual(t, schedule[0].template, schedule[2].template)
+
+	assert.Equal(t, [][]byte{[]byte(`{"type":"Int","value":"1"}`)}, schedule[0].arguments)
+	assert.Equal(t, uint64(replayDefaultGasLimit), schedule[0].gasLimit)
+	assert.Equal(t, uint64(50), schedule[1].gasLimit)
+
+	// authorizers are counted once per address
+	assert.Equal(t, 0, schedule[0].authorizers)
+	assert.Equal(t, 1, schedule[1].authorizers)
+	assert.Equal(t, 2, schedule[2].authorizers)
+
+	t.Run("invalid time scale", func(t *testing.T) {
+		_, err := decodeReplayTransactions(strings.NewReader(recordedTransactionsJSON), -1, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("no transactions", func(t *testing.T) {
+		_, err := decodeReplayTransactions(strings.NewReader(`[]`), 1, nil)
+		require.Error(t, err)
+	})
+}
+
+// TestReplayer tests that the replayer hands out transactions once they are due, and collects statistics.
+func TestReplayer(t *testing.T) {
+	schedule, err := decodeReplayTransactions(strings.NewReader(recordedTransactionsJSON), 1, nil)
+	require.NoError(t, err)
+
+	r := newReplayer(zerolog.Nop(), schedule)
+	assert.Equal(t, 2, r.maxAuthorizers())
+
+	start := time.Now()
+
+	// the first block's transactions are due immediately
+	tx, lag, ok := r.nextDue(start)
+	require.True(t, ok)
+	assert.Equal(t, time.Duration(0), lag)
+	assert.Equal(t, schedule[0].template, tx.template)
+
+	_, _, ok = r.nextDue(start.Add(time.Second))
+	require.True(t, ok)
+
+	// the second block's transaction is due 4s later
+	_, _, ok = r.nextDue(start.Add(3 * time.Second))
+	require.False(t, ok)
+
+	tx, lag, ok = r.nextDue(start.Add(5 * time.Second))
+	require.True(t, ok)
+	assert.Equal(t, time.Second, lag)
+	assert.Equal(t, schedule[2].template, tx.template)
+
+	// the schedule is exhausted
+	_, _, ok = r.nextDue(start.Add(time.Hour))
+	require.False(t, ok)
+
+	r.sent(schedule[0].template)
+	r.sent(schedule[0].template)
+	r.executed(schedule[0].template, 2*time.Second, false)
+	r.executed(schedule[0].template, 4*time.Second, true)
+	r.skipped(schedule[2].template)
+
+	stats := r.getStats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, ReplayTemplateStats{
+		Sent:         2,
+		Executed:     2,
+		Failed:       1,
+		TotalLatency: 6 * time.Second,
+		MaxLatency:   4 * time.Second,
+	}, stats[schedule[0].template])
+	assert.Equal(t, 3*time.Second, stats[schedule[0].template].AverageLatency())
+	assert.Equal(t, ReplayTemplateStats{Skipped: 1}, stats[schedule[2].template])
+}
+
+// TestReplayImports tests that imports of core contracts are rewritten to the replay chain, and that
+// scripts importing other contracts are reported as unmapped.
+func TestReplayImports(t *testing.T) {
+	imports, err := newReplayImports(flow.Mainnet, flow.Localnet)
+	require.NoError(t, err)
+
+	localnet := flow.Localnet.Chain()
+	fungibleToken := fvm.FungibleTokenAddress(localnet).Hex()
+	flowToken := fvm.FlowTokenAddress(localnet).Hex()
+	service := localnet.ServiceAddress().Hex()
+
+	t.Run("core contracts are rewritten", func(t *testing.T) {
+		script, ok := imports.rewrite(`import FungibleToken from 0xf233dcee88fe0abe
+import FlowToken from 0x1654653399040a61
+
+transaction {}`)
+		require.True(t, ok)
+		assert.Equal(t, `import FungibleToken from 0x`+fungibleToken+`
+import FlowToken from 0x`+flowToken+`
+
+transaction {}`, script)
+	})
+
+	t.Run("contracts imported together are rewritten", func(t *testing.T) {
+		script, ok := imports.rewrite(`import FlowServiceAccount, FlowStorageFees from 0xe467b9dd11fa00df
+transaction {}`)
+		require.True(t, ok)
+		assert.Equal(t, `import FlowServiceAccount from 0x`+service+`
+import FlowStorageFees from 0x`+service+`
+transaction {}`, script)
+	})
+
+	t.Run("other contracts are unmapped", func(t *testing.T) {
+		recorded := `import FungibleToken from 0xf233dcee88fe0abe
+import TopShot from 0x0b2a3299cc857e29
+transaction {}`
+		script, ok := imports.rewrite(recorded)
+		require.False(t, ok)
+		assert.Contains(t, script, "import TopShot from 0x0b2a3299cc857e29")
+
+		// a core contract imported from another address than on the recorded chain
+		_, ok = imports.rewrite(`import FlowFees from 0x1654653399040a61
+transaction {}`)
+		require.False(t, ok)
+	})
+
+	t.Run("scripts of the same chain are not rewritten", func(t *testing.T) {
+		imports, err := newReplayImports(flow.Localnet, flow.Localnet)
+		require.NoError(t, err)
+		require.Nil(t, imports)
+	})
+
+	t.Run("unknown chain", func(t *testing.T) {
+		_, err := newReplayImports("unknown", flow.Localnet)
+		require.Error(t, err)
+	})
+
+	t.Run("transactions with unmapped imports are marked", func(t *testing.T) {
+		recorded := `[{"height": 1, "timestamp": "2023-05-01T10:00:00Z", "collections": [{"transactions": [
+			{"script": "import FlowToken from 0x1654653399040a61\ntransaction {}", "arguments": [], "authorizer_addresses": []},
+			{"script": "import TopShot from 0x0b2a3299cc857e29\ntransaction {}", "arguments": [], "authorizer_addresses": []}
+		]}]}]`
+		schedule, err := decodeReplayTransactions(strings.NewReader(recorded), 1, imports)
+		require.NoError(t, err)
+		require.Len(t, schedule, 2)
+
+		assert.False(t, schedule[0].unmappedImports)
+		assert.Equal(t, "import FlowToken from 0x"+flowToken+"\ntransaction {}", string(schedule[0].script))
+		assert.True(t, schedule[1].unmappedImports)
+	})
+}
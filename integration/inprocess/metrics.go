@@ -0,0 +1,45 @@
+package inprocess
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var installRegistererOnce sync.Once
+
+// installSharedRegisterer replaces the default prometheus registerer with one that tolerates the
+// registration of already registered collectors.
+//
+// The node builders register their metrics with the process-wide default registerer, which panics
+// once a second node of the same role registers its metrics. Metrics of all nodes but the first one
+// registering a collector are therefore not exported.
+func installSharedRegisterer() {
+	installRegistererOnce.Do(func() {
+		prometheus.DefaultRegisterer = &sharedRegisterer{Registerer: prometheus.DefaultRegisterer}
+	})
+}
+
+// sharedRegisterer is a prometheus.Registerer which ignores duplicate registrations.
+type sharedRegisterer struct {
+	prometheus.Registerer
+}
+
+func (r *sharedRegisterer) Register(collector prometheus.Collector) error {
+	err := r.Registerer.Register(collector)
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		return nil
+	}
+	return err
+}
+
+func (r *sharedRegisterer) MustRegister(collectors ...prometheus.Collector) {
+	for _, collector := range collectors {
+		err := r.Register(collector)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Package inprocess runs a Flow network with all node roles as goroutines of a single process.
+//
+// The nodes are built by the same node builders as the node binaries, store their data in
+// temporary directories and connect to each other over the loopback interface, so that end-to-end
+// tests and benchmarks can run with `go test`, without Docker.
+//
+// Each node listens on its own loopback IP address (127.0.1.x), which lets all nodes use the
+// default ports of the docker based testnet. This requires the whole 127.0.0.0/8 range to be
+// routed to the loopback interface, as it is by default on Linux.
+package inprocess
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	flowsdk "github.com/onflow/flow-go-sdk"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/integration/benchmark"
+	"github.com/onflow/flow-go/integration/testnet"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+const (
+	// hotstuffStartupDelay is the time between starting the network and the first hotstuff view,
+	// which gives all nodes time to connect to each other.
+	hotstuffStartupDelay = 8 * time.Second
+
+	// nodeStartupTimeout is the time the nodes have to become ready after they are started.
+	nodeStartupTimeout = 2 * time.Minute
+
+	// nodeShutdownTimeout is the time the nodes have to shut down after the network is stopped.
+	nodeShutdownTimeout = time.Minute
+)
+
+// Network is a Flow network of nodes running in the current process.
+type Network struct {
+	t            *testing.T
+	log          zerolog.Logger
+	chainID      flow.ChainID
+	baseDir      string
+	bootstrapDir string
+	logLevel     zerolog.Level
+	confs        []testnet.ContainerConfig
+	hosts        map[flow.Identifier]string
+	root         *flow.Block
+	nodes        []*Node
+	cancel       context.CancelFunc
+}
+
+// PrepareNetwork bootstraps a network with the given configuration. The nodes are built and started
+// by Start.
+//
+// Ghost nodes, corrupted nodes, consensus followers and observers are not supported.
+func PrepareNetwork(t *testing.T, networkConf testnet.NetworkConfig, chainID flow.ChainID) *Network {
+	require.NotZero(t, len(networkConf.Nodes), "must specify at least one node")
+	require.Empty(t, networkConf.ConsensusFollowers, "consensus followers are not supported")
+	require.Empty(t, networkConf.Observers, "observers are not supported")
+
+	// zerolog's level is process-wide, so all nodes log at the most verbose level of any node
+	logLevel := zerolog.Disabled
+	accessNodes := 0
+	for _, nodeConf := range networkConf.Nodes {
+		require.False(t, nodeConf.Ghost, "ghost nodes are not supported")
+		require.False(t, nodeConf.Corrupted, "corrupted nodes are not supported")
+		if nodeConf.LogLevel < logLevel {
+			logLevel = nodeConf.LogLevel
+		}
+		if nodeConf.Role == flow.RoleAccess {
+			accessNodes++
+		}
+	}
+	require.GreaterOrEqual(t, accessNodes, testnet.DefaultMinimumNumOfAccessNodeIDS,
+		fmt.Sprintf("at least %d access nodes must be configured", testnet.DefaultMinimumNumOfAccessNodeIDS))
+	require.Less(t, len(networkConf.Nodes), 255, "at most 254 nodes are supported")
+
+	// assign each node its own loopback IP address
+	assigned := 0
+	testnet.WithNodeAddresses(func(string) string {
+		assigned++
+		return net.JoinHostPort(fmt.Sprintf("127.0.1.%d", assigned), fmt.Sprint(testnet.DefaultFlowPort))
+	})(&networkConf)
+
+	baseDir := t.TempDir()
+	bootstrapDir := filepath.Join(baseDir, "bootstrap")
+
+	t.Logf("%v (%v) bootstrapping in-process flow network with %v nodes", time.Now().UTC(), t.Name(), len(networkConf.Nodes))
+
+	bootstrapData, err := testnet.BootstrapNetwork(networkConf, bootstrapDir, chainID)
+	require.NoError(t, err)
+
+	hosts := make(map[flow.Identifier]string, len(bootstrapData.StakedConfs))
+	for _, nodeConf := range bootstrapData.StakedConfs {
+		host, _, err := net.SplitHostPort(nodeConf.Address)
+		require.NoError(t, err)
+		hosts[nodeConf.NodeID] = host
+	}
+
+	return &Network{
+		t:            t,
+		log:          unittest.LoggerWithLevel(logLevel).With().Str("network", networkConf.Name).Logger(),
+		chainID:      chainID,
+		baseDir:      baseDir,
+		bootstrapDir: bootstrapDir,
+		logLevel:     logLevel,
+		confs:        bootstrapData.StakedConfs,
+		hosts:        hosts,
+		root:         bootstrapData.Root,
+	}
+}
+
+// Start builds all nodes and starts them, then blocks until all nodes are ready.
+// Irrecoverable errors thrown by any node fail the test and stop the network.
+func (n *Network) Start(ctx context.Context) {
+	require.Nil(n.t, n.cancel, "network already started")
+
+	// the node builders register their metrics with the default registerer
+	installSharedRegisterer()
+
+	startupTime := time.Now().Add(hotstuffStartupDelay)
+
+	for _, conf := range n.confs {
+		host := n.hosts[conf.NodeID]
+		dataDir := filepath.Join(n.baseDir, conf.ContainerName)
+
+		metricsPort, err := freePort()
+		require.NoError(n.t, err)
+
+		args := nodeArgs(conf, host, dataDir, n.bootstrapDir, metricsPort, n.logLevel, startupTime)
+		node, err := buildNode(conf.Role, args)
+		require.NoError(n.t, err, "could not build node %s", conf.ContainerName)
+
+		n.log.Info().
+			Str("name", conf.ContainerName).
+			Hex("node_id", conf.NodeID[:]).
+			Str("host", host).
+			Msg("built node")
+
+		n.nodes = append(n.nodes, &Node{
+			Node:   node,
+			Config: conf,
+			Host:   host,
+		})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	n.cancel = cancel
+
+	signalerCtx, errChan := irrecoverable.WithSignaler(ctx)
+	go func() {
+		select {
+		case err := <-errChan:
+			n.t.Errorf("irrecoverable error in in-process network: %v", err)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for _, node := range n.nodes {
+		node.Start(signalerCtx)
+	}
+
+	unittest.RequireComponentsReadyBefore(n.t, nodeStartupTimeout, n.components()...)
+
+	n.log.Info().Time("hotstuff_startup_time", startupTime).Msg("in-process network started")
+}
+
+// Stop stops all nodes, and blocks until all nodes are done.
+func (n *Network) Stop() {
+	if n.cancel == nil {
+		return
+	}
+	n.cancel()
+
+	unittest.RequireComponentsDoneBefore(n.t, nodeShutdownTimeout, n.components()...)
+
+	n.log.Info().Msg("in-process network stopped")
+}
+
+// Root returns the root block of the network.
+func (n *Network) Root() *flow.Block {
+	return n.root
+}
+
+// Nodes returns all nodes of the network. Nodes are only available once the network is started.
+func (n *Network) Nodes() []*Node {
+	return n.nodes
+}
+
+// NodesByRole returns all nodes of the network with the given role.
+func (n *Network) NodesByRole(role flow.Role) []*Node {
+	var nodes []*Node
+	for _, node := range n.nodes {
+		if node.Config.Role == role {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// AccessAddresses returns the addresses of the unsecured gRPC APIs of all access nodes.
+func (n *Network) AccessAddresses() []string {
+	var addrs []string
+	for _, conf := range n.confs {
+		if conf.Role == flow.RoleAccess {
+			addrs = append(addrs, net.JoinHostPort(n.hosts[conf.NodeID], testnet.GRPCPort))
+		}
+	}
+	return addrs
+}
+
+// LoadGeneratorParams returns the network parameters for a benchmark.ContLoadGenerator targeting
+// the network, which sends its transactions from the service account.
+func (n *Network) LoadGeneratorParams() benchmark.NetworkParams {
+	chain := n.chainID.Chain()
+	serviceAccountAddress := flowsdk.Address(chain.ServiceAddress())
+	fungibleTokenAddress := flowsdk.Address(fvm.FungibleTokenAddress(chain))
+	flowTokenAddress := flowsdk.Address(fvm.FlowTokenAddress(chain))
+
+	return benchmark.NetworkParams{
+		ServAccPrivKeyHex:     unittest.ServiceAccountPrivateKeyHex,
+		ServiceAccountAddress: &serviceAccountAddress,
+		FungibleTokenAddress:  &fungibleTokenAddress,
+		FlowTokenAddress:      &flowTokenAddress,
+	}
+}
+
+func (n *Network) components() []module.ReadyDoneAware {
+	components := make([]module.ReadyDoneAware, 0, len(n.nodes))
+	for _, node := range n.nodes {
+		components = append(components, node)
+	}
+	return components
+}
+
+// freePort returns a TCP port which is currently not in use.
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, fmt.Errorf("could not find free port: %w", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,99 @@
+package inprocess
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow-go-sdk/access"
+	client "github.com/onflow/flow-go-sdk/access/grpc"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/onflow/flow-go/integration/benchmark"
+	"github.com/onflow/flow-go/integration/testnet"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestInProcessNetwork_TokenTransfers starts an in-process network with all node roles, and sends
+// token transfers to its access node at a constant rate with the continuous load generator.
+//
+// Run it with:
+//
+//	TEST_RESOURCE_INTENSIVE=1 go test -run TestInProcessNetwork_TokenTransfers -v ./inprocess
+func TestInProcessNetwork_TokenTransfers(t *testing.T) {
+	unittest.SkipUnless(t, unittest.TEST_RESOURCE_INTENSIVE, "starts a full network in the test process")
+
+	const (
+		tps      = 10
+		duration = 30 * time.Second
+	)
+
+	logLevel := testnet.WithLogLevel(zerolog.WarnLevel)
+	nodes := append(testnet.NodeConfigs{
+		testnet.NewNodeConfig(flow.RoleExecution, logLevel),
+		testnet.NewNodeConfig(flow.RoleVerification, logLevel),
+		testnet.NewNodeConfig(flow.RoleAccess, logLevel),
+	}, testnet.NewNodeConfigSet(2, flow.RoleCollection, logLevel)...)
+	nodes = append(nodes, testnet.NewNodeConfigSet(3, flow.RoleConsensus, logLevel)...)
+
+	net := PrepareNetwork(t, testnet.NewNetworkConfig("inprocess-token-transfers", nodes), flow.Localnet)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	net.Start(ctx)
+	defer net.Stop()
+
+	clients := make([]access.Client, 0, len(net.AccessAddresses()))
+	for _, addr := range net.AccessAddresses() {
+		flowClient, err := client.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		clients = append(clients, flowClient)
+	}
+
+	log := unittest.LoggerWithLevel(zerolog.InfoLevel)
+
+	workerStatsTracker := benchmark.NewWorkerStatsTracker(ctx)
+	defer workerStatsTracker.Stop()
+
+	lg, err := benchmark.New(
+		ctx,
+		log,
+		workerStatsTracker,
+		metrics.NewLoaderCollector(),
+		clients,
+		net.LoadGeneratorParams(),
+		benchmark.LoadParams{
+			NumberOfAccounts: tps * 10,
+			LoadType:         benchmark.TokenTransferLoadType,
+			FeedbackEnabled:  true,
+		},
+		benchmark.ConstExecParams{},
+		benchmark.ReplayParams{},
+	)
+	require.NoError(t, err)
+	defer lg.Stop()
+
+	require.NoError(t, lg.Init())
+	require.NoError(t, lg.SetTPS(tps))
+
+	time.Sleep(duration)
+
+	stats := workerStatsTracker.GetStats()
+	log.Info().
+		Int("sent", stats.TxsSent).
+		Int("executed", stats.TxsExecuted).
+		Int("failed", stats.TxsFailed).
+		Int("timed_out", stats.TxsTimedout).
+		Float64("executed_tps", stats.TxsExecutedMovingAverage).
+		Msg("in-process network throughput")
+
+	assert.Positive(t, stats.TxsExecuted)
+	assert.Zero(t, stats.TxsFailed)
+}
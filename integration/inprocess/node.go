@@ -0,0 +1,137 @@
+package inprocess
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/cmd"
+	accessnode "github.com/onflow/flow-go/cmd/access/node_builder"
+	collectionnode "github.com/onflow/flow-go/cmd/collection/node_builder"
+	consensusnode "github.com/onflow/flow-go/cmd/consensus/node_builder"
+	"github.com/onflow/flow-go/integration/testnet"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Node is a node of the in-process network.
+type Node struct {
+	cmd.Node
+	Config testnet.ContainerConfig
+	// Host is the loopback IP address the node listens on, all APIs of the node use their default port.
+	Host string
+}
+
+// Addr returns the address of the node's API listening on the given port.
+func (n *Node) Addr(port string) string {
+	return net.JoinHostPort(n.Host, port)
+}
+
+// nodeArgs returns the command line arguments for the node with the given config.
+func nodeArgs(conf testnet.ContainerConfig, host string, dataDir string, bootstrapDir string, metricsPort int, logLevel zerolog.Level, startupTime time.Time) []string {
+	addr := func(port string) string {
+		return net.JoinHostPort(host, port)
+	}
+
+	args := []string{
+		fmt.Sprintf("--nodeid=%s", conf.NodeID.String()),
+		fmt.Sprintf("--bootstrapdir=%s", bootstrapDir),
+		fmt.Sprintf("--datadir=%s", filepath.Join(dataDir, "protocol")),
+		fmt.Sprintf("--secretsdir=%s", filepath.Join(dataDir, "secrets")),
+		fmt.Sprintf("--profiler-dir=%s", filepath.Join(dataDir, "profiler")),
+		fmt.Sprintf("--metricport=%d", metricsPort),
+		fmt.Sprintf("--loglevel=%s", logLevel.String()),
+		fmt.Sprintf("--peerupdate-interval=%s", time.Second.String()),
+	}
+
+	startup := fmt.Sprintf("--hotstuff-startup-time=%s", startupTime.Format(time.RFC3339))
+
+	switch conf.Role {
+	case flow.RoleCollection:
+		args = append(args,
+			fmt.Sprintf("--ingress-addr=%s", addr(testnet.GRPCPort)),
+			fmt.Sprintf("--hotstuff-min-timeout=%s", time.Second.String()),
+			startup,
+			"--insecure-access-api=false",
+			"--access-node-ids=*",
+		)
+	case flow.RoleConsensus:
+		args = append(args,
+			// use 1 here instead of the default 5, because the network usually has 1 verification node
+			"--chunk-alpha=1",
+			startup,
+			"--insecure-access-api=false",
+			"--access-node-ids=*",
+		)
+	case flow.RoleExecution:
+		args = append(args,
+			fmt.Sprintf("--rpc-addr=%s", addr(testnet.GRPCPort)),
+			fmt.Sprintf("--triedir=%s", filepath.Join(dataDir, "exedb")),
+			fmt.Sprintf("--execution-data-dir=%s", filepath.Join(dataDir, "execution_data")),
+			"--execution-data-transaction-results-height=0",
+		)
+	case flow.RoleVerification:
+		args = append(args, "--chunk-alpha=1")
+	case flow.RoleAccess:
+		args = append(args,
+			fmt.Sprintf("--rpc-addr=%s", addr(testnet.GRPCPort)),
+			fmt.Sprintf("--secure-rpc-addr=%s", addr(testnet.GRPCSecurePort)),
+			fmt.Sprintf("--http-addr=%s", addr(testnet.GRPCWebPort)),
+			fmt.Sprintf("--rest-addr=%s", addr(testnet.RESTPort)),
+			fmt.Sprintf("--state-stream-addr=%s", addr(testnet.ExecutionStatePort)),
+			fmt.Sprintf("--collection-ingress-port=%s", testnet.GRPCPort),
+			fmt.Sprintf("--execution-ingress-port=%s", testnet.GRPCPort),
+			fmt.Sprintf("--execution-data-dir=%s", filepath.Join(dataDir, "execution_data")),
+		)
+	}
+
+	// additional flags are parsed last, so they override the defaults above
+	return append(args, conf.AdditionalFlags...)
+}
+
+// buildNode builds a node of the given role with the node builder used by the node's binary,
+// configured by the given command line arguments instead of the process' command line.
+func buildNode(role flow.Role, args []string) (cmd.Node, error) {
+	base := cmd.FlowNode(role.String(), cmd.WithArgs(args))
+
+	switch role {
+	case flow.RoleCollection:
+		return collectionnode.FlowCollectionNode(base)
+
+	case flow.RoleConsensus:
+		return consensusnode.FlowConsensusNode(base)
+
+	case flow.RoleExecution:
+		builder := cmd.NewExecutionNodeBuilder(base)
+		builder.LoadFlags()
+		if err := builder.FlowNodeBuilder.Initialize(); err != nil {
+			return nil, err
+		}
+		builder.LoadComponentsAndModules()
+		return builder.FlowNodeBuilder.Build()
+
+	case flow.RoleVerification:
+		builder := cmd.NewVerificationNodeBuilder(base)
+		builder.LoadFlags()
+		if err := builder.FlowNodeBuilder.Initialize(); err != nil {
+			return nil, err
+		}
+		builder.LoadComponentsAndModules()
+		return builder.FlowNodeBuilder.Build()
+
+	case flow.RoleAccess:
+		builder := accessnode.FlowAccessNode(base)
+		if err := builder.ParseFlags(); err != nil {
+			return nil, err
+		}
+		if err := builder.Initialize(); err != nil {
+			return nil, err
+		}
+		return builder.Build()
+
+	default:
+		return nil, fmt.Errorf("unsupported role: %s", role)
+	}
+}
@@ -362,6 +362,9 @@ type NetworkConfig struct {
 	ViewsInStakingAuction      uint64
 	ViewsInEpoch               uint64
 	EpochCommitSafetyThreshold uint64
+	// NodeAddress returns the networking address of the node with the given name (e.g. consensus_1).
+	// If not set, nodes are addressed by their container name.
+	NodeAddress func(name string) string
 }
 
 type NetworkConfigOpt func(*NetworkConfig)
@@ -425,6 +428,14 @@ func WithEpochCommitSafetyThreshold(threshold uint64) func(*NetworkConfig) {
 	}
 }
 
+// WithNodeAddresses sets the function used to assign networking addresses to the staked nodes,
+// for networks whose nodes do not run as docker containers.
+func WithNodeAddresses(nodeAddress func(name string) string) func(*NetworkConfig) {
+	return func(config *NetworkConfig) {
+		config.NodeAddress = nodeAddress
+	}
+}
+
 func WithClusters(n uint) func(*NetworkConfig) {
 	return func(conf *NetworkConfig) {
 		conf.NClusters = n
@@ -804,6 +815,7 @@ func (net *FlowNetwork) AddNode(t *testing.T, bootstrapDir string, nodeConf Cont
 
 			nodeContainer.AddFlag("triedir", DefaultExecutionRootDir)
 			nodeContainer.AddFlag("execution-data-dir", DefaultExecutionDataServiceDir)
+			nodeContainer.AddFlag("execution-data-transaction-results-height", "0")
 
 		case flow.RoleAccess:
 			nodeContainer.exposePort(GRPCPort, testingdock.RandomPort(t))
@@ -1189,6 +1201,9 @@ func setupKeys(networkConf NetworkConfig) ([]ContainerConfig, error) {
 		name := fmt.Sprintf("%s_%d", conf.Role.String(), roleCounter[conf.Role]+1)
 
 		addr := fmt.Sprintf("%s:%d", name, DefaultFlowPort)
+		if networkConf.NodeAddress != nil {
+			addr = networkConf.NodeAddress(name)
+		}
 		roleCounter[conf.Role]++
 
 		info := bootstrap.NewPrivateNodeInfo(
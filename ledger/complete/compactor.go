@@ -24,6 +24,15 @@ type WALTrieUpdate struct {
 	TrieCh   <-chan *trie.MTrie // TrieCh channel is used to send new trie from Ledger to Compactor.
 }
 
+// TrieUpdateConsumer is notified of every trie update processed by the Compactor,
+// after the update has been written to the WAL and the new trie has been created.
+type TrieUpdateConsumer interface {
+	// OnTrieUpdate is called with the trie update and the state resulting from it.
+	// It is called from the Compactor goroutine, so it blocks processing of subsequent
+	// trie updates until it returns.
+	OnTrieUpdate(update *ledger.TrieUpdate, newState ledger.State)
+}
+
 // checkpointResult is a message to communicate checkpointing number and error if any.
 type checkpointResult struct {
 	num int
@@ -52,6 +61,7 @@ type Compactor struct {
 	logger                               zerolog.Logger
 	lm                                   *lifecycle.LifecycleManager
 	observers                            map[observable.Observer]struct{}
+	trieUpdateConsumers                  []TrieUpdateConsumer
 	checkpointDistance                   uint
 	checkpointsToKeep                    uint
 	stopCh                               chan chan struct{}
@@ -128,6 +138,12 @@ func (c *Compactor) Unsubscribe(observer observable.Observer) {
 	delete(c.observers, observer)
 }
 
+// AddTrieUpdateConsumer adds a consumer which is notified of every processed trie update.
+// CAUTION: not concurrency safe, consumers must be added before the Compactor is started.
+func (c *Compactor) AddTrieUpdateConsumer(consumer TrieUpdateConsumer) {
+	c.trieUpdateConsumers = append(c.trieUpdateConsumers, consumer)
+}
+
 // Ready returns channel which would be closed when Compactor goroutine starts.
 func (c *Compactor) Ready() <-chan struct{} {
 	c.lm.OnStart(func() {
@@ -402,6 +418,12 @@ func (c *Compactor) processTrieUpdate(
 		}
 
 		trieQueue.Push(trie)
+
+		if updateErr == nil {
+			for _, consumer := range c.trieUpdateConsumers {
+				consumer.OnTrieUpdate(update.Update, ledger.State(trie.RootHash()))
+			}
+		}
 	}()
 
 	if activeSegmentNum == -1 {
@@ -43,16 +43,19 @@ type Ledger struct {
 }
 
 // NewLedger creates a new in-memory trie-backed ledger storage with persistence.
+// The forest options are passed to the underlying forest, e.g. mtrie.WithPayloadStore to keep
+// the payloads of the tries outside of the heap.
 func NewLedger(
 	wal realWAL.LedgerWAL,
 	capacity int,
 	metrics module.LedgerMetrics,
 	log zerolog.Logger,
-	pathFinderVer uint8) (*Ledger, error) {
+	pathFinderVer uint8,
+	forestOpts ...mtrie.ForestOption) (*Ledger, error) {
 
 	logger := log.With().Str("ledger_mod", "complete").Logger()
 
-	forest, err := mtrie.NewForest(capacity, metrics, nil)
+	forest, err := mtrie.NewForest(capacity, metrics, nil, forestOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create forest: %w", err)
 	}
@@ -375,7 +378,10 @@ func (l *Ledger) ExportCheckpointAt(
 		// preCheckpointReporters, which doesn't use the payloads.
 	} else {
 		// get all payloads
-		payloads = t.AllPayloads()
+		payloads, err = t.AllPayloads()
+		if err != nil {
+			return ledger.State(hash.DummyHash), fmt.Errorf("cannot get payloads of trie: %w", err)
+		}
 		payloadSize := len(payloads)
 
 		// migrate payloads
@@ -467,7 +473,10 @@ func (l *Ledger) ExportCheckpointAt(
 	if noMigration {
 		// when there is no mgiration, we generate the payloads now before
 		// running the postCheckpointReporters
-		payloads = newTrie.AllPayloads()
+		payloads, err = newTrie.AllPayloads()
+		if err != nil {
+			return ledger.State(hash.DummyHash), fmt.Errorf("cannot get payloads of trie: %w", err)
+		}
 	}
 
 	// running post checkpoint reporters
@@ -2,6 +2,8 @@ package complete_test
 
 import (
 	"math"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -13,6 +15,8 @@ import (
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	"github.com/onflow/flow-go/ledger/common/testutils"
 	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadfile"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	"github.com/onflow/flow-go/ledger/partial/ptrie"
 	"github.com/onflow/flow-go/module/metrics"
@@ -24,10 +28,38 @@ import (
 //	go test -bench=.  -benchmem
 //
 // will track the heap allocations for the Benchmarks
-func BenchmarkStorage(b *testing.B) { benchmarkStorage(100, b) }
+func BenchmarkStorage(b *testing.B) {
+	runWithPayloadModes(b, func(b *testing.B, forestOpts ...mtrie.ForestOption) {
+		benchmarkStorage(100, b, forestOpts...)
+	})
+}
+
+// runWithPayloadModes runs the benchmark with leaf payloads kept in the heap, and with leaf payloads
+// kept in a memory-mapped payload file.
+func runWithPayloadModes(b *testing.B, bench func(b *testing.B, forestOpts ...mtrie.ForestOption)) {
+	b.Run("heap", func(b *testing.B) {
+		bench(b)
+	})
+	b.Run("mmap", func(b *testing.B) {
+		file, err := payloadfile.Open(filepath.Join(b.TempDir(), "payloads"), payloadfile.DefaultSegmentSize)
+		require.NoError(b, err)
+		defer file.Close()
+
+		bench(b, mtrie.WithPayloadStore(file))
+	})
+}
+
+// reportHeapSize reports the size of the heap after a garbage collection, which includes the
+// tries held by the ledger.
+func reportHeapSize(b *testing.B) {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	b.ReportMetric(float64(stats.HeapAlloc)/(1<<20), "heap_(MB)")
+}
 
 // BenchmarkStorage benchmarks the performance of the storage layer
-func benchmarkStorage(steps int, b *testing.B) {
+func benchmarkStorage(steps int, b *testing.B, forestOpts ...mtrie.ForestOption) {
 	// assumption: 1000 key updates per collection
 	const (
 		numInsPerStep      = 1000
@@ -44,7 +76,7 @@ func benchmarkStorage(steps int, b *testing.B) {
 	diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, steps+1, pathfinder.PathByteSize, wal.SegmentSize)
 	require.NoError(b, err)
 
-	led, err := complete.NewLedger(diskWal, steps+1, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion)
+	led, err := complete.NewLedger(diskWal, steps+1, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion, forestOpts...)
 	require.NoError(b, err)
 
 	compactor, err := complete.NewCompactor(led, diskWal, zerolog.Nop(), uint(steps+1), checkpointDistance, checkpointsToKeep, atomic.NewBool(false))
@@ -136,10 +168,16 @@ func benchmarkStorage(steps int, b *testing.B) {
 	b.ReportMetric(float64(totalProofSize/steps), "proof_size_(MB)")
 	b.ReportMetric(float64(totalPTrieConstTimeMS/steps), "ptrie_const_time_(ms)")
 
+	reportHeapSize(b)
+
 }
 
 // BenchmarkTrieUpdate benchmarks the performance of a trie update
 func BenchmarkTrieUpdate(b *testing.B) {
+	runWithPayloadModes(b, benchmarkTrieUpdate)
+}
+
+func benchmarkTrieUpdate(b *testing.B, forestOpts ...mtrie.ForestOption) {
 	// key updates per iteration
 	const (
 		numInsPerStep      = 10000
@@ -157,7 +195,7 @@ func BenchmarkTrieUpdate(b *testing.B) {
 	diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, capacity, pathfinder.PathByteSize, wal.SegmentSize)
 	require.NoError(b, err)
 
-	led, err := complete.NewLedger(diskWal, capacity, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion)
+	led, err := complete.NewLedger(diskWal, capacity, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion, forestOpts...)
 	require.NoError(b, err)
 
 	compactor, err := complete.NewCompactor(led, diskWal, zerolog.Nop(), capacity, checkpointDistance, checkpointsToKeep, atomic.NewBool(false))
@@ -190,8 +228,12 @@ func BenchmarkTrieUpdate(b *testing.B) {
 	b.StopTimer()
 }
 
-// BenchmarkTrieUpdate benchmarks the performance of a trie read
+// BenchmarkTrieRead benchmarks the performance of a trie read
 func BenchmarkTrieRead(b *testing.B) {
+	runWithPayloadModes(b, benchmarkTrieRead)
+}
+
+func benchmarkTrieRead(b *testing.B, forestOpts ...mtrie.ForestOption) {
 	// key updates per iteration
 	const (
 		numInsPerStep      = 10000
@@ -209,7 +251,7 @@ func BenchmarkTrieRead(b *testing.B) {
 	diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, capacity, pathfinder.PathByteSize, wal.SegmentSize)
 	require.NoError(b, err)
 
-	led, err := complete.NewLedger(diskWal, capacity, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion)
+	led, err := complete.NewLedger(diskWal, capacity, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion, forestOpts...)
 	require.NoError(b, err)
 
 	compactor, err := complete.NewCompactor(led, diskWal, zerolog.Nop(), capacity, checkpointDistance, checkpointsToKeep, atomic.NewBool(false))
@@ -329,8 +371,12 @@ func BenchmarkLedgerGetOneValue(b *testing.B) {
 	})
 }
 
-// BenchmarkTrieUpdate benchmarks the performance of a trie prove
+// BenchmarkTrieProve benchmarks the performance of a trie prove
 func BenchmarkTrieProve(b *testing.B) {
+	runWithPayloadModes(b, benchmarkTrieProve)
+}
+
+func benchmarkTrieProve(b *testing.B, forestOpts ...mtrie.ForestOption) {
 	// key updates per iteration
 	const (
 		numInsPerStep      = 10000
@@ -348,7 +394,7 @@ func BenchmarkTrieProve(b *testing.B) {
 	diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, capacity, pathfinder.PathByteSize, wal.SegmentSize)
 	require.NoError(b, err)
 
-	led, err := complete.NewLedger(diskWal, capacity, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion)
+	led, err := complete.NewLedger(diskWal, capacity, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion, forestOpts...)
 	require.NoError(b, err)
 
 	compactor, err := complete.NewCompactor(led, diskWal, zerolog.Nop(), capacity, checkpointDistance, checkpointsToKeep, atomic.NewBool(false))
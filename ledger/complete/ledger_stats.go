@@ -29,7 +29,10 @@ func (l *Ledger) CollectStats(payloadCallBack func(payload *ledger.Payload)) (*L
 		for itr := flattener.NewUniqueNodeIterator(trie.RootNode(), visitedNodes); itr.Next(); {
 			n := itr.Value()
 			if n.IsLeaf() {
-				payload := n.Payload()
+				payload, err := n.Payload()
+				if err != nil {
+					return nil, err
+				}
 				leafNodeCounter++
 				payloadCallBack(payload)
 			} else {
@@ -47,9 +47,16 @@ const payloadEncodingVersion = 1
 // WARNING: The returned buffer is likely to share the same underlying array as
 // the scratch buffer. Caller is responsible for copying or using returned buffer
 // before scratch buffer is used again.
-func encodeLeafNode(n *node.Node, scratch []byte) []byte {
+// No errors are expected during normal operation, errors are only returned if the payload
+// can't be loaded from the PayloadStore it is kept in.
+func encodeLeafNode(n *node.Node, scratch []byte) ([]byte, error) {
 
-	encPayloadSize := ledger.EncodedPayloadLengthWithoutPrefix(n.Payload(), payloadEncodingVersion)
+	payload, err := n.Payload()
+	if err != nil {
+		return nil, err
+	}
+
+	encPayloadSize := ledger.EncodedPayloadLengthWithoutPrefix(payload, payloadEncodingVersion)
 
 	encodedNodeSize := encNodeTypeSize +
 		encHeightSize +
@@ -93,9 +100,9 @@ func encodeLeafNode(n *node.Node, scratch []byte) []byte {
 
 	// EncodeAndAppendPayloadWithoutPrefix appends encoded payload to the resliced buf.
 	// Returned buf is resliced to include appended payload.
-	buf = ledger.EncodeAndAppendPayloadWithoutPrefix(buf[:pos], n.Payload(), payloadEncodingVersion)
+	buf = ledger.EncodeAndAppendPayloadWithoutPrefix(buf[:pos], payload, payloadEncodingVersion)
 
-	return buf
+	return buf, nil
 }
 
 // encodeInterimNode encodes interim node in the following format:
@@ -159,11 +166,13 @@ func encodeInterimNode(n *node.Node, lchildIndex uint64, rchildIndex uint64, scr
 // WARNING: The returned buffer is likely to share the same underlying array as
 // the scratch buffer. Caller is responsible for copying or using returned buffer
 // before scratch buffer is used again.
-func EncodeNode(n *node.Node, lchildIndex uint64, rchildIndex uint64, scratch []byte) []byte {
+// No errors are expected during normal operation, errors are only returned if the payload
+// of a leaf can't be loaded from the PayloadStore it is kept in.
+func EncodeNode(n *node.Node, lchildIndex uint64, rchildIndex uint64, scratch []byte) ([]byte, error) {
 	if n.IsLeaf() {
 		return encodeLeafNode(n, scratch)
 	}
-	return encodeInterimNode(n, lchildIndex, rchildIndex, scratch)
+	return encodeInterimNode(n, lchildIndex, rchildIndex, scratch), nil
 }
 
 // ReadNode reconstructs a node from data read from reader.
@@ -5,6 +5,7 @@ import (
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/module"
 )
@@ -28,6 +29,19 @@ type Forest struct {
 	forestCapacity int
 	onTreeEvicted  func(tree *trie.MTrie)
 	metrics        module.LedgerMetrics
+	payloads       node.PayloadStore // if set, leaf payloads are kept in this store instead of the heap
+}
+
+// ForestOption configures optional behavior of the Forest.
+type ForestOption func(*Forest)
+
+// WithPayloadStore configures the forest to keep the payloads of all leaves in the given store,
+// instead of the heap. Leaves then only keep the offset of their payload in the store.
+// The store must remain open as long as the forest, or any trie of it, is used.
+func WithPayloadStore(store node.PayloadStore) ForestOption {
+	return func(f *Forest) {
+		f.payloads = store
+	}
 }
 
 // NewForest returns a new instance of memory forest.
@@ -36,12 +50,15 @@ type Forest struct {
 // If more tries are added than the capacity, the Least Recently Added trie is removed (evicted) from the Forest (FIFO queue).
 // Make sure you chose a sufficiently large forestCapacity, such that, when reaching the capacity, the
 // Least Recently Added trie will never be needed again.
-func NewForest(forestCapacity int, metrics module.LedgerMetrics, onTreeEvicted func(tree *trie.MTrie)) (*Forest, error) {
+func NewForest(forestCapacity int, metrics module.LedgerMetrics, onTreeEvicted func(tree *trie.MTrie), opts ...ForestOption) (*Forest, error) {
 	forest := &Forest{tries: NewTrieCache(uint(forestCapacity), onTreeEvicted),
 		forestCapacity: forestCapacity,
 		onTreeEvicted:  onTreeEvicted,
 		metrics:        metrics,
 	}
+	for _, opt := range opts {
+		opt(forest)
+	}
 
 	// add trie with no allocated registers
 	emptyTrie := trie.NewEmptyMTrie()
@@ -82,7 +99,10 @@ func (f *Forest) ValueSizes(r *ledger.TrieRead) ([]int, error) {
 		pathOrgIndex[path] = append(indices, i)
 	}
 
-	sizes := trie.UnsafeValueSizes(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	sizes, err := trie.UnsafeValueSizes(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	if err != nil {
+		return nil, fmt.Errorf("could not read value sizes: %w", err)
+	}
 
 	// reconstruct value sizes in the same key order that called the method
 	orderedValueSizes := make([]int, len(r.Paths))
@@ -109,7 +129,10 @@ func (f *Forest) ReadSingleValue(r *ledger.TrieReadSingleValue) (ledger.Value, e
 		return nil, err
 	}
 
-	payload := trie.ReadSinglePayload(r.Path)
+	payload, err := trie.ReadSinglePayload(r.Path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read payload: %w", err)
+	}
 	return payload.Value().DeepCopy(), nil
 }
 
@@ -129,7 +152,10 @@ func (f *Forest) Read(r *ledger.TrieRead) ([]ledger.Value, error) {
 
 	// call ReadSinglePayload if there is only one path
 	if len(r.Paths) == 1 {
-		payload := trie.ReadSinglePayload(r.Paths[0])
+		payload, err := trie.ReadSinglePayload(r.Paths[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not read payload: %w", err)
+		}
 		return []ledger.Value{payload.Value().DeepCopy()}, nil
 	}
 
@@ -149,7 +175,10 @@ func (f *Forest) Read(r *ledger.TrieRead) ([]ledger.Value, error) {
 		pathOrgIndex[path] = append(indices, i)
 	}
 
-	payloads := trie.UnsafeRead(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	payloads, err := trie.UnsafeRead(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	if err != nil {
+		return nil, fmt.Errorf("could not read payloads: %w", err)
+	}
 
 	// reconstruct the payloads in the same key order that called the method
 	orderedValues := make([]ledger.Value, len(r.Paths))
@@ -236,6 +265,15 @@ func (f *Forest) NewTrie(u *ledger.TrieUpdate) (*trie.MTrie, error) {
 		return nil, fmt.Errorf("constructing updated trie failed: %w", err)
 	}
 
+	// the new trie is not accessible by other goroutines yet, so the payloads of its new leaves
+	// can be offloaded in place
+	if f.payloads != nil {
+		err = node.OffloadPayloads(newTrie.RootNode(), parentTrie.RootNode(), f.payloads)
+		if err != nil {
+			return nil, fmt.Errorf("offloading payloads of updated trie failed: %w", err)
+		}
+	}
+
 	f.metrics.LatestTrieRegCount(newTrie.AllocatedRegCount())
 	f.metrics.LatestTrieRegCountDiff(int64(newTrie.AllocatedRegCount() - parentTrie.AllocatedRegCount()))
 	f.metrics.LatestTrieRegSize(newTrie.AllocatedRegSize())
@@ -297,7 +335,10 @@ func (f *Forest) Proofs(r *ledger.TrieRead) (*ledger.TrieBatchProof, error) {
 		stateTrie = newTrie
 	}
 
-	bp := stateTrie.UnsafeProofs(r.Paths)
+	bp, err := stateTrie.UnsafeProofs(r.Paths)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate proofs: %w", err)
+	}
 	return bp, nil
 }
 
@@ -322,9 +363,24 @@ func (f *Forest) GetTries() ([]*trie.MTrie, error) {
 	return f.tries.Tries(), nil
 }
 
-// AddTries adds a trie to the forest
+// AddTries adds tries to the forest, e.g. the tries loaded from a checkpoint.
+// If the forest keeps payloads in a PayloadStore, the payloads of the given tries are offloaded
+// to the store IN-PLACE, hence the tries must not be accessed by other goroutines concurrently.
 func (f *Forest) AddTries(newTries []*trie.MTrie) error {
-	for _, t := range newTries {
+	for i, t := range newTries {
+		if f.payloads != nil && t != nil {
+			// tries loaded from a checkpoint share sub-tries with their predecessors, which
+			// are already offloaded
+			var previous *node.Node
+			if i > 0 && newTries[i-1] != nil {
+				previous = newTries[i-1].RootNode()
+			}
+			err := node.OffloadPayloads(t.RootNode(), previous, f.payloads)
+			if err != nil {
+				return fmt.Errorf("offloading payloads of trie %v failed: %w", t.RootHash(), err)
+			}
+		}
+
 		err := f.AddTrie(t)
 		if err != nil {
 			return fmt.Errorf("adding tries to forest failed: %w", err)
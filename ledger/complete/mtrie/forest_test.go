@@ -3,6 +3,7 @@ package mtrie
 import (
 	"bytes"
 	"math/rand"
+	"path/filepath"
 	"sort"
 	"testing"
 	"time"
@@ -14,6 +15,8 @@ import (
 	"github.com/onflow/flow-go/ledger/common/hash"
 	prf "github.com/onflow/flow-go/ledger/common/proof"
 	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadfile"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/ledger/partial/ptrie"
 	"github.com/onflow/flow-go/module/metrics"
@@ -1125,3 +1128,77 @@ func TestPurgeCacheExcept(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, forest.tries.Count())
 }
+
+// TestPayloadStore verifies that a forest which keeps payloads in a payload file produces the same
+// tries, reads and proofs as a forest which keeps payloads in the heap.
+func TestPayloadStore(t *testing.T) {
+	file, err := payloadfile.Open(filepath.Join(t.TempDir(), "payloads"), payloadfile.DefaultSegmentSize)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, file.Close())
+	}()
+
+	heapForest, err := NewForest(5, &metrics.NoopCollector{}, nil)
+	require.NoError(t, err)
+	mappedForest, err := NewForest(5, &metrics.NoopCollector{}, nil, WithPayloadStore(file))
+	require.NoError(t, err)
+
+	heapRoot := heapForest.GetEmptyRootHash()
+	mappedRoot := mappedForest.GetEmptyRootHash()
+	var allPaths []ledger.Path
+
+	for step := 0; step < 10; step++ {
+		paths := testutils.RandomPathsRandLen(100)
+		payloads := testutils.RandomPayloads(len(paths), 2, 100)
+		allPaths = append(allPaths, paths...)
+
+		heapRoot, err = heapForest.Update(&ledger.TrieUpdate{RootHash: heapRoot, Paths: paths, Payloads: payloads})
+		require.NoError(t, err)
+		mappedRoot, err = mappedForest.Update(&ledger.TrieUpdate{RootHash: mappedRoot, Paths: paths, Payloads: payloads})
+		require.NoError(t, err)
+		require.Equal(t, heapRoot, mappedRoot)
+
+		// all leaves of the mapped forest keep their payload in the file
+		mappedTrie, err := mappedForest.GetTrie(mappedRoot)
+		require.NoError(t, err)
+		requireAllPayloadsStored(t, mappedTrie.RootNode())
+		require.True(t, mappedTrie.IsAValidTrie())
+
+		// reads include paths which don't exist
+		readPaths := append(testutils.RandomPathsRandLen(10), allPaths...)
+		heapValues, err := heapForest.Read(&ledger.TrieRead{RootHash: heapRoot, Paths: readPaths})
+		require.NoError(t, err)
+		mappedValues, err := mappedForest.Read(&ledger.TrieRead{RootHash: mappedRoot, Paths: readPaths})
+		require.NoError(t, err)
+		require.Equal(t, heapValues, mappedValues)
+
+		heapProofs, err := heapForest.Proofs(&ledger.TrieRead{RootHash: heapRoot, Paths: readPaths})
+		require.NoError(t, err)
+		mappedProofs, err := mappedForest.Proofs(&ledger.TrieRead{RootHash: mappedRoot, Paths: readPaths})
+		require.NoError(t, err)
+		require.True(t, heapProofs.Equals(mappedProofs))
+	}
+
+	// tries added to the forest, e.g. from a checkpoint, are offloaded as well
+	heapTries, err := heapForest.GetTries()
+	require.NoError(t, err)
+	loadedForest, err := NewForest(20, &metrics.NoopCollector{}, nil, WithPayloadStore(file))
+	require.NoError(t, err)
+	require.NoError(t, loadedForest.AddTries(heapTries))
+	for _, tr := range heapTries {
+		requireAllPayloadsStored(t, tr.RootNode())
+		require.True(t, tr.IsAValidTrie())
+	}
+}
+
+func requireAllPayloadsStored(t *testing.T, n *node.Node) {
+	if n == nil {
+		return
+	}
+	if n.IsLeaf() {
+		require.True(t, n.IsPayloadStored())
+		return
+	}
+	requireAllPayloadsStored(t, n.LeftChild())
+	requireAllPayloadsStored(t, n.RightChild())
+}
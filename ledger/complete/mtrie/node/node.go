@@ -36,9 +36,32 @@ type Node struct {
 	height    int             // height where the Node is at
 	path      ledger.Path     // the storage path (dummy value for interim nodes)
 	payload   *ledger.Payload // the payload this node is storing (leaf nodes only)
+	stored    *storedPayload  // the payload this node is storing, if offloaded to a PayloadStore (leaf nodes only)
 	hashValue hash.Hash       // hash value of node (cached)
 }
 
+// PayloadStore stores the payloads of leaf nodes outside of the Go heap, so that leaves only
+// need to keep the offset of their payload in the store.
+type PayloadStore interface {
+	// Append stores the payload and returns its offset in the store.
+	// No errors are expected during normal operation.
+	Append(payload *ledger.Payload) (uint64, error)
+
+	// Load returns the payload stored at the given offset.
+	// No errors are expected during normal operation.
+	Load(offset uint64) (*ledger.Payload, error)
+}
+
+// storedPayload references the payload of a leaf in a PayloadStore. It also keeps the hash of the
+// fully-expanded leaf, so that the hash of the leaf at any height can be computed without loading
+// the payload.
+type storedPayload struct {
+	store    PayloadStore
+	offset   uint64
+	leafHash hash.Hash // hash of the path and value at height 0, unused if the value is empty
+	empty    bool      // true if the payload's value is empty
+}
+
 // NewNode creates a new Node.
 // UNCHECKED requirement: combination of values must conform to
 // a valid node type (see documentation of `Node` for details)
@@ -79,6 +102,23 @@ func NewLeaf(path ledger.Path,
 	return n
 }
 
+// NewLeafAtHeight creates a compact leaf Node at the given height, with the path and payload
+// of the given leaf. The payload is shared with the given leaf, also if it is kept in a PayloadStore.
+// UNCHECKED requirement: height must be non-negative
+// UNCHECKED requirement: leaf is a non-nil leaf node
+func NewLeafAtHeight(leaf *Node, height int) *Node {
+	n := &Node{
+		lChild:  nil,
+		rChild:  nil,
+		height:  height,
+		path:    leaf.path,
+		payload: leaf.payload,
+		stored:  leaf.stored,
+	}
+	n.hashValue = n.computeHash()
+	return n
+}
+
 // NewInterimNode creates a new interim Node.
 // UNCHECKED requirement:
 //   - for any child `c` that is non-nil, its height must satisfy: height = c.height + 1
@@ -122,11 +162,11 @@ func NewInterimCompactifiedNode(height int, lChild, rChild *Node) *Node {
 	// an empty subtrie => in total we have one allocated register, which we represent as single leaf node
 	if rChild == nil && lChild.IsLeaf() {
 		h := hash.HashInterNode(lChild.hashValue, ledger.GetDefaultHashForHeight(lChild.height))
-		return &Node{height: height, path: lChild.path, payload: lChild.payload, hashValue: h}
+		return &Node{height: height, path: lChild.path, payload: lChild.payload, stored: lChild.stored, hashValue: h}
 	}
 	if lChild == nil && rChild.IsLeaf() {
 		h := hash.HashInterNode(ledger.GetDefaultHashForHeight(rChild.height), rChild.hashValue)
-		return &Node{height: height, path: rChild.path, payload: rChild.payload, hashValue: h}
+		return &Node{height: height, path: rChild.path, payload: rChild.payload, stored: rChild.stored, hashValue: h}
 	}
 
 	// CASE (b): both children contain some allocated registers => we can't compactify; return a full interim leaf
@@ -147,9 +187,16 @@ func (n *Node) IsDefaultNode() bool {
 func (n *Node) computeHash() hash.Hash {
 	// check for leaf node
 	if n.lChild == nil && n.rChild == nil {
+		// if payload is kept in a store, compute the hash based on the stored leaf hash
+		if n.stored != nil {
+			if n.stored.empty {
+				return ledger.GetDefaultHashForHeight(n.height)
+			}
+			return ledger.ComputeCompactValueFromLeafHash(hash.Hash(n.path), n.stored.leafHash, n.height)
+		}
 		// if payload is non-nil, compute the hash based on the payload content
-		if n.payload != nil {
-			return ledger.ComputeCompactValue(hash.Hash(n.path), n.payload.Value(), n.height)
+		if payload := n.payload; payload != nil {
+			return ledger.ComputeCompactValue(hash.Hash(n.path), payload.Value(), n.height)
 		}
 		// if payload is nil, return the default hash
 		return ledger.GetDefaultHashForHeight(n.height)
@@ -212,9 +259,66 @@ func (n *Node) Path() *ledger.Path {
 }
 
 // Payload returns the the Node's payload.
+// If the payload is kept in a PayloadStore, it is loaded from the store.
 // Do NOT MODIFY returned slices!
-func (n *Node) Payload() *ledger.Payload {
-	return n.payload
+// No errors are expected during normal operation, errors are only returned if the payload
+// can't be loaded from the PayloadStore.
+func (n *Node) Payload() (*ledger.Payload, error) {
+	if n.stored != nil {
+		payload, err := n.stored.store.Load(n.stored.offset)
+		if err != nil {
+			return nil, fmt.Errorf("could not load payload of leaf at path %v: %w", n.path, err)
+		}
+		return payload, nil
+	}
+	return n.payload, nil
+}
+
+// IsPayloadStored returns true if the payload of the Node is kept in a PayloadStore.
+func (n *Node) IsPayloadStored() bool {
+	return n.stored != nil
+}
+
+// OffloadPayloads moves the payloads of all leaves of the sub-trie with root n, which are kept in
+// the heap, to the given PayloadStore. Sub-tries which are also part of the sub-trie with root
+// `parent` at the same position are skipped, as their payloads are expected to be offloaded already.
+// `parent` may be nil, in which case the entire sub-trie is visited.
+//
+// CAUTION: nodes are supposed to be immutable, but this function modifies leaves IN-PLACE to avoid
+// copying the trie. It must only be used for nodes which are not yet accessible by other goroutines,
+// e.g. for newly constructed tries before they are added to the forest.
+// No errors are expected during normal operation.
+func OffloadPayloads(n, parent *Node, store PayloadStore) error {
+	if n == nil || n == parent {
+		return nil
+	}
+
+	if n.IsLeaf() {
+		if n.payload == nil {
+			return nil
+		}
+		offset, err := store.Append(n.payload)
+		if err != nil {
+			return fmt.Errorf("could not offload payload of leaf at path %v: %w", n.path, err)
+		}
+		value := n.payload.Value()
+		n.stored = &storedPayload{store: store, offset: offset, empty: len(value) == 0}
+		if !n.stored.empty {
+			n.stored.leafHash = hash.HashLeaf(hash.Hash(n.path), value)
+		}
+		n.payload = nil
+		return nil
+	}
+
+	var parentLeft, parentRight *Node
+	if !parent.IsLeaf() {
+		parentLeft, parentRight = parent.lChild, parent.rChild
+	}
+	err := OffloadPayloads(n.lChild, parentLeft, store)
+	if err != nil {
+		return err
+	}
+	return OffloadPayloads(n.rChild, parentRight, store)
 }
 
 // LeftChild returns the the Node's left child.
@@ -244,8 +348,10 @@ func (n *Node) FmtStr(prefix string, subpath string) string {
 		left = fmt.Sprintf("\n%v", n.lChild.FmtStr(prefix+"\t", subpath+"0"))
 	}
 	payloadSize := 0
-	if n.payload != nil {
-		payloadSize = n.payload.Size()
+	if payload, err := n.Payload(); err != nil {
+		payloadSize = -1 // payload can't be loaded from its store
+	} else if payload != nil {
+		payloadSize = payload.Size()
 	}
 	hashStr := hex.EncodeToString(n.hashValue[:])
 	hashStr = hashStr[:3] + "..." + hashStr[len(hashStr)-3:]
@@ -253,20 +359,27 @@ func (n *Node) FmtStr(prefix string, subpath string) string {
 }
 
 // AllPayloads returns the payload of this node and all payloads of the subtrie
-func (n *Node) AllPayloads() []ledger.Payload {
+// No errors are expected during normal operation.
+func (n *Node) AllPayloads() ([]ledger.Payload, error) {
 	return n.appendSubtreePayloads([]ledger.Payload{})
 }
 
 // appendSubtreePayloads appends the payloads of the subtree with this node as root
 // to the provided Payload slice. Follows same pattern as Go's native append method.
-func (n *Node) appendSubtreePayloads(result []ledger.Payload) []ledger.Payload {
+func (n *Node) appendSubtreePayloads(result []ledger.Payload) ([]ledger.Payload, error) {
 	if n == nil {
-		return result
+		return result, nil
 	}
 	if n.IsLeaf() {
-		return append(result, *n.Payload())
+		payload, err := n.Payload()
+		if err != nil {
+			return nil, err
+		}
+		return append(result, *payload), nil
+	}
+	result, err := n.lChild.appendSubtreePayloads(result)
+	if err != nil {
+		return nil, err
 	}
-	result = n.lChild.appendSubtreePayloads(result)
-	result = n.rChild.appendSubtreePayloads(result)
-	return result
+	return n.rChild.appendSubtreePayloads(result)
 }
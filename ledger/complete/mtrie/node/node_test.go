@@ -2,6 +2,7 @@ package node_test
 
 import (
 	"encoding/hex"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -100,7 +101,9 @@ func Test_AllPayloads(t *testing.T) {
 	n3 := node.NewLeaf(path, payload, 1)
 	n4 := node.NewInterimNode(1, n1, n2)
 	n5 := node.NewInterimNode(2, n4, n3)
-	require.Equal(t, 3, len(n5.AllPayloads()))
+	payloads, err := n5.AllPayloads()
+	require.NoError(t, err)
+	require.Equal(t, 3, len(payloads))
 }
 
 func Test_VerifyCachedHash(t *testing.T) {
@@ -276,6 +279,91 @@ func Test_Compactify_BothChildrenPopulated(t *testing.T) {
 	require.Equal(t, n5.Hash(), nn5.Hash())
 }
 
+// Test_OffloadPayloads verifies that payloads of new leaves are moved to the payload store, while
+// leaves shared with the parent trie are skipped, and that offloading doesn't change the hashes.
+func Test_OffloadPayloads(t *testing.T) {
+	path := testutils.PathByUint16(1)
+	p1 := testutils.LightPayload(2, 3)
+	p2 := testutils.LightPayload(4, 5)
+	p3 := testutils.LightPayload(6, 7)
+
+	n1 := node.NewLeaf(path, p1, 0)
+	n2 := node.NewLeaf(path, p2, 0)
+	parent := node.NewInterimNode(1, n1, n2)
+
+	n3 := node.NewLeaf(path, p3, 0)
+	updated := node.NewInterimNode(1, n1, n3)
+	expectedHash := updated.Hash()
+
+	store := newMemoryPayloadStore()
+	require.NoError(t, node.OffloadPayloads(updated, parent, store))
+
+	// only the new leaf is offloaded
+	require.Len(t, store.payloads, 1)
+	require.False(t, n1.IsPayloadStored())
+	require.True(t, n3.IsPayloadStored())
+	payload, err := n3.Payload()
+	require.NoError(t, err)
+	require.True(t, payload.Equals(p3))
+	require.True(t, updated.VerifyCachedHash())
+	require.Equal(t, expectedHash, updated.Hash())
+
+	// leaves at a different height keep sharing the stored payload
+	n4 := node.NewLeafAtHeight(n3, 2)
+	require.True(t, n4.IsPayloadStored())
+	require.Equal(t, node.NewLeaf(path, p3, 2).Hash(), n4.Hash())
+
+	// offloading again doesn't store payloads twice
+	require.NoError(t, node.OffloadPayloads(updated, nil, store))
+	require.Len(t, store.payloads, 2)
+	require.True(t, n1.IsPayloadStored())
+	payloads, err := updated.AllPayloads()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(payloads))
+}
+
+// Test_PayloadLoadError verifies that failing to load a stored payload is returned as an error,
+// and that the hashes of stored leaves are computed without loading their payloads.
+func Test_PayloadLoadError(t *testing.T) {
+	path := testutils.PathByUint16(1)
+	payload := testutils.LightPayload(2, 3)
+	leaf := node.NewLeaf(path, payload, 0)
+
+	store := newMemoryPayloadStore()
+	require.NoError(t, node.OffloadPayloads(leaf, nil, store))
+	store.loadErr = fmt.Errorf("store is closed")
+
+	_, err := leaf.Payload()
+	require.ErrorIs(t, err, store.loadErr)
+
+	_, err = node.NewInterimNode(1, leaf, nil).AllPayloads()
+	require.ErrorIs(t, err, store.loadErr)
+
+	require.Equal(t, node.NewLeaf(path, payload, 3).Hash(), node.NewLeafAtHeight(leaf, 3).Hash())
+}
+
+// memoryPayloadStore is a PayloadStore which keeps payloads in a slice.
+type memoryPayloadStore struct {
+	payloads []*ledger.Payload
+	loadErr  error // if set, returned by Load
+}
+
+func newMemoryPayloadStore() *memoryPayloadStore {
+	return &memoryPayloadStore{}
+}
+
+func (s *memoryPayloadStore) Append(payload *ledger.Payload) (uint64, error) {
+	s.payloads = append(s.payloads, payload.DeepCopy())
+	return uint64(len(s.payloads) - 1), nil
+}
+
+func (s *memoryPayloadStore) Load(offset uint64) (*ledger.Payload, error) {
+	if s.loadErr != nil {
+		return nil, s.loadErr
+	}
+	return s.payloads[offset].DeepCopy(), nil
+}
+
 func hashToString(hash hash.Hash) string {
 	return hex.EncodeToString(hash[:])
 }
@@ -0,0 +1,229 @@
+package payloadfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+)
+
+// DefaultSegmentSize is the default size of the segments the payload file is mapped in.
+const DefaultSegmentSize = 64 << 20 // 64 MiB
+
+// payloadEncodingVersion is the encoding version of the payloads in the file.
+const payloadEncodingVersion = 1
+
+// recordHeaderSize is the size of the length prefix of each payload record.
+const recordHeaderSize = 4
+
+// ErrClosed is returned when appending to a closed payload file.
+var ErrClosed = errors.New("payload file is closed")
+
+var _ node.PayloadStore = (*File)(nil)
+
+// File is an append-only file of trie leaf payloads, which is memory-mapped for reading. It allows
+// the leaves of the in-memory forest to keep only the offset of their payload, instead of the payload
+// itself, and lets the OS page payloads in and out of memory as needed.
+//
+// The file is grown in segments, each of which is mapped separately. Payloads never span segments:
+// a payload which doesn't fit into the remaining space of the current segment is written to a new
+// segment, and payloads larger than the segment size get a segment of their own.
+//
+// The file is not a persistent store: it is truncated when opened, as the forest is rebuilt from
+// the checkpoint and the WAL on startup. Payloads are never removed, so the file grows with every
+// trie update until the node is restarted.
+//
+// EXPERIMENTAL: the file is not compacted when tries are evicted from the forest, hence the payloads
+// of evicted tries keep using disk space until the node is restarted. It must only be enabled on
+// nodes which are restarted regularly, and is disabled by default.
+//
+// File is concurrency safe. Appends are serialized, loads are lock-free.
+type File struct {
+	mu          sync.Mutex
+	file        *os.File
+	segmentSize int64
+	size        int64  // size of the file, i.e. end of the last segment
+	offset      int64  // offset the next payload is written to
+	scratch     []byte // buffer payloads are encoded into before they are written
+	closed      bool
+
+	// segments is the list of mapped segments, ordered by offset. The list is replaced on every
+	// new segment, so that loads can read it without holding the lock.
+	segments atomic.Value // []*segment
+	// written is the offset up to which payloads are written, so that loads can check offsets without holding the lock.
+	written atomic.Int64
+}
+
+type segment struct {
+	start int64
+	data  []byte
+}
+
+// Open creates the payload file at the given path, truncating any existing file.
+// The segment size is rounded up to a multiple of the page size.
+// No errors are expected during normal operation.
+func Open(path string, segmentSize int64) (*File, error) {
+	if segmentSize <= 0 {
+		return nil, fmt.Errorf("invalid segment size: %d", segmentSize)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not create payload file %s: %w", path, err)
+	}
+
+	file := &File{
+		file:        f,
+		segmentSize: roundToPageSize(segmentSize),
+	}
+	file.segments.Store([]*segment{})
+	return file, nil
+}
+
+// Append writes the payload to the file and returns its offset.
+// Expected errors during normal operation:
+//   - ErrClosed if the file is closed
+func (f *File) Append(payload *ledger.Payload) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, ErrClosed
+	}
+
+	f.scratch = ledger.EncodeAndAppendPayloadWithoutPrefix(f.scratch[:0], payload, payloadEncodingVersion)
+	recordSize := int64(recordHeaderSize + len(f.scratch))
+
+	if f.offset+recordSize > f.size {
+		err := f.grow(recordSize)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	seg := f.segmentAt(f.offset)
+	pos := f.offset - seg.start
+	binary.BigEndian.PutUint32(seg.data[pos:], uint32(len(f.scratch)))
+	copy(seg.data[pos+recordHeaderSize:], f.scratch)
+
+	offset := f.offset
+	f.offset += recordSize
+	f.written.Store(f.offset)
+	return uint64(offset), nil
+}
+
+// grow adds a new segment which fits a record of the given size at the end of the file, and
+// moves the write offset to the start of the segment. Must be called while holding the lock.
+func (f *File) grow(recordSize int64) error {
+	size := f.segmentSize
+	if recordSize > size {
+		size = roundToPageSize(recordSize)
+	}
+
+	start := f.size
+	err := f.file.Truncate(start + size)
+	if err != nil {
+		return fmt.Errorf("could not grow payload file: %w", err)
+	}
+	data, err := mmap(f.file, start, int(size))
+	if err != nil {
+		return fmt.Errorf("could not map payload file segment at %d: %w", start, err)
+	}
+
+	segments := f.segments.Load().([]*segment)
+	updated := make([]*segment, len(segments), len(segments)+1)
+	copy(updated, segments)
+	f.segments.Store(append(updated, &segment{start: start, data: data}))
+
+	f.size = start + size
+	f.offset = start
+	return nil
+}
+
+// Load returns a copy of the payload at the given offset. The returned payload doesn't reference
+// the mapped memory, so it remains valid after the file is closed.
+// No errors are expected during normal operation.
+func (f *File) Load(offset uint64) (*ledger.Payload, error) {
+	if int64(offset) >= f.written.Load() {
+		return nil, fmt.Errorf("no payload at offset %d", offset)
+	}
+	seg := f.segmentAt(int64(offset))
+	if seg == nil {
+		return nil, fmt.Errorf("no payload at offset %d", offset)
+	}
+
+	pos := int64(offset) - seg.start
+	if pos+recordHeaderSize > int64(len(seg.data)) {
+		return nil, fmt.Errorf("no payload at offset %d", offset)
+	}
+	size := int64(binary.BigEndian.Uint32(seg.data[pos:]))
+	end := pos + recordHeaderSize + size
+	if end > int64(len(seg.data)) {
+		return nil, fmt.Errorf("payload at offset %d exceeds its segment", offset)
+	}
+
+	payload, err := ledger.DecodePayloadWithoutPrefix(seg.data[pos+recordHeaderSize:end], false, payloadEncodingVersion)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode payload at offset %d: %w", offset, err)
+	}
+	return payload, nil
+}
+
+// segmentAt returns the segment containing the given offset, or nil if there is none.
+func (f *File) segmentAt(offset int64) *segment {
+	segments := f.segments.Load().([]*segment)
+	i := sort.Search(len(segments), func(i int) bool {
+		return segments[i].start+int64(len(segments[i].data)) > offset
+	})
+	if i == len(segments) || segments[i].start > offset {
+		return nil
+	}
+	return segments[i]
+}
+
+// Size returns the number of bytes written to the file, including the unused tails of full segments.
+func (f *File) Size() int64 {
+	return f.written.Load()
+}
+
+// Close unmaps and closes the file.
+// CAUTION: the payloads of leaves referencing the file can't be loaded anymore once it is closed,
+// hence the file must only be closed once the forest using it is not accessed anymore.
+// No errors are expected during normal operation.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+
+	var errs *multierror.Error
+	for _, seg := range f.segments.Load().([]*segment) {
+		err := munmap(seg.data)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("could not unmap payload file segment at %d: %w", seg.start, err))
+		}
+	}
+	f.segments.Store([]*segment{})
+
+	err := f.file.Close()
+	if err != nil {
+		errs = multierror.Append(errs, fmt.Errorf("could not close payload file: %w", err))
+	}
+	return errs.ErrorOrNil()
+}
+
+func roundToPageSize(size int64) int64 {
+	pageSize := int64(os.Getpagesize())
+	return (size + pageSize - 1) / pageSize * pageSize
+}
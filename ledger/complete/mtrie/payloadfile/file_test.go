@@ -0,0 +1,118 @@
+package payloadfile_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadfile"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payloads")
+	file, err := payloadfile.Open(path, int64(os.Getpagesize()))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, file.Close())
+	}()
+
+	// payloads span several segments, and some are larger than a segment
+	payloads := testutils.RandomPayloads(200, 1, 3*os.Getpagesize())
+	payloads = append(payloads, ledger.EmptyPayload())
+
+	offsets := make([]uint64, len(payloads))
+	for i, payload := range payloads {
+		offsets[i], err = file.Append(payload)
+		require.NoError(t, err)
+	}
+
+	for i, payload := range payloads {
+		loaded, err := file.Load(offsets[i])
+		require.NoError(t, err)
+		assert.True(t, payload.Equals(loaded))
+	}
+
+	_, err = file.Load(uint64(file.Size()) + 1)
+	assert.Error(t, err)
+}
+
+func TestConcurrentLoads(t *testing.T) {
+	file, err := payloadfile.Open(filepath.Join(t.TempDir(), "payloads"), int64(os.Getpagesize()))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, file.Close())
+	}()
+
+	payloads := testutils.RandomPayloads(1000, 1, 100)
+	offsets := make(chan uint64, len(payloads))
+	indices := make(map[uint64]int, len(payloads))
+	var mu sync.Mutex
+
+	// payloads are loaded while the file is grown
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range offsets {
+				mu.Lock()
+				payload := payloads[indices[offset]]
+				mu.Unlock()
+
+				loaded, err := file.Load(offset)
+				assert.NoError(t, err)
+				assert.True(t, payload.Equals(loaded))
+			}
+		}()
+	}
+
+	for i, payload := range payloads {
+		offset, err := file.Append(payload)
+		require.NoError(t, err)
+		mu.Lock()
+		indices[offset] = i
+		mu.Unlock()
+		offsets <- offset
+	}
+	close(offsets)
+	wg.Wait()
+}
+
+func TestClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payloads")
+	file, err := payloadfile.Open(path, payloadfile.DefaultSegmentSize)
+	require.NoError(t, err)
+
+	payload := testutils.LightPayload(1, 1)
+	offset, err := file.Append(payload)
+	require.NoError(t, err)
+
+	// loaded payloads don't reference the mapped memory
+	loaded, err := file.Load(offset)
+	require.NoError(t, err)
+
+	require.NoError(t, file.Close())
+	require.NoError(t, file.Close())
+	assert.True(t, payload.Equals(loaded))
+
+	_, err = file.Append(payload)
+	assert.True(t, errors.Is(err, payloadfile.ErrClosed))
+
+	_, err = file.Load(offset)
+	assert.Error(t, err)
+
+	// the file is truncated when opened again
+	file, err = payloadfile.Open(path, payloadfile.DefaultSegmentSize)
+	require.NoError(t, err)
+	defer file.Close()
+	assert.Equal(t, int64(0), file.Size())
+	_, err = file.Load(offset)
+	assert.Error(t, err)
+}
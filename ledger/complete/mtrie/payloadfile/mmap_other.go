@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package payloadfile
+
+import (
+	"errors"
+	"os"
+)
+
+// mmap is only supported on linux and darwin.
+func mmap(_ *os.File, _ int64, _ int) ([]byte, error) {
+	return nil, errors.New("memory-mapped payload files are not supported on this platform")
+}
+
+func munmap(_ []byte) error {
+	return nil
+}
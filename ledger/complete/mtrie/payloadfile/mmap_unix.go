@@ -0,0 +1,20 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package payloadfile
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// mmap maps the given region of the file as shared memory, so that writes to the mapping are
+// written to the file, and pages can be evicted from memory by the OS.
+func mmap(f *os.File, offset int64, size int) ([]byte, error) {
+	return unix.Mmap(int(f.Fd()), offset, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+}
+
+func munmap(data []byte) error {
+	return unix.Munmap(data)
+}
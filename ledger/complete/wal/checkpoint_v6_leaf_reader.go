@@ -18,12 +18,16 @@ type LeafNode struct {
 	Payload *ledger.Payload
 }
 
-func nodeToLeaf(leaf *node.Node) *LeafNode {
+func nodeToLeaf(leaf *node.Node) (*LeafNode, error) {
+	payload, err := leaf.Payload()
+	if err != nil {
+		return nil, err
+	}
 	return &LeafNode{
 		Hash:    leaf.Hash(),
 		Path:    *leaf.Path(),
-		Payload: leaf.Payload(),
-	}
+		Payload: payload,
+	}, nil
 }
 
 // OpenAndReadLeafNodesFromCheckpointV6 takes a channel for pushing the leaf nodes that are read from
@@ -86,7 +90,11 @@ func readCheckpointSubTrieLeafNodes(leafNodesCh chan<- *LeafNode, dir string, fi
 					return fmt.Errorf("cannot read node %d: %w", i, err)
 				}
 				if node.IsLeaf() {
-					leafNodesCh <- nodeToLeaf(node)
+					leaf, err := nodeToLeaf(node)
+					if err != nil {
+						return fmt.Errorf("cannot read payload of node %d: %w", i, err)
+					}
+					leafNodesCh <- leaf
 				}
 
 				logging(i)
@@ -350,7 +350,9 @@ func TestWriteAndReadCheckpointV6LeafSimpleTrie(t *testing.T) {
 				resultPayloads = append(resultPayloads, *leafNode.Payload)
 			}
 		}
-		require.EqualValues(t, tries[1].AllPayloads(), resultPayloads)
+		expectedPayloads, err := tries[1].AllPayloads()
+		require.NoError(t, err)
+		require.EqualValues(t, expectedPayloads, resultPayloads)
 	})
 }
 
@@ -0,0 +1,492 @@
+package wal
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+)
+
+// CheckpointPartError reports a part file of a checkpoint which is missing, truncated or corrupt.
+type CheckpointPartError struct {
+	FileName string // name of the part file, without directory
+	Err      error
+}
+
+func (e *CheckpointPartError) Error() string {
+	return fmt.Sprintf("checkpoint part %s: %v", e.FileName, e.Err)
+}
+
+func (e *CheckpointPartError) Unwrap() error {
+	return e.Err
+}
+
+// VerifyCheckpointV6 verifies the integrity of all the files of the given v6 checkpoint, without loading the tries.
+// For every part file, it checks that the file exists, that its checksum matches the checksum in the checkpoint
+// header, and that the hash of every node matches the hash computed from its children or payload. Finally,
+// it checks that the hashes of the trie roots match the root hashes recorded in the checkpoint.
+//
+// Part files are verified by up to nWorker goroutines concurrently. A part's nodes may reference any earlier node
+// of the same part, because nodes shared between tries are only stored once, so the hashes of all the nodes of the
+// part being verified are kept in memory. A worker only starts verifying a part once the hashes of that part fit
+// within memoryLimit bytes together with the parts being verified by the other workers, so a few large parts are
+// verified one after the other rather than concurrently. A part which is larger than memoryLimit on its own is
+// verified while no other part is being verified.
+//
+// It returns one CheckpointPartError for every corrupt part, which is empty if the checkpoint is valid.
+// The returned error is an exception.
+func VerifyCheckpointV6(
+	dir string,
+	fileName string,
+	nWorker int,
+	memoryLimit uint64,
+	logger *zerolog.Logger,
+) ([]*CheckpointPartError, error) {
+	if nWorker < 1 {
+		return nil, fmt.Errorf("number of workers must be positive, got %v", nWorker)
+	}
+	if memoryLimit < 1 || memoryLimit > math.MaxInt64 {
+		return nil, fmt.Errorf("memory limit must be between 1 and %v bytes, got %v", uint64(math.MaxInt64), memoryLimit)
+	}
+
+	lg := logger.With().Str("checkpoint_file", filePathCheckpointHeader(dir, fileName)).Logger()
+
+	subtrieChecksums, topTrieChecksum, err := readCheckpointHeader(filePathCheckpointHeader(dir, fileName), &lg)
+	if err != nil {
+		// without the header none of the part files can be verified
+		return []*CheckpointPartError{{FileName: fileName, Err: err}}, nil
+	}
+
+	var corrupt []*CheckpointPartError
+	topTriesPath, topTriesFileName := filePathTopTries(dir, fileName)
+
+	// the node counts of all subtrie parts are needed to map the node indices of the top level tries to the parts
+	nodeCounts := make([]uint64, len(subtrieChecksums))
+	unreadable := make([]bool, len(subtrieChecksums))
+	countsKnown := true
+	for i := range subtrieChecksums {
+		subtriePath, subtrieFileName, err := filePathSubTries(dir, fileName, i)
+		if err != nil {
+			return nil, err
+		}
+		nodeCounts[i], err = readSubTrieNodeCount(subtriePath)
+		if err != nil {
+			corrupt = append(corrupt, &CheckpointPartError{FileName: subtrieFileName, Err: err})
+			unreadable[i] = true
+			countsKnown = false
+		}
+	}
+
+	var totalSubTrieNodeCount uint64
+	offsets := make([]uint64, len(nodeCounts))
+	for i, count := range nodeCounts {
+		offsets[i] = totalSubTrieNodeCount
+		totalSubTrieNodeCount += count
+	}
+
+	// collect the subtrie nodes which are referenced by the top level nodes and trie roots
+	referenced := make(map[uint64]hash.Hash)
+	err = processCheckpointTopTries(topTriesPath, topTrieChecksum, &lg, func(reader io.Reader, topLevelNodesCount uint64, triesCount uint16) error {
+		count, err := readSubTrieNodeCountOfTopTries(reader)
+		if err != nil {
+			return err
+		}
+		// if some subtrie parts are unreadable, the node indices can not be mapped to the parts
+		if countsKnown && count != totalSubTrieNodeCount {
+			return fmt.Errorf("mismatch subtrie node count, top trie file has %v, subtrie files have %v", count, totalSubTrieNodeCount)
+		}
+		return readTopTriesReferences(reader, count, topLevelNodesCount, triesCount, referenced)
+	})
+	topTriesValid := err == nil
+	if err != nil {
+		corrupt = append(corrupt, &CheckpointPartError{FileName: topTriesFileName, Err: err})
+	}
+
+	// verify the readable subtrie parts concurrently
+	type subtrieResult struct {
+		hashes map[uint64]hash.Hash
+		err    *CheckpointPartError
+	}
+	results := make([]subtrieResult, len(subtrieChecksums))
+	jobs := make(chan int, len(subtrieChecksums))
+	for i := range subtrieChecksums {
+		if !unreadable[i] {
+			jobs <- i
+		}
+	}
+	close(jobs)
+
+	// memory holds the bytes of node hashes which the workers are allowed to keep in memory at the same time
+	memory := semaphore.NewWeighted(int64(memoryLimit))
+	hashesSize := func(i int) int64 {
+		if nodeCounts[i]+1 > memoryLimit/hash.HashLen {
+			return int64(memoryLimit)
+		}
+		return int64((nodeCounts[i] + 1) * hash.HashLen)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < nWorker && w < len(subtrieChecksums); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				_, subtrieFileName, _ := filePathSubTries(dir, fileName, i)
+
+				size := hashesSize(i)
+				// acquiring never fails, as the context is never canceled
+				_ = memory.Acquire(context.Background(), size)
+				lg.Info().Str("part", subtrieFileName).Uint64("node_count", nodeCounts[i]).Msg("verifying checkpoint part")
+
+				hashes, err := verifyCheckpointSubTrie(dir, fileName, i, subtrieChecksums[i], offsets[i], referenced, &lg)
+				memory.Release(size)
+				if err != nil {
+					results[i].err = &CheckpointPartError{FileName: subtrieFileName, Err: err}
+					continue
+				}
+				results[i].hashes = hashes
+			}
+		}()
+	}
+	wg.Wait()
+
+	subtriesValid := countsKnown
+	for _, result := range results {
+		if result.err != nil {
+			subtriesValid = false
+			corrupt = append(corrupt, result.err)
+		}
+		for index, h := range result.hashes {
+			referenced[index] = h
+		}
+	}
+
+	// the root hashes can only be verified if all the referenced subtrie nodes have been verified
+	if !topTriesValid || !subtriesValid {
+		lg.Warn().Msg("skipping verification of trie root hashes, as some parts of the checkpoint are corrupt")
+		return corrupt, nil
+	}
+
+	err = processCheckpointTopTries(topTriesPath, topTrieChecksum, &lg, func(reader io.Reader, topLevelNodesCount uint64, triesCount uint16) error {
+		return verifyTopTries(reader, totalSubTrieNodeCount, topLevelNodesCount, triesCount, referenced)
+	})
+	if err != nil {
+		corrupt = append(corrupt, &CheckpointPartError{FileName: topTriesFileName, Err: err})
+	}
+
+	return corrupt, nil
+}
+
+// readSubTrieNodeCount returns the node count from the footer of the given subtrie part file.
+// The returned error indicates a missing or corrupt file.
+func readSubTrieNodeCount(filePath string) (count uint64, errToReturn error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("could not open file: %w", err)
+	}
+	defer func(file *os.File) {
+		errToReturn = closeAndMergeError(file, errToReturn)
+	}(f)
+
+	err = validateFileHeader(MagicBytesCheckpointSubtrie, VersionV6, f)
+	if err != nil {
+		return 0, err
+	}
+	count, _, err = readSubTriesFooter(f)
+	if err != nil {
+		return 0, fmt.Errorf("could not read footer, the file might be truncated: %w", err)
+	}
+	return count, nil
+}
+
+// verifyCheckpointSubTrie reads all nodes of the given subtrie part and verifies their hashes. It returns the
+// hashes of the nodes with the given global indices, the part's nodes are numbered starting after the given offset.
+// The returned error indicates a corrupt file.
+func verifyCheckpointSubTrie(
+	dir string,
+	fileName string,
+	index int,
+	checksum uint32,
+	offset uint64,
+	referenced map[uint64]hash.Hash,
+	logger *zerolog.Logger,
+) (map[uint64]hash.Hash, error) {
+	found := make(map[uint64]hash.Hash)
+	err := processCheckpointSubTrie(dir, fileName, index, checksum, logger,
+		func(reader *Crc32Reader, nodesCount uint64) error {
+			scratch := make([]byte, 1024*4) // must not be less than 1024
+
+			hashes := make([]hash.Hash, nodesCount+1) //+1 for 0 index meaning nil
+			logging := logProgress(fmt.Sprintf("verifying %v-th sub trie", index), int(nodesCount), logger)
+			for i := uint64(1); i <= nodesCount; i++ {
+				n, err := flattener.ReadNode(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
+					if nodeIndex >= i {
+						return nil, fmt.Errorf("sequence of serialized nodes does not satisfy Descendents-First-Relationship")
+					}
+					return hashOnlyNode(hashes, nodeIndex), nil
+				})
+				if err != nil {
+					return fmt.Errorf("cannot read node %d: %w", i, err)
+				}
+				err = verifyNodeHash(n)
+				if err != nil {
+					return fmt.Errorf("invalid node %d: %w", i, err)
+				}
+
+				hashes[i] = n.Hash()
+				if _, ok := referenced[offset+i]; ok {
+					found[offset+i] = n.Hash()
+				}
+				logging(i)
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// processCheckpointTopTries validates the header, footer and checksum of the top level tries part file,
+// and calls process with a reader positioned at the subtrie node count.
+// The returned error indicates a missing or corrupt file, or is returned by process.
+func processCheckpointTopTries(
+	filePath string,
+	checksum uint32,
+	logger *zerolog.Logger,
+	process func(reader io.Reader, topLevelNodesCount uint64, triesCount uint16) error,
+) (errToReturn error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("could not open file: %w", err)
+	}
+	defer func(file *os.File) {
+		evictErr := evictFileFromLinuxPageCache(file, false, logger)
+		if evictErr != nil {
+			logger.Warn().Msgf("failed to evict top trie file %s from Linux page cache: %s", filePath, evictErr)
+		}
+		errToReturn = closeAndMergeError(file, errToReturn)
+	}(f)
+
+	err = validateFileHeader(MagicBytesCheckpointToptrie, VersionV6, f)
+	if err != nil {
+		return err
+	}
+	topLevelNodesCount, triesCount, expectedSum, err := readTopTriesFooter(f)
+	if err != nil {
+		return fmt.Errorf("could not read footer, the file might be truncated: %w", err)
+	}
+	if checksum != expectedSum {
+		return fmt.Errorf("mismatch top trie checksum, header file has %v, toptrie file has %v", checksum, expectedSum)
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("could not seek to 0: %w", err)
+	}
+	reader := NewCRC32Reader(bufio.NewReaderSize(f, defaultBufioReadSize))
+	_, _, err = readFileHeader(reader)
+	if err != nil {
+		return err
+	}
+
+	err = process(reader, topLevelNodesCount, triesCount)
+	if err != nil {
+		return err
+	}
+
+	scratch := make([]byte, encNodeCountSize+encTrieCountSize)
+	_, err = io.ReadFull(reader, scratch)
+	if err != nil {
+		return fmt.Errorf("cannot read footer: %w", err)
+	}
+	actualSum := reader.Crc32()
+	if actualSum != expectedSum {
+		return fmt.Errorf("invalid checksum in top level trie, expected %v, actual %v", expectedSum, actualSum)
+	}
+	_, err = io.ReadFull(reader, scratch[:crc32SumSize])
+	if err != nil {
+		return fmt.Errorf("could not read checksum: %w", err)
+	}
+	return ensureReachedEOF(reader)
+}
+
+// readTopTriesReferences reads the top level nodes and trie roots and adds the indices of all
+// referenced subtrie nodes to the given map.
+func readTopTriesReferences(
+	reader io.Reader,
+	totalSubTrieNodeCount uint64,
+	topLevelNodesCount uint64,
+	triesCount uint16,
+	referenced map[uint64]hash.Hash,
+) error {
+	scratch := make([]byte, 1024*4) // must not be less than 1024
+	reference := func(nodeIndex uint64) (*node.Node, error) {
+		if nodeIndex != 0 && nodeIndex <= totalSubTrieNodeCount {
+			referenced[nodeIndex] = hash.DummyHash
+		}
+		return nil, nil
+	}
+	for i := uint64(1); i <= topLevelNodesCount; i++ {
+		_, err := flattener.ReadNode(reader, scratch, reference)
+		if err != nil {
+			return fmt.Errorf("cannot read node at index %d: %w", i, err)
+		}
+	}
+	for i := uint16(0); i < triesCount; i++ {
+		rootIndex, _, err := readEncodedTrie(reader, scratch)
+		if err != nil {
+			return fmt.Errorf("cannot read root trie at index %d: %w", i, err)
+		}
+		_, _ = reference(rootIndex)
+	}
+	return nil
+}
+
+// verifyTopTries reads the top level nodes and trie roots, verifies the hashes of the top level nodes
+// and checks that the root hash of every trie matches its recorded root hash.
+// subtrieHashes must contain the verified hashes of all subtrie nodes referenced by the top level tries.
+func verifyTopTries(
+	reader io.Reader,
+	totalSubTrieNodeCount uint64,
+	topLevelNodesCount uint64,
+	triesCount uint16,
+	subtrieHashes map[uint64]hash.Hash,
+) error {
+	count, err := readSubTrieNodeCountOfTopTries(reader)
+	if err != nil {
+		return err
+	}
+	if count != totalSubTrieNodeCount {
+		return fmt.Errorf("mismatch subtrie node count, top trie file has %v, subtrie files have %v", count, totalSubTrieNodeCount)
+	}
+
+	topHashes := make([]hash.Hash, topLevelNodesCount+1) //+1 for 0 index meaning nil
+	nodeHash := func(nodeIndex uint64) (hash.Hash, bool, error) {
+		if nodeIndex == 0 {
+			return hash.DummyHash, false, nil
+		}
+		if nodeIndex <= totalSubTrieNodeCount {
+			h, ok := subtrieHashes[nodeIndex]
+			if !ok {
+				return hash.DummyHash, false, fmt.Errorf("subtrie node %d was not verified", nodeIndex)
+			}
+			return h, true, nil
+		}
+		pos := nodeIndex - totalSubTrieNodeCount
+		if pos >= uint64(len(topHashes)) {
+			return hash.DummyHash, false, fmt.Errorf("can not find node by index %v", nodeIndex)
+		}
+		return topHashes[pos], true, nil
+	}
+
+	scratch := make([]byte, 1024*4) // must not be less than 1024
+	for i := uint64(1); i <= topLevelNodesCount; i++ {
+		n, err := flattener.ReadNode(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
+			if nodeIndex >= i+totalSubTrieNodeCount {
+				return nil, fmt.Errorf("sequence of serialized nodes does not satisfy Descendents-First-Relationship")
+			}
+			h, ok, err := nodeHash(nodeIndex)
+			if err != nil || !ok {
+				return nil, err
+			}
+			return node.NewNode(0, nil, nil, ledger.DummyPath, nil, h), nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot read node at index %d: %w", i, err)
+		}
+		err = verifyNodeHash(n)
+		if err != nil {
+			return fmt.Errorf("invalid node at index %d: %w", i, err)
+		}
+		topHashes[i] = n.Hash()
+	}
+
+	for i := uint16(0); i < triesCount; i++ {
+		rootIndex, recordedHash, err := readEncodedTrie(reader, scratch)
+		if err != nil {
+			return fmt.Errorf("cannot read root trie at index %d: %w", i, err)
+		}
+		rootHash, ok, err := nodeHash(rootIndex)
+		if err != nil {
+			return fmt.Errorf("cannot find root node of trie at index %d: %w", i, err)
+		}
+		if !ok {
+			rootHash = hash.Hash(trie.EmptyTrieRootHash())
+		}
+		if rootHash != recordedHash {
+			return fmt.Errorf("root hash of trie at index %d does not match, recorded %v, computed %v", i, recordedHash, rootHash)
+		}
+	}
+	return nil
+}
+
+func readSubTrieNodeCountOfTopTries(reader io.Reader) (uint64, error) {
+	buf := make([]byte, encNodeCountSize)
+	_, err := io.ReadFull(reader, buf)
+	if err != nil {
+		return 0, fmt.Errorf("could not read subtrie node count: %w", err)
+	}
+	count, err := decodeNodeCount(buf)
+	if err != nil {
+		return 0, fmt.Errorf("could not decode node count: %w", err)
+	}
+	return count, nil
+}
+
+// readEncodedTrie reads a trie encoded by flattener.EncodeTrie and returns its root node index and root hash.
+func readEncodedTrie(reader io.Reader, scratch []byte) (uint64, hash.Hash, error) {
+	// root node index (8 bytes), reg count (8 bytes), reg size (8 bytes), root hash (32 bytes)
+	const rootIndexSize = 8
+	const encodedTrieSize = rootIndexSize + 8 + 8 + hash.HashLen
+	buf := scratch[:encodedTrieSize]
+	_, err := io.ReadFull(reader, buf)
+	if err != nil {
+		return 0, hash.DummyHash, err
+	}
+	rootHash, err := hash.ToHash(buf[encodedTrieSize-hash.HashLen:])
+	if err != nil {
+		return 0, hash.DummyHash, err
+	}
+	return binary.BigEndian.Uint64(buf[:rootIndexSize]), rootHash, nil
+}
+
+// hashOnlyNode returns a node which only carries the hash at the given index, or nil for index 0.
+func hashOnlyNode(hashes []hash.Hash, index uint64) *node.Node {
+	if index == 0 {
+		return nil
+	}
+	return node.NewNode(0, nil, nil, ledger.DummyPath, nil, hashes[index])
+}
+
+// verifyNodeHash checks that the hash of the given node matches the hash computed from
+// its payload (leaf nodes) or from the hashes of its children (interim nodes).
+func verifyNodeHash(n *node.Node) error {
+	var computed hash.Hash
+	if n.IsLeaf() {
+		payload, err := n.Payload()
+		if err != nil {
+			return err
+		}
+		computed = node.NewLeaf(*n.Path(), payload, n.Height()).Hash()
+	} else {
+		computed = node.NewInterimNode(n.Height(), n.LeftChild(), n.RightChild()).Hash()
+	}
+	if computed != n.Hash() {
+		return fmt.Errorf("hash mismatch at height %d, recorded %v, computed %v", n.Height(), n.Hash(), computed)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package wal
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func requireCorruptParts(t *testing.T, dir string, fileName string, expected ...string) {
+	logger := unittest.Logger()
+	corrupt, err := VerifyCheckpointV6(dir, fileName, 4, 1<<30, &logger)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(corrupt))
+	for _, part := range corrupt {
+		names = append(names, part.FileName)
+	}
+	require.ElementsMatch(t, expected, names)
+}
+
+func TestVerifyCheckpointV6(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		tries := createMultipleRandomTries(t)
+		fileName := "checkpoint-verify"
+		logger := unittest.Logger()
+		require.NoError(t, StoreCheckpointV6Concurrently(tries, dir, fileName, &logger))
+
+		requireCorruptParts(t, dir, fileName)
+	})
+}
+
+// TestVerifyCheckpointV6MemoryLimit verifies that parts larger than the memory limit are still verified.
+func TestVerifyCheckpointV6MemoryLimit(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		tries := createMultipleRandomTries(t)
+		fileName := "checkpoint-verify-memory-limit"
+		logger := unittest.Logger()
+		require.NoError(t, StoreCheckpointV6Concurrently(tries, dir, fileName, &logger))
+
+		corrupt, err := VerifyCheckpointV6(dir, fileName, 4, 1, &logger)
+		require.NoError(t, err)
+		require.Empty(t, corrupt)
+
+		_, err = VerifyCheckpointV6(dir, fileName, 4, 0, &logger)
+		require.Error(t, err)
+	})
+}
+
+func TestVerifyCheckpointV6EmptyTrie(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		fileName := "checkpoint-verify-empty"
+		logger := unittest.Logger()
+		require.NoError(t, StoreCheckpointV6Concurrently([]*trie.MTrie{trie.NewEmptyMTrie()}, dir, fileName, &logger))
+
+		requireCorruptParts(t, dir, fileName)
+	})
+}
+
+func TestVerifyCheckpointV6CorruptParts(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		tries := createMultipleRandomTries(t)
+		fileName := "checkpoint-verify-corrupt"
+		logger := unittest.Logger()
+		require.NoError(t, StoreCheckpointV6Concurrently(tries, dir, fileName, &logger))
+
+		// flip a byte in the middle of the 3rd part
+		flipped, flippedName, err := filePathSubTries(dir, fileName, 3)
+		require.NoError(t, err)
+		data, err := os.ReadFile(flipped)
+		require.NoError(t, err)
+		data[len(data)/2] ^= 0xff
+		require.NoError(t, os.WriteFile(flipped, data, 0644))
+
+		// truncate the 5th part
+		truncated, truncatedName, err := filePathSubTries(dir, fileName, 5)
+		require.NoError(t, err)
+		require.NoError(t, os.Truncate(truncated, 20))
+
+		// remove the 9th part
+		removed, removedName, err := filePathSubTries(dir, fileName, 9)
+		require.NoError(t, err)
+		require.NoError(t, os.Remove(removed))
+
+		requireCorruptParts(t, dir, fileName, flippedName, truncatedName, removedName)
+	})
+}
+
+func TestVerifyCheckpointV6CorruptTopTries(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		tries := createMultipleRandomTries(t)
+		fileName := "checkpoint-verify-corrupt-top"
+		logger := unittest.Logger()
+		require.NoError(t, StoreCheckpointV6Concurrently(tries, dir, fileName, &logger))
+
+		topTries, topTriesName := filePathTopTries(dir, fileName)
+		data, err := os.ReadFile(topTries)
+		require.NoError(t, err)
+		data[len(data)/2] ^= 0xff
+		require.NoError(t, os.WriteFile(topTries, data, 0644))
+
+		requireCorruptParts(t, dir, fileName, topTriesName)
+	})
+}
+
+func TestVerifyCheckpointV6InvalidHash(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		// a trie whose root node has a hash which does not match its children,
+		// the checksums of the files are valid, since they are computed when writing the checkpoint
+		valid := createSimpleTrie(t)[1]
+		root := valid.RootNode()
+		invalidRoot := node.NewNode(root.Height(), root.LeftChild(), root.RightChild(), ledger.DummyPath, nil, hash.Hash{1, 2, 3})
+		invalid, err := trie.NewMTrie(invalidRoot, valid.AllocatedRegCount(), valid.AllocatedRegSize())
+		require.NoError(t, err)
+
+		fileName := "checkpoint-verify-invalid-hash"
+		logger := unittest.Logger()
+		require.NoError(t, StoreCheckpointV6Concurrently([]*trie.MTrie{invalid}, dir, fileName, &logger))
+
+		_, topTriesName := filePathTopTries(dir, fileName)
+		requireCorruptParts(t, dir, fileName, topTriesName)
+	})
+}
+
+func TestVerifyCheckpointV6CorruptHeader(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		tries := createSimpleTrie(t)
+		fileName := "checkpoint-verify-corrupt-header"
+		logger := unittest.Logger()
+		require.NoError(t, StoreCheckpointV6Concurrently(tries, dir, fileName, &logger))
+
+		header := filePathCheckpointHeader(dir, fileName)
+		data, err := os.ReadFile(header)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(header, data[:len(data)-1], 0644))
+
+		requireCorruptParts(t, dir, fileName, fileName)
+	})
+}
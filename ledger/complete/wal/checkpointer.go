@@ -561,8 +561,11 @@ func storeUniqueNodes(
 			}
 		}
 
-		encNode := flattener.EncodeNode(n, lchildIndex, rchildIndex, scratch)
-		_, err := writer.Write(encNode)
+		encNode, err := flattener.EncodeNode(n, lchildIndex, rchildIndex, scratch)
+		if err != nil {
+			return 0, fmt.Errorf("cannot encode node: %w", err)
+		}
+		_, err = writer.Write(encNode)
 		if err != nil {
 			return 0, fmt.Errorf("cannot serialize node: %w", err)
 		}
@@ -71,9 +71,16 @@ func ComputeCompactValue(path hash.Hash, value []byte, nodeHeight int) hash.Hash
 		return GetDefaultHashForHeight(nodeHeight)
 	}
 
-	var out hash.Hash
-	out = hash.HashLeaf(path, value)   // we first compute the hash of the fully-expanded leaf
-	for h := 1; h <= nodeHeight; h++ { // then, we hash our way upwards towards the root until we hit the specified nodeHeight
+	// we first compute the hash of the fully-expanded leaf
+	return ComputeCompactValueFromLeafHash(path, hash.HashLeaf(path, value), nodeHeight)
+}
+
+// ComputeCompactValueFromLeafHash computes the value for the node considering the sub tree
+// to only include the allocated register with the given fully-expanded leaf hash, i.e. the
+// hash of the register's path and value at height 0, and default values.
+func ComputeCompactValueFromLeafHash(path hash.Hash, leafHash hash.Hash, nodeHeight int) hash.Hash {
+	out := leafHash
+	for h := 1; h <= nodeHeight; h++ { // we hash our way upwards towards the root until we hit the specified nodeHeight
 		// h is the height of the node, whose hash we are computing in this iteration.
 		// The hash is computed from the node's children at height h-1.
 		bit := bitutils.ReadBit(path[:], NodeMaxHeight-h)
@@ -0,0 +1,142 @@
+package dkg
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// ParticipantStatus is the outcome of a DKG participant, as reconstructed from a transcript.
+type ParticipantStatus string
+
+const (
+	// ParticipantOK is a participant from which all expected messages were received.
+	ParticipantOK ParticipantStatus = "ok"
+	// ParticipantIncomplete is a participant from which some, but not all expected messages were received.
+	ParticipantIncomplete ParticipantStatus = "incomplete"
+	// ParticipantOffline is a participant from which no message was received.
+	ParticipantOffline ParticipantStatus = "offline"
+	// ParticipantMisbehaved is a participant which was disqualified or flagged, sent invalid messages
+	// or did not answer complaints against it.
+	ParticipantMisbehaved ParticipantStatus = "misbehaved"
+)
+
+// ParticipantReport summarizes the messages of one DKG participant observed by the recording node.
+type ParticipantReport struct {
+	Index                      int
+	NodeID                     flow.Identifier
+	Status                     ParticipantStatus
+	SharesReceived             int
+	BroadcastsReceived         int
+	VerificationVectorReceived bool
+	ComplaintsFiled            []int    // dealers this participant complained about
+	ComplaintsAgainst          []int    // participants which complained about this participant
+	UnansweredComplaints       []int    // complainers which did not receive an answer from this participant
+	Disqualifications          []string // reasons this participant was disqualified
+	MisbehaviorFlags           []string // reasons this participant was flagged
+	InvalidMessages            []string // reasons messages of this participant were dropped
+}
+
+// PostMortem is the per-participant analysis of a DKG transcript.
+type PostMortem struct {
+	Info             TranscriptInfo
+	Participants     []*ParticipantReport
+	BroadcastsSent   int  // broadcasts of the recording node published to the DKG contract
+	BroadcastsFailed int  // broadcasts of the recording node which could not be published
+	ResultSubmitted  bool // whether the recording node submitted its DKG result
+}
+
+// AnalyzeTranscript reconstructs which participants of a DKG misbehaved or were offline, from the
+// point of view of the node which recorded the transcript.
+func AnalyzeTranscript(transcript *Transcript) *PostMortem {
+	pm := &PostMortem{
+		Info:         transcript.Info,
+		Participants: make([]*ParticipantReport, len(transcript.Info.Committee)),
+	}
+	for i, nodeID := range transcript.Info.Committee {
+		pm.Participants[i] = &ParticipantReport{
+			Index:  i,
+			NodeID: nodeID,
+		}
+	}
+	participant := func(index int) *ParticipantReport {
+		if index < 0 || index >= len(pm.Participants) {
+			return nil
+		}
+		return pm.Participants[index]
+	}
+
+	// answered[dealer][complainer] is true if the dealer answered the complaint
+	answered := make(map[int]map[int]bool)
+
+	for _, event := range transcript.Events {
+		switch event.Type {
+		case TranscriptBroadcastSent:
+			pm.BroadcastsSent++
+		case TranscriptBroadcastFailed:
+			pm.BroadcastsFailed++
+		case TranscriptResultSubmitted:
+			pm.ResultSubmitted = true
+		case TranscriptPrivateReceived:
+			if p := participant(event.From); p != nil && event.Kind == MessageShare {
+				p.SharesReceived++
+			}
+		case TranscriptBroadcastReceived:
+			p := participant(event.From)
+			if p == nil {
+				continue
+			}
+			p.BroadcastsReceived++
+			switch event.Kind {
+			case MessageVerificationVector:
+				p.VerificationVectorReceived = true
+			case MessageComplaint:
+				p.ComplaintsFiled = append(p.ComplaintsFiled, event.Subject)
+				if dealer := participant(event.Subject); dealer != nil {
+					dealer.ComplaintsAgainst = append(dealer.ComplaintsAgainst, event.From)
+				}
+			case MessageComplaintAnswer:
+				if answered[event.From] == nil {
+					answered[event.From] = make(map[int]bool)
+				}
+				answered[event.From][event.Subject] = true
+			}
+		case TranscriptInvalidMessage:
+			if p := participant(event.From); p != nil {
+				p.InvalidMessages = append(p.InvalidMessages, event.Reason)
+			}
+		case TranscriptDisqualified:
+			if p := participant(event.Subject); p != nil {
+				p.Disqualifications = append(p.Disqualifications, event.Reason)
+			}
+		case TranscriptMisbehaviorFlagged:
+			if p := participant(event.Subject); p != nil {
+				p.MisbehaviorFlags = append(p.MisbehaviorFlags, event.Reason)
+			}
+		}
+	}
+
+	for _, p := range pm.Participants {
+		for _, complainer := range p.ComplaintsAgainst {
+			if !answered[p.Index][complainer] {
+				p.UnansweredComplaints = append(p.UnansweredComplaints, complainer)
+			}
+		}
+		p.Status = participantStatus(p, p.Index == transcript.Info.MyIndex)
+	}
+
+	return pm
+}
+
+// participantStatus classifies a participant. No private share is expected from the recording node itself.
+func participantStatus(p *ParticipantReport, self bool) ParticipantStatus {
+	if p.SharesReceived == 0 && p.BroadcastsReceived == 0 && len(p.InvalidMessages) == 0 {
+		return ParticipantOffline
+	}
+	if len(p.Disqualifications) > 0 || len(p.MisbehaviorFlags) > 0 ||
+		len(p.InvalidMessages) > 0 || len(p.UnansweredComplaints) > 0 {
+		return ParticipantMisbehaved
+	}
+	if !p.VerificationVectorReceived || (!self && p.SharesReceived == 0) {
+		return ParticipantIncomplete
+	}
+	return ParticipantOK
+}
@@ -0,0 +1,80 @@
+package dkg_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/dkg"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestMessageKindOf(t *testing.T) {
+	kind, subject := dkg.MessageKindOf([]byte{0, 42})
+	assert.Equal(t, dkg.MessageShare, kind)
+	assert.Equal(t, dkg.NoParticipant, subject)
+
+	kind, subject = dkg.MessageKindOf([]byte{2, 3})
+	assert.Equal(t, dkg.MessageComplaint, kind)
+	assert.Equal(t, 3, subject)
+
+	kind, subject = dkg.MessageKindOf([]byte{3, 1, 9, 9})
+	assert.Equal(t, dkg.MessageComplaintAnswer, kind)
+	assert.Equal(t, 1, subject)
+
+	kind, _ = dkg.MessageKindOf(nil)
+	assert.Equal(t, dkg.MessageUnknown, kind)
+}
+
+func TestAnalyzeTranscript(t *testing.T) {
+	// participant 0 is the recording node, 1 is ok, 2 never answers a complaint, 3 is offline
+	// and 4 only broadcast its verification vector
+	transcript := &dkg.Transcript{
+		Info: dkg.TranscriptInfo{
+			DKGInstanceID: "dkg-test-1",
+			Committee:     unittest.IdentifierListFixture(5),
+			MyIndex:       0,
+		},
+	}
+	event := func(eventType dkg.TranscriptEventType, kind dkg.MessageKind, from int, subject int) {
+		transcript.Events = append(transcript.Events, dkg.TranscriptEvent{
+			Type:    eventType,
+			Kind:    kind,
+			From:    from,
+			To:      dkg.NoParticipant,
+			Subject: subject,
+		})
+	}
+	for _, i := range []int{0, 1, 2, 4} {
+		event(dkg.TranscriptBroadcastReceived, dkg.MessageVerificationVector, i, dkg.NoParticipant)
+	}
+	event(dkg.TranscriptBroadcastSent, dkg.MessageVerificationVector, 0, dkg.NoParticipant)
+	event(dkg.TranscriptPrivateReceived, dkg.MessageShare, 1, dkg.NoParticipant)
+	event(dkg.TranscriptPrivateReceived, dkg.MessageShare, 2, dkg.NoParticipant)
+	// participants 1 and 0 complain about 2, only the complaint of 1 is answered
+	event(dkg.TranscriptBroadcastReceived, dkg.MessageComplaint, 1, 2)
+	event(dkg.TranscriptBroadcastReceived, dkg.MessageComplaint, 0, 2)
+	event(dkg.TranscriptBroadcastReceived, dkg.MessageComplaintAnswer, 2, 1)
+	event(dkg.TranscriptDisqualified, "", 0, 2)
+	event(dkg.TranscriptResultSubmitted, "", 0, dkg.NoParticipant)
+
+	pm := dkg.AnalyzeTranscript(transcript)
+	require.Len(t, pm.Participants, 5)
+	assert.Equal(t, 1, pm.BroadcastsSent)
+	assert.True(t, pm.ResultSubmitted)
+
+	assert.Equal(t, dkg.ParticipantOK, pm.Participants[0].Status)
+	assert.Equal(t, dkg.ParticipantOK, pm.Participants[1].Status)
+	assert.Equal(t, []int{2}, pm.Participants[1].ComplaintsFiled)
+
+	p2 := pm.Participants[2]
+	assert.Equal(t, dkg.ParticipantMisbehaved, p2.Status)
+	assert.Equal(t, []int{1, 0}, p2.ComplaintsAgainst)
+	assert.Equal(t, []int{0}, p2.UnansweredComplaints)
+	assert.Len(t, p2.Disqualifications, 1)
+
+	assert.Equal(t, dkg.ParticipantOffline, pm.Participants[3].Status)
+	assert.Equal(t, dkg.ParticipantIncomplete, pm.Participants[4].Status)
+	assert.Equal(t, transcript.Info.Committee[4], pm.Participants[4].NodeID)
+}
@@ -0,0 +1,107 @@
+package dkg
+
+import (
+	"time"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// TranscriptEventType is the type of an event recorded in a DKG transcript.
+type TranscriptEventType string
+
+const (
+	// TranscriptPrivateSent is recorded when this node sends a private message to a participant.
+	TranscriptPrivateSent TranscriptEventType = "private_sent"
+	// TranscriptPrivateReceived is recorded when this node receives a valid private message from a participant.
+	TranscriptPrivateReceived TranscriptEventType = "private_received"
+	// TranscriptBroadcastSent is recorded when a broadcast message of this node is published to the DKG contract.
+	TranscriptBroadcastSent TranscriptEventType = "broadcast_sent"
+	// TranscriptBroadcastFailed is recorded when a broadcast message of this node could not be published.
+	TranscriptBroadcastFailed TranscriptEventType = "broadcast_failed"
+	// TranscriptBroadcastReceived is recorded when this node reads a valid broadcast message of a participant.
+	TranscriptBroadcastReceived TranscriptEventType = "broadcast_received"
+	// TranscriptInvalidMessage is recorded when this node drops a private or broadcast message of a participant.
+	TranscriptInvalidMessage TranscriptEventType = "invalid_message"
+	// TranscriptDisqualified is recorded when the DKG of this node disqualifies a participant.
+	TranscriptDisqualified TranscriptEventType = "disqualified"
+	// TranscriptMisbehaviorFlagged is recorded when the DKG of this node flags a misbehaving participant.
+	TranscriptMisbehaviorFlagged TranscriptEventType = "misbehavior_flagged"
+	// TranscriptResultSubmitted is recorded when this node submitted its DKG result to the DKG contract.
+	TranscriptResultSubmitted TranscriptEventType = "result_submitted"
+)
+
+// MessageKind is the kind of a DKG message, as given by the header byte of the message.
+type MessageKind string
+
+const (
+	MessageShare              MessageKind = "share"
+	MessageVerificationVector MessageKind = "verification_vector"
+	MessageComplaint          MessageKind = "complaint"
+	MessageComplaintAnswer    MessageKind = "complaint_answer"
+	MessageUnknown            MessageKind = "unknown"
+)
+
+// NoParticipant is used in transcript events for participant indices which don't apply to the event.
+const NoParticipant = -1
+
+// MessageKindOf returns the kind of the given DKG message, and the participant the message is about:
+// the accused dealer for complaints, and the complainer for complaint answers.
+// The index is NoParticipant for all other kinds of messages.
+func MessageKindOf(data []byte) (MessageKind, int) {
+	if len(data) == 0 {
+		return MessageUnknown, NoParticipant
+	}
+	switch data[0] {
+	case 0:
+		return MessageShare, NoParticipant
+	case 1:
+		return MessageVerificationVector, NoParticipant
+	case 2:
+		if len(data) < 2 {
+			return MessageComplaint, NoParticipant
+		}
+		return MessageComplaint, int(data[1])
+	case 3:
+		if len(data) < 2 {
+			return MessageComplaintAnswer, NoParticipant
+		}
+		return MessageComplaintAnswer, int(data[1])
+	default:
+		return MessageUnknown, NoParticipant
+	}
+}
+
+// TranscriptInfo describes the DKG a transcript was recorded for.
+type TranscriptInfo struct {
+	DKGInstanceID string
+	Committee     flow.IdentifierList // node IDs of the DKG participants, in index order
+	MyIndex       int                 // index of the recording node in the committee
+	StartedAt     time.Time
+}
+
+// TranscriptEvent is a single event of a DKG transcript, as observed by the recording node.
+// Events only contain message metadata; the contents of messages are never recorded, as they
+// may contain secret key shares.
+type TranscriptEvent struct {
+	Time time.Time
+	Type TranscriptEventType
+	// Kind is the kind of the sent or received message, empty for events which are not about a message.
+	Kind MessageKind
+	// From is the index of the sender of a message, NoParticipant if the sender is not a committee member.
+	From int
+	// To is the index of the recipient of a private message, NoParticipant for broadcast messages.
+	To int
+	// Subject is the participant the event is about: the accused dealer of a complaint, the complainer
+	// of a complaint answer, or the participant which was disqualified or flagged. NoParticipant otherwise.
+	Subject int
+	// Size is the size of the message in bytes.
+	Size int
+	// Reason explains why a message was dropped, or a participant disqualified or flagged.
+	Reason string
+}
+
+// Transcript is the record of the messages exchanged by a node during one DKG.
+type Transcript struct {
+	Info   TranscriptInfo
+	Events []TranscriptEvent
+}
@@ -56,6 +56,23 @@ func (br *BlockResponse) BlocksInternal() []*flow.Block {
 	return internal
 }
 
+// HeaderRangeRequest is part of the synchronization protocol and is used by
+// header-first synchronization. It requests the headers of finalized blocks
+// by a range of block heights, including from and to heights.
+type HeaderRangeRequest struct {
+	Nonce      uint64
+	FromHeight uint64
+	ToHeight   uint64
+}
+
+// HeaderResponse is part of the synchronization protocol and represents the
+// reply to a header range request. It contains a chain of finalized headers
+// in ascending height order.
+type HeaderResponse struct {
+	Nonce   uint64
+	Headers []flow.Header
+}
+
 // ClusterBlockResponse is the same thing as BlockResponse, but for cluster
 // consensus.
 type ClusterBlockResponse struct {
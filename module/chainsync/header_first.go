@@ -0,0 +1,461 @@
+package chainsync
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/model"
+	"github.com/onflow/flow-go/model/chainsync"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/utils/logging"
+)
+
+type HeaderFirstConfig struct {
+	Threshold       uint64        // how far the local finalized height must be behind the height reported by peers before syncing header-first, 0 disables header-first sync
+	HeaderBatchSize uint          // the maximum number of headers we request in the same header range request
+	MaxHeadersAhead uint          // the maximum number of validated headers above the local finalized height
+	BodyBatchSize   uint          // the maximum number of blocks we request in the same payload request
+	MaxBodyRequests uint          // the maximum number of payload requests in flight, each sent to a different peer
+	RetryInterval   time.Duration // the interval before we retry a header range or payload request
+	StallTimeout    time.Duration // the time without finalization progress after which the validated headers are discarded, and blocks are synced with range requests for the same duration
+}
+
+// DefaultHeaderFirstConfig returns the default header-first configuration, which has header-first
+// sync disabled.
+func DefaultHeaderFirstConfig() HeaderFirstConfig {
+	return HeaderFirstConfig{
+		Threshold:       0,
+		HeaderBatchSize: 256,
+		MaxHeadersAhead: 4096,
+		BodyBatchSize:   16,
+		MaxBodyRequests: 8,
+		RetryInterval:   4 * time.Second,
+		StallTimeout:    time.Minute,
+	}
+}
+
+// QCValidator validates quorum certificates. hotstuff.Validator implements QCValidator.
+type QCValidator interface {
+	// ValidateQC checks the validity of a QC.
+	// During normal operations, the following error returns are expected:
+	//  * model.InvalidQCError if the QC is invalid
+	//  * model.ErrViewForUnknownEpoch if the QC refers unknown epoch
+	ValidateQC(qc *flow.QuorumCertificate) error
+}
+
+// pendingBlock is a block with a validated header, whose payload is downloaded.
+type pendingBlock struct {
+	header    *flow.Header
+	validated time.Time // when the header was validated
+	requested time.Time // when the payload was last requested, zero if it was never requested
+	queued    time.Duration
+	block     *flow.Block // the downloaded block, nil until its payload is received
+	originID  flow.Identifier
+	received  time.Time
+}
+
+// HeaderFirst contains the logic of header-first chain synchronization, for nodes which are far
+// behind the finalized chain of their peers.
+//
+// Instead of downloading full blocks one range after the other, HeaderFirst first fetches chains
+// of headers and validates them: each header must extend the previous one, and the QC of each
+// header, which is contained in its child, must be valid. As each header commits to its payload,
+// the payloads of the validated headers are then downloaded in parallel from several peers, and
+// each downloaded block is checked simply by comparing its ID with the validated header. The
+// downloaded blocks are released strictly in height order, to be forwarded to the compliance
+// engine, which fully validates them.
+//
+// A chain of certified headers is not necessarily finalized, a malicious peer could serve a
+// certified fork. The blocks of such a fork are orphaned by the compliance layer, and HeaderFirst
+// discards its header chain as soon as it conflicts with the local finalized block. If the local
+// finalized height does not advance for StallTimeout, e.g. because the released blocks of a fork
+// are never finalized, HeaderFirst discards its header chain as well, and steps aside for
+// StallTimeout so that the blocks are synced with regular range requests.
+//
+// HeaderFirst is safe for concurrent use by multiple goroutines.
+type HeaderFirst struct {
+	log       zerolog.Logger
+	config    HeaderFirstConfig
+	validator QCValidator
+	metrics   module.HeaderFirstSyncMetrics
+
+	mu        sync.Mutex
+	final     *flow.Header    // the latest local finalized header
+	target    uint64          // the highest finalized height reported by peers
+	last      *flow.Header    // the highest validated header, or the finalized header if there is none above it
+	pending   []*pendingBlock // the blocks with validated headers which were not released yet, in height order
+	byID      map[flow.Identifier]*pendingBlock
+	released  uint64    // the height of the highest block released for forwarding
+	requested time.Time // when the in-flight header range request was sent, zero if there is none
+	active    bool
+	progress  time.Time // when the local finalized height last advanced while active, zero if inactive
+	fallback  time.Time // until when blocks are synced with range requests after a stall
+}
+
+func NewHeaderFirst(log zerolog.Logger, config HeaderFirstConfig, validator QCValidator, metrics module.HeaderFirstSyncMetrics, final *flow.Header) *HeaderFirst {
+	return &HeaderFirst{
+		log:       log.With().Str("sync_core", "header_first").Logger(),
+		config:    config,
+		validator: validator,
+		metrics:   metrics,
+		final:     final,
+		last:      final,
+		byID:      make(map[flow.Identifier]*pendingBlock),
+		released:  final.Height,
+	}
+}
+
+// HandleHeight handles receiving a new highest finalized height from another node.
+func (h *HeaderFirst) HandleHeight(final *flow.Header, height uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.updateFinal(final)
+	if height > h.target {
+		h.target = height
+	}
+}
+
+// Active returns whether header-first sync is in progress. While it is active, the finalized
+// blocks are downloaded header-first, and range requests for full blocks are redundant.
+func (h *HeaderFirst) Active() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.active
+}
+
+// ScanPending returns the header range and the payload batches which should be requested. At
+// most one header range is returned, as each header range extends the chain of validated headers.
+func (h *HeaderFirst) ScanPending(final *flow.Header) ([]chainsync.Range, []chainsync.Batch) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.updateFinal(final)
+	h.metrics.HeaderFirstProgress(h.final.Height, h.last.Height, h.released)
+
+	now := time.Now()
+
+	// header-first sync starts when we fall behind by the threshold, and continues until all
+	// validated headers are released
+	h.active = now.After(h.fallback) &&
+		(len(h.pending) > 0 || h.target >= h.final.Height+h.config.Threshold)
+	if !h.active {
+		h.progress = time.Time{}
+		return nil, nil
+	}
+
+	if h.progress.IsZero() {
+		h.progress = now
+	}
+	if now.Sub(h.progress) > h.config.StallTimeout {
+		h.log.Warn().
+			Uint64("final_height", h.final.Height).
+			Uint64("validated_height", h.last.Height).
+			Uint64("released_height", h.released).
+			Msg("finalized height did not advance, discarding validated header chain and falling back to range requests")
+		h.discard()
+		h.active = false
+		h.progress = time.Time{}
+		h.fallback = now.Add(h.config.StallTimeout)
+		return nil, nil
+	}
+
+	var ranges []chainsync.Range
+	headerRetry := h.requested.Add(h.config.RetryInterval)
+	if h.last.Height < h.target &&
+		h.last.Height-h.final.Height < uint64(h.config.MaxHeadersAhead) &&
+		(h.requested.IsZero() || now.After(headerRetry)) {
+
+		// the last header of a range is certified by the first header of the next range, so the
+		// next range starts right above the highest validated header
+		to := h.last.Height + uint64(h.config.HeaderBatchSize)
+		if to > h.target {
+			to = h.target
+		}
+		ranges = append(ranges, chainsync.Range{From: h.last.Height + 1, To: to})
+	}
+
+	var batches []chainsync.Batch
+	var blockIDs []flow.Identifier
+	inFlight := uint(0)
+	for _, pending := range h.pending {
+		if pending.block != nil {
+			continue
+		}
+		if !pending.requested.IsZero() && now.Before(pending.requested.Add(h.config.RetryInterval)) {
+			inFlight++
+			continue
+		}
+		blockIDs = append(blockIDs, pending.header.ID())
+	}
+
+	// the payloads of the lowest blocks are requested first, as the blocks are released in
+	// height order
+	maxRequested := h.config.MaxBodyRequests * h.config.BodyBatchSize
+	for len(blockIDs) > 0 && inFlight < maxRequested {
+		size := h.config.BodyBatchSize
+		if size > maxRequested-inFlight {
+			size = maxRequested - inFlight
+		}
+		if size > uint(len(blockIDs)) {
+			size = uint(len(blockIDs))
+		}
+		batches = append(batches, chainsync.Batch{BlockIDs: blockIDs[:size]})
+		blockIDs = blockIDs[size:]
+		inFlight += size
+	}
+
+	return ranges, batches
+}
+
+// HeadersRequested updates the state for a header range which has been successfully requested.
+// Must be called when a header range request is submitted.
+func (h *HeaderFirst) HeadersRequested(ran chainsync.Range) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.requested = time.Now()
+}
+
+// BodiesRequested updates the state for a batch of payloads which has been successfully
+// requested. Must be called when a payload request is submitted.
+func (h *HeaderFirst) BodiesRequested(batch chainsync.Batch) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for _, blockID := range batch.BlockIDs {
+		pending, ok := h.byID[blockID]
+		if !ok {
+			continue
+		}
+		if pending.requested.IsZero() {
+			pending.queued = now.Sub(pending.validated)
+		}
+		pending.requested = now
+	}
+}
+
+// HandleHeaders handles receiving a chain of headers from another node. The headers which extend
+// the chain of validated headers, and are certified by a valid QC in their child, are appended
+// to the chain. Headers which do not connect to the chain are ignored, as they respond to an
+// outdated request.
+// No errors are expected during normal operation.
+func (h *HeaderFirst) HandleHeaders(originID flow.Identifier, headers []*flow.Header) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	log := h.log.With().Hex("origin_id", originID[:]).Logger()
+	if len(headers) == 0 || headers[0].ParentID != h.last.ID() {
+		log.Debug().Msg("discarding headers not extending the validated header chain")
+		return nil
+	}
+
+	if !h.requested.IsZero() {
+		h.metrics.HeadersReceived(len(headers), time.Since(h.requested))
+	}
+	h.requested = time.Time{}
+
+	start := time.Now()
+	parent := h.last
+	var certified []*flow.Header
+	for _, header := range headers {
+		err := h.validateChild(parent, header)
+		if errors.Is(err, model.ErrViewForUnknownEpoch) {
+			// the epoch of the remaining headers will become known once the blocks of the
+			// epoch setup and commit events are finalized
+			log.Debug().Uint64("height", header.Height).Msg("stopping header validation at unknown epoch")
+			break
+		}
+		if model.IsInvalidQCError(err) || errors.Is(err, errInvalidHeaderChain) {
+			log.Warn().Err(err).
+				Uint64("height", header.Height).
+				Bool(logging.KeySuspicious, true).
+				Msg("received invalid header chain")
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("could not validate header (height: %d): %w", header.Height, err)
+		}
+
+		// the child of the parent contains a valid QC for it, so the parent is certified
+		if parent != h.last {
+			certified = append(certified, parent)
+		}
+		parent = header
+	}
+
+	now := time.Now()
+	for _, header := range certified {
+		pending := &pendingBlock{
+			header:    header,
+			validated: now,
+		}
+		h.pending = append(h.pending, pending)
+		h.byID[header.ID()] = pending
+	}
+	if len(certified) > 0 {
+		h.last = certified[len(certified)-1]
+	}
+	h.metrics.HeadersValidated(len(certified), now.Sub(start))
+
+	log.Debug().
+		Int("received", len(headers)).
+		Int("certified", len(certified)).
+		Uint64("validated_height", h.last.Height).
+		Msg("handled headers")
+
+	return nil
+}
+
+// errInvalidHeaderChain is returned by validateChild if a header does not extend its parent.
+var errInvalidHeaderChain = errors.New("invalid header chain")
+
+// validateChild checks that the child extends the parent, and that the QC for the parent in the
+// child is valid.
+// Expected errors during normal operations:
+//   - errInvalidHeaderChain if the child does not extend the parent
+//   - model.InvalidQCError if the QC for the parent is invalid
+//   - model.ErrViewForUnknownEpoch if the QC refers to an unknown epoch
+func (h *HeaderFirst) validateChild(parent *flow.Header, child *flow.Header) error {
+	if child.ChainID != parent.ChainID {
+		return fmt.Errorf("header has chain ID %s, expected %s: %w", child.ChainID, parent.ChainID, errInvalidHeaderChain)
+	}
+	if child.Height != parent.Height+1 {
+		return fmt.Errorf("header has height %d, expected %d: %w", child.Height, parent.Height+1, errInvalidHeaderChain)
+	}
+	if child.ParentID != parent.ID() || child.ParentView != parent.View {
+		return fmt.Errorf("header does not extend its parent: %w", errInvalidHeaderChain)
+	}
+	if child.View <= parent.View {
+		return fmt.Errorf("header has view %d, not above parent view %d: %w", child.View, parent.View, errInvalidHeaderChain)
+	}
+
+	return h.validator.ValidateQC(child.QuorumCertificate())
+}
+
+// HandleBlock handles receiving a block from another node. It returns true if the block has a
+// validated header, and is either kept to be released in height order or dropped because its
+// payload does not match the header. It returns false if the block should be processed otherwise.
+func (h *HeaderFirst) HandleBlock(originID flow.Identifier, block *flow.Block) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	blockID := block.ID()
+	pending, ok := h.byID[blockID]
+	if !ok {
+		return false
+	}
+	if pending.block != nil {
+		// we already received this block, so the duplicate can be dropped
+		return true
+	}
+
+	// the block ID only commits to the payload hash in the header, so the payload must be
+	// checked against it. The payload is requested again after the retry interval
+	if block.Payload == nil || block.Payload.Hash() != pending.header.PayloadHash {
+		h.log.Warn().
+			Hex("origin_id", originID[:]).
+			Hex("block_id", blockID[:]).
+			Uint64("height", pending.header.Height).
+			Bool(logging.KeySuspicious, true).
+			Msg("received block with payload not matching its validated header")
+		return true
+	}
+
+	now := time.Now()
+	pending.block = block
+	pending.originID = originID
+	pending.received = now
+
+	if !pending.requested.IsZero() {
+		h.metrics.BodyReceived(pending.queued, now.Sub(pending.validated)-pending.queued)
+	}
+
+	return true
+}
+
+// ReadyBlocks returns the downloaded blocks which can be forwarded to the compliance engine, i.e.
+// the consecutive blocks above the highest released block, in height order.
+func (h *HeaderFirst) ReadyBlocks() []flow.Slashable[*flow.Block] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var ready []flow.Slashable[*flow.Block]
+	now := time.Now()
+	for len(h.pending) > 0 && h.pending[0].block != nil {
+		pending := h.pending[0]
+		h.pending = h.pending[1:]
+		delete(h.byID, pending.header.ID())
+
+		ready = append(ready, flow.Slashable[*flow.Block]{
+			OriginID: pending.originID,
+			Message:  pending.block,
+		})
+		h.released = pending.header.Height
+		h.metrics.BlockForwarded(now.Sub(pending.received))
+	}
+
+	return ready
+}
+
+// updateFinal updates the local finalized header, and drops the blocks at or below it. The whole
+// header chain is discarded if it conflicts with the finalized block.
+func (h *HeaderFirst) updateFinal(final *flow.Header) {
+	if final.Height <= h.final.Height {
+		return
+	}
+	h.final = final
+	finalID := final.ID()
+	if h.active {
+		h.progress = time.Now()
+	}
+
+	conflicting := false
+	for len(h.pending) > 0 && h.pending[0].header.Height <= final.Height {
+		pending := h.pending[0]
+		if pending.header.Height == final.Height && pending.header.ID() != finalID {
+			conflicting = true
+		}
+		h.pending = h.pending[1:]
+		delete(h.byID, pending.header.ID())
+	}
+	if len(h.pending) > 0 && h.pending[0].header.Height == final.Height+1 && h.pending[0].header.ParentID != finalID {
+		conflicting = true
+	}
+	if h.last.Height == final.Height && h.last.ID() != finalID {
+		conflicting = true
+	}
+
+	if conflicting {
+		h.log.Warn().
+			Uint64("final_height", final.Height).
+			Hex("final_id", finalID[:]).
+			Msg("validated header chain conflicts with finalized block, discarding it")
+		h.discard()
+		return
+	}
+
+	if h.last.Height <= final.Height {
+		h.last = final
+	}
+	if h.released < final.Height {
+		h.released = final.Height
+	}
+}
+
+// discard drops the whole validated header chain, so that headers are requested again right
+// above the local finalized block.
+func (h *HeaderFirst) discard() {
+	h.pending = nil
+	h.byID = make(map[flow.Identifier]*pendingBlock)
+	h.requested = time.Time{}
+	h.last = h.final
+	h.released = h.final.Height
+}
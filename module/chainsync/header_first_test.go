@@ -0,0 +1,296 @@
+package chainsync
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/model"
+	"github.com/onflow/flow-go/model/chainsync"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestHeaderFirst(t *testing.T) {
+	suite.Run(t, new(HeaderFirstSuite))
+}
+
+// qcValidatorFunc is a QCValidator backed by a function.
+type qcValidatorFunc func(qc *flow.QuorumCertificate) error
+
+func (f qcValidatorFunc) ValidateQC(qc *flow.QuorumCertificate) error {
+	return f(qc)
+}
+
+type HeaderFirstSuite struct {
+	suite.Suite
+	config      HeaderFirstConfig
+	final       *flow.Header
+	blocks      []*flow.Block
+	validateErr map[flow.Identifier]error // errors returned when validating the QC for a block
+	hf          *HeaderFirst
+}
+
+func (ss *HeaderFirstSuite) SetupTest() {
+	ss.config = DefaultHeaderFirstConfig()
+	ss.config.Threshold = 20
+	ss.config.HeaderBatchSize = 10
+	ss.config.BodyBatchSize = 2
+	ss.config.MaxBodyRequests = 2
+	ss.config.RetryInterval = time.Minute
+
+	ss.final = unittest.BlockHeaderFixture()
+	ss.blocks = unittest.ChainFixtureFrom(50, ss.final)
+	ss.validateErr = make(map[flow.Identifier]error)
+
+	validator := qcValidatorFunc(func(qc *flow.QuorumCertificate) error {
+		return ss.validateErr[qc.BlockID]
+	})
+	ss.hf = NewHeaderFirst(zerolog.New(io.Discard), ss.config, validator, metrics.NewNoopCollector(), ss.final)
+}
+
+// headers returns the headers of the blocks from index from to index to, inclusive.
+func (ss *HeaderFirstSuite) headers(from, to int) []*flow.Header {
+	headers := make([]*flow.Header, 0, to-from+1)
+	for _, block := range ss.blocks[from : to+1] {
+		headers = append(headers, block.Header)
+	}
+	return headers
+}
+
+// validateHeaders starts header-first sync and validates the headers of the blocks up to index to.
+func (ss *HeaderFirstSuite) validateHeaders(to int) {
+	ss.hf.HandleHeight(ss.final, ss.final.Height+50)
+	err := ss.hf.HandleHeaders(unittest.IdentifierFixture(), ss.headers(0, to+1))
+	ss.Require().NoError(err)
+}
+
+// TestInactiveWithinThreshold tests that nothing is requested while the node is within the threshold.
+func (ss *HeaderFirstSuite) TestInactiveWithinThreshold() {
+	ss.hf.HandleHeight(ss.final, ss.final.Height+ss.config.Threshold-1)
+
+	ranges, batches := ss.hf.ScanPending(ss.final)
+	ss.Assert().Empty(ranges)
+	ss.Assert().Empty(batches)
+	ss.Assert().False(ss.hf.Active())
+}
+
+// TestHeaderRanges tests that header ranges extend the chain of validated headers.
+func (ss *HeaderFirstSuite) TestHeaderRanges() {
+	ss.hf.HandleHeight(ss.final, ss.final.Height+50)
+
+	ranges, batches := ss.hf.ScanPending(ss.final)
+	ss.Require().True(ss.hf.Active())
+	ss.Assert().Equal([]chainsync.Range{{From: ss.final.Height + 1, To: ss.final.Height + 10}}, ranges)
+	ss.Assert().Empty(batches)
+
+	// the in-flight header range is not requested again before the retry interval
+	ss.hf.HeadersRequested(ranges[0])
+	ranges, _ = ss.hf.ScanPending(ss.final)
+	ss.Assert().Empty(ranges)
+
+	// the last header of the response is not certified yet, so it is requested again
+	err := ss.hf.HandleHeaders(unittest.IdentifierFixture(), ss.headers(0, 9))
+	ss.Require().NoError(err)
+
+	ranges, batches = ss.hf.ScanPending(ss.final)
+	ss.Assert().Equal([]chainsync.Range{{From: ss.final.Height + 10, To: ss.final.Height + 19}}, ranges)
+
+	// the payloads of the lowest blocks are requested first, spread over the configured number of requests
+	ss.Require().Len(batches, 2)
+	ss.Assert().Equal([]flow.Identifier{ss.blocks[0].ID(), ss.blocks[1].ID()}, batches[0].BlockIDs)
+	ss.Assert().Equal([]flow.Identifier{ss.blocks[2].ID(), ss.blocks[3].ID()}, batches[1].BlockIDs)
+
+	// in-flight payloads are not requested again before the retry interval
+	ss.hf.BodiesRequested(batches[0])
+	_, batches = ss.hf.ScanPending(ss.final)
+	ss.Require().Len(batches, 1)
+	ss.Assert().Equal([]flow.Identifier{ss.blocks[2].ID(), ss.blocks[3].ID()}, batches[0].BlockIDs)
+}
+
+// TestMaxHeadersAhead tests that no headers are requested once the maximum number of validated
+// headers above the finalized height is reached.
+func (ss *HeaderFirstSuite) TestMaxHeadersAhead() {
+	ss.hf.config.MaxHeadersAhead = 5
+	ss.validateHeaders(5)
+
+	ranges, _ := ss.hf.ScanPending(ss.final)
+	ss.Assert().Empty(ranges)
+}
+
+// TestHandleHeaders_NotExtending tests that headers which do not extend the validated chain are discarded.
+func (ss *HeaderFirstSuite) TestHandleHeaders_NotExtending() {
+	ss.hf.HandleHeight(ss.final, ss.final.Height+50)
+
+	err := ss.hf.HandleHeaders(unittest.IdentifierFixture(), ss.headers(1, 9))
+	ss.Require().NoError(err)
+
+	_, batches := ss.hf.ScanPending(ss.final)
+	ss.Assert().Empty(batches)
+}
+
+// TestHandleHeaders_BrokenChain tests that only the headers below a gap in the chain are validated.
+func (ss *HeaderFirstSuite) TestHandleHeaders_BrokenChain() {
+	ss.hf.HandleHeight(ss.final, ss.final.Height+50)
+
+	headers := append(ss.headers(0, 3), ss.headers(5, 9)...)
+	err := ss.hf.HandleHeaders(unittest.IdentifierFixture(), headers)
+	ss.Require().NoError(err)
+
+	ranges, _ := ss.hf.ScanPending(ss.final)
+	ss.Assert().Equal(ss.blocks[2].Header.Height+1, ranges[0].From)
+}
+
+// TestHandleHeaders_InvalidQC tests that validation stops at a header with an invalid QC for its parent.
+func (ss *HeaderFirstSuite) TestHandleHeaders_InvalidQC() {
+	ss.hf.HandleHeight(ss.final, ss.final.Height+50)
+	ss.validateErr[ss.blocks[4].ID()] = model.InvalidQCError{BlockID: ss.blocks[4].ID(), Err: fmt.Errorf("invalid")}
+
+	err := ss.hf.HandleHeaders(unittest.IdentifierFixture(), ss.headers(0, 9))
+	ss.Require().NoError(err)
+
+	// the QC for block 4 is invalid, so blocks 0 to 3 are certified
+	ranges, _ := ss.hf.ScanPending(ss.final)
+	ss.Assert().Equal(ss.blocks[3].Header.Height+1, ranges[0].From)
+}
+
+// TestHandleHeaders_UnknownEpoch tests that validation stops without error at a QC for an unknown epoch.
+func (ss *HeaderFirstSuite) TestHandleHeaders_UnknownEpoch() {
+	ss.hf.HandleHeight(ss.final, ss.final.Height+50)
+	ss.validateErr[ss.blocks[6].ID()] = model.ErrViewForUnknownEpoch
+
+	err := ss.hf.HandleHeaders(unittest.IdentifierFixture(), ss.headers(0, 9))
+	ss.Require().NoError(err)
+
+	ranges, _ := ss.hf.ScanPending(ss.final)
+	ss.Assert().Equal(ss.blocks[5].Header.Height+1, ranges[0].From)
+}
+
+// TestHandleHeaders_Exception tests that unexpected validation errors are returned.
+func (ss *HeaderFirstSuite) TestHandleHeaders_Exception() {
+	ss.hf.HandleHeight(ss.final, ss.final.Height+50)
+	exception := fmt.Errorf("exception")
+	ss.validateErr[ss.blocks[2].ID()] = exception
+
+	err := ss.hf.HandleHeaders(unittest.IdentifierFixture(), ss.headers(0, 9))
+	ss.Assert().ErrorIs(err, exception)
+}
+
+// TestReadyBlocks tests that downloaded blocks are released in height order.
+func (ss *HeaderFirstSuite) TestReadyBlocks() {
+	ss.validateHeaders(5)
+	origin1 := unittest.IdentifierFixture()
+	origin2 := unittest.IdentifierFixture()
+
+	// blocks without a validated header are not handled
+	ss.Assert().False(ss.hf.HandleBlock(origin1, ss.blocks[10]))
+
+	ss.Require().True(ss.hf.HandleBlock(origin1, ss.blocks[2]))
+	ss.Require().True(ss.hf.HandleBlock(origin1, ss.blocks[1]))
+	ss.Assert().Empty(ss.hf.ReadyBlocks())
+
+	ss.Require().True(ss.hf.HandleBlock(origin2, ss.blocks[0]))
+	ready := ss.hf.ReadyBlocks()
+	ss.Require().Len(ready, 3)
+	for i, slashable := range ready {
+		ss.Assert().Equal(ss.blocks[i], slashable.Message)
+	}
+	ss.Assert().Equal(origin2, ready[0].OriginID)
+	ss.Assert().Equal(origin1, ready[1].OriginID)
+
+	// released blocks are not requested again
+	_, batches := ss.hf.ScanPending(ss.final)
+	ss.Require().NotEmpty(batches)
+	ss.Assert().Equal(ss.blocks[3].ID(), batches[0].BlockIDs[0])
+}
+
+// TestHandleBlock_PayloadMismatch tests that a block whose payload does not match its validated
+// header is dropped, and that the block with the matching payload is still accepted.
+func (ss *HeaderFirstSuite) TestHandleBlock_PayloadMismatch() {
+	ss.validateHeaders(5)
+	origin1 := unittest.IdentifierFixture()
+	origin2 := unittest.IdentifierFixture()
+
+	payload := unittest.PayloadFixture(unittest.WithGuarantees(unittest.CollectionGuaranteesFixture(1)...))
+	tampered := &flow.Block{
+		Header:  ss.blocks[0].Header,
+		Payload: &payload,
+	}
+	ss.Require().Equal(ss.blocks[0].ID(), tampered.ID())
+
+	ss.Require().True(ss.hf.HandleBlock(origin1, tampered))
+	ss.Assert().Empty(ss.hf.ReadyBlocks())
+
+	ss.Require().True(ss.hf.HandleBlock(origin2, ss.blocks[0]))
+	ready := ss.hf.ReadyBlocks()
+	ss.Require().Len(ready, 1)
+	ss.Assert().Equal(ss.blocks[0], ready[0].Message)
+	ss.Assert().Equal(origin2, ready[0].OriginID)
+}
+
+// TestStall tests that the validated header chain is discarded if the finalized height does not
+// advance, and that header-first sync steps aside for range requests before it resumes.
+func (ss *HeaderFirstSuite) TestStall() {
+	ss.hf.config.StallTimeout = 200 * time.Millisecond
+	ss.validateHeaders(5)
+	origin := unittest.IdentifierFixture()
+
+	ss.hf.ScanPending(ss.final)
+	ss.Require().True(ss.hf.Active())
+
+	// the released blocks are never finalized
+	for _, block := range ss.blocks[:5] {
+		ss.Require().True(ss.hf.HandleBlock(origin, block))
+	}
+	ss.Require().Len(ss.hf.ReadyBlocks(), 5)
+
+	// finalization progress restarts the stall timeout
+	time.Sleep(100 * time.Millisecond)
+	ss.hf.ScanPending(ss.blocks[0].Header)
+	time.Sleep(100 * time.Millisecond)
+	ss.hf.ScanPending(ss.blocks[0].Header)
+	ss.Require().True(ss.hf.Active())
+
+	time.Sleep(ss.hf.config.StallTimeout)
+	ranges, batches := ss.hf.ScanPending(ss.blocks[0].Header)
+	ss.Assert().Empty(ranges)
+	ss.Assert().Empty(batches)
+	ss.Assert().False(ss.hf.Active())
+
+	// the validated header chain was discarded
+	ss.Assert().False(ss.hf.HandleBlock(origin, ss.blocks[5]))
+
+	// headers are requested again right above the finalized block once the fallback is over
+	time.Sleep(ss.hf.config.StallTimeout + 10*time.Millisecond)
+	ranges, _ = ss.hf.ScanPending(ss.blocks[0].Header)
+	ss.Require().True(ss.hf.Active())
+	ss.Require().Len(ranges, 1)
+	ss.Assert().Equal(ss.blocks[1].Header.Height, ranges[0].From)
+}
+
+// TestFinalization tests that finalized blocks are dropped.
+func (ss *HeaderFirstSuite) TestFinalization() {
+	ss.validateHeaders(5)
+
+	_, batches := ss.hf.ScanPending(ss.blocks[1].Header)
+	ss.Require().NotEmpty(batches)
+	ss.Assert().Equal(ss.blocks[2].ID(), batches[0].BlockIDs[0])
+}
+
+// TestConflictingFinalization tests that the header chain is discarded if it conflicts with the
+// finalized chain.
+func (ss *HeaderFirstSuite) TestConflictingFinalization() {
+	ss.validateHeaders(5)
+
+	fork := unittest.BlockHeaderWithParentFixture(ss.blocks[0].Header)
+	ranges, batches := ss.hf.ScanPending(fork)
+	ss.Assert().Empty(batches)
+	ss.Require().Len(ranges, 1)
+	ss.Assert().Equal(fork.Height+1, ranges[0].From)
+	ss.Assert().False(ss.hf.HandleBlock(unittest.IdentifierFixture(), ss.blocks[2]))
+}
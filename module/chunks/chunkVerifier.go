@@ -20,6 +20,7 @@ import (
 	"github.com/onflow/flow-go/ledger/partial"
 	chmodels "github.com/onflow/flow-go/model/chunks"
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol"
 )
 
 // ChunkVerifier is a verifier based on the current definitions of the flow network
@@ -28,15 +29,18 @@ type ChunkVerifier struct {
 	vmCtx          fvm.Context
 	systemChunkCtx fvm.Context
 	logger         zerolog.Logger
+	protocolState  protocol.State
 }
 
-// NewChunkVerifier creates a chunk verifier containing a flow virtual machine
-func NewChunkVerifier(vm fvm.VM, vmCtx fvm.Context, logger zerolog.Logger) *ChunkVerifier {
+// NewChunkVerifier creates a chunk verifier containing a flow virtual machine.
+// The protocol state provides the source of randomness of the blocks whose chunks are verified.
+func NewChunkVerifier(vm fvm.VM, vmCtx fvm.Context, logger zerolog.Logger, protocolState protocol.State) *ChunkVerifier {
 	return &ChunkVerifier{
 		vm:             vm,
 		vmCtx:          vmCtx,
 		systemChunkCtx: computer.SystemChunkContext(vmCtx, vmCtx.Logger),
 		logger:         logger.With().Str("component", "chunk_verifier").Logger(),
+		protocolState:  protocolState,
 	}
 }
 
@@ -52,12 +56,16 @@ func (fcv *ChunkVerifier) Verify(
 	error,
 ) {
 
+	// the chunk must be executed with the same source of randomness as on execution nodes
+	entropyProvider := fcv.protocolState.AtBlockID(vc.Header.ID())
+
 	var ctx fvm.Context
 	var transactions []*fvm.TransactionProcedure
 	if vc.IsSystemChunk {
 		ctx = fvm.NewContextFromParent(
 			fcv.systemChunkCtx,
-			fvm.WithBlockHeader(vc.Header))
+			fvm.WithBlockHeader(vc.Header),
+			fvm.WithEntropyProvider(entropyProvider))
 
 		txBody, err := blueprints.SystemChunkTransaction(fcv.vmCtx.Chain)
 		if err != nil {
@@ -70,7 +78,8 @@ func (fcv *ChunkVerifier) Verify(
 	} else {
 		ctx = fvm.NewContextFromParent(
 			fcv.vmCtx,
-			fvm.WithBlockHeader(vc.Header))
+			fvm.WithBlockHeader(vc.Header),
+			fvm.WithEntropyProvider(entropyProvider))
 
 		transactions = make(
 			[]*fvm.TransactionProcedure,
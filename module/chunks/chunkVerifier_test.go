@@ -74,12 +74,13 @@ func (s *ChunkVerifierTestSuite) SetupSuite() {
 	systemOkVm := new(vmSystemOkMock)
 	systemBadVm := new(vmSystemBadMock)
 	vmCtx := fvm.NewContext(fvm.WithChain(testChain.Chain()))
+	state := unittest.ProtocolStateWithSourceFixture(nil)
 
 	// system chunk runs predefined system transaction, hence we can't distinguish
 	// based on its content and we need separate VMs
-	s.verifier = chunks.NewChunkVerifier(vm, vmCtx, zerolog.Nop())
-	s.systemOkVerifier = chunks.NewChunkVerifier(systemOkVm, vmCtx, zerolog.Nop())
-	s.systemBadVerifier = chunks.NewChunkVerifier(systemBadVm, vmCtx, zerolog.Nop())
+	s.verifier = chunks.NewChunkVerifier(vm, vmCtx, zerolog.Nop(), state)
+	s.systemOkVerifier = chunks.NewChunkVerifier(systemOkVm, vmCtx, zerolog.Nop(), state)
+	s.systemBadVerifier = chunks.NewChunkVerifier(systemBadVm, vmCtx, zerolog.Nop(), state)
 }
 
 // TestChunkVerifier invokes all the tests in this test suite
@@ -97,6 +98,25 @@ func (s *ChunkVerifierTestSuite) TestHappyPath() {
 	assert.NotNil(s.T(), spockSecret)
 }
 
+// TestEntropyProvider tests that transactions are verified with the source of randomness of the
+// chunk's block from the protocol state, like they are executed on execution nodes.
+func (s *ChunkVerifierTestSuite) TestEntropyProvider() {
+	source := unittest.SignatureFixture()
+	vm := &vmEntropyMock{}
+	vmCtx := fvm.NewContext(fvm.WithChain(testChain.Chain()))
+	verifier := chunks.NewChunkVerifier(vm, vmCtx, zerolog.Nop(), unittest.ProtocolStateWithSourceFixture(source))
+
+	vch := GetBaselineVerifiableChunk(s.T(), "", false)
+	_, chFaults, err := verifier.Verify(vch)
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), chFaults)
+
+	require.NotEmpty(s.T(), vm.sources)
+	for _, actual := range vm.sources {
+		assert.Equal(s.T(), []byte(source), actual)
+	}
+}
+
 // TestMissingRegisterTouchForUpdate tests verification given a chunkdatapack missing a register touch (update)
 func (s *ChunkVerifierTestSuite) TestMissingRegisterTouchForUpdate() {
 	unittest.SkipUnless(s.T(), unittest.TEST_DEPRECATED, "Check new partial ledger for missing keys")
@@ -354,6 +374,30 @@ func GetBaselineVerifiableChunk(t *testing.T, script string, system bool) *verif
 
 type vmMock struct{}
 
+// vmEntropyMock records the source of randomness of the context of each procedure it runs.
+type vmEntropyMock struct {
+	vmMock
+	sources [][]byte
+}
+
+func (vm *vmEntropyMock) Run(
+	ctx fvm.Context,
+	proc fvm.Procedure,
+	storage snapshot.StorageSnapshot,
+) (
+	*snapshot.ExecutionSnapshot,
+	fvm.ProcedureOutput,
+	error,
+) {
+	source, err := ctx.EntropyProvider.RandomSource()
+	if err != nil {
+		return nil, fvm.ProcedureOutput{}, err
+	}
+	vm.sources = append(vm.sources, source)
+
+	return vm.vmMock.Run(ctx, proc, storage)
+}
+
 func (vm *vmMock) Run(
 	ctx fvm.Context,
 	proc fvm.Procedure,
@@ -0,0 +1,353 @@
+package component
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onflow/flow-go/module"
+)
+
+// ComponentLabel is the pprof label set on the goroutines of a component started by the node builder,
+// with the name of the component as value. It is used to attribute goroutine dumps to components.
+const ComponentLabel = "component"
+
+// StartupKind is the way a node builder module or component is started.
+type StartupKind string
+
+const (
+	// StartupKindModule is a module, initialized sequentially before any component is started.
+	StartupKindModule StartupKind = "module"
+	// StartupKindComponent is a component started after the previous serial component is ready.
+	StartupKindComponent StartupKind = "component"
+	// StartupKindDependable is a component started once all components of its dependency list are ready.
+	StartupKindDependable StartupKind = "dependable"
+	// StartupKindRestartable is a component which is restarted after handling an irrecoverable error.
+	StartupKindRestartable StartupKind = "restartable"
+)
+
+// StartupState is the lifecycle state of a node builder module or component.
+type StartupState string
+
+const (
+	StartupPending      StartupState = "pending"      // waiting for its dependencies
+	StartupInitializing StartupState = "initializing" // the factory is running
+	StartupStarting     StartupState = "starting"     // initialized, waiting to become ready
+	StartupReady        StartupState = "ready"
+	StartupAborted      StartupState = "aborted" // the node shut down before the component became ready
+	StartupStopping     StartupState = "stopping"
+	StartupDone         StartupState = "done"
+)
+
+// StartupNode is a snapshot of the startup and shutdown of a single module or component.
+// Durations are given in milliseconds.
+type StartupNode struct {
+	Name         string       `json:"name"`
+	Kind         StartupKind  `json:"kind"`
+	State        StartupState `json:"state"`
+	Dependencies []string     `json:"dependencies"`
+	// StartedAt is the time the dependencies of the component were ready, and its initialization started.
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// InitMS is the time the module or component factory took.
+	InitMS *int64 `json:"init_ms,omitempty"`
+	// ReadyMS is the time from the start of the initialization until the component was ready.
+	ReadyMS *int64 `json:"ready_ms,omitempty"`
+	// DoneMS is the time from the start of the shutdown until the component was done.
+	DoneMS *int64 `json:"done_ms,omitempty"`
+	// Restarts is the number of times a restartable component was restarted.
+	Restarts int `json:"restarts,omitempty"`
+	// Stuck is set if the component was not ready after the configured timeout.
+	Stuck bool `json:"stuck,omitempty"`
+	// Goroutines is the goroutine dump of the component, taken when it was flagged as stuck.
+	Goroutines string `json:"goroutines,omitempty"`
+}
+
+type startupEntry struct {
+	name         string
+	kind         StartupKind
+	state        StartupState
+	dependsOn    []string
+	dependencies []module.ReadyDoneAware // resolved to names when taking a snapshot
+	instance     module.ReadyDoneAware
+	startedAt    time.Time
+	initialized  time.Time
+	readyAt      time.Time
+	stoppingAt   time.Time
+	doneAt       time.Time
+	restarts     int
+	stuck        bool
+	goroutines   string
+}
+
+// StartupGraph records the dependencies of the modules and components of a node, and how long each of
+// them took to initialize, to become ready and to shut down. It is used to diagnose slow or stuck
+// startups and shutdowns.
+//
+// All methods are concurrency safe. Methods which reference an unknown name are no-ops.
+type StartupGraph struct {
+	mu      sync.RWMutex
+	entries map[string]*startupEntry
+	order   []string
+}
+
+func NewStartupGraph() *StartupGraph {
+	return &StartupGraph{
+		entries: make(map[string]*startupEntry),
+	}
+}
+
+// ModuleInitialized records a module which was initialized in the given duration, ending now.
+func (g *StartupGraph) ModuleInitialized(name string, duration time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	e := g.add(name, StartupKindModule, nil, nil)
+	e.state = StartupDone
+	e.startedAt = now.Add(-duration)
+	e.initialized = now
+}
+
+// AddComponent records a component which depends on the named components and on the given
+// ReadyDoneAware instances. Instances are resolved to the names of the components they were
+// constructed by when the graph is read, so they may not exist yet.
+func (g *StartupGraph) AddComponent(name string, kind StartupKind, dependsOn []string, dependencies []module.ReadyDoneAware) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.add(name, kind, dependsOn, dependencies)
+}
+
+func (g *StartupGraph) add(name string, kind StartupKind, dependsOn []string, dependencies []module.ReadyDoneAware) *startupEntry {
+	e, ok := g.entries[name]
+	if !ok {
+		e = &startupEntry{name: name}
+		g.entries[name] = e
+		g.order = append(g.order, name)
+	}
+	e.kind = kind
+	e.state = StartupPending
+	e.dependsOn = dependsOn
+	e.dependencies = dependencies
+	return e
+}
+
+// Initializing records that the dependencies of the component are ready, and its factory is called.
+// Restartable components call this each time they are (re)started.
+func (g *StartupGraph) Initializing(name string) {
+	g.update(name, func(e *startupEntry) {
+		if !e.startedAt.IsZero() {
+			e.restarts++
+		}
+		e.state = StartupInitializing
+		e.startedAt = time.Now()
+		e.initialized = time.Time{}
+		e.readyAt = time.Time{}
+		e.stoppingAt = time.Time{}
+		e.doneAt = time.Time{}
+		e.stuck = false
+		e.goroutines = ""
+	})
+}
+
+// Initialized records the instance constructed by the factory of the component.
+func (g *StartupGraph) Initialized(name string, instance module.ReadyDoneAware) {
+	g.update(name, func(e *startupEntry) {
+		e.state = StartupStarting
+		e.initialized = time.Now()
+		e.instance = instance
+	})
+}
+
+// Ready records that the component is ready.
+func (g *StartupGraph) Ready(name string) {
+	g.update(name, func(e *startupEntry) {
+		e.state = StartupReady
+		e.readyAt = time.Now()
+	})
+}
+
+// Aborted records that the node shut down before the component was ready.
+func (g *StartupGraph) Aborted(name string) {
+	g.update(name, func(e *startupEntry) {
+		e.state = StartupAborted
+	})
+}
+
+// Stopping records that the shutdown of the component started.
+func (g *StartupGraph) Stopping(name string) {
+	g.update(name, func(e *startupEntry) {
+		e.state = StartupStopping
+		e.stoppingAt = time.Now()
+	})
+}
+
+// Done records that the component finished shutting down.
+func (g *StartupGraph) Done(name string) {
+	g.update(name, func(e *startupEntry) {
+		e.state = StartupDone
+		e.doneAt = time.Now()
+	})
+}
+
+// Stuck flags a component which did not become ready in time, along with the goroutine dump of its workers.
+func (g *StartupGraph) Stuck(name string, goroutines string) {
+	g.update(name, func(e *startupEntry) {
+		e.stuck = true
+		e.goroutines = goroutines
+	})
+}
+
+func (g *StartupGraph) update(name string, f func(e *startupEntry)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if e, ok := g.entries[name]; ok {
+		f(e)
+	}
+}
+
+// Snapshot returns the modules and components of the graph, in the order they were added.
+func (g *StartupGraph) Snapshot() []StartupNode {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	// resolve dependency instances to the names of the components which constructed them. Only
+	// comparable instances can be resolved, all others are represented by their type.
+	names := make(map[module.ReadyDoneAware]string)
+	for _, name := range g.order {
+		instance := g.entries[name].instance
+		if instance != nil && reflect.TypeOf(instance).Comparable() {
+			names[instance] = name
+		}
+	}
+
+	nodes := make([]StartupNode, 0, len(g.order))
+	for _, name := range g.order {
+		e := g.entries[name]
+		node := StartupNode{
+			Name:         e.name,
+			Kind:         e.kind,
+			State:        e.state,
+			Dependencies: append([]string{}, e.dependsOn...),
+			Restarts:     e.restarts,
+			Stuck:        e.stuck,
+			Goroutines:   e.goroutines,
+		}
+		for _, dep := range e.dependencies {
+			depName := fmt.Sprintf("%T", dep)
+			if dep != nil && reflect.TypeOf(dep).Comparable() {
+				if n, ok := names[dep]; ok {
+					depName = n
+				}
+			}
+			node.Dependencies = append(node.Dependencies, depName)
+		}
+		if !e.startedAt.IsZero() {
+			startedAt := e.startedAt
+			node.StartedAt = &startedAt
+		}
+		node.InitMS = milliseconds(e.startedAt, e.initialized)
+		node.ReadyMS = milliseconds(e.startedAt, e.readyAt)
+		node.DoneMS = milliseconds(e.stoppingAt, e.doneAt)
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// milliseconds returns the time between from and to in milliseconds, or nil if either is not set.
+func milliseconds(from, to time.Time) *int64 {
+	if from.IsZero() || to.IsZero() {
+		return nil
+	}
+	ms := to.Sub(from).Milliseconds()
+	return &ms
+}
+
+// DOT returns the graph in the Graphviz DOT format. Edges point from a component to its dependencies,
+// and each node is labeled with its state and timings. Stuck components are highlighted.
+func (g *StartupGraph) DOT() string {
+	nodes := g.Snapshot()
+
+	var b strings.Builder
+	b.WriteString("digraph components {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box];\n")
+	for _, node := range nodes {
+		label := fmt.Sprintf("%s\\n%s, %s", escapeDOT(node.Name), node.Kind, node.State)
+		if node.InitMS != nil {
+			label += fmt.Sprintf("\\ninit: %dms", *node.InitMS)
+		}
+		if node.ReadyMS != nil {
+			label += fmt.Sprintf("\\nready: %dms", *node.ReadyMS)
+		}
+		if node.DoneMS != nil {
+			label += fmt.Sprintf("\\ndone: %dms", *node.DoneMS)
+		}
+		attrs := ""
+		if node.Stuck {
+			attrs = ", color=red, style=bold"
+		}
+		fmt.Fprintf(&b, "\t\"%s\" [label=\"%s\"%s];\n", escapeDOT(node.Name), label, attrs)
+	}
+
+	known := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		known[node.Name] = struct{}{}
+	}
+	unknown := make(map[string]struct{})
+	for _, node := range nodes {
+		for _, dep := range node.Dependencies {
+			if _, ok := known[dep]; !ok {
+				unknown[dep] = struct{}{}
+			}
+			fmt.Fprintf(&b, "\t\"%s\" -> \"%s\";\n", escapeDOT(node.Name), escapeDOT(dep))
+		}
+	}
+	// dependencies which weren't constructed by a component of the graph
+	unknownNames := make([]string, 0, len(unknown))
+	for name := range unknown {
+		unknownNames = append(unknownNames, name)
+	}
+	sort.Strings(unknownNames)
+	for _, name := range unknownNames {
+		fmt.Fprintf(&b, "\t\"%s\" [style=dashed];\n", escapeDOT(name))
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func escapeDOT(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`)
+}
+
+// LabeledGoroutines returns the goroutine dump, aggregated by stack, of all goroutines which have
+// the given pprof label set to the given value.
+// No errors are expected during normal operation.
+func LabeledGoroutines(label string, value string) (string, error) {
+	var buf bytes.Buffer
+	err := pprof.Lookup("goroutine").WriteTo(&buf, 1)
+	if err != nil {
+		return "", fmt.Errorf("could not write goroutine profile: %w", err)
+	}
+
+	// in the debug=1 format, each group of identical goroutines is a paragraph, which contains
+	// a line of the form `# labels: {"component":"name", ...}` if the goroutines have labels
+	match := fmt.Sprintf("%q:%q", label, value)
+	var groups []string
+	for _, group := range strings.Split(buf.String(), "\n\n") {
+		for _, line := range strings.Split(group, "\n") {
+			if strings.HasPrefix(line, "# labels: ") && strings.Contains(line, match) {
+				groups = append(groups, strings.TrimSpace(group))
+				break
+			}
+		}
+	}
+	return strings.Join(groups, "\n\n"), nil
+}
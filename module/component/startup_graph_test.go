@@ -0,0 +1,112 @@
+package component_test
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/component"
+)
+
+func TestStartupGraph(t *testing.T) {
+	graph := component.NewStartupGraph()
+
+	graph.ModuleInitialized("state", 10*time.Millisecond)
+
+	network := &readyDoneAware{name: "network"}
+	untracked := &readyDoneAware{name: "untracked"}
+	graph.AddComponent("network", component.StartupKindComponent, nil, nil)
+	graph.AddComponent("engine", component.StartupKindComponent, []string{"network"}, nil)
+	graph.AddComponent("sync", component.StartupKindDependable, nil, []module.ReadyDoneAware{network, untracked})
+
+	graph.Initializing("network")
+	graph.Initialized("network", network)
+	graph.Ready("network")
+	graph.Stopping("network")
+	graph.Done("network")
+
+	graph.Initializing("sync")
+	graph.Stuck("sync", "goroutine dump")
+
+	// unknown names are ignored
+	graph.Ready("unknown")
+
+	nodes := graph.Snapshot()
+	require.Len(t, nodes, 4)
+
+	assert.Equal(t, "state", nodes[0].Name)
+	assert.Equal(t, component.StartupKindModule, nodes[0].Kind)
+	assert.Equal(t, component.StartupDone, nodes[0].State)
+	require.NotNil(t, nodes[0].InitMS)
+	assert.Equal(t, int64(10), *nodes[0].InitMS)
+
+	assert.Equal(t, component.StartupDone, nodes[1].State)
+	assert.NotNil(t, nodes[1].InitMS)
+	assert.NotNil(t, nodes[1].ReadyMS)
+	assert.NotNil(t, nodes[1].DoneMS)
+
+	assert.Equal(t, component.StartupPending, nodes[2].State)
+	assert.Equal(t, []string{"network"}, nodes[2].Dependencies)
+	assert.Nil(t, nodes[2].StartedAt)
+
+	// dependencies are resolved to the component which constructed them
+	assert.Equal(t, []string{"network", "*component_test.readyDoneAware"}, nodes[3].Dependencies)
+	assert.Equal(t, component.StartupInitializing, nodes[3].State)
+	assert.True(t, nodes[3].Stuck)
+	assert.Equal(t, "goroutine dump", nodes[3].Goroutines)
+	assert.Nil(t, nodes[3].ReadyMS)
+
+	// restarting resets the timings
+	graph.Initializing("network")
+	nodes = graph.Snapshot()
+	assert.Equal(t, 1, nodes[1].Restarts)
+	assert.Nil(t, nodes[1].ReadyMS)
+
+	dot := graph.DOT()
+	assert.Contains(t, dot, `"engine" -> "network";`)
+	assert.Contains(t, dot, `"sync" -> "network";`)
+	assert.Contains(t, dot, `"sync" -> "*component_test.readyDoneAware";`)
+	assert.Contains(t, dot, `"*component_test.readyDoneAware" [style=dashed];`)
+	assert.Contains(t, dot, "color=red")
+}
+
+func TestLabeledGoroutines(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	started := make(chan struct{})
+	go pprof.Do(context.Background(), pprof.Labels(component.ComponentLabel, "stuck component"), func(context.Context) {
+		// goroutines inherit the labels of the goroutine which started them
+		go func() {
+			close(started)
+			blockForever(stop)
+		}()
+		<-stop
+	})
+	<-started
+
+	dump, err := component.LabeledGoroutines(component.ComponentLabel, "stuck component")
+	require.NoError(t, err)
+	assert.Contains(t, dump, "blockForever")
+	assert.Contains(t, dump, `"component":"stuck component"`)
+
+	dump, err = component.LabeledGoroutines(component.ComponentLabel, "other component")
+	require.NoError(t, err)
+	assert.Empty(t, dump)
+}
+
+type readyDoneAware struct {
+	name string
+}
+
+func (r *readyDoneAware) Ready() <-chan struct{} { return nil }
+func (r *readyDoneAware) Done() <-chan struct{}  { return nil }
+
+func blockForever(stop <-chan struct{}) {
+	<-stop
+}
@@ -11,11 +11,13 @@ import (
 
 	"github.com/onflow/flow-go/crypto"
 	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/dkg"
 	"github.com/onflow/flow-go/model/fingerprint"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/messages"
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/module/retrymiddleware"
+	"github.com/onflow/flow-go/storage"
 )
 
 // BrokerOpt is a functional option which modifies the DKG Broker config.
@@ -38,6 +40,16 @@ type BrokerConfig struct {
 	// RetryJitterPct is the percentage jitter to introduce to each retry interval
 	// for all retryable requests.
 	RetryJitterPct uint64
+	// Transcripts is an optional store in which the broker records the metadata of
+	// all messages it sends and receives, to investigate failed DKGs.
+	Transcripts storage.DKGTranscripts
+}
+
+// WithTranscripts configures the broker to record its DKG transcript in the given store.
+func WithTranscripts(transcripts storage.DKGTranscripts) BrokerOpt {
+	return func(config *BrokerConfig) {
+		config.Transcripts = transcripts
+	}
 }
 
 // DefaultBrokerConfig returns the default config for the DKG Broker component.
@@ -112,6 +124,20 @@ func NewBroker(
 		shutdownCh:         make(chan struct{}),
 	}
 
+	if config.Transcripts != nil {
+		err := config.Transcripts.InitTranscript(&dkg.TranscriptInfo{
+			DKGInstanceID: dkgInstanceID,
+			Committee:     committee.NodeIDs(),
+			MyIndex:       myIndex,
+			StartedAt:     time.Now(),
+		})
+		if err != nil {
+			// the transcript is only used for investigating failed DKGs, so the DKG proceeds without it
+			b.log.Error().Err(err).Msg("could not initialize dkg transcript")
+			b.config.Transcripts = nil
+		}
+	}
+
 	go b.listen()
 
 	return b
@@ -137,6 +163,7 @@ func (b *Broker) PrivateSend(dest int, data []byte) {
 		DKGMessage: messages.NewDKGMessage(data, b.dkgInstanceID),
 		DestID:     b.committee[dest].NodeID,
 	}
+	b.recordMessage(dkg.TranscriptPrivateSent, b.myIndex, dest, data)
 	b.tunnel.SendOut(dkgMessageOut)
 }
 
@@ -197,9 +224,11 @@ func (b *Broker) Broadcast(data []byte) {
 		// it is acceptable to log the error and move on.
 		if err != nil {
 			log.Error().Err(err).Msgf("failed to broadcast message after %d attempts", attempts)
+			b.recordMessage(dkg.TranscriptBroadcastFailed, b.myIndex, dkg.NoParticipant, data)
 			return
 		}
 		log.Info().Msgf("dkg broadcast successfully on attempt %d", attempts)
+		b.recordMessage(dkg.TranscriptBroadcastSent, b.myIndex, dkg.NoParticipant, data)
 	})
 }
 
@@ -247,6 +276,12 @@ func (b *Broker) SubmitResult(groupKey crypto.PublicKey, pubKeys []crypto.Public
 	}
 
 	b.log.Info().Msgf("dkg result submitted successfully on attempt %d", attempts)
+	b.record(&dkg.TranscriptEvent{
+		Type:    dkg.TranscriptResultSubmitted,
+		From:    b.myIndex,
+		To:      dkg.NoParticipant,
+		Subject: dkg.NoParticipant,
+	})
 	return nil
 }
 
@@ -260,6 +295,7 @@ func (b *Broker) Disqualify(node int, log string) {
 	// The warn-level log is used by the integration tests to check if this method is called.
 	b.log.Warn().Msgf("participant %d (this node) is disqualifying participant (index=%d, node_id=%s) because: %s",
 		b.myIndex, node, nodeID, log)
+	b.recordParticipant(dkg.TranscriptDisqualified, node, log)
 }
 
 // FlagMisbehavior warns that a node is misbehaving.
@@ -272,6 +308,7 @@ func (b *Broker) FlagMisbehavior(node int, log string) {
 	// The warn-level log is used by the integration tests to check if this method is called.
 	b.log.Warn().Msgf("participant %d (this node) is flagging participant (index=%d, node_id=%s) because: %s",
 		b.myIndex, node, nodeID, log)
+	b.recordParticipant(dkg.TranscriptMisbehaviorFlagged, node, log)
 }
 
 // GetPrivateMsgCh returns the channel through which consumers can receive
@@ -342,12 +379,15 @@ func (b *Broker) Poll(referenceBlock flow.Identifier) error {
 		ok, err := b.verifyBroadcastMessage(msg)
 		if err != nil {
 			b.log.Error().Err(err).Msg("unable to verify broadcast message")
+			b.recordInvalidMessage(int(memberIndex), msg.Data, fmt.Sprintf("unable to verify broadcast message: %s", err))
 			continue
 		}
 		if !ok {
 			b.log.Error().Msg("invalid signature on broadcast dkg message")
+			b.recordInvalidMessage(int(memberIndex), msg.Data, "invalid signature on broadcast message")
 			continue
 		}
+		b.recordMessage(dkg.TranscriptBroadcastReceived, int(memberIndex), dkg.NoParticipant, msg.Data)
 		b.log.Debug().Msgf("forwarding broadcast message to controller")
 		b.broadcastMsgCh <- msg
 	}
@@ -423,9 +463,11 @@ func (b *Broker) onPrivateMessage(originID flow.Identifier, msg messages.DKGMess
 	err := b.hasValidDKGInstanceID(msg)
 	if err != nil {
 		b.log.Err(err).Msg("bad message")
+		b.recordInvalidMessage(int(memberIndex), msg.Data, err.Error())
 		return
 	}
 
+	b.recordMessage(dkg.TranscriptPrivateReceived, int(memberIndex), b.myIndex, msg.Data)
 	b.privateMsgCh <- messages.PrivDKGMessageIn{DKGMessage: msg, OriginID: originID, CommitteeMemberIndex: uint64(memberIndex)}
 }
 
@@ -480,3 +522,55 @@ func (b *Broker) verifyBroadcastMessage(bcastMsg messages.BroadcastDKGMessage) (
 		NewDKGMessageHasher(),
 	)
 }
+
+// recordMessage records a sent or received message in the DKG transcript. Only the metadata of the message is
+// recorded, as private messages contain secret key shares.
+func (b *Broker) recordMessage(eventType dkg.TranscriptEventType, from int, to int, data []byte) {
+	kind, subject := dkg.MessageKindOf(data)
+	b.record(&dkg.TranscriptEvent{
+		Type:    eventType,
+		Kind:    kind,
+		From:    from,
+		To:      to,
+		Subject: subject,
+		Size:    len(data),
+	})
+}
+
+// recordInvalidMessage records a dropped message of the given participant in the DKG transcript.
+func (b *Broker) recordInvalidMessage(from int, data []byte, reason string) {
+	kind, _ := dkg.MessageKindOf(data)
+	b.record(&dkg.TranscriptEvent{
+		Type:    dkg.TranscriptInvalidMessage,
+		Kind:    kind,
+		From:    from,
+		To:      dkg.NoParticipant,
+		Subject: dkg.NoParticipant,
+		Size:    len(data),
+		Reason:  reason,
+	})
+}
+
+// recordParticipant records that the given participant was disqualified or flagged in the DKG transcript.
+func (b *Broker) recordParticipant(eventType dkg.TranscriptEventType, node int, reason string) {
+	b.record(&dkg.TranscriptEvent{
+		Type:    eventType,
+		From:    b.myIndex,
+		To:      dkg.NoParticipant,
+		Subject: node,
+		Reason:  reason,
+	})
+}
+
+// record appends the event to the DKG transcript, if transcripts are enabled. Failures are logged only,
+// as the transcript is not needed to complete the DKG.
+func (b *Broker) record(event *dkg.TranscriptEvent) {
+	if b.config.Transcripts == nil {
+		return
+	}
+	event.Time = time.Now()
+	err := b.config.Transcripts.AppendEvent(b.dkgInstanceID, event)
+	if err != nil {
+		b.log.Error().Err(err).Str("event_type", string(event.Type)).Msg("could not record dkg transcript event")
+	}
+}
@@ -22,16 +22,19 @@ type ControllerFactory struct {
 	dkgContractClients []module.DKGContractClient
 	tunnel             *BrokerTunnel
 	config             ControllerConfig
+	brokerOpts         []BrokerOpt
 }
 
 // NewControllerFactory creates a new factory that generates Controllers with
 // the same underlying Local object, tunnel and dkg smart-contract client.
+// The given broker options are applied to the broker of each Controller.
 func NewControllerFactory(
 	log zerolog.Logger,
 	me module.Local,
 	dkgContractClients []module.DKGContractClient,
 	tunnel *BrokerTunnel,
-	config ControllerConfig) *ControllerFactory {
+	config ControllerConfig,
+	brokerOpts ...BrokerOpt) *ControllerFactory {
 
 	return &ControllerFactory{
 		log:                log,
@@ -39,6 +42,7 @@ func NewControllerFactory(
 		dkgContractClients: dkgContractClients,
 		tunnel:             tunnel,
 		config:             config,
+		brokerOpts:         brokerOpts,
 	}
 }
 
@@ -62,6 +66,7 @@ func (f *ControllerFactory) Create(
 		int(myIndex),
 		f.dkgContractClients,
 		f.tunnel,
+		f.brokerOpts...,
 	)
 
 	n := len(participants)
@@ -0,0 +1,90 @@
+// Package archive implements a portable file format for execution data, used to move execution
+// data between nodes or into cold storage.
+//
+// An archive contains the execution data of a range of sealed blocks in ascending height order.
+// The execution data of each block is stored as the raw, content-addressed blobs of its blob tree,
+// so that importing an archive reproduces exactly the blobs the block's execution data ID commits
+// to. The CID of every blob is recomputed from its data when it is read.
+//
+// File layout (all integers are big endian):
+//
+//	archive := header record* index footer
+//	header  := magic[8] version:uint16
+//	record  := height:uint64 blockID[32] executionDataID[32] blobCount:uint32 blob*
+//	blob    := cidLength:uint16 cid dataLength:uint32 data
+//	index   := entryCount:uint64 entry*
+//	entry   := height:uint64 blockID[32] executionDataID[32] offset:uint64
+//	footer  := indexOffset:uint64 magic[8]
+//
+// The first blob of each record is the root blob of the block's execution data. Records can be
+// read sequentially, or looked up by height through the index at the end of the file.
+package archive
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
+)
+
+// Version is the version of the archive format written by Writer.
+const Version uint16 = 1
+
+var magic = [8]byte{'F', 'L', 'O', 'W', 'E', 'D', 'A', 'R'}
+
+const (
+	headerSize = len(magic) + 2
+	footerSize = 8 + len(magic)
+	entrySize  = 8 + flow.IdentifierLen + flow.IdentifierLen + 8
+
+	// maxBlobSize bounds the size of blobs read from an archive, so that a corrupted length cannot
+	// make the reader allocate arbitrary amounts of memory.
+	maxBlobSize = 64 << 20
+)
+
+var (
+	// ErrInvalidArchive is returned when a file is not a well-formed archive.
+	ErrInvalidArchive = errors.New("invalid execution data archive")
+
+	// ErrCidMismatch is returned when the data of a blob in an archive does not match its CID.
+	ErrCidMismatch = errors.New("blob data does not match its CID")
+)
+
+// Record is the execution data of a single block.
+type Record struct {
+	Height          uint64
+	BlockID         flow.Identifier
+	ExecutionDataID flow.Identifier
+
+	// Blobs of the execution data's blob tree, starting with the root blob
+	Blobs []blobs.Blob
+}
+
+// IndexEntry locates the record of a block in an archive.
+type IndexEntry struct {
+	Height          uint64
+	BlockID         flow.Identifier
+	ExecutionDataID flow.Identifier
+
+	// Offset of the record from the start of the archive
+	Offset uint64
+}
+
+// validate checks that the record is non-empty and starts with the root blob of its execution data.
+func (r *Record) validate() error {
+	if len(r.Blobs) == 0 {
+		return fmt.Errorf("record for height %d has no blobs", r.Height)
+	}
+
+	rootID, err := flow.CidToId(r.Blobs[0].Cid())
+	if err != nil {
+		return fmt.Errorf("invalid root blob CID for height %d: %w", r.Height, err)
+	}
+
+	if rootID != r.ExecutionDataID {
+		return fmt.Errorf("root blob %v for height %d does not match execution data ID %v", rootID, r.Height, r.ExecutionDataID)
+	}
+
+	return nil
+}
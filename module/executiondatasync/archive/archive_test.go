@@ -0,0 +1,151 @@
+package archive_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/executiondatasync/archive"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func newBlobstore() blobs.Blobstore {
+	return blobs.NewBlobstore(dssync.MutexWrap(datastore.NewMapDatastore()))
+}
+
+// addExecutionData adds execution data for count blocks to the blobstore, with a small max blob
+// size so that the blob trees have several levels.
+func addExecutionData(t *testing.T, bs blobs.Blobstore, count int) ([]*execution_data.BlockExecutionData, []flow.Identifier) {
+	store := execution_data.NewExecutionDataStore(bs, execution_data.DefaultSerializer, execution_data.WithMaxBlobSize(1000))
+
+	eds := make([]*execution_data.BlockExecutionData, count)
+	ids := make([]flow.Identifier, count)
+	for i := range eds {
+		eds[i] = unittest.BlockExecutionDataFixture(
+			unittest.WithChunkExecutionDatas(unittest.ChunkExecutionDataFixture(t, 5000)),
+		)
+
+		id, err := store.AddExecutionData(context.Background(), eds[i])
+		require.NoError(t, err)
+		ids[i] = id
+	}
+
+	return eds, ids
+}
+
+// writeArchive writes the execution data with the given IDs to an archive, at heights starting at 10.
+func writeArchive(t *testing.T, bs blobs.Blobstore, eds []*execution_data.BlockExecutionData, ids []flow.Identifier) []byte {
+	buf := new(bytes.Buffer)
+	writer, err := archive.NewWriter(buf)
+	require.NoError(t, err)
+
+	for i, id := range ids {
+		record, err := archive.NewRecord(context.Background(), bs, uint64(10+i), eds[i].BlockID, id)
+		require.NoError(t, err)
+		require.NoError(t, writer.Write(record))
+	}
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+// TestArchive_RoundTrip tests that execution data exported to an archive is imported unchanged.
+func TestArchive_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	source := newBlobstore()
+	eds, ids := addExecutionData(t, source, 3)
+	data := writeArchive(t, source, eds, ids)
+
+	reader, err := archive.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, reader.Index(), 3)
+
+	target := newBlobstore()
+	for i, entry := range reader.Index() {
+		assert.Equal(t, uint64(10+i), entry.Height)
+		assert.Equal(t, ids[i], entry.ExecutionDataID)
+
+		record, err := reader.Read(entry)
+		require.NoError(t, err)
+		assert.Greater(t, len(record.Blobs), 2)
+
+		require.NoError(t, archive.Import(ctx, target, record))
+	}
+
+	store := execution_data.NewExecutionDataStore(target, execution_data.DefaultSerializer)
+	for i, id := range ids {
+		ed, err := store.GetExecutionData(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, eds[i], ed)
+	}
+
+	entry, ok := reader.Lookup(11)
+	require.True(t, ok)
+	assert.Equal(t, ids[1], entry.ExecutionDataID)
+
+	_, ok = reader.Lookup(13)
+	assert.False(t, ok)
+}
+
+// TestArchive_CorruptedBlob tests that a blob whose data does not match its CID is rejected.
+func TestArchive_CorruptedBlob(t *testing.T) {
+	source := newBlobstore()
+	eds, ids := addExecutionData(t, source, 1)
+	data := writeArchive(t, source, eds, ids)
+
+	reader, err := archive.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	entry := reader.Index()[0]
+
+	// flip the last byte of the last blob of the record, which directly precedes the index
+	indexOffset := binary.BigEndian.Uint64(data[len(data)-16:])
+	data[indexOffset-1] ^= 0xff
+
+	reader, err = archive.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	_, err = reader.Read(entry)
+	assert.ErrorIs(t, err, archive.ErrCidMismatch)
+}
+
+// TestArchive_Truncated tests that a truncated archive is rejected.
+func TestArchive_Truncated(t *testing.T) {
+	source := newBlobstore()
+	eds, ids := addExecutionData(t, source, 2)
+	data := writeArchive(t, source, eds, ids)
+
+	truncated := data[:len(data)-10]
+	_, err := archive.NewReader(bytes.NewReader(truncated), int64(len(truncated)))
+	assert.ErrorIs(t, err, archive.ErrInvalidArchive)
+}
+
+// TestWriter_HeightOrder tests that records must be written in ascending height order.
+func TestWriter_HeightOrder(t *testing.T) {
+	source := newBlobstore()
+	eds, ids := addExecutionData(t, source, 1)
+
+	record, err := archive.NewRecord(context.Background(), source, 10, eds[0].BlockID, ids[0])
+	require.NoError(t, err)
+
+	writer, err := archive.NewWriter(new(bytes.Buffer))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Write(record))
+	assert.Error(t, writer.Write(record))
+}
+
+// TestNewRecord_MissingBlob tests that exporting execution data with missing blobs fails.
+func TestNewRecord_MissingBlob(t *testing.T) {
+	_, err := archive.NewRecord(context.Background(), newBlobstore(), 10, unittest.IdentifierFixture(), unittest.IdentifierFixture())
+	assert.True(t, execution_data.IsBlobNotFoundError(err))
+}
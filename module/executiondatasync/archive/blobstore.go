@@ -0,0 +1,80 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+)
+
+// NewRecord reads the blob tree of the given execution data from the blobstore, and returns it as
+// the record of the block at the given height.
+// The returned error will be:
+// - execution_data.BlobNotFoundError if some blob of the tree is missing, e.g. because it was pruned
+// - execution_data.MalformedDataError if some level of the blob tree cannot be deserialized
+func NewRecord(ctx context.Context, blobstore blobs.Blobstore, height uint64, blockID flow.Identifier, executionDataID flow.Identifier) (*Record, error) {
+	// the execution data store reads the whole tree starting with the root blob, so recording the
+	// blobs it reads yields all blobs of the tree, and checks that they deserialize
+	recorder := &recordingBlobstore{Blobstore: blobstore}
+	store := execution_data.NewExecutionDataStore(recorder, execution_data.DefaultSerializer)
+
+	executionData, err := store.GetExecutionData(ctx, executionDataID)
+	if err != nil {
+		return nil, err
+	}
+
+	if executionData.BlockID != blockID {
+		return nil, fmt.Errorf("execution data %v is for block %v, expected block %v", executionDataID, executionData.BlockID, blockID)
+	}
+
+	return &Record{
+		Height:          height,
+		BlockID:         blockID,
+		ExecutionDataID: executionDataID,
+		Blobs:           recorder.blobs,
+	}, nil
+}
+
+// Import adds the blobs of the record to the blobstore, and verifies that they form the complete
+// execution data of the record's block.
+func Import(ctx context.Context, blobstore blobs.Blobstore, record *Record) error {
+	if err := record.validate(); err != nil {
+		return err
+	}
+
+	if err := blobstore.PutMany(ctx, record.Blobs); err != nil {
+		return fmt.Errorf("could not add blobs for height %d: %w", record.Height, err)
+	}
+
+	store := execution_data.NewExecutionDataStore(blobstore, execution_data.DefaultSerializer)
+	executionData, err := store.GetExecutionData(ctx, record.ExecutionDataID)
+	if err != nil {
+		return fmt.Errorf("imported execution data for height %d is incomplete: %w", record.Height, err)
+	}
+
+	if executionData.BlockID != record.BlockID {
+		return fmt.Errorf("imported execution data for height %d is for block %v, expected block %v", record.Height, executionData.BlockID, record.BlockID)
+	}
+
+	return nil
+}
+
+// recordingBlobstore records the blobs read from the wrapped blobstore, in the order they are read.
+type recordingBlobstore struct {
+	blobs.Blobstore
+	blobs []blobs.Blob
+}
+
+func (r *recordingBlobstore) Get(ctx context.Context, c cid.Cid) (blobs.Blob, error) {
+	blob, err := r.Blobstore.Get(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	r.blobs = append(r.blobs, blob)
+	return blob, nil
+}
@@ -0,0 +1,208 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
+)
+
+// Reader reads records from an archive. The CID of every blob read is verified against its data.
+//
+// Reader is concurrency safe if the underlying io.ReaderAt is.
+type Reader struct {
+	r     io.ReaderAt
+	size  int64
+	index []IndexEntry
+}
+
+// NewReader returns a Reader for the archive of the given size, and reads its index.
+// The returned error will be:
+// - ErrInvalidArchive if the data is not a well-formed archive
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	if size < int64(headerSize+footerSize+8) {
+		return nil, fmt.Errorf("%w: archive is too short", ErrInvalidArchive)
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("could not read archive header: %w", err)
+	}
+	if !bytes.Equal(header[:len(magic)], magic[:]) {
+		return nil, fmt.Errorf("%w: wrong magic number", ErrInvalidArchive)
+	}
+	if version := binary.BigEndian.Uint16(header[len(magic):]); version != Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidArchive, version)
+	}
+
+	footer := make([]byte, footerSize)
+	if _, err := r.ReadAt(footer, size-int64(footerSize)); err != nil {
+		return nil, fmt.Errorf("could not read archive footer: %w", err)
+	}
+	if !bytes.Equal(footer[8:], magic[:]) {
+		return nil, fmt.Errorf("%w: wrong magic number in footer, the archive may be truncated", ErrInvalidArchive)
+	}
+
+	indexOffset := binary.BigEndian.Uint64(footer[:8])
+	indexEnd := uint64(size) - uint64(footerSize)
+	if indexOffset < uint64(headerSize) || indexOffset+8 > indexEnd {
+		return nil, fmt.Errorf("%w: index offset %d out of range", ErrInvalidArchive, indexOffset)
+	}
+
+	index, err := readIndex(io.NewSectionReader(r, int64(indexOffset), int64(indexEnd-indexOffset)), indexOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		r:     r,
+		size:  size,
+		index: index,
+	}, nil
+}
+
+func readIndex(r io.Reader, indexOffset uint64) ([]IndexEntry, error) {
+	var count uint64
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, fmt.Errorf("could not read index: %w", err)
+	}
+
+	if count > indexOffset/uint64(entrySize) {
+		return nil, fmt.Errorf("%w: index entry count %d exceeds archive size", ErrInvalidArchive, count)
+	}
+
+	index := make([]IndexEntry, count)
+	buf := make([]byte, entrySize)
+	for i := range index {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("could not read index entry %d: %w", i, err)
+		}
+
+		entry := &index[i]
+		entry.Height = binary.BigEndian.Uint64(buf)
+		copy(entry.BlockID[:], buf[8:])
+		copy(entry.ExecutionDataID[:], buf[8+flow.IdentifierLen:])
+		entry.Offset = binary.BigEndian.Uint64(buf[8+2*flow.IdentifierLen:])
+
+		if entry.Offset < uint64(headerSize) || entry.Offset >= indexOffset {
+			return nil, fmt.Errorf("%w: offset of height %d out of range", ErrInvalidArchive, entry.Height)
+		}
+		if i > 0 && entry.Height <= index[i-1].Height {
+			return nil, fmt.Errorf("%w: index is not in ascending height order", ErrInvalidArchive)
+		}
+	}
+
+	return index, nil
+}
+
+// Index returns the index entries of all records in the archive, in ascending height order.
+func (ar *Reader) Index() []IndexEntry {
+	return ar.index
+}
+
+// Lookup returns the index entry of the record at the given height.
+func (ar *Reader) Lookup(height uint64) (IndexEntry, bool) {
+	i := sort.Search(len(ar.index), func(i int) bool {
+		return ar.index[i].Height >= height
+	})
+	if i < len(ar.index) && ar.index[i].Height == height {
+		return ar.index[i], true
+	}
+	return IndexEntry{}, false
+}
+
+// Read reads the record of the given index entry, and verifies the CIDs of all its blobs.
+// The returned error will be:
+// - ErrInvalidArchive if the record is malformed or does not match the index entry
+// - ErrCidMismatch if the data of a blob does not match its CID
+func (ar *Reader) Read(entry IndexEntry) (*Record, error) {
+	r := bufio.NewReader(io.NewSectionReader(ar.r, int64(entry.Offset), ar.size-int64(entry.Offset)))
+
+	record, err := readRecord(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read record for height %d: %w", entry.Height, err)
+	}
+
+	if record.Height != entry.Height || record.BlockID != entry.BlockID || record.ExecutionDataID != entry.ExecutionDataID {
+		return nil, fmt.Errorf("%w: record at offset %d does not match index entry for height %d", ErrInvalidArchive, entry.Offset, entry.Height)
+	}
+
+	return record, nil
+}
+
+func readRecord(r io.Reader) (*Record, error) {
+	header := make([]byte, 8+2*flow.IdentifierLen+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	record := &Record{
+		Height: binary.BigEndian.Uint64(header),
+	}
+	copy(record.BlockID[:], header[8:])
+	copy(record.ExecutionDataID[:], header[8+flow.IdentifierLen:])
+	blobCount := binary.BigEndian.Uint32(header[8+2*flow.IdentifierLen:])
+
+	for i := uint32(0); i < blobCount; i++ {
+		blob, err := readBlob(r)
+		if err != nil {
+			return nil, fmt.Errorf("could not read blob %d: %w", i, err)
+		}
+		record.Blobs = append(record.Blobs, blob)
+	}
+
+	if err := record.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	return record, nil
+}
+
+func readBlob(r io.Reader) (blobs.Blob, error) {
+	var cidLen uint16
+	if err := binary.Read(r, binary.BigEndian, &cidLen); err != nil {
+		return nil, err
+	}
+
+	cidBytes := make([]byte, cidLen)
+	if _, err := io.ReadFull(r, cidBytes); err != nil {
+		return nil, err
+	}
+
+	c, err := cid.Cast(cidBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid CID: %v", ErrInvalidArchive, err)
+	}
+
+	var dataLen uint32
+	if err := binary.Read(r, binary.BigEndian, &dataLen); err != nil {
+		return nil, err
+	}
+	if dataLen > maxBlobSize {
+		return nil, fmt.Errorf("%w: blob %s exceeds maximum size", ErrInvalidArchive, c)
+	}
+
+	data := make([]byte, dataLen)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	// blobs are content-addressed, so their CID must be the hash of their data
+	actual, err := c.Prefix().Sum(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not hash blob %s: %w", c, err)
+	}
+	if !actual.Equals(c) {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrCidMismatch, c, actual)
+	}
+
+	return blocks.NewBlockWithCid(data, c)
+}
@@ -0,0 +1,158 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Writer writes records to an archive. Records must be written in strictly ascending height order.
+// The archive is only complete once Close has been called.
+//
+// Writer is not concurrency safe.
+type Writer struct {
+	w       *bufio.Writer
+	offset  uint64
+	index   []IndexEntry
+	closed  bool
+	scratch [8]byte
+}
+
+// NewWriter returns a new Writer, which writes the archive header to w.
+func NewWriter(w io.Writer) (*Writer, error) {
+	aw := &Writer{
+		w: bufio.NewWriter(w),
+	}
+
+	if err := aw.write(magic[:]); err != nil {
+		return nil, fmt.Errorf("could not write archive header: %w", err)
+	}
+	if err := aw.writeUint16(Version); err != nil {
+		return nil, fmt.Errorf("could not write archive header: %w", err)
+	}
+
+	return aw, nil
+}
+
+// Write appends the record to the archive.
+func (aw *Writer) Write(record *Record) error {
+	if aw.closed {
+		return errors.New("archive writer is closed")
+	}
+
+	if n := len(aw.index); n > 0 && record.Height <= aw.index[n-1].Height {
+		return fmt.Errorf("record height %d is not above previous height %d", record.Height, aw.index[n-1].Height)
+	}
+
+	if err := record.validate(); err != nil {
+		return err
+	}
+
+	entry := IndexEntry{
+		Height:          record.Height,
+		BlockID:         record.BlockID,
+		ExecutionDataID: record.ExecutionDataID,
+		Offset:          aw.offset,
+	}
+
+	if err := aw.writeUint64(record.Height); err != nil {
+		return err
+	}
+	if err := aw.write(record.BlockID[:]); err != nil {
+		return err
+	}
+	if err := aw.write(record.ExecutionDataID[:]); err != nil {
+		return err
+	}
+	if err := aw.writeUint32(uint32(len(record.Blobs))); err != nil {
+		return err
+	}
+
+	for _, blob := range record.Blobs {
+		c := blob.Cid().Bytes()
+		data := blob.RawData()
+
+		if len(c) > math.MaxUint16 || len(data) > maxBlobSize {
+			return fmt.Errorf("blob %s for height %d is too large", blob.Cid(), record.Height)
+		}
+
+		if err := aw.writeUint16(uint16(len(c))); err != nil {
+			return err
+		}
+		if err := aw.write(c); err != nil {
+			return err
+		}
+		if err := aw.writeUint32(uint32(len(data))); err != nil {
+			return err
+		}
+		if err := aw.write(data); err != nil {
+			return err
+		}
+	}
+
+	aw.index = append(aw.index, entry)
+
+	return nil
+}
+
+// Close writes the index and footer of the archive, and flushes all buffered data.
+// It does not close the underlying writer.
+func (aw *Writer) Close() error {
+	if aw.closed {
+		return nil
+	}
+	aw.closed = true
+
+	indexOffset := aw.offset
+
+	if err := aw.writeUint64(uint64(len(aw.index))); err != nil {
+		return fmt.Errorf("could not write index: %w", err)
+	}
+	for _, entry := range aw.index {
+		if err := aw.writeUint64(entry.Height); err != nil {
+			return fmt.Errorf("could not write index: %w", err)
+		}
+		if err := aw.write(entry.BlockID[:]); err != nil {
+			return fmt.Errorf("could not write index: %w", err)
+		}
+		if err := aw.write(entry.ExecutionDataID[:]); err != nil {
+			return fmt.Errorf("could not write index: %w", err)
+		}
+		if err := aw.writeUint64(entry.Offset); err != nil {
+			return fmt.Errorf("could not write index: %w", err)
+		}
+	}
+
+	if err := aw.writeUint64(indexOffset); err != nil {
+		return fmt.Errorf("could not write footer: %w", err)
+	}
+	if err := aw.write(magic[:]); err != nil {
+		return fmt.Errorf("could not write footer: %w", err)
+	}
+
+	return aw.w.Flush()
+}
+
+func (aw *Writer) write(b []byte) error {
+	n, err := aw.w.Write(b)
+	aw.offset += uint64(n)
+	return err
+}
+
+func (aw *Writer) writeUint16(v uint16) error {
+	binary.BigEndian.PutUint16(aw.scratch[:2], v)
+	return aw.write(aw.scratch[:2])
+}
+
+func (aw *Writer) writeUint32(v uint32) error {
+	binary.BigEndian.PutUint32(aw.scratch[:4], v)
+	return aw.write(aw.scratch[:4])
+}
+
+func (aw *Writer) writeUint64(v uint64) error {
+	binary.BigEndian.PutUint64(aw.scratch[:], v)
+	return aw.write(aw.scratch[:])
+}
@@ -25,6 +25,14 @@ type Downloader interface {
 	// - BlobNotFoundError if some CID in the blob tree could not be found from the blob service
 	// - BlobSizeLimitExceededError if some blob in the blob tree exceeds the maximum allowed size
 	Download(ctx context.Context, executionDataID flow.Identifier) (*BlockExecutionData, error)
+
+	// DownloadBatch downloads and returns the Block Execution Datas with the given IDs from the
+	// network, in the same order as the IDs.
+	// The returned error will be:
+	// - MalformedDataError if some level of some blob tree cannot be properly deserialized
+	// - BlobNotFoundError if some CID in some blob tree could not be found from the blob service
+	// - BlobSizeLimitExceededError if some blob in some blob tree exceeds the maximum allowed size
+	DownloadBatch(ctx context.Context, executionDataIDs []flow.Identifier) ([]*BlockExecutionData, error)
 }
 
 type downloader struct {
@@ -77,9 +85,81 @@ func (d *downloader) Download(ctx context.Context, executionDataID flow.Identifi
 		return nil, fmt.Errorf("failed to get execution data root: %w", err)
 	}
 
+	// Next, download each of the chunk execution data blobs
+	return d.downloadChunks(ctx, edRoot, blobGetter)
+}
+
+// DownloadBatch downloads the blob trees identified by executionDataIDs from the network within a
+// single session, and returns the deserialized BlockExecutionData structs in the order of the IDs.
+// The root blobs of all trees are requested in one batch, and the chunk execution data of all trees
+// is downloaded concurrently, so that the blob service can spread the requests across peers.
+// During normal operation, the returned error will be:
+// - MalformedDataError if some level of some blob tree cannot be properly deserialized
+// - BlobNotFoundError if some CID in some blob tree could not be found from the blob service
+// - BlobSizeLimitExceededError if some blob in some blob tree exceeds the maximum allowed size
+func (d *downloader) DownloadBatch(ctx context.Context, executionDataIDs []flow.Identifier) ([]*BlockExecutionData, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	blobGetter := d.blobService.GetSession(ctx)
+
+	rootCids := make([]cid.Cid, len(executionDataIDs))
+	for i, executionDataID := range executionDataIDs {
+		rootCids[i] = flow.IdToCid(executionDataID)
+	}
+
+	// First, download the root execution data records of all blob trees.
+	// Blobs are received in the order of the CIDs.
+	blobCh, errCh := d.retrieveBlobs(ctx, blobGetter, rootCids)
+	edRoots := make([]*BlockExecutionDataRoot, 0, len(executionDataIDs))
+	for blob := range blobCh {
+		edRoot, err := d.decodeExecutionDataRoot(blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get execution data root %v: %w", executionDataIDs[len(edRoots)], err)
+		}
+		edRoots = append(edRoots, edRoot)
+	}
+
+	if err := <-errCh; err != nil {
+		return nil, fmt.Errorf("failed to get execution data roots: %w", err)
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	// Next, download the chunk execution data blobs of all blob trees
+	beds := make([]*BlockExecutionData, len(edRoots))
+	for i, edRoot := range edRoots {
+		i := i
+		edRoot := edRoot
+
+		g.Go(func() error {
+			bed, err := d.downloadChunks(gCtx, edRoot, blobGetter)
+			if err != nil {
+				return fmt.Errorf("failed to get execution data %v: %w", executionDataIDs[i], err)
+			}
+
+			beds[i] = bed
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return beds, nil
+}
+
+// downloadChunks downloads each of the chunk execution data blobs of the given root, and recombines
+// them into the original record.
+func (d *downloader) downloadChunks(
+	ctx context.Context,
+	edRoot *BlockExecutionDataRoot,
+	blobGetter network.BlobGetter,
+) (*BlockExecutionData, error) {
 	g, gCtx := errgroup.WithContext(ctx)
 
-	// Next, download each of the chunk execution data blobs
 	chunkExecutionDatas := make([]*ChunkExecutionData, len(edRoot.ChunkExecutionDataIDs))
 	for i, chunkDataID := range edRoot.ChunkExecutionDataIDs {
 		i := i
@@ -106,7 +186,6 @@ func (d *downloader) Download(ctx context.Context, executionDataID flow.Identifi
 		return nil, err
 	}
 
-	// Finally, recombine data into original record.
 	bed := &BlockExecutionData{
 		BlockID:             edRoot.BlockID,
 		ChunkExecutionDatas: chunkExecutionDatas,
@@ -131,6 +210,11 @@ func (d *downloader) getExecutionDataRoot(
 		return nil, fmt.Errorf("failed to get root blob: %w", err)
 	}
 
+	return d.decodeExecutionDataRoot(blob)
+}
+
+// decodeExecutionDataRoot checks the size of the given root blob, and deserializes it.
+func (d *downloader) decodeExecutionDataRoot(blob blobs.Blob) (*BlockExecutionDataRoot, error) {
 	blobSize := len(blob.RawData())
 
 	if blobSize > d.maxBlobSize {
@@ -12,9 +12,11 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module/blobs"
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 	"github.com/onflow/flow-go/network/mocknetwork"
+	"github.com/onflow/flow-go/utils/unittest"
 )
 
 func TestCIDNotFound(t *testing.T) {
@@ -58,3 +60,53 @@ func TestCIDNotFound(t *testing.T) {
 	var blobNotFoundError *execution_data.BlobNotFoundError
 	assert.ErrorAs(t, err, &blobNotFoundError)
 }
+
+func TestDownloadBatch(t *testing.T) {
+	blobstore := blobs.NewBlobstore(dssync.MutexWrap(datastore.NewMapDatastore()))
+	blobService := new(mocknetwork.BlobService)
+	downloader := execution_data.NewDownloader(blobService)
+	edStore := execution_data.NewExecutionDataStore(blobstore, execution_data.DefaultSerializer)
+
+	var beds []*execution_data.BlockExecutionData
+	var edIDs []flow.Identifier
+	for i := 0; i < 3; i++ {
+		bed := generateBlockExecutionData(t, 3, 2*execution_data.DefaultMaxBlobSize)
+		edID, err := edStore.AddExecutionData(context.Background(), bed)
+		require.NoError(t, err)
+		beds = append(beds, bed)
+		edIDs = append(edIDs, edID)
+	}
+
+	blobGetter := new(mocknetwork.BlobGetter)
+	blobService.On("GetSession", mock.Anything).Return(blobGetter, nil).Once()
+	blobGetter.On("GetBlobs", mock.Anything, mock.AnythingOfType("[]cid.Cid")).Return(
+		func(ctx context.Context, cids []cid.Cid) <-chan blobs.Blob {
+			// return the blobs in reverse order, as they may arrive in any order
+			blobCh := make(chan blobs.Blob, len(cids))
+			for i := len(cids) - 1; i >= 0; i-- {
+				blob, err := blobstore.Get(ctx, cids[i])
+				if err == nil {
+					blobCh <- blob
+				}
+			}
+			close(blobCh)
+			return blobCh
+		},
+	)
+
+	downloaded, err := downloader.DownloadBatch(context.Background(), edIDs)
+	require.NoError(t, err)
+	require.Len(t, downloaded, len(beds))
+	for i, bed := range beds {
+		assert.Equal(t, bed, downloaded[i])
+	}
+
+	// the root blobs of all blob trees are requested in a single batch
+	blobGetter.AssertNumberOfCalls(t, "GetBlob", 0)
+
+	// the whole batch fails if some execution data is missing
+	blobService.On("GetSession", mock.Anything).Return(blobGetter, nil).Once()
+	_, err = downloader.DownloadBatch(context.Background(), []flow.Identifier{edIDs[0], unittest.IdentifierFixture()})
+	var blobNotFoundError *execution_data.BlobNotFoundError
+	assert.ErrorAs(t, err, &blobNotFoundError)
+}
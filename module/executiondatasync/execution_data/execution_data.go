@@ -14,6 +14,11 @@ type ChunkExecutionData struct {
 	Collection *flow.Collection
 	Events     flow.EventsList
 	TrieUpdate *ledger.TrieUpdate
+
+	// TransactionResults are the results of the transactions of the collection, in execution order.
+	// It is empty for blocks below the execution data transaction results height of the execution
+	// nodes, and then omitted from the encoding, which is the same as before the field was added.
+	TransactionResults []flow.TransactionResult `cbor:",omitempty"`
 }
 
 type BlockExecutionDataRoot struct {
@@ -0,0 +1,112 @@
+package execution_data_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/encoding/cbor"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+)
+
+// legacyChunkExecutionData is ChunkExecutionData as it was before the transaction results were added.
+type legacyChunkExecutionData struct {
+	Collection *flow.Collection
+	Events     flow.EventsList
+	TrieUpdate *ledger.TrieUpdate
+}
+
+// the serialized chunk execution data returned by chunkExecutionDataFixture, and its CID.
+const (
+	serializedChunkExecutionData = "0304224d186470b9c1010000fa05a3664576656e747381a56454797065781a412e300100f847312e466f6f2e426172675061796c6f6164477061796c6f61646a4576656e74496e646578006d5472616e73616374696f6e4944582093ca991f0ea36819459a960468d981d298a9eaf25129ec5db925b950af8a130c703000f7036e646578006a436f6c6c656374696f6ea16c1e00f60d7381a965506179657248000000000000000166536372697074581a747400f524207b2065786563757465207b7d207d684761734c696d69741903e869417267756d656e7473f66b417574686f72697a657273815000f5066b50726f706f73616c4b6579a367416464726573736e00f21f684b6579496e646578006e53657175656e63654e756d62657201705265666572656e6365426c6f636b494458200153000f02000613714301f7055369676e617475726573f672456e76656c6f70651400ff076a54726965557064617465a36550617468738158200355000503020013685c00f3167381a2634b6579a1684b6579506172747381a26454797065006556616c7565456f776e65720c00f20276616c756568526f6f74486173685820024b00000200000200000200d00000000000000000000000000000000000e031ef02"
+	chunkExecutionDataCID        = "QmZUAeHabWJ4LgEu3nAayRvbNNKr5pMWFpL6XyVzSe8QKx"
+)
+
+// TestChunkExecutionData_EncodingWithoutTransactionResults tests that chunk execution data without
+// transaction results is encoded exactly as before they were added, so that the execution data IDs
+// of blocks below the activation height do not change.
+func TestChunkExecutionData_EncodingWithoutTransactionResults(t *testing.T) {
+	chunk := chunkExecutionDataFixture()
+
+	encoded, err := cbor.EncMode.Marshal(chunk)
+	require.NoError(t, err)
+	legacy, err := cbor.EncMode.Marshal(&legacyChunkExecutionData{
+		Collection: chunk.Collection,
+		Events:     chunk.Events,
+		TrieUpdate: chunk.TrieUpdate,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, legacy, encoded)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, execution_data.DefaultSerializer.Serialize(buf, chunk))
+	assert.Equal(t, serializedChunkExecutionData, hex.EncodeToString(buf.Bytes()))
+	assert.Equal(t, chunkExecutionDataCID, blobs.NewBlob(buf.Bytes()).Cid().String())
+}
+
+// TestChunkExecutionData_TransactionResultsRoundTrip tests that transaction results, when included,
+// are part of the encoding and survive a round trip.
+func TestChunkExecutionData_TransactionResultsRoundTrip(t *testing.T) {
+	chunk := chunkExecutionDataFixture()
+	chunk.TransactionResults = []flow.TransactionResult{
+		{
+			TransactionID:   chunk.Collection.Transactions[0].ID(),
+			ErrorMessage:    "error",
+			ComputationUsed: 42,
+			MemoryUsed:      7,
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, execution_data.DefaultSerializer.Serialize(buf, chunk))
+	assert.NotEqual(t, serializedChunkExecutionData, hex.EncodeToString(buf.Bytes()))
+
+	decoded, err := execution_data.DefaultSerializer.Deserialize(buf)
+	require.NoError(t, err)
+	assert.Equal(t, chunk, decoded)
+}
+
+func chunkExecutionDataFixture() *execution_data.ChunkExecutionData {
+	address := flow.HexToAddress("0000000000000001")
+	tx := &flow.TransactionBody{
+		ReferenceBlockID: flow.Identifier{1},
+		Script:           []byte("transaction { execute {} }"),
+		GasLimit:         1000,
+		ProposalKey: flow.ProposalKey{
+			Address:        address,
+			KeyIndex:       0,
+			SequenceNumber: 1,
+		},
+		Payer:       address,
+		Authorizers: []flow.Address{address},
+	}
+
+	return &execution_data.ChunkExecutionData{
+		Collection: &flow.Collection{Transactions: []*flow.TransactionBody{tx}},
+		Events: flow.EventsList{
+			{
+				Type:             "A.0000000000000001.Foo.Bar",
+				TransactionID:    tx.ID(),
+				TransactionIndex: 0,
+				EventIndex:       0,
+				Payload:          []byte("payload"),
+			},
+		},
+		TrieUpdate: &ledger.TrieUpdate{
+			RootHash: ledger.RootHash{2},
+			Paths:    []ledger.Path{{3}},
+			Payloads: []*ledger.Payload{
+				ledger.NewPayload(
+					ledger.NewKey([]ledger.KeyPart{ledger.NewKeyPart(0, []byte("owner"))}),
+					[]byte("value"),
+				),
+			},
+		},
+	}
+}
@@ -58,6 +58,32 @@ func (_m *Downloader) Download(ctx context.Context, executionDataID flow.Identif
 	return r0, r1
 }
 
+// DownloadBatch provides a mock function with given fields: ctx, executionDataIDs
+func (_m *Downloader) DownloadBatch(ctx context.Context, executionDataIDs []flow.Identifier) ([]*execution_data.BlockExecutionData, error) {
+	ret := _m.Called(ctx, executionDataIDs)
+
+	var r0 []*execution_data.BlockExecutionData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []flow.Identifier) ([]*execution_data.BlockExecutionData, error)); ok {
+		return rf(ctx, executionDataIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []flow.Identifier) []*execution_data.BlockExecutionData); ok {
+		r0 = rf(ctx, executionDataIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*execution_data.BlockExecutionData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []flow.Identifier) error); ok {
+		r1 = rf(ctx, executionDataIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Ready provides a mock function with given fields:
 func (_m *Downloader) Ready() <-chan struct{} {
 	ret := _m.Called()
@@ -87,3 +87,24 @@ func WithSignallerAndCancel(ctx context.Context) (SignalerContext, context.Cance
 	irrecoverableCtx, errCh := WithSignaler(parent)
 	return irrecoverableCtx, cancel, errCh
 }
+
+// WithCancel returns a child of the given SignalerContext which is canceled when the returned
+// cancel function is called, like context.WithCancel. Errors thrown with the child are thrown
+// with the parent's signaler.
+func WithCancel(parent SignalerContext) (SignalerContext, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	return &childSignalerCtx{ctx, parent}, cancel
+}
+
+// childSignalerCtx is a SignalerContext derived from another SignalerContext.
+type childSignalerCtx struct {
+	context.Context
+	parent SignalerContext
+}
+
+func (sc *childSignalerCtx) sealed() {}
+
+// Throw delegates to the signaler of the parent context.
+func (sc *childSignalerCtx) Throw(err error) {
+	sc.parent.Throw(err)
+}
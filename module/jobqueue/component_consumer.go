@@ -34,6 +34,7 @@ func NewComponentConsumer(
 	processor JobProcessor, // method used to process jobs
 	maxProcessing uint64,
 	maxSearchAhead uint64,
+	opts ...ConsumerOption, // optional consumer config, e.g. job timeouts and dead-lettering
 ) *ComponentConsumer {
 
 	c := &ComponentConsumer{
@@ -48,7 +49,7 @@ func NewComponentConsumer(
 		func(id module.JobID) { c.NotifyJobIsDone(id) },
 		maxProcessing,
 	)
-	c.consumer = NewConsumer(c.log, c.jobs, progress, worker, maxProcessing, maxSearchAhead)
+	c.consumer = NewConsumer(c.log, c.jobs, progress, worker, maxProcessing, maxSearchAhead, opts...)
 
 	builder := component.NewComponentManagerBuilder().
 		AddWorker(func(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog"
 	"go.uber.org/atomic"
@@ -17,6 +18,48 @@ type Worker interface {
 	Run(job module.Job) error
 }
 
+// CancelableWorker is a Worker which can stop processing a job. It is used by the consumer to stop
+// an attempt of a job which has timed out, before the job is retried or dead-lettered.
+type CancelableWorker interface {
+	Worker
+
+	// Cancel stops processing the given job, if it is being processed.
+	Cancel(jobID module.JobID)
+}
+
+// ConsumerOption configures optional behavior of a Consumer.
+type ConsumerOption func(*Consumer)
+
+// JobTimeoutConfig configures how the consumer handles jobs which are not done in time.
+type JobTimeoutConfig struct {
+	// Timeout is the time an attempt of a job may take before the job is retried. 0 disables timeouts.
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts of a job before it is dead-lettered. Jobs are only
+	// dead-lettered if the consumer has a dead-letter store, and are retried indefinitely otherwise.
+	MaxAttempts uint
+	// RetryDelay is the delay before the first retry of a job, which doubles with each further
+	// retry up to MaxRetryDelay.
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+}
+
+// DefaultJobTimeoutConfig returns the default job timeout config. Timeouts are disabled by default.
+func DefaultJobTimeoutConfig() JobTimeoutConfig {
+	return JobTimeoutConfig{
+		Timeout:       0,
+		MaxAttempts:   3,
+		RetryDelay:    time.Second,
+		MaxRetryDelay: time.Minute,
+	}
+}
+
+// WithJobTimeout configures the consumer to retry jobs which are not done within the configured timeout.
+func WithJobTimeout(config JobTimeoutConfig) ConsumerOption {
+	return func(c *Consumer) {
+		c.timeouts = config
+	}
+}
+
 type Consumer struct {
 	mu  sync.Mutex
 	log zerolog.Logger
@@ -43,6 +86,10 @@ type Consumer struct {
 	processings      map[uint64]*jobStatus   // keep track of the status of each on going job
 	processingsIndex map[module.JobID]uint64 // lookup the index of the job, useful when fast forwarding the
 	// `processed` variable
+
+	timeouts    JobTimeoutConfig
+	deadLetters storage.JobDeadLetters  // optional store of jobs which exhausted their attempts
+	retrying    map[module.JobID]uint64 // dead-lettered jobs which are being retried, by job ID
 }
 
 func NewConsumer(
@@ -52,8 +99,9 @@ func NewConsumer(
 	worker Worker,
 	maxProcessing uint64,
 	maxSearchAhead uint64,
+	opts ...ConsumerOption,
 ) *Consumer {
-	return &Consumer{
+	c := &Consumer{
 		log: log.With().Str("sub_module", "job_queue").Logger(),
 
 		// store dependency
@@ -71,7 +119,14 @@ func NewConsumer(
 		processedIndex:   0,
 		processings:      make(map[uint64]*jobStatus),
 		processingsIndex: make(map[module.JobID]uint64),
+		retrying:         make(map[module.JobID]uint64),
+	}
+
+	for _, apply := range opts {
+		apply(c)
 	}
+
+	return c
 }
 
 // Start starts consuming the jobs from the job queue.
@@ -154,6 +209,10 @@ func (c *Consumer) NotifyJobIsDone(jobID module.JobID) uint64 {
 	defer c.mu.Unlock()
 	c.log.Debug().Str("job_id", string(jobID)).Msg("finishing job")
 
+	if index, ok := c.retrying[jobID]; ok {
+		c.doneDeadLetter(jobID, index)
+	}
+
 	if c.doneJob(jobID) {
 		c.checkProcessable()
 	}
@@ -220,20 +279,15 @@ func (c *Consumer) run() (int64, error) {
 	for _, indexedJob := range processables {
 		jobID := indexedJob.job.ID()
 
-		c.processingsIndex[jobID] = indexedJob.index
-		c.processings[indexedJob.index] = &jobStatus{
+		status := &jobStatus{
 			jobID: jobID,
+			job:   indexedJob.job,
 			done:  false,
 		}
+		c.processingsIndex[jobID] = indexedJob.index
+		c.processings[indexedJob.index] = status
 
-		c.runningJobs.Add(1)
-		go func(j *jobAtIndex) {
-			err := c.worker.Run(j.job)
-			if err != nil {
-				c.log.Fatal().Err(err).Msg("could not run the job")
-			}
-			c.runningJobs.Done()
-		}(indexedJob)
+		c.startAttempt(indexedJob.index, status)
 	}
 
 	err = c.progress.SetProcessedIndex(processedTo)
@@ -366,15 +420,114 @@ func (c *Consumer) doneJob(jobID module.JobID) bool {
 	}
 
 	status.done = true
+	if status.timer != nil {
+		status.timer.Stop()
+	}
 	return true
 }
 
+// startAttempt runs the next attempt of the job at the given index, and starts its timeout.
+// Must be called while holding the lock.
+func (c *Consumer) startAttempt(index uint64, status *jobStatus) {
+	status.attempts++
+	if c.timeouts.Timeout > 0 {
+		attempt := status.attempts
+		status.timer = time.AfterFunc(c.timeouts.Timeout, func() {
+			c.onJobTimeout(index, status, attempt)
+		})
+	}
+
+	c.runJob(status.job)
+}
+
+// runJob passes the job to the worker. Must be called while holding the lock.
+func (c *Consumer) runJob(job module.Job) {
+	c.runningJobs.Add(1)
+	go func() {
+		err := c.worker.Run(job)
+		if err != nil {
+			c.log.Fatal().Err(err).Msg("could not run the job")
+		}
+		c.runningJobs.Done()
+	}()
+}
+
+// isCurrentAttempt returns true if the given attempt is the latest attempt of a job which is not done yet.
+// Must be called while holding the lock.
+func (c *Consumer) isCurrentAttempt(index uint64, status *jobStatus, attempt uint) bool {
+	return c.running && c.processings[index] == status && !status.done && status.attempts == attempt
+}
+
+// onJobTimeout is called when an attempt of a job timed out. The job is retried after the retry delay,
+// or dead-lettered if it has exhausted its attempts, so that the consumer can move past it.
+func (c *Consumer) onJobTimeout(index uint64, status *jobStatus, attempt uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.isCurrentAttempt(index, status, attempt) {
+		return
+	}
+
+	if worker, ok := c.worker.(CancelableWorker); ok {
+		worker.Cancel(status.jobID)
+	}
+
+	lg := c.log.With().
+		Str("job_id", string(status.jobID)).
+		Uint64("index", index).
+		Uint("attempts", attempt).
+		Logger()
+
+	if c.deadLetters != nil && attempt >= c.timeouts.MaxAttempts {
+		err := c.deadLetters.Add(&storage.DeadLetteredJob{
+			Index:          index,
+			JobID:          string(status.jobID),
+			Attempts:       attempt,
+			Reason:         fmt.Sprintf("job was not done within %s", c.timeouts.Timeout),
+			DeadLetteredAt: time.Now(),
+		})
+		if err == nil {
+			lg.Error().Msg("job exhausted its attempts and was dead-lettered")
+			c.doneJob(status.jobID)
+			c.checkProcessable()
+			return
+		}
+		lg.Error().Err(err).Msg("could not dead-letter job, retrying")
+	}
+
+	delay := c.retryDelay(attempt)
+	lg.Warn().Dur("retry_delay", delay).Msg("job timed out, retrying")
+	status.timer = time.AfterFunc(delay, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.isCurrentAttempt(index, status, attempt) {
+			c.startAttempt(index, status)
+		}
+	})
+}
+
+// retryDelay returns the delay before retrying a job after the given number of attempts.
+func (c *Consumer) retryDelay(attempts uint) time.Duration {
+	delay := c.timeouts.RetryDelay
+	for i := uint(1); i < attempts && delay < c.timeouts.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if c.timeouts.MaxRetryDelay > 0 && delay > c.timeouts.MaxRetryDelay {
+		delay = c.timeouts.MaxRetryDelay
+	}
+	return delay
+}
+
 type jobAtIndex struct {
 	job   module.Job
 	index uint64
 }
 
 type jobStatus struct {
-	jobID module.JobID
-	done  bool
+	jobID    module.JobID
+	job      module.Job
+	done     bool
+	attempts uint        // number of attempts to process the job
+	timer    *time.Timer // timeout of the current attempt, or the delay before the next attempt
 }
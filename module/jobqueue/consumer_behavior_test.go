@@ -15,6 +15,8 @@ import (
 	"github.com/onflow/flow-go/module/jobqueue"
 	"github.com/onflow/flow-go/storage"
 	"github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/operation/dbtest"
+	"github.com/onflow/flow-go/storage/store"
 	"github.com/onflow/flow-go/utils/unittest"
 )
 
@@ -89,14 +91,14 @@ func TestConsumer(t *testing.T) {
 }
 
 func testOnStartup(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		assertProcessed(t, cp, 0)
 	})
 }
 
 func TestProcessedOrder(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(5))
 		assertProcessed(t, cp, 5)
 	})
@@ -105,7 +107,7 @@ func TestProcessedOrder(t *testing.T) {
 // [+1] => 									[0#, 1!]
 // when received job 1, it will be processed
 func testOnReceiveOneJob(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 
@@ -121,7 +123,7 @@ func testOnReceiveOneJob(t *testing.T) {
 // [+1, 1*] => 							[0#, 1#]
 // when job 1 is finished, it will be marked as processed
 func testOnJobFinished(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 
@@ -138,7 +140,7 @@ func testOnJobFinished(t *testing.T) {
 // [+1, +2, 1*, 2*] => 			[0#, 1#, 2#]
 // when job 2 and 1 are finished, they will be marked as processed
 func testOnJobsFinished(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 		c.Check()
@@ -159,7 +161,7 @@ func testOnJobsFinished(t *testing.T) {
 // [+1, +2, +3, +4] => 			[0#, 1!, 2!, 3!, 4]
 // when more jobs are arrived than the max number of workers, only the first 3 jobs will be processed
 func testMaxWorker(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 		c.Check()
@@ -183,7 +185,7 @@ func testMaxWorker(t *testing.T) {
 // [+1, +2, +3, +4, +5, +6] => [0#, !1, *2, *3, *4, *5, 6, +7] => [0#, *1, *2, *3, *4, *5, !6, !7]
 // when processing lags behind, the consumer is paused until processing catches up
 func testPauseResume(t *testing.T) {
-	runWithSeatchAhead(t, 5, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWithSeatchAhead(t, 5, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 		c.Check()
@@ -229,7 +231,7 @@ func testPauseResume(t *testing.T) {
 // [+1, +2, +3, +4, 3*] => 	[0#, 1!, 2!, 3*, 4!]
 // when job 3 is finished, which is not the next processing job 1, the processed index won't change
 func testNonNextFinished(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 		c.Check()
@@ -259,7 +261,7 @@ func testNonNextFinished(t *testing.T) {
 //
 // [+1, +2, +3, +3, +4] => 	[1, 2, 3*, 4] => [1, 2, 3*, 4*] => => [1#, 2, 3*, 4*] => [1#, 2#, 3#, 4#]
 func testMovingProcessedIndex(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 		c.Check()
@@ -312,7 +314,7 @@ func testMovingProcessedIndex(t *testing.T) {
 // [+1, +2, +3, +4, 3*, 2*] => 			[0#, 1!, 2*, 3*, 4!]
 // when job 3 and 2 are finished, the processed index won't change, because 1 is still not finished
 func testTwoNonNextFinished(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 		c.Check()
@@ -339,7 +341,7 @@ func testTwoNonNextFinished(t *testing.T) {
 // [+1, +2, +3, +4, 3*, 2*, +5] =>	[0#, 1!, 2*, 3*, 4!, 5!]
 // when job 5 is received, it will be processed, because the worker has capacity
 func testProcessingWithNonNextFinished(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 		c.Check()
@@ -369,7 +371,7 @@ func testProcessingWithNonNextFinished(t *testing.T) {
 // [+1, +2, +3, +4, 3*, 2*, +5, +6] =>	[0#, 1!, 2*, 3*, 4!, 5!, 6]
 // when job 6 is received, no more worker can process it, it will be buffered
 func testMaxWorkerWithFinishedNonNexts(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 		c.Check()
@@ -402,7 +404,7 @@ func testMaxWorkerWithFinishedNonNexts(t *testing.T) {
 // [+1, +2, +3, +4, 3*, 2*, +5, 1*] => [0#, 1#, 2#, 3#, 4!, 5!]
 // when job 1 is finally finished, it will fast forward the processed index to 3
 func testFastforward(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 		c.Check()
@@ -434,7 +436,7 @@ func testFastforward(t *testing.T) {
 // [+1, +2, +3, +4, 3*, 2*, +5, 1*, +6, +7, 6*], restart => [0#, 1#, 2#, 3#, 4!, 5!, 6*, 7!]
 // when job queue crashed and restarted, the queue can be resumed
 func testWorkOnNextAfterFastforward(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		require.NoError(t, j.PushOne()) // +1
 		c.Check()
@@ -469,7 +471,7 @@ func testWorkOnNextAfterFastforward(t *testing.T) {
 		// rebuild a consumer with the dependencies to simulate a restart
 		// jobs need to be reused, since it stores all the jobs
 		reWorker := newMockWorker()
-		reProgress := badger.NewConsumerProgress(db, ConsumerTag)
+		reProgress := store.NewConsumerProgress(db, ConsumerTag)
 		reConsumer := newTestConsumer(reProgress, j, reWorker, 0)
 
 		err := reConsumer.Start(DefaultIndex)
@@ -485,7 +487,7 @@ func testWorkOnNextAfterFastforward(t *testing.T) {
 // [+1, +2, +3, +4, Stop, 2*] => [0#, 1!, 2*, 3!, 4]
 // when Stop is called, it won't work on any job any more
 func testStopRunning(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		for i := 0; i < 4; i++ {
 			require.NoError(t, j.PushOne())
@@ -507,7 +509,7 @@ func testStopRunning(t *testing.T) {
 }
 
 func testConcurrency(t *testing.T) {
-	runWith(t, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	runWith(t, func(t *testing.T, c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db storage.DB) {
 		require.NoError(t, c.Start(DefaultIndex))
 		var finishAll sync.WaitGroup
 		finishAll.Add(100)
@@ -549,17 +551,18 @@ func testConcurrency(t *testing.T) {
 type JobID = module.JobID
 type Job = module.Job
 
-func runWith(t testing.TB, runTestWith func(module.JobConsumer, storage.ConsumerProgress, *mockWorker, *jobqueue.MockJobs, *badgerdb.DB)) {
+// runWith runs the test against a consumer whose progress is stored in a database of each supported backend.
+func runWith(t *testing.T, runTestWith func(*testing.T, module.JobConsumer, storage.ConsumerProgress, *mockWorker, *jobqueue.MockJobs, storage.DB)) {
 	runWithSeatchAhead(t, 0, runTestWith)
 }
 
-func runWithSeatchAhead(t testing.TB, maxSearchAhead uint64, runTestWith func(module.JobConsumer, storage.ConsumerProgress, *mockWorker, *jobqueue.MockJobs, *badgerdb.DB)) {
-	unittest.RunWithBadgerDB(t, func(db *badgerdb.DB) {
+func runWithSeatchAhead(t *testing.T, maxSearchAhead uint64, runTestWith func(*testing.T, module.JobConsumer, storage.ConsumerProgress, *mockWorker, *jobqueue.MockJobs, storage.DB)) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
 		jobs := jobqueue.NewMockJobs()
 		worker := newMockWorker()
-		progress := badger.NewConsumerProgress(db, ConsumerTag)
+		progress := store.NewConsumerProgress(db, ConsumerTag)
 		consumer := newTestConsumer(progress, jobs, worker, maxSearchAhead)
-		runTestWith(consumer, progress, worker, jobs, db)
+		runTestWith(t, consumer, progress, worker, jobs, db)
 	})
 }
 
@@ -629,7 +632,11 @@ func (w *mockWorker) AssertCalled(t *testing.T, expectCalled []int64) {
 // 0.22 ms to finish job
 func BenchmarkPushAndConsume(b *testing.B) {
 	b.StopTimer()
-	runWith(b, func(c module.JobConsumer, cp storage.ConsumerProgress, w *mockWorker, j *jobqueue.MockJobs, db *badgerdb.DB) {
+	unittest.RunWithBadgerDB(b, func(db *badgerdb.DB) {
+		j := jobqueue.NewMockJobs()
+		w := newMockWorker()
+		c := newTestConsumer(badger.NewConsumerProgress(db, ConsumerTag), j, w, 0)
+
 		var wg sync.WaitGroup
 		wg.Add(b.N)
 
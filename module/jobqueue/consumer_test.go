@@ -7,13 +7,13 @@ import (
 	"testing"
 	"time"
 
-	badgerdb "github.com/dgraph-io/badger/v2"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/storage"
-	"github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/operation/dbtest"
+	"github.com/onflow/flow-go/storage/store"
 	"github.com/onflow/flow-go/utils/unittest"
 )
 
@@ -156,21 +156,21 @@ func TestProcessableJobs(t *testing.T) {
 
 // Test after jobs have been processed, the job status are removed to prevent from memory-leak
 func TestProcessedIndexDeletion(t *testing.T) {
-	setup := func(t *testing.T, f func(c *Consumer, jobs *MockJobs)) {
-		unittest.RunWithBadgerDB(t, func(db *badgerdb.DB) {
+	setup := func(t *testing.T, f func(t *testing.T, c *Consumer, jobs *MockJobs)) {
+		dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
 			log := unittest.Logger().With().Str("module", "consumer").Logger()
 			jobs := NewMockJobs()
-			progress := badger.NewConsumerProgress(db, "consumer")
+			progress := store.NewConsumerProgress(db, "consumer")
 			worker := newMockWorker()
 			maxProcessing := uint64(3)
 			c := NewConsumer(log, jobs, progress, worker, maxProcessing, 0)
 			worker.WithConsumer(c)
 
-			f(c, jobs)
+			f(t, c, jobs)
 		})
 	}
 
-	setup(t, func(c *Consumer, jobs *MockJobs) {
+	setup(t, func(t *testing.T, c *Consumer, jobs *MockJobs) {
 		require.NoError(t, jobs.PushN(10))
 		require.NoError(t, c.Start(0))
 
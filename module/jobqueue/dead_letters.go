@@ -0,0 +1,168 @@
+package jobqueue
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/storage"
+)
+
+// ErrConsumerNotRunning is returned when retrying a dead-lettered job of a consumer which is not running.
+var ErrConsumerNotRunning = errors.New("consumer is not running")
+
+// DeadLetterQueue gives access to the jobs a consumer gave up on after they exhausted their attempts.
+type DeadLetterQueue interface {
+	// DeadLetters returns all dead-lettered jobs, ordered by index.
+	// No errors are expected during normal operation.
+	DeadLetters() ([]*storage.DeadLetteredJob, error)
+
+	// RetryDeadLetter processes the dead-lettered job at the given index once more. The job is removed
+	// from the dead-lettered jobs once it is done.
+	// Expected errors during normal operation:
+	//   - storage.ErrNotFound if there is no dead-lettered job at the index
+	//   - ErrConsumerNotRunning if the consumer is not running
+	RetryDeadLetter(index uint64) error
+
+	// SkipDeadLetter removes the dead-lettered job at the given index without processing it.
+	// Expected errors during normal operation:
+	//   - storage.ErrNotFound if there is no dead-lettered job at the index
+	SkipDeadLetter(index uint64) error
+}
+
+// WithDeadLetters configures the consumer to dead-letter jobs which exhausted their attempts in the
+// given store, so that the consumer can move past them. The consumer is registered in the given
+// registry under the given name, if the registry is not nil.
+// Jobs are only dead-lettered if the consumer is also configured with a job timeout.
+func WithDeadLetters(name string, deadLetters storage.JobDeadLetters, registry *DeadLetterQueues) ConsumerOption {
+	return func(c *Consumer) {
+		c.deadLetters = deadLetters
+		if registry != nil {
+			registry.Register(name, c)
+		}
+	}
+}
+
+var _ DeadLetterQueue = (*Consumer)(nil)
+
+// DeadLetters returns all dead-lettered jobs of the consumer, ordered by index.
+// No errors are expected during normal operation.
+func (c *Consumer) DeadLetters() ([]*storage.DeadLetteredJob, error) {
+	if c.deadLetters == nil {
+		return nil, nil
+	}
+	return c.deadLetters.All()
+}
+
+// RetryDeadLetter processes the dead-lettered job at the given index once more. The attempt is not
+// subject to the job timeout, and the job is removed from the dead-lettered jobs once it is done.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if there is no dead-lettered job at the index
+//   - ErrConsumerNotRunning if the consumer is not running
+func (c *Consumer) RetryDeadLetter(index uint64) error {
+	if c.deadLetters == nil {
+		return fmt.Errorf("no dead-lettered job at index %d: %w", index, storage.ErrNotFound)
+	}
+	_, err := c.deadLetters.ByIndex(index)
+	if err != nil {
+		return err
+	}
+	job, err := c.jobs.AtIndex(index)
+	if err != nil {
+		return fmt.Errorf("could not read dead-lettered job at index %d: %w", index, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running {
+		return ErrConsumerNotRunning
+	}
+
+	c.log.Info().Str("job_id", string(job.ID())).Uint64("index", index).Msg("retrying dead-lettered job")
+	c.retrying[job.ID()] = index
+	c.runJob(job)
+	return nil
+}
+
+// SkipDeadLetter removes the dead-lettered job at the given index without processing it.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if there is no dead-lettered job at the index
+func (c *Consumer) SkipDeadLetter(index uint64) error {
+	if c.deadLetters == nil {
+		return fmt.Errorf("no dead-lettered job at index %d: %w", index, storage.ErrNotFound)
+	}
+	err := c.deadLetters.Remove(index)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for jobID, retryingIndex := range c.retrying {
+		if retryingIndex == index {
+			delete(c.retrying, jobID)
+		}
+	}
+
+	c.log.Warn().Uint64("index", index).Msg("skipped dead-lettered job")
+	return nil
+}
+
+// doneDeadLetter removes a retried dead-lettered job once it is done. Must be called while holding the lock.
+func (c *Consumer) doneDeadLetter(jobID module.JobID, index uint64) {
+	delete(c.retrying, jobID)
+
+	err := c.deadLetters.Remove(index)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		c.log.Error().Err(err).Uint64("index", index).Msg("could not remove retried dead-lettered job")
+		return
+	}
+	c.log.Info().Str("job_id", string(jobID)).Uint64("index", index).Msg("retried dead-lettered job is done")
+}
+
+// DeadLetterQueues is a registry of the job consumers of a node which dead-letter jobs, so that
+// operators can inspect, retry and skip dead-lettered jobs through the admin commands.
+type DeadLetterQueues struct {
+	mu     sync.RWMutex
+	queues map[string]DeadLetterQueue
+}
+
+func NewDeadLetterQueues() *DeadLetterQueues {
+	return &DeadLetterQueues{
+		queues: make(map[string]DeadLetterQueue),
+	}
+}
+
+// Register adds the dead-letter queue of a consumer under the given name.
+func (q *DeadLetterQueues) Register(name string, queue DeadLetterQueue) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.queues[name] = queue
+}
+
+// ByName returns the dead-letter queue registered under the given name.
+func (q *DeadLetterQueues) ByName(name string) (DeadLetterQueue, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	queue, ok := q.queues[name]
+	return queue, ok
+}
+
+// Names returns the names of all registered dead-letter queues, in sorted order.
+func (q *DeadLetterQueues) Names() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	names := make([]string, 0, len(q.queues))
+	for name := range q.queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
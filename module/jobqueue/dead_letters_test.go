@@ -0,0 +1,131 @@
+package jobqueue
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// stallingWorker completes all jobs immediately, except for stalled jobs which are never completed.
+type stallingWorker struct {
+	consumer *Consumer
+	mu       sync.Mutex
+	stalled  map[module.JobID]bool
+	runs     map[module.JobID]int
+}
+
+func (w *stallingWorker) Run(job module.Job) error {
+	w.mu.Lock()
+	w.runs[job.ID()]++
+	stalled := w.stalled[job.ID()]
+	w.mu.Unlock()
+
+	if !stalled {
+		w.consumer.NotifyJobIsDone(job.ID())
+	}
+	return nil
+}
+
+func (w *stallingWorker) setStalled(jobID module.JobID, stalled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stalled[jobID] = stalled
+}
+
+func (w *stallingWorker) runCount(jobID module.JobID) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.runs[jobID]
+}
+
+func TestDeadLetters(t *testing.T) {
+	setup := func(t *testing.T, f func(c *Consumer, worker *stallingWorker, registry *DeadLetterQueues)) {
+		unittest.RunWithBadgerDB(t, func(db *badgerdb.DB) {
+			jobs := NewMockJobs()
+			require.NoError(t, jobs.PushN(10))
+
+			worker := &stallingWorker{
+				stalled: map[module.JobID]bool{JobIDAtIndex(3): true},
+				runs:    make(map[module.JobID]int),
+			}
+			registry := NewDeadLetterQueues()
+			c := NewConsumer(unittest.Logger(), jobs, badger.NewConsumerProgress(db, "consumer"), worker, 3, 0,
+				WithJobTimeout(JobTimeoutConfig{
+					Timeout:       20 * time.Millisecond,
+					MaxAttempts:   2,
+					RetryDelay:    10 * time.Millisecond,
+					MaxRetryDelay: 10 * time.Millisecond,
+				}),
+				WithDeadLetters("consumer", badger.NewJobDeadLetters(db, "consumer"), registry),
+			)
+			worker.consumer = c
+
+			require.NoError(t, c.Start(0))
+			defer c.Stop()
+
+			// the stalled job is dead-lettered after its attempts, and the consumer moves past it
+			require.Eventually(t, func() bool {
+				return c.LastProcessedIndex() == 10
+			}, 2*time.Second, 10*time.Millisecond)
+			assert.Equal(t, 2, worker.runCount(JobIDAtIndex(3)))
+
+			f(c, worker, registry)
+		})
+	}
+
+	t.Run("dead-lettered job", func(t *testing.T) {
+		setup(t, func(c *Consumer, worker *stallingWorker, registry *DeadLetterQueues) {
+			queue, ok := registry.ByName("consumer")
+			require.True(t, ok)
+			assert.Equal(t, []string{"consumer"}, registry.Names())
+
+			deadLetters, err := queue.DeadLetters()
+			require.NoError(t, err)
+			require.Len(t, deadLetters, 1)
+			assert.Equal(t, uint64(3), deadLetters[0].Index)
+			assert.Equal(t, string(JobIDAtIndex(3)), deadLetters[0].JobID)
+			assert.Equal(t, uint(2), deadLetters[0].Attempts)
+		})
+	})
+
+	t.Run("retry dead-lettered job", func(t *testing.T) {
+		setup(t, func(c *Consumer, worker *stallingWorker, registry *DeadLetterQueues) {
+			err := c.RetryDeadLetter(4)
+			assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+			worker.setStalled(JobIDAtIndex(3), false)
+			require.NoError(t, c.RetryDeadLetter(3))
+
+			require.Eventually(t, func() bool {
+				deadLetters, err := c.DeadLetters()
+				require.NoError(t, err)
+				return len(deadLetters) == 0
+			}, time.Second, 10*time.Millisecond)
+			assert.Equal(t, 3, worker.runCount(JobIDAtIndex(3)))
+		})
+	})
+
+	t.Run("skip dead-lettered job", func(t *testing.T) {
+		setup(t, func(c *Consumer, worker *stallingWorker, registry *DeadLetterQueues) {
+			require.NoError(t, c.SkipDeadLetter(3))
+
+			deadLetters, err := c.DeadLetters()
+			require.NoError(t, err)
+			assert.Empty(t, deadLetters)
+
+			err = c.SkipDeadLetter(3)
+			assert.True(t, errors.Is(err, storage.ErrNotFound))
+			assert.Equal(t, 2, worker.runCount(JobIDAtIndex(3)))
+		})
+	})
+}
@@ -1,6 +1,9 @@
 package jobqueue
 
 import (
+	"context"
+	"sync"
+
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/module/component"
 	"github.com/onflow/flow-go/module/irrecoverable"
@@ -16,8 +19,13 @@ type WorkerPool struct {
 	processor JobProcessor
 	notify    NotifyDone
 	ch        chan module.Job
+
+	mu      sync.Mutex
+	cancels map[module.JobID]*context.CancelFunc // cancel functions of the jobs being processed
 }
 
+var _ CancelableWorker = (*WorkerPool)(nil)
+
 // JobProcessor is called by the worker to execute each job. It should only return when the job has
 // completed, either successfully or after performing any failure handling.
 // It takes 3 arguments:
@@ -42,6 +50,7 @@ func NewWorkerPool(processor JobProcessor, notify NotifyDone, workers uint64) *W
 		processor: processor,
 		notify:    notify,
 		ch:        make(chan module.Job),
+		cancels:   make(map[module.JobID]*context.CancelFunc),
 	}
 
 	builder := component.NewComponentManagerBuilder()
@@ -84,9 +93,43 @@ func (w *WorkerPool) workerLoop(ctx irrecoverable.SignalerContext) {
 		case <-ctx.Done():
 			return
 		case job := <-w.ch:
-			w.processor(ctx, job, func() {
-				w.notify(job.ID())
-			})
+			w.process(ctx, job)
 		}
 	}
 }
+
+// process runs the processor for the given job with a context which is canceled when the job is canceled.
+func (w *WorkerPool) process(ctx irrecoverable.SignalerContext, job module.Job) {
+	jobCtx, cancel := irrecoverable.WithCancel(ctx)
+	defer cancel()
+
+	jobID := job.ID()
+	w.mu.Lock()
+	w.cancels[jobID] = &cancel
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		// the job may have been retried by another worker after it was canceled
+		if w.cancels[jobID] == &cancel {
+			delete(w.cancels, jobID)
+		}
+		w.mu.Unlock()
+	}()
+
+	w.processor(jobCtx, job, func() {
+		w.notify(jobID)
+	})
+}
+
+// Cancel cancels the context of the given job, if it is being processed. The processor is expected to
+// return once the context is canceled.
+func (w *WorkerPool) Cancel(jobID module.JobID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if cancel, ok := w.cancels[jobID]; ok {
+		(*cancel)()
+		delete(w.cancels, jobID)
+	}
+}
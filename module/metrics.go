@@ -520,6 +520,13 @@ type ExecutionDataRequesterMetrics interface {
 
 	// FetchRetried reports that a download retry was processed
 	FetchRetried()
+
+	// CatchUpWorkers reports the number of ranges downloaded in parallel in catch-up mode
+	CatchUpWorkers(workers int)
+
+	// CatchUpHeightFetched reports that the execution data for a height was prefetched in catch-up
+	// mode, either from the network or from the local archive
+	CatchUpHeightFetched(fromArchive bool)
 }
 
 type RuntimeMetrics interface {
@@ -592,6 +599,17 @@ type AccessMetrics interface {
 	ConnectionFromPoolEvicted()
 }
 
+// AccessAPIKeyMetrics tracks the requests made to the Access API on behalf of each API key.
+type AccessAPIKeyMetrics interface {
+	// APIKeyRequestAccepted tracks a request made with the given API key that was admitted
+	// against the quota of the given method class.
+	APIKeyRequestAccepted(keyName string, methodClass string)
+
+	// APIKeyRequestRejected tracks a request made with the given API key that was rejected,
+	// e.g. because the key is unknown or the quota of the method class is exhausted.
+	APIKeyRequestRejected(keyName string, methodClass string, reason string)
+}
+
 type ExecutionResultStats struct {
 	ComputationUsed                 uint64
 	MemoryUsed                      uint64
@@ -778,6 +796,31 @@ type ChainSyncMetrics interface {
 	BatchRequested(batch chainsync.Batch)
 }
 
+// HeaderFirstSyncMetrics shows where the time of header-first chain synchronization goes:
+// fetching and validating header chains, downloading payloads, and waiting for the payloads of
+// lower blocks before forwarding blocks in height order.
+type HeaderFirstSyncMetrics interface {
+	// HeadersReceived is called when a chain of headers is received, with the time since it was requested.
+	HeadersReceived(count int, latency time.Duration)
+
+	// HeadersValidated is called after validating a chain of headers, with the number of headers
+	// certified by valid QCs and the time spent validating.
+	HeadersValidated(count int, duration time.Duration)
+
+	// BodyReceived is called when the payload of a block with a validated header is received, with the
+	// time between validating the header and first requesting the payload, and the time between
+	// first requesting and receiving the payload.
+	BodyReceived(queued, download time.Duration)
+
+	// BlockForwarded is called when a block is forwarded to the compliance engine, with the time it
+	// waited for the payloads of lower blocks.
+	BlockForwarded(waited time.Duration)
+
+	// HeaderFirstProgress reports the local finalized height, the height of the highest validated
+	// header, and the height of the highest block forwarded to the compliance engine.
+	HeaderFirstProgress(finalized, validated, forwarded uint64)
+}
+
 type DHTMetrics interface {
 	RoutingTablePeerAdded()
 	RoutingTablePeerRemoved()
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/onflow/flow-go/module"
+)
+
+type AccessAPIKeyCollector struct {
+	requestsAccepted *prometheus.CounterVec
+	requestsRejected *prometheus.CounterVec
+}
+
+var _ module.AccessAPIKeyMetrics = (*AccessAPIKeyCollector)(nil)
+
+func NewAccessAPIKeyCollector() *AccessAPIKeyCollector {
+	return &AccessAPIKeyCollector{
+		requestsAccepted: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name:      "requests_accepted_total",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemAPIKey,
+			Help:      "counter for the number of requests admitted per API key and method class",
+		}, []string{"key", "class"}),
+		requestsRejected: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name:      "requests_rejected_total",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemAPIKey,
+			Help:      "counter for the number of requests rejected per API key, method class and rejection reason",
+		}, []string{"key", "class", "reason"}),
+	}
+}
+
+func (ac *AccessAPIKeyCollector) APIKeyRequestAccepted(keyName string, methodClass string) {
+	ac.requestsAccepted.WithLabelValues(keyName, methodClass).Inc()
+}
+
+func (ac *AccessAPIKeyCollector) APIKeyRequestRejected(keyName string, methodClass string, reason string) {
+	ac.requestsRejected.WithLabelValues(keyName, methodClass, reason).Inc()
+}
@@ -20,6 +20,9 @@ type ExecutionDataRequesterCollector struct {
 
 	downloadRetries prometheus.Counter
 	failedDownloads prometheus.Counter
+
+	catchUpWorkers        prometheus.Gauge
+	catchUpHeightsFetched *prometheus.CounterVec
 }
 
 func NewExecutionDataRequesterCollector() module.ExecutionDataRequesterMetrics {
@@ -74,6 +77,20 @@ func NewExecutionDataRequesterCollector() module.ExecutionDataRequesterMetrics {
 		Help:      "number of failed execution data downloads",
 	})
 
+	catchUpWorkers := promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespaceStateSync,
+		Subsystem: subsystemExecutionDataRequester,
+		Name:      "execution_requester_catch_up_workers",
+		Help:      "number of height ranges downloaded in parallel in catch-up mode",
+	})
+
+	catchUpHeightsFetched := promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespaceStateSync,
+		Subsystem: subsystemExecutionDataRequester,
+		Name:      "execution_requester_catch_up_heights_total",
+		Help:      "number of heights prefetched in catch-up mode, by source",
+	}, []string{LabelSource})
+
 	return &ExecutionDataRequesterCollector{
 		fetchDuration:             fetchDuration,
 		downloadsInProgress:       downloadsInProgress,
@@ -82,6 +99,8 @@ func NewExecutionDataRequesterCollector() module.ExecutionDataRequesterMetrics {
 		highestNotificationHeight: highestNotificationHeight,
 		downloadRetries:           downloadRetries,
 		failedDownloads:           failedDownloads,
+		catchUpWorkers:            catchUpWorkers,
+		catchUpHeightsFetched:     catchUpHeightsFetched,
 	}
 }
 
@@ -108,3 +127,15 @@ func (ec *ExecutionDataRequesterCollector) NotificationSent(height uint64) {
 func (ec *ExecutionDataRequesterCollector) FetchRetried() {
 	ec.downloadRetries.Inc()
 }
+
+func (ec *ExecutionDataRequesterCollector) CatchUpWorkers(workers int) {
+	ec.catchUpWorkers.Set(float64(workers))
+}
+
+func (ec *ExecutionDataRequesterCollector) CatchUpHeightFetched(fromArchive bool) {
+	source := "network"
+	if fromArchive {
+		source = "archive"
+	}
+	ec.catchUpHeightsFetched.WithLabelValues(source).Inc()
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/onflow/flow-go/module"
+)
+
+type HeaderFirstSyncCollector struct {
+	headerFetchDuration      prometheus.Histogram
+	headersReceived          prometheus.Counter
+	headerValidationDuration prometheus.Histogram
+	headersValidated         prometheus.Counter
+
+	bodyQueuedDuration   prometheus.Histogram
+	bodyDownloadDuration prometheus.Histogram
+	forwardWaitDuration  prometheus.Histogram
+
+	finalizedHeight prometheus.Gauge
+	validatedHeight prometheus.Gauge
+	forwardedHeight prometheus.Gauge
+}
+
+var _ module.HeaderFirstSyncMetrics = (*HeaderFirstSyncCollector)(nil)
+
+func NewHeaderFirstSyncCollector() *HeaderFirstSyncCollector {
+	return &HeaderFirstSyncCollector{
+		headerFetchDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespaceChainsync,
+			Subsystem: subsystemHeaderFirst,
+			Name:      "header_fetch_duration_seconds",
+			Help:      "the time between requesting a chain of headers and receiving it",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10},
+		}),
+		headersReceived: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: namespaceChainsync,
+			Subsystem: subsystemHeaderFirst,
+			Name:      "headers_received_total",
+			Help:      "the total number of headers received in response to header range requests",
+		}),
+		headerValidationDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespaceChainsync,
+			Subsystem: subsystemHeaderFirst,
+			Name:      "header_validation_duration_seconds",
+			Help:      "the time spent validating a received chain of headers and their QCs",
+			Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5},
+		}),
+		headersValidated: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: namespaceChainsync,
+			Subsystem: subsystemHeaderFirst,
+			Name:      "headers_validated_total",
+			Help:      "the total number of headers certified by a valid QC",
+		}),
+		bodyQueuedDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespaceChainsync,
+			Subsystem: subsystemHeaderFirst,
+			Name:      "body_queued_duration_seconds",
+			Help:      "the time between validating the header of a block and first requesting its payload",
+			Buckets:   []float64{.1, .25, .5, 1, 2.5, 5, 10, 20, 60},
+		}),
+		bodyDownloadDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespaceChainsync,
+			Subsystem: subsystemHeaderFirst,
+			Name:      "body_download_duration_seconds",
+			Help:      "the time between first requesting the payload of a block and receiving it, including retries",
+			Buckets:   []float64{.1, .25, .5, 1, 2.5, 5, 10, 20, 60},
+		}),
+		forwardWaitDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespaceChainsync,
+			Subsystem: subsystemHeaderFirst,
+			Name:      "forward_wait_duration_seconds",
+			Help:      "the time a downloaded block waits for the payloads of lower blocks before it is forwarded to the compliance engine",
+			Buckets:   []float64{.01, .1, .25, .5, 1, 2.5, 5, 10, 20},
+		}),
+		finalizedHeight: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespaceChainsync,
+			Subsystem: subsystemHeaderFirst,
+			Name:      "finalized_height",
+			Help:      "the local finalized height",
+		}),
+		validatedHeight: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespaceChainsync,
+			Subsystem: subsystemHeaderFirst,
+			Name:      "validated_height",
+			Help:      "the height of the highest validated header",
+		}),
+		forwardedHeight: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespaceChainsync,
+			Subsystem: subsystemHeaderFirst,
+			Name:      "forwarded_height",
+			Help:      "the height of the highest block forwarded to the compliance engine",
+		}),
+	}
+}
+
+func (c *HeaderFirstSyncCollector) HeadersReceived(count int, latency time.Duration) {
+	c.headersReceived.Add(float64(count))
+	c.headerFetchDuration.Observe(latency.Seconds())
+}
+
+func (c *HeaderFirstSyncCollector) HeadersValidated(count int, duration time.Duration) {
+	c.headersValidated.Add(float64(count))
+	c.headerValidationDuration.Observe(duration.Seconds())
+}
+
+func (c *HeaderFirstSyncCollector) BodyReceived(queued, download time.Duration) {
+	c.bodyQueuedDuration.Observe(queued.Seconds())
+	c.bodyDownloadDuration.Observe(download.Seconds())
+}
+
+func (c *HeaderFirstSyncCollector) BlockForwarded(waited time.Duration) {
+	c.forwardWaitDuration.Observe(waited.Seconds())
+}
+
+func (c *HeaderFirstSyncCollector) HeaderFirstProgress(finalized, validated, forwarded uint64) {
+	c.finalizedHeight.Set(float64(finalized))
+	c.validatedHeight.Set(float64(validated))
+	c.forwardedHeight.Set(float64(forwarded))
+}
@@ -19,6 +19,7 @@ const (
 	LabelConnectionUseFD     = "usefd" // whether the connection is using a file descriptor
 	LabelSuccess             = "success"
 	LabelMisbehavior         = "misbehavior"
+	LabelSource              = "source"
 )
 
 const (
@@ -125,6 +126,8 @@ const (
 	MessageRangeRequest        = "range"
 	MessageBatchRequest        = "batch"
 	MessageBlockResponse       = "block"
+	MessageHeaderRangeRequest  = "header_range"
+	MessageHeaderResponse      = "header"
 	MessageSyncedBlocks        = "synced_blocks"
 	MessageSyncedClusterBlock  = "synced_cluster_block"
 	MessageTransaction         = "transaction"
@@ -42,6 +42,7 @@ const (
 	subsystemTransactionTiming     = "transaction_timing"
 	subsystemTransactionSubmission = "transaction_submission"
 	subsystemConnectionPool        = "connection_pool"
+	subsystemAPIKey                = "api_key"
 )
 
 // Observer subsystem
@@ -92,7 +93,8 @@ const (
 
 // module/synchronization core
 const (
-	subsystemSyncCore = "sync_core"
+	subsystemSyncCore    = "sync_core"
+	subsystemHeaderFirst = "header_first"
 )
 
 // METRIC NAMING GUIDELINES
@@ -152,6 +152,8 @@ func (nc *NoopCollector) NewConnectionEstablished()
 func (nc *NoopCollector) ConnectionFromPoolInvalidated()                                        {}
 func (nc *NoopCollector) ConnectionFromPoolUpdated()                                            {}
 func (nc *NoopCollector) ConnectionFromPoolEvicted()                                            {}
+func (nc *NoopCollector) APIKeyRequestAccepted(string, string)                                  {}
+func (nc *NoopCollector) APIKeyRequestRejected(string, string, string)                          {}
 func (nc *NoopCollector) StartBlockReceivedToExecuted(blockID flow.Identifier)                  {}
 func (nc *NoopCollector) FinishBlockReceivedToExecuted(blockID flow.Identifier)                 {}
 func (nc *NoopCollector) ExecutionComputationUsedPerBlock(computation uint64)                   {}
@@ -231,6 +233,8 @@ func (nc *NoopCollector) ExecutionDataFetchStarted()
 func (nc *NoopCollector) ExecutionDataFetchFinished(_ time.Duration, _ bool, _ uint64)          {}
 func (nc *NoopCollector) NotificationSent(height uint64)                                        {}
 func (nc *NoopCollector) FetchRetried()                                                         {}
+func (nc *NoopCollector) CatchUpWorkers(workers int)                                            {}
+func (nc *NoopCollector) CatchUpHeightFetched(fromArchive bool)                                 {}
 func (nc *NoopCollector) RoutingTablePeerAdded()                                                {}
 func (nc *NoopCollector) RoutingTablePeerRemoved()                                              {}
 func (nc *NoopCollector) PrunedBlockById(status *chainsync.Status)                              {}
@@ -238,6 +242,11 @@ func (nc *NoopCollector) PrunedBlockByHeight(status *chainsync.Status)
 func (nc *NoopCollector) PrunedBlocks(totalByHeight, totalById, storedByHeight, storedById int) {}
 func (nc *NoopCollector) RangeRequested(ran chainsync.Range)                                    {}
 func (nc *NoopCollector) BatchRequested(batch chainsync.Batch)                                  {}
+func (nc *NoopCollector) HeadersReceived(count int, latency time.Duration)                      {}
+func (nc *NoopCollector) HeadersValidated(count int, duration time.Duration)                    {}
+func (nc *NoopCollector) BodyReceived(queued, download time.Duration)                           {}
+func (nc *NoopCollector) BlockForwarded(waited time.Duration)                                   {}
+func (nc *NoopCollector) HeaderFirstProgress(finalized, validated, forwarded uint64)            {}
 func (nc *NoopCollector) OnUnauthorizedMessage(role, msgType, topic, offense string)            {}
 func (nc *NoopCollector) ObserveHTTPRequestDuration(context.Context, httpmetrics.HTTPReqProperties, time.Duration) {
 }
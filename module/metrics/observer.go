@@ -7,7 +7,8 @@ import (
 )
 
 type ObserverCollector struct {
-	rpcs *prometheus.CounterVec
+	rpcs   *prometheus.CounterVec
+	routes *prometheus.CounterVec
 }
 
 func NewObserverCollector() *ObserverCollector {
@@ -18,6 +19,12 @@ func NewObserverCollector() *ObserverCollector {
 			Name:      "handler_grpc_counter",
 			Help:      "tracking error/success rate of each rpc for the observer service",
 		}, []string{"handler", "grpc_method", "grpc_code"}),
+		routes: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespaceObserver,
+			Subsystem: subsystemObserverGRPC,
+			Name:      "route_decision_counter",
+			Help:      "tracking whether each rpc was served locally, forwarded upstream, or forwarded upstream after the local data was missing",
+		}, []string{"grpc_method", "route"}),
 	}
 }
 
@@ -28,3 +35,11 @@ func (oc *ObserverCollector) RecordRPC(handler, rpc string, code codes.Code) {
 		"grpc_code":   code.String(),
 	}).Inc()
 }
+
+// RecordRouteDecision records where a request of the given rpc was routed to.
+func (oc *ObserverCollector) RecordRouteDecision(rpc, route string) {
+	oc.routes.With(prometheus.Labels{
+		"grpc_method": rpc,
+		"route":       route,
+	}).Inc()
+}
@@ -13,6 +13,16 @@ type ExecutionDataRequesterMetrics struct {
 	mock.Mock
 }
 
+// CatchUpHeightFetched provides a mock function with given fields: fromArchive
+func (_m *ExecutionDataRequesterMetrics) CatchUpHeightFetched(fromArchive bool) {
+	_m.Called(fromArchive)
+}
+
+// CatchUpWorkers provides a mock function with given fields: workers
+func (_m *ExecutionDataRequesterMetrics) CatchUpWorkers(workers int) {
+	_m.Called(workers)
+}
+
 // ExecutionDataFetchFinished provides a mock function with given fields: duration, success, height
 func (_m *ExecutionDataRequesterMetrics) ExecutionDataFetchFinished(duration time.Duration, success bool, height uint64) {
 	_m.Called(duration, success, height)
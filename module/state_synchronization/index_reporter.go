@@ -0,0 +1,14 @@
+package state_synchronization
+
+// IndexReporter reports the range of heights for which data derived from execution data has been
+// indexed into the local storage.
+type IndexReporter interface {
+	// LowestIndexedHeight returns the lowest height indexed.
+	// No errors are expected during normal operation.
+	LowestIndexedHeight() (uint64, error)
+
+	// HighestIndexedHeight returns the highest height indexed. All heights between LowestIndexedHeight
+	// and HighestIndexedHeight are indexed, the range is empty if it is lower than LowestIndexedHeight.
+	// No errors are expected during normal operation.
+	HighestIndexedHeight() (uint64, error)
+}
@@ -0,0 +1,92 @@
+package indexer
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/module/state_synchronization/requester/jobs"
+	"github.com/onflow/flow-go/storage"
+)
+
+// executionDataReader provides the execution data of sealed blocks as jobs indexed by height.
+// In contrast to jobs.ExecutionDataReader, it reads the execution data from the local execution
+// data store only, since the indexer only processes heights which have already been downloaded.
+type executionDataReader struct {
+	store   execution_data.ExecutionDataStore
+	headers storage.Headers
+	results storage.ExecutionResults
+	seals   storage.Seals
+
+	highestAvailableHeight func() uint64
+
+	ctx irrecoverable.SignalerContext
+}
+
+var _ module.Jobs = (*executionDataReader)(nil)
+
+func newExecutionDataReader(
+	store execution_data.ExecutionDataStore,
+	headers storage.Headers,
+	results storage.ExecutionResults,
+	seals storage.Seals,
+	highestAvailableHeight func() uint64,
+) *executionDataReader {
+	return &executionDataReader{
+		store:                  store,
+		headers:                headers,
+		results:                results,
+		seals:                  seals,
+		highestAvailableHeight: highestAvailableHeight,
+	}
+}
+
+// AddContext adds the context used to read execution data from the store.
+func (r *executionDataReader) AddContext(ctx irrecoverable.SignalerContext) {
+	r.ctx = ctx
+}
+
+// AtIndex returns the block entry job at the given height.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the execution data at the given height has not been downloaded yet
+func (r *executionDataReader) AtIndex(height uint64) (module.Job, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("execution data reader is not initialized")
+	}
+
+	if height > r.highestAvailableHeight() {
+		return nil, storage.ErrNotFound
+	}
+
+	header, err := r.headers.ByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get header for height %d: %w", height, err)
+	}
+
+	seal, err := r.seals.FinalizedSealForBlock(header.ID())
+	if err != nil {
+		return nil, fmt.Errorf("could not get seal for block %v: %w", header.ID(), err)
+	}
+
+	result, err := r.results.ByID(seal.ResultID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get execution result for block %v: %w", header.ID(), err)
+	}
+
+	executionData, err := r.store.GetExecutionData(r.ctx, result.ExecutionDataID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get execution data for block %v: %w", header.ID(), err)
+	}
+
+	return jobs.BlockEntryToJob(&jobs.BlockEntry{
+		BlockID:       header.ID(),
+		Height:        height,
+		ExecutionData: execution_data.NewBlockExecutionDataEntity(result.ExecutionDataID, executionData),
+	}), nil
+}
+
+// Head returns the highest height for which execution data has been downloaded.
+func (r *executionDataReader) Head() (uint64, error) {
+	return r.highestAvailableHeight(), nil
+}
@@ -0,0 +1,160 @@
+package indexer
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/atomic"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/module/jobqueue"
+	"github.com/onflow/flow-go/module/state_synchronization"
+	"github.com/onflow/flow-go/module/state_synchronization/requester/jobs"
+	"github.com/onflow/flow-go/module/util"
+	"github.com/onflow/flow-go/storage"
+)
+
+// workersCount is the number of workers indexing execution data. A single worker is used so that
+// blocks are indexed in consecutive height order.
+const workersCount = 1
+
+// searchAhead is the number of heights the job consumer looks ahead of the last indexed height.
+// It is ignored by the consumer when a single worker is used.
+const searchAhead = 1
+
+var _ state_synchronization.IndexReporter = (*Indexer)(nil)
+
+// Indexer indexes the execution data of sealed blocks into the local storage, in consecutive height
+// order, so that the events, transaction results and collections of indexed blocks can be served
+// without querying execution nodes.
+//
+// The Indexer is notified by the execution data requester through OnExecutionData whenever the
+// execution data of a block has been downloaded, and reads the execution data from the local
+// execution data store. The highest indexed height is persisted, so indexing resumes after a restart.
+type Indexer struct {
+	component.Component
+
+	log          zerolog.Logger
+	core         *IndexerCore
+	jobConsumer  *jobqueue.ComponentConsumer
+	notifier     engine.Notifier
+	headers      storage.Headers
+	lowestHeight uint64
+
+	// highestAvailableHeight is the highest height for which execution data has been downloaded
+	highestAvailableHeight *atomic.Uint64
+}
+
+// NewIndexer creates a new Indexer.
+//   - initHeight is the last height considered indexed when the indexer is started for the first time,
+//     i.e. indexing starts at initHeight + 1.
+//   - highestAvailableHeight is the highest height for which execution data is available in the
+//     execution data store when the indexer is created.
+func NewIndexer(
+	log zerolog.Logger,
+	initHeight uint64,
+	highestAvailableHeight uint64,
+	core *IndexerCore,
+	executionDataStore execution_data.ExecutionDataStore,
+	headers storage.Headers,
+	results storage.ExecutionResults,
+	seals storage.Seals,
+	processedHeight storage.ConsumerProgress,
+) *Indexer {
+	r := &Indexer{
+		log:                    log.With().Str("component", "execution_data_indexer").Logger(),
+		core:                   core,
+		notifier:               engine.NewNotifier(),
+		headers:                headers,
+		lowestHeight:           initHeight + 1,
+		highestAvailableHeight: atomic.NewUint64(highestAvailableHeight),
+	}
+
+	reader := newExecutionDataReader(executionDataStore, headers, results, seals, r.highestAvailableHeight.Load)
+
+	r.jobConsumer = jobqueue.NewComponentConsumer(
+		r.log,
+		r.notifier.Channel(),
+		processedHeight,
+		reader,
+		initHeight,
+		r.processExecutionData,
+		workersCount,
+		searchAhead,
+	)
+
+	r.Component = component.NewComponentManagerBuilder().
+		AddWorker(func(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+			reader.AddContext(ctx)
+			r.jobConsumer.Start(ctx)
+
+			err := util.WaitClosed(ctx, r.jobConsumer.Ready())
+			if err == nil {
+				ready()
+				// index the execution data already available
+				r.notifier.Notify()
+			}
+
+			<-r.jobConsumer.Done()
+		}).
+		Build()
+
+	return r
+}
+
+// OnExecutionData is notified by the execution data requester whenever the execution data of a
+// sealed block has been downloaded. Notifications are expected in consecutive height order.
+func (i *Indexer) OnExecutionData(executionData *execution_data.BlockExecutionDataEntity) {
+	header, err := i.headers.ByBlockID(executionData.BlockID)
+	if err != nil {
+		// the requester only notifies for sealed blocks, hence the header must be stored
+		i.log.Error().Err(err).
+			Hex("block_id", executionData.BlockID[:]).
+			Msg("could not get header of downloaded execution data")
+		return
+	}
+
+	// notifications may be repeated, never decrease the available height
+	for {
+		highest := i.highestAvailableHeight.Load()
+		if header.Height <= highest || i.highestAvailableHeight.CAS(highest, header.Height) {
+			break
+		}
+	}
+
+	i.notifier.Notify()
+}
+
+// LowestIndexedHeight returns the first height indexed by the indexer.
+func (i *Indexer) LowestIndexedHeight() (uint64, error) {
+	return i.lowestHeight, nil
+}
+
+// HighestIndexedHeight returns the highest height for which the execution data and all the
+// execution data of lower heights down to LowestIndexedHeight has been indexed. It is lower than
+// LowestIndexedHeight as long as nothing has been indexed.
+func (i *Indexer) HighestIndexedHeight() (uint64, error) {
+	return i.jobConsumer.LastProcessedIndex(), nil
+}
+
+// processExecutionData indexes the execution data of a job. Errors are irrecoverable, since indexing
+// must not skip any height.
+func (i *Indexer) processExecutionData(ctx irrecoverable.SignalerContext, job module.Job, done func()) {
+	entry, err := jobs.JobToBlockEntry(job)
+	if err != nil {
+		ctx.Throw(fmt.Errorf("could not convert job to block entry: %w", err))
+		return
+	}
+
+	err = i.core.IndexBlockData(entry.ExecutionData)
+	if err != nil {
+		ctx.Throw(fmt.Errorf("could not index execution data of block %v at height %d: %w", entry.BlockID, entry.Height, err))
+		return
+	}
+
+	done()
+}
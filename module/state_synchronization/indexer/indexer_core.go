@@ -0,0 +1,189 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+)
+
+// IndexerCore indexes the events, transaction results and collections contained in the execution
+// data of a block into the local storage, as well as the register updates if a register index is set.
+type IndexerCore struct {
+	log          zerolog.Logger
+	db           *badger.DB
+	headers      storage.Headers
+	events       storage.Events
+	results      storage.TransactionResults
+	collections  storage.Collections
+	transactions storage.Transactions
+	registers    storage.RegisterIndex // nil if registers are not indexed
+}
+
+// NewIndexerCore creates a new IndexerCore. The registers are optional, register updates are only
+// indexed if they are set. In that case, the registers must have been indexed up to the height
+// preceding the first block indexed.
+func NewIndexerCore(
+	log zerolog.Logger,
+	db *badger.DB,
+	headers storage.Headers,
+	events storage.Events,
+	results storage.TransactionResults,
+	collections storage.Collections,
+	transactions storage.Transactions,
+	registers storage.RegisterIndex,
+) *IndexerCore {
+	return &IndexerCore{
+		log:          log.With().Str("component", "execution_data_indexer_core").Logger(),
+		db:           db,
+		headers:      headers,
+		events:       events,
+		results:      results,
+		collections:  collections,
+		transactions: transactions,
+		registers:    registers,
+	}
+}
+
+// IndexBlockData indexes the events, transaction results, collections and register updates of the given execution data.
+// Indexing the same execution data multiple times is supported, the data is overwritten with identical values.
+//
+// Transaction results are only indexed if the execution data contains the results of all transactions.
+// Execution data produced before transaction results were included doesn't, in which case the results
+// remain available from execution nodes only.
+//
+// No errors are expected during normal operation.
+func (c *IndexerCore) IndexBlockData(data *execution_data.BlockExecutionDataEntity) error {
+	header, err := c.headers.ByBlockID(data.BlockID)
+	if err != nil {
+		return fmt.Errorf("could not get header for block %v: %w", data.BlockID, err)
+	}
+
+	log := c.log.With().
+		Hex("block_id", data.BlockID[:]).
+		Uint64("height", header.Height).
+		Logger()
+
+	events := make([]flow.EventsList, 0, len(data.ChunkExecutionDatas))
+	var results []flow.TransactionResult
+	resultsComplete := true
+	for _, chunk := range data.ChunkExecutionDatas {
+		events = append(events, chunk.Events)
+
+		txCount := 0
+		if chunk.Collection != nil {
+			txCount = len(chunk.Collection.Transactions)
+		}
+		if len(chunk.TransactionResults) != txCount {
+			resultsComplete = false
+		}
+		results = append(results, chunk.TransactionResults...)
+	}
+
+	batch := bstorage.NewBatch(c.db)
+
+	err = c.events.BatchStore(data.BlockID, events, batch)
+	if err != nil {
+		return fmt.Errorf("could not index events: %w", err)
+	}
+
+	if resultsComplete {
+		err = c.results.BatchStore(data.BlockID, results, batch)
+		if err != nil {
+			return fmt.Errorf("could not index transaction results: %w", err)
+		}
+	} else {
+		log.Debug().Msg("execution data does not contain all transaction results, skipping their indexing")
+	}
+
+	err = batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not commit batch: %w", err)
+	}
+
+	// the last chunk is the system chunk, whose collection isn't guaranteed and therefore not stored
+	for i := 0; i < len(data.ChunkExecutionDatas)-1; i++ {
+		collection := data.ChunkExecutionDatas[i].Collection
+		if collection == nil {
+			continue
+		}
+
+		err = c.indexCollection(collection)
+		if err != nil {
+			return fmt.Errorf("could not index collection %d: %w", i, err)
+		}
+	}
+
+	if c.registers != nil {
+		err = c.indexRegisters(data, header.Height)
+		if err != nil {
+			return fmt.Errorf("could not index registers: %w", err)
+		}
+	}
+
+	log.Debug().
+		Int("chunks", len(data.ChunkExecutionDatas)).
+		Int("transaction_results", len(results)).
+		Msg("indexed execution data")
+
+	return nil
+}
+
+// indexCollection stores the collection and its transactions. The transactions are stored first, so that
+// an interrupted indexing is completed when the collection is indexed again. Collections which have already
+// been stored, e.g. after they were received from a collection node, are skipped.
+// No errors are expected during normal operation.
+func (c *IndexerCore) indexCollection(collection *flow.Collection) error {
+	for _, tx := range collection.Transactions {
+		err := c.transactions.Store(tx)
+		if err != nil {
+			return fmt.Errorf("could not store transaction %v: %w", tx.ID(), err)
+		}
+	}
+
+	light := collection.Light()
+	err := c.collections.StoreLightAndIndexByTransaction(&light)
+	if err != nil && !errors.Is(err, storage.ErrAlreadyExists) {
+		return fmt.Errorf("could not store collection: %w", err)
+	}
+
+	return nil
+}
+
+// indexRegisters stores the values of the registers updated by the block at the given height. Registers
+// updated by multiple chunks take the value of the last update.
+// No errors are expected during normal operation.
+func (c *IndexerCore) indexRegisters(data *execution_data.BlockExecutionDataEntity, height uint64) error {
+	updates := make(map[flow.RegisterID]flow.RegisterValue)
+	for i, chunk := range data.ChunkExecutionDatas {
+		if chunk.TrieUpdate == nil {
+			continue
+		}
+
+		for _, payload := range chunk.TrieUpdate.Payloads {
+			key, err := payload.Key()
+			if err != nil {
+				return fmt.Errorf("could not get key of payload in chunk %d: %w", i, err)
+			}
+			id, err := state.KeyToRegisterID(key)
+			if err != nil {
+				return fmt.Errorf("could not convert key of payload in chunk %d: %w", i, err)
+			}
+			updates[id] = payload.Value()
+		}
+	}
+
+	entries := make(flow.RegisterEntries, 0, len(updates))
+	for id, value := range updates {
+		entries = append(entries, flow.RegisterEntry{Key: id, Value: value})
+	}
+
+	return c.registers.Store(entries, height)
+}
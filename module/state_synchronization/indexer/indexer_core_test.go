@@ -0,0 +1,245 @@
+package indexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/operation/badgerimpl"
+	"github.com/onflow/flow-go/storage/store"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// executionDataFixture returns the execution data of the given block with the given number of
+// collections, followed by a system chunk. Every transaction emits two events, and every second
+// transaction fails.
+func executionDataFixture(blockID flow.Identifier, collections int, withResults bool) *execution_data.BlockExecutionDataEntity {
+	chunks := make([]*execution_data.ChunkExecutionData, 0, collections+1)
+	txIndex := uint32(0)
+	for i := 0; i <= collections; i++ {
+		collection := unittest.CollectionFixture(2)
+
+		chunk := &execution_data.ChunkExecutionData{
+			Collection: &collection,
+		}
+		for _, tx := range collection.Transactions {
+			txID := tx.ID()
+			for eventIndex := uint32(0); eventIndex < 2; eventIndex++ {
+				eventType := flow.EventType(fmt.Sprintf("A.0x1.Test.Event%d", eventIndex))
+				chunk.Events = append(chunk.Events, unittest.EventFixture(eventType, txIndex, eventIndex, txID, 0))
+			}
+
+			if withResults {
+				result := flow.TransactionResult{
+					TransactionID:   txID,
+					ComputationUsed: uint64(txIndex),
+				}
+				if txIndex%2 == 1 {
+					result.ErrorMessage = "failed"
+				}
+				chunk.TransactionResults = append(chunk.TransactionResults, result)
+			}
+			txIndex++
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return execution_data.NewBlockExecutionDataEntity(unittest.IdentifierFixture(), &execution_data.BlockExecutionData{
+		BlockID:             blockID,
+		ChunkExecutionDatas: chunks,
+	})
+}
+
+type coreStorages struct {
+	headers      *bstorage.Headers
+	events       *bstorage.Events
+	results      *bstorage.TransactionResults
+	collections  *bstorage.Collections
+	transactions *bstorage.Transactions
+}
+
+func withIndexerCore(t *testing.T, f func(*IndexerCore, *coreStorages, *badger.DB)) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		collector := metrics.NewNoopCollector()
+		transactions := bstorage.NewTransactions(collector, db)
+		s := &coreStorages{
+			headers:      bstorage.NewHeaders(collector, db),
+			events:       bstorage.NewEvents(collector, db),
+			results:      bstorage.NewTransactionResults(collector, db, 100),
+			collections:  bstorage.NewCollections(db, transactions),
+			transactions: transactions,
+		}
+		core := NewIndexerCore(unittest.Logger(), db, s.headers, s.events, s.results, s.collections, s.transactions, nil)
+		f(core, s, db)
+	})
+}
+
+func TestIndexBlockData(t *testing.T) {
+	withIndexerCore(t, func(core *IndexerCore, s *coreStorages, _ *badger.DB) {
+		header := unittest.BlockHeaderFixture()
+		require.NoError(t, s.headers.Store(header))
+
+		data := executionDataFixture(header.ID(), 2, true)
+		require.NoError(t, core.IndexBlockData(data))
+
+		// indexing the same data again is a no-op
+		require.NoError(t, core.IndexBlockData(data))
+
+		// events
+		var expectedEvents []flow.Event
+		var expectedResults []flow.TransactionResult
+		for _, chunk := range data.ChunkExecutionDatas {
+			expectedEvents = append(expectedEvents, chunk.Events...)
+			expectedResults = append(expectedResults, chunk.TransactionResults...)
+		}
+		events, err := s.events.ByBlockID(header.ID())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, expectedEvents, events)
+
+		events, err = s.events.ByBlockIDEventType(header.ID(), "A.0x1.Test.Event1")
+		require.NoError(t, err)
+		assert.Len(t, events, len(expectedResults))
+
+		// transaction results, ordered by transaction index
+		results, err := s.results.ByBlockID(header.ID())
+		require.NoError(t, err)
+		assert.Equal(t, expectedResults, results)
+
+		result, err := s.results.ByBlockIDTransactionIndex(header.ID(), 3)
+		require.NoError(t, err)
+		assert.Equal(t, expectedResults[3], *result)
+
+		// collections, except the system collection
+		for i, chunk := range data.ChunkExecutionDatas {
+			light, err := s.collections.LightByID(chunk.Collection.ID())
+			if i == len(data.ChunkExecutionDatas)-1 {
+				assert.ErrorIs(t, err, storage.ErrNotFound)
+				continue
+			}
+			require.NoError(t, err)
+			assert.Equal(t, chunk.Collection.Light(), *light)
+
+			for _, tx := range chunk.Collection.Transactions {
+				stored, err := s.transactions.ByID(tx.ID())
+				require.NoError(t, err)
+				assert.Equal(t, tx.ID(), stored.ID())
+
+				byTx, err := s.collections.LightByTransactionID(tx.ID())
+				require.NoError(t, err)
+				assert.Equal(t, chunk.Collection.ID(), byTx.ID())
+			}
+		}
+	})
+}
+
+// TestIndexBlockData_WithoutTransactionResults verifies that execution data without transaction
+// results is indexed, except for the transaction results.
+func TestIndexBlockData_WithoutTransactionResults(t *testing.T) {
+	withIndexerCore(t, func(core *IndexerCore, s *coreStorages, _ *badger.DB) {
+		header := unittest.BlockHeaderFixture()
+		require.NoError(t, s.headers.Store(header))
+
+		data := executionDataFixture(header.ID(), 1, false)
+		require.NoError(t, core.IndexBlockData(data))
+
+		events, err := s.events.ByBlockID(header.ID())
+		require.NoError(t, err)
+		assert.Len(t, events, 8)
+
+		results, err := s.results.ByBlockID(header.ID())
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		_, err = s.results.ByBlockIDTransactionIndex(header.ID(), 0)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+// TestIndexBlockData_CollectionAlreadyStored verifies that collections which have already been
+// received from collection nodes are skipped.
+func TestIndexBlockData_CollectionAlreadyStored(t *testing.T) {
+	withIndexerCore(t, func(core *IndexerCore, s *coreStorages, _ *badger.DB) {
+		header := unittest.BlockHeaderFixture()
+		require.NoError(t, s.headers.Store(header))
+
+		data := executionDataFixture(header.ID(), 1, true)
+		collection := data.ChunkExecutionDatas[0].Collection
+		light := collection.Light()
+		require.NoError(t, s.collections.StoreLightAndIndexByTransaction(&light))
+
+		require.NoError(t, core.IndexBlockData(data))
+
+		stored, err := s.collections.ByID(collection.ID())
+		require.NoError(t, err)
+		assert.Equal(t, collection.ID(), stored.ID())
+	})
+}
+
+func TestIndexBlockData_UnknownBlock(t *testing.T) {
+	withIndexerCore(t, func(core *IndexerCore, s *coreStorages, _ *badger.DB) {
+		data := executionDataFixture(unittest.IdentifierFixture(), 1, true)
+		err := core.IndexBlockData(data)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+// TestIndexBlockData_Registers verifies that the register updates of all chunks are indexed at the
+// height of the block, and that later chunks take precedence.
+func TestIndexBlockData_Registers(t *testing.T) {
+	withIndexerCore(t, func(_ *IndexerCore, s *coreStorages, db *badger.DB) {
+		header := unittest.BlockHeaderFixture()
+		require.NoError(t, s.headers.Store(header))
+
+		registersDB := badgerimpl.ToDB(db)
+		require.NoError(t, store.BootstrapRegisters(registersDB, header.Height-1, func() (flow.RegisterEntries, error) {
+			return nil, nil
+		}))
+		registers, err := store.NewRegisters(registersDB)
+		require.NoError(t, err)
+
+		core := NewIndexerCore(unittest.Logger(), db, s.headers, s.events, s.results, s.collections, s.transactions, registers)
+
+		owner := string(flow.HexToAddress("0x01").Bytes())
+		reg1 := flow.RegisterID{Owner: owner, Key: "a"}
+		reg2 := flow.RegisterID{Owner: "", Key: "b"}
+		trieUpdate := func(entries ...flow.RegisterEntry) *ledger.TrieUpdate {
+			update := &ledger.TrieUpdate{}
+			for _, entry := range entries {
+				update.Payloads = append(update.Payloads, ledger.NewPayload(state.RegisterIDToKey(entry.Key), entry.Value))
+			}
+			return update
+		}
+
+		data := executionDataFixture(header.ID(), 1, true)
+		data.ChunkExecutionDatas[0].TrieUpdate = trieUpdate(
+			flow.RegisterEntry{Key: reg1, Value: []byte("1")},
+			flow.RegisterEntry{Key: reg2, Value: []byte("2")},
+		)
+		data.ChunkExecutionDatas[1].TrieUpdate = trieUpdate(
+			flow.RegisterEntry{Key: reg1, Value: []byte("3")},
+		)
+		require.NoError(t, core.IndexBlockData(data))
+		require.Equal(t, header.Height, registers.LatestHeight())
+
+		value, err := registers.Get(reg1, header.Height)
+		require.NoError(t, err)
+		assert.Equal(t, "3", string(value))
+
+		value, err = registers.Get(reg2, header.Height)
+		require.NoError(t, err)
+		assert.Equal(t, "2", string(value))
+
+		_, err = registers.Get(reg1, header.Height-1)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
@@ -0,0 +1,119 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	synctest "github.com/onflow/flow-go/module/state_synchronization/requester/unittest"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestIndexer(t *testing.T) {
+	withIndexerCore(t, func(core *IndexerCore, s *coreStorages, db *badger.DB) {
+		const blockCount = 10
+		const initiallyAvailable = 5
+
+		store := execution_data.NewExecutionDataStore(
+			blobs.NewBlobstore(dssync.MutexWrap(datastore.NewMapDatastore())),
+			execution_data.DefaultSerializer,
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// create a chain of blocks with execution data, starting at height 1
+		blocksByHeight := make(map[uint64]*flow.Block)
+		blocksByID := make(map[flow.Identifier]*flow.Block)
+		resultsByID := make(map[flow.Identifier]*flow.ExecutionResult)
+		sealsByBlockID := make(map[flow.Identifier]*flow.Seal)
+		executionData := make(map[uint64]*execution_data.BlockExecutionDataEntity)
+		for height := uint64(1); height <= blockCount; height++ {
+			block := unittest.BlockFixture()
+			block.Header.Height = height
+			require.NoError(t, s.headers.Store(block.Header))
+			blocksByHeight[height] = &block
+			blocksByID[block.ID()] = &block
+
+			data := executionDataFixture(block.ID(), 1, true)
+			executionDataID, err := store.AddExecutionData(ctx, data.BlockExecutionData)
+			require.NoError(t, err)
+			executionData[height] = execution_data.NewBlockExecutionDataEntity(executionDataID, data.BlockExecutionData)
+
+			result := unittest.ExecutionResultFixture(unittest.WithBlock(&block))
+			result.ExecutionDataID = executionDataID
+			resultsByID[result.ID()] = result
+			sealsByBlockID[block.ID()] = unittest.Seal.Fixture(unittest.Seal.WithResult(result))
+		}
+
+		headers := synctest.MockBlockHeaderStorage(synctest.WithByHeight(blocksByHeight), synctest.WithByID(blocksByID))
+		results := synctest.MockResultsStorage(synctest.WithResultByID(resultsByID))
+		seals := synctest.MockSealsStorage(synctest.WithSealsByBlockID(sealsByBlockID))
+
+		indexer := NewIndexer(
+			unittest.Logger(),
+			0,
+			initiallyAvailable,
+			core,
+			store,
+			headers,
+			results,
+			seals,
+			bstorage.NewConsumerProgress(db, "indexer_test"),
+		)
+
+		signalerCtx, errChan := irrecoverable.WithSignaler(ctx)
+		go func() {
+			select {
+			case err := <-errChan:
+				t.Errorf("unexpected error: %v", err)
+			case <-ctx.Done():
+			}
+		}()
+
+		indexer.Start(signalerCtx)
+		unittest.RequireCloseBefore(t, indexer.Ready(), time.Second, "indexer not ready")
+
+		lowest, err := indexer.LowestIndexedHeight()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), lowest)
+
+		// the execution data available on startup is indexed
+		require.Eventually(t, func() bool {
+			highest, err := indexer.HighestIndexedHeight()
+			return err == nil && highest == initiallyAvailable
+		}, time.Second, 10*time.Millisecond)
+
+		// execution data of higher heights is indexed once the requester notifies about it, also if
+		// notifications are repeated
+		for height := uint64(initiallyAvailable + 1); height <= blockCount; height++ {
+			indexer.OnExecutionData(executionData[height])
+			indexer.OnExecutionData(executionData[height-1])
+		}
+		require.Eventually(t, func() bool {
+			highest, err := indexer.HighestIndexedHeight()
+			return err == nil && highest == blockCount
+		}, time.Second, 10*time.Millisecond)
+
+		for height := uint64(1); height <= blockCount; height++ {
+			blockID := blocksByHeight[height].ID()
+			indexed, err := s.results.ByBlockID(blockID)
+			require.NoError(t, err)
+			assert.Len(t, indexed, 4, "transaction results of height %d should be indexed", height)
+		}
+
+		cancel()
+		unittest.RequireCloseBefore(t, indexer.Done(), time.Second, "indexer not done")
+	})
+}
@@ -0,0 +1,82 @@
+package indexer
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/store"
+)
+
+// registersBootstrapBatchSize is the number of registers stored in one batch when bootstrapping registers.
+const registersBootstrapBatchSize = 1000
+
+// BootstrapRegisters stores the registers of the execution state in the given checkpoint file as the
+// execution state at the given height, so that register updates indexed from the execution data of the
+// following heights can be stored on top of it. The checkpoint must contain the execution state at the
+// given height, e.g. the root checkpoint of the spork and the spork root block height. This is not checked.
+// Expected errors during normal operation:
+//   - storage.ErrAlreadyExists if the registers have already been bootstrapped
+func BootstrapRegisters(log zerolog.Logger, db storage.DB, checkpointFile string, height uint64) error {
+	log = log.With().
+		Str("checkpoint_file", checkpointFile).
+		Uint64("height", height).
+		Logger()
+
+	leafNodes := make(chan *wal.LeafNode, registersBootstrapBatchSize)
+	readDone := make(chan error, 1)
+	go func() {
+		readDone <- wal.OpenAndReadLeafNodesFromCheckpointV6(leafNodes, filepath.Dir(checkpointFile), filepath.Base(checkpointFile), &log)
+	}()
+	defer func() {
+		// unblock the reader if bootstrapping is aborted
+		for range leafNodes {
+		}
+	}()
+
+	count := 0
+	next := func() (flow.RegisterEntries, error) {
+		entries := make(flow.RegisterEntries, 0, registersBootstrapBatchSize)
+		for leaf := range leafNodes {
+			key, err := leaf.Payload.Key()
+			if err != nil {
+				return nil, fmt.Errorf("could not get key of payload: %w", err)
+			}
+			id, err := state.KeyToRegisterID(key)
+			if err != nil {
+				return nil, fmt.Errorf("could not convert key of payload: %w", err)
+			}
+			entries = append(entries, flow.RegisterEntry{Key: id, Value: leaf.Payload.Value()})
+
+			if len(entries) == registersBootstrapBatchSize {
+				break
+			}
+		}
+
+		if len(entries) == 0 {
+			// the reader closes the channel once the checkpoint has been read
+			err := <-readDone
+			if err != nil {
+				return nil, fmt.Errorf("could not read checkpoint: %w", err)
+			}
+		}
+
+		count += len(entries)
+		return entries, nil
+	}
+
+	log.Info().Msg("bootstrapping registers from checkpoint")
+
+	err := store.BootstrapRegisters(db, height, next)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int("registers", count).Msg("bootstrapped registers from checkpoint")
+	return nil
+}
@@ -0,0 +1,67 @@
+package indexer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation/dbtest"
+	"github.com/onflow/flow-go/storage/store"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestBootstrapRegisters(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			log := unittest.Logger()
+
+			// more registers than fit into one batch
+			expected := make(map[flow.RegisterID]flow.RegisterValue)
+			for i := 0; i < registersBootstrapBatchSize+10; i++ {
+				address := unittest.RandomAddressFixture()
+				expected[flow.RegisterID{Owner: string(address.Bytes()), Key: "key"}] = unittest.RandomBytes(8)
+			}
+			expected[flow.RegisterID{Owner: "", Key: "global"}] = []byte("global")
+
+			paths := make([]ledger.Path, 0, len(expected))
+			payloads := make([]ledger.Payload, 0, len(expected))
+			for id, value := range expected {
+				key := state.RegisterIDToKey(id)
+				path, err := pathfinder.KeyToPath(key, complete.DefaultPathFinderVersion)
+				require.NoError(t, err)
+				paths = append(paths, path)
+				payloads = append(payloads, *ledger.NewPayload(key, value))
+			}
+			tr, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), paths, payloads, true)
+			require.NoError(t, err)
+			require.NoError(t, wal.StoreCheckpointV6Concurrently([]*trie.MTrie{tr}, dir, "root.checkpoint", &log))
+
+			checkpointFile := filepath.Join(dir, "root.checkpoint")
+			require.NoError(t, BootstrapRegisters(log, db, checkpointFile, 100))
+
+			registers, err := store.NewRegisters(db)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(100), registers.FirstHeight())
+			assert.Equal(t, uint64(100), registers.LatestHeight())
+
+			for id, value := range expected {
+				stored, err := registers.Get(id, 100)
+				require.NoError(t, err)
+				assert.Equal(t, value, stored)
+			}
+
+			err = BootstrapRegisters(log, db, checkpointFile, 100)
+			require.ErrorIs(t, err, storage.ErrAlreadyExists)
+		})
+	})
+}
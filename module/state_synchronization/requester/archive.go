@@ -0,0 +1,99 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/executiondatasync/archive"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+)
+
+// ExecutionDataArchive is a local source of execution data, e.g. the execution data database of
+// another node, which the requester imports execution data from instead of downloading it.
+type ExecutionDataArchive interface {
+	// Import copies the execution data with the given ID from the archive into the local
+	// execution data store.
+	// The returned error will be:
+	// - BlobNotFoundError if the archive does not contain the execution data
+	Import(ctx context.Context, executionDataID flow.Identifier) error
+}
+
+// ErrArchiveMismatch is returned when archived execution data does not match its ID.
+var ErrArchiveMismatch = errors.New("archived execution data does not match its ID")
+
+type storeArchive struct {
+	source execution_data.ExecutionDataStore
+	target execution_data.ExecutionDataStore
+}
+
+var _ ExecutionDataArchive = (*storeArchive)(nil)
+
+// NewStoreArchive returns an archive which imports execution data from the source store into the
+// target store. Since blobs are content-addressed, the imported execution data is verified by
+// comparing the ID it is stored under in the target store with the requested ID.
+func NewStoreArchive(source execution_data.ExecutionDataStore, target execution_data.ExecutionDataStore) ExecutionDataArchive {
+	return &storeArchive{
+		source: source,
+		target: target,
+	}
+}
+
+func (a *storeArchive) Import(ctx context.Context, executionDataID flow.Identifier) error {
+	executionData, err := a.source.GetExecutionData(ctx, executionDataID)
+	if err != nil {
+		return fmt.Errorf("could not get execution data from archive: %w", err)
+	}
+
+	id, err := a.target.AddExecutionData(ctx, executionData)
+	if err != nil {
+		return fmt.Errorf("could not add execution data: %w", err)
+	}
+
+	if id != executionDataID {
+		return fmt.Errorf("%w: expected %v, got %v", ErrArchiveMismatch, executionDataID, id)
+	}
+
+	return nil
+}
+
+type fileArchive struct {
+	reader  *archive.Reader
+	entries map[flow.Identifier]archive.IndexEntry
+	target  blobs.Blobstore
+}
+
+var _ ExecutionDataArchive = (*fileArchive)(nil)
+
+// NewFileArchive returns an archive which imports execution data from an archive file, e.g. one
+// written by the export-execution-data util command, into the target blobstore. The blobs read
+// from the file are verified against their CIDs, and the imported execution data is checked to be
+// complete.
+func NewFileArchive(reader *archive.Reader, target blobs.Blobstore) ExecutionDataArchive {
+	entries := make(map[flow.Identifier]archive.IndexEntry, len(reader.Index()))
+	for _, entry := range reader.Index() {
+		entries[entry.ExecutionDataID] = entry
+	}
+
+	return &fileArchive{
+		reader:  reader,
+		entries: entries,
+		target:  target,
+	}
+}
+
+func (a *fileArchive) Import(ctx context.Context, executionDataID flow.Identifier) error {
+	entry, ok := a.entries[executionDataID]
+	if !ok {
+		return execution_data.NewBlobNotFoundError(flow.IdToCid(executionDataID))
+	}
+
+	record, err := a.reader.Read(entry)
+	if err != nil {
+		return fmt.Errorf("could not read execution data from archive: %w", err)
+	}
+
+	return archive.Import(ctx, a.target, record)
+}
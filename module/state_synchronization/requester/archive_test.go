@@ -0,0 +1,87 @@
+package requester_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/executiondatasync/archive"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/module/state_synchronization/requester"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func newExecutionDataStore() execution_data.ExecutionDataStore {
+	blobstore := blobs.NewBlobstore(dssync.MutexWrap(datastore.NewMapDatastore()))
+	return execution_data.NewExecutionDataStore(blobstore, execution_data.DefaultSerializer)
+}
+
+// TestStoreArchive_Import tests that archived execution data is imported into the target store,
+// and that missing execution data is reported as not found.
+func TestStoreArchive_Import(t *testing.T) {
+	ctx := context.Background()
+
+	source := newExecutionDataStore()
+	target := newExecutionDataStore()
+	archive := requester.NewStoreArchive(source, target)
+
+	ed := unittest.BlockExecutionDataFixture()
+	id, err := source.AddExecutionData(ctx, ed)
+	require.NoError(t, err)
+
+	_, err = target.GetExecutionData(ctx, id)
+	require.True(t, execution_data.IsBlobNotFoundError(err))
+
+	require.NoError(t, archive.Import(ctx, id))
+
+	imported, err := target.GetExecutionData(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, ed, imported)
+
+	err = archive.Import(ctx, unittest.IdentifierFixture())
+	assert.True(t, execution_data.IsBlobNotFoundError(err))
+}
+
+// TestFileArchive_Import tests that execution data is imported from an archive file into the target
+// blobstore, and that execution data missing from the file is reported as not found.
+func TestFileArchive_Import(t *testing.T) {
+	ctx := context.Background()
+
+	sourceBlobstore := blobs.NewBlobstore(dssync.MutexWrap(datastore.NewMapDatastore()))
+	source := execution_data.NewExecutionDataStore(sourceBlobstore, execution_data.DefaultSerializer)
+
+	ed := unittest.BlockExecutionDataFixture()
+	id, err := source.AddExecutionData(ctx, ed)
+	require.NoError(t, err)
+
+	record, err := archive.NewRecord(ctx, sourceBlobstore, 10, ed.BlockID, id)
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	writer, err := archive.NewWriter(buf)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(record))
+	require.NoError(t, writer.Close())
+
+	reader, err := archive.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	targetBlobstore := blobs.NewBlobstore(dssync.MutexWrap(datastore.NewMapDatastore()))
+	target := execution_data.NewExecutionDataStore(targetBlobstore, execution_data.DefaultSerializer)
+	fileArchive := requester.NewFileArchive(reader, targetBlobstore)
+
+	require.NoError(t, fileArchive.Import(ctx, id))
+
+	imported, err := target.GetExecutionData(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, ed, imported)
+
+	err = fileArchive.Import(ctx, unittest.IdentifierFixture())
+	assert.True(t, execution_data.IsBlobNotFoundError(err))
+}
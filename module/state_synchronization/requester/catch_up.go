@@ -0,0 +1,529 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/storage"
+)
+
+const (
+	// DefaultCatchUpRangeSize is the default number of consecutive heights a catch-up worker
+	// downloads at a time.
+	DefaultCatchUpRangeSize = 50
+
+	// DefaultCatchUpMinWorkers is the default minimum number of ranges downloaded in parallel.
+	DefaultCatchUpMinWorkers = 4
+
+	// DefaultCatchUpMaxWorkers is the default maximum number of ranges downloaded in parallel.
+	DefaultCatchUpMaxWorkers = 64
+
+	// DefaultCatchUpAdjustInterval is the default interval at which the number of catch-up workers
+	// is adjusted to the observed throughput.
+	DefaultCatchUpAdjustInterval = 30 * time.Second
+)
+
+// CatchUpConfig contains configuration options for the catch-up mode of the ExecutionDataRequester.
+//
+// When the requester falls more than Threshold heights behind the latest sealed block, e.g. after
+// an outage, it prefetches the missing execution data in ranges of consecutive heights, downloading
+// many ranges in parallel so that the blob service can spread the requests across all peers
+// which have the data. The regular block consumer then finds the execution data locally.
+type CatchUpConfig struct {
+	// Number of heights the requester must fall behind the latest sealed block before it catches
+	// up. Catch-up mode is disabled if Threshold is 0.
+	Threshold uint64
+
+	// Number of consecutive heights downloaded by a worker at a time
+	RangeSize uint64
+
+	// Bounds of the number of ranges downloaded in parallel. The number of workers starts at
+	// MinWorkers, and is adjusted to the observed throughput every AdjustInterval.
+	MinWorkers     int
+	MaxWorkers     int
+	AdjustInterval time.Duration
+
+	// Optional local source of execution data, imported from before downloading from the network
+	Archive ExecutionDataArchive
+}
+
+// DefaultCatchUpConfig returns the default catch-up configuration, which has catch-up mode disabled.
+func DefaultCatchUpConfig() CatchUpConfig {
+	return CatchUpConfig{
+		RangeSize:      DefaultCatchUpRangeSize,
+		MinWorkers:     DefaultCatchUpMinWorkers,
+		MaxWorkers:     DefaultCatchUpMaxWorkers,
+		AdjustInterval: DefaultCatchUpAdjustInterval,
+	}
+}
+
+// heightRange is an inclusive range of block heights
+type heightRange struct {
+	start uint64
+	end   uint64
+
+	// time before which the range must not be retried
+	notBefore time.Time
+}
+
+// rangeResult is the result of downloading a range of heights
+type rangeResult struct {
+	fetched  int
+	archived int
+	// the remaining heights of the range, if the download of a height failed
+	remaining *heightRange
+}
+
+// catchUp prefetches the execution data of the heights the requester has not processed yet,
+// when the requester is far behind the latest sealed block.
+type catchUp struct {
+	component.Component
+
+	log        zerolog.Logger
+	config     CatchUpConfig
+	downloader execution_data.Downloader
+	metrics    module.ExecutionDataRequesterMetrics
+
+	headers storage.Headers
+	results storage.ExecutionResults
+	seals   storage.Seals
+
+	fetchTimeout   time.Duration
+	retryDelay     time.Duration
+	maxSearchAhead uint64
+
+	// highest sealed height, and highest consecutive height processed by the block consumer
+	sealedHeight    func() (uint64, error)
+	processedHeight func() uint64
+
+	notifier engine.Notifier
+}
+
+func newCatchUp(
+	log zerolog.Logger,
+	config CatchUpConfig,
+	downloader execution_data.Downloader,
+	metrics module.ExecutionDataRequesterMetrics,
+	headers storage.Headers,
+	results storage.ExecutionResults,
+	seals storage.Seals,
+	requesterConfig ExecutionDataConfig,
+	sealedHeight func() (uint64, error),
+	processedHeight func() uint64,
+) *catchUp {
+	c := &catchUp{
+		log:             log.With().Str("module", "catch_up").Logger(),
+		config:          config,
+		downloader:      downloader,
+		metrics:         metrics,
+		headers:         headers,
+		results:         results,
+		seals:           seals,
+		fetchTimeout:    requesterConfig.FetchTimeout,
+		retryDelay:      requesterConfig.RetryDelay,
+		maxSearchAhead:  requesterConfig.MaxSearchAhead,
+		sealedHeight:    sealedHeight,
+		processedHeight: processedHeight,
+		notifier:        engine.NewNotifier(),
+	}
+
+	c.Component = component.NewComponentManagerBuilder().
+		AddWorker(c.loop).
+		Build()
+
+	return c
+}
+
+// OnBlockFinalized notifies the catch-up mode that the sealed height may have changed.
+func (c *catchUp) OnBlockFinalized() {
+	c.notifier.Notify()
+}
+
+func (c *catchUp) loop(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+	ready()
+
+	// check right away whether the requester fell behind while the node was down
+	c.notifier.Notify()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.notifier.Channel():
+		}
+
+		sealed, err := c.sealedHeight()
+		if err != nil {
+			ctx.Throw(fmt.Errorf("could not get sealed height: %w", err))
+			return
+		}
+
+		processed := c.processedHeight()
+		if sealed <= processed || sealed-processed < c.config.Threshold {
+			continue
+		}
+
+		c.log.Info().
+			Uint64("processed_height", processed).
+			Uint64("sealed_height", sealed).
+			Msg("catching up")
+
+		start := time.Now()
+		fetched, archived := c.run(ctx, processed+1, sealed)
+
+		c.log.Info().
+			Uint64("sealed_height", sealed).
+			Int("fetched", fetched).
+			Int("archived", archived).
+			Dur("duration", time.Since(start)).
+			Msg("caught up")
+	}
+}
+
+// run prefetches the execution data of all heights in the given range, lowest ranges first, and
+// blocks until all of them are available locally or the context is cancelled.
+// Returns the number of heights downloaded from the network, and imported from the archive.
+func (c *catchUp) run(ctx irrecoverable.SignalerContext, from, to uint64) (int, int) {
+	scheduler := newRangeScheduler(from, to, c.config.RangeSize)
+	controller := newConcurrencyController(c.config.MinWorkers, c.config.MaxWorkers)
+	c.metrics.CatchUpWorkers(controller.workers)
+
+	results := make(chan rangeResult, c.config.MaxWorkers)
+	ticker := time.NewTicker(c.config.AdjustInterval)
+	defer ticker.Stop()
+
+	// retries are due after at most retryDelay
+	retryTicker := time.NewTicker(c.retryDelay)
+	defer retryTicker.Stop()
+
+	lastAdjusted := time.Now()
+	fetched, archived := 0, 0
+	inFlight := 0
+
+	for {
+		// don't search further ahead of the block consumer than it would itself
+		limit := to
+		if c.maxSearchAhead > 0 {
+			limit = c.processedHeight() + c.maxSearchAhead
+		}
+
+		for inFlight < controller.workers {
+			r, ok := scheduler.next(time.Now(), limit)
+			if !ok {
+				break
+			}
+
+			inFlight++
+			go func() {
+				results <- c.fetchRange(ctx, r)
+			}()
+		}
+
+		if inFlight == 0 && scheduler.done() {
+			return fetched, archived
+		}
+
+		select {
+		case <-ctx.Done():
+			// wait for the workers to return, so no download outlives the requester
+			for ; inFlight > 0; inFlight-- {
+				<-results
+			}
+			return fetched, archived
+
+		case result := <-results:
+			inFlight--
+			fetched += result.fetched
+			archived += result.archived
+			controller.observe(result.fetched+result.archived, result.remaining != nil)
+
+			if result.remaining != nil {
+				result.remaining.notBefore = time.Now().Add(c.retryDelay)
+				scheduler.retry(*result.remaining)
+			}
+
+		case now := <-ticker.C:
+			workers := controller.adjust(now.Sub(lastAdjusted))
+			lastAdjusted = now
+			c.metrics.CatchUpWorkers(workers)
+
+			c.log.Debug().
+				Int("workers", workers).
+				Uint64("processed_height", c.processedHeight()).
+				Msg("adjusted catch-up workers")
+
+		case <-retryTicker.C:
+		}
+	}
+}
+
+// fetchRange makes the execution data of the given range available locally. Heights already
+// processed by the block consumer are skipped, heights in the archive are imported from it, and all
+// other heights are downloaded in a single batch.
+func (c *catchUp) fetchRange(ctx irrecoverable.SignalerContext, r heightRange) rangeResult {
+	var result rangeResult
+
+	// heights to download, and their execution data IDs
+	heights := make([]uint64, 0, r.end-r.start+1)
+	executionDataIDs := make([]flow.Identifier, 0, r.end-r.start+1)
+
+	for height := r.start; height <= r.end; height++ {
+		if height <= c.processedHeight() {
+			continue
+		}
+
+		executionDataID, err := c.executionDataID(height)
+		if err != nil {
+			c.log.Debug().Err(err).Uint64("height", height).Msg("could not get execution data ID")
+			result.remaining = &heightRange{start: height, end: r.end}
+			break
+		}
+
+		if c.importFromArchive(ctx, height, executionDataID) {
+			result.archived++
+			c.metrics.CatchUpHeightFetched(true)
+			continue
+		}
+
+		heights = append(heights, height)
+		executionDataIDs = append(executionDataIDs, executionDataID)
+	}
+
+	if len(executionDataIDs) == 0 {
+		return result
+	}
+
+	err := c.download(ctx, executionDataIDs)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.log.Debug().Err(err).
+				Uint64("start_height", heights[0]).
+				Uint64("end_height", heights[len(heights)-1]).
+				Msg("could not prefetch execution data")
+		}
+
+		result.remaining = &heightRange{start: heights[0], end: r.end}
+		return result
+	}
+
+	result.fetched += len(executionDataIDs)
+	for range executionDataIDs {
+		c.metrics.CatchUpHeightFetched(false)
+	}
+
+	return result
+}
+
+// executionDataID returns the execution data ID of the sealed result of the block at the given height.
+func (c *catchUp) executionDataID(height uint64) (flow.Identifier, error) {
+	header, err := c.headers.ByHeight(height)
+	if err != nil {
+		return flow.ZeroID, fmt.Errorf("could not get header: %w", err)
+	}
+
+	seal, err := c.seals.FinalizedSealForBlock(header.ID())
+	if err != nil {
+		return flow.ZeroID, fmt.Errorf("could not get seal: %w", err)
+	}
+
+	result, err := c.results.ByID(seal.ResultID)
+	if err != nil {
+		return flow.ZeroID, fmt.Errorf("could not get execution result: %w", err)
+	}
+
+	return result.ExecutionDataID, nil
+}
+
+// importFromArchive imports the given execution data from the archive, if one is configured.
+// Returns true if the execution data was imported.
+func (c *catchUp) importFromArchive(ctx irrecoverable.SignalerContext, height uint64, executionDataID flow.Identifier) bool {
+	if c.config.Archive == nil {
+		return false
+	}
+
+	err := c.config.Archive.Import(ctx, executionDataID)
+	if err == nil {
+		return true
+	}
+
+	if !isBlobNotFoundError(err) {
+		c.log.Warn().Err(err).
+			Uint64("height", height).
+			Hex("execution_data_id", executionDataID[:]).
+			Msg("could not import execution data from archive, downloading it instead")
+	}
+
+	return false
+}
+
+// download downloads the given execution data in a single batch. The blob trees are downloaded
+// concurrently, so the whole batch gets the fetch timeout of a single height.
+//
+// Invalid execution data is not retried, it is left to the block consumer to halt on it.
+func (c *catchUp) download(ctx irrecoverable.SignalerContext, executionDataIDs []flow.Identifier) error {
+	fetchCtx, cancel := context.WithTimeout(ctx, c.fetchTimeout)
+	defer cancel()
+
+	_, err := c.downloader.DownloadBatch(fetchCtx, executionDataIDs)
+	if err == nil {
+		return nil
+	}
+	if !isInvalidBlobError(err) {
+		return fmt.Errorf("could not download execution data: %w", err)
+	}
+
+	// some execution data in the batch is invalid, download the others individually
+	for _, executionDataID := range executionDataIDs {
+		fetchCtx, cancel := context.WithTimeout(ctx, c.fetchTimeout)
+		_, err := c.downloader.Download(fetchCtx, executionDataID)
+		cancel()
+
+		if err != nil && !isInvalidBlobError(err) {
+			return fmt.Errorf("could not download execution data %v: %w", executionDataID, err)
+		}
+	}
+
+	return nil
+}
+
+// rangeScheduler hands out the ranges of heights to download, lowest heights first.
+// It is not concurrency safe.
+type rangeScheduler struct {
+	nextHeight uint64
+	end        uint64
+	size       uint64
+	// failed ranges to retry, sorted by start height
+	retries []heightRange
+}
+
+func newRangeScheduler(from, to, size uint64) *rangeScheduler {
+	if size == 0 {
+		size = 1
+	}
+	return &rangeScheduler{
+		nextHeight: from,
+		end:        to,
+		size:       size,
+	}
+}
+
+// next returns the lowest range due at the given time which starts at or below limit.
+func (s *rangeScheduler) next(now time.Time, limit uint64) (heightRange, bool) {
+	// retried ranges always start below the next new range
+	for i, r := range s.retries {
+		if r.start > limit {
+			break
+		}
+		if now.Before(r.notBefore) {
+			continue
+		}
+		s.retries = append(s.retries[:i], s.retries[i+1:]...)
+		return r, true
+	}
+
+	if s.nextHeight > s.end || s.nextHeight > limit {
+		return heightRange{}, false
+	}
+
+	r := heightRange{start: s.nextHeight, end: s.nextHeight + s.size - 1}
+	if r.end > s.end {
+		r.end = s.end
+	}
+	s.nextHeight = r.end + 1
+
+	return r, true
+}
+
+// retry schedules the given range to be downloaded again.
+func (s *rangeScheduler) retry(r heightRange) {
+	s.retries = append(s.retries, r)
+	sort.Slice(s.retries, func(i, j int) bool {
+		return s.retries[i].start < s.retries[j].start
+	})
+}
+
+// done returns true if all ranges have been handed out and none need to be retried.
+func (s *rangeScheduler) done() bool {
+	return s.nextHeight > s.end && len(s.retries) == 0
+}
+
+// concurrencyController adapts the number of workers to the observed throughput: it keeps adding
+// workers while that increases the throughput, removes workers when the throughput drops, and
+// halves the number of workers if most range downloads fail, e.g. because peers are overloaded.
+// It is not concurrency safe.
+type concurrencyController struct {
+	min     int
+	max     int
+	workers int
+
+	// throughput of the previous interval, in heights per second
+	previous float64
+	// heights fetched in the current interval
+	fetched int
+	// range downloads completed and failed in the current interval
+	completed int
+	failed    int
+}
+
+func newConcurrencyController(min, max int) *concurrencyController {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return &concurrencyController{
+		min:     min,
+		max:     max,
+		workers: min,
+	}
+}
+
+// observe records the result of a range download, which fetched the given number of heights
+// before it completed or failed.
+func (c *concurrencyController) observe(fetched int, failed bool) {
+	c.fetched += fetched
+	if failed {
+		c.failed++
+	} else {
+		c.completed++
+	}
+}
+
+// adjust updates and returns the number of workers, based on the throughput since the last adjustment.
+func (c *concurrencyController) adjust(elapsed time.Duration) int {
+	throughput := float64(c.fetched) / elapsed.Seconds()
+
+	switch {
+	case c.failed > c.completed:
+		c.workers /= 2
+	case throughput > c.previous*1.05:
+		c.workers++
+	case throughput < c.previous*0.95:
+		c.workers--
+	}
+
+	if c.workers < c.min {
+		c.workers = c.min
+	}
+	if c.workers > c.max {
+		c.workers = c.max
+	}
+
+	c.previous = throughput
+	c.fetched = 0
+	c.completed = 0
+	c.failed = 0
+
+	return c.workers
+}
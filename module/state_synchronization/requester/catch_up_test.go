@@ -0,0 +1,107 @@
+package requester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRangeScheduler_LowestFirst tests that ranges are handed out in height order, and that failed
+// ranges are retried before new ranges once their retry delay has passed.
+func TestRangeScheduler_LowestFirst(t *testing.T) {
+	now := time.Now()
+	s := newRangeScheduler(10, 34, 10)
+
+	r, ok := s.next(now, 100)
+	require.True(t, ok)
+	assert.Equal(t, heightRange{start: 10, end: 19}, r)
+
+	r, ok = s.next(now, 100)
+	require.True(t, ok)
+	assert.Equal(t, heightRange{start: 20, end: 29}, r)
+
+	// the remainder of the first range failed
+	s.retry(heightRange{start: 15, end: 19, notBefore: now.Add(time.Second)})
+
+	// the retry is not due yet
+	r, ok = s.next(now, 100)
+	require.True(t, ok)
+	assert.Equal(t, heightRange{start: 30, end: 34}, r)
+
+	_, ok = s.next(now, 100)
+	assert.False(t, ok)
+	assert.False(t, s.done())
+
+	r, ok = s.next(now.Add(time.Second), 100)
+	require.True(t, ok)
+	assert.Equal(t, uint64(15), r.start)
+	assert.Equal(t, uint64(19), r.end)
+
+	assert.True(t, s.done())
+}
+
+// TestRangeScheduler_Limit tests that no range starting above the limit is handed out.
+func TestRangeScheduler_Limit(t *testing.T) {
+	now := time.Now()
+	s := newRangeScheduler(1, 100, 10)
+
+	r, ok := s.next(now, 5)
+	require.True(t, ok)
+	assert.Equal(t, heightRange{start: 1, end: 10}, r)
+
+	_, ok = s.next(now, 5)
+	assert.False(t, ok)
+
+	s.retry(heightRange{start: 8, end: 10})
+	_, ok = s.next(now, 5)
+	assert.False(t, ok)
+
+	r, ok = s.next(now, 11)
+	require.True(t, ok)
+	assert.Equal(t, heightRange{start: 8, end: 10}, r)
+
+	r, ok = s.next(now, 11)
+	require.True(t, ok)
+	assert.Equal(t, heightRange{start: 11, end: 20}, r)
+}
+
+// TestConcurrencyController tests that workers are added while the throughput increases, removed
+// when it drops, and halved when most range downloads fail, within the configured bounds.
+func TestConcurrencyController(t *testing.T) {
+	c := newConcurrencyController(2, 4)
+	assert.Equal(t, 2, c.workers)
+
+	// throughput increases
+	c.observe(10, false)
+	assert.Equal(t, 3, c.adjust(time.Second))
+
+	c.observe(20, false)
+	assert.Equal(t, 4, c.adjust(time.Second))
+
+	// capped at max
+	c.observe(30, false)
+	assert.Equal(t, 4, c.adjust(time.Second))
+
+	// throughput drops
+	c.observe(20, false)
+	assert.Equal(t, 3, c.adjust(time.Second))
+
+	// stable throughput
+	c.observe(20, false)
+	assert.Equal(t, 3, c.adjust(time.Second))
+
+	c.observe(40, false)
+	assert.Equal(t, 4, c.adjust(time.Second))
+
+	// more failed than completed ranges, even though the failed ranges fetched most heights
+	c.observe(30, true)
+	c.observe(30, true)
+	c.observe(5, false)
+	assert.Equal(t, 2, c.adjust(time.Second))
+
+	// bounded by min
+	c.observe(0, true)
+	assert.Equal(t, 2, c.adjust(time.Second))
+}
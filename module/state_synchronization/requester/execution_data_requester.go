@@ -111,6 +111,15 @@ type ExecutionDataConfig struct {
 	// Exponential backoff settings for download retries
 	RetryDelay    time.Duration
 	MaxRetryDelay time.Duration
+
+	// Timeout and retry settings for block jobs which don't complete, e.g. because their execution
+	// data is invalid or unavailable. Timeouts are disabled if BlockJobTimeout.Timeout is 0.
+	// BlockJobTimeout.MaxAttempts is ignored, since timed out block jobs are retried indefinitely.
+	BlockJobTimeout jobqueue.JobTimeoutConfig
+
+	// Settings for prefetching execution data in parallel when the requester is far behind the
+	// latest sealed block. See CatchUpConfig.
+	CatchUp CatchUpConfig
 }
 
 type executionDataRequester struct {
@@ -135,6 +144,9 @@ type executionDataRequester struct {
 	blockConsumer        *jobqueue.ComponentConsumer
 	notificationConsumer *jobqueue.ComponentConsumer
 
+	// Prefetches execution data in parallel when far behind. nil if catch-up mode is disabled.
+	catchUp *catchUp
+
 	// List of callbacks to call when ExecutionData is successfully fetched for a block
 	consumers []state_synchronization.OnExecutionDataReceivedConsumer
 
@@ -192,6 +204,9 @@ func New(
 		e.processBlockJob,                // process the sealed block job to download its execution data
 		fetchWorkers,                     // the number of concurrent workers
 		e.config.MaxSearchAhead,          // max number of unsent notifications to allow before pausing new fetches
+		// block jobs are never dead-lettered, since the notification consumer requires the execution
+		// data of every height. Timed out downloads are retried indefinitely instead.
+		jobqueue.WithJobTimeout(e.config.BlockJobTimeout),
 	)
 	// notifies notificationConsumer when new ExecutionData blobs are available
 	// SetPostNotifier will notify executionDataNotifier AFTER e.blockConsumer.LastProcessedIndex is updated.
@@ -241,6 +256,25 @@ func New(
 		AddWorker(e.runBlockConsumer).
 		AddWorker(e.runNotificationConsumer)
 
+	// catchUp downloads ranges of sealed heights in parallel when the blockConsumer falls more than
+	// `CatchUp.Threshold` heights behind, e.g. after an outage. The blockConsumer then finds the
+	// execution data in the local blobstore instead of downloading one height at a time.
+	if e.config.CatchUp.Threshold > 0 {
+		e.catchUp = newCatchUp(
+			e.log,
+			e.config.CatchUp,
+			e.downloader,
+			e.metrics,
+			e.headers,
+			e.results,
+			e.seals,
+			e.config,
+			e.blockConsumer.Head,               // highest sealed height
+			e.blockConsumer.LastProcessedIndex, // highest consecutive downloaded height
+		)
+		builder.AddWorker(e.runCatchUp)
+	}
+
 	e.cm = builder.Build()
 	e.Component = e.cm
 
@@ -250,6 +284,9 @@ func New(
 // OnBlockFinalized accepts block finalization notifications from the FollowerDistributor
 func (e *executionDataRequester) OnBlockFinalized(*model.Block) {
 	e.finalizationNotifier.Notify()
+	if e.catchUp != nil {
+		e.catchUp.OnBlockFinalized()
+	}
 }
 
 // AddOnExecutionDataReceivedConsumer adds a callback to be called when a new ExecutionData is received
@@ -299,6 +336,20 @@ func (e *executionDataRequester) runNotificationConsumer(ctx irrecoverable.Signa
 	<-e.notificationConsumer.Done()
 }
 
+// runCatchUp runs the catchUp component once the blockConsumer is ready
+func (e *executionDataRequester) runCatchUp(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+	ready()
+
+	err := util.WaitClosed(ctx, e.blockConsumer.Ready())
+	if err != nil {
+		return // context cancelled
+	}
+
+	e.catchUp.Start(ctx)
+
+	<-e.catchUp.Done()
+}
+
 // Fetch Worker Methods
 
 // processBlockJob consumes jobs from the blockConsumer and attempts to download an ExecutionData
@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/atomic"
 
 	"github.com/onflow/flow-go/consensus/hotstuff/model"
 	"github.com/onflow/flow-go/consensus/hotstuff/notifications/pubsub"
@@ -125,6 +126,22 @@ func mockDownloader(edStore map[flow.Identifier]*testExecutionDataServiceEntry)
 		).
 		Maybe() // Maybe() needed to get call count
 
+	downloader.On("DownloadBatch", mock.Anything, mock.AnythingOfType("[]flow.Identifier")).
+		Return(
+			func(ctx context.Context, ids []flow.Identifier) ([]*execution_data.BlockExecutionData, error) {
+				eds := make([]*execution_data.BlockExecutionData, len(ids))
+				for i, id := range ids {
+					ed, err := get(id)
+					if err != nil {
+						return nil, err
+					}
+					eds[i] = ed
+				}
+				return eds, nil
+			},
+		).
+		Maybe() // Maybe() needed to get call count
+
 	noop := module.NoopReadyDoneAware{}
 	downloader.On("Ready").
 		Return(func() <-chan struct{} { return noop.Ready() }).
@@ -258,6 +275,47 @@ func (suite *ExecutionDataRequesterSuite) TestRequesterCatchesUp() {
 	})
 }
 
+// TestRequesterCatchesUpInParallel tests that the requester processes all heights when it starts
+// with a backlog of sealed blocks, and catch-up mode prefetches them in parallel ranges.
+func (suite *ExecutionDataRequesterSuite) TestRequesterCatchesUpInParallel() {
+	unittest.RunWithBadgerDB(suite.T(), func(db *badger.DB) {
+		suite.db = db
+
+		suite.datastore = dssync.MutexWrap(datastore.NewMapDatastore())
+		suite.blobstore = blobs.NewBlobstore(suite.datastore)
+
+		testData := suite.generateTestData(suite.run.blockCount, generateBlocksWithRandomDelays(suite.run.blockCount))
+		testData.catchUp = requester.CatchUpConfig{
+			Threshold:      10,
+			RangeSize:      5,
+			MinWorkers:     2,
+			MaxWorkers:     8,
+			AdjustInterval: 10 * time.Millisecond,
+		}
+		catchUpMetrics := &catchUpMetrics{NoopCollector: metrics.NewNoopCollector()}
+		testData.metrics = catchUpMetrics
+
+		// start processing with all seals available
+		edr, fd := suite.prepareRequesterTest(testData)
+		testData.resumeHeight = testData.endHeight
+		fetchedExecutionData := suite.runRequesterTest(edr, fd, testData)
+
+		verifyFetchedExecutionData(suite.T(), fetchedExecutionData, testData)
+
+		// catch-up mode prefetched ranges of heights ahead of the block consumer
+		assert.Positive(suite.T(), catchUpMetrics.fetched.Load())
+		calls := 0
+		for _, call := range suite.downloader.Calls {
+			if call.Method == "DownloadBatch" {
+				calls++
+			}
+		}
+		assert.Positive(suite.T(), calls)
+
+		suite.T().Log("Shutting down test")
+	})
+}
+
 // TestRequesterPausesAndResumes tests that the requester pauses when it downloads maxSearchAhead
 // blocks beyond the last processed block, and resumes when it catches up.
 func (suite *ExecutionDataRequesterSuite) TestRequesterPausesAndResumes() {
@@ -400,13 +458,18 @@ func (suite *ExecutionDataRequesterSuite) prepareRequesterTest(cfg *fetchTestRun
 
 	suite.downloader = mockDownloader(cfg.executionDataEntries)
 
+	var requesterMetrics module.ExecutionDataRequesterMetrics = metrics.NewNoopCollector()
+	if cfg.metrics != nil {
+		requesterMetrics = cfg.metrics
+	}
+
 	followerDistributor := pubsub.NewFollowerDistributor()
 	processedHeight := bstorage.NewConsumerProgress(suite.db, module.ConsumeProgressExecutionDataRequesterBlockHeight)
 	processedNotification := bstorage.NewConsumerProgress(suite.db, module.ConsumeProgressExecutionDataRequesterNotification)
 
 	edr := requester.New(
 		zerolog.New(os.Stdout).With().Timestamp().Logger(),
-		metrics.NewNoopCollector(),
+		requesterMetrics,
 		suite.downloader,
 		processedHeight,
 		processedNotification,
@@ -420,6 +483,7 @@ func (suite *ExecutionDataRequesterSuite) prepareRequesterTest(cfg *fetchTestRun
 			FetchTimeout:       cfg.fetchTimeout,
 			RetryDelay:         cfg.retryDelay,
 			MaxRetryDelay:      cfg.maxRetryDelay,
+			CatchUp:            cfg.catchUp,
 		},
 	)
 
@@ -584,6 +648,18 @@ type fetchTestRun struct {
 	fetchTimeout   time.Duration
 	retryDelay     time.Duration
 	maxRetryDelay  time.Duration
+	catchUp        requester.CatchUpConfig
+	metrics        module.ExecutionDataRequesterMetrics
+}
+
+// catchUpMetrics counts the heights prefetched by catch-up mode.
+type catchUpMetrics struct {
+	*metrics.NoopCollector
+	fetched atomic.Int64
+}
+
+func (m *catchUpMetrics) CatchUpHeightFetched(bool) {
+	m.fetched.Inc()
 }
 
 func (r *fetchTestRun) StartHeight() uint64 {
@@ -178,6 +178,7 @@ const (
 	FVMEnvGetCurrentBlockHeight      SpanName = "fvm.env.getCurrentBlockHeight"
 	FVMEnvGetBlockAtHeight           SpanName = "fvm.env.getBlockAtHeight"
 	FVMEnvUnsafeRandom               SpanName = "fvm.env.unsafeRandom"
+	FVMEnvVerifiableRandom           SpanName = "fvm.env.verifiableRandom"
 	FVMEnvCreateAccount              SpanName = "fvm.env.createAccount"
 	FVMEnvAddAccountKey              SpanName = "fvm.env.addAccountKey"
 	FVMEnvAddEncodedAccountKey       SpanName = "fvm.env.addEncodedAccountKey"
@@ -1,15 +1,36 @@
 package updatable_configs
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/rs/zerolog"
+
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module/util"
 )
 
+// ErrUnknownConfig is returned when referencing a config field which is not registered.
+var ErrUnknownConfig = fmt.Errorf("unknown config name")
+
+// Source describes where the current value of a config field came from.
+type Source string
+
+const (
+	// SourceDefault is the source of config values which were not changed from their default.
+	SourceDefault Source = "default"
+	// SourceFlag is the source of config values which were set by a command line flag.
+	SourceFlag Source = "flag"
+	// SourcePersisted is the source of config values which were set at runtime and persisted,
+	// including values re-applied from the Store on startup.
+	SourcePersisted Source = "persisted"
+	// SourceRuntime is the source of config values which were set at runtime, but not persisted.
+	SourceRuntime Source = "runtime"
+)
+
 // ErrAlreadyRegistered is returned when a config field is registered with a name
 // conflicting with an already registered config field.
 var ErrAlreadyRegistered = fmt.Errorf("config name already registered")
@@ -89,15 +110,234 @@ type Field struct {
 // The registration functions must convert input types (as parsed from JSON) to
 // the Go type expected by the config field setter. They must also convert Go types
 // from config field getters to displayable types (see structpb.NewValue for details).
+//
+// Optionally, values set through the Manager are persisted to a Store, and re-applied
+// with ApplyPersistedOverrides when the node restarts. Values set by command line flags
+// take precedence over persisted values.
 type Manager struct {
-	mu     sync.Mutex
-	fields map[string]Field
+	mu        sync.Mutex
+	fields    map[string]Field
+	sources   map[string]Source
+	flags     map[string]string          // config name -> name of the flag setting the initial value
+	flagSet   map[string]struct{}        // configs whose flag was set on the command line
+	transient map[string]struct{}        // configs which are never persisted
+	initial   map[string]json.RawMessage // config name -> value set by default or flag, restored by Unset
+	store     Store
 }
 
 func NewManager() *Manager {
 	return &Manager{
-		fields: make(map[string]Field),
+		fields:    make(map[string]Field),
+		sources:   make(map[string]Source),
+		flags:     make(map[string]string),
+		flagSet:   make(map[string]struct{}),
+		transient: make(map[string]struct{}),
+		initial:   make(map[string]json.RawMessage),
+	}
+}
+
+// SetStore enables persisting the config values set through the Manager to the given store.
+func (m *Manager) SetStore(store Store) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = store
+}
+
+// SetFlagName sets the name of the command line flag which sets the initial value of the given config.
+// By default, the flag name is assumed to be the same as the config name.
+func (m *Manager) SetFlagName(name string, flag string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flags[name] = flag
+}
+
+// SetTransient marks the given config as transient. Changes to transient configs, for example
+// configs triggering an action, are not persisted.
+func (m *Manager) SetTransient(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transient[name] = struct{}{}
+}
+
+// ResolveFlagSources sets the source of all registered config fields whose flag was set on the
+// command line to SourceFlag, and records the initial values of all config fields so they can
+// be restored by Unset. Must be called after all config fields are registered, and before
+// persisted overrides are applied.
+func (m *Manager) ResolveFlagSources(isFlagSet func(flag string) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, field := range m.fields {
+		flag, ok := m.flags[name]
+		if !ok {
+			flag = name
+		}
+		if isFlagSet(flag) {
+			m.sources[name] = SourceFlag
+			m.flagSet[name] = struct{}{}
+		}
+
+		// values returned by getters are encoded the same way as the values accepted by setters
+		encoded, err := json.Marshal(field.Get())
+		if err == nil {
+			m.initial[name] = encoded
+		}
+	}
+}
+
+// Source returns the source of the current value of the given config.
+func (m *Manager) Source(name string) Source {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if source, ok := m.sources[name]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// Set sets the value of the given config, and persists it if a store is configured and the
+// config is not transient.
+// Expected errors during normal operation:
+//   - ErrUnknownConfig if no config is registered with the given name
+//   - ValidationError if the new config value is invalid
+func (m *Manager) Set(name string, value any) error {
+	field, ok := m.GetField(name)
+	if !ok {
+		return fmt.Errorf("can't set config %s: %w", name, ErrUnknownConfig)
+	}
+
+	err := field.Set(value)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, transient := m.transient[name]
+	if m.store == nil || transient {
+		m.sources[name] = SourceRuntime
+		return nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("could not encode value of config %s: %w", name, err)
+	}
+	// the value is applied even if persisting it fails, it is then only lost on restart
+	m.sources[name] = SourceRuntime
+	err = m.store.SetOverride(name, encoded)
+	if err != nil {
+		return fmt.Errorf("could not persist value of config %s: %w", name, err)
+	}
+	m.sources[name] = SourcePersisted
+	return nil
+}
+
+// Unset removes the persisted value of the given config if a store is configured, and restores the
+// value the config had before persisted overrides were applied, i.e. its default or flag value.
+// Expected errors during normal operation:
+//   - ErrUnknownConfig if no config is registered with the given name
+func (m *Manager) Unset(name string) error {
+	field, ok := m.GetField(name)
+	if !ok {
+		return fmt.Errorf("can't unset config %s: %w", name, ErrUnknownConfig)
+	}
+
+	m.mu.Lock()
+	store := m.store
+	initial, hasInitial := m.initial[name]
+	m.mu.Unlock()
+
+	if store != nil {
+		err := store.DeleteOverride(name)
+		if err != nil {
+			return fmt.Errorf("could not remove persisted value of config %s: %w", name, err)
+		}
+	}
+
+	if !hasInitial {
+		return fmt.Errorf("initial value of config %s is unknown", name)
+	}
+	var value any
+	err := json.Unmarshal(initial, &value)
+	if err != nil {
+		return fmt.Errorf("could not decode initial value of config %s: %w", name, err)
 	}
+	err = field.Set(value)
+	if err != nil {
+		return fmt.Errorf("could not restore initial value of config %s: %w", name, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.flagSet[name]; ok {
+		m.sources[name] = SourceFlag
+	} else {
+		delete(m.sources, name)
+	}
+	return nil
+}
+
+// ApplyPersistedOverrides applies the config values persisted in the store. Must be called after all
+// config fields are registered and flag sources are resolved, and before the node's components are
+// started. Persisted values of configs which are set by a command line flag, which are no longer
+// registered, or which are no longer valid, are skipped with a warning.
+// No errors are expected during normal operations.
+func (m *Manager) ApplyPersistedOverrides(log zerolog.Logger) error {
+	m.mu.Lock()
+	store := m.store
+	m.mu.Unlock()
+
+	if store == nil {
+		return nil
+	}
+
+	overrides, err := store.Overrides()
+	if err != nil {
+		return fmt.Errorf("could not read persisted config overrides: %w", err)
+	}
+
+	for name, encoded := range overrides {
+		field, ok := m.GetField(name)
+		if !ok {
+			log.Warn().Str("config", name).Msg("skipping persisted override of unknown config")
+			continue
+		}
+		if m.Source(name) == SourceFlag {
+			log.Warn().
+				Str("config", name).
+				RawJSON("value", encoded).
+				Interface("flag_value", field.Get()).
+				Msg("skipping persisted config override of value set by command line flag, use the unset-config admin command to remove it")
+			continue
+		}
+
+		var value any
+		err := json.Unmarshal(encoded, &value)
+		if err != nil {
+			log.Warn().Err(err).Str("config", name).Msg("skipping undecodable persisted config override")
+			continue
+		}
+
+		err = field.Set(value)
+		if IsValidationError(err) {
+			log.Warn().Err(err).Str("config", name).Msg("skipping invalid persisted config override")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not apply persisted override of config %s: %w", name, err)
+		}
+
+		m.mu.Lock()
+		m.sources[name] = SourcePersisted
+		m.mu.Unlock()
+
+		log.Info().Str("config", name).RawJSON("value", encoded).Msg("applied persisted config override")
+	}
+
+	return nil
 }
 
 // GetField returns the updatable config field with the given name, if one exists.
@@ -0,0 +1,123 @@
+package updatable_configs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists config values set at runtime, so they can be re-applied when the node restarts.
+// Values are stored in their JSON encoding, as received by the set-config admin command.
+type Store interface {
+	// Overrides returns all persisted config values, keyed by config name.
+	// No errors are expected during normal operations.
+	Overrides() (map[string]json.RawMessage, error)
+	// SetOverride persists the given config value, replacing any previous value.
+	// No errors are expected during normal operations.
+	SetOverride(name string, value json.RawMessage) error
+	// DeleteOverride removes the persisted value of the given config, if there is one.
+	// No errors are expected during normal operations.
+	DeleteOverride(name string) error
+}
+
+// FileStore persists config overrides in a JSON file, mapping config names to values.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+var _ Store = (*FileStore)(nil)
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Overrides() (map[string]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read()
+}
+
+func (s *FileStore) SetOverride(name string, value json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	overrides, err := s.read()
+	if err != nil {
+		return err
+	}
+	overrides[name] = value
+
+	return s.write(overrides)
+}
+
+func (s *FileStore) DeleteOverride(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	overrides, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := overrides[name]; !ok {
+		return nil
+	}
+	delete(overrides, name)
+
+	return s.write(overrides)
+}
+
+// write replaces the overrides in the file with the given ones.
+func (s *FileStore) write(overrides map[string]json.RawMessage) error {
+	data, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode config overrides: %w", err)
+	}
+
+	// write to a temporary file first, so that the overrides are never partially written
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not create config overrides file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write config overrides file: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), s.path)
+	if err != nil {
+		return fmt.Errorf("could not replace config overrides file: %w", err)
+	}
+	return nil
+}
+
+// read returns the overrides in the file, or no overrides if the file doesn't exist yet.
+func (s *FileStore) read() (map[string]json.RawMessage, error) {
+	overrides := make(map[string]json.RawMessage)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return overrides, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read config overrides file: %w", err)
+	}
+
+	err = json.Unmarshal(data, &overrides)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode config overrides file: %w", err)
+	}
+	return overrides, nil
+}
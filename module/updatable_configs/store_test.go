@@ -0,0 +1,141 @@
+package updatable_configs_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/updatable_configs"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// testConfigs are updatable configs registered with a manager, which record their values.
+type testConfigs struct {
+	mgr       *updatable_configs.Manager
+	enabled   bool
+	rate      uint
+	blocklist flow.IdentifierList
+}
+
+func newTestConfigs(t *testing.T, store updatable_configs.Store) *testConfigs {
+	c := &testConfigs{mgr: updatable_configs.NewManager()}
+	require.NoError(t, c.mgr.RegisterBoolConfig("enabled",
+		func() bool { return c.enabled },
+		func(v bool) error { c.enabled = v; return nil }))
+	require.NoError(t, c.mgr.RegisterUintConfig("rate",
+		func() uint { return c.rate },
+		func(v uint) error {
+			if v > 100 {
+				return updatable_configs.NewValidationErrorf("rate too high: %d", v)
+			}
+			c.rate = v
+			return nil
+		}))
+	require.NoError(t, c.mgr.RegisterIdentifierListConfig("blocklist",
+		func() flow.IdentifierList { return c.blocklist },
+		func(v flow.IdentifierList) error { c.blocklist = v; return nil }))
+	c.mgr.SetFlagName("rate", "rate-flag")
+	c.mgr.SetTransient("enabled")
+	c.mgr.SetStore(store)
+	return c
+}
+
+func testPersistedOverrides(t *testing.T, newStore func() updatable_configs.Store) {
+	configs := newTestConfigs(t, newStore())
+	require.NoError(t, configs.mgr.ApplyPersistedOverrides(unittest.Logger()))
+
+	blocked := unittest.IdentifierListFixture(2)
+	require.NoError(t, configs.mgr.Set("rate", float64(10)))
+	require.NoError(t, configs.mgr.Set("blocklist", []any{blocked[0].String(), blocked[1].String()}))
+	require.NoError(t, configs.mgr.Set("enabled", true))
+
+	err := configs.mgr.Set("rate", float64(200))
+	assert.True(t, updatable_configs.IsValidationError(err))
+	err = configs.mgr.Set("unknown", true)
+	assert.ErrorIs(t, err, updatable_configs.ErrUnknownConfig)
+
+	assert.Equal(t, updatable_configs.SourcePersisted, configs.mgr.Source("rate"))
+	assert.Equal(t, updatable_configs.SourceRuntime, configs.mgr.Source("enabled"))
+
+	// the persisted values are re-applied on restart, transient configs are not persisted
+	restarted := newTestConfigs(t, newStore())
+	restarted.mgr.ResolveFlagSources(func(flag string) bool { return false })
+	assert.Equal(t, updatable_configs.SourceDefault, restarted.mgr.Source("blocklist"))
+
+	require.NoError(t, restarted.mgr.ApplyPersistedOverrides(unittest.Logger()))
+	assert.Equal(t, uint(10), restarted.rate)
+	assert.Equal(t, blocked, restarted.blocklist)
+	assert.False(t, restarted.enabled)
+	assert.Equal(t, updatable_configs.SourcePersisted, restarted.mgr.Source("rate"))
+	assert.Equal(t, updatable_configs.SourcePersisted, restarted.mgr.Source("blocklist"))
+	assert.Equal(t, updatable_configs.SourceDefault, restarted.mgr.Source("enabled"))
+
+	// values set by flags take precedence over persisted values
+	flagged := newTestConfigs(t, newStore())
+	flagged.rate = 20
+	flagged.mgr.ResolveFlagSources(func(flag string) bool { return flag == "rate-flag" })
+	assert.Equal(t, updatable_configs.SourceFlag, flagged.mgr.Source("rate"))
+
+	require.NoError(t, flagged.mgr.ApplyPersistedOverrides(unittest.Logger()))
+	assert.Equal(t, uint(20), flagged.rate)
+	assert.Equal(t, blocked, flagged.blocklist)
+	assert.Equal(t, updatable_configs.SourceFlag, flagged.mgr.Source("rate"))
+
+	// unsetting a config restores its flag value, and removes the persisted value
+	require.NoError(t, flagged.mgr.Set("rate", float64(30)))
+	assert.Equal(t, updatable_configs.SourcePersisted, flagged.mgr.Source("rate"))
+	require.NoError(t, flagged.mgr.Unset("rate"))
+	assert.Equal(t, uint(20), flagged.rate)
+	assert.Equal(t, updatable_configs.SourceFlag, flagged.mgr.Source("rate"))
+
+	// unsetting a config without flag restores its default value
+	require.NoError(t, flagged.mgr.Unset("blocklist"))
+	assert.Empty(t, flagged.blocklist)
+	assert.Equal(t, updatable_configs.SourceDefault, flagged.mgr.Source("blocklist"))
+	err = flagged.mgr.Unset("unknown")
+	assert.ErrorIs(t, err, updatable_configs.ErrUnknownConfig)
+
+	overrides, err := newStore().Overrides()
+	require.NoError(t, err)
+	assert.NotContains(t, overrides, "rate")
+	assert.NotContains(t, overrides, "blocklist")
+
+	// overrides of configs which are no longer registered are skipped
+	require.NoError(t, newStore().SetOverride("removed", []byte(`true`)))
+	require.NoError(t, newStore().SetOverride("rate", []byte(`10`)))
+	restarted = newTestConfigs(t, newStore())
+	restarted.mgr.ResolveFlagSources(func(flag string) bool { return false })
+	require.NoError(t, restarted.mgr.ApplyPersistedOverrides(unittest.Logger()))
+	assert.Equal(t, uint(10), restarted.rate)
+}
+
+func TestBadgerStore(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		testPersistedOverrides(t, func() updatable_configs.Store {
+			return bstorage.NewConfigOverrides(db)
+		})
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "overrides.json")
+		testPersistedOverrides(t, func() updatable_configs.Store {
+			return updatable_configs.NewFileStore(path)
+		})
+	})
+}
+
+func TestManager_NoStore(t *testing.T) {
+	configs := newTestConfigs(t, nil)
+	require.NoError(t, configs.mgr.ApplyPersistedOverrides(unittest.Logger()))
+
+	require.NoError(t, configs.mgr.Set("rate", float64(10)))
+	assert.Equal(t, uint(10), configs.rate)
+	assert.Equal(t, updatable_configs.SourceRuntime, configs.mgr.Source("rate"))
+}
@@ -64,6 +64,10 @@ const (
 	// DKG
 	CodeDKGMessage
 
+	// header-first protocol state sync
+	CodeHeaderRangeRequest
+	CodeHeaderResponse
+
 	CodeMax
 )
 
@@ -141,6 +145,12 @@ func MessageCodeFromInterface(v interface{}) (MessageCode, string, error) {
 	case *messages.DKGMessage:
 		return CodeDKGMessage, s, nil
 
+	// header-first protocol state sync
+	case *messages.HeaderRangeRequest:
+		return CodeHeaderRangeRequest, s, nil
+	case *messages.HeaderResponse:
+		return CodeHeaderResponse, s, nil
+
 	default:
 		return 0, "", fmt.Errorf("invalid encode type (%T)", v)
 	}
@@ -218,6 +228,12 @@ func InterfaceFromMessageCode(code MessageCode) (interface{}, string, error) {
 	case CodeDKGMessage:
 		return &messages.DKGMessage{}, what(&messages.DKGMessage{}), nil
 
+	// header-first protocol state sync
+	case CodeHeaderRangeRequest:
+		return &messages.HeaderRangeRequest{}, what(&messages.HeaderRangeRequest{}), nil
+	case CodeHeaderResponse:
+		return &messages.HeaderResponse{}, what(&messages.HeaderResponse{}), nil
+
 	// test messages
 	case CodeEcho:
 		return &message.TestMessage{}, what(&message.TestMessage{}), nil
@@ -176,6 +176,30 @@ func initializeMessageAuthConfigsMap() {
 			},
 		},
 	}
+	authorizationConfigs[HeaderRangeRequest] = MsgAuthConfig{
+		Name: HeaderRangeRequest,
+		Type: func() interface{} {
+			return new(messages.HeaderRangeRequest)
+		},
+		Config: map[channels.Channel]ChannelAuthConfig{
+			channels.SyncCommittee: {
+				AuthorizedRoles:  flow.Roles(),
+				AllowedProtocols: Protocols{ProtocolTypePubSub},
+			},
+		},
+	}
+	authorizationConfigs[HeaderResponse] = MsgAuthConfig{
+		Name: HeaderResponse,
+		Type: func() interface{} {
+			return new(messages.HeaderResponse)
+		},
+		Config: map[channels.Channel]ChannelAuthConfig{
+			channels.SyncCommittee: {
+				AuthorizedRoles:  flow.RoleList{flow.RoleConsensus},
+				AllowedProtocols: Protocols{ProtocolTypeUnicast},
+			},
+		},
+	}
 
 	// cluster consensus
 	authorizationConfigs[ClusterBlockProposal] = MsgAuthConfig{
@@ -423,6 +447,10 @@ func GetMessageAuthConfig(v interface{}) (MsgAuthConfig, error) {
 		return authorizationConfigs[BatchRequest], nil
 	case *messages.BlockResponse:
 		return authorizationConfigs[BlockResponse], nil
+	case *messages.HeaderRangeRequest:
+		return authorizationConfigs[HeaderRangeRequest], nil
+	case *messages.HeaderResponse:
+		return authorizationConfigs[HeaderResponse], nil
 
 	// cluster consensus
 	case *messages.ClusterBlockProposal:
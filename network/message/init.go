@@ -68,4 +68,6 @@ const (
 	EntityResponse       = "EntityResponse"
 	TestMessage          = "TestMessage"
 	DKGMessage           = "DKGMessage"
+	HeaderRangeRequest   = "HeaderRangeRequest"
+	HeaderResponse       = "HeaderResponse"
 )
@@ -0,0 +1,64 @@
+package badger
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// SimulateEpochTransition checks the given EpochSetup and EpochCommit service events, as if they were
+// sealed in a child of the latest finalized block. The events are validated with the same checks
+// applied when extending the state, and every failure is reported, rather than only the first one.
+// The state is not modified. Either event may be nil, in which case it is not checked; when simulating
+// only the EpochCommit event, the next epoch's EpochSetup event must already be part of the state.
+// Returns an empty slice if the service events are valid.
+// No errors are expected during normal operations.
+func (state *State) SimulateEpochTransition(setup *flow.EpochSetup, commit *flow.EpochCommit) ([]error, error) {
+	final, err := state.Final().Head()
+	if err != nil {
+		return nil, fmt.Errorf("could not get finalized block: %w", err)
+	}
+	status, err := state.epoch.statuses.ByBlockID(final.ID())
+	if err != nil {
+		return nil, fmt.Errorf("could not get epoch status of finalized block: %w", err)
+	}
+	activeSetup, err := state.epoch.setups.ByID(status.CurrentEpoch.SetupID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get current epoch setup event: %w", err)
+	}
+	epochFallbackTriggered, err := state.isEpochEmergencyFallbackTriggered()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve epoch fallback status: %w", err)
+	}
+
+	var violations []error
+
+	// service events are not processed at all after epoch fallback is triggered
+	if epochFallbackTriggered || status.InvalidServiceEventIncorporated {
+		violations = append(violations, fmt.Errorf("epoch fallback mode is triggered, service events are ignored"))
+	}
+
+	// IMPORTANT: copy the status to avoid modifying the status in the cache
+	simulated := status.Copy()
+
+	if setup != nil {
+		violations = append(violations, extendingEpochSetupViolations(setup, activeSetup, simulated)...)
+		simulated.NextEpoch.SetupID = setup.ID()
+	}
+
+	if commit != nil {
+		extendingSetup := setup
+		if extendingSetup == nil {
+			if simulated.NextEpoch.SetupID == flow.ZeroID {
+				return append(violations, fmt.Errorf("missing epoch setup for epoch commit")), nil
+			}
+			extendingSetup, err = state.epoch.setups.ByID(simulated.NextEpoch.SetupID)
+			if err != nil {
+				return nil, fmt.Errorf("could not get next epoch setup event: %w", err)
+			}
+		}
+		violations = append(violations, extendingEpochCommitViolations(commit, extendingSetup, activeSetup, simulated)...)
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,80 @@
+package badger_test
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	protocol "github.com/onflow/flow-go/state/protocol/badger"
+	"github.com/onflow/flow-go/state/protocol/util"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestSimulateEpochTransition tests that proposed epoch service events are checked against the
+// current protocol state, and that all failures are reported.
+func TestSimulateEpochTransition(t *testing.T) {
+	rootSnapshot := unittest.RootSnapshotFixture(participants)
+	result, _, err := rootSnapshot.SealedResult()
+	require.NoError(t, err)
+	rootSetup := result.ServiceEvents[0].Event.(*flow.EpochSetup)
+
+	validEvents := func() (*flow.EpochSetup, *flow.EpochCommit) {
+		setup := unittest.EpochSetupFixture(
+			unittest.WithParticipants(rootSetup.Participants),
+			unittest.SetupWithCounter(rootSetup.Counter+1),
+			unittest.WithFinalView(rootSetup.FinalView+1000),
+			unittest.WithFirstView(rootSetup.FinalView+1),
+		)
+		commit := unittest.EpochCommitFixture(
+			unittest.CommitWithCounter(setup.Counter),
+			unittest.WithClusterQCsFromAssignments(setup.Assignments),
+			unittest.WithDKGFromParticipants(rootSetup.Participants),
+		)
+		return setup, commit
+	}
+
+	util.RunWithFullProtocolState(t, rootSnapshot, func(db *badger.DB, state *protocol.ParticipantState) {
+		t.Run("valid events", func(t *testing.T) {
+			setup, commit := validEvents()
+			violations, err := state.SimulateEpochTransition(setup, commit)
+			require.NoError(t, err)
+			assert.Empty(t, violations)
+		})
+
+		t.Run("all failures are reported", func(t *testing.T) {
+			setup, commit := validEvents()
+			// invalid view range
+			setup.FirstView = rootSetup.FinalView + 2
+			// invalid cluster assignment, a collector appears in two clusters
+			setup.Assignments = append(setup.Assignments, setup.Assignments[0])
+			// missing DKG participant key
+			commit.DKGParticipantKeys = commit.DKGParticipantKeys[1:]
+
+			violations, err := state.SimulateEpochTransition(setup, commit)
+			require.NoError(t, err)
+			// the cluster QCs don't match the clusters either
+			assert.Len(t, violations, 4)
+		})
+
+		t.Run("commit without setup", func(t *testing.T) {
+			_, commit := validEvents()
+			violations, err := state.SimulateEpochTransition(nil, commit)
+			require.NoError(t, err)
+			require.Len(t, violations, 1)
+			assert.Contains(t, violations[0].Error(), "missing epoch setup")
+		})
+
+		t.Run("epoch fallback triggered", func(t *testing.T) {
+			require.NoError(t, db.Update(operation.SetEpochEmergencyFallbackTriggered(flow.ZeroID)))
+
+			setup, commit := validEvents()
+			violations, err := state.SimulateEpochTransition(setup, commit)
+			require.NoError(t, err)
+			assert.Len(t, violations, 1)
+		})
+	})
+}
@@ -22,33 +22,45 @@ import (
 // Expected errors during normal operations:
 // * protocol.InvalidServiceEventError if the input service event is invalid to extend the currently active epoch status
 func isValidExtendingEpochSetup(extendingSetup *flow.EpochSetup, activeSetup *flow.EpochSetup, status *flow.EpochStatus) error {
+	violations := extendingEpochSetupViolations(extendingSetup, activeSetup, status)
+	if len(violations) > 0 {
+		return protocol.NewInvalidServiceEventErrorf("%w", violations[0])
+	}
+	return nil
+}
+
+// extendingEpochSetupViolations returns all reasons why the epoch setup service event is invalid
+// to extend the currently active epoch status, in the order in which they are checked.
+// Returns an empty slice if the service event is valid.
+func extendingEpochSetupViolations(extendingSetup *flow.EpochSetup, activeSetup *flow.EpochSetup, status *flow.EpochStatus) []error {
+	var violations []error
+
 	// We should only have a single epoch setup event per epoch.
 	if status.NextEpoch.SetupID != flow.ZeroID {
 		// true iff EpochSetup event for NEXT epoch was already included before
-		return protocol.NewInvalidServiceEventErrorf("duplicate epoch setup service event: %x", status.NextEpoch.SetupID)
+		violations = append(violations, fmt.Errorf("duplicate epoch setup service event: %x", status.NextEpoch.SetupID))
 	}
 
 	// The setup event should have the counter increased by one.
 	if extendingSetup.Counter != activeSetup.Counter+1 {
-		return protocol.NewInvalidServiceEventErrorf("next epoch setup has invalid counter (%d => %d)", activeSetup.Counter, extendingSetup.Counter)
+		violations = append(violations, fmt.Errorf("next epoch setup has invalid counter (%d => %d)", activeSetup.Counter, extendingSetup.Counter))
 	}
 
 	// The first view needs to be exactly one greater than the current epoch final view
 	if extendingSetup.FirstView != activeSetup.FinalView+1 {
-		return protocol.NewInvalidServiceEventErrorf(
+		violations = append(violations, fmt.Errorf(
 			"next epoch first view must be exactly 1 more than current epoch final view (%d != %d+1)",
 			extendingSetup.FirstView,
 			activeSetup.FinalView,
-		)
+		))
 	}
 
 	// Finally, the epoch setup event must contain all necessary information.
-	err := verifyEpochSetup(extendingSetup, true)
-	if err != nil {
-		return protocol.NewInvalidServiceEventErrorf("invalid epoch setup: %w", err)
+	for _, err := range epochSetupViolations(extendingSetup, true) {
+		violations = append(violations, fmt.Errorf("invalid epoch setup: %w", err))
 	}
 
-	return nil
+	return violations
 }
 
 // verifyEpochSetup checks whether an `EpochSetup` event is syntactically correct.
@@ -57,10 +69,23 @@ func isValidExtendingEpochSetup(extendingSetup *flow.EpochSetup, activeSetup *fl
 // This is a side-effect-free function. Any error return indicates that the
 // EpochSetup event is not compliant with protocol rules.
 func verifyEpochSetup(setup *flow.EpochSetup, verifyNetworkAddress bool) error {
+	violations := epochSetupViolations(setup, verifyNetworkAddress)
+	if len(violations) > 0 {
+		return violations[0]
+	}
+	return nil
+}
+
+// epochSetupViolations returns all reasons why an `EpochSetup` event is not syntactically
+// correct, in the order in which they are checked. Returns an empty slice if the event is correct.
+// This is a side-effect-free function.
+func epochSetupViolations(setup *flow.EpochSetup, verifyNetworkAddress bool) []error {
+	var violations []error
+
 	// STEP 1: general sanity checks
 	// the seed needs to be at least minimum length
 	if len(setup.RandomSource) != flow.EpochSetupRandomSourceLength {
-		return fmt.Errorf("seed has incorrect length (%d != %d)", len(setup.RandomSource), flow.EpochSetupRandomSourceLength)
+		violations = append(violations, fmt.Errorf("seed has incorrect length (%d != %d)", len(setup.RandomSource), flow.EpochSetupRandomSourceLength))
 	}
 
 	// STEP 2: sanity checks of all nodes listed as participants
@@ -69,7 +94,7 @@ func verifyEpochSetup(setup *flow.EpochSetup, verifyNetworkAddress bool) error {
 	for _, participant := range setup.Participants {
 		_, ok := identLookup[participant.NodeID]
 		if ok {
-			return fmt.Errorf("duplicate node identifier (%x)", participant.NodeID)
+			violations = append(violations, fmt.Errorf("duplicate node identifier (%x)", participant.NodeID))
 		}
 		identLookup[participant.NodeID] = struct{}{}
 	}
@@ -80,7 +105,7 @@ func verifyEpochSetup(setup *flow.EpochSetup, verifyNetworkAddress bool) error {
 		for _, participant := range setup.Participants {
 			_, ok := addrLookup[participant.Address]
 			if ok {
-				return fmt.Errorf("duplicate node address (%x)", participant.Address)
+				violations = append(violations, fmt.Errorf("duplicate node address (%x)", participant.Address))
 			}
 			addrLookup[participant.Address] = struct{}{}
 		}
@@ -88,7 +113,7 @@ func verifyEpochSetup(setup *flow.EpochSetup, verifyNetworkAddress bool) error {
 
 	// the participants must be listed in canonical order
 	if !setup.Participants.Sorted(order.Canonical) {
-		return fmt.Errorf("participants are not canonically ordered")
+		violations = append(violations, fmt.Errorf("participants are not canonically ordered"))
 	}
 
 	// STEP 3: sanity checks for individual roles
@@ -102,35 +127,36 @@ func verifyEpochSetup(setup *flow.EpochSetup, verifyNetworkAddress bool) error {
 		roles[participant.Role]++
 	}
 	if roles[flow.RoleConsensus] < 1 {
-		return fmt.Errorf("need at least one consensus node")
+		violations = append(violations, fmt.Errorf("need at least one consensus node"))
 	}
 	if roles[flow.RoleCollection] < 1 {
-		return fmt.Errorf("need at least one collection node")
+		violations = append(violations, fmt.Errorf("need at least one collection node"))
 	}
 	if roles[flow.RoleExecution] < 1 {
-		return fmt.Errorf("need at least one execution node")
+		violations = append(violations, fmt.Errorf("need at least one execution node"))
 	}
 	if roles[flow.RoleVerification] < 1 {
-		return fmt.Errorf("need at least one verification node")
+		violations = append(violations, fmt.Errorf("need at least one verification node"))
 	}
 
 	// first view must be before final view
 	if setup.FirstView >= setup.FinalView {
-		return fmt.Errorf("first view (%d) must be before final view (%d)", setup.FirstView, setup.FinalView)
+		violations = append(violations, fmt.Errorf("first view (%d) must be before final view (%d)", setup.FirstView, setup.FinalView))
 	}
 
 	// we need at least one collection cluster
 	if len(setup.Assignments) == 0 {
-		return fmt.Errorf("need at least one collection cluster")
+		violations = append(violations, fmt.Errorf("need at least one collection cluster"))
+		return violations
 	}
 
 	// the collection cluster assignments need to be valid
 	_, err := factory.NewClusterList(setup.Assignments, activeParticipants.Filter(filter.HasRole(flow.RoleCollection)))
 	if err != nil {
-		return fmt.Errorf("invalid cluster assignments: %w", err)
+		violations = append(violations, fmt.Errorf("invalid cluster assignments: %w", err))
 	}
 
-	return nil
+	return violations
 }
 
 // isValidExtendingEpochCommit checks whether an epoch commit service being
@@ -141,29 +167,40 @@ func verifyEpochSetup(setup *flow.EpochSetup, verifyNetworkAddress bool) error {
 // Expected errors during normal operations:
 // * protocol.InvalidServiceEventError if the input service event is invalid to extend the currently active epoch status
 func isValidExtendingEpochCommit(extendingCommit *flow.EpochCommit, extendingSetup *flow.EpochSetup, activeSetup *flow.EpochSetup, status *flow.EpochStatus) error {
+	violations := extendingEpochCommitViolations(extendingCommit, extendingSetup, activeSetup, status)
+	if len(violations) > 0 {
+		return protocol.NewInvalidServiceEventErrorf("%w", violations[0])
+	}
+	return nil
+}
+
+// extendingEpochCommitViolations returns all reasons why the epoch commit service event is invalid
+// to extend the currently active epoch status, in the order in which they are checked.
+// Returns an empty slice if the service event is valid.
+func extendingEpochCommitViolations(extendingCommit *flow.EpochCommit, extendingSetup *flow.EpochSetup, activeSetup *flow.EpochSetup, status *flow.EpochStatus) []error {
+	var violations []error
 
 	// We should only have a single epoch commit event per epoch.
 	if status.NextEpoch.CommitID != flow.ZeroID {
 		// true iff EpochCommit event for NEXT epoch was already included before
-		return protocol.NewInvalidServiceEventErrorf("duplicate epoch commit service event: %x", status.NextEpoch.CommitID)
+		violations = append(violations, fmt.Errorf("duplicate epoch commit service event: %x", status.NextEpoch.CommitID))
 	}
 
 	// The epoch setup event needs to happen before the commit.
 	if status.NextEpoch.SetupID == flow.ZeroID {
-		return protocol.NewInvalidServiceEventErrorf("missing epoch setup for epoch commit")
+		violations = append(violations, fmt.Errorf("missing epoch setup for epoch commit"))
 	}
 
 	// The commit event should have the counter increased by one.
 	if extendingCommit.Counter != activeSetup.Counter+1 {
-		return protocol.NewInvalidServiceEventErrorf("next epoch commit has invalid counter (%d => %d)", activeSetup.Counter, extendingCommit.Counter)
+		violations = append(violations, fmt.Errorf("next epoch commit has invalid counter (%d => %d)", activeSetup.Counter, extendingCommit.Counter))
 	}
 
-	err := isValidEpochCommit(extendingCommit, extendingSetup)
-	if err != nil {
-		return protocol.NewInvalidServiceEventErrorf("invalid epoch commit: %s", err)
+	for _, err := range epochCommitViolations(extendingCommit, extendingSetup) {
+		violations = append(violations, fmt.Errorf("invalid epoch commit: %w", err))
 	}
 
-	return nil
+	return violations
 }
 
 // isValidEpochCommit checks whether an epoch commit service event is intrinsically valid.
@@ -171,26 +208,37 @@ func isValidExtendingEpochCommit(extendingCommit *flow.EpochCommit, extendingSet
 // Expected errors during normal operations:
 // * protocol.InvalidServiceEventError if the EpochCommit is invalid
 func isValidEpochCommit(commit *flow.EpochCommit, setup *flow.EpochSetup) error {
+	violations := epochCommitViolations(commit, setup)
+	if len(violations) > 0 {
+		return protocol.NewInvalidServiceEventErrorf("%w", violations[0])
+	}
+	return nil
+}
+
+// epochCommitViolations returns all reasons why an epoch commit service event is not intrinsically
+// valid, in the order in which they are checked. Returns an empty slice if the event is valid.
+func epochCommitViolations(commit *flow.EpochCommit, setup *flow.EpochSetup) []error {
+	var violations []error
 
 	if len(setup.Assignments) != len(commit.ClusterQCs) {
-		return protocol.NewInvalidServiceEventErrorf("number of clusters (%d) does not number of QCs (%d)", len(setup.Assignments), len(commit.ClusterQCs))
+		violations = append(violations, fmt.Errorf("number of clusters (%d) does not number of QCs (%d)", len(setup.Assignments), len(commit.ClusterQCs)))
 	}
 
 	if commit.Counter != setup.Counter {
-		return protocol.NewInvalidServiceEventErrorf("inconsistent epoch counter between commit (%d) and setup (%d) events in same epoch", commit.Counter, setup.Counter)
+		violations = append(violations, fmt.Errorf("inconsistent epoch counter between commit (%d) and setup (%d) events in same epoch", commit.Counter, setup.Counter))
 	}
 
 	// make sure we have a valid DKG public key
 	if commit.DKGGroupKey == nil {
-		return protocol.NewInvalidServiceEventErrorf("missing DKG public group key")
+		violations = append(violations, fmt.Errorf("missing DKG public group key"))
 	}
 
 	participants := setup.Participants.Filter(filter.IsValidDKGParticipant)
 	if len(participants) != len(commit.DKGParticipantKeys) {
-		return protocol.NewInvalidServiceEventErrorf("participant list (len=%d) does not match dkg key list (len=%d)", len(participants), len(commit.DKGParticipantKeys))
+		violations = append(violations, fmt.Errorf("participant list (len=%d) does not match dkg key list (len=%d)", len(participants), len(commit.DKGParticipantKeys)))
 	}
 
-	return nil
+	return violations
 }
 
 // IsValidRootSnapshot checks internal consistency of root state snapshot
@@ -0,0 +1,71 @@
+package badger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/module/updatable_configs"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// ConfigOverrides persists the config values set through the updatable config manager in the node's database.
+type ConfigOverrides struct {
+	db *badger.DB
+}
+
+var _ updatable_configs.Store = (*ConfigOverrides)(nil)
+
+func NewConfigOverrides(db *badger.DB) *ConfigOverrides {
+	return &ConfigOverrides{db: db}
+}
+
+func (s *ConfigOverrides) Overrides() (map[string]json.RawMessage, error) {
+	var stored map[string][]byte
+	err := s.db.View(operation.RetrieveConfigOverrides(&stored))
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("could not retrieve config overrides: %w", err)
+	}
+
+	overrides := make(map[string]json.RawMessage, len(stored))
+	for name, value := range stored {
+		overrides[name] = value
+	}
+	return overrides, nil
+}
+
+func (s *ConfigOverrides) SetOverride(name string, value json.RawMessage) error {
+	return s.update(func(stored map[string][]byte) {
+		stored[name] = value
+	})
+}
+
+func (s *ConfigOverrides) DeleteOverride(name string) error {
+	return s.update(func(stored map[string][]byte) {
+		delete(stored, name)
+	})
+}
+
+// update applies the given change to the stored overrides in a single transaction.
+func (s *ConfigOverrides) update(change func(stored map[string][]byte)) error {
+	return s.db.Update(func(tx *badger.Txn) error {
+		var stored map[string][]byte
+		err := operation.RetrieveConfigOverrides(&stored)(tx)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("could not retrieve config overrides: %w", err)
+		}
+		if stored == nil {
+			stored = make(map[string][]byte)
+		}
+
+		change(stored)
+		err = operation.PersistConfigOverrides(stored)(tx)
+		if err != nil {
+			return fmt.Errorf("could not persist config overrides: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,103 @@
+package badger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/dkg"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// DKGTranscripts stores the transcripts of the DKGs run by this node. Events of a transcript are
+// stored by index, and the number of events is tracked separately, like for job queues.
+type DKGTranscripts struct {
+	db *badger.DB
+	mu sync.Mutex // serializes appends, which read and update the event count
+}
+
+var _ storage.DKGTranscripts = (*DKGTranscripts)(nil)
+
+func NewDKGTranscripts(db *badger.DB) *DKGTranscripts {
+	return &DKGTranscripts{db: db}
+}
+
+func (t *DKGTranscripts) InitTranscript(info *dkg.TranscriptInfo) error {
+	err := operation.RetryOnConflict(t.db.Update, operation.UpsertDKGTranscriptInfo(info))
+	if err != nil {
+		return fmt.Errorf("could not store dkg transcript info: %w", err)
+	}
+	return nil
+}
+
+func (t *DKGTranscripts) AppendEvent(dkgInstanceID string, event *dkg.TranscriptEvent) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.db.Update(func(tx *badger.Txn) error {
+		var info dkg.TranscriptInfo
+		err := operation.RetrieveDKGTranscriptInfo(dkgInstanceID, &info)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve dkg transcript info: %w", err)
+		}
+
+		count, err := t.eventCount(tx, dkgInstanceID)
+		if err != nil {
+			return err
+		}
+
+		err = operation.InsertDKGTranscriptEvent(dkgInstanceID, count, event)(tx)
+		if err != nil {
+			return fmt.Errorf("could not insert dkg transcript event: %w", err)
+		}
+		err = operation.SetDKGTranscriptEventCount(dkgInstanceID, count+1)(tx)
+		if err != nil {
+			return fmt.Errorf("could not update dkg transcript event count: %w", err)
+		}
+		return nil
+	})
+}
+
+func (t *DKGTranscripts) ByInstanceID(dkgInstanceID string) (*dkg.Transcript, error) {
+	var transcript dkg.Transcript
+	err := t.db.View(func(tx *badger.Txn) error {
+		err := operation.RetrieveDKGTranscriptInfo(dkgInstanceID, &transcript.Info)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve dkg transcript info: %w", err)
+		}
+
+		count, err := t.eventCount(tx, dkgInstanceID)
+		if err != nil {
+			return err
+		}
+
+		transcript.Events = make([]dkg.TranscriptEvent, count)
+		for i := uint64(0); i < count; i++ {
+			err = operation.RetrieveDKGTranscriptEvent(dkgInstanceID, i, &transcript.Events[i])(tx)
+			if err != nil {
+				return fmt.Errorf("could not retrieve dkg transcript event %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transcript, nil
+}
+
+// eventCount returns the number of events in the given transcript, which is zero if no event was appended yet.
+func (t *DKGTranscripts) eventCount(tx *badger.Txn, dkgInstanceID string) (uint64, error) {
+	var count uint64
+	err := operation.RetrieveDKGTranscriptEventCount(dkgInstanceID, &count)(tx)
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("could not retrieve dkg transcript event count: %w", err)
+	}
+	return count, nil
+}
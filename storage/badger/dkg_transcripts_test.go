@@ -0,0 +1,61 @@
+package badger_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/dkg"
+	"github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestDKGTranscripts(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		transcripts := bstorage.NewDKGTranscripts(db)
+
+		_, err := transcripts.ByInstanceID("dkg-test-1")
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		err = transcripts.AppendEvent("dkg-test-1", &dkg.TranscriptEvent{Type: dkg.TranscriptBroadcastSent})
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		info := &dkg.TranscriptInfo{
+			DKGInstanceID: "dkg-test-1",
+			Committee:     unittest.IdentifierListFixture(3),
+			MyIndex:       1,
+			StartedAt:     time.Now().UTC().Truncate(time.Second),
+		}
+		require.NoError(t, transcripts.InitTranscript(info))
+		// a transcript with an instance ID which has the other one as prefix must not be mixed up with it
+		require.NoError(t, transcripts.InitTranscript(&dkg.TranscriptInfo{DKGInstanceID: "dkg-test-12"}))
+
+		events := []dkg.TranscriptEvent{
+			{Type: dkg.TranscriptPrivateSent, Kind: dkg.MessageShare, From: 1, To: 0, Subject: dkg.NoParticipant, Size: 33},
+			{Type: dkg.TranscriptBroadcastReceived, Kind: dkg.MessageComplaint, From: 2, To: dkg.NoParticipant, Subject: 0, Size: 2},
+			{Type: dkg.TranscriptDisqualified, From: 1, To: dkg.NoParticipant, Subject: 0, Reason: "complaint not answered"},
+		}
+		for i := range events {
+			require.NoError(t, transcripts.AppendEvent("dkg-test-1", &events[i]))
+		}
+		require.NoError(t, transcripts.AppendEvent("dkg-test-12", &dkg.TranscriptEvent{Type: dkg.TranscriptBroadcastSent}))
+
+		transcript, err := transcripts.ByInstanceID("dkg-test-1")
+		require.NoError(t, err)
+		assert.Equal(t, info.Committee, transcript.Info.Committee)
+		assert.Equal(t, info.MyIndex, transcript.Info.MyIndex)
+		assert.True(t, info.StartedAt.Equal(transcript.Info.StartedAt))
+		assert.Equal(t, events, transcript.Events)
+
+		// re-initializing keeps the recorded events
+		require.NoError(t, transcripts.InitTranscript(info))
+		transcript, err = transcripts.ByInstanceID("dkg-test-1")
+		require.NoError(t, err)
+		assert.Len(t, transcript.Events, len(events))
+	})
+}
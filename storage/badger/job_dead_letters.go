@@ -0,0 +1,116 @@
+package badger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// JobDeadLetters stores the dead-lettered jobs of a job consumer. Dead-lettered jobs are expected
+// to be rare, so the jobs of a consumer are stored together in a single entry.
+type JobDeadLetters struct {
+	db       *badger.DB
+	consumer string     // to distinguish the dead-lettered jobs of different consumers
+	mu       sync.Mutex // serializes updates, which read and write the whole entry
+}
+
+var _ storage.JobDeadLetters = (*JobDeadLetters)(nil)
+
+func NewJobDeadLetters(db *badger.DB, consumer string) *JobDeadLetters {
+	return &JobDeadLetters{
+		db:       db,
+		consumer: consumer,
+	}
+}
+
+func (d *JobDeadLetters) Add(job *storage.DeadLetteredJob) error {
+	return d.update(func(jobs map[uint64]*storage.DeadLetteredJob) error {
+		jobs[job.Index] = job
+		return nil
+	})
+}
+
+func (d *JobDeadLetters) ByIndex(index uint64) (*storage.DeadLetteredJob, error) {
+	jobs, err := d.all()
+	if err != nil {
+		return nil, err
+	}
+	job, ok := jobs[index]
+	if !ok {
+		return nil, fmt.Errorf("no dead-lettered job at index %d: %w", index, storage.ErrNotFound)
+	}
+	return job, nil
+}
+
+func (d *JobDeadLetters) Remove(index uint64) error {
+	return d.update(func(jobs map[uint64]*storage.DeadLetteredJob) error {
+		if _, ok := jobs[index]; !ok {
+			return fmt.Errorf("no dead-lettered job at index %d: %w", index, storage.ErrNotFound)
+		}
+		delete(jobs, index)
+		return nil
+	})
+}
+
+func (d *JobDeadLetters) All() ([]*storage.DeadLetteredJob, error) {
+	jobs, err := d.all()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make([]*storage.DeadLetteredJob, 0, len(jobs))
+	for _, job := range jobs {
+		all = append(all, job)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Index < all[j].Index
+	})
+	return all, nil
+}
+
+// all returns the dead-lettered jobs keyed by index, which is empty if no job was dead-lettered yet.
+func (d *JobDeadLetters) all() (map[uint64]*storage.DeadLetteredJob, error) {
+	var jobs map[uint64]*storage.DeadLetteredJob
+	err := d.db.View(operation.RetrieveJobDeadLetters(d.consumer, &jobs))
+	if errors.Is(err, storage.ErrNotFound) {
+		return map[uint64]*storage.DeadLetteredJob{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve dead-lettered jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// update applies the given modification to the dead-lettered jobs.
+func (d *JobDeadLetters) update(modify func(map[uint64]*storage.DeadLetteredJob) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.db.Update(func(tx *badger.Txn) error {
+		var jobs map[uint64]*storage.DeadLetteredJob
+		err := operation.RetrieveJobDeadLetters(d.consumer, &jobs)(tx)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("could not retrieve dead-lettered jobs: %w", err)
+		}
+		if jobs == nil {
+			jobs = make(map[uint64]*storage.DeadLetteredJob)
+		}
+
+		err = modify(jobs)
+		if err != nil {
+			return err
+		}
+
+		err = operation.PersistJobDeadLetters(d.consumer, jobs)(tx)
+		if err != nil {
+			return fmt.Errorf("could not persist dead-lettered jobs: %w", err)
+		}
+		return nil
+	})
+}
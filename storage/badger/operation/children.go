@@ -20,3 +20,9 @@ func UpdateBlockChildren(blockID flow.Identifier, childrenIDs flow.IdentifierLis
 func RetrieveBlockChildren(blockID flow.Identifier, childrenIDs *flow.IdentifierList) func(*badger.Txn) error {
 	return retrieve(makePrefix(codeBlockChildren, blockID), childrenIDs)
 }
+
+// BatchRemoveBlockChildren removes the children index of a block in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveBlockChildren(blockID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeBlockChildren, blockID))
+}
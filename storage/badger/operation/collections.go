@@ -44,3 +44,21 @@ func IndexCollectionByTransaction(txID flow.Identifier, collectionID flow.Identi
 func RetrieveCollectionID(txID flow.Identifier, collectionID *flow.Identifier) func(*badger.Txn) error {
 	return retrieve(makePrefix(codeIndexCollectionByTransaction, txID), collectionID)
 }
+
+// BatchRemoveCollection removes a light collection in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveCollection(collID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeCollection, collID))
+}
+
+// BatchRemoveCollectionByTransaction removes the transaction-to-collection index in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveCollectionByTransaction(txID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeIndexCollectionByTransaction, txID))
+}
+
+// BatchRemoveCollectionBlock removes the collection-to-block index in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveCollectionBlock(collID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeCollectionBlock, collID))
+}
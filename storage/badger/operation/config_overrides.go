@@ -0,0 +1,18 @@
+package operation
+
+import (
+	"github.com/dgraph-io/badger/v2"
+)
+
+// PersistConfigOverrides writes the runtime config overrides, keyed by config name, into the data base.
+// If an entry already exists, it is overwritten; otherwise a new entry is created.
+// No errors are expected during normal operations.
+func PersistConfigOverrides(overrides map[string][]byte) func(*badger.Txn) error {
+	return upsert(makePrefix(codeConfigOverrides), overrides)
+}
+
+// RetrieveConfigOverrides reads the runtime config overrides, keyed by config name, from the data base.
+// Returns `storage.ErrNotFound` error in case no respective data base entry is present.
+func RetrieveConfigOverrides(overrides *map[string][]byte) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeConfigOverrides), overrides)
+}
@@ -0,0 +1,43 @@
+package operation
+
+import (
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/dkg"
+)
+
+// UpsertDKGTranscriptInfo writes the info of the transcript of the given DKG instance.
+// No errors are expected during normal operations.
+func UpsertDKGTranscriptInfo(info *dkg.TranscriptInfo) func(*badger.Txn) error {
+	return upsert(makePrefix(codeDKGTranscriptInfo, info.DKGInstanceID), info)
+}
+
+// RetrieveDKGTranscriptInfo reads the info of the transcript of the given DKG instance.
+// Returns `storage.ErrNotFound` if the transcript was not initialized.
+func RetrieveDKGTranscriptInfo(dkgInstanceID string, info *dkg.TranscriptInfo) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeDKGTranscriptInfo, dkgInstanceID), info)
+}
+
+// RetrieveDKGTranscriptEventCount reads the number of events in the transcript of the given DKG instance.
+// Returns `storage.ErrNotFound` if no event was appended yet.
+func RetrieveDKGTranscriptEventCount(dkgInstanceID string, count *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeDKGTranscriptPointer, dkgInstanceID), count)
+}
+
+// SetDKGTranscriptEventCount writes the number of events in the transcript of the given DKG instance.
+// No errors are expected during normal operations.
+func SetDKGTranscriptEventCount(dkgInstanceID string, count uint64) func(*badger.Txn) error {
+	return upsert(makePrefix(codeDKGTranscriptPointer, dkgInstanceID), count)
+}
+
+// InsertDKGTranscriptEvent writes the transcript event at the given index.
+// Returns `storage.ErrAlreadyExists` if an event is already stored at the index.
+func InsertDKGTranscriptEvent(dkgInstanceID string, index uint64, event *dkg.TranscriptEvent) func(*badger.Txn) error {
+	return insert(makePrefix(codeDKGTranscriptEvent, dkgInstanceID, index), event)
+}
+
+// RetrieveDKGTranscriptEvent reads the transcript event at the given index.
+// Returns `storage.ErrNotFound` if no event is stored at the index.
+func RetrieveDKGTranscriptEvent(dkgInstanceID string, index uint64, event *dkg.TranscriptEvent) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeDKGTranscriptEvent, dkgInstanceID, index), event)
+}
@@ -73,3 +73,9 @@ func CheckEpochEmergencyFallbackTriggered(triggered *bool) func(*badger.Txn) err
 		return err
 	}
 }
+
+// BatchRemoveEpochStatus removes the epoch status of a block in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveEpochStatus(blockID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeBlockEpochStatus, blockID))
+}
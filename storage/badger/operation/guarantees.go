@@ -21,3 +21,15 @@ func IndexPayloadGuarantees(blockID flow.Identifier, guarIDs []flow.Identifier)
 func LookupPayloadGuarantees(blockID flow.Identifier, guarIDs *[]flow.Identifier) func(*badger.Txn) error {
 	return retrieve(makePrefix(codePayloadGuarantees, blockID), guarIDs)
 }
+
+// BatchRemoveGuarantee removes a collection guarantee in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveGuarantee(collID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeGuarantee, collID))
+}
+
+// BatchRemovePayloadGuarantees removes the payload guarantees index of a block in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemovePayloadGuarantees(blockID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codePayloadGuarantees, blockID))
+}
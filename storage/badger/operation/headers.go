@@ -75,3 +75,15 @@ func FindHeaders(filter func(header *flow.Header) bool, found *[]flow.Header) fu
 		return check, create, handle
 	})
 }
+
+// BatchRemoveHeader removes the header of the given block in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveHeader(blockID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeHeader, blockID))
+}
+
+// BatchRemoveBlockHeight removes the height index of a finalized block in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveBlockHeight(height uint64) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeHeightToBlock, height))
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/dgraph-io/badger/v2"
 
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
 )
 
 func RetrieveJobLatestIndex(queue string, index *uint64) func(*badger.Txn) error {
@@ -41,3 +42,15 @@ func InsertProcessedIndex(jobName string, processed uint64) func(*badger.Txn) er
 func SetProcessedIndex(jobName string, processed uint64) func(*badger.Txn) error {
 	return update(makePrefix(codeJobConsumerProcessed, jobName), processed)
 }
+
+// PersistJobDeadLetters writes the dead-lettered jobs of a job consumer, keyed by job index.
+// If an entry already exists, it is overwritten; otherwise a new entry is created.
+func PersistJobDeadLetters(consumer string, jobs map[uint64]*storage.DeadLetteredJob) func(*badger.Txn) error {
+	return upsert(makePrefix(codeJobDeadLetters, consumer), jobs)
+}
+
+// RetrieveJobDeadLetters reads the dead-lettered jobs of a job consumer, keyed by job index.
+// Returns `storage.ErrNotFound` if no job was ever dead-lettered by the consumer.
+func RetrieveJobDeadLetters(consumer string, jobs *map[uint64]*storage.DeadLetteredJob) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeJobDeadLetters, consumer), jobs)
+}
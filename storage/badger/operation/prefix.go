@@ -33,6 +33,8 @@ const (
 	codeRootHeight              = 24 // the height of the highest block contained in the root snapshot
 	codeLastCompleteBlockHeight = 25 // the height of the last block for which all collections were received
 	codeEpochFirstHeight        = 26 // the height of the first block in a given epoch
+	// 27 to 29 are used for the heights of the execution state registers in storage/operation,
+	// 108 is used for the registers themselves, 109 to 111 for the historical registers of execution nodes
 
 	// codes for single entity storage
 	// 31 was used for identities before epochs
@@ -74,6 +76,9 @@ const (
 	codeDKGEnded         = 65 // flag that the DKG for an epoch has ended (stores end state)
 	codeVersionBeacon    = 67 // flag for storing version beacons
 
+	// runtime config overrides set through the admin API, which are re-applied on restart
+	codeConfigOverrides = 68
+
 	// code for ComputationResult upload status storage
 	// NOTE: for now only GCP uploader is supported. When other uploader (AWS e.g.) needs to
 	//		 be supported, we will need to define new code.
@@ -83,6 +88,12 @@ const (
 	codeJobConsumerProcessed = 70
 	codeJobQueue             = 71
 	codeJobQueuePointer      = 72
+	codeJobDeadLetters       = 76
+
+	// DKG transcripts, keyed by DKG instance ID
+	codeDKGTranscriptInfo    = 73
+	codeDKGTranscriptEvent   = 74
+	codeDKGTranscriptPointer = 75
 
 	// legacy codes (should be cleaned up)
 	codeChunkDataPack                = 100
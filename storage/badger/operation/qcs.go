@@ -17,3 +17,9 @@ func InsertQuorumCertificate(qc *flow.QuorumCertificate) func(*badger.Txn) error
 func RetrieveQuorumCertificate(blockID flow.Identifier, qc *flow.QuorumCertificate) func(*badger.Txn) error {
 	return retrieve(makePrefix(codeBlockIDToQuorumCertificate, blockID), qc)
 }
+
+// BatchRemoveQuorumCertificate removes the quorum certificate of a block in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveQuorumCertificate(blockID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeBlockIDToQuorumCertificate, blockID))
+}
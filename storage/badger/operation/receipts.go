@@ -85,3 +85,17 @@ func receiptIterationFunc(receiptIDs *[]flow.Identifier) func() (checkFunc, crea
 		return check, create, handle
 	}
 }
+
+// BatchRemoveExecutionReceiptMeta removes an execution receipt meta in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveExecutionReceiptMeta(receiptID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeExecutionReceiptMeta, receiptID))
+}
+
+// BatchRemoveExecutionReceipts removes all execution receipt index entries of a block in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveExecutionReceipts(blockID flow.Identifier, batch *badger.WriteBatch) func(*badger.Txn) error {
+	return func(txn *badger.Txn) error {
+		return batchRemoveByPrefix(makePrefix(codeAllBlockReceipts, blockID))(txn, batch)
+	}
+}
@@ -52,3 +52,9 @@ func RemoveExecutionResultIndex(blockID flow.Identifier) func(*badger.Txn) error
 func BatchRemoveExecutionResultIndex(blockID flow.Identifier) func(*badger.WriteBatch) error {
 	return batchRemove(makePrefix(codeIndexExecutionResultByBlock, blockID))
 }
+
+// BatchRemoveExecutionResult removes an execution result in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveExecutionResult(resultID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeExecutionResult, resultID))
+}
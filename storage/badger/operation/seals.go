@@ -75,3 +75,35 @@ func RemoveExecutionForkEvidence() func(*badger.Txn) error {
 func RetrieveExecutionForkEvidence(conflictingSeals *[]*flow.IncorporatedResultSeal) func(*badger.Txn) error {
 	return retrieve(makePrefix(codeExecutionFork), conflictingSeals)
 }
+
+// BatchRemoveSeal removes a seal in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveSeal(sealID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeSeal, sealID))
+}
+
+// BatchRemovePayloadIndexes removes the payload seal, receipt and result indexes of a block in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemovePayloadIndexes(blockID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return func(batch *badger.WriteBatch) error {
+		for _, code := range []byte{codePayloadSeals, codePayloadReceipts, codePayloadResults} {
+			err := batchRemove(makePrefix(code, blockID))(batch)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// BatchRemoveLatestSealAtBlock removes the latest seal index of a block in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveLatestSealAtBlock(blockID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeBlockIDToLatestSealID, blockID))
+}
+
+// BatchRemoveFinalizedSealByBlockID removes the finalized seal index of a sealed block in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveFinalizedSealByBlockID(sealedBlockID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeBlockIDToFinalizedSeal, sealedBlockID))
+}
@@ -101,3 +101,17 @@ func BatchRemoveTransactionResultsByBlockID(blockID flow.Identifier, batch *badg
 		return nil
 	}
 }
+
+// BatchRemoveTransactionResultIndexByBlockID removes the transaction results indexed by
+// transaction index for the given blockID in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveTransactionResultIndexByBlockID(blockID flow.Identifier, batch *badger.WriteBatch) func(*badger.Txn) error {
+	return func(txn *badger.Txn) error {
+		prefix := makePrefix(codeTransactionResultIndex, blockID)
+		err := batchRemoveByPrefix(prefix)(txn, batch)
+		if err != nil {
+			return fmt.Errorf("could not remove transaction result index for block %v: %w", blockID, err)
+		}
+		return nil
+	}
+}
@@ -15,3 +15,9 @@ func InsertTransaction(txID flow.Identifier, tx *flow.TransactionBody) func(*bad
 func RetrieveTransaction(txID flow.Identifier, tx *flow.TransactionBody) func(*badger.Txn) error {
 	return retrieve(makePrefix(codeTransaction, txID), tx)
 }
+
+// BatchRemoveTransaction removes a transaction in a provided batch.
+// No errors are expected during normal operation, even if no entries are matched.
+func BatchRemoveTransaction(txID flow.Identifier) func(batch *badger.WriteBatch) error {
+	return batchRemove(makePrefix(codeTransaction, txID))
+}
@@ -0,0 +1,694 @@
+package badger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// PruningCategory is a category of block data which is pruned independently.
+type PruningCategory string
+
+const (
+	PruneHeaders            PruningCategory = "headers"
+	PrunePayloads           PruningCategory = "payloads"
+	PruneCollections        PruningCategory = "collections"
+	PruneEvents             PruningCategory = "events"
+	PruneTransactionResults PruningCategory = "transaction_results"
+	PruneChunkDataPacks     PruningCategory = "chunk_data_packs"
+	PruneReceipts           PruningCategory = "receipts"
+)
+
+// PruningCategories lists all pruning categories in the order they are pruned. Categories which
+// find the data to prune through the indexes of another category are pruned before that category.
+var PruningCategories = []PruningCategory{
+	PruneCollections,
+	PruneEvents,
+	PruneTransactionResults,
+	PruneChunkDataPacks,
+	PruneReceipts,
+	PrunePayloads,
+	PruneHeaders,
+}
+
+// pruningDependents maps each category to the categories which find their data through its indexes.
+// A category is never pruned beyond the progress of its (enabled) dependents, as their data would
+// otherwise not be found anymore.
+var pruningDependents = map[PruningCategory][]PruningCategory{
+	PruneHeaders:  {PrunePayloads, PruneCollections, PruneEvents, PruneTransactionResults, PruneChunkDataPacks, PruneReceipts},
+	PrunePayloads: {PruneCollections},
+	PruneReceipts: {PruneChunkDataPacks},
+}
+
+// ParsePruningCategory returns the pruning category with the given name.
+func ParsePruningCategory(name string) (PruningCategory, error) {
+	for _, category := range PruningCategories {
+		if string(category) == name {
+			return category, nil
+		}
+	}
+	return "", fmt.Errorf("unknown pruning category: %s", name)
+}
+
+// ParsePruningRetention converts the retention of pruning categories by name, as given on the command line,
+// to a retention by pruning category.
+func ParsePruningRetention(retention map[string]int) (map[PruningCategory]uint64, error) {
+	parsed := make(map[PruningCategory]uint64, len(retention))
+	for name, heights := range retention {
+		category, err := ParsePruningCategory(name)
+		if err != nil {
+			return nil, err
+		}
+		if heights < 0 {
+			return nil, fmt.Errorf("invalid retention for pruning category %s: %d", name, heights)
+		}
+		parsed[category] = uint64(heights)
+	}
+	return parsed, nil
+}
+
+// PrunerConfig configures which data the Pruner deletes, and how fast.
+type PrunerConfig struct {
+	// Interval is the interval at which the pruner checks for prunable data.
+	Interval time.Duration
+	// BatchSize is the number of heights which are pruned in a single write batch.
+	BatchSize uint64
+	// Retention is the number of heights below the latest sealed height retained per category.
+	// Categories which are not listed are not pruned.
+	Retention map[PruningCategory]uint64
+}
+
+// Pruner is a component which deletes the data of finalized blocks below a configurable sealed height.
+// Each category of data is pruned up to its own height, in batches of heights, and the pruned height is
+// tracked per category, so that pruning resumes where it left off after a restart.
+//
+// The pruner never deletes data which is still needed by the node:
+//   - the root block and everything below it are kept
+//   - all data of the blocks in the sealing segment of the latest finalized block is kept, as well as
+//     the seals and results referenced by the segment
+//   - blocks which are not yet executed (execution nodes) or whose collections are not yet
+//     complete (access nodes) are kept
+//   - headers and epoch statuses of the first blocks of epochs are kept, so that epochs can be looked up
+//
+// Pruned blocks can't be retrieved anymore, even by ID.
+type Pruner struct {
+	component.Component
+	log       zerolog.Logger
+	db        *badger.DB
+	interval  time.Duration
+	batchSize uint64
+	retention map[PruningCategory]uint64
+	progress  map[PruningCategory]storage.ConsumerProgress
+}
+
+var _ component.Component = (*Pruner)(nil)
+
+// NewPruner returns a pruner which deletes data according to the given config. If the config doesn't
+// enable pruning for any category, the pruner is a no-op.
+// No errors are expected during normal operation.
+func NewPruner(log zerolog.Logger, db *badger.DB, config PrunerConfig) (*Pruner, error) {
+	p := &Pruner{
+		log:       log.With().Str("component", "pruner").Logger(),
+		db:        db,
+		interval:  config.Interval,
+		batchSize: config.BatchSize,
+		retention: make(map[PruningCategory]uint64, len(config.Retention)),
+		progress:  make(map[PruningCategory]storage.ConsumerProgress, len(config.Retention)),
+	}
+
+	for category, retention := range config.Retention {
+		if _, err := ParsePruningCategory(string(category)); err != nil {
+			return nil, err
+		}
+		p.retention[category] = retention
+		p.progress[category] = NewConsumerProgress(db, prunerConsumer(category))
+	}
+
+	if len(p.retention) == 0 {
+		p.Component = &module.NoopComponent{}
+		return p, nil
+	}
+	if p.interval <= 0 {
+		return nil, fmt.Errorf("invalid pruning interval: %v", p.interval)
+	}
+	if p.batchSize == 0 {
+		return nil, fmt.Errorf("pruning batch size must be positive")
+	}
+
+	p.Component = component.NewComponentManagerBuilder().
+		AddWorker(p.pruneWorkerRoutine).
+		Build()
+
+	return p, nil
+}
+
+// prunerConsumer returns the name the progress of the given category is stored under.
+func prunerConsumer(category PruningCategory) string {
+	return "ConsumeProgressPruner_" + string(category)
+}
+
+// PrunedHeight returns the height up to which (inclusive) the data of the given category is pruned.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the category was never pruned
+func (p *Pruner) PrunedHeight(category PruningCategory) (uint64, error) {
+	return NewConsumerProgress(p.db, prunerConsumer(category)).ProcessedIndex()
+}
+
+// pruneWorkerRoutine prunes data on a regular basis.
+func (p *Pruner) pruneWorkerRoutine(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+	err := p.initProgress()
+	if err != nil {
+		ctx.Throw(err)
+	}
+	ready()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		err := p.prune(ctx)
+		if err != nil {
+			ctx.Throw(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// initProgress initializes the progress of all enabled categories to the root height, unless they were
+// pruned before.
+//
+// The blocks whose headers are pruned can't be looked up by height anymore, hence the data of other
+// categories at these heights can't be found and pruned. Categories which are behind the pruned height
+// of headers, e.g. because they were enabled after headers were pruned, are moved forward to it, and
+// their data at lower heights is left in the database.
+func (p *Pruner) initProgress() error {
+	var rootHeight uint64
+	err := p.db.View(operation.RetrieveRootHeight(&rootHeight))
+	if err != nil {
+		return fmt.Errorf("could not retrieve root height: %w", err)
+	}
+
+	headersPruned, err := p.PrunedHeight(PruneHeaders)
+	if errors.Is(err, storage.ErrNotFound) {
+		headersPruned = rootHeight
+	} else if err != nil {
+		return fmt.Errorf("could not retrieve pruning progress of %s: %w", PruneHeaders, err)
+	}
+
+	for category, progress := range p.progress {
+		err := progress.InitProcessedIndex(rootHeight)
+		if err != nil && !errors.Is(err, storage.ErrAlreadyExists) {
+			return fmt.Errorf("could not initialize pruning progress of %s: %w", category, err)
+		}
+		if category == PruneHeaders {
+			continue
+		}
+
+		height, err := progress.ProcessedIndex()
+		if err != nil {
+			return fmt.Errorf("could not retrieve pruning progress of %s: %w", category, err)
+		}
+		if height >= headersPruned {
+			continue
+		}
+
+		p.log.Warn().
+			Str("category", string(category)).
+			Uint64("pruned_height", height).
+			Uint64("headers_pruned_height", headersPruned).
+			Msg("headers were pruned beyond the pruned height of category, its data up to the pruned height of headers can't be found and is not pruned")
+
+		err = progress.SetProcessedIndex(headersPruned)
+		if err != nil {
+			return fmt.Errorf("could not update pruning progress of %s: %w", category, err)
+		}
+	}
+	return nil
+}
+
+// prune prunes all enabled categories up to their target heights, one batch of heights at a time.
+// No errors are expected during normal operation.
+func (p *Pruner) prune(ctx irrecoverable.SignalerContext) error {
+	var sealed, safe uint64
+	var epochFirstHeights map[uint64]struct{}
+	err := p.db.View(func(tx *badger.Txn) error {
+		var err error
+		sealed, safe, err = pruningBounds(tx)
+		if err != nil {
+			return err
+		}
+		epochFirstHeights, err = lookupEpochFirstHeights(tx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not determine pruning bounds: %w", err)
+	}
+
+	pruned := make(map[PruningCategory]uint64, len(p.retention))
+	for _, category := range PruningCategories {
+		retention, ok := p.retention[category]
+		if !ok {
+			continue
+		}
+
+		height, err := p.progress[category].ProcessedIndex()
+		if err != nil {
+			return fmt.Errorf("could not retrieve pruning progress of %s: %w", category, err)
+		}
+		pruned[category] = height
+
+		if sealed < retention {
+			continue
+		}
+		target := sealed - retention
+		if target > safe {
+			target = safe
+		}
+		for _, dependent := range pruningDependents[category] {
+			if dependentHeight, ok := pruned[dependent]; ok && dependentHeight < target {
+				target = dependentHeight
+			}
+		}
+
+		for height < target {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			to := height + p.batchSize
+			if to > target {
+				to = target
+			}
+			err := p.pruneHeights(category, height+1, to, epochFirstHeights)
+			if err != nil {
+				return fmt.Errorf("could not prune %s at heights [%d, %d]: %w", category, height+1, to, err)
+			}
+			err = p.progress[category].SetProcessedIndex(to)
+			if err != nil {
+				return fmt.Errorf("could not update pruning progress of %s: %w", category, err)
+			}
+
+			p.log.Debug().
+				Str("category", string(category)).
+				Uint64("from_height", height+1).
+				Uint64("to_height", to).
+				Msg("pruned heights")
+
+			height = to
+			pruned[category] = height
+		}
+	}
+
+	return nil
+}
+
+// pruneHeights deletes the data of the given category for the finalized blocks within the given
+// height range (inclusive) in a single write batch.
+// No errors are expected during normal operation.
+func (p *Pruner) pruneHeights(category PruningCategory, from, to uint64, epochFirstHeights map[uint64]struct{}) error {
+	batch := p.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	err := p.db.View(func(tx *badger.Txn) error {
+		for height := from; height <= to; height++ {
+			var blockID flow.Identifier
+			err := operation.LookupBlockHeight(height, &blockID)(tx)
+			if errors.Is(err, storage.ErrNotFound) {
+				// categories never fall behind the pruned height of headers (see initProgress), so the
+				// header was either removed by other means or the database is inconsistent
+				p.log.Warn().
+					Str("category", string(category)).
+					Uint64("height", height).
+					Msg("could not find finalized block at height, skipping its data")
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not look up block at height %d: %w", height, err)
+			}
+
+			switch category {
+			case PruneHeaders:
+				if _, ok := epochFirstHeights[height]; ok {
+					continue
+				}
+				err = pruneHeader(batch, height, blockID)
+			case PrunePayloads:
+				err = prunePayload(tx, batch, blockID)
+			case PruneCollections:
+				err = pruneCollections(tx, batch, blockID)
+			case PruneEvents:
+				err = pruneEvents(tx, batch, blockID)
+			case PruneTransactionResults:
+				err = pruneTransactionResults(tx, batch, blockID)
+			case PruneChunkDataPacks:
+				err = pruneChunkDataPacks(tx, batch, blockID)
+			case PruneReceipts:
+				err = pruneReceipts(tx, batch, blockID)
+			default:
+				err = fmt.Errorf("unknown pruning category: %s", category)
+			}
+			if err != nil {
+				return fmt.Errorf("could not prune block %v at height %d: %w", blockID, height, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return batch.Flush()
+}
+
+// pruningBounds returns the latest sealed height, and the highest height which can be pruned without
+// breaking the sealing segment of the latest finalized block, or blocks which are still processed.
+// No errors are expected during normal operation.
+func pruningBounds(tx *badger.Txn) (uint64, uint64, error) {
+	var rootHeight, finalized, sealed uint64
+	err := operation.RetrieveRootHeight(&rootHeight)(tx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not retrieve root height: %w", err)
+	}
+	err = operation.RetrieveFinalizedHeight(&finalized)(tx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not retrieve finalized height: %w", err)
+	}
+	err = operation.RetrieveSealedHeight(&sealed)(tx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not retrieve sealed height: %w", err)
+	}
+
+	// The sealing segment of the latest finalized block starts at the lowest of the latest sealed
+	// block and the reference block of the oldest unexpired transaction, and includes the latest
+	// seal as of that block, as well as the results of all seals within the segment. Seals and
+	// results for a block are only included in its descendants, hence everything below the block
+	// sealed by the first seal of the segment can be pruned.
+	lowest := sealed
+	if finalized > flow.DefaultTransactionExpiry && finalized-flow.DefaultTransactionExpiry < lowest {
+		lowest = finalized - flow.DefaultTransactionExpiry
+	}
+	if lowest <= rootHeight {
+		return sealed, rootHeight, nil
+	}
+
+	var lowestID, sealID flow.Identifier
+	err = operation.LookupBlockHeight(lowest, &lowestID)(tx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not look up block at height %d: %w", lowest, err)
+	}
+	err = operation.LookupLatestSealAtBlock(lowestID, &sealID)(tx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not look up latest seal at block %v: %w", lowestID, err)
+	}
+	var seal flow.Seal
+	err = operation.RetrieveSeal(sealID, &seal)(tx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not retrieve seal %v: %w", sealID, err)
+	}
+	var firstSealed flow.Header
+	err = operation.RetrieveHeader(seal.BlockID, &firstSealed)(tx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not retrieve sealed block %v: %w", seal.BlockID, err)
+	}
+	if firstSealed.Height <= rootHeight {
+		return sealed, rootHeight, nil
+	}
+	safe := firstSealed.Height - 1
+
+	// execution nodes must keep the blocks they haven't executed yet
+	var executedID flow.Identifier
+	err = operation.RetrieveExecutedBlock(&executedID)(tx)
+	if err == nil {
+		var executed flow.Header
+		err = operation.RetrieveHeader(executedID, &executed)(tx)
+		if err != nil {
+			return 0, 0, fmt.Errorf("could not retrieve executed block %v: %w", executedID, err)
+		}
+		if executed.Height <= rootHeight {
+			return sealed, rootHeight, nil
+		}
+		if executed.Height <= safe {
+			safe = executed.Height - 1
+		}
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return 0, 0, fmt.Errorf("could not retrieve executed block: %w", err)
+	}
+
+	// access nodes must keep the blocks whose collections they haven't received yet
+	var complete uint64
+	err = operation.RetrieveLastCompleteBlockHeight(&complete)(tx)
+	if err == nil {
+		if complete < safe {
+			safe = complete
+		}
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return 0, 0, fmt.Errorf("could not retrieve last complete block height: %w", err)
+	}
+
+	if safe < rootHeight {
+		return sealed, rootHeight, nil
+	}
+	return sealed, safe, nil
+}
+
+// lookupEpochFirstHeights returns the first heights of the current and all past epochs.
+// No errors are expected during normal operation.
+func lookupEpochFirstHeights(tx *badger.Txn) (map[uint64]struct{}, error) {
+	var finalized uint64
+	err := operation.RetrieveFinalizedHeight(&finalized)(tx)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve finalized height: %w", err)
+	}
+	var headID flow.Identifier
+	err = operation.LookupBlockHeight(finalized, &headID)(tx)
+	if err != nil {
+		return nil, fmt.Errorf("could not look up finalized block: %w", err)
+	}
+	var status flow.EpochStatus
+	err = operation.RetrieveEpochStatus(headID, &status)(tx)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve epoch status: %w", err)
+	}
+	var setup flow.EpochSetup
+	err = operation.RetrieveEpochSetup(status.CurrentEpoch.SetupID, &setup)(tx)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve current epoch setup: %w", err)
+	}
+
+	heights := make(map[uint64]struct{})
+	for counter := setup.Counter; ; counter-- {
+		var height uint64
+		err := operation.RetrieveEpochFirstHeight(counter, &height)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			// epochs which started before the root block are not indexed
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve first height of epoch %d: %w", counter, err)
+		}
+		heights[height] = struct{}{}
+		if counter == 0 {
+			break
+		}
+	}
+	return heights, nil
+}
+
+// pruneHeader deletes the header of a finalized block, together with its indexes.
+func pruneHeader(batch *badger.WriteBatch, height uint64, blockID flow.Identifier) error {
+	for _, remove := range []func(*badger.WriteBatch) error{
+		operation.BatchRemoveHeader(blockID),
+		operation.BatchRemoveBlockHeight(height),
+		operation.BatchRemoveBlockChildren(blockID),
+		operation.BatchRemoveLatestSealAtBlock(blockID),
+		operation.BatchRemoveFinalizedSealByBlockID(blockID),
+		operation.BatchRemoveEpochStatus(blockID),
+		operation.BatchRemoveQuorumCertificate(blockID),
+	} {
+		err := remove(batch)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prunePayload deletes the guarantees, seals, receipts and results included in a block, together with
+// the payload indexes.
+func prunePayload(tx *badger.Txn, batch *badger.WriteBatch, blockID flow.Identifier) error {
+	var guaranteeIDs, sealIDs, receiptIDs, resultIDs []flow.Identifier
+	lookups := []func(*badger.Txn) error{
+		operation.LookupPayloadGuarantees(blockID, &guaranteeIDs),
+		operation.LookupPayloadSeals(blockID, &sealIDs),
+		operation.LookupPayloadReceipts(blockID, &receiptIDs),
+		operation.LookupPayloadResults(blockID, &resultIDs),
+	}
+	for _, lookup := range lookups {
+		err := lookup(tx)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("could not look up payload: %w", err)
+		}
+	}
+
+	removes := []func(*badger.WriteBatch) error{
+		operation.BatchRemovePayloadGuarantees(blockID),
+		operation.BatchRemovePayloadIndexes(blockID),
+	}
+	for _, guaranteeID := range guaranteeIDs {
+		removes = append(removes, operation.BatchRemoveGuarantee(guaranteeID))
+	}
+	for _, sealID := range sealIDs {
+		removes = append(removes, operation.BatchRemoveSeal(sealID))
+	}
+	for _, receiptID := range receiptIDs {
+		removes = append(removes, operation.BatchRemoveExecutionReceiptMeta(receiptID))
+	}
+	for _, resultID := range resultIDs {
+		removes = append(removes, operation.BatchRemoveExecutionResult(resultID))
+	}
+	for _, remove := range removes {
+		err := remove(batch)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneCollections deletes the collections guaranteed in a block, and their transactions.
+func pruneCollections(tx *badger.Txn, batch *badger.WriteBatch, blockID flow.Identifier) error {
+	var guaranteeIDs []flow.Identifier
+	err := operation.LookupPayloadGuarantees(blockID, &guaranteeIDs)(tx)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up payload guarantees: %w", err)
+	}
+
+	for _, collID := range guaranteeIDs {
+		var collection flow.LightCollection
+		err := operation.RetrieveCollection(collID, &collection)(tx)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("could not retrieve collection %v: %w", collID, err)
+		}
+
+		removes := []func(*badger.WriteBatch) error{
+			operation.BatchRemoveCollection(collID),
+			operation.BatchRemoveCollectionBlock(collID),
+		}
+		for _, txID := range collection.Transactions {
+			removes = append(removes,
+				operation.BatchRemoveTransaction(txID),
+				operation.BatchRemoveCollectionByTransaction(txID),
+			)
+		}
+		for _, remove := range removes {
+			err := remove(batch)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruneEvents deletes the events and service events emitted in a block.
+func pruneEvents(tx *badger.Txn, batch *badger.WriteBatch, blockID flow.Identifier) error {
+	err := operation.BatchRemoveEventsByBlockID(blockID, batch)(tx)
+	if err != nil {
+		return err
+	}
+	return operation.BatchRemoveServiceEventsByBlockID(blockID, batch)(tx)
+}
+
+// pruneTransactionResults deletes the transaction results of a block, by ID and by index.
+func pruneTransactionResults(tx *badger.Txn, batch *badger.WriteBatch, blockID flow.Identifier) error {
+	err := operation.BatchRemoveTransactionResultsByBlockID(blockID, batch)(tx)
+	if err != nil {
+		return err
+	}
+	return operation.BatchRemoveTransactionResultIndexByBlockID(blockID, batch)(tx)
+}
+
+// pruneChunkDataPacks deletes the chunk data packs of the execution result for a block.
+func pruneChunkDataPacks(tx *badger.Txn, batch *badger.WriteBatch, blockID flow.Identifier) error {
+	var resultID flow.Identifier
+	err := operation.LookupExecutionResult(blockID, &resultID)(tx)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not look up execution result: %w", err)
+	}
+	var result flow.ExecutionResult
+	err = operation.RetrieveExecutionResult(resultID, &result)(tx)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not retrieve execution result %v: %w", resultID, err)
+	}
+
+	for _, chunk := range result.Chunks {
+		err := operation.BatchRemoveChunkDataPack(chunk.ID())(batch)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneReceipts deletes the execution receipts for a block, as well as the execution result
+// indexed for it.
+func pruneReceipts(tx *badger.Txn, batch *badger.WriteBatch, blockID flow.Identifier) error {
+	var receiptIDs []flow.Identifier
+	err := operation.LookupExecutionReceipts(blockID, &receiptIDs)(tx)
+	if err != nil {
+		return fmt.Errorf("could not look up execution receipts: %w", err)
+	}
+	var ownReceiptID flow.Identifier
+	err = operation.LookupOwnExecutionReceipt(blockID, &ownReceiptID)(tx)
+	if err == nil {
+		receiptIDs = append(receiptIDs, ownReceiptID)
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("could not look up own execution receipt: %w", err)
+	}
+
+	removes := []func(*badger.WriteBatch) error{
+		operation.BatchRemoveOwnExecutionReceipt(blockID),
+		operation.BatchRemoveExecutionResultIndex(blockID),
+	}
+	for _, receiptID := range receiptIDs {
+		removes = append(removes, operation.BatchRemoveExecutionReceiptMeta(receiptID))
+	}
+	var resultID flow.Identifier
+	err = operation.LookupExecutionResult(blockID, &resultID)(tx)
+	if err == nil {
+		removes = append(removes, operation.BatchRemoveExecutionResult(resultID))
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("could not look up execution result: %w", err)
+	}
+	for _, remove := range removes {
+		err := remove(batch)
+		if err != nil {
+			return err
+		}
+	}
+
+	return operation.BatchRemoveExecutionReceipts(blockID, batch)(tx)
+}
@@ -0,0 +1,337 @@
+package badger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/storage"
+	badgerstorage "github.com/onflow/flow-go/storage/badger"
+	badgermodel "github.com/onflow/flow-go/storage/badger/model"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// prunerTestBlock is a finalized block together with the data stored for it.
+type prunerTestBlock struct {
+	header     *flow.Header
+	collection flow.LightCollection
+	sealID     flow.Identifier
+	result     *flow.ExecutionResult
+	receiptID  flow.Identifier
+}
+
+// storePrunerTestChain stores a chain of finalized blocks from height 0 to finalized, where each block
+// seals the block 10 heights below it, and the current epoch started at height 100.
+func storePrunerTestChain(t *testing.T, db *badger.DB, finalized uint64) []prunerTestBlock {
+	blocks := make([]prunerTestBlock, 0, finalized+1)
+	setup := &flow.EpochSetup{Counter: 2, FirstView: 100}
+	status := unittest.EpochStatusFixture()
+	status.CurrentEpoch.SetupID = setup.ID()
+
+	var latestSealID flow.Identifier
+	for height := uint64(0); height <= finalized; height++ {
+		var header *flow.Header
+		if height == 0 {
+			header = unittest.BlockHeaderFixture(unittest.WithHeaderHeight(0))
+		} else {
+			header = unittest.BlockHeaderWithParentFixture(blocks[height-1].header)
+		}
+		blockID := header.ID()
+
+		collection := unittest.CollectionFixture(2)
+		light := collection.Light()
+		result := unittest.ExecutionResultFixture()
+		result.BlockID = blockID
+		receipt := unittest.ExecutionReceiptFixture(unittest.WithResult(result))
+
+		sealed := header
+		if height >= 10 {
+			sealed = blocks[height-10].header
+		}
+		seal := unittest.Seal.Fixture(unittest.Seal.WithBlock(sealed))
+		if height == 0 || height >= 10 {
+			latestSealID = seal.ID()
+		}
+
+		err := db.Update(func(tx *badger.Txn) error {
+			ops := []func(*badger.Txn) error{
+				operation.InsertHeader(blockID, header),
+				operation.IndexBlockHeight(height, blockID),
+				operation.InsertEpochStatus(blockID, status),
+				operation.InsertCollection(&light),
+				operation.InsertGuarantee(light.ID(), &flow.CollectionGuarantee{CollectionID: light.ID()}),
+				operation.IndexPayloadGuarantees(blockID, []flow.Identifier{light.ID()}),
+				operation.IndexCollectionBlock(light.ID(), blockID),
+				operation.InsertSeal(seal.ID(), seal),
+				operation.IndexPayloadSeals(blockID, []flow.Identifier{seal.ID()}),
+				operation.IndexLatestSealAtBlock(blockID, latestSealID),
+				operation.InsertExecutionResult(result),
+				operation.IndexExecutionResult(blockID, result.ID()),
+				operation.InsertExecutionReceiptMeta(receipt.ID(), receipt.Meta()),
+				operation.IndexOwnExecutionReceipt(blockID, receipt.ID()),
+				operation.InsertEvent(blockID, unittest.EventFixture(flow.EventAccountCreated, 0, 0, collection.Transactions[0].ID(), 0)),
+				operation.InsertTransactionResult(blockID, &flow.TransactionResult{TransactionID: collection.Transactions[0].ID()}),
+			}
+			for _, txBody := range collection.Transactions {
+				ops = append(ops,
+					operation.InsertTransaction(txBody.ID(), txBody),
+					operation.IndexCollectionByTransaction(txBody.ID(), light.ID()),
+				)
+			}
+			for _, chunk := range result.Chunks {
+				ops = append(ops, operation.InsertChunkDataPack(&badgermodel.StoredChunkDataPack{ChunkID: chunk.ID()}))
+			}
+			for _, op := range ops {
+				if err := op(tx); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		blocks = append(blocks, prunerTestBlock{
+			header:     header,
+			collection: light,
+			sealID:     seal.ID(),
+			result:     result,
+			receiptID:  receipt.ID(),
+		})
+	}
+
+	err := db.Update(func(tx *badger.Txn) error {
+		for _, op := range []func(*badger.Txn) error{
+			operation.InsertRootHeight(0),
+			operation.InsertFinalizedHeight(finalized),
+			operation.InsertSealedHeight(finalized - 10),
+			operation.InsertEpochSetup(setup.ID(), setup),
+			operation.InsertEpochFirstHeight(1, 0),
+			operation.InsertEpochFirstHeight(2, 100),
+		} {
+			if err := op(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	return blocks
+}
+
+// runPruner runs the pruner until all given categories are pruned up to the expected heights.
+func runPruner(t *testing.T, pruner *badgerstorage.Pruner, expected map[badgerstorage.PruningCategory]uint64) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signalerCtx, _ := irrecoverable.WithSignaler(ctx)
+	pruner.Start(signalerCtx)
+	unittest.RequireComponentsReadyBefore(t, time.Second, pruner)
+
+	require.Eventually(t, func() bool {
+		for category, height := range expected {
+			pruned, err := pruner.PrunedHeight(category)
+			if err != nil || pruned != height {
+				return false
+			}
+		}
+		return true
+	}, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	unittest.RequireComponentsDoneBefore(t, time.Second, pruner)
+}
+
+func TestPruner(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		blocks := storePrunerTestChain(t, db, 800)
+
+		// The sealing segment of the finalized block starts at height 200 (expired transactions),
+		// whose latest seal is for height 190, hence data is pruned up to height 189.
+		// Headers can't be pruned beyond events, which retain 700 heights below the sealed height 790.
+		pruner, err := badgerstorage.NewPruner(unittest.Logger(), db, badgerstorage.PrunerConfig{
+			Interval:  10 * time.Millisecond,
+			BatchSize: 50,
+			Retention: map[badgerstorage.PruningCategory]uint64{
+				badgerstorage.PruneHeaders:            0,
+				badgerstorage.PrunePayloads:           0,
+				badgerstorage.PruneCollections:        0,
+				badgerstorage.PruneEvents:             700,
+				badgerstorage.PruneTransactionResults: 0,
+				badgerstorage.PruneChunkDataPacks:     0,
+				badgerstorage.PruneReceipts:           0,
+			},
+		})
+		require.NoError(t, err)
+
+		runPruner(t, pruner, map[badgerstorage.PruningCategory]uint64{
+			badgerstorage.PruneHeaders:            90,
+			badgerstorage.PrunePayloads:           189,
+			badgerstorage.PruneCollections:        189,
+			badgerstorage.PruneEvents:             90,
+			badgerstorage.PruneTransactionResults: 189,
+			badgerstorage.PruneChunkDataPacks:     189,
+			badgerstorage.PruneReceipts:           189,
+		})
+
+		// the root block is kept
+		requireHeader(t, db, blocks[0], true)
+		requireHeader(t, db, blocks[50], false)
+		requireEvents(t, db, blocks[50], false)
+		requireBlockData(t, db, blocks[50], false)
+
+		// the first block of the current epoch is kept
+		requireHeader(t, db, blocks[100], true)
+
+		// above the pruned height of events, only block data is pruned
+		requireHeader(t, db, blocks[150], true)
+		requireEvents(t, db, blocks[150], true)
+		requireBlockData(t, db, blocks[150], false)
+
+		// the block sealed by the first seal of the sealing segment is kept
+		requireBlockData(t, db, blocks[190], true)
+		var sealID flow.Identifier
+		err = db.View(operation.LookupLatestSealAtBlock(blocks[200].header.ID(), &sealID))
+		require.NoError(t, err)
+		assert.Equal(t, blocks[200].sealID, sealID)
+		err = db.View(operation.RetrieveSeal(sealID, &flow.Seal{}))
+		require.NoError(t, err)
+
+		// after a restart, pruning resumes where it left off
+		pruner, err = badgerstorage.NewPruner(unittest.Logger(), db, badgerstorage.PrunerConfig{
+			Interval:  10 * time.Millisecond,
+			BatchSize: 50,
+			Retention: map[badgerstorage.PruningCategory]uint64{
+				badgerstorage.PruneHeaders: 0,
+				badgerstorage.PruneEvents:  0,
+			},
+		})
+		require.NoError(t, err)
+
+		runPruner(t, pruner, map[badgerstorage.PruningCategory]uint64{
+			badgerstorage.PruneHeaders: 189,
+			badgerstorage.PruneEvents:  189,
+		})
+
+		requireHeader(t, db, blocks[100], true)
+		requireHeader(t, db, blocks[150], false)
+		requireEvents(t, db, blocks[150], false)
+		requireHeader(t, db, blocks[190], true)
+	})
+}
+
+// TestPrunerCategoryEnabledAfterHeaders verifies that a category which is enabled after headers were pruned
+// starts at the pruned height of headers, as the data at lower heights can't be found anymore.
+func TestPrunerCategoryEnabledAfterHeaders(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		blocks := storePrunerTestChain(t, db, 800)
+
+		pruner, err := badgerstorage.NewPruner(unittest.Logger(), db, badgerstorage.PrunerConfig{
+			Interval:  10 * time.Millisecond,
+			BatchSize: 50,
+			Retention: map[badgerstorage.PruningCategory]uint64{
+				badgerstorage.PruneHeaders: 0,
+			},
+		})
+		require.NoError(t, err)
+		runPruner(t, pruner, map[badgerstorage.PruningCategory]uint64{
+			badgerstorage.PruneHeaders: 189,
+		})
+
+		pruner, err = badgerstorage.NewPruner(unittest.Logger(), db, badgerstorage.PrunerConfig{
+			Interval:  10 * time.Millisecond,
+			BatchSize: 50,
+			Retention: map[badgerstorage.PruningCategory]uint64{
+				badgerstorage.PruneHeaders: 0,
+				badgerstorage.PruneEvents:  0,
+			},
+		})
+		require.NoError(t, err)
+		runPruner(t, pruner, map[badgerstorage.PruningCategory]uint64{
+			badgerstorage.PruneHeaders: 189,
+			badgerstorage.PruneEvents:  189,
+		})
+
+		// the events of blocks whose headers were pruned before events were enabled are left
+		requireHeader(t, db, blocks[50], false)
+		requireEvents(t, db, blocks[50], true)
+		requireEvents(t, db, blocks[190], true)
+	})
+}
+
+func TestPrunerDisabled(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		pruner, err := badgerstorage.NewPruner(unittest.Logger(), db, badgerstorage.PrunerConfig{})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		signalerCtx, _ := irrecoverable.WithSignaler(ctx)
+		pruner.Start(signalerCtx)
+		unittest.RequireComponentsReadyBefore(t, time.Second, pruner)
+		cancel()
+		unittest.RequireComponentsDoneBefore(t, time.Second, pruner)
+
+		_, err = pruner.PrunedHeight(badgerstorage.PruneHeaders)
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		_, err = badgerstorage.NewPruner(unittest.Logger(), db, badgerstorage.PrunerConfig{
+			Retention: map[badgerstorage.PruningCategory]uint64{"unknown": 0},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func requireHeader(t *testing.T, db *badger.DB, block prunerTestBlock, exists bool) {
+	blockID := block.header.ID()
+	requireExists(t, exists, db.View(operation.RetrieveHeader(blockID, &flow.Header{})))
+	requireExists(t, exists, db.View(operation.LookupBlockHeight(block.header.Height, &flow.Identifier{})))
+	requireExists(t, exists, db.View(operation.RetrieveEpochStatus(blockID, &flow.EpochStatus{})))
+	requireExists(t, exists, db.View(operation.LookupLatestSealAtBlock(blockID, &flow.Identifier{})))
+}
+
+func requireEvents(t *testing.T, db *badger.DB, block prunerTestBlock, exists bool) {
+	var events []flow.Event
+	require.NoError(t, db.View(operation.LookupEventsByBlockID(block.header.ID(), &events)))
+	assert.Equal(t, exists, len(events) > 0)
+}
+
+func requireBlockData(t *testing.T, db *badger.DB, block prunerTestBlock, exists bool) {
+	blockID := block.header.ID()
+	collID := block.collection.ID()
+
+	requireExists(t, exists, db.View(operation.LookupPayloadGuarantees(blockID, &[]flow.Identifier{})))
+	requireExists(t, exists, db.View(operation.RetrieveGuarantee(collID, &flow.CollectionGuarantee{})))
+	requireExists(t, exists, db.View(operation.LookupPayloadSeals(blockID, &[]flow.Identifier{})))
+	requireExists(t, exists, db.View(operation.RetrieveSeal(block.sealID, &flow.Seal{})))
+	requireExists(t, exists, db.View(operation.RetrieveCollection(collID, &flow.LightCollection{})))
+	requireExists(t, exists, db.View(operation.LookupCollectionBlock(collID, &flow.Identifier{})))
+	for _, txID := range block.collection.Transactions {
+		requireExists(t, exists, db.View(operation.RetrieveTransaction(txID, &flow.TransactionBody{})))
+		requireExists(t, exists, db.View(operation.RetrieveCollectionID(txID, &flow.Identifier{})))
+	}
+	for _, chunk := range block.result.Chunks {
+		requireExists(t, exists, db.View(operation.RetrieveChunkDataPack(chunk.ID(), &badgermodel.StoredChunkDataPack{})))
+	}
+	requireExists(t, exists, db.View(operation.LookupExecutionResult(blockID, &flow.Identifier{})))
+	requireExists(t, exists, db.View(operation.RetrieveExecutionResult(block.result.ID(), &flow.ExecutionResult{})))
+	requireExists(t, exists, db.View(operation.LookupOwnExecutionReceipt(blockID, &flow.Identifier{})))
+	requireExists(t, exists, db.View(operation.RetrieveExecutionReceiptMeta(block.receiptID, &flow.ExecutionReceiptMeta{})))
+
+	var results []flow.TransactionResult
+	require.NoError(t, db.View(operation.LookupTransactionResultsByBlockID(blockID, &results)))
+	assert.Equal(t, exists, len(results) > 0)
+}
+
+func requireExists(t *testing.T, exists bool, err error) {
+	if exists {
+		require.NoError(t, err)
+		return
+	}
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"github.com/onflow/flow-go/crypto"
+	"github.com/onflow/flow-go/model/dkg"
 	"github.com/onflow/flow-go/model/flow"
 )
 
@@ -57,3 +58,21 @@ type SafeBeaconKeys interface {
 	//   - (nil, false, error) for any unexpected exception
 	RetrieveMyBeaconPrivateKey(epochCounter uint64) (key crypto.PrivateKey, safe bool, err error)
 }
+
+// DKGTranscripts stores the transcripts of the DKGs run by this node. Transcripts only contain
+// message metadata and never secret material, so they may be stored in the protocol database.
+type DKGTranscripts interface {
+
+	// InitTranscript stores the info of the transcript for a DKG instance. Events are appended
+	// to the existing transcript if the transcript was already initialized.
+	// No errors expected during normal operation.
+	InitTranscript(info *dkg.TranscriptInfo) error
+
+	// AppendEvent appends an event to the transcript of the given DKG instance.
+	// Error returns: storage.ErrNotFound if the transcript was not initialized
+	AppendEvent(dkgInstanceID string, event *dkg.TranscriptEvent) error
+
+	// ByInstanceID returns the transcript of the given DKG instance.
+	// Error returns: storage.ErrNotFound
+	ByInstanceID(dkgInstanceID string) (*dkg.Transcript, error)
+}
@@ -21,4 +21,8 @@ var (
 	// ErrDataMismatch is returned when a repeatable insert operation attempts
 	// to insert a different value for the same key.
 	ErrDataMismatch = errors.New("data for key is different")
+
+	// ErrHeightNotIndexed is returned when data that is indexed sequentially is queried by a given block height
+	// and that data is unavailable, because the height is outside of the indexed range.
+	ErrHeightNotIndexed = errors.New("data for block height not available")
 )
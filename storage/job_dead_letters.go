@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"time"
+)
+
+// DeadLetteredJob is a job which a job consumer gave up on after it exhausted its attempts.
+// The consumer moved past the job, which is kept until it is retried or skipped by an operator.
+type DeadLetteredJob struct {
+	Index          uint64    // index of the job in the job queue
+	JobID          string    // ID of the job
+	Attempts       uint      // number of attempts before the job was dead-lettered
+	Reason         string    // why the last attempt failed
+	DeadLetteredAt time.Time // when the job was dead-lettered
+}
+
+// JobDeadLetters stores the dead-lettered jobs of a job consumer.
+type JobDeadLetters interface {
+	// Add stores the given dead-lettered job, replacing any job stored at the same index.
+	// No errors are expected during normal operation.
+	Add(job *DeadLetteredJob) error
+
+	// ByIndex returns the dead-lettered job at the given index.
+	// Error returns: storage.ErrNotFound
+	ByIndex(index uint64) (*DeadLetteredJob, error)
+
+	// Remove removes the dead-lettered job at the given index.
+	// Error returns: storage.ErrNotFound
+	Remove(index uint64) error
+
+	// All returns all dead-lettered jobs, ordered by index.
+	// No errors are expected during normal operation.
+	All() ([]*DeadLetteredJob, error)
+}
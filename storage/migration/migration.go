@@ -0,0 +1,139 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation/badgerimpl"
+	"github.com/onflow/flow-go/storage/operation/pebbleimpl"
+)
+
+// DefaultBatchSize is the default number of key-value pairs written per batch during a migration.
+const DefaultBatchSize = 1000
+
+// CopyFromBadgerToPebble copies all key-value pairs of the given Badger database into the given
+// Pebble database, which is expected to be empty.
+// No errors are expected during normal operation.
+func CopyFromBadgerToPebble(log zerolog.Logger, badgerDB *badger.DB, pebbleDB *pebble.DB, batchSize int) error {
+	return Copy(log, badgerimpl.ToReader(badgerDB), pebbleimpl.ToDB(pebbleDB), batchSize)
+}
+
+// Copy copies all key-value pairs readable from src into dst, committing a batch every batchSize pairs.
+// No errors are expected during normal operation.
+func Copy(log zerolog.Logger, src storage.Reader, dst storage.DB, batchSize int) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	// all keys have a prefix within [0x00, 0xff]
+	it, err := src.NewIter([]byte{0x00}, []byte{0xff}, storage.DefaultIteratorOptions())
+	if err != nil {
+		return fmt.Errorf("could not create iterator: %w", err)
+	}
+	defer it.Close()
+
+	type pair struct {
+		key   []byte
+		value []byte
+	}
+	pairs := make([]pair, 0, batchSize)
+	copied := 0
+
+	flush := func() error {
+		if len(pairs) == 0 {
+			return nil
+		}
+		err := dst.WithReaderBatchWriter(storage.OnlyWriter(func(w storage.Writer) error {
+			for _, p := range pairs {
+				err := w.Set(p.key, p.value)
+				if err != nil {
+					return fmt.Errorf("could not set key %x: %w", p.key, err)
+				}
+			}
+			return nil
+		}))
+		if err != nil {
+			return fmt.Errorf("could not write batch: %w", err)
+		}
+		copied += len(pairs)
+		pairs = pairs[:0]
+		log.Info().Int("copied", copied).Msg("batch copied")
+		return nil
+	}
+
+	for it.First(); it.Valid(); it.Next() {
+		item := it.IterItem()
+		key := item.KeyCopy(nil)
+		var value []byte
+		err := item.Value(func(val []byte) error {
+			value = append([]byte(nil), val...)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not read value of key %x: %w", key, err)
+		}
+
+		pairs = append(pairs, pair{key: key, value: value})
+		if len(pairs) >= batchSize {
+			err = flush()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = flush()
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int("total", copied).Msg("all key-value pairs copied")
+	return nil
+}
+
+// Verify checks that every key-value pair readable from src is stored with the same value in dst.
+// Expected errors during normal operation:
+//   - ErrMismatch if a key is missing in dst or stored with a different value
+func Verify(src storage.Reader, dst storage.Reader) error {
+	it, err := src.NewIter([]byte{0x00}, []byte{0xff}, storage.DefaultIteratorOptions())
+	if err != nil {
+		return fmt.Errorf("could not create iterator: %w", err)
+	}
+	defer it.Close()
+
+	for it.First(); it.Valid(); it.Next() {
+		item := it.IterItem()
+		key := item.KeyCopy(nil)
+		err := item.Value(func(val []byte) error {
+			return verifyValue(dst, key, val)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrMismatch is returned by Verify if the destination database doesn't match the source database.
+var ErrMismatch = errors.New("databases do not match")
+
+func verifyValue(dst storage.Reader, key []byte, expected []byte) error {
+	actual, closer, err := dst.Get(key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("key %x is missing: %w", key, ErrMismatch)
+	}
+	if err != nil {
+		return fmt.Errorf("could not read key %x: %w", key, err)
+	}
+	defer closer.Close()
+
+	if string(actual) != string(expected) {
+		return fmt.Errorf("value of key %x differs: %w", key, ErrMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation/badgerimpl"
+	"github.com/onflow/flow-go/storage/operation/dbtest"
+	"github.com/onflow/flow-go/storage/operation/pebbleimpl"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestCopyFromBadgerToPebble(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(badgerDB *badger.DB) {
+		dbtest.RunWithPebbleDB(t, func(pebbleDB *pebble.DB) {
+			// include the boundaries of the key space
+			keys := [][]byte{{0x00}, {0x01, 0x02}, {0x66, 0xff}, {0xff}, {0xff, 0xff, 0x01}}
+			for i := 0; i < 25; i++ {
+				keys = append(keys, unittest.RandomBytes(16))
+			}
+
+			err := dbtest.WithWriter(badgerimpl.ToDB(badgerDB))(func(w storage.Writer) error {
+				for _, key := range keys {
+					err := w.Set(key, unittest.RandomBytes(32))
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+
+			// use a batch size which doesn't divide the number of keys
+			err = CopyFromBadgerToPebble(zerolog.Nop(), badgerDB, pebbleDB, 7)
+			require.NoError(t, err)
+
+			err = Verify(badgerimpl.ToReader(badgerDB), pebbleimpl.ToReader(pebbleDB))
+			require.NoError(t, err)
+
+			// a modified value in the destination is detected
+			err = dbtest.WithWriter(pebbleimpl.ToDB(pebbleDB))(func(w storage.Writer) error {
+				return w.Set(keys[3], []byte{0x00})
+			})
+			require.NoError(t, err)
+			err = Verify(badgerimpl.ToReader(badgerDB), pebbleimpl.ToReader(pebbleDB))
+			require.ErrorIs(t, err, ErrMismatch)
+
+			// a missing key in the destination is detected
+			err = dbtest.WithWriter(pebbleimpl.ToDB(pebbleDB))(func(w storage.Writer) error {
+				return w.Delete(keys[0])
+			})
+			require.NoError(t, err)
+			err = Verify(badgerimpl.ToReader(badgerDB), pebbleimpl.ToReader(pebbleDB))
+			require.ErrorIs(t, err, ErrMismatch)
+		})
+	})
+}
+
+func TestCopyInvalidBatchSize(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(badgerDB *badger.DB) {
+		dbtest.RunWithPebbleDB(t, func(pebbleDB *pebble.DB) {
+			err := CopyFromBadgerToPebble(zerolog.Nop(), badgerDB, pebbleDB, 0)
+			require.Error(t, err)
+		})
+	})
+}
@@ -0,0 +1,33 @@
+package badgerimpl
+
+import (
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/storage"
+)
+
+type dbStore struct {
+	db *badger.DB
+}
+
+var _ storage.DB = (*dbStore)(nil)
+
+// ToDB returns the given Badger database as a backend-neutral storage.DB.
+func ToDB(db *badger.DB) storage.DB {
+	return &dbStore{db: db}
+}
+
+func (b *dbStore) Reader() storage.Reader {
+	return ToReader(b.db)
+}
+
+func (b *dbStore) WithReaderBatchWriter(fn func(storage.ReaderBatchWriter) error) error {
+	batch := NewReaderBatchWriter(b.db)
+	defer batch.Discard()
+
+	err := fn(batch)
+	if err != nil {
+		return err
+	}
+	return batch.Commit()
+}
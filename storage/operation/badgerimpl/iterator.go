@@ -0,0 +1,70 @@
+package badgerimpl
+
+import (
+	"bytes"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/storage"
+)
+
+type badgerIterator struct {
+	tx         *badger.Txn
+	iter       *badger.Iterator
+	lowerBound []byte
+	upperBound []byte // exclusive, nil if unbounded
+}
+
+var _ storage.Iterator = (*badgerIterator)(nil)
+
+func newBadgerIterator(db *badger.DB, startPrefix, endPrefix []byte, ops storage.IteratorOption) *badgerIterator {
+	options := badger.DefaultIteratorOptions
+	if ops.KeysOnly {
+		options.PrefetchValues = false
+	}
+
+	tx := db.NewTransaction(false)
+	return &badgerIterator{
+		tx:         tx,
+		iter:       tx.NewIterator(options),
+		lowerBound: startPrefix,
+		upperBound: storage.PrefixUpperBound(endPrefix),
+	}
+}
+
+// First positions the iterator at the first key of the range, and returns whether it is valid.
+func (i *badgerIterator) First() bool {
+	i.iter.Seek(i.lowerBound)
+	return i.Valid()
+}
+
+// Valid returns whether the iterator is positioned at a key within the range.
+func (i *badgerIterator) Valid() bool {
+	if !i.iter.Valid() {
+		return false
+	}
+	if i.upperBound == nil {
+		return true
+	}
+	return bytes.Compare(i.iter.Item().Key(), i.upperBound) < 0
+}
+
+// Next moves the iterator to the next key.
+func (i *badgerIterator) Next() {
+	i.iter.Next()
+}
+
+// IterItem returns the item the iterator is positioned at.
+func (i *badgerIterator) IterItem() storage.IterItem {
+	return i.iter.Item()
+}
+
+var _ storage.IterItem = (*badger.Item)(nil)
+
+// Close closes the iterator and discards its transaction.
+// No errors are expected during normal operation.
+func (i *badgerIterator) Close() error {
+	i.iter.Close()
+	i.tx.Discard()
+	return nil
+}
@@ -0,0 +1,55 @@
+package badgerimpl
+
+import (
+	"errors"
+	"io"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/storage"
+)
+
+type dbReader struct {
+	db *badger.DB
+}
+
+var _ storage.Reader = (*dbReader)(nil)
+
+// ToReader returns a reader of the committed state of the given Badger database.
+func ToReader(db *badger.DB) storage.Reader {
+	return dbReader{db: db}
+}
+
+// Get returns a copy of the value stored under the given key.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if no value is stored under the key
+func (b dbReader) Get(key []byte) ([]byte, io.Closer, error) {
+	var value []byte
+	err := b.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, irrecoverable.NewExceptionf("could not get value: %w", err)
+	}
+	return value, noopCloser{}, nil
+}
+
+// NewIter returns an iterator over all keys which have a prefix within [startPrefix, endPrefix].
+// The iterator reads from a read-only transaction, which is discarded when the iterator is closed.
+// No errors are expected during normal operation.
+func (b dbReader) NewIter(startPrefix, endPrefix []byte, ops storage.IteratorOption) (storage.Iterator, error) {
+	return newBadgerIterator(b.db, startPrefix, endPrefix, ops), nil
+}
+
+type noopCloser struct{}
+
+func (noopCloser) Close() error { return nil }
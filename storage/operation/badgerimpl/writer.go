@@ -0,0 +1,111 @@
+package badgerimpl
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/storage"
+)
+
+// ReaderBatchWriter is a batch of writes to a Badger database, backed by a badger.WriteBatch.
+// Badger write batches are split into multiple transactions if they exceed the transaction size
+// limit, hence a committed batch is NOT guaranteed to be applied atomically.
+type ReaderBatchWriter struct {
+	globalReader storage.Reader
+	batch        *badger.WriteBatch
+	callbacks    []func(error)
+}
+
+var _ storage.ReaderBatchWriter = (*ReaderBatchWriter)(nil)
+var _ storage.Writer = (*ReaderBatchWriter)(nil)
+
+// NewReaderBatchWriter returns a new batch of writes to the given database.
+func NewReaderBatchWriter(db *badger.DB) *ReaderBatchWriter {
+	return &ReaderBatchWriter{
+		globalReader: ToReader(db),
+		batch:        db.NewWriteBatch(),
+	}
+}
+
+// GlobalReader returns a reader of the committed database state, which doesn't see the writes of the batch.
+func (b *ReaderBatchWriter) GlobalReader() storage.Reader {
+	return b.globalReader
+}
+
+// Writer returns the writer of the batch.
+func (b *ReaderBatchWriter) Writer() storage.Writer {
+	return b
+}
+
+// AddCallback adds a function which is called with the result of committing the batch.
+func (b *ReaderBatchWriter) AddCallback(callback func(error)) {
+	b.callbacks = append(b.callbacks, callback)
+}
+
+// Commit flushes the batch to the database, and calls the callbacks with the result.
+// No errors are expected during normal operation.
+func (b *ReaderBatchWriter) Commit() error {
+	err := b.batch.Flush()
+	if err != nil {
+		err = irrecoverable.NewExceptionf("could not commit batch: %w", err)
+	}
+	for _, callback := range b.callbacks {
+		callback(err)
+	}
+	return err
+}
+
+// Discard discards the writes of the batch. It is a no-op if the batch is committed.
+func (b *ReaderBatchWriter) Discard() {
+	b.batch.Cancel()
+}
+
+// Set stores the value under the given key, overwriting any existing value.
+// No errors are expected during normal operation.
+func (b *ReaderBatchWriter) Set(key, value []byte) error {
+	// badger keeps references to the key and value until the batch is flushed
+	k := make([]byte, len(key))
+	copy(k, key)
+	v := make([]byte, len(value))
+	copy(v, value)
+
+	err := b.batch.Set(k, v)
+	if err != nil {
+		return irrecoverable.NewExceptionf("could not set key: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes the value stored under the given key, if any.
+// No errors are expected during normal operation.
+func (b *ReaderBatchWriter) Delete(key []byte) error {
+	k := make([]byte, len(key))
+	copy(k, key)
+
+	err := b.batch.Delete(k)
+	if err != nil {
+		return irrecoverable.NewExceptionf("could not delete key: %w", err)
+	}
+	return nil
+}
+
+// DeleteByRange deletes all keys read from the given reader which have a prefix within
+// [startPrefix, endPrefix]. Badger doesn't support range deletions, hence each key is deleted.
+// No errors are expected during normal operation.
+func (b *ReaderBatchWriter) DeleteByRange(globalReader storage.Reader, startPrefix, endPrefix []byte) error {
+	it, err := globalReader.NewIter(startPrefix, endPrefix, storage.IteratorOption{KeysOnly: true})
+	if err != nil {
+		return fmt.Errorf("could not create iterator: %w", err)
+	}
+	defer it.Close()
+
+	for it.First(); it.Valid(); it.Next() {
+		err := b.Delete(it.IterItem().KeyCopy(nil))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
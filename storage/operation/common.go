@@ -0,0 +1,203 @@
+// Package operation contains the backend-neutral counterparts of the low-level operations in
+// storage/badger/operation, which read from a storage.Reader and write to a storage.Writer, so that
+// they work with any database backend. Entities are encoded with msgpack, as in storage/badger/operation,
+// hence both can be used on the same data.
+package operation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/storage"
+)
+
+// UpsertByKey encodes the given entity with msgpack and stores it under the given key, overwriting any
+// existing value.
+// No errors are expected during normal operation.
+func UpsertByKey(key []byte, entity interface{}) func(storage.Writer) error {
+	return func(w storage.Writer) error {
+		val, err := msgpack.Marshal(entity)
+		if err != nil {
+			return irrecoverable.NewExceptionf("could not encode entity: %w", err)
+		}
+
+		err = w.Set(key, val)
+		if err != nil {
+			return irrecoverable.NewExceptionf("could not store data: %w", err)
+		}
+		return nil
+	}
+}
+
+// RemoveByKey deletes the value stored under the given key. It is a no-op if no value is stored.
+// No errors are expected during normal operation.
+func RemoveByKey(key []byte) func(storage.Writer) error {
+	return func(w storage.Writer) error {
+		err := w.Delete(key)
+		if err != nil {
+			return irrecoverable.NewExceptionf("could not delete item: %w", err)
+		}
+		return nil
+	}
+}
+
+// RemoveByKeyPrefix deletes all keys with the given prefix, as read from the given reader.
+// No errors are expected during normal operation.
+func RemoveByKeyPrefix(r storage.Reader, prefix []byte) func(storage.Writer) error {
+	return RemoveByKeyRange(r, prefix, prefix)
+}
+
+// RemoveByKeyRange deletes all keys which have a prefix within [start, end], as read from the given reader.
+// No errors are expected during normal operation.
+func RemoveByKeyRange(r storage.Reader, start, end []byte) func(storage.Writer) error {
+	return func(w storage.Writer) error {
+		if bytes.Compare(start, end) > 0 {
+			return fmt.Errorf("start prefix %x is larger than end prefix %x", start, end)
+		}
+
+		err := w.DeleteByRange(r, start, end)
+		if err != nil {
+			return irrecoverable.NewExceptionf("could not delete range: %w", err)
+		}
+		return nil
+	}
+}
+
+// RetrieveByKey reads the value stored under the given key and decodes it into the given entity, which
+// must be a pointer to an initialized entity of the correct type.
+// Error returns:
+//   - storage.ErrNotFound if no value is stored under the key
+//   - generic error in case of unexpected failure from the database layer, or failure to decode the value
+func RetrieveByKey(key []byte, entity interface{}) func(storage.Reader) error {
+	return func(r storage.Reader) error {
+		val, closer, err := r.Get(key)
+		if err != nil {
+			return err
+		}
+		defer closer.Close()
+
+		err = msgpack.Unmarshal(val, entity)
+		if err != nil {
+			return irrecoverable.NewExceptionf("could not decode entity: %w", err)
+		}
+		return nil
+	}
+}
+
+// KeyExists sets keyExists to whether a value is stored under the given key.
+// No errors are expected during normal operation.
+func KeyExists(key []byte, keyExists *bool) func(storage.Reader) error {
+	return func(r storage.Reader) error {
+		_, closer, err := r.Get(key)
+		if errors.Is(err, storage.ErrNotFound) {
+			*keyExists = false
+			return nil
+		}
+		if err != nil {
+			return irrecoverable.NewExceptionf("could not load data: %w", err)
+		}
+		defer closer.Close()
+
+		*keyExists = true
+		return nil
+	}
+}
+
+// CheckFunc is called during key iteration in order to check whether the given key-value pair should
+// be processed. It allows to skip loading values which are not of interest.
+type CheckFunc func(key []byte) bool
+
+// CreateFunc returns a pointer to an initialized entity the next value is decoded into.
+type CreateFunc func() interface{}
+
+// HandleFunc processes the current key-value pair, after the key was checked and the value decoded.
+// No errors are expected during normal operation. Any errors will halt the iteration.
+type HandleFunc func() error
+
+// IterationFunc initializes the functions which check, decode and process the key-value pairs of an
+// iteration, see storage/badger/operation for details.
+type IterationFunc func() (CheckFunc, CreateFunc, HandleFunc)
+
+// Lookup is the default iteration function, collecting a list of entity IDs from an index.
+func Lookup(entityIDs *[]flow.Identifier) IterationFunc {
+	*entityIDs = make([]flow.Identifier, 0, len(*entityIDs))
+	return func() (CheckFunc, CreateFunc, HandleFunc) {
+		check := func(key []byte) bool {
+			return true
+		}
+		var entityID flow.Identifier
+		create := func() interface{} {
+			return &entityID
+		}
+		handle := func() error {
+			*entityIDs = append(*entityIDs, entityID)
+			return nil
+		}
+		return check, create, handle
+	}
+}
+
+// IterateKeys iterates in ascending order over all keys which have a prefix within [start, end], both
+// inclusive, and calls the iteration function for each of them.
+// No errors are expected during normal operation. Any errors returned by the provided HandleFunc
+// will be propagated back to the caller of IterateKeys.
+func IterateKeys(start []byte, end []byte, iteration IterationFunc, opt storage.IteratorOption) func(storage.Reader) error {
+	return func(r storage.Reader) error {
+		if bytes.Compare(start, end) > 0 {
+			return fmt.Errorf("start prefix %x is larger than end prefix %x", start, end)
+		}
+
+		it, err := r.NewIter(start, end, opt)
+		if err != nil {
+			return fmt.Errorf("could not create iterator: %w", err)
+		}
+		defer it.Close()
+
+		for it.First(); it.Valid(); it.Next() {
+			item := it.IterItem()
+			check, create, handle := iteration()
+
+			if !check(item.Key()) {
+				continue
+			}
+			if opt.KeysOnly {
+				// the entity isn't decoded if values aren't loaded
+				err = handle()
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			entity := create()
+			err = item.Value(func(val []byte) error {
+				err := msgpack.Unmarshal(val, entity)
+				if err != nil {
+					return irrecoverable.NewExceptionf("could not decode entity: %w", err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			err = handle()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// TraverseByPrefix iterates over all keys with the given prefix, and calls the iteration function for each of them.
+// No errors are expected during normal operation. Any errors returned by the provided HandleFunc
+// will be propagated back to the caller of TraverseByPrefix.
+func TraverseByPrefix(prefix []byte, iteration IterationFunc) func(storage.Reader) error {
+	return IterateKeys(prefix, prefix, iteration, storage.DefaultIteratorOptions())
+}
@@ -0,0 +1,484 @@
+package operation_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation"
+	"github.com/onflow/flow-go/storage/operation/dbtest"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+type Entity struct {
+	ID uint64
+}
+
+type UnencodeableEntity Entity
+
+var errCantEncode = fmt.Errorf("encoding not supported")
+var errCantDecode = fmt.Errorf("decoding not supported")
+
+func (a UnencodeableEntity) MarshalMsgpack() ([]byte, error) {
+	return nil, errCantEncode
+}
+
+func (a UnencodeableEntity) UnmarshalMsgpack(b []byte) error {
+	return errCantDecode
+}
+
+// getValue returns a copy of the raw value stored under the given key.
+func getValue(t *testing.T, r storage.Reader, key []byte) []byte {
+	val, closer, err := r.Get(key)
+	require.NoError(t, err)
+	defer closer.Close()
+	return append([]byte(nil), val...)
+}
+
+func TestUpsertValid(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		e := Entity{ID: 1337}
+		key := []byte{0x01, 0x02, 0x03}
+		val, _ := msgpack.Marshal(e)
+
+		err := dbtest.WithWriter(db)(operation.UpsertByKey(key, e))
+		require.NoError(t, err)
+		assert.Equal(t, val, getValue(t, db.Reader(), key))
+
+		// upserting the same key overwrites the value
+		newEntity := Entity{ID: 1338}
+		newVal, _ := msgpack.Marshal(newEntity)
+		err = dbtest.WithWriter(db)(operation.UpsertByKey(key, newEntity))
+		require.NoError(t, err)
+		assert.Equal(t, newVal, getValue(t, db.Reader(), key))
+	})
+}
+
+func TestUpsertEncodingError(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		e := Entity{ID: 1337}
+		key := []byte{0x01, 0x02, 0x03}
+
+		err := dbtest.WithWriter(db)(operation.UpsertByKey(key, UnencodeableEntity(e)))
+		require.Error(t, err)
+
+		// nothing is written if the batch fails
+		var exists bool
+		require.NoError(t, operation.KeyExists(key, &exists)(db.Reader()))
+		assert.False(t, exists)
+	})
+}
+
+func TestRetrieveValid(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		e := Entity{ID: 1337}
+		key := []byte{0x01, 0x02, 0x03}
+
+		err := dbtest.WithWriter(db)(operation.UpsertByKey(key, e))
+		require.NoError(t, err)
+
+		var act Entity
+		err = operation.RetrieveByKey(key, &act)(db.Reader())
+		require.NoError(t, err)
+		assert.Equal(t, e, act)
+	})
+}
+
+func TestRetrieveMissing(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		key := []byte{0x01, 0x02, 0x03}
+
+		var act Entity
+		err := operation.RetrieveByKey(key, &act)(db.Reader())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+func TestRetrieveUnencodeable(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		e := Entity{ID: 1337}
+		key := []byte{0x01, 0x02, 0x03}
+
+		err := dbtest.WithWriter(db)(operation.UpsertByKey(key, e))
+		require.NoError(t, err)
+
+		var act *UnencodeableEntity
+		err = operation.RetrieveByKey(key, &act)(db.Reader())
+		require.Error(t, err)
+		require.NotErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+// TestExists verifies that `KeyExists` returns correct results in different scenarios.
+func TestExists(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		t.Run("non-existent key", func(t *testing.T) {
+			key := unittest.RandomBytes(32)
+			var exists bool
+			err := operation.KeyExists(key, &exists)(db.Reader())
+			require.NoError(t, err)
+			assert.False(t, exists)
+		})
+
+		t.Run("existent key", func(t *testing.T) {
+			key := unittest.RandomBytes(32)
+			err := dbtest.WithWriter(db)(operation.UpsertByKey(key, unittest.RandomBytes(256)))
+			require.NoError(t, err)
+
+			var exists bool
+			err = operation.KeyExists(key, &exists)(db.Reader())
+			require.NoError(t, err)
+			assert.True(t, exists)
+		})
+
+		t.Run("removed key", func(t *testing.T) {
+			key := unittest.RandomBytes(32)
+			// insert, then remove the key
+			err := dbtest.WithWriter(db)(operation.UpsertByKey(key, unittest.RandomBytes(256)))
+			require.NoError(t, err)
+			err = dbtest.WithWriter(db)(operation.RemoveByKey(key))
+			require.NoError(t, err)
+
+			var exists bool
+			err = operation.KeyExists(key, &exists)(db.Reader())
+			require.NoError(t, err)
+			assert.False(t, exists)
+		})
+	})
+}
+
+func TestLookup(t *testing.T) {
+	expected := []flow.Identifier{
+		{0x01},
+		{0x02},
+		{0x03},
+		{0x04},
+	}
+	actual := []flow.Identifier{}
+
+	iterationFunc := operation.Lookup(&actual)
+
+	for _, e := range expected {
+		checkFunc, createFunc, handleFunc := iterationFunc()
+		assert.True(t, checkFunc([]byte{0x00}))
+		target := createFunc()
+		assert.IsType(t, &flow.Identifier{}, target)
+
+		// set the value to target. Need to use reflection here since target is not strongly typed
+		reflect.ValueOf(target).Elem().Set(reflect.ValueOf(e))
+
+		assert.NoError(t, handleFunc())
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestIterate(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		keys := [][]byte{{0x00}, {0x12}, {0xf0}, {0xff}}
+		vals := []bool{false, false, true, true}
+		expected := []bool{false, true}
+
+		err := dbtest.WithWriter(db)(func(w storage.Writer) error {
+			for i, key := range keys {
+				err := operation.UpsertByKey(key, vals[i])(w)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		actual := make([]bool, 0, len(keys))
+		iterationFunc := func() (operation.CheckFunc, operation.CreateFunc, operation.HandleFunc) {
+			check := func(key []byte) bool {
+				return !bytes.Equal(key, []byte{0x12})
+			}
+			var val bool
+			create := func() interface{} {
+				return &val
+			}
+			handle := func() error {
+				actual = append(actual, val)
+				return nil
+			}
+			return check, create, handle
+		}
+
+		err = operation.IterateKeys(keys[0], keys[2], iterationFunc, storage.DefaultIteratorOptions())(db.Reader())
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+
+		// iterating from a higher to a lower prefix is not supported
+		err = operation.IterateKeys(keys[2], keys[0], iterationFunc, storage.DefaultIteratorOptions())(db.Reader())
+		require.Error(t, err)
+	})
+}
+
+func TestTraverse(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		keys := [][]byte{{0x42, 0x00}, {0xff}, {0x42, 0x56}, {0x00}, {0x42, 0xff}}
+		vals := []bool{false, false, true, false, true}
+		expected := []bool{false, true}
+
+		err := dbtest.WithWriter(db)(func(w storage.Writer) error {
+			for i, key := range keys {
+				err := operation.UpsertByKey(key, vals[i])(w)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		actual := make([]bool, 0, len(keys))
+		iterationFunc := func() (operation.CheckFunc, operation.CreateFunc, operation.HandleFunc) {
+			check := func(key []byte) bool {
+				return !bytes.Equal(key, []byte{0x42, 0x56})
+			}
+			var val bool
+			create := func() interface{} {
+				return &val
+			}
+			handle := func() error {
+				actual = append(actual, val)
+				return nil
+			}
+			return check, create, handle
+		}
+
+		err = operation.TraverseByPrefix([]byte{0x42}, iterationFunc)(db.Reader())
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestRemove(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		e := Entity{ID: 1337}
+		key := []byte{0x01, 0x02, 0x03}
+
+		err := dbtest.WithWriter(db)(operation.UpsertByKey(key, e))
+		require.NoError(t, err)
+
+		t.Run("should be able to remove", func(t *testing.T) {
+			err := dbtest.WithWriter(db)(operation.RemoveByKey(key))
+			require.NoError(t, err)
+
+			var act Entity
+			err = operation.RetrieveByKey(key, &act)(db.Reader())
+			require.ErrorIs(t, err, storage.ErrNotFound)
+		})
+
+		t.Run("should no-op when removing non-existing value", func(t *testing.T) {
+			nonexistentKey := append(key, 0x01)
+			err := dbtest.WithWriter(db)(operation.RemoveByKey(nonexistentKey))
+			require.NoError(t, err)
+		})
+	})
+}
+
+func TestRemoveByPrefix(t *testing.T) {
+	t.Run("should no-op when removing non-existing value", func(t *testing.T) {
+		dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+			e := Entity{ID: 1337}
+			key := []byte{0x01, 0x02, 0x03}
+
+			err := dbtest.WithWriter(db)(operation.UpsertByKey(key, e))
+			require.NoError(t, err)
+
+			nonexistentKey := append(key, 0x01)
+			err = dbtest.WithWriter(db)(operation.RemoveByKeyPrefix(db.Reader(), nonexistentKey))
+			require.NoError(t, err)
+
+			var act Entity
+			err = operation.RetrieveByKey(key, &act)(db.Reader())
+			require.NoError(t, err)
+			assert.Equal(t, e, act)
+		})
+	})
+
+	t.Run("should be able to remove", func(t *testing.T) {
+		dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+			keys := [][]byte{{0x01, 0x02, 0x03}, {0x01, 0x02}, {0x01, 0x02, 0xff, 0xff}}
+			kept := []byte{0x01, 0x03}
+			err := dbtest.WithWriter(db)(func(w storage.Writer) error {
+				for _, key := range append(keys, kept) {
+					err := operation.UpsertByKey(key, Entity{ID: 1337})(w)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+
+			err = dbtest.WithWriter(db)(operation.RemoveByKeyPrefix(db.Reader(), []byte{0x01, 0x02}))
+			require.NoError(t, err)
+
+			for _, key := range keys {
+				var exists bool
+				require.NoError(t, operation.KeyExists(key, &exists)(db.Reader()))
+				assert.False(t, exists, "key %x should be removed", key)
+			}
+			var exists bool
+			require.NoError(t, operation.KeyExists(kept, &exists)(db.Reader()))
+			assert.True(t, exists)
+		})
+	})
+
+	t.Run("should be able to remove unbounded prefix", func(t *testing.T) {
+		dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+			keys := [][]byte{{0xff}, {0xff, 0xff, 0x01}}
+			err := dbtest.WithWriter(db)(func(w storage.Writer) error {
+				for _, key := range keys {
+					err := operation.UpsertByKey(key, Entity{ID: 1337})(w)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+
+			err = dbtest.WithWriter(db)(operation.RemoveByKeyPrefix(db.Reader(), []byte{0xff}))
+			require.NoError(t, err)
+
+			for _, key := range keys {
+				var exists bool
+				require.NoError(t, operation.KeyExists(key, &exists)(db.Reader()))
+				assert.False(t, exists, "key %x should be removed", key)
+			}
+		})
+	})
+}
+
+func TestIterateBoundaries(t *testing.T) {
+
+	// create range of keys covering all boundaries around our start/end values
+	start := []byte{0x10}
+	end := []byte{0x20}
+	keys := [][]byte{
+		// before start -> not included in range
+		{0x09, 0xff},
+		// shares prefix with start -> included in range
+		{0x10, 0x00},
+		{0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		{0x10, 0xff},
+		{0x10, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		// prefix between start and end -> included in range
+		{0x11, 0x00},
+		{0x19, 0xff},
+		// shares prefix with end -> included in range
+		{0x20, 0x00},
+		{0x20, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		{0x20, 0xff},
+		{0x20, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		// after end -> not included in range
+		{0x21, 0x00},
+	}
+
+	// keys within the expected range
+	keysInRange := keys[1:11]
+
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+
+		// insert the keys into the database
+		err := dbtest.WithWriter(db)(func(w storage.Writer) error {
+			for _, key := range keys {
+				err := w.Set(key, []byte{0x00})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		// define iteration function that simply appends all traversed keys
+		var found [][]byte
+		iteration := func() (operation.CheckFunc, operation.CreateFunc, operation.HandleFunc) {
+			check := func(key []byte) bool {
+				found = append(found, append([]byte(nil), key...))
+				return false
+			}
+			create := func() interface{} {
+				return nil
+			}
+			handle := func() error {
+				return fmt.Errorf("shouldn't handle anything")
+			}
+			return check, create, handle
+		}
+
+		// iterate and check boundaries are included correctly
+		err = operation.IterateKeys(start, end, iteration, storage.IteratorOption{KeysOnly: true})(db.Reader())
+		require.NoError(t, err, "should iterate without error")
+		assert.ElementsMatch(t, keysInRange, found, "iteration should go over correct keys")
+	})
+}
+
+func TestReaderBatchWriter(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		key := []byte{0x01}
+
+		t.Run("writes are visible after commit only", func(t *testing.T) {
+			var committed error = fmt.Errorf("callback not called")
+			err := db.WithReaderBatchWriter(func(rw storage.ReaderBatchWriter) error {
+				rw.AddCallback(func(err error) {
+					committed = err
+				})
+
+				err := operation.UpsertByKey(key, Entity{ID: 1})(rw.Writer())
+				require.NoError(t, err)
+
+				var exists bool
+				require.NoError(t, operation.KeyExists(key, &exists)(rw.GlobalReader()))
+				assert.False(t, exists)
+				return nil
+			})
+			require.NoError(t, err)
+			require.NoError(t, committed)
+
+			var act Entity
+			require.NoError(t, operation.RetrieveByKey(key, &act)(db.Reader()))
+			assert.Equal(t, Entity{ID: 1}, act)
+		})
+
+		t.Run("writes are discarded on error", func(t *testing.T) {
+			expected := fmt.Errorf("failed")
+			called := false
+			err := db.WithReaderBatchWriter(func(rw storage.ReaderBatchWriter) error {
+				rw.AddCallback(func(error) {
+					called = true
+				})
+
+				err := operation.UpsertByKey(key, Entity{ID: 2})(rw.Writer())
+				require.NoError(t, err)
+				return expected
+			})
+			require.ErrorIs(t, err, expected)
+			assert.False(t, called)
+
+			var act Entity
+			require.NoError(t, operation.RetrieveByKey(key, &act)(db.Reader()))
+			assert.Equal(t, Entity{ID: 1}, act)
+		})
+	})
+}
+
+func TestPrefixUpperBound(t *testing.T) {
+	assert.Equal(t, []byte{0x01, 0x03}, storage.PrefixUpperBound([]byte{0x01, 0x02}))
+	assert.Equal(t, []byte{0x02}, storage.PrefixUpperBound([]byte{0x01, 0xff}))
+	assert.Nil(t, storage.PrefixUpperBound([]byte{0xff, 0xff}))
+	assert.Nil(t, storage.PrefixUpperBound(nil))
+}
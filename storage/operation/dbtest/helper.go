@@ -0,0 +1,48 @@
+package dbtest
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation/badgerimpl"
+	"github.com/onflow/flow-go/storage/operation/pebbleimpl"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// RunWithDB runs the given test against an empty database of each supported backend.
+func RunWithDB(t *testing.T, f func(t *testing.T, db storage.DB)) {
+	t.Run("badger", func(t *testing.T) {
+		unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+			f(t, badgerimpl.ToDB(db))
+		})
+	})
+
+	t.Run("pebble", func(t *testing.T) {
+		RunWithPebbleDB(t, func(db *pebble.DB) {
+			f(t, pebbleimpl.ToDB(db))
+		})
+	})
+}
+
+// RunWithPebbleDB runs the given function with an empty Pebble database in a temporary directory.
+func RunWithPebbleDB(t testing.TB, f func(*pebble.DB)) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		db, err := pebble.Open(dir, &pebble.Options{})
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, db.Close())
+		}()
+		f(db)
+	})
+}
+
+// WithWriter returns a function which writes to the database in a single batch.
+func WithWriter(db storage.DB) func(func(storage.Writer) error) error {
+	return func(fn func(storage.Writer) error) error {
+		return db.WithReaderBatchWriter(storage.OnlyWriter(fn))
+	}
+}
@@ -0,0 +1,18 @@
+package operation
+
+import (
+	"github.com/onflow/flow-go/storage"
+)
+
+// RetrieveProcessedIndex retrieves the processed index of a job consumer.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the processed index of the job consumer was never stored
+func RetrieveProcessedIndex(jobName string, processed *uint64) func(storage.Reader) error {
+	return RetrieveByKey(MakePrefix(codeJobConsumerProcessed, jobName), processed)
+}
+
+// UpsertProcessedIndex stores the processed index of a job consumer, overwriting any existing index.
+// No errors are expected during normal operation.
+func UpsertProcessedIndex(jobName string, processed uint64) func(storage.Writer) error {
+	return UpsertByKey(MakePrefix(codeJobConsumerProcessed, jobName), processed)
+}
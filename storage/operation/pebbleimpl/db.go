@@ -0,0 +1,33 @@
+package pebbleimpl
+
+import (
+	"github.com/cockroachdb/pebble"
+
+	"github.com/onflow/flow-go/storage"
+)
+
+type dbStore struct {
+	db *pebble.DB
+}
+
+var _ storage.DB = (*dbStore)(nil)
+
+// ToDB returns the given Pebble database as a backend-neutral storage.DB.
+func ToDB(db *pebble.DB) storage.DB {
+	return &dbStore{db: db}
+}
+
+func (b *dbStore) Reader() storage.Reader {
+	return ToReader(b.db)
+}
+
+func (b *dbStore) WithReaderBatchWriter(fn func(storage.ReaderBatchWriter) error) error {
+	batch := NewReaderBatchWriter(b.db)
+	defer batch.Close()
+
+	err := fn(batch)
+	if err != nil {
+		return err
+	}
+	return batch.Commit()
+}
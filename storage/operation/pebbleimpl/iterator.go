@@ -0,0 +1,76 @@
+package pebbleimpl
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/pebble"
+
+	"github.com/onflow/flow-go/storage"
+)
+
+type pebbleIterator struct {
+	iter *pebble.Iterator
+}
+
+var _ storage.Iterator = (*pebbleIterator)(nil)
+
+func newPebbleIterator(db *pebble.DB, startPrefix, endPrefix []byte) *pebbleIterator {
+	return &pebbleIterator{
+		iter: db.NewIter(&pebble.IterOptions{
+			LowerBound: startPrefix,
+			UpperBound: storage.PrefixUpperBound(endPrefix),
+		}),
+	}
+}
+
+// First positions the iterator at the first key of the range, and returns whether it is valid.
+func (i *pebbleIterator) First() bool {
+	return i.iter.First()
+}
+
+// Valid returns whether the iterator is positioned at a key within the range.
+func (i *pebbleIterator) Valid() bool {
+	return i.iter.Valid()
+}
+
+// Next moves the iterator to the next key.
+func (i *pebbleIterator) Next() {
+	i.iter.Next()
+}
+
+// IterItem returns the item the iterator is positioned at.
+func (i *pebbleIterator) IterItem() storage.IterItem {
+	return pebbleIterItem{iter: i.iter}
+}
+
+// Close closes the iterator.
+// No errors are expected during normal operation.
+func (i *pebbleIterator) Close() error {
+	err := i.iter.Close()
+	if err != nil {
+		return fmt.Errorf("could not close iterator: %w", err)
+	}
+	return nil
+}
+
+type pebbleIterItem struct {
+	iter *pebble.Iterator
+}
+
+var _ storage.IterItem = (*pebbleIterItem)(nil)
+
+func (i pebbleIterItem) Key() []byte {
+	return i.iter.Key()
+}
+
+func (i pebbleIterItem) KeyCopy(dst []byte) []byte {
+	return append(dst[:0], i.iter.Key()...)
+}
+
+func (i pebbleIterItem) Value(fn func(val []byte) error) error {
+	value, err := i.iter.ValueAndErr()
+	if err != nil {
+		return fmt.Errorf("could not load value: %w", err)
+	}
+	return fn(value)
+}
@@ -0,0 +1,29 @@
+package pebbleimpl
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// DefaultCacheSize is the default size of the block cache of a Pebble database.
+const DefaultCacheSize = 1 << 30 // 1 GiB
+
+// OpenDefaultPebbleDB opens the Pebble database in the given directory, creating it if it doesn't exist.
+// No errors are expected during normal operation.
+func OpenDefaultPebbleDB(dir string) (*pebble.DB, error) {
+	cache := pebble.NewCache(DefaultCacheSize)
+	defer cache.Unref()
+
+	opts := &pebble.Options{
+		Cache:                    cache,
+		MemTableSize:             64 << 20,
+		MaxConcurrentCompactions: func() int { return 4 },
+	}
+
+	db, err := pebble.Open(dir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not open pebble db at %s: %w", dir, err)
+	}
+	return db, nil
+}
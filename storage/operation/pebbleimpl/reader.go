@@ -0,0 +1,42 @@
+package pebbleimpl
+
+import (
+	"errors"
+	"io"
+
+	"github.com/cockroachdb/pebble"
+
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/storage"
+)
+
+type dbReader struct {
+	db *pebble.DB
+}
+
+var _ storage.Reader = (*dbReader)(nil)
+
+// ToReader returns a reader of the committed state of the given Pebble database.
+func ToReader(db *pebble.DB) storage.Reader {
+	return dbReader{db: db}
+}
+
+// Get returns the value stored under the given key. The value is only valid until the closer is called.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if no value is stored under the key
+func (b dbReader) Get(key []byte) ([]byte, io.Closer, error) {
+	value, closer, err := b.db.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, irrecoverable.NewExceptionf("could not get value: %w", err)
+	}
+	return value, closer, nil
+}
+
+// NewIter returns an iterator over all keys which have a prefix within [startPrefix, endPrefix].
+// No errors are expected during normal operation.
+func (b dbReader) NewIter(startPrefix, endPrefix []byte, _ storage.IteratorOption) (storage.Iterator, error) {
+	return newPebbleIterator(b.db, startPrefix, endPrefix), nil
+}
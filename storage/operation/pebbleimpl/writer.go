@@ -0,0 +1,116 @@
+package pebbleimpl
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/pebble"
+
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/storage"
+)
+
+// ReaderBatchWriter is a batch of writes to a Pebble database, which is committed atomically.
+type ReaderBatchWriter struct {
+	globalReader storage.Reader
+	batch        *pebble.Batch
+	callbacks    []func(error)
+}
+
+var _ storage.ReaderBatchWriter = (*ReaderBatchWriter)(nil)
+var _ storage.Writer = (*ReaderBatchWriter)(nil)
+
+// NewReaderBatchWriter returns a new batch of writes to the given database.
+func NewReaderBatchWriter(db *pebble.DB) *ReaderBatchWriter {
+	return &ReaderBatchWriter{
+		globalReader: ToReader(db),
+		batch:        db.NewBatch(),
+	}
+}
+
+// GlobalReader returns a reader of the committed database state, which doesn't see the writes of the batch.
+func (b *ReaderBatchWriter) GlobalReader() storage.Reader {
+	return b.globalReader
+}
+
+// Writer returns the writer of the batch.
+func (b *ReaderBatchWriter) Writer() storage.Writer {
+	return b
+}
+
+// AddCallback adds a function which is called with the result of committing the batch.
+func (b *ReaderBatchWriter) AddCallback(callback func(error)) {
+	b.callbacks = append(b.callbacks, callback)
+}
+
+// Commit commits the batch to the database, and calls the callbacks with the result.
+// The batch is synced to disk before Commit returns.
+// No errors are expected during normal operation.
+func (b *ReaderBatchWriter) Commit() error {
+	err := b.batch.Commit(pebble.Sync)
+	if err != nil {
+		err = irrecoverable.NewExceptionf("could not commit batch: %w", err)
+	}
+	for _, callback := range b.callbacks {
+		callback(err)
+	}
+	return err
+}
+
+// Close releases the batch. The writes of the batch are discarded, unless it was committed.
+// No errors are expected during normal operation.
+func (b *ReaderBatchWriter) Close() error {
+	return b.batch.Close()
+}
+
+// Set stores the value under the given key, overwriting any existing value.
+// No errors are expected during normal operation.
+func (b *ReaderBatchWriter) Set(key, value []byte) error {
+	err := b.batch.Set(key, value, pebble.Sync)
+	if err != nil {
+		return irrecoverable.NewExceptionf("could not set key: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes the value stored under the given key, if any.
+// No errors are expected during normal operation.
+func (b *ReaderBatchWriter) Delete(key []byte) error {
+	err := b.batch.Delete(key, pebble.Sync)
+	if err != nil {
+		return irrecoverable.NewExceptionf("could not delete key: %w", err)
+	}
+	return nil
+}
+
+// DeleteByRange deletes all keys which have a prefix within [startPrefix, endPrefix] using a single
+// range deletion. The given reader is only used if the range is unbounded, i.e. the end prefix only
+// consists of 0xff bytes, in which case the keys are deleted one by one.
+// No errors are expected during normal operation.
+func (b *ReaderBatchWriter) DeleteByRange(globalReader storage.Reader, startPrefix, endPrefix []byte) error {
+	end := storage.PrefixUpperBound(endPrefix)
+	if end == nil {
+		return b.deleteEach(globalReader, startPrefix, endPrefix)
+	}
+
+	err := b.batch.DeleteRange(startPrefix, end, pebble.Sync)
+	if err != nil {
+		return irrecoverable.NewExceptionf("could not delete range: %w", err)
+	}
+	return nil
+}
+
+func (b *ReaderBatchWriter) deleteEach(globalReader storage.Reader, startPrefix, endPrefix []byte) error {
+	it, err := globalReader.NewIter(startPrefix, endPrefix, storage.IteratorOption{KeysOnly: true})
+	if err != nil {
+		return fmt.Errorf("could not create iterator: %w", err)
+	}
+	defer it.Close()
+
+	for it.First(); it.Valid(); it.Next() {
+		err := b.Delete(it.IterItem().Key())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package operation
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// The codes below must match the codes of the same data in storage/badger/operation.
+const (
+	// heights of the execution state registers
+	codeRegistersFirstHeight    = 27 // height of the execution state the registers were bootstrapped from
+	codeRegistersLatestHeight   = 28 // latest height whose register updates have been stored
+	codeRegistersSkippedHeights = 29 // ranges of heights whose register updates have not been stored
+
+	codeJobConsumerProcessed = 70
+
+	// execution state registers, keyed by register ID and height
+	codeRegister = 108
+
+	// historical registers of execution nodes
+	codeRegistersCommit   = 109 // state commitment of every height whose register updates have been stored
+	codePendingTrieUpdate = 110 // trie updates whose register updates have not been stored yet, keyed by resulting state
+	codePendingTrieSeq    = 111 // sequence number index of the pending trie updates
+)
+
+// MakePrefix builds a key from a code and the given key parts, encoded the same way as the keys in
+// storage/badger/operation, so that operations of both packages address the same data.
+func MakePrefix(code byte, keys ...interface{}) []byte {
+	prefix := make([]byte, 1)
+	prefix[0] = code
+	for _, key := range keys {
+		prefix = append(prefix, keyPartToBinary(key)...)
+	}
+	return prefix
+}
+
+func keyPartToBinary(v interface{}) []byte {
+	switch i := v.(type) {
+	case uint8:
+		return []byte{i}
+	case uint32:
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, i)
+		return b
+	case uint64:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, i)
+		return b
+	case string:
+		return []byte(i)
+	case flow.Role:
+		return []byte{byte(i)}
+	case flow.Identifier:
+		return i[:]
+	case flow.ChainID:
+		return []byte(i)
+	default:
+		panic(fmt.Sprintf("unsupported type to convert (%T)", v))
+	}
+}
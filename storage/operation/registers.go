@@ -0,0 +1,198 @@
+package operation
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/storage"
+)
+
+// The values of a register are keyed by the register ID followed by the one's complement of the
+// height, so that the values of a register are ordered from the highest to the lowest height, and
+// the value at a given height is the first key at or after the key of the height.
+// The lengths of the owner and the key are part of the key, so that the values of different
+// registers never interleave.
+
+// registerPrefix returns the prefix of the keys of all values of the given register.
+func registerPrefix(id flow.RegisterID) []byte {
+	return MakePrefix(codeRegister, uint8(len(id.Owner)), id.Owner, uint32(len(id.Key)), id.Key)
+}
+
+// registerKey returns the key of the value of the given register at the given height.
+func registerKey(id flow.RegisterID, height uint64) []byte {
+	return append(registerPrefix(id), keyPartToBinary(^height)...)
+}
+
+// decodeRegisterKey returns the register ID and the height of the given register key.
+func decodeRegisterKey(key []byte) (flow.RegisterID, uint64, error) {
+	// code, owner length, owner, key length, key and height
+	rest := key[1:]
+	if len(rest) < 1 || len(rest) < 1+int(rest[0]) {
+		return flow.RegisterID{}, 0, fmt.Errorf("owner out of range")
+	}
+	owner := string(rest[1 : 1+int(rest[0])])
+	rest = rest[1+int(rest[0]):]
+	if len(rest) < 4 {
+		return flow.RegisterID{}, 0, fmt.Errorf("key length out of range")
+	}
+	keyLength := binary.BigEndian.Uint32(rest)
+	rest = rest[4:]
+	if uint64(len(rest)) != uint64(keyLength)+8 {
+		return flow.RegisterID{}, 0, fmt.Errorf("key and height out of range")
+	}
+	id := flow.RegisterID{Owner: owner, Key: string(rest[:keyLength])}
+	return id, ^binary.BigEndian.Uint64(rest[keyLength:]), nil
+}
+
+// UpsertRegister stores the value of the given register at the given height, overwriting any
+// existing value at the height.
+// No errors are expected during normal operation.
+func UpsertRegister(id flow.RegisterID, height uint64, value flow.RegisterValue) func(storage.Writer) error {
+	return UpsertByKey(registerKey(id, height), value)
+}
+
+// RemoveRegister removes the value of the given register at the given height.
+// No errors are expected during normal operation.
+func RemoveRegister(id flow.RegisterID, height uint64) func(storage.Writer) error {
+	return RemoveByKey(registerKey(id, height))
+}
+
+// RetrieveRegister retrieves the value of the given register at the given height, which is the value
+// stored at the highest height at or below the given height.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if no value of the register is stored at or below the height
+func RetrieveRegister(id flow.RegisterID, height uint64, value *flow.RegisterValue) func(storage.Reader) error {
+	return func(r storage.Reader) error {
+		it, err := r.NewIter(registerKey(id, height), registerKey(id, 0), storage.DefaultIteratorOptions())
+		if err != nil {
+			return fmt.Errorf("could not create iterator: %w", err)
+		}
+		defer it.Close()
+
+		if !it.First() {
+			return storage.ErrNotFound
+		}
+
+		return it.IterItem().Value(func(val []byte) error {
+			err := msgpack.Unmarshal(val, value)
+			if err != nil {
+				return irrecoverable.NewExceptionf("could not decode register value: %w", err)
+			}
+			return nil
+		})
+	}
+}
+
+// RemoveAllRegisters removes the values of all registers at all heights, as read from the given reader.
+// No errors are expected during normal operation.
+func RemoveAllRegisters(r storage.Reader) func(storage.Writer) error {
+	return RemoveByKeyPrefix(r, MakePrefix(codeRegister))
+}
+
+// UpsertRegistersFirstHeight stores the height of the execution state the registers were bootstrapped from.
+// No errors are expected during normal operation.
+func UpsertRegistersFirstHeight(height uint64) func(storage.Writer) error {
+	return UpsertByKey(MakePrefix(codeRegistersFirstHeight), height)
+}
+
+// RetrieveRegistersFirstHeight retrieves the height of the execution state the registers were bootstrapped from.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the registers were never bootstrapped
+func RetrieveRegistersFirstHeight(height *uint64) func(storage.Reader) error {
+	return RetrieveByKey(MakePrefix(codeRegistersFirstHeight), height)
+}
+
+// UpsertRegistersLatestHeight stores the latest height whose register updates have been stored.
+// No errors are expected during normal operation.
+func UpsertRegistersLatestHeight(height uint64) func(storage.Writer) error {
+	return UpsertByKey(MakePrefix(codeRegistersLatestHeight), height)
+}
+
+// RetrieveRegistersLatestHeight retrieves the latest height whose register updates have been stored.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the registers were never bootstrapped
+func RetrieveRegistersLatestHeight(height *uint64) func(storage.Reader) error {
+	return RetrieveByKey(MakePrefix(codeRegistersLatestHeight), height)
+}
+
+// UpsertRegistersSkippedHeights stores that the registers of the heights in [start, end] have not been stored.
+// No errors are expected during normal operation.
+func UpsertRegistersSkippedHeights(start uint64, end uint64) func(storage.Writer) error {
+	return UpsertByKey(MakePrefix(codeRegistersSkippedHeights, start), end)
+}
+
+// TraverseRegistersSkippedHeights calls fn with every range of heights whose registers have not been stored,
+// in ascending order.
+// No errors are expected during normal operation.
+func TraverseRegistersSkippedHeights(fn func(start uint64, end uint64)) func(storage.Reader) error {
+	return TraverseByPrefix(MakePrefix(codeRegistersSkippedHeights), func() (CheckFunc, CreateFunc, HandleFunc) {
+		var start, end uint64
+		check := func(key []byte) bool {
+			start = binary.BigEndian.Uint64(key[1:])
+			return true
+		}
+		create := func() interface{} {
+			return &end
+		}
+		handle := func() error {
+			fn(start, end)
+			return nil
+		}
+		return check, create, handle
+	})
+}
+
+// TraverseRegisters calls fn with the ID and the height of every stored register value, ordered by register
+// and from the highest to the lowest height of each register. The value is only decoded when fn calls the
+// given function. Errors returned by fn are propagated.
+// No errors are expected during normal operation.
+func TraverseRegisters(fn func(id flow.RegisterID, height uint64, value func() (flow.RegisterValue, error)) error) func(storage.Reader) error {
+	return func(r storage.Reader) error {
+		prefix := MakePrefix(codeRegister)
+		it, err := r.NewIter(prefix, prefix, storage.DefaultIteratorOptions())
+		if err != nil {
+			return fmt.Errorf("could not create iterator: %w", err)
+		}
+		defer it.Close()
+
+		for it.First(); it.Valid(); it.Next() {
+			item := it.IterItem()
+			id, height, err := decodeRegisterKey(item.Key())
+			if err != nil {
+				return irrecoverable.NewExceptionf("could not decode register key %x: %w", item.Key(), err)
+			}
+			value := func() (flow.RegisterValue, error) {
+				var value flow.RegisterValue
+				err := item.Value(func(val []byte) error {
+					return msgpack.Unmarshal(val, &value)
+				})
+				if err != nil {
+					return nil, irrecoverable.NewExceptionf("could not decode register value: %w", err)
+				}
+				return value, nil
+			}
+			err = fn(id, height, value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// UpsertRegistersCommit stores the state commitment of the execution state at the given height.
+// No errors are expected during normal operation.
+func UpsertRegistersCommit(height uint64, commit flow.StateCommitment) func(storage.Writer) error {
+	return UpsertByKey(MakePrefix(codeRegistersCommit, height), commit)
+}
+
+// RetrieveRegistersCommit retrieves the state commitment of the execution state at the given height.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if no state commitment is stored for the height
+func RetrieveRegistersCommit(height uint64, commit *flow.StateCommitment) func(storage.Reader) error {
+	return RetrieveByKey(MakePrefix(codeRegistersCommit, height), commit)
+}
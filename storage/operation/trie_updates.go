@@ -0,0 +1,89 @@
+package operation
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// Execution nodes keep the trie updates applied to the ledger until the registers they update have
+// been stored by height. The trie updates are keyed by the state they result in, and additionally
+// indexed by a sequence number in the order they were applied, so that old trie updates can be pruned.
+
+// pendingTrieUpdate is the stored value of a pending trie update.
+type pendingTrieUpdate struct {
+	Seq    uint64
+	Update []byte
+}
+
+func pendingTrieSeqKey(seq uint64, state flow.StateCommitment) []byte {
+	return MakePrefix(codePendingTrieSeq, seq, flow.Identifier(state))
+}
+
+// InsertPendingTrieUpdate stores the encoded trie update resulting in the given state, with the given sequence number.
+// A trie update resulting in the same state stored before is overwritten, its sequence number is still indexed.
+// No errors are expected during normal operation.
+func InsertPendingTrieUpdate(seq uint64, state flow.StateCommitment, update []byte) func(storage.Writer) error {
+	return func(w storage.Writer) error {
+		err := UpsertByKey(MakePrefix(codePendingTrieUpdate, flow.Identifier(state)), pendingTrieUpdate{Seq: seq, Update: update})(w)
+		if err != nil {
+			return err
+		}
+		return UpsertByKey(pendingTrieSeqKey(seq, state), seq)(w)
+	}
+}
+
+// RetrievePendingTrieUpdate retrieves the encoded trie update resulting in the given state, and its sequence number.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if no trie update resulting in the state is stored
+func RetrievePendingTrieUpdate(state flow.StateCommitment, seq *uint64, update *[]byte) func(storage.Reader) error {
+	return func(r storage.Reader) error {
+		var pending pendingTrieUpdate
+		err := RetrieveByKey(MakePrefix(codePendingTrieUpdate, flow.Identifier(state)), &pending)(r)
+		if err != nil {
+			return err
+		}
+		*seq = pending.Seq
+		*update = pending.Update
+		return nil
+	}
+}
+
+// RemovePendingTrieUpdate removes the trie update resulting in the given state.
+// No errors are expected during normal operation.
+func RemovePendingTrieUpdate(state flow.StateCommitment) func(storage.Writer) error {
+	return RemoveByKey(MakePrefix(codePendingTrieUpdate, flow.Identifier(state)))
+}
+
+// RemovePendingTrieSeq removes the given sequence number of the trie update resulting in the given state from the index.
+// No errors are expected during normal operation.
+func RemovePendingTrieSeq(seq uint64, state flow.StateCommitment) func(storage.Writer) error {
+	return RemoveByKey(pendingTrieSeqKey(seq, state))
+}
+
+// TraversePendingTrieSeqs calls fn with the sequence number and the resulting state of every indexed
+// trie update, in ascending order of the sequence numbers, until fn returns false.
+// No errors are expected during normal operation.
+func TraversePendingTrieSeqs(fn func(seq uint64, state flow.StateCommitment) bool) func(storage.Reader) error {
+	return func(r storage.Reader) error {
+		prefix := MakePrefix(codePendingTrieSeq)
+		it, err := r.NewIter(prefix, prefix, storage.IteratorOption{KeysOnly: true})
+		if err != nil {
+			return fmt.Errorf("could not create iterator: %w", err)
+		}
+		defer it.Close()
+
+		for it.First(); it.Valid(); it.Next() {
+			key := it.IterItem().Key()
+			seq := binary.BigEndian.Uint64(key[1:9])
+			var state flow.StateCommitment
+			copy(state[:], key[9:])
+			if !fn(seq, state) {
+				return nil
+			}
+		}
+		return nil
+	}
+}
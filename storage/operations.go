@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"io"
+)
+
+// The interfaces below abstract the key-value database the protocol data is stored in, so that storage
+// operations can be implemented independently of the database backend. Implementations exist for
+// Badger (storage/operation/badgerimpl) and Pebble (storage/operation/pebbleimpl). Storage implementations
+// on top of these interfaces live in storage/store, and are tested against both backends.
+
+// IterItem is a key-value pair an Iterator is positioned at.
+type IterItem interface {
+	// Key returns the key of the current item. The key is only valid until the iterator moves on.
+	Key() []byte
+
+	// KeyCopy returns a copy of the key of the current item, using dst if it is large enough.
+	KeyCopy(dst []byte) []byte
+
+	// Value calls fn with the value of the current item. The value is only valid during the call.
+	// No errors are expected during normal operation. Errors returned by fn are propagated.
+	Value(fn func(val []byte) error) error
+}
+
+// Iterator iterates over a range of keys in ascending order.
+type Iterator interface {
+	// First positions the iterator at the first key of the range, and returns whether it is valid.
+	First() bool
+
+	// Valid returns whether the iterator is positioned at a key within the range.
+	Valid() bool
+
+	// Next moves the iterator to the next key.
+	Next()
+
+	// IterItem returns the item the iterator is positioned at.
+	IterItem() IterItem
+
+	// Close releases the resources of the iterator. It must be called once the iteration is done.
+	// No errors are expected during normal operation.
+	Close() error
+}
+
+// IteratorOption configures an Iterator.
+type IteratorOption struct {
+	// KeysOnly indicates that the values of the iterated items are not accessed, which allows
+	// backends to skip loading them.
+	KeysOnly bool
+}
+
+// DefaultIteratorOptions returns the default options of an Iterator, which iterates keys and values.
+func DefaultIteratorOptions() IteratorOption {
+	return IteratorOption{
+		KeysOnly: false,
+	}
+}
+
+// Reader reads data from the database.
+type Reader interface {
+	// Get returns the value stored under the given key. The caller must call the returned closer once
+	// the value isn't used anymore, the value must not be modified.
+	// Expected errors during normal operation:
+	//   - storage.ErrNotFound if no value is stored under the key
+	Get(key []byte) (value []byte, closer io.Closer, err error)
+
+	// NewIter returns an iterator over all keys which have a prefix within [startPrefix, endPrefix],
+	// both inclusive. For example, with startPrefix 0x10 and endPrefix 0x12, the keys 0x10, 0x1000,
+	// 0x11ff and 0x12ff are included, while the keys 0x0fff and 0x13 are not.
+	// No errors are expected during normal operation.
+	NewIter(startPrefix, endPrefix []byte, ops IteratorOption) (Iterator, error)
+}
+
+// Writer writes data to the database as part of a batch. The writes are only visible to readers once
+// the batch is committed.
+type Writer interface {
+	// Set stores the value under the given key, overwriting any existing value.
+	// The key and value may be modified after the call returns.
+	// No errors are expected during normal operation.
+	Set(k, v []byte) error
+
+	// Delete deletes the value stored under the given key. It is a no-op if no value is stored.
+	// No errors are expected during normal operation.
+	Delete(key []byte) error
+
+	// DeleteByRange deletes all keys which have a prefix within [startPrefix, endPrefix], both inclusive.
+	// The keys are read from the given reader, hence keys written within the same batch are not deleted.
+	// No errors are expected during normal operation.
+	DeleteByRange(globalReader Reader, startPrefix, endPrefix []byte) error
+}
+
+// ReaderBatchWriter gives access to a batch, as well as to a reader of the committed database state.
+type ReaderBatchWriter interface {
+	// GlobalReader returns a reader of the committed database state. It does NOT see the writes of
+	// the batch, and isn't isolated from batches committed concurrently.
+	GlobalReader() Reader
+
+	// Writer returns the writer of the batch.
+	Writer() Writer
+
+	// AddCallback adds a function which is called with the result of committing the batch: nil if it
+	// was committed, or the error which prevented it from being committed.
+	AddCallback(func(error))
+}
+
+// DB is a key-value database, which protocol data is read from and written to in batches.
+type DB interface {
+	// Reader returns a reader of the committed database state.
+	Reader() Reader
+
+	// WithReaderBatchWriter creates a batch, passes it to fn, and commits it if fn returns no error.
+	// The batch is discarded if fn returns an error, which is returned as is.
+	// No errors are expected during normal operation, apart from the ones returned by fn.
+	WithReaderBatchWriter(fn func(ReaderBatchWriter) error) error
+}
+
+// OnlyWriter returns a function which can be passed to DB.WithReaderBatchWriter for writes which
+// don't need to read.
+func OnlyWriter(fn func(Writer) error) func(ReaderBatchWriter) error {
+	return func(rw ReaderBatchWriter) error {
+		return fn(rw.Writer())
+	}
+}
+
+// PrefixUpperBound returns the smallest key which is larger than all keys with the given prefix,
+// or nil if there is none, i.e. if the prefix only consists of 0xff bytes.
+func PrefixUpperBound(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	return nil
+}
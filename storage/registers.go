@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// RegisterIndex stores the values of the execution state registers by block height, so that the
+// execution state at any height between FirstHeight and LatestHeight can be read.
+type RegisterIndex interface {
+	// Get returns the value of the register at the given height, i.e. the value set by the latest
+	// update at or below the height.
+	// Expected errors during normal operation:
+	//   - storage.ErrNotFound if the register has no value at the given height
+	//   - storage.ErrHeightNotIndexed if the height is outside of [FirstHeight, LatestHeight], or the
+	//     registers of the height have not been stored
+	Get(ID flow.RegisterID, height uint64) (flow.RegisterValue, error)
+
+	// FirstHeight returns the height of the execution state the index was bootstrapped from.
+	FirstHeight() uint64
+
+	// LatestHeight returns the latest height whose register updates have been stored.
+	LatestHeight() uint64
+
+	// Store stores the register updates of the block at the given height, which must be
+	// LatestHeight + 1. Storing the updates of a height which has already been stored is a no-op.
+	// No errors are expected during normal operation.
+	Store(entries flow.RegisterEntries, height uint64) error
+}
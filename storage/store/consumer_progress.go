@@ -0,0 +1,84 @@
+// Package store contains the implementations of the storage interfaces on top of a backend-neutral
+// storage.DB, so that they work with any database backend.
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation"
+)
+
+// ConsumerProgress stores the processed index of a job consumer.
+type ConsumerProgress struct {
+	db       storage.DB
+	consumer string // to distinguish the consume progress between different consumers
+
+	// batches are not isolated from the database state they read, hence initializing and updating
+	// the processed index, which check the stored index before writing it, are serialized.
+	// CAUTION: instances for the same consumer must not initialize or update the index concurrently.
+	mu sync.Mutex
+}
+
+var _ storage.ConsumerProgress = (*ConsumerProgress)(nil)
+
+func NewConsumerProgress(db storage.DB, consumer string) *ConsumerProgress {
+	return &ConsumerProgress{
+		db:       db,
+		consumer: consumer,
+	}
+}
+
+// ProcessedIndex returns the processed index of the consumer.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the processed index was never initialized
+func (cp *ConsumerProgress) ProcessedIndex() (uint64, error) {
+	var processed uint64
+	err := operation.RetrieveProcessedIndex(cp.consumer, &processed)(cp.db.Reader())
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve processed index: %w", err)
+	}
+	return processed, nil
+}
+
+// InitProcessedIndex insert the default processed index to the storage layer, can only be done once.
+// initialize for the second time will return storage.ErrAlreadyExists
+func (cp *ConsumerProgress) InitProcessedIndex(defaultIndex uint64) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	_, err := cp.ProcessedIndex()
+	if err == nil {
+		return fmt.Errorf("processed index of %s is already initialized: %w", cp.consumer, storage.ErrAlreadyExists)
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	err = cp.db.WithReaderBatchWriter(storage.OnlyWriter(operation.UpsertProcessedIndex(cp.consumer, defaultIndex)))
+	if err != nil {
+		return fmt.Errorf("could not update processed index: %w", err)
+	}
+	return nil
+}
+
+// SetProcessedIndex updates the processed index of the consumer.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the processed index was never initialized
+func (cp *ConsumerProgress) SetProcessedIndex(processed uint64) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	_, err := cp.ProcessedIndex()
+	if err != nil {
+		return err
+	}
+
+	err = cp.db.WithReaderBatchWriter(storage.OnlyWriter(operation.UpsertProcessedIndex(cp.consumer, processed)))
+	if err != nil {
+		return fmt.Errorf("could not update processed index: %w", err)
+	}
+	return nil
+}
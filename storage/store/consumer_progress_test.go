@@ -0,0 +1,39 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation/dbtest"
+	"github.com/onflow/flow-go/storage/store"
+)
+
+func TestConsumerProgress(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		progress := store.NewConsumerProgress(db, "consumer")
+
+		// can't read or set the index before it is initialized
+		_, err := progress.ProcessedIndex()
+		require.ErrorIs(t, err, storage.ErrNotFound)
+		require.ErrorIs(t, progress.SetProcessedIndex(10), storage.ErrNotFound)
+
+		// can read after init, and can only init once
+		require.NoError(t, progress.InitProcessedIndex(5))
+		require.ErrorIs(t, progress.InitProcessedIndex(6), storage.ErrAlreadyExists)
+		index, err := progress.ProcessedIndex()
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), index)
+
+		// can read after set
+		require.NoError(t, progress.SetProcessedIndex(10))
+		index, err = progress.ProcessedIndex()
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), index)
+
+		// the progress of other consumers is independent
+		_, err = store.NewConsumerProgress(db, "other").ProcessedIndex()
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
@@ -0,0 +1,354 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/atomic"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation"
+)
+
+// Registers stores the values of the execution state registers by block height.
+// The registers must have been bootstrapped from the execution state at FirstHeight, see
+// BootstrapRegisters, before register updates can be stored. Access and observer nodes store
+// the registers indexed from execution data, execution nodes the registers of every sealed height
+// (see engine/execution/state/history).
+type Registers struct {
+	db           storage.DB
+	firstHeight  uint64
+	latestHeight *atomic.Uint64
+
+	// storing register updates, which checks the latest height before writing it, is serialized
+	mu sync.Mutex
+
+	skippedMu sync.RWMutex
+	skipped   []heightRange // heights skipped by Rebootstrap, in ascending order
+}
+
+// heightRange is a range of heights [start, end].
+type heightRange struct {
+	start uint64
+	end   uint64
+}
+
+var _ storage.RegisterIndex = (*Registers)(nil)
+
+// NewRegisters creates a new Registers on the given database.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the registers in the database were never bootstrapped
+func NewRegisters(db storage.DB) (*Registers, error) {
+	var firstHeight uint64
+	err := operation.RetrieveRegistersFirstHeight(&firstHeight)(db.Reader())
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve first height of registers: %w", err)
+	}
+
+	var latestHeight uint64
+	err = operation.RetrieveRegistersLatestHeight(&latestHeight)(db.Reader())
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve latest height of registers: %w", err)
+	}
+
+	var skipped []heightRange
+	err = operation.TraverseRegistersSkippedHeights(func(start uint64, end uint64) {
+		skipped = append(skipped, heightRange{start: start, end: end})
+	})(db.Reader())
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve skipped heights of registers: %w", err)
+	}
+
+	return &Registers{
+		db:           db,
+		firstHeight:  firstHeight,
+		latestHeight: atomic.NewUint64(latestHeight),
+		skipped:      skipped,
+	}, nil
+}
+
+// Get returns the value of the register at the given height, i.e. the value set by the latest
+// update at or below the height.
+// Expected errors during normal operation:
+//   - storage.ErrNotFound if the register has no value at the given height
+//   - storage.ErrHeightNotIndexed if the height is outside of [FirstHeight, LatestHeight], or has been
+//     skipped by Rebootstrap
+func (r *Registers) Get(ID flow.RegisterID, height uint64) (flow.RegisterValue, error) {
+	if height < r.firstHeight || height > r.latestHeight.Load() {
+		return nil, fmt.Errorf("height %d is outside of the indexed range [%d, %d]: %w",
+			height, r.firstHeight, r.latestHeight.Load(), storage.ErrHeightNotIndexed)
+	}
+	if r.isSkipped(height) {
+		return nil, fmt.Errorf("height %d has been skipped: %w", height, storage.ErrHeightNotIndexed)
+	}
+
+	var value flow.RegisterValue
+	err := operation.RetrieveRegister(ID, height, &value)(r.db.Reader())
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve register %v at height %d: %w", ID, height, err)
+	}
+	return value, nil
+}
+
+// FirstHeight returns the height of the execution state the registers were bootstrapped from.
+func (r *Registers) FirstHeight() uint64 {
+	return r.firstHeight
+}
+
+// LatestHeight returns the latest height whose register updates have been stored.
+func (r *Registers) LatestHeight() uint64 {
+	return r.latestHeight.Load()
+}
+
+// IsIndexed returns whether the registers at the given height can be read, i.e. whether the height
+// is within [FirstHeight, LatestHeight] and has not been skipped by Rebootstrap.
+func (r *Registers) IsIndexed(height uint64) bool {
+	return height >= r.firstHeight && height <= r.latestHeight.Load() && !r.isSkipped(height)
+}
+
+func (r *Registers) isSkipped(height uint64) bool {
+	r.skippedMu.RLock()
+	defer r.skippedMu.RUnlock()
+
+	for _, skipped := range r.skipped {
+		if height >= skipped.start && height <= skipped.end {
+			return true
+		}
+	}
+	return false
+}
+
+// Store stores the register updates of the block at the given height, which must be
+// LatestHeight + 1. Storing the updates of a height which has already been stored is a no-op.
+// No errors are expected during normal operation.
+func (r *Registers) Store(entries flow.RegisterEntries, height uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latestHeight := r.latestHeight.Load()
+	if height <= latestHeight {
+		return nil
+	}
+	if height != latestHeight+1 {
+		return fmt.Errorf("must store registers of height %d, got %d", latestHeight+1, height)
+	}
+
+	err := r.db.WithReaderBatchWriter(func(rw storage.ReaderBatchWriter) error {
+		for _, entry := range entries {
+			err := operation.UpsertRegister(entry.Key, height, entry.Value)(rw.Writer())
+			if err != nil {
+				return fmt.Errorf("could not store register %v: %w", entry.Key, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not store registers of height %d: %w", height, err)
+	}
+
+	// batches are not guaranteed to be applied atomically by all backends, hence the latest height is
+	// only stored once all registers of the height have been stored. Storing them again is harmless.
+	err = r.db.WithReaderBatchWriter(storage.OnlyWriter(operation.UpsertRegistersLatestHeight(height)))
+	if err != nil {
+		return fmt.Errorf("could not store latest height %d: %w", height, err)
+	}
+
+	r.latestHeight.Store(height)
+	return nil
+}
+
+// BootstrapRegisters stores the given registers as the execution state at the given height, and marks
+// the registers as bootstrapped once all of them have been stored. The registers are read from the
+// given function, which is called until it returns no more registers, and stored in one batch per call.
+// Registers left by an interrupted bootstrapping are removed first.
+// Expected errors during normal operation:
+//   - storage.ErrAlreadyExists if the registers in the database have already been bootstrapped
+func BootstrapRegisters(db storage.DB, height uint64, next func() (flow.RegisterEntries, error)) error {
+	var firstHeight uint64
+	err := operation.RetrieveRegistersFirstHeight(&firstHeight)(db.Reader())
+	if err == nil {
+		return fmt.Errorf("registers have already been bootstrapped at height %d: %w", firstHeight, storage.ErrAlreadyExists)
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("could not retrieve first height of registers: %w", err)
+	}
+
+	// an interrupted bootstrapping might have been at a different height, its registers must not be
+	// mistaken for values of the execution state at this height
+	err = db.WithReaderBatchWriter(func(rw storage.ReaderBatchWriter) error {
+		return operation.RemoveAllRegisters(rw.GlobalReader())(rw.Writer())
+	})
+	if err != nil {
+		return fmt.Errorf("could not remove registers of interrupted bootstrapping: %w", err)
+	}
+
+	for {
+		entries, err := next()
+		if err != nil {
+			return fmt.Errorf("could not read registers: %w", err)
+		}
+		if len(entries) == 0 {
+			break
+		}
+
+		err = db.WithReaderBatchWriter(func(rw storage.ReaderBatchWriter) error {
+			for _, entry := range entries {
+				err := operation.UpsertRegister(entry.Key, height, entry.Value)(rw.Writer())
+				if err != nil {
+					return fmt.Errorf("could not store register %v: %w", entry.Key, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not store registers: %w", err)
+		}
+	}
+
+	// the heights are stored last, so that an interrupted bootstrapping is repeated from scratch
+	err = db.WithReaderBatchWriter(func(rw storage.ReaderBatchWriter) error {
+		err := operation.UpsertRegistersFirstHeight(height)(rw.Writer())
+		if err != nil {
+			return err
+		}
+		return operation.UpsertRegistersLatestHeight(height)(rw.Writer())
+	})
+	if err != nil {
+		return fmt.Errorf("could not store heights of registers: %w", err)
+	}
+
+	return nil
+}
+
+// rebootstrapBatchSize is the maximum number of registers written in a single batch when removing the
+// registers which are not part of the execution state stored by Rebootstrap.
+const rebootstrapBatchSize = 10_000
+
+// Rebootstrap stores the registers returned by next, which must be all registers of the execution state
+// at the given height, as the registers at the height. It is used when the register updates of the heights
+// following LatestHeight are not available anymore: the heights between LatestHeight and the given height
+// are marked as skipped, and reading them fails with storage.ErrHeightNotIndexed, while the heights stored
+// before stay readable. Register updates are stored on top of the given height afterwards.
+//
+// The registers are read from next until it returns no more registers, and stored in one batch per call.
+// Registers which are not part of the execution state get an empty value at the given height, which
+// requires reading all stored registers. The skipped heights and the latest height are stored last, so
+// that an interrupted Rebootstrap can be repeated, also at a different height.
+// No errors are expected during normal operation.
+func (r *Registers) Rebootstrap(height uint64, next func() (flow.RegisterEntries, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latestHeight := r.latestHeight.Load()
+	if height <= latestHeight {
+		return fmt.Errorf("must rebootstrap registers above latest height %d, got %d", latestHeight, height)
+	}
+
+	for {
+		entries, err := next()
+		if err != nil {
+			return fmt.Errorf("could not read registers: %w", err)
+		}
+		if len(entries) == 0 {
+			break
+		}
+
+		err = r.db.WithReaderBatchWriter(func(rw storage.ReaderBatchWriter) error {
+			for _, entry := range entries {
+				err := operation.UpsertRegister(entry.Key, height, entry.Value)(rw.Writer())
+				if err != nil {
+					return fmt.Errorf("could not store register %v: %w", entry.Key, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not store registers: %w", err)
+		}
+	}
+
+	err := r.removeRegistersMissingAt(height)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.WithReaderBatchWriter(func(rw storage.ReaderBatchWriter) error {
+		if height > latestHeight+1 {
+			err := operation.UpsertRegistersSkippedHeights(latestHeight+1, height-1)(rw.Writer())
+			if err != nil {
+				return err
+			}
+		}
+		return operation.UpsertRegistersLatestHeight(height)(rw.Writer())
+	})
+	if err != nil {
+		return fmt.Errorf("could not store heights of registers: %w", err)
+	}
+
+	if height > latestHeight+1 {
+		r.skippedMu.Lock()
+		r.skipped = append(r.skipped, heightRange{start: latestHeight + 1, end: height - 1})
+		r.skippedMu.Unlock()
+	}
+	r.latestHeight.Store(height)
+	return nil
+}
+
+// removeRegistersMissingAt stores an empty value at the given height for every register which has a value
+// below the height, but no value at the height, i.e. which is not part of the execution state stored at
+// the height. Values above the height, which can only be left by an interrupted Rebootstrap, are removed.
+// No errors are expected during normal operation.
+func (r *Registers) removeRegistersMissingAt(height uint64) error {
+	var writes []func(storage.Writer) error
+	flush := func() error {
+		err := r.db.WithReaderBatchWriter(func(rw storage.ReaderBatchWriter) error {
+			for _, write := range writes {
+				err := write(rw.Writer())
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not remove registers: %w", err)
+		}
+		writes = writes[:0]
+		return nil
+	}
+
+	var current flow.RegisterID
+	var seen, decided bool
+	err := operation.TraverseRegisters(func(id flow.RegisterID, valueHeight uint64, value func() (flow.RegisterValue, error)) error {
+		if !seen || id != current {
+			current, seen, decided = id, true, false
+		}
+		if valueHeight > height {
+			writes = append(writes, operation.RemoveRegister(id, valueHeight))
+		} else if !decided {
+			// the values of a register are ordered from the highest to the lowest height, hence this
+			// is the value of the register at the height
+			decided = true
+			if valueHeight < height {
+				v, err := value()
+				if err != nil {
+					return err
+				}
+				if len(v) > 0 {
+					writes = append(writes, operation.UpsertRegister(id, height, nil))
+				}
+			}
+		}
+
+		if len(writes) >= rebootstrapBatchSize {
+			return flush()
+		}
+		return nil
+	})(r.db.Reader())
+	if err != nil {
+		return fmt.Errorf("could not read registers: %w", err)
+	}
+	return flush()
+}
@@ -0,0 +1,219 @@
+package store_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/operation/dbtest"
+	"github.com/onflow/flow-go/storage/store"
+)
+
+func TestRegisters(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		owner := string(flow.HexToAddress("0x01").Bytes())
+		reg := flow.RegisterID{Owner: owner, Key: "key"}
+		// registers whose owner or key extend the ones of reg must not be mixed up with it
+		longerKey := flow.RegisterID{Owner: owner, Key: "key2"}
+		global := flow.RegisterID{Owner: "", Key: "key"}
+
+		// can't be used before bootstrapping
+		_, err := store.NewRegisters(db)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		batches := []flow.RegisterEntries{
+			{{Key: reg, Value: []byte("a")}},
+			{{Key: global, Value: []byte("g")}},
+		}
+		next := func() (flow.RegisterEntries, error) {
+			if len(batches) == 0 {
+				return nil, nil
+			}
+			entries := batches[0]
+			batches = batches[1:]
+			return entries, nil
+		}
+		require.NoError(t, store.BootstrapRegisters(db, 10, next))
+		require.ErrorIs(t, store.BootstrapRegisters(db, 10, next), storage.ErrAlreadyExists)
+
+		registers, err := store.NewRegisters(db)
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), registers.FirstHeight())
+		require.Equal(t, uint64(10), registers.LatestHeight())
+
+		// heights must be stored consecutively
+		require.Error(t, registers.Store(flow.RegisterEntries{{Key: reg, Value: []byte("c")}}, 12))
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: longerKey, Value: []byte("b")}}, 11))
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: reg, Value: []byte("c")}}, 12))
+		// storing a height again is a no-op
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: reg, Value: []byte("d")}}, 12))
+		require.Equal(t, uint64(12), registers.LatestHeight())
+
+		for height, expected := range map[uint64]string{10: "a", 11: "a", 12: "c"} {
+			value, err := registers.Get(reg, height)
+			require.NoError(t, err)
+			require.Equal(t, expected, string(value))
+
+			value, err = registers.Get(global, height)
+			require.NoError(t, err)
+			require.Equal(t, "g", string(value))
+		}
+
+		_, err = registers.Get(longerKey, 10)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+		value, err := registers.Get(longerKey, 12)
+		require.NoError(t, err)
+		require.Equal(t, "b", string(value))
+
+		_, err = registers.Get(flow.RegisterID{Owner: owner, Key: "other"}, 12)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		// heights outside of the indexed range can't be read
+		_, err = registers.Get(reg, 9)
+		require.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+		_, err = registers.Get(reg, 13)
+		require.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+
+		// the latest height is persisted
+		registers, err = store.NewRegisters(db)
+		require.NoError(t, err)
+		require.Equal(t, uint64(12), registers.LatestHeight())
+	})
+}
+
+// TestBootstrapRegisters_Interrupted tests that the registers of an interrupted bootstrapping are
+// removed when bootstrapping again, even at a different height.
+func TestBootstrapRegisters_Interrupted(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		leftover := flow.RegisterID{Owner: "", Key: "leftover"}
+		reg := flow.RegisterID{Owner: "", Key: "key"}
+
+		interrupted := fmt.Errorf("interrupted")
+		stored := false
+		err := store.BootstrapRegisters(db, 9, func() (flow.RegisterEntries, error) {
+			if stored {
+				return nil, interrupted
+			}
+			stored = true
+			return flow.RegisterEntries{{Key: leftover, Value: []byte("l")}}, nil
+		})
+		require.ErrorIs(t, err, interrupted)
+		_, err = store.NewRegisters(db)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		entries := flow.RegisterEntries{{Key: reg, Value: []byte("a")}}
+		require.NoError(t, store.BootstrapRegisters(db, 10, func() (flow.RegisterEntries, error) {
+			next := entries
+			entries = nil
+			return next, nil
+		}))
+
+		registers, err := store.NewRegisters(db)
+		require.NoError(t, err)
+		_, err = registers.Get(leftover, 10)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+		value, err := registers.Get(reg, 10)
+		require.NoError(t, err)
+		require.Equal(t, "a", string(value))
+	})
+}
+
+// TestRegisters_Rebootstrap tests that rebootstrapping the registers at a later height keeps the heights
+// stored before, skips the heights in between, and removes the registers missing from the new state.
+func TestRegisters_Rebootstrap(t *testing.T) {
+	dbtest.RunWithDB(t, func(t *testing.T, db storage.DB) {
+		kept := flow.RegisterID{Owner: "", Key: "kept"}
+		removed := flow.RegisterID{Owner: "", Key: "removed"}
+		added := flow.RegisterID{Owner: "", Key: "added"}
+		leftover := flow.RegisterID{Owner: "", Key: "leftover"}
+
+		batches := func(entries ...flow.RegisterEntries) func() (flow.RegisterEntries, error) {
+			return func() (flow.RegisterEntries, error) {
+				if len(entries) == 0 {
+					return nil, nil
+				}
+				next := entries[0]
+				entries = entries[1:]
+				return next, nil
+			}
+		}
+
+		require.NoError(t, store.BootstrapRegisters(db, 10, batches(flow.RegisterEntries{
+			{Key: kept, Value: []byte("a")},
+			{Key: removed, Value: []byte("r")},
+		})))
+		registers, err := store.NewRegisters(db)
+		require.NoError(t, err)
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: kept, Value: []byte("b")}}, 11))
+
+		// can only rebootstrap above the latest height
+		require.Error(t, registers.Rebootstrap(11, batches()))
+
+		// an interrupted rebootstrapping at a higher height leaves registers behind
+		interrupted := fmt.Errorf("interrupted")
+		next := batches(flow.RegisterEntries{{Key: leftover, Value: []byte("l")}})
+		err = registers.Rebootstrap(20, func() (flow.RegisterEntries, error) {
+			entries, err := next()
+			if len(entries) == 0 {
+				return nil, interrupted
+			}
+			return entries, err
+		})
+		require.ErrorIs(t, err, interrupted)
+		require.Equal(t, uint64(11), registers.LatestHeight())
+
+		require.NoError(t, registers.Rebootstrap(15, batches(
+			flow.RegisterEntries{{Key: kept, Value: []byte("c")}},
+			flow.RegisterEntries{{Key: added, Value: []byte("d")}},
+		)))
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: added, Value: []byte("e")}}, 16))
+
+		check := func(registers *store.Registers) {
+			require.Equal(t, uint64(10), registers.FirstHeight())
+			require.Equal(t, uint64(16), registers.LatestHeight())
+
+			for height := uint64(12); height <= 14; height++ {
+				require.False(t, registers.IsIndexed(height))
+				_, err := registers.Get(kept, height)
+				require.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+			}
+			for _, height := range []uint64{10, 11, 15, 16} {
+				require.True(t, registers.IsIndexed(height))
+			}
+
+			expected := []struct {
+				id     flow.RegisterID
+				height uint64
+				value  string
+			}{
+				{kept, 10, "a"},
+				{kept, 11, "b"},
+				{kept, 15, "c"},
+				{kept, 16, "c"},
+				{removed, 11, "r"},
+				{removed, 15, ""},
+				{added, 15, "d"},
+				{added, 16, "e"},
+				{leftover, 16, ""},
+			}
+			for _, e := range expected {
+				value, err := registers.Get(e.id, e.height)
+				if err == nil {
+					require.Equal(t, e.value, string(value), "register %s at height %d", e.id, e.height)
+					continue
+				}
+				require.ErrorIs(t, err, storage.ErrNotFound)
+				require.Empty(t, e.value, "register %s at height %d", e.id, e.height)
+			}
+		}
+		check(registers)
+
+		// the skipped heights are persisted
+		registers, err = store.NewRegisters(db)
+		require.NoError(t, err)
+		check(registers)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/environment"
 	"github.com/onflow/flow-go/model/flow"
 )
 
@@ -12,6 +13,7 @@ type RemoteDebugger struct {
 	vm          fvm.VM
 	ctx         fvm.Context
 	grpcAddress string
+	profiler    *environment.ComputationProfiler
 }
 
 // Warning : make sure you use the proper flow-go version, same version as the network you are collecting registers
@@ -21,8 +23,8 @@ func NewRemoteDebugger(grpcAddress string,
 	logger zerolog.Logger) *RemoteDebugger {
 	vm := fvm.NewVirtualMachine()
 
-	// no signature processor here
-	// TODO Maybe we add fee-deduction step as well
+	// no signature processor or fee deduction here, ReplayTransaction
+	// executes transactions with the production context instead
 	ctx := fvm.NewContext(
 		fvm.WithLogger(logger),
 		fvm.WithChain(chain),
@@ -36,6 +38,19 @@ func NewRemoteDebugger(grpcAddress string,
 	}
 }
 
+// EnableComputationProfiling makes the debugger profile the computation and
+// memory usage of the transactions and scripts it runs, attributed to Cadence
+// (contract, function) call stacks. The returned profiler accumulates the
+// usage of all subsequent runs; use its WriteProfile method to export a pprof
+// profile.
+func (d *RemoteDebugger) EnableComputationProfiling() *environment.ComputationProfiler {
+	d.profiler = environment.NewComputationProfiler()
+	d.ctx = fvm.NewContextFromParent(
+		d.ctx,
+		fvm.WithComputationProfiler(d.profiler))
+	return d.profiler
+}
+
 // RunTransaction runs the transaction given the latest sealed block data
 func (d *RemoteDebugger) RunTransaction(
 	txBody *flow.TransactionBody,
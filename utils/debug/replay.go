@@ -0,0 +1,450 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/execution"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/environment"
+	reusableRuntime "github.com/onflow/flow-go/fvm/runtime"
+	"github.com/onflow/flow-go/fvm/storage/snapshot"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol/seed"
+)
+
+// TransactionReplayResult holds the outcome of replaying an already executed
+// transaction, together with the result recorded on chain.
+type TransactionReplayResult struct {
+	BlockID          flow.Identifier
+	TransactionIndex uint32
+	// PriorTransactions is the number of transactions of the same block which
+	// were re-executed to build the pre-state of the replayed transaction.
+	PriorTransactions int
+
+	Output fvm.ProcedureOutput
+
+	OnChainErrorMessage string
+	OnChainEvents       flow.EventsList
+
+	// Diffs lists every difference found between the replayed execution and
+	// the on chain result. It is empty if the replay matches the chain.
+	Diffs []string
+}
+
+// Matches returns true if the replayed execution produced the same result
+// and events as the ones recorded on chain.
+func (r *TransactionReplayResult) Matches() bool {
+	return len(r.Diffs) == 0
+}
+
+// ProductionContext returns the fvm context an execution node uses to execute
+// user transactions on the given chain.
+func ProductionContext(
+	chain flow.Chain,
+	opts ...fvm.Option,
+) fvm.Context {
+	chainID := chain.ChainID()
+
+	vmOpts := append(fvm.ChainOptions(chainID),
+		fvm.WithReusableCadenceRuntimePool(
+			reusableRuntime.NewReusableCadenceRuntimePool(
+				0,
+				fvm.RuntimeConfig(chainID, false),
+			),
+		),
+	)
+
+	return fvm.NewContext(append(vmOpts, opts...)...)
+}
+
+// ReplayTransaction re-executes an already executed transaction at its
+// original block, using the production fvm context of the chain.
+//
+// Registers are fetched as of the start of the transaction's block (the end
+// state of its parent), and all transactions preceding it in the block are
+// executed first to reproduce the exact pre-state. The produced events and
+// result are then compared against the ones recorded on chain.
+//
+// Transaction bodies and the block payload are fetched from the access node
+// at accessAddress, registers and on chain results from the execution node
+// the debugger is connected to. The block's source of randomness is read from
+// its finalized child through the access node as well.
+// If computation profiling is enabled, only the replayed transaction is
+// profiled.
+// If regCachePath is not empty, fetched registers are cached in that file and
+// reused by subsequent replays of transactions in the same block.
+func (d *RemoteDebugger) ReplayTransaction(
+	accessAddress string,
+	txID flow.Identifier,
+	regCachePath string,
+) (
+	*TransactionReplayResult,
+	error,
+) {
+	conn, err := grpc.Dial(
+		accessAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to access node: %w", err)
+	}
+	defer conn.Close()
+
+	accessClient := access.NewAccessAPIClient(conn)
+	chain := d.ctx.Chain
+
+	block, txBodies, txIndex, err := fetchBlockTransactions(
+		accessClient,
+		chain,
+		txID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := NewRemoteStorageSnapshot(
+		d.grpcAddress,
+		WithBlockID(block.Header.ParentID))
+	defer snapshot.Close()
+
+	if len(regCachePath) > 0 {
+		snapshot.Cache = newFileRegisterCache(regCachePath)
+	}
+
+	ctx := ProductionContext(
+		chain,
+		fvm.WithLogger(d.ctx.Logger),
+		fvm.WithBlocks(&remoteBlocks{client: accessClient}),
+		fvm.WithBlockHeader(block.Header),
+		fvm.WithEntropyProvider(&remoteEntropyProvider{
+			client: accessClient,
+			header: block.Header,
+		}))
+
+	output, err := replayBlockTransactions(
+		d.vm,
+		ctx,
+		snapshot,
+		txBodies,
+		d.profiler)
+	if err != nil {
+		return nil, err
+	}
+
+	err = snapshot.Cache.Persist()
+	if err != nil {
+		return nil, fmt.Errorf("could not persist register cache: %w", err)
+	}
+
+	blockID := block.ID()
+	onChain, err := snapshot.executionAPIclient.GetTransactionResult(
+		context.Background(),
+		&execution.GetTransactionResultRequest{
+			BlockId:       blockID[:],
+			TransactionId: txID[:],
+		})
+	if err != nil {
+		return nil, fmt.Errorf("could not get on chain transaction result: %w", err)
+	}
+
+	onChainEvents := make(flow.EventsList, 0, len(onChain.Events))
+	for _, event := range onChain.Events {
+		onChainEvents = append(onChainEvents, convert.MessageToEvent(event))
+	}
+
+	return &TransactionReplayResult{
+		BlockID:             blockID,
+		TransactionIndex:    txIndex,
+		PriorTransactions:   len(txBodies) - 1,
+		Output:              output,
+		OnChainErrorMessage: onChain.ErrorMessage,
+		OnChainEvents:       onChainEvents,
+		Diffs: DiffTransactionResult(
+			output,
+			onChain.ErrorMessage,
+			onChainEvents),
+	}, nil
+}
+
+// fetchBlockTransactions returns the block containing the given transaction,
+// and the bodies of all transactions of that block up to and including the
+// given transaction, in execution order.
+func fetchBlockTransactions(
+	client access.AccessAPIClient,
+	chain flow.Chain,
+	txID flow.Identifier,
+) (
+	*flow.Block,
+	[]*flow.TransactionBody,
+	uint32,
+	error,
+) {
+	txResult, err := client.GetTransactionResult(
+		context.Background(),
+		&access.GetTransactionRequest{Id: txID[:]})
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("could not get transaction result: %w", err)
+	}
+	if len(txResult.BlockId) == 0 {
+		return nil, nil, 0, fmt.Errorf("transaction %v has not been executed", txID)
+	}
+
+	blockResp, err := client.GetBlockByID(
+		context.Background(),
+		&access.GetBlockByIDRequest{
+			Id:                txResult.BlockId,
+			FullBlockResponse: true,
+		})
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("could not get block: %w", err)
+	}
+
+	block, err := convert.MessageToBlock(blockResp.Block)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("could not convert block: %w", err)
+	}
+
+	var txBodies []*flow.TransactionBody
+	for _, guarantee := range block.Payload.Guarantees {
+		colResp, err := client.GetCollectionByID(
+			context.Background(),
+			&access.GetCollectionByIDRequest{Id: guarantee.CollectionID[:]})
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf(
+				"could not get collection %v: %w",
+				guarantee.CollectionID,
+				err)
+		}
+
+		for _, id := range colResp.Collection.TransactionIds {
+			txResp, err := client.GetTransaction(
+				context.Background(),
+				&access.GetTransactionRequest{Id: id})
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf(
+					"could not get transaction %x: %w",
+					id,
+					err)
+			}
+
+			txBody, err := convert.MessageToTransaction(
+				txResp.Transaction,
+				chain)
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf(
+					"could not convert transaction %x: %w",
+					id,
+					err)
+			}
+
+			txBodies = append(txBodies, &txBody)
+
+			if bytes.Equal(id, txID[:]) {
+				return block, txBodies, uint32(len(txBodies) - 1), nil
+			}
+		}
+	}
+
+	return nil, nil, 0, fmt.Errorf(
+		"transaction %v not found in the collections of block %v (system "+
+			"transactions can not be replayed)",
+		txID,
+		block.ID())
+}
+
+// replayBlockTransactions executes the given transactions in order on top of
+// the given snapshot, each one seeing the writes of the previous ones, and
+// returns the output of the last transaction.  If profiler is not nil, only
+// the last transaction is profiled.
+func replayBlockTransactions(
+	vm fvm.VM,
+	ctx fvm.Context,
+	storageSnapshot snapshot.StorageSnapshot,
+	txBodies []*flow.TransactionBody,
+	profiler *environment.ComputationProfiler,
+) (
+	fvm.ProcedureOutput,
+	error,
+) {
+	if len(txBodies) == 0 {
+		return fvm.ProcedureOutput{}, fmt.Errorf("no transaction to replay")
+	}
+
+	tree := snapshot.NewSnapshotTree(storageSnapshot)
+
+	priorCtx := fvm.NewContextFromParent(ctx, fvm.WithComputationProfiler(nil))
+	replayCtx := fvm.NewContextFromParent(ctx, fvm.WithComputationProfiler(profiler))
+
+	var output fvm.ProcedureOutput
+	for i, txBody := range txBodies {
+		txCtx := priorCtx
+		if i == len(txBodies)-1 {
+			txCtx = replayCtx
+		}
+
+		executionSnapshot, txOutput, err := vm.Run(
+			txCtx,
+			fvm.Transaction(txBody, uint32(i)),
+			tree)
+		if err != nil {
+			return fvm.ProcedureOutput{}, fmt.Errorf(
+				"failed to execute transaction %v (index %d): %w",
+				txBody.ID(),
+				i,
+				err)
+		}
+
+		tree = tree.Append(executionSnapshot)
+		output = txOutput
+	}
+
+	return output, nil
+}
+
+// DiffTransactionResult compares the output of a replayed transaction with
+// the error message and events recorded on chain, and returns a human
+// readable description of each difference.
+func DiffTransactionResult(
+	output fvm.ProcedureOutput,
+	onChainErrorMessage string,
+	onChainEvents flow.EventsList,
+) []string {
+	var diffs []string
+
+	replayedErrorMessage := ""
+	if output.Err != nil {
+		replayedErrorMessage = output.Err.Error()
+	}
+	if replayedErrorMessage != onChainErrorMessage {
+		diffs = append(diffs, fmt.Sprintf(
+			"error message differs: replayed %q, on chain %q",
+			replayedErrorMessage,
+			onChainErrorMessage))
+	}
+
+	if len(output.Events) != len(onChainEvents) {
+		diffs = append(diffs, fmt.Sprintf(
+			"event count differs: replayed %d, on chain %d",
+			len(output.Events),
+			len(onChainEvents)))
+	}
+
+	for i := 0; i < len(output.Events) && i < len(onChainEvents); i++ {
+		replayed := output.Events[i]
+		onChain := onChainEvents[i]
+
+		if replayed.Type != onChain.Type {
+			diffs = append(diffs, fmt.Sprintf(
+				"event %d type differs: replayed %s, on chain %s",
+				i,
+				replayed.Type,
+				onChain.Type))
+			continue
+		}
+
+		if replayed.TransactionIndex != onChain.TransactionIndex ||
+			replayed.EventIndex != onChain.EventIndex {
+			diffs = append(diffs, fmt.Sprintf(
+				"event %d (%s) index differs: replayed %d/%d, on chain %d/%d",
+				i,
+				replayed.Type,
+				replayed.TransactionIndex,
+				replayed.EventIndex,
+				onChain.TransactionIndex,
+				onChain.EventIndex))
+		}
+
+		if !bytes.Equal(replayed.Payload, onChain.Payload) {
+			diffs = append(diffs, fmt.Sprintf(
+				"event %d (%s) payload differs: replayed %s, on chain %s",
+				i,
+				replayed.Type,
+				replayed.Payload,
+				onChain.Payload))
+		}
+	}
+
+	return diffs
+}
+
+// remoteBlocks looks up finalized block headers through the access API. The
+// replayed blocks are sealed, so the chain ending in the executed block is
+// part of the finalized chain.
+type remoteBlocks struct {
+	client access.AccessAPIClient
+}
+
+var _ environment.Blocks = (*remoteBlocks)(nil)
+
+func (b *remoteBlocks) ByHeightFrom(
+	height uint64,
+	header *flow.Header,
+) (
+	*flow.Header,
+	error,
+) {
+	if header != nil && header.Height == height {
+		return header, nil
+	}
+
+	if header != nil && height > header.Height {
+		return nil, fmt.Errorf(
+			"requested height (%d) is above the executed block height (%d)",
+			height,
+			header.Height)
+	}
+
+	resp, err := b.client.GetBlockHeaderByHeight(
+		context.Background(),
+		&access.GetBlockHeaderByHeightRequest{Height: height})
+	if err != nil {
+		return nil, fmt.Errorf("could not get block header at height %d: %w", height, err)
+	}
+
+	return convert.MessageToBlockHeader(resp.Block)
+}
+
+// remoteEntropyProvider reads the source of randomness of a sealed block
+// through the access API.  The source of randomness of a block is the random
+// beacon signature in the QC certifying it, which is included in its child.
+// The replayed block is sealed, so its child at the next height is finalized.
+type remoteEntropyProvider struct {
+	client access.AccessAPIClient
+	header *flow.Header
+}
+
+var _ environment.EntropyProvider = (*remoteEntropyProvider)(nil)
+
+func (p *remoteEntropyProvider) RandomSource() ([]byte, error) {
+	resp, err := p.client.GetBlockHeaderByHeight(
+		context.Background(),
+		&access.GetBlockHeaderByHeightRequest{Height: p.header.Height + 1})
+	if err != nil {
+		return nil, fmt.Errorf("could not get child block header at height %d: %w", p.header.Height+1, err)
+	}
+
+	child, err := convert.MessageToBlockHeader(resp.Block)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert child block header: %w", err)
+	}
+
+	blockID := p.header.ID()
+	if child.ParentID != blockID {
+		return nil, fmt.Errorf(
+			"finalized block at height %d is not a child of block %v",
+			child.Height,
+			blockID)
+	}
+
+	source, err := seed.FromParentQCSignature(child.ParentVoterSigData)
+	if err != nil {
+		return nil, fmt.Errorf("could not get source of randomness from QC of block %v: %w", blockID, err)
+	}
+
+	return source, nil
+}
@@ -0,0 +1,204 @@
+package debug
+
+import (
+	"encoding/binary"
+	"strconv"
+	"testing"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	accessmock "github.com/onflow/flow-go/engine/access/mock"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/engine/execution/testutil"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/errors"
+	fvmmock "github.com/onflow/flow-go/fvm/mock"
+	"github.com/onflow/flow-go/fvm/storage/snapshot"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol/seed"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestDiffTransactionResult(t *testing.T) {
+	events := flow.EventsList{
+		unittest.EventFixture("A.0x1.Foo.Bar", 1, 0, unittest.IdentifierFixture(), 0),
+		unittest.EventFixture("A.0x1.Foo.Baz", 1, 1, unittest.IdentifierFixture(), 0),
+	}
+
+	t.Run("matching result", func(t *testing.T) {
+		output := fvm.ProcedureOutput{Events: events}
+		require.Empty(t, DiffTransactionResult(output, "", events))
+	})
+
+	t.Run("different error", func(t *testing.T) {
+		output := fvm.ProcedureOutput{
+			Events: events,
+			Err:    errors.NewAccountNotFoundError(unittest.AddressFixture()),
+		}
+		diffs := DiffTransactionResult(output, "", events)
+		require.Len(t, diffs, 1)
+		require.Contains(t, diffs[0], "error message differs")
+	})
+
+	t.Run("missing event", func(t *testing.T) {
+		output := fvm.ProcedureOutput{Events: events[:1]}
+		diffs := DiffTransactionResult(output, "", events)
+		require.Len(t, diffs, 1)
+		require.Contains(t, diffs[0], "event count differs")
+	})
+
+	t.Run("different payload", func(t *testing.T) {
+		replayed := make(flow.EventsList, len(events))
+		copy(replayed, events)
+		replayed[1].Payload = []byte("different")
+
+		diffs := DiffTransactionResult(
+			fvm.ProcedureOutput{Events: replayed},
+			"",
+			events)
+		require.Len(t, diffs, 1)
+		require.Contains(t, diffs[0], "event 1 (A.0x1.Foo.Baz) payload differs")
+	})
+}
+
+func TestReplayBlockTransactions(t *testing.T) {
+	owner := unittest.RandomAddressFixture()
+	registerID := flow.NewRegisterID(string(owner.Bytes()), "key")
+
+	prior := unittest.TransactionBodyFixture()
+	replayed := unittest.TransactionBodyFixture()
+	txBodies := []*flow.TransactionBody{&prior, &replayed}
+
+	vm := &fvmmock.VM{}
+	vm.On("Run", mock.Anything, mock.Anything, mock.Anything).
+		Return(
+			func(
+				_ fvm.Context,
+				proc fvm.Procedure,
+				storageSnapshot snapshot.StorageSnapshot,
+			) *snapshot.ExecutionSnapshot {
+				// the prior transaction write must be visible to the replayed one
+				if proc.ExecutionTime() == 1 {
+					value, err := storageSnapshot.Get(registerID)
+					require.NoError(t, err)
+					require.Equal(t, flow.RegisterValue("prior"), value)
+				}
+				return &snapshot.ExecutionSnapshot{
+					WriteSet: map[flow.RegisterID]flow.RegisterValue{
+						registerID: []byte("prior"),
+					},
+				}
+			},
+			func(
+				_ fvm.Context,
+				proc fvm.Procedure,
+				_ snapshot.StorageSnapshot,
+			) fvm.ProcedureOutput {
+				return fvm.ProcedureOutput{
+					ComputationUsed: uint64(proc.ExecutionTime()) + 1,
+				}
+			},
+			nil)
+
+	output, err := replayBlockTransactions(
+		vm,
+		fvm.NewContext(),
+		snapshot.MapStorageSnapshot{},
+		txBodies,
+		nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), output.ComputationUsed)
+	vm.AssertNumberOfCalls(t, "Run", 2)
+}
+
+// TestReplayBlockTransactions_VerifiableRandom tests that transactions using
+// verifiable randomness are replayed with the source of randomness of their
+// block, read from the QC in its child.
+func TestReplayBlockTransactions_VerifiableRandom(t *testing.T) {
+	chain := flow.Localnet.Chain()
+	vm := fvm.NewVirtualMachine()
+
+	header := unittest.BlockHeaderFixture()
+	header.ChainID = chain.ChainID()
+	child := unittest.BlockHeaderWithParentFixture(header)
+	child.ParentVoterSigData = unittest.QCSigDataFixture()
+
+	source, err := seed.FromParentQCSignature(child.ParentVoterSigData)
+	require.NoError(t, err)
+
+	childMessage, err := convert.BlockHeaderToMessage(child, nil)
+	require.NoError(t, err)
+
+	client := accessmock.NewAccessAPIClient(t)
+	client.On(
+		"GetBlockHeaderByHeight",
+		mock.Anything,
+		&access.GetBlockHeaderByHeightRequest{Height: header.Height + 1},
+	).Return(&access.BlockHeaderResponse{Block: childMessage}, nil)
+
+	ctx := ProductionContext(
+		chain,
+		fvm.WithCadenceLogging(true),
+		fvm.WithBlockHeader(header),
+		fvm.WithEntropyProvider(&remoteEntropyProvider{
+			client: client,
+			header: header,
+		}))
+
+	txBodies := make([]*flow.TransactionBody, 2)
+	for i := range txBodies {
+		txBodies[i] = flow.NewTransactionBody().
+			SetScript([]byte(`
+				transaction {
+					prepare(signer: AuthAccount) {}
+					execute {
+						log(verifiableRandom())
+					}
+				}
+			`)).
+			AddAuthorizer(chain.ServiceAddress())
+		err := testutil.SignTransactionAsServiceAccount(txBodies[i], uint64(i), chain)
+		require.NoError(t, err)
+	}
+
+	output, err := replayBlockTransactions(
+		vm,
+		ctx,
+		testutil.RootBootstrappedLedger(vm, ctx),
+		txBodies,
+		nil)
+	require.NoError(t, err)
+	require.NoError(t, output.Err)
+	require.Len(t, output.Logs, 1)
+
+	replayed, err := strconv.ParseUint(output.Logs[0], 10, 64)
+	require.NoError(t, err)
+
+	// the replayed transaction is the second of its block
+	prg, err := seed.PRGFromRandomSource(source, seed.ExecutionTransaction(1))
+	require.NoError(t, err)
+	buf := make([]byte, 8)
+	prg.Read(buf)
+	require.Equal(t, binary.LittleEndian.Uint64(buf), replayed)
+}
+
+func TestRemoteEntropyProvider_NotAChild(t *testing.T) {
+	header := unittest.BlockHeaderFixture()
+	header.ChainID = flow.Localnet
+	other := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(header.Height + 1))
+	other.ChainID = flow.Localnet
+	other.ParentVoterSigData = unittest.QCSigDataFixture()
+
+	otherMessage, err := convert.BlockHeaderToMessage(other, nil)
+	require.NoError(t, err)
+
+	client := accessmock.NewAccessAPIClient(t)
+	client.On("GetBlockHeaderByHeight", mock.Anything, mock.Anything).
+		Return(&access.BlockHeaderResponse{Block: otherMessage}, nil)
+
+	provider := &remoteEntropyProvider{client: client, header: header}
+	_, err = provider.RandomSource()
+	require.ErrorContains(t, err, "is not a child of block")
+}
@@ -100,3 +100,19 @@ func InsertAndFinalize(t *testing.T, st protocol.ParticipantState, block *flow.B
 	err = st.Finalize(context.Background(), block.ID())
 	require.NoError(t, err)
 }
+
+// ProtocolStateWithSourceFixture returns a protocol state whose snapshots at any block
+// have the given source of randomness. A random source is used if source is nil.
+func ProtocolStateWithSourceFixture(source []byte) protocol.State {
+	if source == nil {
+		source = SignatureFixture()
+	}
+
+	snapshot := &mockprotocol.Snapshot{}
+	snapshot.On("RandomSource").Return(source, nil)
+
+	state := &mockprotocol.State{}
+	state.On("AtBlockID", mock.Anything).Return(snapshot)
+
+	return state
+}